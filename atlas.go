@@ -0,0 +1,204 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"image"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/packing"
+	"github.com/hajimehoshi/ebiten/internal/restorable"
+)
+
+const (
+	// atlasInitSize is the initial size of a backend atlas texture.
+	atlasInitSize = 512
+
+	// maxSharedImageSize is the maximum width/height of an image to be put into an atlas.
+	// Bigger images have their own textures.
+	maxSharedImageSize = 256
+
+	// atlasPadding is the transparent margin around each image in an atlas.
+	// This prevents linear filtering from sampling the pixels of the neighbors.
+	atlasPadding = 1
+)
+
+// atlasMaxSize is the maximum size that a backend atlas can grow to.
+var atlasMaxSize = 4096
+
+func init() {
+	if atlasMaxSize > MaxImageSize {
+		atlasMaxSize = MaxImageSize
+	}
+}
+
+// atlasBackend is a texture shared by multiple small images.
+type atlasBackend struct {
+	restorable *restorable.Image
+	page       *packing.Page
+	filter     opengl.Filter
+}
+
+// sharedImage is a region in an atlas backend.
+type sharedImage struct {
+	backend *atlasBackend
+	node    *packing.Node
+	width   int
+	height  int
+}
+
+var (
+	atlasBackends []*atlasBackend
+	atlasM        sync.Mutex
+)
+
+func newAtlasBackend(filter opengl.Filter) *atlasBackend {
+	r := restorable.NewImage(atlasInitSize, atlasInitSize, filter, false)
+	r.Fill(0, 0, 0, 0)
+	return &atlasBackend{
+		restorable: r,
+		page:       packing.NewPage(atlasInitSize, atlasMaxSize),
+		filter:     filter,
+	}
+}
+
+// extend doubles the size of the backend texture by copying the current pixels to a new texture.
+// The positions of the images in the backend are kept.
+func (b *atlasBackend) extend() bool {
+	old := b.page.Size()
+	if !b.page.Extend() {
+		return false
+	}
+	s := b.page.Size()
+	r := restorable.NewImage(s, s, b.filter, false)
+	r.Fill(0, 0, 0, 0)
+
+	var geom GeoM
+	var colorm ColorM
+	vs := vertices(0, 0, old, old, old, old, &geom.impl)
+	r.DrawImage(b.restorable, vs, quadIndices, &colorm.impl, glBlend(CompositeModeCopy, nil), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	b.restorable.Dispose()
+	b.restorable = r
+	return true
+}
+
+// newSharedImage allocates a region for an image of the given size in an atlas.
+// newSharedImage returns nil if the image is too big to share.
+func newSharedImage(width, height int, filter opengl.Filter) *sharedImage {
+	if width > maxSharedImageSize || height > maxSharedImageSize {
+		return nil
+	}
+	w, h := width+2*atlasPadding, height+2*atlasPadding
+
+	atlasM.Lock()
+	defer atlasM.Unlock()
+
+	alloc := func(b *atlasBackend) *sharedImage {
+		n := b.page.Alloc(w, h)
+		if n == nil {
+			return nil
+		}
+		return &sharedImage{
+			backend: b,
+			node:    n,
+			width:   width,
+			height:  height,
+		}
+	}
+
+	for _, b := range atlasBackends {
+		if b.filter != filter {
+			continue
+		}
+		if s := alloc(b); s != nil {
+			return s
+		}
+	}
+	// Grow an existing backend before creating a new one, so that the number of textures is kept small.
+	for _, b := range atlasBackends {
+		if b.filter != filter {
+			continue
+		}
+		for b.extend() {
+			if s := alloc(b); s != nil {
+				return s
+			}
+		}
+	}
+	b := newAtlasBackend(filter)
+	atlasBackends = append(atlasBackends, b)
+	return alloc(b)
+}
+
+// offset returns the position of the image in the backend texture.
+func (s *sharedImage) offset() image.Point {
+	x, y, _, _ := s.node.Region()
+	return image.Pt(x+atlasPadding, y+atlasPadding)
+}
+
+// initPixels initializes the pixels of the region with rgba.
+// The padding is cleared since it might have the pixels of a disposed image.
+func (s *sharedImage) initPixels(rgba *image.RGBA) {
+	x, y, w, h := s.node.Region()
+	pix := make([]uint8, 4*w*h)
+	for j := 0; j < s.height; j++ {
+		p := 4 * ((j+atlasPadding)*w + atlasPadding)
+		copy(pix[p:p+4*s.width], rgba.Pix[j*rgba.Stride:])
+	}
+	s.backend.restorable.ReplacePixelsRegion(pix, x, y, w, h)
+}
+
+// dispose releases the region.
+// When the backend becomes empty, the backend texture is disposed.
+func (s *sharedImage) dispose() {
+	atlasM.Lock()
+	defer atlasM.Unlock()
+
+	b := s.backend
+	b.page.Free(s.node)
+	if !b.page.IsEmpty() {
+		return
+	}
+	b.restorable.Dispose()
+	for i, bb := range atlasBackends {
+		if bb == b {
+			atlasBackends = append(atlasBackends[:i], atlasBackends[i+1:]...)
+			break
+		}
+	}
+}
+
+// ensureNotShared moves the image's pixels from the atlas to its own texture.
+// This is called before the image becomes a render target.
+func (i *Image) ensureNotShared() {
+	s := i.shared
+	if s == nil {
+		return
+	}
+	r := restorable.NewImage(s.width, s.height, s.backend.filter, false)
+	r.Fill(0, 0, 0, 0)
+
+	o := s.offset()
+	var geom GeoM
+	var colorm ColorM
+	w, h := s.backend.restorable.Size()
+	vs := vertices(o.X, o.Y, o.X+s.width, o.Y+s.height, w, h, &geom.impl)
+	r.DrawImage(s.backend.restorable, vs, quadIndices, &colorm.impl, glBlend(CompositeModeCopy, nil), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+
+	s.dispose()
+	i.shared = nil
+	i.restorable = r
+}
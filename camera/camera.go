@@ -0,0 +1,199 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package camera offers a 2D camera that converts between the world coordinates and the screen coordinates.
+//
+// A Camera has a position, a zoom, a rotation, a shake and optional bounds of the world.
+// Its GeoM can be concatenated to the GeoM of DrawImageOptions to draw world objects on the screen.
+//
+// Note: This package is experimental and API might be changed.
+package camera
+
+import (
+	"image"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Camera represents a 2D camera.
+type Camera struct {
+	// X and Y are the position in the world shown at the center of the viewport.
+	X float64
+	Y float64
+
+	// Zoom is the scale of the world.
+	// The default (zero) value means 1.
+	Zoom float64
+
+	// Rotation is the rotation of the camera in radians.
+	// The world is rotated in the opposite direction on the screen.
+	Rotation float64
+
+	// Bounds is the region of the world that the camera can show.
+	// The camera position is clamped so that the viewport doesn't show the outside of Bounds.
+	// If the viewport is bigger than Bounds, the center of Bounds is shown.
+	// Rotation is not taken into account.
+	// The default (empty) value means the world is not bounded.
+	Bounds image.Rectangle
+
+	width  int
+	height int
+
+	shakeMagnitude float64
+	shakeDuration  int
+	shakeCount     int
+	shakeX         float64
+	shakeY         float64
+}
+
+// New returns a new Camera with the given viewport size.
+//
+// The viewport size is usually the screen size.
+func New(width, height int) *Camera {
+	return &Camera{
+		width:  width,
+		height: height,
+	}
+}
+
+// ViewportSize returns the size of the viewport.
+func (c *Camera) ViewportSize() (width, height int) {
+	return c.width, c.height
+}
+
+// SetViewportSize sets the size of the viewport.
+func (c *Camera) SetViewportSize(width, height int) {
+	c.width = width
+	c.height = height
+}
+
+// Shake starts shaking the camera.
+//
+// magnitude is the maximum offset in the world's pixels, and duration is the number of ticks.
+// The magnitude decreases linearly until the shake ends.
+func (c *Camera) Shake(magnitude float64, duration int) {
+	c.shakeMagnitude = magnitude
+	c.shakeDuration = duration
+	c.shakeCount = duration
+}
+
+// Update advances the camera's shake by one tick.
+//
+// Update is expected to be called every tick, i.e., in the game's update function.
+func (c *Camera) Update() {
+	if c.shakeCount <= 0 {
+		c.shakeX = 0
+		c.shakeY = 0
+		return
+	}
+	m := c.shakeMagnitude * float64(c.shakeCount) / float64(c.shakeDuration)
+	c.shakeX = (rand.Float64()*2 - 1) * m
+	c.shakeY = (rand.Float64()*2 - 1) * m
+	c.shakeCount--
+}
+
+func (c *Camera) zoom() float64 {
+	if c.Zoom == 0 {
+		return 1
+	}
+	return c.Zoom
+}
+
+func clamp(v, min, max, size float64) float64 {
+	if max-min <= size {
+		return (min + max) / 2
+	}
+	if v < min+size/2 {
+		return min + size/2
+	}
+	if v > max-size/2 {
+		return max - size/2
+	}
+	return v
+}
+
+// center returns the position in the world at the center of the viewport, taking the bounds and the shake into account.
+func (c *Camera) center() (x, y float64) {
+	x, y = c.X, c.Y
+	if !c.Bounds.Empty() {
+		z := c.zoom()
+		b := c.Bounds
+		x = clamp(x, float64(b.Min.X), float64(b.Max.X), float64(c.width)/z)
+		y = clamp(y, float64(b.Min.Y), float64(b.Max.Y), float64(c.height)/z)
+	}
+	return x + c.shakeX, y + c.shakeY
+}
+
+// GeoM returns the geometry matrix that converts the world coordinates to the screen coordinates.
+func (c *Camera) GeoM() ebiten.GeoM {
+	x, y := c.center()
+	z := c.zoom()
+	var g ebiten.GeoM
+	g.Translate(-x, -y)
+	g.Rotate(-c.Rotation)
+	g.Scale(z, z)
+	g.Translate(float64(c.width)/2, float64(c.height)/2)
+	return g
+}
+
+// WorldToScreen converts the world coordinates (x, y) to the screen coordinates.
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	g := c.GeoM()
+	return g.Apply(x, y)
+}
+
+// ScreenToWorld converts the screen coordinates (x, y) to the world coordinates.
+func (c *Camera) ScreenToWorld(x, y float64) (float64, float64) {
+	cx, cy := c.center()
+	z := c.zoom()
+	x = (x - float64(c.width)/2) / z
+	y = (y - float64(c.height)/2) / z
+	sin, cos := math.Sincos(c.Rotation)
+	return x*cos - y*sin + cx, x*sin + y*cos + cy
+}
+
+// CursorPosition returns the position of the mouse cursor in the world coordinates.
+func (c *Camera) CursorPosition() (x, y float64) {
+	sx, sy := ebiten.CursorPosition()
+	return c.ScreenToWorld(float64(sx), float64(sy))
+}
+
+// TouchPosition returns the position of the touch of the given ID in the world coordinates.
+//
+// If the touch of the given ID is not pressed, TouchPosition returns the world position at the
+// left-upper corner of the screen.
+func (c *Camera) TouchPosition(id int) (x, y float64) {
+	sx, sy := ebiten.TouchPosition(id)
+	return c.ScreenToWorld(float64(sx), float64(sy))
+}
+
+// VisibleRect returns the rectangle in the world that contains the whole viewport.
+// When the camera is rotated, the rectangle is the bounding box of the rotated viewport.
+//
+// VisibleRect is useful to cull objects outside of the screen.
+func (c *Camera) VisibleRect() (x0, y0, x1, y1 float64) {
+	x0, y0 = math.Inf(1), math.Inf(1)
+	x1, y1 = math.Inf(-1), math.Inf(-1)
+	w, h := float64(c.width), float64(c.height)
+	for _, p := range [][2]float64{{0, 0}, {w, 0}, {0, h}, {w, h}} {
+		x, y := c.ScreenToWorld(p[0], p[1])
+		x0 = math.Min(x0, x)
+		y0 = math.Min(y0, y)
+		x1 = math.Max(x1, x)
+		y1 = math.Max(y1, y)
+	}
+	return
+}
@@ -0,0 +1,53 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package camera_test
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/camera"
+)
+
+func TestScreenToWorld(t *testing.T) {
+	c := New(320, 240)
+	c.X = 100
+	c.Y = 50
+	c.Zoom = 2
+	c.Rotation = math.Pi / 6
+	for _, p := range [][2]float64{{0, 0}, {160, 120}, {320, 0}, {12.5, 200}} {
+		wx, wy := c.ScreenToWorld(p[0], p[1])
+		sx, sy := c.WorldToScreen(wx, wy)
+		if math.Abs(sx-p[0]) > 1e-9 || math.Abs(sy-p[1]) > 1e-9 {
+			t.Errorf("WorldToScreen(ScreenToWorld(%v, %v)): got: (%v, %v)", p[0], p[1], sx, sy)
+		}
+	}
+	if x, y := c.ScreenToWorld(160, 120); math.Abs(x-100) > 1e-9 || math.Abs(y-50) > 1e-9 {
+		t.Errorf("ScreenToWorld(160, 120): got: (%v, %v), want: (100, 50)", x, y)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	c := New(320, 240)
+	c.Bounds = image.Rect(0, 0, 1000, 200)
+	c.X = -100
+	c.Y = 100
+	// The viewport is clamped to the left, and the height of the bounds are smaller than the viewport's.
+	x0, y0, x1, y1 := c.VisibleRect()
+	if x0 != 0 || y0 != -20 || x1 != 320 || y1 != 220 {
+		t.Errorf("VisibleRect(): got: (%v, %v, %v, %v), want: (0, -20, 320, 220)", x0, y0, x1, y1)
+	}
+}
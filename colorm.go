@@ -85,6 +85,35 @@ func (c *ColorM) ChangeHSV(hueTheta float64, saturationScale float64, valueScale
 	c.impl.ChangeHSV(hueTheta, saturationScale, valueScale)
 }
 
+// ChangeBrightness adds delta to the red, green and blue values.
+// A positive delta brightens the color and a negative delta darkens it.
+// The alpha value is not affected.
+func (c *ColorM) ChangeBrightness(delta float64) {
+	c.Translate(delta, delta, delta, 0)
+}
+
+// ChangeSaturation scales the saturation.
+// 0 makes the color monochrome and 1 keeps the original saturation.
+func (c *ColorM) ChangeSaturation(scale float64) {
+	c.ChangeHSV(0, scale, 1)
+}
+
+// Tint blends the red, green and blue values with clr.
+// strength is the ratio of clr in the result:
+// 0 keeps the original color and 1 replaces it with clr entirely.
+// The alpha value is not affected, and clr's alpha value is ignored.
+//
+// Tint is useful for effects like a flash on damage or a fade to black.
+func (c *ColorM) Tint(clr color.Color, strength float64) {
+	n := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	r := float64(n.R) / 0xff
+	g := float64(n.G) / 0xff
+	b := float64(n.B) / 0xff
+	s := 1 - strength
+	c.Scale(s, s, s, 1)
+	c.Translate(r*strength, g*strength, b*strength, 0)
+}
+
 // Element returns a value of a matrix at (i, j).
 func (c *ColorM) Element(i, j int) float64 {
 	return c.impl.UnsafeElements()[i*affine.ColorMDim+j]
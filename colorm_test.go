@@ -182,6 +182,18 @@ func TestColorMApply(t *testing.T) {
 	shiny := ColorM{}
 	shiny.Translate(1, 1, 1, 0)
 
+	dark := ColorM{}
+	dark.ChangeBrightness(-0.5)
+
+	gray := ColorM{}
+	gray.ChangeSaturation(0)
+
+	red := ColorM{}
+	red.Tint(color.RGBA{0xff, 0, 0, 0xff}, 0.5)
+
+	black := ColorM{}
+	black.Tint(color.Black, 1)
+
 	cases := []struct {
 		ColorM ColorM
 		In     color.Color
@@ -212,6 +224,30 @@ func TestColorMApply(t *testing.T) {
 			Out:    color.RGBA{0xb0, 0xb0, 0xb0, 0xb0},
 			Delta:  1,
 		},
+		{
+			ColorM: dark,
+			In:     color.RGBA{0xff, 0x80, 0x40, 0xff},
+			Out:    color.RGBA{0x80, 0, 0, 0xff},
+			Delta:  0x101,
+		},
+		{
+			ColorM: gray,
+			In:     color.RGBA{0xff, 0, 0, 0xff},
+			Out:    color.RGBA{0x4c, 0x4c, 0x4c, 0xff},
+			Delta:  0x101,
+		},
+		{
+			ColorM: red,
+			In:     color.RGBA{0, 0, 0xff, 0xff},
+			Out:    color.RGBA{0x80, 0, 0x80, 0xff},
+			Delta:  0x101,
+		},
+		{
+			ColorM: black,
+			In:     color.RGBA{0x40, 0x80, 0xc0, 0x80},
+			Out:    color.RGBA{0, 0, 0, 0x80},
+			Delta:  0x101,
+		},
 	}
 	for _, c := range cases {
 		out := c.ColorM.Apply(c.In)
@@ -0,0 +1,105 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/hajimehoshi/ebiten/internal/math"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/restorable"
+)
+
+// CompressedFormat represents a format of GPU-compressed pixels.
+//
+// All the formats compress each block of 4x4 pixels into a fixed size:
+// 8 bytes for CompressedFormatDXT1 and CompressedFormatETC2RGB, and 16 bytes for the others.
+// The blocks are ordered from left to right and from top to bottom.
+type CompressedFormat int
+
+const (
+	// CompressedFormatDXT1 is S3TC DXT1 (also known as BC1) with 1-bit alpha.
+	// This is available on most desktops.
+	CompressedFormatDXT1 CompressedFormat = CompressedFormat(opengl.CompressedFormatDXT1)
+
+	// CompressedFormatDXT5 is S3TC DXT5 (also known as BC3) with interpolated alpha.
+	// This is available on most desktops.
+	CompressedFormatDXT5 CompressedFormat = CompressedFormat(opengl.CompressedFormatDXT5)
+
+	// CompressedFormatETC2RGB is ETC2 RGB8 without alpha.
+	// This is available on OpenGL ES 3.0 devices and desktops with OpenGL 4.3.
+	CompressedFormatETC2RGB CompressedFormat = CompressedFormat(opengl.CompressedFormatETC2RGB)
+
+	// CompressedFormatETC2RGBA is ETC2 RGBA8 with EAC alpha.
+	// This is available on OpenGL ES 3.0 devices and desktops with OpenGL 4.3.
+	CompressedFormatETC2RGBA CompressedFormat = CompressedFormat(opengl.CompressedFormatETC2RGBA)
+)
+
+// IsCompressedFormatAvailable reports whether the compressed format is available in the current environment.
+//
+// IsCompressedFormatAvailable can't be called before the main loop (ebiten.Run) starts.
+func IsCompressedFormatAvailable(format CompressedFormat) bool {
+	return opengl.GetContext().IsCompressedFormatAvailable(opengl.CompressedFormat(format))
+}
+
+// NewCompressedImage returns an image with GPU-compressed pixels.
+// A compressed image uses less video memory than a regular image, and is uploaded without being decoded.
+// The ebitenutil/texcompress package can compress an image in DXT1 or DXT5.
+//
+// pixels is the blocks of width x height pixels in the format.
+// width and height must be multiples of 4.
+// When width, height or len(pixels) is not appropriate, NewCompressedImage panics.
+//
+// A compressed image can be only a source of drawing: Rendering functions like DrawImage, Fill and ReplacePixels
+// on a compressed image panic. At and ReadPixels on a compressed image return transparent pixels.
+// A compressed image is not color-converted by gamma correction.
+//
+// NewCompressedImage returns an error when the format is not available. Use IsCompressedFormatAvailable to check this.
+//
+// NewCompressedImage can't be called before the main loop (ebiten.Run) starts.
+func NewCompressedImage(pixels []byte, width, height int, format CompressedFormat, filter Filter) (*Image, error) {
+	checkSize(width, height)
+	if width%4 != 0 || height%4 != 0 {
+		panic(fmt.Sprintf("ebiten: width (%d) and height (%d) must be multiples of 4", width, height))
+	}
+	f := opengl.CompressedFormat(format)
+	if l := f.DataSizeInBytes(width, height); len(pixels) != l {
+		panic(fmt.Sprintf("ebiten: len(pixels) was %d but must be %d", len(pixels), l))
+	}
+	if !IsCompressedFormatAvailable(format) {
+		return nil, fmt.Errorf("ebiten: the compressed format %d is not available", format)
+	}
+
+	// The blocks are placed at the upper-left of the texture, whose size is the power of 2.
+	w2, h2 := math.NextPowerOf2Int(width), math.NextPowerOf2Int(height)
+	data := make([]uint8, f.DataSizeInBytes(w2, h2))
+	rowSize := f.DataSizeInBytes(width, 4)
+	rowSize2 := f.DataSizeInBytes(w2, 4)
+	for j := 0; j < height/4; j++ {
+		copy(data[j*rowSize2:], pixels[j*rowSize:(j+1)*rowSize])
+	}
+
+	r := restorable.NewCompressedImage(data, width, height, f, glFilter(filter))
+	i := &Image{restorable: r}
+	runtime.SetFinalizer(i, (*Image).Dispose)
+	return i, nil
+}
+
+// isCompressed reports whether the image is created by NewCompressedImage.
+func (i *Image) isCompressed() bool {
+	r := i.restorableImage()
+	return r != nil && r.IsCompressed()
+}
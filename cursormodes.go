@@ -0,0 +1,35 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// A CursorModeType represents how the mouse cursor behaves on the game window.
+type CursorModeType int
+
+// CursorModes
+const (
+	// CursorModeVisible is the normal mode. The cursor is visible and can move freely.
+	CursorModeVisible CursorModeType = CursorModeType(ui.CursorModeVisible)
+
+	// CursorModeHidden hides the cursor while it is on the game window.
+	CursorModeHidden CursorModeType = CursorModeType(ui.CursorModeHidden)
+
+	// CursorModeCaptured hides the cursor and locks it to the game window.
+	// The cursor position is not meaningful in this mode. Use CursorDelta instead.
+	CursorModeCaptured CursorModeType = CursorModeType(ui.CursorModeCaptured)
+)
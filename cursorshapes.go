@@ -0,0 +1,32 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// A CursorShape represents a standard shape of the mouse cursor provided by the system.
+type CursorShape int
+
+// CursorShapes
+const (
+	CursorShapeDefault   CursorShape = CursorShape(ui.CursorShapeDefault)
+	CursorShapeText      CursorShape = CursorShape(ui.CursorShapeText)
+	CursorShapeCrosshair CursorShape = CursorShape(ui.CursorShapeCrosshair)
+	CursorShapePointer   CursorShape = CursorShape(ui.CursorShapePointer)
+	CursorShapeEWResize  CursorShape = CursorShape(ui.CursorShapeEWResize)
+	CursorShapeNSResize  CursorShape = CursorShape(ui.CursorShapeNSResize)
+)
@@ -0,0 +1,64 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+)
+
+// DebugInfo represents the information about the graphics environment.
+type DebugInfo struct {
+	// GraphicsLibrary is the name of the graphics library: "OpenGL", "OpenGL ES" or "WebGL".
+	GraphicsLibrary string
+
+	// Vendor, Renderer, Version and ShadingLanguageVersion are the strings reported by the graphics driver.
+	// Their formats depend on the driver.
+	Vendor                 string
+	Renderer               string
+	Version                string
+	ShadingLanguageVersion string
+
+	// MaxTextureSize is the maximum width/height of a texture that the graphics driver supports.
+	// If MaxTextureSize is less than MaxImageSize, images bigger than MaxTextureSize are not rendered correctly.
+	MaxTextureSize int
+
+	// Antialias reports whether anti-aliased images are available (see NewImageOptions.Antialias).
+	Antialias bool
+
+	// HDR reports whether HDR images are available (see NewImageOptions.HDR).
+	HDR bool
+
+	// GammaCorrection reports whether rendering is gamma-correct (see SetGammaCorrectionEnabled).
+	GammaCorrection bool
+}
+
+// ReadDebugInfo writes the information about the graphics environment to d.
+//
+// This is useful e.g. to warn about old drivers, or to choose the sizes of assets according to MaxTextureSize.
+//
+// ReadDebugInfo can't be called before the main loop (ebiten.Run) starts.
+func ReadDebugInfo(d *DebugInfo) {
+	c := opengl.GetContext()
+	i := c.DriverInfo()
+	d.GraphicsLibrary = i.API
+	d.Vendor = i.Vendor
+	d.Renderer = i.Renderer
+	d.Version = i.Version
+	d.ShadingLanguageVersion = i.ShadingLanguageVersion
+	d.MaxTextureSize = i.MaxTextureSize
+	d.Antialias = c.MaxSamples() > 0
+	d.HDR = c.IsFloatTextureAvailable()
+	d.GammaCorrection = c.IsSRGBEnabled()
+}
@@ -0,0 +1,36 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+)
+
+func TestReadDebugInfo(t *testing.T) {
+	var d DebugInfo
+	ReadDebugInfo(&d)
+	if d.GraphicsLibrary == "" {
+		t.Errorf("GraphicsLibrary must not be empty")
+	}
+	if d.Version == "" {
+		t.Errorf("Version must not be empty")
+	}
+	// OpenGL 2.1, OpenGL ES 2.0 and WebGL 1 require 64 at least.
+	if d.MaxTextureSize < 64 {
+		t.Errorf("MaxTextureSize: got %d, want: >= 64", d.MaxTextureSize)
+	}
+}
@@ -23,4 +23,49 @@
 //     func main() {
 //         ebiten.Run(update, 320, 240, 2, "Your game's title")
 //     }
+//
+// Alternatively, you can start the game by calling the function RunGame with a Game,
+// which separates updating the game logic from drawing the screen:
+//
+//     type Game struct{}
+//
+//     func (g *Game) Update() error {
+//         // Update the game logic by one tick.
+//         return nil
+//     }
+//
+//     func (g *Game) Draw(screen *ebiten.Image) {
+//         // Draw the game screen.
+//     }
+//
+//     func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+//         return 320, 240
+//     }
+//
+//     func main() {
+//         ebiten.SetWindowSize(640, 480)
+//         ebiten.SetWindowTitle("Your game's title")
+//         ebiten.RunGame(&Game{})
+//     }
+//
+// All the rendering is done by drawing images onto images. The screen is also an image.
+// To render offscreen, create an image by NewImage, draw onto it and then draw it onto the screen:
+//
+//     offscreen, _ := ebiten.NewImage(320, 240, ebiten.FilterNearest)
+//
+//     func update(screen *ebiten.Image) error {
+//         offscreen.Clear()
+//         offscreen.DrawImage(sprite, nil)
+//         screen.DrawImage(offscreen, nil)
+//         return nil
+//     }
+//
+// On desktops, Ebiten uses OpenGL 2.1 by default.
+// With the build tag 'gles', Ebiten creates an OpenGL ES 2.0 context via EGL instead:
+//
+//     go build -tags gles
+//
+// This is useful for systems without desktop OpenGL drivers, e.g. some single board computers on Linux.
+// On Windows, ANGLE's libEGL.dll and libGLESv2.dll are required instead of OpenGL drivers.
+// The 'gles' tag is not supported on macOS.
 package ebiten
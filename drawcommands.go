@@ -0,0 +1,298 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/restorable"
+)
+
+// DrawCommandImage represents an image used by a DrawCommand.
+//
+// The images Ebiten creates internally, like the screen framebuffer and the atlases that small images share,
+// also appear in DrawCommands. A small image is recorded as its atlas.
+type DrawCommandImage struct {
+	// ID identifies the image. An image has the same ID across frames.
+	ID int `json:"id"`
+
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	// Screen indicates whether the image is the screen framebuffer.
+	Screen bool `json:"screen,omitempty"`
+}
+
+// DrawCommand represents a drawing command executed on the GPU.
+//
+// DrawCommand can be encoded with encoding/json.
+type DrawCommand struct {
+	// Type is "fill", "draw" or "replace-pixels".
+	Type string `json:"type"`
+
+	Destination DrawCommandImage `json:"destination"`
+
+	// Sources is the source images for "draw":
+	// the source image, the other images of the custom shader, the palette and the mask in this order.
+	Sources []DrawCommandImage `json:"sources,omitempty"`
+
+	// Bounds is the bounding rectangle of the vertices on the destination for "draw",
+	// or the replaced region for "replace-pixels".
+	// For an image in an atlas, Bounds is in the atlas's coordinates.
+	Bounds image.Rectangle `json:"bounds"`
+
+	// Triangles is the number of the triangles for "draw".
+	// Consecutive draws with the same parameters are merged into one command.
+	Triangles int `json:"triangles,omitempty"`
+
+	// ColorM is the elements of the color matrix in row-major order for "draw".
+	// ColorM is nil when the matrix is identity.
+	ColorM []float64 `json:"colorM,omitempty"`
+
+	// MaxAlpha is the maximum of the alpha scales of the vertices for "draw".
+	// If MaxAlpha is 0, nothing is rendered with the regular composite modes.
+	MaxAlpha float64 `json:"maxAlpha,omitempty"`
+
+	// Blend is the name of the composite mode like "source-over", or "custom" for a custom Blend.
+	Blend string `json:"blend,omitempty"`
+
+	// Filter is "nearest", "linear", "linear-mipmap" or "anisotropic".
+	Filter string `json:"filter,omitempty"`
+
+	// Address is "default", "repeat" or "mirrored-repeat".
+	Address string `json:"address,omitempty"`
+
+	// Clip is the region of the destination that can be rendered. An empty Clip means the whole destination.
+	Clip image.Rectangle `json:"clip"`
+
+	// Shader indicates whether a custom shader is used.
+	Shader bool `json:"shader,omitempty"`
+
+	// Paletted indicates whether the source image is paletted.
+	Paletted bool `json:"paletted,omitempty"`
+
+	// Masked indicates whether the result is masked (see DrawImageOptions.Mask).
+	Masked bool `json:"masked,omitempty"`
+
+	// Color is the color for "fill".
+	Color color.RGBA `json:"color"`
+}
+
+var compositeModeNames = []string{
+	CompositeModeSourceOver:      "source-over",
+	CompositeModeClear:           "clear",
+	CompositeModeCopy:            "copy",
+	CompositeModeDestination:     "destination",
+	CompositeModeDestinationOver: "destination-over",
+	CompositeModeSourceIn:        "source-in",
+	CompositeModeDestinationIn:   "destination-in",
+	CompositeModeSourceOut:       "source-out",
+	CompositeModeDestinationOut:  "destination-out",
+	CompositeModeSourceAtop:      "source-atop",
+	CompositeModeDestinationAtop: "destination-atop",
+	CompositeModeXor:             "xor",
+	CompositeModeLighter:         "lighter",
+	CompositeModeMultiply:        "multiply",
+}
+
+var addressNames = []string{
+	AddressDefault:        "default",
+	AddressRepeat:         "repeat",
+	AddressMirroredRepeat: "mirrored-repeat",
+}
+
+func blendName(b opengl.Blend) string {
+	for m, n := range compositeModeNames {
+		if opengl.CompositeMode(m).Blend() == b {
+			return n
+		}
+	}
+	return "custom"
+}
+
+func filterName(f opengl.Filter) string {
+	switch f {
+	case opengl.Nearest:
+		return "nearest"
+	case opengl.Linear:
+		return "linear"
+	case opengl.LinearMipmap:
+		return "linear-mipmap"
+	case opengl.Anisotropic:
+		return "anisotropic"
+	}
+	panic("not reached")
+}
+
+var theDrawCommandCapture = &drawCommandCapture{}
+
+type drawCommandCapture struct {
+	enabled   bool
+	lastFrame []DrawCommand
+	m         sync.Mutex
+}
+
+// SetDrawCommandCaptureEnabled sets whether the drawing commands of each frame are recorded.
+//
+// This is a debugging feature to find out e.g. why an image is not rendered:
+// the destination, the region, the alpha or the composite mode might be unexpected.
+// Recording commands is slow, and capturing should be disabled in production.
+//
+// Capturing is disabled by default.
+//
+// This function is concurrent-safe.
+func SetDrawCommandCaptureEnabled(enabled bool) {
+	theDrawCommandCapture.m.Lock()
+	theDrawCommandCapture.enabled = enabled
+	if !enabled {
+		theDrawCommandCapture.lastFrame = nil
+	}
+	restorable.SetCaptureEnabled(enabled)
+	theDrawCommandCapture.m.Unlock()
+}
+
+// IsDrawCommandCaptureEnabled reports whether the drawing commands of each frame are recorded.
+//
+// This function is concurrent-safe.
+func IsDrawCommandCaptureEnabled() bool {
+	theDrawCommandCapture.m.Lock()
+	e := theDrawCommandCapture.enabled
+	theDrawCommandCapture.m.Unlock()
+	return e
+}
+
+// LastFrameDrawCommands returns the drawing commands executed in the last frame
+// while SetDrawCommandCaptureEnabled(true) is set.
+//
+// The commands include the ones Ebiten executes internally, e.g. to render the offscreen to the screen.
+// The commands of the current frame are not available until the frame ends.
+//
+// This function is concurrent-safe.
+func LastFrameDrawCommands() []DrawCommand {
+	theDrawCommandCapture.m.Lock()
+	cs := make([]DrawCommand, len(theDrawCommandCapture.lastFrame))
+	copy(cs, theDrawCommandCapture.lastFrame)
+	theDrawCommandCapture.m.Unlock()
+	return cs
+}
+
+// endFrame stores the commands recorded in the frame.
+func (d *drawCommandCapture) endFrame() {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if !d.enabled {
+		return
+	}
+	d.lastFrame = d.lastFrame[:0]
+	for _, c := range restorable.TakeCapturedCommands() {
+		cmd := DrawCommand{
+			Type:        c.Type,
+			Destination: DrawCommandImage(c.Dst),
+			Bounds:      c.Bounds,
+			Color:       c.Color,
+		}
+		if c.Type == "draw" {
+			for _, s := range c.Srcs {
+				cmd.Sources = append(cmd.Sources, DrawCommandImage(s))
+			}
+			cmd.Triangles = c.Triangles
+			cmd.ColorM = c.ColorM
+			cmd.MaxAlpha = float64(c.MaxAlpha)
+			cmd.Blend = blendName(c.Blend)
+			cmd.Filter = filterName(c.Filter)
+			cmd.Address = addressNames[c.Address]
+			cmd.Clip = c.DstRegion
+			cmd.Shader = c.Shader
+			cmd.Paletted = c.Paletted
+			cmd.Masked = c.Masked
+		}
+		d.lastFrame = append(d.lastFrame, cmd)
+	}
+}
+
+func (i DrawCommandImage) String() string {
+	if i.Screen {
+		return fmt.Sprintf("#%d(screen %dx%d)", i.ID, i.Width, i.Height)
+	}
+	return fmt.Sprintf("#%d(%dx%d)", i.ID, i.Width, i.Height)
+}
+
+// String returns the command in one line.
+func (c *DrawCommand) String() string {
+	s := []string{c.Type, "dst=" + c.Destination.String()}
+	switch c.Type {
+	case "fill":
+		s = append(s, fmt.Sprintf("color=(%d,%d,%d,%d)", c.Color.R, c.Color.G, c.Color.B, c.Color.A))
+	case "replace-pixels":
+		s = append(s, fmt.Sprintf("bounds=%v", c.Bounds))
+	case "draw":
+		if len(c.Sources) > 0 {
+			srcs := []string{}
+			for _, src := range c.Sources {
+				srcs = append(srcs, src.String())
+			}
+			s = append(s, "srcs=["+strings.Join(srcs, " ")+"]")
+		}
+		s = append(s, fmt.Sprintf("bounds=%v", c.Bounds))
+		s = append(s, fmt.Sprintf("triangles=%d", c.Triangles))
+		s = append(s, fmt.Sprintf("alpha=%g", c.MaxAlpha))
+		s = append(s, "blend="+c.Blend)
+		s = append(s, "filter="+c.Filter)
+		if c.Address != addressNames[AddressDefault] {
+			s = append(s, "address="+c.Address)
+		}
+		if !c.Clip.Empty() {
+			s = append(s, fmt.Sprintf("clip=%v", c.Clip))
+		}
+		if c.ColorM != nil {
+			s = append(s, fmt.Sprintf("colorm=%v", c.ColorM))
+		}
+		if c.Shader {
+			s = append(s, "shader")
+		}
+		if c.Paletted {
+			s = append(s, "paletted")
+		}
+		if c.Masked {
+			s = append(s, "masked")
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// DumpDrawCommands writes the commands to w as text, one command per line.
+func DumpDrawCommands(w io.Writer, commands []DrawCommand) error {
+	for i := range commands {
+		if _, err := fmt.Fprintf(w, "%d: %s\n", i, commands[i].String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DumpDrawCommandsJSON writes the commands to w as a JSON array.
+func DumpDrawCommandsJSON(w io.Writer, commands []DrawCommand) error {
+	if commands == nil {
+		commands = []DrawCommand{}
+	}
+	return json.NewEncoder(w).Encode(commands)
+}
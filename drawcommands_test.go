@@ -0,0 +1,72 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+)
+
+func TestDumpDrawCommands(t *testing.T) {
+	cs := []DrawCommand{
+		{
+			Type:        "fill",
+			Destination: DrawCommandImage{ID: 1, Width: 320, Height: 240},
+		},
+		{
+			Type:        "draw",
+			Destination: DrawCommandImage{ID: 2, Width: 640, Height: 480, Screen: true},
+			Sources:     []DrawCommandImage{{ID: 1, Width: 320, Height: 240}},
+			Bounds:      image.Rect(0, 0, 640, 480),
+			Triangles:   2,
+			MaxAlpha:    1,
+			Blend:       "source-over",
+			Filter:      "nearest",
+			Address:     "default",
+		},
+	}
+
+	var text bytes.Buffer
+	if err := DumpDrawCommands(&text, cs); err != nil {
+		t.Fatal(err)
+	}
+	want := `0: fill dst=#1(320x240) color=(0,0,0,0)
+1: draw dst=#2(screen 640x480) srcs=[#1(320x240)] bounds=(0,0)-(640,480) triangles=2 alpha=1 blend=source-over filter=nearest
+`
+	if got := text.String(); got != want {
+		t.Errorf("DumpDrawCommands: got: %q, want: %q", got, want)
+	}
+
+	var j bytes.Buffer
+	if err := DumpDrawCommandsJSON(&j, cs); err != nil {
+		t.Fatal(err)
+	}
+	var got []DrawCommand
+	if err := json.Unmarshal(j.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(cs) {
+		t.Fatalf("len(got): got: %d, want: %d", len(got), len(cs))
+	}
+	for i := range cs {
+		if got[i].String() != cs[i].String() {
+			t.Errorf("got[%d]: got: %s, want: %s", i, got[i].String(), cs[i].String())
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebitenutil
+
+import (
+	"sync"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// ditherFuncSrc is the function to calculate the threshold of ordered dithering at a pixel.
+//
+// The threshold is from the 4x4 Bayer matrix, and is in the range (-0.5, 0.5).
+const ditherFuncSrc = `
+func ditherThreshold(position vec4) float {
+	p := floor(position.xy)
+	x0 := mod(p.x, 2)
+	y0 := mod(p.y, 2)
+	x1 := floor(mod(p.x, 4) / 2)
+	y1 := floor(mod(p.y, 4) / 2)
+	b := 4*(2*abs(x0-y0)+y0) + 2*abs(x1-y1) + y1
+	return (b+0.5)/16 - 0.5
+}
+`
+
+const ditherShaderSrc = `package main
+
+var Strength float
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	c := imageSrc0At(texCoord)
+	if c.a <= 0 {
+		return vec4(0)
+	}
+	rgb := c.rgb / c.a
+	rgb = clamp(rgb+ditherThreshold(position)*Strength/255, 0, 1)
+	a := clamp(c.a, 0, 1)
+	return vec4(rgb*a, a)
+}
+` + ditherFuncSrc
+
+var (
+	ditherShader  *ebiten.Shader
+	ditherShaderM sync.Mutex
+)
+
+// DitherOptions represents options for DrawDithered.
+type DitherOptions struct {
+	// GeoM is a geometry matrix to draw.
+	GeoM ebiten.GeoM
+
+	// Strength is the amplitude of the dithering in the 8-bit steps.
+	// The default (zero) value means 1, which is enough to remove banding.
+	Strength float64
+
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	CompositeMode ebiten.CompositeMode
+}
+
+// DrawDithered draws src on dst with ordered dithering.
+//
+// When an image with smooth gradients is drawn on an image with 8-bit channels like the screen,
+// the colors are quantized and the gradients can have visible bands, e.g. in skies and vignettes.
+// DrawDithered adds the threshold of a 4x4 Bayer matrix to the colors before the quantization
+// so that the bands turn into a fine pattern, which is hardly visible.
+//
+// Dithering is effective when src has more precision than dst, i.e. src is an HDR image
+// (see ebiten.NewImageOptions.HDR). Drawing a regular image on a regular image with DrawDithered
+// just adds a noise. To draw an HDR image with tone mapping, use ToneMapOptions.Dither instead.
+//
+// op can be nil, which means the default options.
+func DrawDithered(dst, src *ebiten.Image, op *DitherOptions) {
+	if op == nil {
+		op = &DitherOptions{}
+	}
+	ditherShaderM.Lock()
+	if ditherShader == nil {
+		s, err := ebiten.NewShader([]byte(ditherShaderSrc))
+		if err != nil {
+			ditherShaderM.Unlock()
+			panic(err)
+		}
+		ditherShader = s
+	}
+	s := ditherShader
+	ditherShaderM.Unlock()
+
+	strength := op.Strength
+	if strength == 0 {
+		strength = 1
+	}
+	w, h := src.Size()
+	sop := &ebiten.DrawRectShaderOptions{
+		GeoM:          op.GeoM,
+		CompositeMode: op.CompositeMode,
+		Uniforms: map[string]interface{}{
+			"Strength": strength,
+		},
+	}
+	sop.Images[0] = src
+	dst.DrawRectShader(w, h, s, sop)
+}
@@ -50,7 +50,11 @@ func init() {
 }
 
 func (r *recorder) delay() int {
-	delay := 100 * r.skips / ebiten.FPS
+	tps := ebiten.MaxTPS()
+	if tps <= 0 {
+		tps = ebiten.FPS
+	}
+	delay := 100 * r.skips / tps
 	if delay < 2 {
 		return 2
 	}
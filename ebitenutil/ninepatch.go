@@ -0,0 +1,123 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebitenutil
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// NinePatch represents an image divided into nine parts by its borders,
+// which can be drawn at arbitrary sizes for UI elements like panels and buttons.
+//
+// When a NinePatch is drawn, the corners are drawn as they are, the edges are stretched along one direction
+// and the center is stretched (or tiled) along both directions.
+type NinePatch struct {
+	// Image is the source image.
+	// A sub-image can be used to take a part of a UI atlas.
+	Image *ebiten.Image
+
+	// Left, Top, Right and Bottom are the widths of the borders in pixels.
+	Left   int
+	Top    int
+	Right  int
+	Bottom int
+
+	// TileCenter indicates whether the center part is repeated instead of stretched.
+	TileCenter bool
+}
+
+// Draw draws the nine-patch image in the rectangle (x, y, width, height) on dst.
+//
+// If width or height is less than the sum of the borders, the borders are shrunk proportionally.
+//
+// options can be nil, which means the default options.
+func (n *NinePatch) Draw(dst *ebiten.Image, x, y, width, height float64, options *ebiten.DrawTrianglesOptions) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	b := n.Image.Bounds()
+
+	l, r := float64(n.Left), float64(n.Right)
+	if l+r > width {
+		s := width / (l + r)
+		l, r = l*s, r*s
+	}
+	t, bt := float64(n.Top), float64(n.Bottom)
+	if t+bt > height {
+		s := height / (t + bt)
+		t, bt = t*s, bt*s
+	}
+
+	dxs := [4]float64{x, x + l, x + width - r, x + width}
+	dys := [4]float64{y, y + t, y + height - bt, y + height}
+	sxs := [4]float64{float64(b.Min.X), float64(b.Min.X + n.Left), float64(b.Max.X - n.Right), float64(b.Max.X)}
+	sys := [4]float64{float64(b.Min.Y), float64(b.Min.Y + n.Top), float64(b.Max.Y - n.Bottom), float64(b.Max.Y)}
+
+	// The vertex indices must be within the range of uint16.
+	const maxQuadsNum = (1 << 16) / 4
+
+	var vs []ebiten.Vertex
+	var is []uint16
+	quad := func(dx0, dy0, dx1, dy1, sx0, sy0, sx1, sy1 float64) {
+		if dx0 >= dx1 || dy0 >= dy1 || sx0 >= sx1 || sy0 >= sy1 {
+			return
+		}
+		idx := uint16(len(vs) % (4 * maxQuadsNum))
+		vs = append(vs,
+			ebiten.Vertex{DstX: float32(dx0), DstY: float32(dy0), SrcX: float32(sx0), SrcY: float32(sy0), ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+			ebiten.Vertex{DstX: float32(dx1), DstY: float32(dy0), SrcX: float32(sx1), SrcY: float32(sy0), ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+			ebiten.Vertex{DstX: float32(dx0), DstY: float32(dy1), SrcX: float32(sx0), SrcY: float32(sy1), ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+			ebiten.Vertex{DstX: float32(dx1), DstY: float32(dy1), SrcX: float32(sx1), SrcY: float32(sy1), ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		)
+		is = append(is, idx, idx+1, idx+2, idx+1, idx+2, idx+3)
+	}
+
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			if i != 1 || j != 1 || !n.TileCenter {
+				quad(dxs[i], dys[j], dxs[i+1], dys[j+1], sxs[i], sys[j], sxs[i+1], sys[j+1])
+				continue
+			}
+			// Repeat the center part. The last tiles in each direction are cut.
+			tw, th := sxs[2]-sxs[1], sys[2]-sys[1]
+			if tw <= 0 || th <= 0 {
+				continue
+			}
+			nx := int(math.Ceil((dxs[2] - dxs[1]) / tw))
+			ny := int(math.Ceil((dys[2] - dys[1]) / th))
+			for ty := 0; ty < ny; ty++ {
+				dy0 := dys[1] + float64(ty)*th
+				dy1 := math.Min(dy0+th, dys[2])
+				for tx := 0; tx < nx; tx++ {
+					dx0 := dxs[1] + float64(tx)*tw
+					dx1 := math.Min(dx0+tw, dxs[2])
+					quad(dx0, dy0, dx1, dy1, sxs[1], sys[1], sxs[1]+dx1-dx0, sys[1]+dy1-dy0)
+				}
+			}
+		}
+	}
+
+	for len(vs) > 0 {
+		q := len(vs) / 4
+		if q > maxQuadsNum {
+			q = maxQuadsNum
+		}
+		dst.DrawTriangles(vs[:4*q], is[:6*q], n.Image, options)
+		vs = vs[4*q:]
+		is = is[6*q:]
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebitenutil
+
+import (
+	"image/png"
+	"io"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// WriteScreenshotAsPNG takes a screenshot with ebiten.Screenshot and writes it to out as a PNG image.
+//
+// As well as ebiten.Screenshot, call this at the end of the update function after rendering the screen.
+//
+// Here is the example to save a screenshot when the P key is pressed:
+//
+//     func update(screen *ebiten.Image) error {
+//         // Render the screen...
+//
+//         if ebiten.IsKeyPressed(ebiten.KeyP) {
+//             out, err := os.Create("screenshot.png")
+//             if err != nil {
+//                 return err
+//             }
+//             defer out.Close()
+//             if err := ebitenutil.WriteScreenshotAsPNG(out); err != nil {
+//                 return err
+//             }
+//         }
+//         return nil
+//     }
+func WriteScreenshotAsPNG(out io.Writer) error {
+	img, err := ebiten.Screenshot()
+	if err != nil {
+		return err
+	}
+	return png.Encode(out, img)
+}
@@ -0,0 +1,129 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebitenutil
+
+import (
+	"sync"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// ToneMapOperator represents a curve to map HDR colors to the range [0, 1].
+type ToneMapOperator int
+
+const (
+	// ToneMapReinhard maps a color c to c / (1 + c).
+	ToneMapReinhard ToneMapOperator = iota
+
+	// ToneMapExponential maps a color c to 1 - exp(-c).
+	ToneMapExponential
+
+	// ToneMapClamp clamps a color to 1.
+	ToneMapClamp
+)
+
+const toneMapShaderSrc = `package main
+
+var Exposure float
+var Operator float
+var Dither float
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	c := imageSrc0At(texCoord)
+	if c.a <= 0 {
+		return vec4(0)
+	}
+	rgb := c.rgb / c.a * Exposure
+	if Operator == 0 {
+		rgb = rgb / (1 + rgb)
+	}
+	if Operator == 1 {
+		rgb = 1 - exp(-rgb)
+	}
+	rgb = clamp(rgb+ditherThreshold(position)*Dither/255, 0, 1)
+	a := clamp(c.a, 0, 1)
+	return vec4(rgb*a, a)
+}
+` + ditherFuncSrc
+
+var (
+	toneMapShader  *ebiten.Shader
+	toneMapShaderM sync.Mutex
+)
+
+// ToneMapOptions represents options for DrawToneMapped.
+type ToneMapOptions struct {
+	// GeoM is a geometry matrix to draw.
+	GeoM ebiten.GeoM
+
+	// Exposure is the scale applied to the colors before the tone mapping.
+	// The default (zero) value means 1.
+	Exposure float64
+
+	// Operator is the curve of the tone mapping.
+	// The default (zero) value is ToneMapReinhard.
+	Operator ToneMapOperator
+
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	CompositeMode ebiten.CompositeMode
+
+	// Dither indicates whether the mapped colors are dithered to remove banding. See DrawDithered.
+	Dither bool
+}
+
+// DrawToneMapped draws the HDR image src on dst mapping the colors to the range [0, 1].
+//
+// This is typically used to draw the result of lighting accumulation or bloom on the screen.
+// src can also be a regular image.
+//
+// op can be nil, which means the default options.
+func DrawToneMapped(dst, src *ebiten.Image, op *ToneMapOptions) {
+	if op == nil {
+		op = &ToneMapOptions{}
+	}
+	toneMapShaderM.Lock()
+	if toneMapShader == nil {
+		s, err := ebiten.NewShader([]byte(toneMapShaderSrc))
+		if err != nil {
+			toneMapShaderM.Unlock()
+			panic(err)
+		}
+		toneMapShader = s
+	}
+	s := toneMapShader
+	toneMapShaderM.Unlock()
+
+	exposure := op.Exposure
+	if exposure == 0 {
+		exposure = 1
+	}
+	dither := 0
+	if op.Dither {
+		dither = 1
+	}
+	w, h := src.Size()
+	sop := &ebiten.DrawRectShaderOptions{
+		GeoM:          op.GeoM,
+		CompositeMode: op.CompositeMode,
+		Uniforms: map[string]interface{}{
+			"Exposure": exposure,
+			"Operator": int(op.Operator),
+			"Dither":   dither,
+		},
+	}
+	sop.Images[0] = src
+	dst.DrawRectShader(w, h, s, sop)
+}
@@ -166,8 +166,8 @@ func (p *Player) update() error {
 	p.updateSE()
 	p.updateVolume()
 	if p.input.isKeyTriggered(ebiten.KeyB) {
-		b := ebiten.IsRunnableInBackground()
-		ebiten.SetRunnableInBackground(!b)
+		b := ebiten.IsRunnableOnUnfocused()
+		ebiten.SetRunnableOnUnfocused(!b)
 	}
 	if err := p.audioContext.Update(); err != nil {
 		return err
@@ -259,7 +259,7 @@ func (p *Player) draw(screen *ebiten.Image) {
 Press S to toggle Play/Pause
 Press P to play SE
 Press Z or X to change volume of the music
-Press B to switch the run-in-background state
+Press B to switch the run-on-unfocused state
 %s`, ebiten.CurrentFPS(), currentTimeStr)
 	ebitenutil.DebugPrint(screen, msg)
 }
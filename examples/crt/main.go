@@ -0,0 +1,99 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+// +build example
+
+package main
+
+import (
+	_ "image/jpeg"
+	"log"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+const (
+	screenWidth  = 320
+	screenHeight = 240
+)
+
+const shaderSrc = `package main
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	clr := imageSrc0At(texCoord)
+
+	// Darken the lower half of each pixel row like scanlines of a CRT display.
+	size := imageSrcTextureSize()
+	if fract(texCoord.y*size.y) > 0.5 {
+		clr.rgb *= 0.6
+	}
+	return clr
+}
+`
+
+var (
+	gophersImage *ebiten.Image
+	shader       *ebiten.Shader
+	crt          = true
+)
+
+// drawCRT draws the game screen onto the window with the scanline effect.
+func drawCRT(screen *ebiten.Image, offscreen *ebiten.Image) {
+	sw, sh := screen.Size()
+	w, h := offscreen.Size()
+	op := &ebiten.DrawRectShaderOptions{}
+	op.GeoM.Scale(float64(sw)/float64(w), float64(sh)/float64(h))
+	op.Images[0] = offscreen
+	screen.DrawRectShader(w, h, shader, op)
+}
+
+func update(screen *ebiten.Image) error {
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		crt = !crt
+		if crt {
+			ebiten.SetFinalScreenDrawer(drawCRT)
+		} else {
+			ebiten.SetFinalScreenDrawer(nil)
+		}
+	}
+	if ebiten.IsRunningSlowly() {
+		return nil
+	}
+
+	w, h := gophersImage.Size()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(screenWidth-w)/2, float64(screenHeight-h)/2)
+	screen.DrawImage(gophersImage, op)
+	ebitenutil.DebugPrint(screen, "Press Space to switch the CRT effect")
+	return nil
+}
+
+func main() {
+	var err error
+	gophersImage, _, err = ebitenutil.NewImageFromFile("_resources/images/gophers.jpg", ebiten.FilterNearest)
+	if err != nil {
+		log.Fatal(err)
+	}
+	shader, err = ebiten.NewShader([]byte(shaderSrc))
+	if err != nil {
+		log.Fatal(err)
+	}
+	ebiten.SetFinalScreenDrawer(drawCRT)
+	if err := ebiten.Run(update, screenWidth, screenHeight, 3, "CRT (Ebiten Demo)"); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -31,22 +31,29 @@ const (
 )
 
 var (
-	count        int
 	gophersImage *ebiten.Image
 )
 
-func update(screen *ebiten.Image) error {
-	count++
-	if ebiten.IsRunningSlowly() {
-		return nil
-	}
+type game struct {
+	count int
+}
+
+func (g *game) Update() error {
+	g.count++
+	return nil
+}
+
+func (g *game) Draw(screen *ebiten.Image) {
 	w, h := gophersImage.Size()
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
-	op.GeoM.Rotate(float64(count%360) * 2 * math.Pi / 360)
+	op.GeoM.Rotate(float64(g.count%360) * 2 * math.Pi / 360)
 	op.GeoM.Translate(screenWidth/2, screenHeight/2)
 	screen.DrawImage(gophersImage, op)
-	return nil
+}
+
+func (g *game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return screenWidth, screenHeight
 }
 
 func main() {
@@ -55,7 +62,9 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	if err := ebiten.Run(update, screenWidth, screenHeight, 2, "Rotate (Ebiten Demo)"); err != nil {
+	ebiten.SetWindowSize(screenWidth*2, screenHeight*2)
+	ebiten.SetWindowTitle("Rotate (Ebiten Demo)")
+	if err := ebiten.RunGame(&game{}); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,98 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build example
+
+package main
+
+import (
+	_ "image/jpeg"
+	"log"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+const (
+	screenWidth  = 320
+	screenHeight = 240
+)
+
+const shaderSrc = `package main
+
+var Time float
+var Cursor vec2
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	// Shift the texture coordinate like waves.
+	size := imageSrcTextureSize()
+	texCoord.x += sin(position.y/16+Time) * 4 / size.x
+
+	clr := imageSrc0At(texCoord)
+
+	// Lighten the area around the cursor.
+	d := distance(position.xy, Cursor)
+	clr.rgb *= 1 + max(0, 1-d/64)
+	return clr * color
+}
+`
+
+var (
+	count        int
+	gophersImage *ebiten.Image
+	shader       *ebiten.Shader
+)
+
+func update(screen *ebiten.Image) error {
+	count++
+	if ebiten.IsRunningSlowly() {
+		return nil
+	}
+
+	w, h := gophersImage.Size()
+	x0 := float32(screenWidth-w) / 2
+	y0 := float32(screenHeight-h) / 2
+	x1 := x0 + float32(w)
+	y1 := y0 + float32(h)
+	vs := []ebiten.Vertex{
+		{DstX: x0, DstY: y0, SrcX: 0, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: x1, DstY: y0, SrcX: float32(w), SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: x0, DstY: y1, SrcX: 0, SrcY: float32(h), ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: x1, DstY: y1, SrcX: float32(w), SrcY: float32(h), ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+	}
+	cx, cy := ebiten.CursorPosition()
+	op := &ebiten.DrawTrianglesShaderOptions{}
+	op.Uniforms = map[string]interface{}{
+		"Time":   float64(count) / 60,
+		"Cursor": []float32{float32(cx), float32(cy)},
+	}
+	op.Images[0] = gophersImage
+	screen.DrawTrianglesShader(vs, []uint16{0, 1, 2, 1, 2, 3}, shader, op)
+	return nil
+}
+
+func main() {
+	var err error
+	gophersImage, _, err = ebitenutil.NewImageFromFile("_resources/images/gophers.jpg", ebiten.FilterNearest)
+	if err != nil {
+		log.Fatal(err)
+	}
+	shader, err = ebiten.NewShader([]byte(shaderSrc))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ebiten.Run(update, screenWidth, screenHeight, 2, "Shader (Ebiten Demo)"); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build example
+
+package main
+
+import (
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/vector"
+)
+
+const (
+	screenWidth  = 320
+	screenHeight = 240
+)
+
+var count int
+
+func drawStar(screen *ebiten.Image, cx, cy, r float32, theta float64) {
+	var p vector.Path
+	for i := 0; i < 5; i++ {
+		a := theta + float64(i)*4*math.Pi/5
+		x := cx + r*float32(math.Cos(a))
+		y := cy + r*float32(math.Sin(a))
+		p.LineTo(x, y)
+	}
+	p.Close()
+	p.Fill(screen, &vector.FillOptions{
+		Color: color.RGBA{0xff, 0xcc, 0x33, 0xff},
+	})
+	p.Stroke(screen, &vector.StrokeOptions{
+		Color:    color.RGBA{0x80, 0x40, 0, 0x80},
+		Width:    4,
+		LineJoin: vector.LineJoinRound,
+	})
+}
+
+func drawWave(screen *ebiten.Image) {
+	var p vector.Path
+	p.MoveTo(20, 200)
+	for i := 0; i < 7; i++ {
+		x := float32(20 + i*40)
+		dy := float32(40 * math.Sin(float64(count)/30+float64(i)))
+		p.CubicTo(x+13, 200-dy, x+27, 200+dy, x+40, 200)
+	}
+	p.Stroke(screen, &vector.StrokeOptions{
+		Color:   color.RGBA{0x33, 0xcc, 0xff, 0xff},
+		Width:   6,
+		LineCap: vector.LineCapRound,
+	})
+}
+
+func drawPie(screen *ebiten.Image) {
+	sweep := float32(math.Pi * (1 + math.Sin(float64(count)/60)))
+	var p vector.Path
+	p.MoveTo(240, 80)
+	p.Arc(240, 80, 50, 0, sweep, vector.Clockwise)
+	p.Close()
+	p.Fill(screen, &vector.FillOptions{
+		Color: color.RGBA{0x66, 0xcc, 0x66, 0xff},
+	})
+	p.Stroke(screen, &vector.StrokeOptions{
+		Color:      color.White,
+		Width:      2,
+		LineCap:    vector.LineCapRound,
+		DashArray:  []float32{8, 6},
+		DashOffset: float32(count) / 4,
+	})
+}
+
+func update(screen *ebiten.Image) error {
+	count++
+	if ebiten.IsRunningSlowly() {
+		return nil
+	}
+	drawStar(screen, 90, 90, 60, float64(count)/120)
+	drawWave(screen)
+	drawPie(screen)
+	return nil
+}
+
+func main() {
+	if err := ebiten.Run(update, screenWidth, screenHeight, 2, "Vector (Ebiten Demo)"); err != nil {
+		log.Fatal(err)
+	}
+}
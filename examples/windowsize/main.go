@@ -28,6 +28,7 @@ import (
 
 	"github.com/hajimehoshi/ebiten"
 	"github.com/hajimehoshi/ebiten/ebitenutil"
+	"github.com/hajimehoshi/ebiten/inpututil"
 )
 
 func init() {
@@ -42,18 +43,7 @@ const (
 
 var (
 	gophersImage *ebiten.Image
-	keyStates    = map[ebiten.Key]int{
-		ebiten.KeyUp:    0,
-		ebiten.KeyDown:  0,
-		ebiten.KeyLeft:  0,
-		ebiten.KeyRight: 0,
-		ebiten.KeyS:     0,
-		ebiten.KeyF:     0,
-		ebiten.KeyB:     0,
-		ebiten.KeyC:     0,
-		ebiten.KeyI:     0,
-	}
-	count = 0
+	count        = 0
 )
 
 func createRandomIconImage() image.Image {
@@ -76,37 +66,32 @@ func createRandomIconImage() image.Image {
 }
 
 func update(screen *ebiten.Image) error {
-	for key := range keyStates {
-		if !ebiten.IsKeyPressed(key) {
-			keyStates[key] = 0
-			continue
-		}
-		keyStates[key]++
-	}
 	screenScale := ebiten.ScreenScale()
 	d := int(32 / screenScale)
 	screenWidth, screenHeight := screen.Size()
 	fullscreen := ebiten.IsFullscreen()
-	runnableInBackground := ebiten.IsRunnableInBackground()
+	runnableInBackground := ebiten.IsRunnableOnUnfocused()
 	cursorVisible := ebiten.IsCursorVisible()
+	decorated := ebiten.IsWindowDecorated()
+	integerScaling := ebiten.IsIntegerScalingEnabled()
 
-	if keyStates[ebiten.KeyUp] == 1 {
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
 		screenHeight += d
 	}
-	if keyStates[ebiten.KeyDown] == 1 {
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
 		if 16 < screenHeight && d < screenHeight {
 			screenHeight -= d
 		}
 	}
-	if keyStates[ebiten.KeyLeft] == 1 {
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
 		if 16 < screenWidth && d < screenWidth {
 			screenWidth -= d
 		}
 	}
-	if keyStates[ebiten.KeyRight] == 1 {
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
 		screenWidth += d
 	}
-	if keyStates[ebiten.KeyS] == 1 {
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
 		switch screenScale {
 		case 1:
 			screenScale = 1.5
@@ -118,22 +103,30 @@ func update(screen *ebiten.Image) error {
 			panic("not reached")
 		}
 	}
-	if keyStates[ebiten.KeyF] == 1 {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
 		fullscreen = !fullscreen
 	}
-	if keyStates[ebiten.KeyB] == 1 {
+	if inpututil.IsKeyJustPressed(ebiten.KeyB) {
 		runnableInBackground = !runnableInBackground
 	}
-	if keyStates[ebiten.KeyC] == 1 {
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
 		cursorVisible = !cursorVisible
 	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyD) {
+		decorated = !decorated
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		integerScaling = !integerScaling
+	}
 	ebiten.SetScreenSize(screenWidth, screenHeight)
 	ebiten.SetScreenScale(screenScale)
 	ebiten.SetFullscreen(fullscreen)
-	ebiten.SetRunnableInBackground(runnableInBackground)
+	ebiten.SetRunnableOnUnfocused(runnableInBackground)
 	ebiten.SetCursorVisibility(cursorVisible)
+	ebiten.SetWindowDecorated(decorated)
+	ebiten.SetIntegerScalingEnabled(integerScaling)
 
-	if keyStates[ebiten.KeyI] == 1 {
+	if inpututil.IsKeyJustPressed(ebiten.KeyI) {
 		ebiten.SetWindowIcon([]image.Image{createRandomIconImage()})
 	}
 
@@ -154,14 +147,18 @@ func update(screen *ebiten.Image) error {
 	screen.DrawImage(gophersImage, op)
 
 	x, y := ebiten.CursorPosition()
+	wx, wy := ebiten.WindowPosition()
 	msg := fmt.Sprintf(`Press arrow keys to change the window size
 Press S key to change the window scale
 Press F key to switch the fullscreen state
-Press B key to switch the run-in-background state
+Press B key to switch the run-on-unfocused state
 Press C key to switch the cursor visibility
 Press I key to change the window icon
+Press D key to switch the window decoration
+Press P key to switch the integer scaling in fullscreen mode
 Cursor: (%d, %d)
-FPS: %0.2f`, x, y, ebiten.CurrentFPS())
+Window position: (%d, %d)
+FPS: %0.2f`, x, y, wx, wy, ebiten.CurrentFPS())
 	ebitenutil.DebugPrint(screen, msg)
 	return nil
 }
@@ -0,0 +1,40 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// A FPSModeType represents how the rendering frames are scheduled.
+type FPSModeType int
+
+// FPSModes
+const (
+	// FPSModeVsyncOn is the default mode. The screen is rendered in sync with the display's refresh rate.
+	FPSModeVsyncOn FPSModeType = FPSModeType(ui.FPSModeVsyncOn)
+
+	// FPSModeVsyncOffMaximum disables vertical sync and renders the screen as fast as possible.
+	// This is useful e.g. for benchmarking or for measuring input latency.
+	FPSModeVsyncOffMaximum FPSModeType = FPSModeType(ui.FPSModeVsyncOffMaximum)
+
+	// FPSModeVsyncOffMinimum renders the screen only when needed, i.e. when an input event happens
+	// or ScheduleFrame is called. This is useful to save power e.g. for tools.
+	// Gamepads don't fire input events on desktops, so call ScheduleFrame when gamepad inputs are needed.
+	//
+	// In this mode, the game is updated only once per rendering frame,
+	// as the time between frames can be arbitrarily long.
+	FPSModeVsyncOffMinimum FPSModeType = FPSModeType(ui.FPSModeVsyncOffMinimum)
+)
@@ -0,0 +1,42 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/clock"
+)
+
+// A FrameSkipPolicyType represents how game updating and rendering behave when rendering falls behind TPS.
+type FrameSkipPolicyType int
+
+// FrameSkipPolicies
+const (
+	// FrameSkipPolicySkipDraw is the default policy.
+	// Rendering frames are skipped to keep game updating at TPS:
+	// game updating happens up to 3 times in one rendering frame and IsRunningSlowly returns true except for the last one.
+	// The rest of the accumulated ticks are carried over to the next frames,
+	// and they are discarded only when the game is too far behind.
+	FrameSkipPolicySkipDraw FrameSkipPolicyType = FrameSkipPolicyType(clock.FrameSkipPolicySkipDraw)
+
+	// FrameSkipPolicySlowDown never skips rendering frames.
+	// Game updating happens at most once in one rendering frame,
+	// so the simulation slows down when rendering is slower than TPS.
+	FrameSkipPolicySlowDown FrameSkipPolicyType = FrameSkipPolicyType(clock.FrameSkipPolicySlowDown)
+
+	// FrameSkipPolicyClamp is similar to FrameSkipPolicySkipDraw,
+	// but the accumulated ticks exceeding 3 in one rendering frame are discarded immediately
+	// instead of being carried over.
+	FrameSkipPolicyClamp FrameSkipPolicyType = FrameSkipPolicyType(clock.FrameSkipPolicyClamp)
+)
@@ -0,0 +1,204 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// Game defines the functions of a game.
+//
+// Game is passed to RunGame. Different from the function passed to Run,
+// Game separates updating the game logic from rendering.
+type Game interface {
+	// Update updates the game logic by one tick.
+	//
+	// Update is called MaxTPS times a second (60 by default) as the function passed to Run is.
+	// When rendering falls behind, the behavior depends on the frame skip policy (see SetFrameSkipPolicy).
+	Update() error
+
+	// Draw draws the game screen.
+	//
+	// Draw is called once every rendering frame, after Update is called zero or more times to keep MaxTPS.
+	// screen is the render target that represents the screen, and its size is the one returned by Layout.
+	// The content of screen is cleared before Draw is called.
+	// Use TickProgress to interpolate the game state between ticks.
+	Draw(screen *Image)
+
+	// Layout accepts the outside size, i.e. the actual window size in device-independent pixels
+	// (the monitor size in fullscreen mode, or the page size on browsers),
+	// and returns the game's logical screen size.
+	//
+	// The screen is scaled to fit the outside size keeping its aspect ratio.
+	// Layout is called at the start of every frame, so the screen size can be changed at any time,
+	// e.g. to keep the size fixed regardless of the window size, or to match the screen size with the window size.
+	//
+	// The returned width and height must be positive.
+	Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int)
+}
+
+const (
+	defaultWindowWidth  = 640
+	defaultWindowHeight = 480
+	defaultWindowTitle  = "Ebiten"
+)
+
+var theWindow = &window{
+	width:  defaultWindowWidth,
+	height: defaultWindowHeight,
+	title:  defaultWindowTitle,
+}
+
+// window is the state of the game window for RunGame.
+type window struct {
+	width  int
+	height int
+	title  string
+
+	// sizeRequested is true when the size is set by SetWindowSize and not applied to the window yet.
+	sizeRequested bool
+
+	m sync.Mutex
+}
+
+func (w *window) size() (int, int) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	return w.width, w.height
+}
+
+func (w *window) setSize(width, height int) {
+	w.m.Lock()
+	w.width = width
+	w.height = height
+	w.sizeRequested = true
+	w.m.Unlock()
+}
+
+// requestedSize returns the size set by SetWindowSize and true if the size is not applied to the window yet.
+func (w *window) requestedSize() (int, int, bool) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	return w.width, w.height, w.sizeRequested
+}
+
+// takeRequestedSize is the same as requestedSize, but marks the size as applied.
+func (w *window) takeRequestedSize() (int, int, bool) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	r := w.sizeRequested
+	w.sizeRequested = false
+	return w.width, w.height, r
+}
+
+func (w *window) getTitle() string {
+	w.m.Lock()
+	defer w.m.Unlock()
+	return w.title
+}
+
+func (w *window) setTitle(title string) {
+	w.m.Lock()
+	w.title = title
+	w.m.Unlock()
+}
+
+// outsideSize returns the outside size passed to Game's Layout.
+func outsideSize() (int, int) {
+	if w, h, ok := theWindow.requestedSize(); ok {
+		return w, h
+	}
+	if w, h := ui.OutsideSize(); w > 0 && h > 0 {
+		return w, h
+	}
+	return theWindow.size()
+}
+
+// WindowSize returns the window size passed to Game's Layout.
+//
+// The unit is device-independent pixel.
+// While the game is running, WindowSize returns the actual size of the window, or the size of the monitor
+// in fullscreen mode, or the size of the page on browsers.
+// The initial value is (640, 480).
+//
+// This function is concurrent-safe.
+func WindowSize() (width, height int) {
+	return outsideSize()
+}
+
+// SetWindowSize sets the window size passed to Game's Layout.
+//
+// The unit is device-independent pixel.
+// The actual window size is the screen size returned by Layout multiplied by the largest scale
+// that fits in the window size. Then, Layout receives the actual window size from the next frame.
+//
+// SetWindowSize is for RunGame. With Run, use SetScreenSize and SetScreenScale instead.
+//
+// SetWindowSize can be called before RunGame.
+//
+// This function is concurrent-safe.
+func SetWindowSize(width, height int) {
+	if width <= 0 || height <= 0 {
+		panic("ebiten: width and height must be positive")
+	}
+	theWindow.setSize(width, height)
+}
+
+// layout calls game's Layout with the outside size (ow, oh) and returns the screen size and scale.
+func layout(game Game, ow, oh int) (width, height int, scale float64) {
+	width, height = game.Layout(ow, oh)
+	if width <= 0 || height <= 0 {
+		panic("ebiten: Layout must return positive numbers")
+	}
+	scale = math.Min(float64(ow)/float64(width), float64(oh)/float64(height))
+	return width, height, scale
+}
+
+// RunGame runs the game.
+//
+// RunGame is the same as Run except for the following points.
+// The game logic and rendering are separated into Update and Draw, and Draw is called every
+// rendering frame even when no Update is called in the frame. The screen size is decided by Layout every frame instead of
+// being given as arguments. The window size is set by SetWindowSize, and the window title is set
+// by SetWindowTitle before RunGame.
+//
+// RunGame must be called from the OS main thread.
+//
+// RunGame returns error when 1) OpenGL error happens, or 2) Update returns error.
+// In the case of 2), RunGame returns the same error.
+//
+// Don't call RunGame or Run twice or more in one process.
+func RunGame(game Game) error {
+	ow, oh, _ := theWindow.takeRequestedSize()
+	w, h, s := layout(game, ow, oh)
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+
+		g := newGraphicsContextWithGame(game)
+		theGraphicsContext.Store(g)
+		if err := run(w, h, s, theWindow.getTitle(), g); err != nil {
+			ch <- err
+			return
+		}
+	}()
+	if err := ui.RunMainThreadLoop(ch); err != nil {
+		return err
+	}
+	return nil
+}
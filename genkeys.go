@@ -150,6 +150,17 @@ const (
 {{range $index, $name := .KeyNames}}Key{{$name}} Key = Key(ui.Key{{$name}})
 {{end}}	KeyMax Key = Key{{.LastKeyName}}
 )
+
+// String returns the name of the key without the prefix 'Key', e.g. "A" for KeyA or "Space" for KeySpace.
+//
+// The name doesn't depend on the keyboard layout. Use KeyName for a human-readable name.
+func (k Key) String() string {
+	switch k {
+	{{range $index, $name := .KeyNames}}case Key{{$name}}:
+		return {{printf "%q" $name}}
+	{{end}}}
+	return ""
+}
 `
 
 const uiKeysTmpl = `{{.License}}
@@ -162,7 +173,7 @@ type Key int
 
 const (
 {{range $index, $name := .KeyNames}}Key{{$name}}{{if eq $index 0}} Key = iota{{end}}
-{{end}}
+{{end}}	KeyMax = Key{{.LastKeyName}}
 )
 `
 
@@ -0,0 +1,318 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gesture provides a recognizer of touch gestures like taps, swipes and pinches.
+//
+// All the positions are in the same unit as ebiten.TouchPosition, and all the durations are in ticks.
+package gesture
+
+import (
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// EventType represents the type of a gesture event.
+type EventType int
+
+const (
+	// EventTap is emitted when a finger touches and leaves the screen quickly without moving.
+	EventTap EventType = iota
+
+	// EventDoubleTap is emitted when the second tap follows a tap quickly at a close position.
+	// EventTap is also emitted for the second tap.
+	EventDoubleTap
+
+	// EventLongPress is emitted once when a finger keeps touching the screen without moving.
+	EventLongPress
+
+	// EventSwipe is emitted when a finger moves fast and leaves the screen.
+	EventSwipe
+
+	// EventPinch is emitted at every tick while two fingers are moving closer or apart.
+	EventPinch
+
+	// EventRotate is emitted at every tick while two fingers are rotating.
+	EventRotate
+)
+
+// Event represents a gesture event.
+type Event struct {
+	Type EventType
+
+	// X and Y are the position of the gesture.
+	// For two-finger gestures, this is the middle point of the fingers.
+	X float64
+	Y float64
+
+	// VX and VY are the velocity in pixels per tick.
+	// For EventSwipe, this is the velocity of the finger when it leaves the screen.
+	// For two-finger gestures, this is the velocity of the middle point.
+	VX float64
+	VY float64
+
+	// Scale is the ratio of the distance between the fingers to the one at the previous tick.
+	// Scale is valid only for EventPinch.
+	Scale float64
+
+	// Rotation is the angle in radians the fingers rotated since the previous tick.
+	// Rotation is positive when the fingers rotate clockwise on the screen.
+	// Rotation is valid only for EventRotate.
+	Rotation float64
+}
+
+type position struct {
+	x float64
+	y float64
+}
+
+type touch struct {
+	start      position
+	startTick  int
+	positions  []position
+	moved      bool
+	longPress  bool
+	multiTouch bool
+}
+
+// velocityTicks is the number of the ticks used to calculate velocities.
+const velocityTicks = 4
+
+func (t *touch) last() position {
+	return t.positions[len(t.positions)-1]
+}
+
+func (t *touch) velocity() (vx, vy float64) {
+	n := len(t.positions) - 1
+	if n == 0 {
+		return 0, 0
+	}
+	p0, p1 := t.positions[0], t.positions[n]
+	return (p1.x - p0.x) / float64(n), (p1.y - p0.y) / float64(n)
+}
+
+// Recognizer recognizes touch gestures.
+//
+// Use NewRecognizer to create a Recognizer.
+// The thresholds can be modified before calling Update for the first time.
+type Recognizer struct {
+	// TapSlop is the distance a finger can move to be recognized as a tap or a long press.
+	TapSlop float64
+
+	// LongPressTicks is the duration a finger needs to keep touching for a long press.
+	LongPressTicks int
+
+	// DoubleTapTicks is the maximum interval between two taps for a double tap.
+	DoubleTapTicks int
+
+	// DoubleTapSlop is the maximum distance between two taps for a double tap.
+	DoubleTapSlop float64
+
+	// SwipeMinSpeed is the minimum speed in pixels per tick for a swipe.
+	SwipeMinSpeed float64
+
+	// PinchSlop is the distance the fingers need to move closer or apart to start a pinch.
+	PinchSlop float64
+
+	// RotateSlop is the angle in radians the fingers need to rotate to start a rotation.
+	RotateSlop float64
+
+	tick    int
+	touches map[int]*touch
+	events  []Event
+
+	lastTapTick int
+	lastTap     position
+	lastTapOK   bool
+
+	pinching      bool
+	rotating      bool
+	twoFingerIDs  [2]int
+	twoFingerDist float64
+	twoFingerAng  float64
+	startDist     float64
+	startAng      float64
+	prevCenter    position
+}
+
+// NewRecognizer returns a new Recognizer with the default thresholds.
+func NewRecognizer() *Recognizer {
+	return &Recognizer{
+		TapSlop:        8,
+		LongPressTicks: 30,
+		DoubleTapTicks: 18,
+		DoubleTapSlop:  32,
+		SwipeMinSpeed:  4,
+		PinchSlop:      8,
+		RotateSlop:     math.Pi / 36,
+		touches:        map[int]*touch{},
+		twoFingerIDs:   [2]int{-1, -1},
+	}
+}
+
+// Update updates the recognizer with the current touches.
+//
+// Update must be called once at every tick, e.g. at the beginning of the game's update function.
+func (r *Recognizer) Update() {
+	r.tick++
+	r.events = r.events[:0]
+
+	ids := ebiten.TouchIDs()
+	sort.Ints(ids)
+	current := map[int]struct{}{}
+	for _, id := range ids {
+		current[id] = struct{}{}
+		x, y := ebiten.TouchPosition(id)
+		p := position{float64(x), float64(y)}
+		t, ok := r.touches[id]
+		if !ok {
+			t = &touch{
+				start:     p,
+				startTick: r.tick,
+			}
+			r.touches[id] = t
+		}
+		t.positions = append(t.positions, p)
+		if len(t.positions) > velocityTicks+1 {
+			t.positions = t.positions[1:]
+		}
+		if math.Hypot(p.x-t.start.x, p.y-t.start.y) > r.TapSlop {
+			t.moved = true
+		}
+	}
+	if len(ids) > 1 {
+		for _, t := range r.touches {
+			t.multiTouch = true
+		}
+	}
+
+	// Released touches
+	for id, t := range r.touches {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		delete(r.touches, id)
+		r.released(t)
+	}
+
+	// Long presses
+	if len(ids) == 1 {
+		t := r.touches[ids[0]]
+		if !t.moved && !t.multiTouch && !t.longPress && r.tick-t.startTick >= r.LongPressTicks {
+			t.longPress = true
+			p := t.last()
+			r.events = append(r.events, Event{Type: EventLongPress, X: p.x, Y: p.y})
+		}
+	}
+
+	r.updateTwoFingers(ids)
+}
+
+func (r *Recognizer) released(t *touch) {
+	if t.multiTouch || t.longPress {
+		return
+	}
+	p := t.last()
+	if !t.moved {
+		r.events = append(r.events, Event{Type: EventTap, X: p.x, Y: p.y})
+		if r.lastTapOK && r.tick-r.lastTapTick <= r.DoubleTapTicks &&
+			math.Hypot(p.x-r.lastTap.x, p.y-r.lastTap.y) <= r.DoubleTapSlop {
+			r.events = append(r.events, Event{Type: EventDoubleTap, X: p.x, Y: p.y})
+			// A third tap doesn't make another double tap.
+			r.lastTapOK = false
+			return
+		}
+		r.lastTap = p
+		r.lastTapTick = r.tick
+		r.lastTapOK = true
+		return
+	}
+	vx, vy := t.velocity()
+	if math.Hypot(vx, vy) >= r.SwipeMinSpeed {
+		r.events = append(r.events, Event{Type: EventSwipe, X: p.x, Y: p.y, VX: vx, VY: vy})
+	}
+}
+
+func (r *Recognizer) updateTwoFingers(ids []int) {
+	if len(ids) != 2 {
+		r.pinching = false
+		r.rotating = false
+		r.twoFingerIDs = [2]int{-1, -1}
+		return
+	}
+	p0 := r.touches[ids[0]].last()
+	p1 := r.touches[ids[1]].last()
+	dist := math.Hypot(p1.x-p0.x, p1.y-p0.y)
+	ang := math.Atan2(p1.y-p0.y, p1.x-p0.x)
+	center := position{(p0.x + p1.x) / 2, (p0.y + p1.y) / 2}
+
+	if r.twoFingerIDs != [2]int{ids[0], ids[1]} {
+		// A new two-finger gesture starts.
+		r.twoFingerIDs = [2]int{ids[0], ids[1]}
+		r.twoFingerDist = dist
+		r.twoFingerAng = ang
+		r.startDist = dist
+		r.startAng = ang
+		r.prevCenter = center
+		return
+	}
+
+	if !r.pinching && math.Abs(dist-r.startDist) > r.PinchSlop {
+		r.pinching = true
+	}
+	if !r.rotating && math.Abs(normalizeAngle(ang-r.startAng)) > r.RotateSlop {
+		r.rotating = true
+	}
+	vx, vy := center.x-r.prevCenter.x, center.y-r.prevCenter.y
+	if r.pinching && r.twoFingerDist > 0 && dist != r.twoFingerDist {
+		r.events = append(r.events, Event{
+			Type:  EventPinch,
+			X:     center.x,
+			Y:     center.y,
+			VX:    vx,
+			VY:    vy,
+			Scale: dist / r.twoFingerDist,
+		})
+	}
+	if r.rotating && ang != r.twoFingerAng {
+		r.events = append(r.events, Event{
+			Type:     EventRotate,
+			X:        center.x,
+			Y:        center.y,
+			VX:       vx,
+			VY:       vy,
+			Rotation: normalizeAngle(ang - r.twoFingerAng),
+		})
+	}
+	r.twoFingerDist = dist
+	r.twoFingerAng = ang
+	r.prevCenter = center
+}
+
+// normalizeAngle normalizes the angle into [-π, π).
+func normalizeAngle(a float64) float64 {
+	a = math.Mod(a+math.Pi, 2*math.Pi)
+	if a < 0 {
+		a += 2 * math.Pi
+	}
+	return a - math.Pi
+}
+
+// Events returns the gesture events recognized at the last Update call.
+//
+// The returned slice is valid until the next Update call.
+func (r *Recognizer) Events() []Event {
+	return r.events
+}
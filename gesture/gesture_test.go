@@ -0,0 +1,116 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gesture_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/gesture"
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// update updates r with the given touches. The touches are given via the replayed input state.
+func update(r *Recognizer, touches ...ui.TouchState) []Event {
+	ui.SetReplayedInputState(&ui.InputState{TouchStates: touches})
+	defer ui.SetReplayedInputState(nil)
+	r.Update()
+	return append([]Event{}, r.Events()...)
+}
+
+func types(events []Event) []EventType {
+	ts := []EventType{}
+	for _, e := range events {
+		ts = append(ts, e.Type)
+	}
+	return ts
+}
+
+func equalTypes(a, b []EventType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTapAndDoubleTap(t *testing.T) {
+	r := NewRecognizer()
+	update(r, ui.TouchState{ID: 1, X: 10, Y: 10})
+	update(r, ui.TouchState{ID: 1, X: 11, Y: 10})
+	if got, want := types(update(r)), []EventType{EventTap}; !equalTypes(got, want) {
+		t.Errorf("first tap: got: %v, want: %v", got, want)
+	}
+	update(r)
+	update(r, ui.TouchState{ID: 2, X: 12, Y: 10})
+	if got, want := types(update(r)), []EventType{EventTap, EventDoubleTap}; !equalTypes(got, want) {
+		t.Errorf("second tap: got: %v, want: %v", got, want)
+	}
+}
+
+func TestLongPress(t *testing.T) {
+	r := NewRecognizer()
+	var got []EventType
+	for i := 0; i < r.LongPressTicks+5; i++ {
+		got = append(got, types(update(r, ui.TouchState{ID: 1, X: 10, Y: 10}))...)
+	}
+	got = append(got, types(update(r))...)
+	if want := []EventType{EventLongPress}; !equalTypes(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestSwipe(t *testing.T) {
+	r := NewRecognizer()
+	for i := 0; i < 10; i++ {
+		update(r, ui.TouchState{ID: 1, X: 10 + 10*i, Y: 20})
+	}
+	es := update(r)
+	if len(es) != 1 || es[0].Type != EventSwipe {
+		t.Fatalf("got: %v, want: a swipe", types(es))
+	}
+	if es[0].VX != 10 || es[0].VY != 0 {
+		t.Errorf("velocity: got: (%f, %f), want: (10, 0)", es[0].VX, es[0].VY)
+	}
+}
+
+func TestPinchAndRotate(t *testing.T) {
+	r := NewRecognizer()
+	update(r, ui.TouchState{ID: 1, X: 90, Y: 100}, ui.TouchState{ID: 2, X: 110, Y: 100})
+	// The distance changes from 20 to 40.
+	es := update(r, ui.TouchState{ID: 1, X: 80, Y: 100}, ui.TouchState{ID: 2, X: 120, Y: 100})
+	if len(es) != 1 || es[0].Type != EventPinch {
+		t.Fatalf("got: %v, want: a pinch", types(es))
+	}
+	if es[0].Scale != 2 || es[0].X != 100 || es[0].Y != 100 {
+		t.Errorf("got: %+v, want: the scale 2 at (100, 100)", es[0])
+	}
+	// Rotate by 90 degrees clockwise.
+	es = update(r, ui.TouchState{ID: 1, X: 100, Y: 80}, ui.TouchState{ID: 2, X: 100, Y: 120})
+	if got, want := types(es), []EventType{EventRotate}; !equalTypes(got, want) {
+		t.Fatalf("got: %v, want: %v", got, want)
+	}
+	if math.Abs(es[0].Rotation-math.Pi/2) > 1e-9 {
+		t.Errorf("rotation: got: %f, want: %f", es[0].Rotation, math.Pi/2)
+	}
+	// Releasing the fingers doesn't make any taps.
+	if es := update(r); len(es) != 0 {
+		t.Errorf("got: %v, want: no events", types(es))
+	}
+}
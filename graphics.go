@@ -22,23 +22,69 @@ import (
 type Filter int
 
 const (
+	// FilterDefault represents the default filter.
+	// For drawing, FilterDefault means the filter specified when the source image is created.
+	// For creating an image, FilterDefault is the same as FilterNearest.
+	FilterDefault Filter = iota
+
 	// FilterNearest represents nearest (crisp-edged) filter
-	FilterNearest Filter = iota
+	FilterNearest
 
-	// FilterLinear represents linear filter
+	// FilterLinear represents linear filter.
+	// When an image is drawn with DrawImage and shrunk to less than half of its size,
+	// mipmaps are used as well.
 	FilterLinear
+
+	// FilterAnisotropic represents linear filter with mipmaps that are sampled anisotropically.
+	// This keeps an image sharp when the image is strongly shrunk in one direction,
+	// e.g. a floor drawn in perspective like mode 7.
+	//
+	// FilterAnisotropic works only when the environment supports anisotropic filtering
+	// (EXT_texture_filter_anisotropic as of 1.6.0-alpha). Use IsAnisotropicFilterAvailable to check this.
+	// Otherwise, mipmaps are sampled without anisotropy.
+	// For a source image in an atlas or a compressed image, FilterAnisotropic is the same as FilterLinear.
+	FilterAnisotropic
 )
 
+// IsAnisotropicFilterAvailable reports whether FilterAnisotropic samples anisotropically in the current environment.
+//
+// IsAnisotropicFilterAvailable can't be called before the main loop (ebiten.Run) starts.
+func IsAnisotropicFilterAvailable() bool {
+	return opengl.GetContext().IsAnisotropicFilterAvailable()
+}
+
 func glFilter(filter Filter) opengl.Filter {
 	switch filter {
-	case FilterNearest:
+	case FilterDefault, FilterNearest:
 		return opengl.Nearest
 	case FilterLinear:
 		return opengl.Linear
+	case FilterAnisotropic:
+		return opengl.Anisotropic
 	}
 	panic("not reach")
 }
 
+// Address represents how the source image is sampled outside of its bounds.
+type Address int
+
+const (
+	// AddressDefault represents the default address mode.
+	// The source image is not repeated, and the pixels outside of the bounds are undefined.
+	// For example, they can belong to another image in the same atlas.
+	AddressDefault Address = Address(opengl.AddressDefault)
+
+	// AddressRepeat repeats the source image.
+	AddressRepeat = Address(opengl.AddressRepeat)
+
+	// AddressMirroredRepeat repeats the source image, mirroring it at every other repetition.
+	AddressMirroredRepeat = Address(opengl.AddressMirroredRepeat)
+)
+
+func glAddress(address Address) opengl.Address {
+	return opengl.Address(address)
+}
+
 // CompositeMode represents Porter-Duff composition mode.
 type CompositeMode int
 
@@ -87,4 +133,78 @@ const (
 	// Sum of source and destination (a.k.a. 'plus' or 'additive')
 	// c_out = c_src + c_dst
 	CompositeModeLighter = CompositeMode(opengl.CompositeModeLighter)
+
+	// Product of source and destination, which darkens the destination
+	// c_out = c_src × c_dst + c_dst × (1 - α_src)
+	CompositeModeMultiply = CompositeMode(opengl.CompositeModeMultiply)
 )
+
+// BlendFactor represents a factor of Blend.
+type BlendFactor int
+
+const (
+	BlendFactorZero                     = BlendFactor(opengl.BlendFactorZero)
+	BlendFactorOne                      = BlendFactor(opengl.BlendFactorOne)
+	BlendFactorSourceColor              = BlendFactor(opengl.BlendFactorSourceColor)
+	BlendFactorOneMinusSourceColor      = BlendFactor(opengl.BlendFactorOneMinusSourceColor)
+	BlendFactorSourceAlpha              = BlendFactor(opengl.BlendFactorSourceAlpha)
+	BlendFactorOneMinusSourceAlpha      = BlendFactor(opengl.BlendFactorOneMinusSourceAlpha)
+	BlendFactorDestinationColor         = BlendFactor(opengl.BlendFactorDestinationColor)
+	BlendFactorOneMinusDestinationColor = BlendFactor(opengl.BlendFactorOneMinusDestinationColor)
+	BlendFactorDestinationAlpha         = BlendFactor(opengl.BlendFactorDestinationAlpha)
+	BlendFactorOneMinusDestinationAlpha = BlendFactor(opengl.BlendFactorOneMinusDestinationAlpha)
+)
+
+// BlendOperation represents an operation to combine the source and the destination of Blend.
+type BlendOperation int
+
+const (
+	// c_out = c_src × factor_src + c_dst × factor_dst
+	BlendOperationAdd = BlendOperation(opengl.BlendOperationAdd)
+
+	// c_out = c_src × factor_src - c_dst × factor_dst
+	BlendOperationSubtract = BlendOperation(opengl.BlendOperationSubtract)
+
+	// c_out = c_dst × factor_dst - c_src × factor_src
+	BlendOperationReverseSubtract = BlendOperation(opengl.BlendOperationReverseSubtract)
+)
+
+// Blend represents a custom blending function, which is more flexible than CompositeMode.
+//
+// The RGB values and the alpha value are calculated separately.
+// The source and destination colors are alpha-premultiplied.
+//
+// For example, CompositeModeSourceOver is equivalent to:
+//
+//     Blend{
+//         SourceRGB:        BlendFactorOne,
+//         SourceAlpha:      BlendFactorOne,
+//         DestinationRGB:   BlendFactorOneMinusSourceAlpha,
+//         DestinationAlpha: BlendFactorOneMinusSourceAlpha,
+//         OperationRGB:     BlendOperationAdd,
+//         OperationAlpha:   BlendOperationAdd,
+//     }
+type Blend struct {
+	SourceRGB        BlendFactor
+	SourceAlpha      BlendFactor
+	DestinationRGB   BlendFactor
+	DestinationAlpha BlendFactor
+	OperationRGB     BlendOperation
+	OperationAlpha   BlendOperation
+}
+
+// glBlend returns the blending function for OpenGL.
+// If b is not nil, b is used instead of mode.
+func glBlend(mode CompositeMode, b *Blend) opengl.Blend {
+	if b == nil {
+		return opengl.CompositeMode(mode).Blend()
+	}
+	return opengl.Blend{
+		SourceRGB:        opengl.BlendFactor(b.SourceRGB),
+		SourceAlpha:      opengl.BlendFactor(b.SourceAlpha),
+		DestinationRGB:   opengl.BlendFactor(b.DestinationRGB),
+		DestinationAlpha: opengl.BlendFactor(b.DestinationAlpha),
+		OperationRGB:     opengl.BlendOperation(b.OperationRGB),
+		OperationAlpha:   opengl.BlendOperation(b.OperationAlpha),
+	}
+}
@@ -15,8 +15,11 @@
 package ebiten
 
 import (
+	"errors"
+	"image"
 	"math"
 
+	"github.com/hajimehoshi/ebiten/internal/hooks"
 	"github.com/hajimehoshi/ebiten/internal/restorable"
 	"github.com/hajimehoshi/ebiten/internal/ui"
 	"github.com/hajimehoshi/ebiten/internal/web"
@@ -28,13 +31,28 @@ func newGraphicsContext(f func(*Image) error) *graphicsContext {
 	}
 }
 
+func newGraphicsContextWithGame(game Game) *graphicsContext {
+	return &graphicsContext{
+		game: game,
+	}
+}
+
 type graphicsContext struct {
 	f           func(*Image) error
+	game        Game // game is not nil when the game is run by RunGame
 	offscreen   *Image
 	offscreen2  *Image // TODO: better name
 	screen      *Image
 	screenScale float64
 	initialized bool
+
+	// outsideWidth, outsideHeight, layoutWidth and layoutHeight are the outside size and the screen size
+	// returned by Layout when the screen size is applied last time.
+	outsideWidth  int
+	outsideHeight int
+	layoutWidth   int
+	layoutHeight  int
+
 	invalidated bool // browser only
 }
 
@@ -101,26 +119,83 @@ func (c *graphicsContext) Update(updateCount int, afterFrameUpdate func()) error
 	if err := c.initializeIfNeeded(); err != nil {
 		return err
 	}
+	if c.game != nil {
+		ow, oh, requested := theWindow.takeRequestedSize()
+		if !requested {
+			ow, oh = outsideSize()
+		}
+		w, h, s := layout(c.game, ow, oh)
+		if requested || ow != c.outsideWidth || oh != c.outsideHeight || w != c.layoutWidth || h != c.layoutHeight {
+			// The new size is applied at the next frame.
+			ui.SetScreenSizeAndScale(w, h, s)
+			// Remember the outside size after the window is resized. Otherwise, the rounded window size
+			// would change the scale little by little every frame.
+			c.outsideWidth, c.outsideHeight = outsideSize()
+			c.layoutWidth, c.layoutHeight = w, h
+		}
+	}
+	setUpdateCountInFrame(updateCount)
 	for i := 0; i < updateCount; i++ {
 		restorable.ClearVolatileImages()
 		setRunningSlowly(i < updateCount-1)
-		if err := c.f(c.offscreen); err != nil {
+		if err := hooks.RunBeforeUpdateHooks(); err != nil {
 			return err
 		}
+		if c.game != nil {
+			if err := c.game.Update(); err != nil {
+				return err
+			}
+		} else {
+			if err := c.f(c.offscreen); err != nil {
+				return err
+			}
+		}
 		afterFrameUpdate()
 	}
-	if 0 < updateCount {
-		drawWithFittingScale(c.offscreen2, c.offscreen)
+	// Game's Draw follows rendering frames, decoupled from the number of Update calls.
+	if c.game != nil {
+		if updateCount == 0 {
+			restorable.ClearVolatileImages()
+		}
+		c.game.Draw(c.offscreen)
+	}
+	// The screen is cleared with the transparent color so that the desktop is visible
+	// behind the window where nothing is rendered when the screen is transparent.
+	if f := currentFinalScreenDrawer(); f != nil {
+		_ = c.screen.Clear()
+		f(c.screen, c.offscreen)
+	} else {
+		if 0 < updateCount || c.game != nil {
+			drawWithFittingScale(c.offscreen2, c.offscreen)
+		}
+		_ = c.screen.Clear()
+		drawWithFittingScale(c.screen, c.offscreen2)
 	}
-	_ = c.screen.Clear()
-	drawWithFittingScale(c.screen, c.offscreen2)
 
 	if err := restorable.ResolveStaleImages(); err != nil {
 		return err
 	}
+	theReadPixelsQueue.flush()
+	theDrawCommandCapture.endFrame()
+	if err := theMemoryBudget.check(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// screenshot returns a copy of the offscreen, which is the screen image for the game.
+func (c *graphicsContext) screenshot() (*image.RGBA, error) {
+	if c.offscreen == nil {
+		return nil, errors.New("ebiten: the screen is not initialized yet")
+	}
+	w, h := c.offscreen.Size()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	if err := c.offscreen.ReadPixels(img.Pix); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
 func (c *graphicsContext) needsRestoring() (bool, error) {
 	if web.IsBrowser() {
 		return c.invalidated, nil
@@ -29,14 +29,164 @@ import (
 // The pixel format is alpha-premultiplied RGBA.
 // Image implements image.Image.
 //
+// Any image can be a render target: an image created by NewImage or NewImageFromImage can be drawn onto by
+// DrawImage or Fill in the same way as the screen image passed to the update function.
+// This is useful for offscreen rendering like lighting buffers, minimaps and post-processing.
+// The contents of offscreen images are kept and restored automatically when the GL context is lost.
+//
+// Small images created by NewImageFromImage are packed into shared textures (atlases) automatically,
+// so that drawing many different small images can be batched.
+// An image is moved out of the atlas to its own texture when it becomes a render target.
+//
 // Functions of Image never returns error as of 1.5.0-alpha, and error values are always nil.
 type Image struct {
 	restorable *restorable.Image
+
+	// shared is the region in an atlas if the image is in an atlas.
+	// restorable is nil while shared is not nil.
+	shared *sharedImage
+
+	// original is the image that the sub-image is created from.
+	// original is nil if the image is not a sub-image.
+	original *Image
+
+	// bounds is the region of the sub-image in the original image.
+	bounds image.Rectangle
+
+	// view is the matrix to transform the destination positions if the image is a viewport.
+	// view is nil if the image is not a viewport.
+	view *GeoM
 }
 
 // Size returns the size of the image.
 func (i *Image) Size() (width, height int) {
-	return i.restorable.Size()
+	s := i.Bounds().Size()
+	return s.X, s.Y
+}
+
+// isSubImage reports whether the image is a sub-image created by SubImage.
+func (i *Image) isSubImage() bool {
+	return i.original != nil
+}
+
+// restorableImage returns the restorable image that holds the pixels.
+// For a sub-image, this returns the original image's one.
+func (i *Image) restorableImage() *restorable.Image {
+	if i.isSubImage() {
+		return i.original.restorableImage()
+	}
+	if i.shared != nil {
+		return i.shared.backend.restorable
+	}
+	return i.restorable
+}
+
+// offset returns the position of the image's pixels in the restorable image.
+// The offset is not zero when the image is in an atlas.
+func (i *Image) offset() image.Point {
+	if i.isSubImage() {
+		return i.original.offset()
+	}
+	if i.shared != nil {
+		return i.shared.offset()
+	}
+	return image.Point{}
+}
+
+// isShared reports whether the image's pixels are in an atlas.
+func (i *Image) isShared() bool {
+	if i.isSubImage() {
+		return i.original.isShared()
+	}
+	return i.shared != nil
+}
+
+// sameTexture reports whether the images share the same pixels.
+func (i *Image) sameTexture(other *Image) bool {
+	if i == other {
+		return true
+	}
+	r := i.restorableImage()
+	return r != nil && r == other.restorableImage()
+}
+
+// SubImage returns an image representing the portion of the image i visible through r.
+// The returned value is always *ebiten.Image.
+//
+// The returned image shares the pixels with i, so no pixels are copied.
+// The bounds of the returned image are in i's coordinates, and the returned image
+// can be used as a source of drawing in the same way as a regular image.
+// This is useful to draw a part of a spritesheet or an atlas.
+//
+// ReplacePixels on a sub-image replaces only the pixels in the sub-image's bounds.
+//
+// A sub-image can also be a render target.
+// Rendering functions like DrawImage, DrawTriangles, Fill and Clear on a sub-image affect only
+// the pixels in the sub-image's bounds, and the part outside of the bounds is clipped.
+// The destination coordinates are the same as the original image's, i.e., (0, 0) is not the sub-image's upper-left corner.
+// This is useful to render a scrollable list or a split-screen viewport without an offscreen image.
+//
+// If the image is disposed, SubImage returns nil.
+func (i *Image) SubImage(r image.Rectangle) image.Image {
+	if i.restorableImage() == nil {
+		return nil
+	}
+	original := i
+	if i.isSubImage() {
+		original = i.original
+	}
+	return &Image{
+		original: original,
+		bounds:   r.Intersect(i.Bounds()),
+	}
+}
+
+// Viewport returns an image representing the region r of the image i as a viewport with the view matrix view.
+//
+// A viewport is a sub-image with its own destination coordinates (see SubImage).
+// When drawing on the returned image, the destination positions are transformed by view,
+// then the origin (0, 0) is moved to r.Min, and the result is clipped to r.
+// This applies to DrawImage, DrawTriangles, DrawTrianglesShader and DrawRectShader.
+// This is useful for split-screen and picture-in-picture rendering: the same drawing code can render
+// to different regions with different view transforms, e.g. camera.Camera's GeoM.
+//
+// r is in i's coordinates, and the returned image's Bounds is r clipped to i's bounds as SubImage's.
+// When i is a viewport, i's view is not applied to the returned image.
+// As a source image, the returned image is the same as a sub-image.
+//
+// If the image is disposed, Viewport returns nil.
+func (i *Image) Viewport(r image.Rectangle, view GeoM) *Image {
+	img, _ := i.SubImage(r).(*Image)
+	if img == nil {
+		return nil
+	}
+	view.Translate(float64(r.Min.X), float64(r.Min.Y))
+	img.view = &view
+	return img
+}
+
+// renderTarget prepares the image as a render target, and returns the image to render to and
+// the region to restrict rendering to.
+// An empty region means the whole image.
+// If the image is in an atlas, renderTarget moves the image to its own texture.
+//
+// renderTarget returns nil when nothing can be rendered, e.g., the image is disposed or
+// the image is an empty sub-image.
+//
+// renderTarget panics if the image is a compressed image.
+func (i *Image) renderTarget() (*restorable.Image, image.Rectangle) {
+	if i.isCompressed() {
+		panic("ebiten: a compressed image can't be a render target")
+	}
+	if i.isSubImage() {
+		if i.bounds.Empty() {
+			return nil, image.Rectangle{}
+		}
+		r, _ := i.original.renderTarget()
+		return r, i.bounds
+	}
+	i.ensureNotShared()
+	return i.restorable, image.Rectangle{}
 }
 
 // Clear resets the pixels of the image into 0.
@@ -45,18 +195,35 @@ func (i *Image) Size() (width, height int) {
 //
 // Clear always returns nil as of 1.5.0-alpha.
 func (i *Image) Clear() error {
-	i.restorable.Fill(0, 0, 0, 0)
-	return nil
+	return i.Fill(color.Transparent)
 }
 
 // Fill fills the image with a solid color.
 //
+// When the image is a sub-image, only the pixels in the sub-image's bounds are filled.
+//
 // When the image is disposed, Fill does nothing.
 //
 // Fill always returns nil as of 1.5.0-alpha.
 func (i *Image) Fill(clr color.Color) error {
+	dst, region := i.renderTarget()
+	if dst == nil {
+		return nil
+	}
 	r, g, b, a := clr.RGBA()
-	i.restorable.Fill(uint8(r>>8), uint8(g>>8), uint8(b>>8), uint8(a>>8))
+	if region.Empty() {
+		dst.Fill(uint8(r>>8), uint8(g>>8), uint8(b>>8), uint8(a>>8))
+		return nil
+	}
+	w, h := region.Dx(), region.Dy()
+	pix := make([]uint8, 4*w*h)
+	for idx := 0; idx < w*h; idx++ {
+		pix[4*idx] = uint8(r >> 8)
+		pix[4*idx+1] = uint8(g >> 8)
+		pix[4*idx+2] = uint8(b >> 8)
+		pix[4*idx+3] = uint8(a >> 8)
+	}
+	dst.ReplacePixelsRegion(pix, region.Min.X, region.Min.Y, w, h)
 	return nil
 }
 
@@ -72,7 +239,20 @@ func (i *Image) Fill(clr color.Color) error {
 //
 // When the i is disposed, DrawImage does nothing.
 //
-// When the given image is as same as i, DrawImage panics.
+// When the given image is as same as i or a sub-image of i, DrawImage panics.
+//
+// When the given image is a sub-image, SourceRect is in the same coordinates as the sub-image's Bounds,
+// and the part outside of the bounds is not drawn.
+//
+// When the image i is a sub-image, the drawing result is clipped to the bounds of i.
+// When the image i is a viewport, the geometry matrix is followed by the view (see Viewport).
+//
+// When a palette is specified in the options, the colors of the given indexed image are looked up in the palette.
+// This is useful to swap or animate the colors of sprites without duplicating them.
+//
+// When the linear filter is used and the geometry matrix shrinks the image to less than half of its size,
+// DrawImage samples the image from its mipmaps so that the result doesn't flicker or look jaggy.
+// The mipmaps are generated lazily and regenerated only after the image is modified.
 //
 // DrawImage works more efficiently as batches
 // when the successive calls of DrawImages satisfies the below conditions:
@@ -80,16 +260,22 @@ func (i *Image) Fill(clr color.Color) error {
 //   * All render targets are same (A in A.DrawImage(B, op))
 //   * All render sources are same (B in A.DrawImage(B, op))
 //   * All ColorM values are same
-//   * All CompositeMode values are same
+//   * All CompositeMode and Blend values are same
+//   * All Filter values in the options are same
+//   * All Palette values in the options are same
+//   * All Mask values in the options are same
+//
+// Images in the same atlas are regarded as the same render source.
 //
 // For more performance tips, see https://github.com/hajimehoshi/ebiten/wiki/Performance-Tips.
 //
 // DrawImage always returns nil as of 1.5.0-alpha.
 func (i *Image) DrawImage(img *Image, options *DrawImageOptions) error {
-	if i == img {
+	dst, region := i.renderTarget()
+	if i.sameTexture(img) {
 		panic("ebiten: Image.DrawImage: img must be different from the receiver")
 	}
-	if i.restorable == nil {
+	if dst == nil {
 		return nil
 	}
 	// Calculate vertices before locking because the user can do anything in
@@ -111,6 +297,10 @@ func (i *Image) DrawImage(img *Image, options *DrawImageOptions) error {
 			op := &DrawImageOptions{
 				ColorM:        options.ColorM,
 				CompositeMode: options.CompositeMode,
+				Blend:         options.Blend,
+				Filter:        options.Filter,
+				Palette:       options.Palette,
+				Mask:          options.Mask,
 			}
 			r := image.Rect(sx0, sy0, sx1, sy1)
 			op.SourceRect = &r
@@ -123,22 +313,280 @@ func (i *Image) DrawImage(img *Image, options *DrawImageOptions) error {
 		}
 		return nil
 	}
-	w, h := img.restorable.Size()
-	sx0, sy0, sx1, sy1 := 0, 0, w, h
+	src := img.restorableImage()
+	if src == nil {
+		return nil
+	}
+	w, h := src.Size()
+	b := img.Bounds()
+	geom := options.GeoM
 	if r := options.SourceRect; r != nil {
-		sx0 = r.Min.X
-		sy0 = r.Min.Y
-		sx1 = r.Max.X
-		sy1 = r.Max.Y
-	}
-	vs := vertices(sx0, sy0, sx1, sy1, w, h, &options.GeoM.impl)
-	mode := opengl.CompositeMode(options.CompositeMode)
-	i.restorable.DrawImage(img.restorable, vs, &options.ColorM.impl, mode)
+		b = *r
+		if img.isSubImage() {
+			// The part outside of the sub-image must not be drawn.
+			b = r.Intersect(img.bounds)
+			if b.Empty() {
+				return nil
+			}
+			geom = GeoM{}
+			geom.Translate(float64(b.Min.X-r.Min.X), float64(b.Min.Y-r.Min.Y))
+			geom.Concat(options.GeoM)
+		}
+	}
+	if i.view != nil {
+		geom.Concat(*i.view)
+	}
+	b = b.Add(img.offset())
+	vs := vertices(b.Min.X, b.Min.Y, b.Max.X, b.Max.Y, w, h, &geom.impl)
+	mask, ok := i.maskImage(options.Mask)
+	if !ok {
+		return nil
+	}
+	if options.Palette != nil {
+		p := options.Palette.restorableImage()
+		if p == nil {
+			return nil
+		}
+		if mask != nil {
+			dst.DrawMaskedImage(src, p, mask, options.Mask.offset(), options.Mask.Bounds(), vs, quadIndices, &options.ColorM.impl, glBlend(options.CompositeMode, options.Blend), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, region)
+			return nil
+		}
+		dst.DrawPalettedImage(src, p, vs, quadIndices, &options.ColorM.impl, glBlend(options.CompositeMode, options.Blend), opengl.AddressDefault, image.Rectangle{}, region)
+		return nil
+	}
+	filter := drawFilter(img, options.Filter)
+	// Mipmaps of an atlas would mix the neighbor images.
+	// Mipmaps can't be generated from compressed pixels.
+	if filter == opengl.Linear && isMinified(&geom) && !img.isShared() && !src.IsCompressed() {
+		filter = opengl.LinearMipmap
+	}
+	if mask != nil {
+		dst.DrawMaskedImage(src, nil, mask, options.Mask.offset(), options.Mask.Bounds(), vs, quadIndices, &options.ColorM.impl, glBlend(options.CompositeMode, options.Blend), filter, opengl.AddressDefault, image.Rectangle{}, region)
+		return nil
+	}
+	dst.DrawImage(src, vs, quadIndices, &options.ColorM.impl, glBlend(options.CompositeMode, options.Blend), filter, opengl.AddressDefault, image.Rectangle{}, region)
+	return nil
+}
+
+// maskImage returns the restorable image of the mask to draw on the image i.
+// maskImage returns nil and true if mask is nil, and returns false if nothing should be drawn,
+// e.g., the mask is disposed.
+//
+// maskImage panics if the mask is the same as i or a sub-image of i.
+func (i *Image) maskImage(mask *Image) (*restorable.Image, bool) {
+	if mask == nil {
+		return nil, true
+	}
+	if i.sameTexture(mask) {
+		panic("ebiten: the mask must be different from the render target")
+	}
+	m := mask.restorableImage()
+	if m == nil || mask.Bounds().Empty() {
+		return nil, false
+	}
+	return m, true
+}
+
+// Vertex represents a vertex passed to DrawTriangles.
+type Vertex struct {
+	// DstX and DstY represent a point on a destination image.
+	DstX float32
+	DstY float32
+
+	// SrcX and SrcY represent a point on a source image.
+	SrcX float32
+	SrcY float32
+
+	// ColorR/ColorG/ColorB/ColorA represents color scaling values.
+	// The color of the source image at the vertex is multiplied by these values after the color matrix is applied.
+	// 1 means the original source image color is used.
+	// 0 means a transparent color is used.
+	// The values are interpolated between the vertices.
+	ColorR float32
+	ColorG float32
+	ColorB float32
+	ColorA float32
+}
+
+// DrawTrianglesOptions represents options to render triangles on an image.
+//
+// Note that this API is experimental.
+type DrawTrianglesOptions struct {
+	// ColorM is a color matrix to draw.
+	// The default (zero) value is identity, which doesn't change any color.
+	// ColorM is applied before vertex color scale is applied.
+	ColorM ColorM
+
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	CompositeMode CompositeMode
+
+	// Blend is a custom blending function to draw.
+	// If Blend is not nil, CompositeMode is ignored.
+	Blend *Blend
+
+	// Filter is a filter to sample the source image.
+	// The default (zero) value is FilterDefault, which uses the filter specified when the source image is created.
+	Filter Filter
+
+	// Address is an address mode to sample the source image outside of its bounds.
+	// The default (zero) value is AddressDefault.
+	Address Address
+
+	// Palette is a palette to look up the colors of the source image.
+	// If Palette is not nil, the source image is regarded as an indexed image (see NewIndexedImage)
+	// and Filter is ignored.
+	Palette *Palette
+
+	// Mask is an image to clip the drawing result to an arbitrary shape.
+	// See the document of DrawImageOptions.Mask.
+	Mask *Image
+}
+
+// MaxIndicesNum is the maximum number of indices for DrawTriangles.
+const MaxIndicesNum = restorable.MaxIndicesNum
+
+// DrawTriangles draws triangles with the specified vertices and their indices.
+//
+// If len(indices) is not multiple of 3, DrawTriangles panics.
+//
+// If len(indices) is more than MaxIndicesNum, DrawTriangles panics.
+//
+// If an index is out of the range of vertices, DrawTriangles panics.
+//
+// The rule in which DrawTriangles works effectively is same as DrawImage's.
+//
+// When the image i is disposed, DrawTriangles does nothing.
+//
+// When the given image is as same as i or a sub-image of i, DrawTriangles panics.
+//
+// When the given image is a sub-image, SrcX and SrcY are in the same coordinates as the sub-image's Bounds.
+//
+// When the image i is a sub-image, the triangles are clipped to the bounds of i.
+// When the image i is a viewport, the destination positions are transformed by the view (see Viewport).
+//
+// With AddressRepeat or AddressMirroredRepeat, SrcX and SrcY can be out of the source image's bounds,
+// and the source image is tiled. This is useful to draw a tiling background with one call.
+//
+// Note that this API is experimental.
+//
+// DrawTriangles always returns nil.
+func (i *Image) DrawTriangles(vertices []Vertex, indices []uint16, img *Image, options *DrawTrianglesOptions) error {
+	dst, region := i.renderTarget()
+	if i.sameTexture(img) {
+		panic("ebiten: Image.DrawTriangles: img must be different from the receiver")
+	}
+	if dst == nil {
+		return nil
+	}
+	checkTriangles(vertices, indices)
+	if options == nil {
+		options = &DrawTrianglesOptions{}
+	}
+
+	src := img.restorableImage()
+	if src == nil {
+		return nil
+	}
+	w, h := src.Size()
+	wf := float32(math.NextPowerOf2Int(w))
+	hf := float32(math.NextPowerOf2Int(h))
+	o := img.offset()
+	ox, oy := float32(o.X), float32(o.Y)
+	vs := make([]float32, 0, len(vertices)*restorable.VertexSizeInBytes()/4)
+	for _, v := range vertices {
+		dx, dy := i.viewPosition(v.DstX, v.DstY)
+		vs = append(vs, dx, dy, (v.SrcX+ox)/wf, (v.SrcY+oy)/hf, v.ColorR, v.ColorG, v.ColorB, v.ColorA)
+	}
+	var srcRegion image.Rectangle
+	if options.Address != AddressDefault {
+		srcRegion = img.Bounds().Add(o)
+	}
+	mask, ok := i.maskImage(options.Mask)
+	if !ok {
+		return nil
+	}
+	if options.Palette != nil {
+		p := options.Palette.restorableImage()
+		if p == nil {
+			return nil
+		}
+		if mask != nil {
+			dst.DrawMaskedImage(src, p, mask, options.Mask.offset(), options.Mask.Bounds(), vs, indices, &options.ColorM.impl, glBlend(options.CompositeMode, options.Blend), opengl.Nearest, glAddress(options.Address), srcRegion, region)
+			return nil
+		}
+		dst.DrawPalettedImage(src, p, vs, indices, &options.ColorM.impl, glBlend(options.CompositeMode, options.Blend), glAddress(options.Address), srcRegion, region)
+		return nil
+	}
+	filter := drawFilter(img, options.Filter)
+	if filter == opengl.Anisotropic && options.Address != AddressDefault {
+		// Repeating is done in the shader, where mipmaps can't be selected correctly at the seams.
+		filter = opengl.Linear
+	}
+	if mask != nil {
+		dst.DrawMaskedImage(src, nil, mask, options.Mask.offset(), options.Mask.Bounds(), vs, indices, &options.ColorM.impl, glBlend(options.CompositeMode, options.Blend), filter, glAddress(options.Address), srcRegion, region)
+		return nil
+	}
+	dst.DrawImage(src, vs, indices, &options.ColorM.impl, glBlend(options.CompositeMode, options.Blend), filter, glAddress(options.Address), srcRegion, region)
 	return nil
 }
 
+// viewPosition transforms the destination position (x, y) with the view matrix if the image is a viewport.
+func (i *Image) viewPosition(x, y float32) (float32, float32) {
+	if i.view == nil {
+		return x, y
+	}
+	dx, dy := i.view.Apply(float64(x), float64(y))
+	return float32(dx), float32(dy)
+}
+
+// drawFilter returns the filter to sample img with.
+func drawFilter(img *Image, filter Filter) opengl.Filter {
+	r := img.restorableImage()
+	f := r.Filter()
+	if filter != FilterDefault {
+		f = glFilter(filter)
+	}
+	// Mipmaps are not available for an atlas or compressed pixels.
+	if f == opengl.Anisotropic && (img.isShared() || r.IsCompressed()) {
+		return opengl.Linear
+	}
+	return f
+}
+
+// isMinified reports whether the geometry matrix shrinks the source image
+// to less than half of its size in both directions.
+func isMinified(geom *GeoM) bool {
+	a, b, c, d, _, _ := geom.impl.Elements()
+	return a*a+c*c < 0.25 && b*b+d*d < 0.25
+}
+
+// checkTriangles panics if the vertices and the indices are invalid for DrawTriangles.
+func checkTriangles(vertices []Vertex, indices []uint16) {
+	if len(indices)%3 != 0 {
+		panic("ebiten: len(indices) % 3 must be 0")
+	}
+	if len(indices) > MaxIndicesNum {
+		panic("ebiten: len(indices) must be <= MaxIndicesNum")
+	}
+	if len(vertices) > restorable.MaxVerticesNum {
+		panic(fmt.Sprintf("ebiten: len(vertices) must be <= %d", restorable.MaxVerticesNum))
+	}
+	for _, idx := range indices {
+		if int(idx) >= len(vertices) {
+			panic(fmt.Sprintf("ebiten: index %d is out of range of the vertices", idx))
+		}
+	}
+}
+
 // Bounds returns the bounds of the image.
 func (i *Image) Bounds() image.Rectangle {
+	if i.isSubImage() {
+		return i.bounds
+	}
+	if i.shared != nil {
+		return image.Rect(0, 0, i.shared.width, i.shared.height)
+	}
 	w, h := i.restorable.Size()
 	return image.Rect(0, 0, w, h)
 }
@@ -154,13 +602,21 @@ func (i *Image) ColorModel() color.Model {
 //
 // At always returns color.Transparend if the image is disposed.
 //
+// For a sub-image, (x, y) is in the same coordinates as Bounds, and
+// At returns color.Transparent if (x, y) is out of the bounds.
+//
 // At can't be called before the main loop (ebiten.Run) starts (as of version 1.4.0-alpha).
 func (i *Image) At(x, y int) color.Color {
-	if i.restorable == nil {
+	r := i.restorableImage()
+	if r == nil {
+		return color.Transparent
+	}
+	if i.isSubImage() && !image.Pt(x, y).In(i.bounds) {
 		return color.Transparent
 	}
 	// TODO: Error should be delayed until flushing. Do not panic here.
-	clr, err := i.restorable.At(x, y)
+	o := i.offset()
+	clr, err := r.At(x+o.X, y+o.Y)
 	if err != nil {
 		panic(err)
 	}
@@ -173,8 +629,16 @@ func (i *Image) At(x, y int) color.Color {
 //
 // When the image is disposed, Dipose does nothing.
 //
+// When the image is a sub-image, Dispose does nothing.
+//
 // Dipose always return nil as of 1.5.0-alpha.
 func (i *Image) Dispose() error {
+	if i.shared != nil {
+		i.shared.dispose()
+		i.shared = nil
+		runtime.SetFinalizer(i, nil)
+		return nil
+	}
 	if i.restorable == nil {
 		return nil
 	}
@@ -188,7 +652,11 @@ func (i *Image) Dispose() error {
 //
 // The given p must represent RGBA pre-multiplied alpha values. len(p) must equal to 4 * (image width) * (image height).
 //
-// ReplacePixels may be slow (as for implementation, this calls glTexSubImage2D).
+// ReplacePixels uploads p to the texture directly (as for implementation, this calls glTexSubImage2D).
+// This is much faster than drawing pixels one by one, and is suitable for procedural textures or video frames.
+//
+// When the image is a sub-image, only the pixels in the sub-image's bounds are replaced.
+// This is useful to update a part of a large image.
 //
 // When len(p) is not appropriate, ReplacePixels panics.
 //
@@ -196,13 +664,25 @@ func (i *Image) Dispose() error {
 //
 // ReplacePixels always returns nil as of 1.5.0-alpha.
 func (i *Image) ReplacePixels(p []uint8) error {
-	if i.restorable == nil {
+	r := i.restorableImage()
+	if r == nil {
 		return nil
 	}
-	w, h := i.restorable.Size()
+	if r.IsCompressed() {
+		panic("ebiten: ReplacePixels can't be called on a compressed image")
+	}
+	w, h := i.Size()
 	if l := 4 * w * h; len(p) != l {
 		panic(fmt.Sprintf("ebiten: len(p) was %d but must be %d", len(p), l))
 	}
+	if i.isSubImage() || i.isShared() {
+		if w == 0 || h == 0 {
+			return nil
+		}
+		b := i.Bounds().Add(i.offset())
+		r.ReplacePixelsRegion(p, b.Min.X, b.Min.Y, w, h)
+		return nil
+	}
 	w2, h2 := math.NextPowerOf2Int(w), math.NextPowerOf2Int(h)
 	pix := make([]uint8, 4*w2*h2)
 	for j := 0; j < h; j++ {
@@ -230,6 +710,30 @@ type DrawImageOptions struct {
 	// The default (zero) value is regular alpha blending.
 	CompositeMode CompositeMode
 
+	// Blend is a custom blending function to draw.
+	// If Blend is not nil, CompositeMode is ignored.
+	Blend *Blend
+
+	// Filter is a filter to sample the source image.
+	// The default (zero) value is FilterDefault, which uses the filter specified when the source image is created.
+	Filter Filter
+
+	// Palette is a palette to look up the colors of the source image.
+	// If Palette is not nil, the source image is regarded as an indexed image (see NewIndexedImage)
+	// and Filter is ignored.
+	Palette *Palette
+
+	// Mask is an image to clip the drawing result to an arbitrary shape.
+	// If Mask is not nil, the result at each pixel of the destination is multiplied by the alpha of Mask's pixel
+	// at the same position. Only the alpha channel of Mask is used.
+	//
+	// Mask's pixels are in the destination's coordinates: Mask's pixel at (x, y) in its Bounds masks
+	// the destination pixel at (x, y). When the destination is a sub-image or a viewport,
+	// the coordinates are the original image's. The destination pixels outside of Mask's bounds are fully masked.
+	//
+	// This is useful for e.g. circular minimaps, holes of fog of war, and UI shapes.
+	Mask *Image
+
 	// Deprecated (as of 1.5.0-alpha): Use SourceRect instead.
 	ImageParts ImageParts
 
@@ -246,11 +750,89 @@ func NewImage(width, height int, filter Filter) (*Image, error) {
 	checkSize(width, height)
 	r := restorable.NewImage(width, height, glFilter(filter), false)
 	r.Fill(0, 0, 0, 0)
-	i := &Image{r}
+	i := &Image{restorable: r}
 	runtime.SetFinalizer(i, (*Image).Dispose)
 	return i, nil
 }
 
+// NewImageOptions represents options for NewImageWithOptions.
+type NewImageOptions struct {
+	// Filter is the filter used when the image is drawn with FilterDefault.
+	// The default (zero) value is FilterDefault, which is the same as FilterNearest.
+	Filter Filter
+
+	// Antialias indicates whether the rendering onto the image is anti-aliased.
+	// If Antialias is true, edges of triangles drawn onto the image are smooth.
+	//
+	// Anti-aliasing is implemented with multisampling, and is available only when the environment supports it
+	// (OpenGL 3.0 or ARB_framebuffer_object on desktops as of 1.6.0-alpha).
+	// Otherwise, Antialias is ignored.
+	Antialias bool
+
+	// HDR indicates whether the image has 16-bit floating point channels (RGBA16F) instead of 8-bit ones.
+	// The pixels of an HDR image are not clamped to 1 when rendered with additive blending like CompositeModeLighter
+	// or vertex colors more than 1, which is useful for lighting accumulation and bloom.
+	//
+	// DrawImage and DrawTriangles clamp the colors of an HDR source after applying the color matrix.
+	// To read the values over 1, use a shader or ebitenutil.DrawToneMapped onto an 8-bit image like the screen.
+	// At and ReadPixels return the clamped 8-bit values.
+	//
+	// HDR images are available only when the environment supports floating point textures
+	// (ARB_texture_float on desktops as of 1.6.0-alpha).
+	// Otherwise, HDR is ignored. Use IsHDRAvailable to check this.
+	// If HDR is true, Antialias is ignored.
+	HDR bool
+
+	// Volatile indicates whether the pixels of the image are not preserved across frames.
+	// A volatile image is cleared at the start of every frame, before the update function is called.
+	//
+	// Pixels of a regular image are saved when the image is changed in a frame, and restored automatically
+	// when the graphics context is lost, e.g. when an Android app is resumed.
+	// Pixels of a volatile image are never saved, which saves memory and time.
+	// This is suitable for offscreen images that are fully redrawn every frame.
+	//
+	// When a volatile image is drawn onto a regular image, the regular image's pixels have to be read from GPU
+	// at the end of the frame on environments where the context can be lost, which is slow.
+	Volatile bool
+}
+
+// NewImageWithOptions returns an empty image with the options.
+// If options is nil, NewImageWithOptions works in the same way as NewImage with FilterDefault.
+//
+// If width or height is less than 1 or more than MaxImageSize, NewImageWithOptions panics.
+//
+// Error returned by NewImageWithOptions is always nil as of 1.6.0-alpha.
+func NewImageWithOptions(width, height int, options *NewImageOptions) (*Image, error) {
+	if options == nil {
+		options = &NewImageOptions{}
+	}
+	if !options.Antialias && !options.HDR && !options.Volatile {
+		return NewImage(width, height, options.Filter)
+	}
+	checkSize(width, height)
+	var r *restorable.Image
+	switch {
+	case options.HDR:
+		r = restorable.NewHDRImage(width, height, glFilter(options.Filter), options.Volatile)
+	case options.Antialias:
+		r = restorable.NewAntialiasedImage(width, height, glFilter(options.Filter), options.Volatile)
+	default:
+		r = restorable.NewImage(width, height, glFilter(options.Filter), true)
+	}
+	r.Fill(0, 0, 0, 0)
+	i := &Image{restorable: r}
+	runtime.SetFinalizer(i, (*Image).Dispose)
+	return i, nil
+}
+
+// IsHDRAvailable reports whether HDR images are available in the current environment.
+// See also the document of NewImageOptions.HDR.
+//
+// IsHDRAvailable can't be called before the main loop (ebiten.Run) starts.
+func IsHDRAvailable() bool {
+	return opengl.GetContext().IsFloatTextureAvailable()
+}
+
 // newVolatileImage returns an empty 'volatile' image.
 // A volatile image is always cleared at the start of a frame.
 //
@@ -261,7 +843,7 @@ func NewImage(width, height int, filter Filter) (*Image, error) {
 // On the other hand, pixels in volatile images are not saved.
 // Saving pixels is an expensive operation, and it is desirable to avoid it if possible.
 //
-// Note that volatile images are internal only and will never be source of drawing.
+// NewImageOptions.Volatile exposes volatile images.
 //
 // If width or height is less than 1 or more than MaxImageSize, newVolatileImage panics.
 //
@@ -270,7 +852,7 @@ func newVolatileImage(width, height int, filter Filter) *Image {
 	checkSize(width, height)
 	r := restorable.NewImage(width, height, glFilter(filter), true)
 	r.Fill(0, 0, 0, 0)
-	i := &Image{r}
+	i := &Image{restorable: r}
 	runtime.SetFinalizer(i, (*Image).Dispose)
 	return i
 }
@@ -283,8 +865,14 @@ func newVolatileImage(width, height int, filter Filter) *Image {
 func NewImageFromImage(source image.Image, filter Filter) (*Image, error) {
 	size := source.Bounds().Size()
 	checkSize(size.X, size.Y)
+	if s := newSharedImage(size.X, size.Y, glFilter(filter)); s != nil {
+		s.initPixels(restorable.CopyImage(source))
+		i := &Image{shared: s}
+		runtime.SetFinalizer(i, (*Image).Dispose)
+		return i, nil
+	}
 	r := restorable.NewImageFromImage(source, glFilter(filter))
-	i := &Image{r}
+	i := &Image{restorable: r}
 	runtime.SetFinalizer(i, (*Image).Dispose)
 	return i, nil
 }
@@ -292,7 +880,7 @@ func NewImageFromImage(source image.Image, filter Filter) (*Image, error) {
 func newImageWithScreenFramebuffer(width, height int, offsetX, offsetY float64) *Image {
 	checkSize(width, height)
 	r := restorable.NewScreenFramebufferImage(width, height, offsetX, offsetY)
-	i := &Image{r}
+	i := &Image{restorable: r}
 	runtime.SetFinalizer(i, (*Image).Dispose)
 	return i
 }
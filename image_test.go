@@ -25,6 +25,7 @@ import (
 	"testing"
 
 	. "github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
 	emath "github.com/hajimehoshi/ebiten/internal/math"
 )
 
@@ -583,6 +584,678 @@ func TestImageTooManyFill(t *testing.T) {
 	}
 }
 
+func TestImageDrawTriangles(t *testing.T) {
+	const w, h = 16, 16
+	src, _ := NewImage(w, h, FilterNearest)
+	src.Fill(color.White)
+	dst, _ := NewImage(w, h, FilterNearest)
+
+	// Draw the upper-left triangle in red.
+	vs := []Vertex{
+		{DstX: 0, DstY: 0, SrcX: 0, SrcY: 0, ColorR: 1, ColorA: 1},
+		{DstX: w, DstY: 0, SrcX: w, SrcY: 0, ColorR: 1, ColorA: 1},
+		{DstX: 0, DstY: h, SrcX: 0, SrcY: h, ColorR: 1, ColorA: 1},
+	}
+	dst.DrawTriangles(vs, []uint16{0, 1, 2}, src, nil)
+
+	if got, want := color.RGBAModel.Convert(dst.At(2, 2)), (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+		t.Errorf("dst At(2, 2): got %#v, want: %#v", got, want)
+	}
+	if got, want := color.RGBAModel.Convert(dst.At(w-2, h-2)), (color.RGBA{}); got != want {
+		t.Errorf("dst At(%d, %d): got %#v, want: %#v", w-2, h-2, got, want)
+	}
+}
+
+func TestImageDrawTrianglesShader(t *testing.T) {
+	const w, h = 16, 16
+	s, err := NewShader([]byte(`package main
+
+var Color vec4
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	if position.x < 8 {
+		return Color
+	}
+	return vec4(0)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Dispose()
+	dst, _ := NewImage(w, h, FilterNearest)
+
+	vs := []Vertex{
+		{DstX: 0, DstY: 0},
+		{DstX: w, DstY: 0},
+		{DstX: 0, DstY: h},
+		{DstX: w, DstY: h},
+	}
+	op := &DrawTrianglesShaderOptions{
+		Uniforms: map[string]interface{}{
+			"Color": []float32{0, 1, 0, 1},
+		},
+	}
+	dst.DrawTrianglesShader(vs, []uint16{0, 1, 2, 1, 2, 3}, s, op)
+
+	if got, want := color.RGBAModel.Convert(dst.At(2, 2)), (color.RGBA{0, 0xff, 0, 0xff}); got != want {
+		t.Errorf("dst At(2, 2): got %#v, want: %#v", got, want)
+	}
+	if got, want := color.RGBAModel.Convert(dst.At(w-2, h-2)), (color.RGBA{}); got != want {
+		t.Errorf("dst At(%d, %d): got %#v, want: %#v", w-2, h-2, got, want)
+	}
+}
+
+func TestImageDrawTrianglesShaderImages(t *testing.T) {
+	const w, h = 4, 4
+	s, err := NewShader([]byte(`package main
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	return imageSrc0At(texCoord) + imageSrc1At(texCoord)
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Dispose()
+
+	src0, _ := NewImage(w, h, FilterNearest)
+	src0.Fill(color.RGBA{0xff, 0, 0, 0xff})
+
+	// Only the right half of src1 is green, and the right half is used as Images[1].
+	pix := image.NewRGBA(image.Rect(0, 0, 2*w, h))
+	for j := 0; j < h; j++ {
+		for i := w; i < 2*w; i++ {
+			pix.Set(i, j, color.RGBA{0, 0xff, 0, 0xff})
+		}
+	}
+	src1, _ := NewImageFromImage(pix, FilterNearest)
+
+	dst, _ := NewImage(w, h, FilterNearest)
+	vs := []Vertex{
+		{DstX: 0, DstY: 0, SrcX: 0, SrcY: 0},
+		{DstX: w, DstY: 0, SrcX: w, SrcY: 0},
+		{DstX: 0, DstY: h, SrcX: 0, SrcY: h},
+		{DstX: w, DstY: h, SrcX: w, SrcY: h},
+	}
+	op := &DrawTrianglesShaderOptions{}
+	op.Images[0] = src0
+	op.Images[1] = src1.SubImage(image.Rect(w, 0, 2*w, h)).(*Image)
+	dst.DrawTrianglesShader(vs, []uint16{0, 1, 2, 1, 2, 3}, s, op)
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			if got, want := color.RGBAModel.Convert(dst.At(i, j)), (color.RGBA{0xff, 0xff, 0, 0xff}); got != want {
+				t.Errorf("dst At(%d, %d): got %#v, want: %#v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageViewport(t *testing.T) {
+	src, _ := NewImage(2, 2, FilterNearest)
+	src.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	dst, _ := NewImage(16, 16, FilterNearest)
+
+	var view GeoM
+	view.Translate(2, 0)
+	v := dst.Viewport(image.Rect(8, 8, 16, 16), view)
+	if got, want := v.Bounds(), image.Rect(8, 8, 16, 16); got != want {
+		t.Errorf("v.Bounds(): got: %v, want: %v", got, want)
+	}
+
+	v.DrawImage(src, nil)
+	op := &DrawImageOptions{}
+	op.GeoM.Translate(-3, 4)
+	v.DrawImage(src, op)
+
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	cases := []struct {
+		X, Y  int
+		Color color.RGBA
+	}{
+		{8, 8, color.RGBA{}},
+		{10, 8, red},
+		{11, 9, red},
+		{12, 8, color.RGBA{}},
+		// The part outside of the viewport is clipped.
+		{7, 12, color.RGBA{}},
+		{8, 12, red},
+	}
+	for _, c := range cases {
+		if got := color.RGBAModel.Convert(dst.At(c.X, c.Y)); got != c.Color {
+			t.Errorf("dst.At(%d, %d): got %#v, want: %#v", c.X, c.Y, got, c.Color)
+		}
+	}
+}
+
+func TestImageDrawMask(t *testing.T) {
+	src, _ := NewImage(4, 4, FilterNearest)
+	src.Fill(color.RGBA{0xff, 0, 0, 0xff})
+
+	// The left half of the mask is opaque, and the right half is transparent.
+	pix := make([]uint8, 4*4*4)
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 2; i++ {
+			pix[4*(4*j+i)+3] = 0xff
+		}
+	}
+	mask, _ := NewImage(4, 4, FilterNearest)
+	mask.ReplacePixels(pix)
+
+	dst, _ := NewImage(8, 8, FilterNearest)
+	op := &DrawImageOptions{}
+	op.Mask = mask
+	dst.DrawImage(src, op)
+	// The destination pixels outside of the mask's bounds are masked.
+	op.GeoM.Translate(4, 4)
+	dst.DrawImage(src, op)
+
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	for j := 0; j < 8; j++ {
+		for i := 0; i < 8; i++ {
+			want := color.RGBA{}
+			if i < 2 && j < 4 {
+				want = red
+			}
+			if got := color.RGBAModel.Convert(dst.At(i, j)); got != want {
+				t.Errorf("dst.At(%d, %d): got %#v, want: %#v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageDrawRectShader(t *testing.T) {
+	s, err := NewShader([]byte(`package main
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	return imageSrc0At(texCoord).bgra * color
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Dispose()
+
+	src, _ := NewImage(4, 4, FilterNearest)
+	src.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	dst, _ := NewImage(16, 16, FilterNearest)
+
+	op := &DrawRectShaderOptions{}
+	op.GeoM.Translate(8, 8)
+	op.Images[0] = src
+	dst.DrawRectShader(4, 4, s, op)
+
+	if got, want := color.RGBAModel.Convert(dst.At(9, 9)), (color.RGBA{0, 0, 0xff, 0xff}); got != want {
+		t.Errorf("dst At(9, 9): got %#v, want: %#v", got, want)
+	}
+	if got, want := color.RGBAModel.Convert(dst.At(7, 7)), (color.RGBA{}); got != want {
+		t.Errorf("dst At(7, 7): got %#v, want: %#v", got, want)
+	}
+	if got, want := color.RGBAModel.Convert(dst.At(12, 12)), (color.RGBA{}); got != want {
+		t.Errorf("dst At(12, 12): got %#v, want: %#v", got, want)
+	}
+}
+
+func TestImageBlend(t *testing.T) {
+	src, _ := NewImage(1, 1, FilterNearest)
+	src.Fill(color.RGBA{0x80, 0x40, 0xff, 0xff})
+	dst, _ := NewImage(1, 1, FilterNearest)
+
+	dst.Fill(color.RGBA{0xff, 0xff, 0x80, 0xff})
+	dst.DrawImage(src, &DrawImageOptions{
+		CompositeMode: CompositeModeMultiply,
+	})
+	if got, want := color.RGBAModel.Convert(dst.At(0, 0)), (color.RGBA{0x80, 0x40, 0x80, 0xff}); got != want {
+		t.Errorf("multiply: got %#v, want: %#v", got, want)
+	}
+
+	// Subtract the source from the destination.
+	dst.Fill(color.RGBA{0xff, 0xff, 0xff, 0xff})
+	dst.DrawImage(src, &DrawImageOptions{
+		Blend: &Blend{
+			SourceRGB:        BlendFactorOne,
+			SourceAlpha:      BlendFactorZero,
+			DestinationRGB:   BlendFactorOne,
+			DestinationAlpha: BlendFactorOne,
+			OperationRGB:     BlendOperationReverseSubtract,
+			OperationAlpha:   BlendOperationAdd,
+		},
+	})
+	if got, want := color.RGBAModel.Convert(dst.At(0, 0)), (color.RGBA{0x7f, 0xbf, 0, 0xff}); got != want {
+		t.Errorf("reverse subtract: got %#v, want: %#v", got, want)
+	}
+}
+
+func TestImageDrawFilter(t *testing.T) {
+	src, _ := NewImage(2, 1, FilterNearest)
+	src.ReplacePixels([]uint8{0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	for _, c := range []struct {
+		filter Filter
+		linear bool
+	}{
+		{FilterDefault, false},
+		{FilterNearest, false},
+		{FilterLinear, true},
+	} {
+		dst, _ := NewImage(8, 1, FilterNearest)
+		op := &DrawImageOptions{
+			Filter: c.filter,
+		}
+		op.GeoM.Scale(4, 1)
+		dst.DrawImage(src, op)
+		r, _, _, _ := dst.At(3, 0).RGBA()
+		r >>= 8
+		if got := 0 < r && r < 0xff; got != c.linear {
+			t.Errorf("filter %d: dst.At(3, 0) red: %d, linear: got %t, want: %t", c.filter, r, got, c.linear)
+		}
+	}
+}
+
+func TestImageDrawMipmap(t *testing.T) {
+	const size = 8
+	src, _ := NewImage(size, size, FilterLinear)
+	pix := make([]uint8, 4*size*size)
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			if (i+j)%2 == 0 {
+				continue
+			}
+			idx := 4 * (i + j*size)
+			pix[idx] = 0xff
+			pix[idx+1] = 0xff
+			pix[idx+2] = 0xff
+			pix[idx+3] = 0xff
+		}
+	}
+	src.ReplacePixels(pix)
+
+	dst, _ := NewImage(1, 1, FilterNearest)
+	op := &DrawImageOptions{}
+	op.GeoM.Scale(1.0/size, 1.0/size)
+	dst.DrawImage(src, op)
+	r, _, _, _ := dst.At(0, 0).RGBA()
+	r >>= 8
+	if r == 0 || r == 0xff {
+		t.Errorf("dst.At(0, 0) red: %d, want: neither 0 nor 0xff", r)
+	}
+
+	// The mipmaps must be regenerated after the source is modified.
+	src.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	dst.Clear()
+	dst.DrawImage(src, op)
+	got := dst.At(0, 0).(color.RGBA)
+	want := color.RGBA{0xff, 0, 0, 0xff}
+	if got != want {
+		t.Errorf("dst.At(0, 0) after modifying the source: got %v, want: %v", got, want)
+	}
+}
+
+func TestImageDrawAnisotropic(t *testing.T) {
+	const size = 8
+	src, _ := NewImage(size, size, FilterAnisotropic)
+	pix := make([]uint8, 4*size*size)
+	for i := 0; i < size*size; i++ {
+		// Odd rows are white.
+		if (i/size)%2 == 0 {
+			continue
+		}
+		copy(pix[4*i:], []uint8{0xff, 0xff, 0xff, 0xff})
+	}
+	src.ReplacePixels(pix)
+
+	// Shrink the source only vertically, where the rows are averaged.
+	dst, _ := NewImage(size, 1, FilterNearest)
+	op := &DrawImageOptions{}
+	op.GeoM.Scale(1, 1.0/size)
+	dst.DrawImage(src, op)
+	for i := 0; i < size; i++ {
+		r, _, _, _ := dst.At(i, 0).RGBA()
+		r >>= 8
+		if r == 0 || r == 0xff {
+			t.Errorf("dst.At(%d, 0) red: %d, want: neither 0 nor 0xff", i, r)
+		}
+	}
+}
+
+func TestImageSubImage(t *testing.T) {
+	src, _ := NewImage(4, 4, FilterNearest)
+	pix := make([]uint8, 4*4*4)
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			idx := 4 * (i + j*4)
+			pix[idx] = uint8(i * 0x40)
+			pix[idx+1] = uint8(j * 0x40)
+			pix[idx+3] = 0xff
+		}
+	}
+	src.ReplacePixels(pix)
+
+	sub := src.SubImage(image.Rect(1, 2, 3, 4)).(*Image)
+	if got, want := sub.Bounds(), image.Rect(1, 2, 3, 4); got != want {
+		t.Errorf("sub.Bounds(): got %v, want: %v", got, want)
+	}
+	if got, want := sub.At(0, 0), color.Transparent; got != want {
+		t.Errorf("sub.At(0, 0): got %v, want: %v", got, want)
+	}
+	if got, want := sub.At(2, 3), src.At(2, 3); got != want {
+		t.Errorf("sub.At(2, 3): got %v, want: %v", got, want)
+	}
+
+	dst, _ := NewImage(4, 4, FilterNearest)
+	dst.DrawImage(sub, nil)
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := color.RGBA{}
+			if i < 2 && j < 2 {
+				want = color.RGBA{uint8((i + 1) * 0x40), uint8((j + 2) * 0x40), 0, 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+
+	// SourceRect is clipped by the sub-image's bounds.
+	dst.Clear()
+	r := image.Rect(0, 0, 2, 3)
+	dst.DrawImage(sub, &DrawImageOptions{SourceRect: &r})
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			got := dst.At(i, j).(color.RGBA)
+			want := color.RGBA{}
+			if i == 1 && j == 2 {
+				want = color.RGBA{0x40, 0x80, 0, 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d) with SourceRect: got %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageReplacePixelsSubImage(t *testing.T) {
+	img, _ := NewImage(4, 4, FilterNearest)
+	img.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	sub := img.SubImage(image.Rect(1, 1, 3, 2)).(*Image)
+	sub.ReplacePixels([]uint8{0, 0, 0xff, 0xff, 0, 0, 0xff, 0xff})
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			got := img.At(i, j)
+			want := color.RGBA{0xff, 0, 0, 0xff}
+			if 1 <= i && i < 3 && j == 1 {
+				want = color.RGBA{0, 0, 0xff, 0xff}
+			}
+			if got != want {
+				t.Errorf("img.At(%d, %d): got %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageReadPixels(t *testing.T) {
+	img, _ := NewImage(3, 2, FilterNearest)
+	pix := make([]uint8, 4*3*2)
+	for i := range pix {
+		pix[i] = uint8(i)
+	}
+	img.ReplacePixels(pix)
+
+	dst := make([]uint8, len(pix))
+	if err := img.ReadPixels(dst); err != nil {
+		t.Fatal(err)
+	}
+	for i := range pix {
+		if dst[i] != pix[i] {
+			t.Errorf("dst[%d]: got %d, want: %d", i, dst[i], pix[i])
+		}
+	}
+
+	sub := img.SubImage(image.Rect(1, 1, 3, 2)).(*Image)
+	dst = make([]uint8, 4*2*1)
+	if err := sub.ReadPixels(dst); err != nil {
+		t.Fatal(err)
+	}
+	for i := range dst {
+		if want := pix[4*3+4+i]; dst[i] != want {
+			t.Errorf("sub-image dst[%d]: got %d, want: %d", i, dst[i], want)
+		}
+	}
+}
+
+func TestImageAntialias(t *testing.T) {
+	src, _ := NewImage(1, 1, FilterNearest)
+	src.Fill(color.White)
+
+	dst, _ := NewImageWithOptions(16, 16, &NewImageOptions{Antialias: true})
+	vs := []Vertex{
+		{DstX: 0, DstY: 0, SrcX: 0, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: 16, DstY: 0, SrcX: 1, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		{DstX: 0, DstY: 16, SrcX: 0, SrcY: 1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+	}
+	dst.DrawTriangles(vs, []uint16{0, 1, 2}, src, nil)
+
+	// Whether the edge is smooth depends on the environment, but the result must be valid anyway.
+	if got, want := dst.At(2, 2), (color.RGBA{0xff, 0xff, 0xff, 0xff}); got != want {
+		t.Errorf("dst.At(2, 2): got %v, want: %v", got, want)
+	}
+	if got, want := dst.At(13, 13), (color.RGBA{}); got != want {
+		t.Errorf("dst.At(13, 13): got %v, want: %v", got, want)
+	}
+
+	// The drawn result must be kept after the pixels are partly replaced.
+	sub := dst.SubImage(image.Rect(12, 12, 13, 13)).(*Image)
+	sub.ReplacePixels([]uint8{0, 0, 0xff, 0xff})
+	dst2, _ := NewImage(16, 16, FilterNearest)
+	dst2.DrawImage(dst, nil)
+	if got, want := dst2.At(2, 2), (color.RGBA{0xff, 0xff, 0xff, 0xff}); got != want {
+		t.Errorf("dst2.At(2, 2): got %v, want: %v", got, want)
+	}
+	if got, want := dst2.At(12, 12), (color.RGBA{0, 0, 0xff, 0xff}); got != want {
+		t.Errorf("dst2.At(12, 12): got %v, want: %v", got, want)
+	}
+}
+
+func TestImageDrawSubImage(t *testing.T) {
+	src, _ := NewImage(16, 16, FilterNearest)
+	src.Fill(color.White)
+
+	dst, _ := NewImage(16, 16, FilterNearest)
+	dst.Fill(color.RGBA{0, 0, 0xff, 0xff})
+	sub := dst.SubImage(image.Rect(4, 4, 12, 8)).(*Image)
+	sub.Fill(color.RGBA{0xff, 0, 0, 0xff})
+	op := &DrawImageOptions{}
+	op.GeoM.Translate(8, 0)
+	sub.DrawImage(src, op)
+	for j := 0; j < 16; j++ {
+		for i := 0; i < 16; i++ {
+			got := dst.At(i, j)
+			want := color.RGBA{0, 0, 0xff, 0xff}
+			if 4 <= i && i < 12 && 4 <= j && j < 8 {
+				if i < 8 {
+					want = color.RGBA{0xff, 0, 0, 0xff}
+				} else {
+					want = color.RGBA{0xff, 0xff, 0xff, 0xff}
+				}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestImageDrawTrianglesAddress(t *testing.T) {
+	src, _ := NewImage(4, 1, FilterNearest)
+	src.ReplacePixels([]uint8{
+		0, 0, 0xff, 0xff,
+		0xff, 0, 0, 0xff,
+		0, 0xff, 0, 0xff,
+		0, 0, 0xff, 0xff,
+	})
+	// The blue pixels at the both ends must not be drawn.
+	sub := src.SubImage(image.Rect(1, 0, 3, 1)).(*Image)
+
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	green := color.RGBA{0, 0xff, 0, 0xff}
+	for _, c := range []struct {
+		address Address
+		want    []color.RGBA
+	}{
+		{AddressRepeat, []color.RGBA{red, green, red, green, red, green, red, green}},
+		{AddressMirroredRepeat, []color.RGBA{red, green, green, red, red, green, green, red}},
+	} {
+		dst, _ := NewImage(8, 1, FilterNearest)
+		vs := []Vertex{
+			{DstX: 0, DstY: 0, SrcX: 1, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+			{DstX: 8, DstY: 0, SrcX: 9, SrcY: 0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+			{DstX: 0, DstY: 1, SrcX: 1, SrcY: 1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+			{DstX: 8, DstY: 1, SrcX: 9, SrcY: 1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		}
+		op := &DrawTrianglesOptions{
+			Address: c.address,
+		}
+		dst.DrawTriangles(vs, []uint16{0, 1, 2, 1, 2, 3}, sub, op)
+		for i, want := range c.want {
+			if got := dst.At(i, 0); got != want {
+				t.Errorf("address: %d, dst.At(%d, 0): got %v, want: %v", c.address, i, got, want)
+			}
+		}
+	}
+}
+
+func TestImageHDR(t *testing.T) {
+	if !IsHDRAvailable() {
+		t.Skip("HDR images are not available")
+	}
+	src, _ := NewImage(1, 1, FilterNearest)
+	src.Fill(color.White)
+
+	hdr, _ := NewImageWithOptions(1, 1, &NewImageOptions{HDR: true})
+	// Draw the color (2, 2, 2, 1), which is not clamped in the HDR image.
+	vs := []Vertex{
+		{DstX: 0, DstY: 0, SrcX: 0, SrcY: 0, ColorR: 2, ColorG: 2, ColorB: 2, ColorA: 1},
+		{DstX: 1, DstY: 0, SrcX: 1, SrcY: 0, ColorR: 2, ColorG: 2, ColorB: 2, ColorA: 1},
+		{DstX: 0, DstY: 1, SrcX: 0, SrcY: 1, ColorR: 2, ColorG: 2, ColorB: 2, ColorA: 1},
+		{DstX: 1, DstY: 1, SrcX: 1, SrcY: 1, ColorR: 2, ColorG: 2, ColorB: 2, ColorA: 1},
+	}
+	hdr.DrawTriangles(vs, []uint16{0, 1, 2, 1, 2, 3}, src, nil)
+
+	dst, _ := NewImage(1, 1, FilterNearest)
+	ebitenutil.DrawToneMapped(dst, hdr, &ebitenutil.ToneMapOptions{
+		Exposure: 0.25,
+		Operator: ebitenutil.ToneMapClamp,
+	})
+	got := dst.At(0, 0).(color.RGBA)
+	if diff(got.R, 0x80) > 1 || diff(got.G, 0x80) > 1 || diff(got.B, 0x80) > 1 || got.A != 0xff {
+		t.Errorf("dst.At(0, 0): got %v, want: %v", got, color.RGBA{0x80, 0x80, 0x80, 0xff})
+	}
+}
+
+func TestImagePalette(t *testing.T) {
+	pal := color.Palette{
+		color.RGBA{0xff, 0, 0, 0xff},
+		color.RGBA{0, 0xff, 0, 0xff},
+		color.RGBA{0, 0, 0xff, 0xff},
+		color.RGBA{0x80, 0x80, 0x80, 0x80},
+	}
+	pimg := image.NewPaletted(image.Rect(0, 0, 4, 1), pal)
+	for i := 0; i < 4; i++ {
+		pimg.SetColorIndex(i, 0, uint8(i))
+	}
+	src, _ := NewIndexedImage(pimg)
+	p := NewPalette(pal)
+
+	dst, _ := NewImage(4, 1, FilterNearest)
+	dst.DrawImage(src, &DrawImageOptions{Palette: p})
+	for i := 0; i < 4; i++ {
+		got := dst.At(i, 0).(color.RGBA)
+		want := pal[i].(color.RGBA)
+		if diff(got.R, want.R) > 1 || diff(got.G, want.G) > 1 || diff(got.B, want.B) > 1 || diff(got.A, want.A) > 1 {
+			t.Errorf("dst.At(%d, 0): got %v, want: %v", i, got, want)
+		}
+	}
+
+	// Swap the colors.
+	p.SetColor(0, pal[2])
+	p.SetColor(2, pal[0])
+	dst.Clear()
+	dst.DrawImage(src, &DrawImageOptions{Palette: p})
+	for i, c := range []color.Color{pal[2], pal[1], pal[0], pal[3]} {
+		got := dst.At(i, 0).(color.RGBA)
+		want := c.(color.RGBA)
+		if diff(got.R, want.R) > 1 || diff(got.G, want.G) > 1 || diff(got.B, want.B) > 1 || diff(got.A, want.A) > 1 {
+			t.Errorf("dst.At(%d, 0): got %v, want: %v", i, got, want)
+		}
+	}
+}
+
+func TestImageVolatile(t *testing.T) {
+	src, _ := NewImageWithOptions(16, 16, &NewImageOptions{Volatile: true})
+	src.Fill(color.RGBA{0xff, 0, 0, 0xff})
+
+	// A volatile image works as a regular image in the same frame.
+	dst, _ := NewImage(16, 16, FilterNearest)
+	dst.DrawImage(src, nil)
+	if got, want := dst.At(8, 8), (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+		t.Errorf("dst.At(8, 8): got %v, want: %v", got, want)
+	}
+}
+
+func TestImageAtlas(t *testing.T) {
+	const size = 8
+	colors := []color.RGBA{
+		{0xff, 0, 0, 0xff},
+		{0, 0xff, 0, 0xff},
+		{0, 0, 0xff, 0xff},
+	}
+	var imgs []*Image
+	for _, c := range colors {
+		src := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.Draw(src, src.Bounds(), &image.Uniform{c}, image.ZP, draw.Src)
+		img, _ := NewImageFromImage(src, FilterNearest)
+		imgs = append(imgs, img)
+	}
+	defer func() {
+		for _, img := range imgs {
+			img.Dispose()
+		}
+	}()
+
+	dst, _ := NewImage(size*len(imgs), size, FilterNearest)
+	for i, img := range imgs {
+		if got, want := img.Bounds(), image.Rect(0, 0, size, size); got != want {
+			t.Errorf("imgs[%d].Bounds(): got: %v, want: %v", i, got, want)
+		}
+		if got, want := img.At(size-1, size-1), colors[i]; got != want {
+			t.Errorf("imgs[%d].At(%d, %d): got: %v, want: %v", i, size-1, size-1, got, want)
+		}
+		op := &DrawImageOptions{}
+		op.GeoM.Translate(float64(i*size), 0)
+		dst.DrawImage(img, op)
+	}
+	for i := range imgs {
+		for _, p := range []image.Point{{i * size, 0}, {i*size + size - 1, size - 1}} {
+			if got, want := dst.At(p.X, p.Y), colors[i]; got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", p.X, p.Y, got, want)
+			}
+		}
+	}
+
+	// An image in an atlas can be a render target.
+	imgs[1].Fill(color.White)
+	if got, want := imgs[1].At(0, 0), (color.RGBA{0xff, 0xff, 0xff, 0xff}); got != want {
+		t.Errorf("imgs[1].At(0, 0) after Fill: got: %v, want: %v", got, want)
+	}
+	for _, i := range []int{0, 2} {
+		if got, want := imgs[i].At(0, 0), colors[i]; got != want {
+			t.Errorf("imgs[%d].At(0, 0) after Fill: got: %v, want: %v", i, got, want)
+		}
+	}
+}
+
 func BenchmarkDrawImage(b *testing.B) {
 	img0, _ := NewImage(16, 16, FilterNearest)
 	img1, _ := NewImage(16, 16, FilterNearest)
@@ -15,9 +15,58 @@
 package ebiten
 
 import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/internal/gamepaddb"
 	"github.com/hajimehoshi/ebiten/internal/ui"
 )
 
+// input is the source of the input state.
+// This is implemented by *ui.Input for the actual input and by *ui.InputState for the replayed input.
+type input interface {
+	RuneBuffer() []rune
+	IMEComposition() (string, int)
+	CursorDelta() (float64, float64)
+	RawMouseDelta() (float64, float64)
+	IsRawMouseDeltaSupported() bool
+	Wheel() (float64, float64)
+	DroppedFiles() []string
+	IsKeyPressed(key ui.Key) bool
+	CursorPosition() (x, y int)
+	IsMouseButtonPressed(button ui.MouseButton) bool
+	GamepadIDs() []int
+	GamepadName(id int) string
+	GamepadGUID(id int) string
+	GamepadVendorProductID(id int) (vendor, product int)
+	JustConnectedGamepadIDs() []int
+	JustDisconnectedGamepadIDs() []int
+	GamepadAxisNum(id int) int
+	GamepadAxis(id int, axis int) float64
+	GamepadButtonNum(id int) int
+	IsGamepadButtonPressed(id int, button ui.GamepadButton) bool
+	IsStandardGamepadLayoutAvailable(id int) bool
+	StandardGamepadButtonValue(id int, button gamepaddb.StandardButton) float64
+	IsStandardGamepadButtonPressed(id int, button gamepaddb.StandardButton) bool
+	StandardGamepadAxisValue(id int, axis gamepaddb.StandardAxis) float64
+	TouchIDs() []int
+	TouchPosition(id int) (x, y int)
+	Touches() []ui.Touch
+	IsPenInRange() bool
+	PenPosition() (x, y int)
+	PenPressure() float64
+	PenTilt() (x, y float64)
+	IsPenEraser() bool
+	IsCapsLockOn() bool
+	IsNumLockOn() bool
+}
+
+func currentInput() input {
+	if s := ui.ReplayedInputState(); s != nil {
+		return s
+	}
+	return ui.CurrentInput()
+}
+
 // InputChars return "printable" runes read from the keyboard at the time update is called.
 //
 // InputChars represents the environment's locale-dependent translation of keyboard
@@ -26,24 +75,170 @@ import (
 // IsKeyPressed is based on a mapping of device (US keyboard) codes to input device keys.
 // "Control" and modifier keys should be handled with IsKeyPressed.
 //
+// On desktops, InputChars includes the characters committed by input methods (IMEs).
+// The text being composed (preedit) is not included. Use IMEComposition to get it.
+// To place IMEs' windows near a text box, use SetIMEPosition.
+//
 // This function is concurrent-safe.
 func InputChars() []rune {
-	rb := ui.CurrentInput().RuneBuffer()
+	rb := currentInput().RuneBuffer()
 	return append(make([]rune, 0, len(rb)), rb...)
 }
 
+// AppendInputChars appends "printable" runes read from the keyboard at the time update is called to runes,
+// and returns the extended slice.
+//
+// AppendInputChars returns the same characters as InputChars, including the ones respecting
+// the keyboard layout, the shift state and dead keys. Unlike InputChars, AppendInputChars doesn't
+// allocate a new slice when runes has enough capacity, and is suitable to be called every frame, e.g.:
+//
+//     var chars []rune
+//
+//     func update(screen *ebiten.Image) error {
+//         chars = ebiten.AppendInputChars(chars[:0])
+//         // ...
+//     }
+//
+// This function is concurrent-safe.
+func AppendInputChars(runes []rune) []rune {
+	return append(runes, currentInput().RuneBuffer()...)
+}
+
+// CursorDelta returns the movement of the mouse cursor since the previous frame.
+//
+// The unit is the same as CursorPosition, but the values are not rounded.
+// CursorDelta is useful especially with CursorModeCaptured,
+// where the cursor position is not meaningful.
+//
+// On desktops, the movement is based on the cursor positions reported by the OS,
+// and might be affected by the OS's pointer acceleration.
+//
+// CursorDelta always returns (0, 0) on mobiles.
+//
+// This function is concurrent-safe.
+func CursorDelta() (dx, dy float64) {
+	return currentInput().CursorDelta()
+}
+
+// RawMouseDelta returns the raw movement of the mouse since the previous frame.
+//
+// Unlike CursorDelta, the movement is not affected by the OS's pointer acceleration
+// and is not clamped at the edges of the screen,
+// which is suitable e.g. for aiming in first-person games.
+// The unit depends on the platform and the mouse: it is the mouse's counts on Windows,
+// and the unscaled CSS pixels on browsers.
+//
+// RawMouseDelta works only when IsRawMouseDeltaSupported returns true.
+// Otherwise, RawMouseDelta returns (0, 0), and CursorDelta should be used instead.
+//
+// This function is concurrent-safe.
+func RawMouseDelta() (dx, dy float64) {
+	return currentInput().RawMouseDelta()
+}
+
+// IsRawMouseDeltaSupported returns a boolean value indicating whether RawMouseDelta is available.
+//
+// Raw mouse motion is supported only on Windows and on browsers supporting the unadjusted movement
+// of the Pointer Lock API so far.
+// On browsers, raw mouse motion is available only while the pointer is locked by CursorModeCaptured.
+//
+// This function is concurrent-safe.
+func IsRawMouseDeltaSupported() bool {
+	return currentInput().IsRawMouseDeltaSupported()
+}
+
+// Wheel returns the amount of the mouse wheel scrolled since the previous frame.
+//
+// The unit is the number of wheel notches. Trackpads and high-resolution wheels might report fractional values.
+// x is positive when scrolled to the left, and y is positive when scrolled up.
+//
+// Wheel always returns (0, 0) on mobiles.
+//
+// This function is concurrent-safe.
+func Wheel() (x, y float64) {
+	return currentInput().Wheel()
+}
+
+// IMEComposition returns the text being composed by the input method (IME) and the caret position in the text.
+//
+// caret is the number of the runes before the caret in text.
+// IMEComposition returns an empty string and 0 when no text is being composed.
+// When the composition ends, the committed text is reported by InputChars.
+//
+// IMEComposition works only on Windows so far.
+// On other platforms, IMEComposition always returns an empty string and 0, and IMEs show the text by themselves.
+//
+// This function is concurrent-safe.
+func IMEComposition() (text string, caret int) {
+	return currentInput().IMEComposition()
+}
+
+// SetIMEPosition sets the position where input methods (IMEs) show their composition and candidate windows.
+//
+// (x, y) is the position on the screen image, usually the caret position of a text box.
+//
+// SetIMEPosition works only on Windows so far.
+// On other platforms, IMEs decide the positions by themselves.
+//
+// This function is concurrent-safe.
+func SetIMEPosition(x, y int) {
+	ui.SetIMEPosition(x, y)
+}
+
+// DroppedFiles returns the files dropped onto the game window at the time update is called.
+//
+// On desktops, DroppedFiles returns the paths of the files.
+//
+// On browsers, DroppedFiles returns object URLs ("blob:...") of the files instead,
+// which can be read by ebitenutil.OpenFile.
+//
+// DroppedFiles always returns an empty slice on mobiles.
+//
+// This function is concurrent-safe.
+func DroppedFiles() []string {
+	fs := currentInput().DroppedFiles()
+	return append(make([]string, 0, len(fs)), fs...)
+}
+
 // IsKeyPressed returns a boolean indicating whether key is pressed.
 //
+// key represents a physical key regardless of the keyboard layout.
+// For example, IsKeyPressed(KeyW) reports the key at the position of W on US keyboards,
+// which is Z on AZERTY keyboards. This is suitable for e.g. WASD movements.
+// To handle a key by the character printed on it, use KeyForName.
+//
 // This function is concurrent-safe.
 func IsKeyPressed(key Key) bool {
-	return ui.CurrentInput().IsKeyPressed(ui.Key(key))
+	return currentInput().IsKeyPressed(ui.Key(key))
+}
+
+// IsCapsLockOn returns a boolean value indicating whether Caps Lock is on.
+//
+// Unlike IsKeyPressed(KeyCapsLock), IsCapsLockOn reports the toggled state of the lock.
+//
+// On browsers, the state is updated only when a keyboard or mouse event happens on the canvas.
+// IsCapsLockOn always returns false on mobiles.
+//
+// This function is concurrent-safe.
+func IsCapsLockOn() bool {
+	return currentInput().IsCapsLockOn()
+}
+
+// IsNumLockOn returns a boolean value indicating whether Num Lock is on.
+//
+// On browsers, the state is updated only when a keyboard or mouse event happens on the canvas.
+// IsNumLockOn always returns false on macOS, which doesn't have Num Lock, and on mobiles.
+//
+// This function is concurrent-safe.
+func IsNumLockOn() bool {
+	return currentInput().IsNumLockOn()
 }
 
 // CursorPosition returns a position of a mouse cursor.
 //
 // This function is concurrent-safe.
 func CursorPosition() (x, y int) {
-	return ui.CurrentInput().CursorPosition()
+	return currentInput().CursorPosition()
 }
 
 // IsMouseButtonPressed returns a boolean indicating whether mouseButton is pressed.
@@ -53,47 +248,115 @@ func CursorPosition() (x, y int) {
 // Note that touch events not longer affect this function's result as of 1.4.0-alpha.
 // Use Touches instead.
 func IsMouseButtonPressed(mouseButton MouseButton) bool {
-	return ui.CurrentInput().IsMouseButtonPressed(ui.MouseButton(mouseButton))
+	return currentInput().IsMouseButtonPressed(ui.MouseButton(mouseButton))
 }
 
 // GamepadIDs returns a slice indicating available gamepad IDs.
 //
+// The IDs include the virtual gamepads created by the virtualgamepad package.
+//
 // This function is concurrent-safe.
 //
-// This function always returns an empty slice on mobiles.
+// This function returns only the virtual gamepads on mobiles.
 func GamepadIDs() []int {
-	return ui.CurrentInput().GamepadIDs()
+	return currentInput().GamepadIDs()
+}
+
+// JustConnectedGamepadIDs returns a slice indicating IDs of the gamepads connected since the previous frame.
+//
+// This is useful e.g. to start using a gamepad as soon as the player plugs it in.
+//
+// This function is concurrent-safe.
+//
+// This function always returns an empty slice on mobiles.
+func JustConnectedGamepadIDs() []int {
+	ids := currentInput().JustConnectedGamepadIDs()
+	return append(make([]int, 0, len(ids)), ids...)
+}
+
+// JustDisconnectedGamepadIDs returns a slice indicating IDs of the gamepads disconnected since the previous frame.
+//
+// This is useful e.g. to pause the game when the gamepad the player is using is unplugged.
+// The ID might be reused for another gamepad connected later.
+//
+// This function is concurrent-safe.
+//
+// This function always returns an empty slice on mobiles.
+func JustDisconnectedGamepadIDs() []int {
+	ids := currentInput().JustDisconnectedGamepadIDs()
+	return append(make([]int, 0, len(ids)), ids...)
+}
+
+// GamepadName returns the name of the gamepad (id), e.g. "Xbox 360 Controller".
+//
+// GamepadName returns an empty string when the gamepad (id) is not connected.
+// The name is given by the OS or the browser, and might differ among platforms for the same gamepad.
+//
+// This function is concurrent-safe.
+func GamepadName(id int) string {
+	return currentInput().GamepadName(id)
+}
+
+// GamepadGUID returns the GUID of the gamepad (id) as 32 hexadecimal characters.
+//
+// The format is the same as SDL 2's, so the GUID can be used e.g. to persist a key binding for a device.
+// When the vendor and the product IDs are unknown, the GUID is made from the gamepad's name.
+//
+// GamepadGUID returns an empty string when the gamepad (id) is not connected.
+//
+// This function is concurrent-safe.
+func GamepadGUID(id int) string {
+	return currentInput().GamepadGUID(id)
+}
+
+// GamepadVendorProductID returns the USB vendor and product IDs of the gamepad (id).
+//
+// GamepadVendorProductID returns (0, 0) when the IDs are unknown or the gamepad (id) is not connected.
+// The IDs are available only on Linux and on browsers reporting them in their gamepad IDs so far.
+//
+// This function is concurrent-safe.
+func GamepadVendorProductID(id int) (vendorID, productID int) {
+	return currentInput().GamepadVendorProductID(id)
 }
 
 // GamepadAxisNum returns the number of axes of the gamepad (id).
 //
+// GamepadAxisNum returns 0 when the gamepad (id) is not connected.
+//
 // This function is concurrent-safe.
 //
 // This function always returns 0 on mobiles.
 func GamepadAxisNum(id int) int {
-	return ui.CurrentInput().GamepadAxisNum(id)
+	return currentInput().GamepadAxisNum(id)
 }
 
 // GamepadAxis returns the float value [-1.0 - 1.0] of the given gamepad (id)'s axis (axis).
 //
+// The axis is in the range [0, GamepadAxisNum(id)).
+// GamepadAxis returns 0 when the gamepad (id) is not connected or the axis is out of the range.
+//
 // This function is concurrent-safe.
 //
 // This function always returns 0 on mobiles.
 func GamepadAxis(id int, axis int) float64 {
-	return ui.CurrentInput().GamepadAxis(id, axis)
+	return currentInput().GamepadAxis(id, axis)
 }
 
 // GamepadButtonNum returns the number of the buttons of the given gamepad (id).
 //
+// GamepadButtonNum returns 0 when the gamepad (id) is not connected.
+//
 // This function is concurrent-safe.
 //
 // This function always returns 0 on mobiles.
 func GamepadButtonNum(id int) int {
-	return ui.CurrentInput().GamepadButtonNum(id)
+	return currentInput().GamepadButtonNum(id)
 }
 
 // IsGamepadButtonPressed returns the boolean indicating the given button of the gamepad (id) is pressed or not.
 //
+// IsGamepadButtonPressed returns false when the gamepad (id) is not connected or the gamepad doesn't have the button.
+//
 // This function is concurrent-safe.
 //
 // The button states vary depending on environments.
@@ -102,7 +365,26 @@ func GamepadButtonNum(id int) int {
 //
 // This function always returns false on mobiles.
 func IsGamepadButtonPressed(id int, button GamepadButton) bool {
-	return ui.CurrentInput().IsGamepadButtonPressed(id, ui.GamepadButton(button))
+	return currentInput().IsGamepadButtonPressed(id, ui.GamepadButton(button))
+}
+
+// VibrateGamepad vibrates the gamepad (id) for the given duration.
+//
+// strongMagnitude and weakMagnitude are the strengths [0.0 - 1.0] of the low-frequency (strong) motor
+// and the high-frequency (weak) motor.
+// Calling VibrateGamepad while the gamepad is vibrating replaces the vibration.
+// VibrateGamepad with a non-positive duration stops the vibration.
+//
+// VibrateGamepad works with XInput gamepads on Windows, gamepads supporting force feedback on Linux,
+// and browsers supporting the Gamepad API's vibrationActuator.
+// On Linux, the write permission to the gamepad's event device (/dev/input/event*) is required.
+// VibrateGamepad does nothing when the gamepad doesn't support vibration.
+//
+// VibrateGamepad does nothing on macOS and mobiles.
+//
+// This function is concurrent-safe.
+func VibrateGamepad(id int, duration time.Duration, strongMagnitude, weakMagnitude float64) {
+	ui.CurrentInput().VibrateGamepad(id, duration, strongMagnitude, weakMagnitude)
 }
 
 // Touch represents a touch state.
@@ -114,14 +396,81 @@ type Touch interface {
 	Position() (x, y int)
 }
 
+// TouchIDs returns the IDs of the current touches.
+//
+// An ID identifies one stroke from touching to releasing, and is not reused while the touch continues.
+//
+// On desktops, touches are available only on Windows 7 or later so far.
+//
+// This function is concurrent-safe.
+func TouchIDs() []int {
+	return currentInput().TouchIDs()
+}
+
+// TouchPosition returns the position of the touch (id) in the same way as CursorPosition.
+//
+// TouchPosition returns (0, 0) when the touch (id) doesn't exist.
+//
+// This function is concurrent-safe.
+func TouchPosition(id int) (x, y int) {
+	return currentInput().TouchPosition(id)
+}
+
 // Touches returns the current touch states.
 //
-// Touches always returns nil on desktops.
+// On desktops, touches are available only on Windows 7 or later so far.
+// Use TouchIDs and TouchPosition to get the positions adjusted in the same way as CursorPosition.
 func Touches() []Touch {
-	t := ui.CurrentInput().Touches()
+	t := currentInput().Touches()
 	tt := make([]Touch, len(t))
 	for i := 0; i < len(tt); i++ {
 		tt[i] = t[i]
 	}
 	return tt
 }
+
+// IsPenInRange returns a boolean value indicating whether a pen (stylus) is touching or hovering over the screen.
+//
+// Pens are available only on Windows 8 or later and on browsers supporting the Pointer Events so far.
+// The pen also works as the mouse at the same time.
+//
+// This function is concurrent-safe.
+func IsPenInRange() bool {
+	return currentInput().IsPenInRange()
+}
+
+// PenPosition returns the position of the pen, adjusted in the same way as CursorPosition.
+//
+// PenPosition returns (0, 0) when the pen is not in range.
+//
+// This function is concurrent-safe.
+func PenPosition() (x, y int) {
+	return currentInput().PenPosition()
+}
+
+// PenPressure returns the pressure of the pen in [0, 1].
+//
+// PenPressure returns 0 when the pen is not touching the screen.
+//
+// This function is concurrent-safe.
+func PenPressure() float64 {
+	return currentInput().PenPressure()
+}
+
+// PenTilt returns the tilt of the pen in degrees in [-90, 90].
+//
+// x is positive when the pen tilts to the right, and y is positive when the pen tilts toward the user.
+// PenTilt returns (0, 0) when the pen or the platform doesn't support tilts.
+//
+// This function is concurrent-safe.
+func PenTilt() (x, y float64) {
+	return currentInput().PenTilt()
+}
+
+// IsPenEraser returns a boolean value indicating whether the pen is used as an eraser,
+// e.g. the pen is inverted or its eraser button is pressed.
+//
+// This function is concurrent-safe.
+func IsPenEraser() bool {
+	return currentInput().IsPenEraser()
+}
@@ -0,0 +1,321 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+type bindingType int
+
+const (
+	bindingTypeKey bindingType = iota
+	bindingTypeMouseButton
+	bindingTypeGamepadButton
+	bindingTypeStandardGamepadButton
+	bindingTypeGamepadAxis
+	bindingTypeStandardGamepadAxis
+)
+
+// A Binding represents a physical input that triggers an action.
+//
+// A Binding is a value type and can be compared with ==.
+type Binding struct {
+	typ       bindingType
+	value     int
+	threshold float64
+}
+
+// KeyBinding returns a binding of the keyboard key.
+func KeyBinding(key ebiten.Key) Binding {
+	return Binding{typ: bindingTypeKey, value: int(key)}
+}
+
+// MouseButtonBinding returns a binding of the mouse button.
+func MouseButtonBinding(button ebiten.MouseButton) Binding {
+	return Binding{typ: bindingTypeMouseButton, value: int(button)}
+}
+
+// GamepadButtonBinding returns a binding of the gamepad button.
+//
+// As the button numbers depend on the gamepad model, StandardGamepadButtonBinding is recommended
+// when the standard layout is available.
+func GamepadButtonBinding(button ebiten.GamepadButton) Binding {
+	return Binding{typ: bindingTypeGamepadButton, value: int(button)}
+}
+
+// StandardGamepadButtonBinding returns a binding of the gamepad button in the standard layout.
+func StandardGamepadButtonBinding(button ebiten.StandardGamepadButton) Binding {
+	return Binding{typ: bindingTypeStandardGamepadButton, value: int(button)}
+}
+
+// GamepadAxisBinding returns a binding of the gamepad axis.
+//
+// If threshold is positive, the binding is pressed when the axis value is threshold or more.
+// If threshold is negative, the binding is pressed when the axis value is threshold or less.
+// For example, GamepadAxisBinding(0, -0.5) represents tilting the axis 0 to the negative direction.
+//
+// GamepadAxisBinding panics if threshold is 0 or its absolute value is more than 1.
+func GamepadAxisBinding(axis int, threshold float64) Binding {
+	checkThreshold(threshold)
+	return Binding{typ: bindingTypeGamepadAxis, value: axis, threshold: threshold}
+}
+
+// StandardGamepadAxisBinding returns a binding of the gamepad axis in the standard layout.
+//
+// threshold works in the same way as GamepadAxisBinding.
+//
+// StandardGamepadAxisBinding panics if threshold is 0 or its absolute value is more than 1.
+func StandardGamepadAxisBinding(axis ebiten.StandardGamepadAxis, threshold float64) Binding {
+	checkThreshold(threshold)
+	return Binding{typ: bindingTypeStandardGamepadAxis, value: int(axis), threshold: threshold}
+}
+
+func checkThreshold(threshold float64) {
+	if err := validateThreshold(threshold); err != nil {
+		panic(err)
+	}
+}
+
+// String returns a description of the binding like "Key Space" or "Standard Gamepad Axis LeftStickHorizontal -0.5".
+func (b Binding) String() string {
+	switch b.typ {
+	case bindingTypeKey:
+		return "Key " + ebiten.Key(b.value).String()
+	case bindingTypeMouseButton:
+		return "Mouse Button " + mouseButtonNames[ebiten.MouseButton(b.value)]
+	case bindingTypeGamepadButton:
+		return "Gamepad Button " + strconv.Itoa(b.value)
+	case bindingTypeStandardGamepadButton:
+		return "Standard Gamepad Button " + standardGamepadButtonNames[ebiten.StandardGamepadButton(b.value)]
+	case bindingTypeGamepadAxis:
+		return fmt.Sprintf("Gamepad Axis %d %g", b.value, b.threshold)
+	case bindingTypeStandardGamepadAxis:
+		return fmt.Sprintf("Standard Gamepad Axis %s %g", standardGamepadAxisNames[ebiten.StandardGamepadAxis(b.value)], b.threshold)
+	}
+	return ""
+}
+
+// valueFor returns the analog value [0.0 - 1.0] of the binding for the gamepad (id).
+// For gamepad bindings, only the gamepad (id) is checked.
+func (b Binding) valueFor(id int) float64 {
+	switch b.typ {
+	case bindingTypeKey:
+		return boolToValue(ebiten.IsKeyPressed(ebiten.Key(b.value)))
+	case bindingTypeMouseButton:
+		return boolToValue(ebiten.IsMouseButtonPressed(ebiten.MouseButton(b.value)))
+	case bindingTypeGamepadButton:
+		return boolToValue(ebiten.IsGamepadButtonPressed(id, ebiten.GamepadButton(b.value)))
+	case bindingTypeStandardGamepadButton:
+		return ebiten.StandardGamepadButtonValue(id, ebiten.StandardGamepadButton(b.value))
+	case bindingTypeGamepadAxis:
+		return axisToValue(ebiten.GamepadAxis(id, b.value), b.threshold)
+	case bindingTypeStandardGamepadAxis:
+		return axisToValue(ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxis(b.value)), b.threshold)
+	}
+	return 0
+}
+
+func (b Binding) isGamepad() bool {
+	return b.typ == bindingTypeGamepadButton || b.typ == bindingTypeStandardGamepadButton ||
+		b.typ == bindingTypeGamepadAxis || b.typ == bindingTypeStandardGamepadAxis
+}
+
+// pressed reports whether the binding is pressed with the given value.
+func (b Binding) pressed(v float64) bool {
+	switch b.typ {
+	case bindingTypeGamepadAxis, bindingTypeStandardGamepadAxis:
+		t := b.threshold
+		if t < 0 {
+			t = -t
+		}
+		return v >= t
+	case bindingTypeStandardGamepadButton:
+		// Analog buttons like triggers are treated as pressed when they are pushed more than half.
+		return v > 0.5
+	}
+	return v > 0
+}
+
+func boolToValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// axisToValue returns the axis value in the direction of threshold.
+func axisToValue(v float64, threshold float64) float64 {
+	if threshold < 0 {
+		v = -v
+	}
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+var mouseButtonNames = map[ebiten.MouseButton]string{
+	ebiten.MouseButtonLeft:   "Left",
+	ebiten.MouseButtonRight:  "Right",
+	ebiten.MouseButtonMiddle: "Middle",
+	ebiten.MouseButton4:      "4",
+	ebiten.MouseButton5:      "5",
+	ebiten.MouseButton6:      "6",
+	ebiten.MouseButton7:      "7",
+	ebiten.MouseButton8:      "8",
+}
+
+var standardGamepadButtonNames = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "RightBottom",
+	ebiten.StandardGamepadButtonRightRight:       "RightRight",
+	ebiten.StandardGamepadButtonRightLeft:        "RightLeft",
+	ebiten.StandardGamepadButtonRightTop:         "RightTop",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "FrontTopLeft",
+	ebiten.StandardGamepadButtonFrontTopRight:    "FrontTopRight",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "FrontBottomLeft",
+	ebiten.StandardGamepadButtonFrontBottomRight: "FrontBottomRight",
+	ebiten.StandardGamepadButtonCenterLeft:       "CenterLeft",
+	ebiten.StandardGamepadButtonCenterRight:      "CenterRight",
+	ebiten.StandardGamepadButtonLeftStick:        "LeftStick",
+	ebiten.StandardGamepadButtonRightStick:       "RightStick",
+	ebiten.StandardGamepadButtonLeftTop:          "LeftTop",
+	ebiten.StandardGamepadButtonLeftBottom:       "LeftBottom",
+	ebiten.StandardGamepadButtonLeftLeft:         "LeftLeft",
+	ebiten.StandardGamepadButtonLeftRight:        "LeftRight",
+	ebiten.StandardGamepadButtonCenterCenter:     "CenterCenter",
+}
+
+var standardGamepadAxisNames = map[ebiten.StandardGamepadAxis]string{
+	ebiten.StandardGamepadAxisLeftStickHorizontal:  "LeftStickHorizontal",
+	ebiten.StandardGamepadAxisLeftStickVertical:    "LeftStickVertical",
+	ebiten.StandardGamepadAxisRightStickHorizontal: "RightStickHorizontal",
+	ebiten.StandardGamepadAxisRightStickVertical:   "RightStickVertical",
+}
+
+// bindingJSON is the serialized form of Binding.
+//
+// Keys, mouse buttons and standard gamepad buttons and axes are stored by their names
+// so that saved bindings don't depend on the internal values of the constants.
+type bindingJSON struct {
+	Key                   string  `json:"key,omitempty"`
+	MouseButton           string  `json:"mouseButton,omitempty"`
+	GamepadButton         *int    `json:"gamepadButton,omitempty"`
+	StandardGamepadButton string  `json:"standardGamepadButton,omitempty"`
+	GamepadAxis           *int    `json:"gamepadAxis,omitempty"`
+	StandardGamepadAxis   string  `json:"standardGamepadAxis,omitempty"`
+	Threshold             float64 `json:"threshold,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b Binding) MarshalJSON() ([]byte, error) {
+	var j bindingJSON
+	switch b.typ {
+	case bindingTypeKey:
+		j.Key = ebiten.Key(b.value).String()
+	case bindingTypeMouseButton:
+		j.MouseButton = mouseButtonNames[ebiten.MouseButton(b.value)]
+	case bindingTypeGamepadButton:
+		v := b.value
+		j.GamepadButton = &v
+	case bindingTypeStandardGamepadButton:
+		j.StandardGamepadButton = standardGamepadButtonNames[ebiten.StandardGamepadButton(b.value)]
+	case bindingTypeGamepadAxis:
+		v := b.value
+		j.GamepadAxis = &v
+		j.Threshold = b.threshold
+	case bindingTypeStandardGamepadAxis:
+		j.StandardGamepadAxis = standardGamepadAxisNames[ebiten.StandardGamepadAxis(b.value)]
+		j.Threshold = b.threshold
+	default:
+		return nil, fmt.Errorf("inputmap: invalid binding type: %d", b.typ)
+	}
+	return json.Marshal(&j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Binding) UnmarshalJSON(data []byte) error {
+	var j bindingJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	switch {
+	case j.Key != "":
+		for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+			if k.String() == j.Key {
+				*b = KeyBinding(k)
+				return nil
+			}
+		}
+		return fmt.Errorf("inputmap: unknown key: %q", j.Key)
+	case j.MouseButton != "":
+		for m, name := range mouseButtonNames {
+			if name == j.MouseButton {
+				*b = MouseButtonBinding(m)
+				return nil
+			}
+		}
+		return fmt.Errorf("inputmap: unknown mouse button: %q", j.MouseButton)
+	case j.GamepadButton != nil:
+		if *j.GamepadButton < 0 || int(ebiten.GamepadButtonMax) < *j.GamepadButton {
+			return fmt.Errorf("inputmap: invalid gamepad button: %d", *j.GamepadButton)
+		}
+		*b = GamepadButtonBinding(ebiten.GamepadButton(*j.GamepadButton))
+		return nil
+	case j.StandardGamepadButton != "":
+		for s, name := range standardGamepadButtonNames {
+			if name == j.StandardGamepadButton {
+				*b = StandardGamepadButtonBinding(s)
+				return nil
+			}
+		}
+		return fmt.Errorf("inputmap: unknown standard gamepad button: %q", j.StandardGamepadButton)
+	case j.GamepadAxis != nil:
+		if *j.GamepadAxis < 0 {
+			return fmt.Errorf("inputmap: invalid gamepad axis: %d", *j.GamepadAxis)
+		}
+		if err := validateThreshold(j.Threshold); err != nil {
+			return err
+		}
+		*b = GamepadAxisBinding(*j.GamepadAxis, j.Threshold)
+		return nil
+	case j.StandardGamepadAxis != "":
+		if err := validateThreshold(j.Threshold); err != nil {
+			return err
+		}
+		for a, name := range standardGamepadAxisNames {
+			if name == j.StandardGamepadAxis {
+				*b = StandardGamepadAxisBinding(a, j.Threshold)
+				return nil
+			}
+		}
+		return fmt.Errorf("inputmap: unknown standard gamepad axis: %q", j.StandardGamepadAxis)
+	}
+	return fmt.Errorf("inputmap: invalid binding: %s", string(data))
+}
+
+func validateThreshold(threshold float64) error {
+	if threshold == 0 || threshold < -1 || 1 < threshold {
+		return fmt.Errorf("inputmap: invalid threshold: %f", threshold)
+	}
+	return nil
+}
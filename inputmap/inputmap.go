@@ -0,0 +1,321 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inputmap provides a mapping from named actions to physical inputs.
+//
+// A game declares actions like "jump" or "fire", binds them to keys, mouse buttons,
+// gamepad buttons or gamepad axes, and queries the actions instead of the inputs:
+//
+//     m := inputmap.NewMap()
+//     m.Bind("jump", inputmap.KeyBinding(ebiten.KeySpace), inputmap.StandardGamepadButtonBinding(ebiten.StandardGamepadButtonRightBottom))
+//     m.Bind("left", inputmap.KeyBinding(ebiten.KeyLeft), inputmap.StandardGamepadAxisBinding(ebiten.StandardGamepadAxisLeftStickHorizontal, -0.5))
+//
+//     func update(screen *ebiten.Image) error {
+//         m.Update()
+//         if m.IsActionJustPressed("jump") {
+//             ...
+//         }
+//         ...
+//     }
+//
+// The bindings can be saved and loaded so that players can customize them.
+package inputmap
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// AnyGamepad represents all the connected gamepads. See (*Map).SetGamepadID.
+const AnyGamepad = -1
+
+type actionState struct {
+	bindings     []Binding
+	duration     int
+	prevDuration int
+	value        float64
+}
+
+// A Map represents a set of actions and their bindings.
+type Map struct {
+	actions   map[string]*actionState
+	gamepadID int
+	m         sync.RWMutex
+}
+
+// NewMap returns a new empty Map.
+//
+// The gamepad bindings of the returned Map are applied to all the connected gamepads.
+func NewMap() *Map {
+	return &Map{
+		actions:   map[string]*actionState{},
+		gamepadID: AnyGamepad,
+	}
+}
+
+// SetGamepadID sets the gamepad (id) whose inputs are used for gamepad bindings.
+//
+// If id is AnyGamepad, all the connected gamepads are used. This is useful for a single player game.
+// For a local multiplayer game, create one Map for each player and set a different gamepad.
+//
+// This function is concurrent-safe.
+func (m *Map) SetGamepadID(id int) {
+	m.m.Lock()
+	m.gamepadID = id
+	m.m.Unlock()
+}
+
+// GamepadID returns the gamepad ID set by SetGamepadID. The default value is AnyGamepad.
+//
+// This function is concurrent-safe.
+func (m *Map) GamepadID() int {
+	m.m.RLock()
+	defer m.m.RUnlock()
+	return m.gamepadID
+}
+
+// Bind adds the bindings to the action.
+//
+// A binding already bound to the action is ignored.
+//
+// This function is concurrent-safe.
+func (m *Map) Bind(action string, bindings ...Binding) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	a, ok := m.actions[action]
+	if !ok {
+		a = &actionState{}
+		m.actions[action] = a
+	}
+	for _, b := range bindings {
+		if !containsBinding(a.bindings, b) {
+			a.bindings = append(a.bindings, b)
+		}
+	}
+}
+
+// Unbind removes the bindings from the action.
+// If no bindings are given, all the bindings and the action itself are removed.
+//
+// This function is concurrent-safe.
+func (m *Map) Unbind(action string, bindings ...Binding) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	a, ok := m.actions[action]
+	if !ok {
+		return
+	}
+	if len(bindings) == 0 {
+		delete(m.actions, action)
+		return
+	}
+	bs := a.bindings[:0]
+	for _, b := range a.bindings {
+		if !containsBinding(bindings, b) {
+			bs = append(bs, b)
+		}
+	}
+	a.bindings = bs
+}
+
+func containsBinding(bindings []Binding, binding Binding) bool {
+	for _, b := range bindings {
+		if b == binding {
+			return true
+		}
+	}
+	return false
+}
+
+// Bindings returns the bindings of the action in the order of addition.
+//
+// This function is concurrent-safe.
+func (m *Map) Bindings(action string) []Binding {
+	m.m.RLock()
+	defer m.m.RUnlock()
+	a, ok := m.actions[action]
+	if !ok {
+		return nil
+	}
+	return append([]Binding{}, a.bindings...)
+}
+
+// Actions returns the names of the actions in the map in sorted order.
+//
+// This function is concurrent-safe.
+func (m *Map) Actions() []string {
+	m.m.RLock()
+	defer m.m.RUnlock()
+	actions := make([]string, 0, len(m.actions))
+	for name := range m.actions {
+		actions = append(actions, name)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// Update updates the states of the actions from the current inputs.
+//
+// Update must be called exactly once at every tick before the actions are queried,
+// typically at the beginning of the game's update function.
+//
+// This function is concurrent-safe.
+func (m *Map) Update() {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	var ids []int
+	if m.gamepadID == AnyGamepad {
+		ids = ebiten.GamepadIDs()
+	} else {
+		ids = []int{m.gamepadID}
+	}
+
+	for _, a := range m.actions {
+		pressed := false
+		value := 0.0
+		for _, b := range a.bindings {
+			if !b.isGamepad() {
+				v := b.valueFor(0)
+				pressed = pressed || b.pressed(v)
+				if value < v {
+					value = v
+				}
+				continue
+			}
+			for _, id := range ids {
+				v := b.valueFor(id)
+				pressed = pressed || b.pressed(v)
+				if value < v {
+					value = v
+				}
+			}
+		}
+		a.prevDuration = a.duration
+		if pressed {
+			a.duration++
+		} else {
+			a.duration = 0
+		}
+		a.value = value
+	}
+}
+
+// IsActionPressed returns a boolean value indicating whether any of the bindings of the action is pressed.
+//
+// A gamepad axis binding is pressed when the axis is tilted beyond its threshold,
+// and an analog standard gamepad button is pressed when it is pushed more than half.
+//
+// This function is concurrent-safe.
+func (m *Map) IsActionPressed(action string) bool {
+	return m.ActionPressDuration(action) > 0
+}
+
+// IsActionJustPressed returns a boolean value indicating whether the action is just pressed in the current tick.
+//
+// This function is concurrent-safe.
+func (m *Map) IsActionJustPressed(action string) bool {
+	return m.ActionPressDuration(action) == 1
+}
+
+// IsActionJustReleased returns a boolean value indicating whether the action is just released in the current tick.
+//
+// This function is concurrent-safe.
+func (m *Map) IsActionJustReleased(action string) bool {
+	m.m.RLock()
+	defer m.m.RUnlock()
+	a, ok := m.actions[action]
+	if !ok {
+		return false
+	}
+	return a.duration == 0 && a.prevDuration > 0
+}
+
+// ActionPressDuration returns how long the action is pressed in ticks.
+//
+// This function is concurrent-safe.
+func (m *Map) ActionPressDuration(action string) int {
+	m.m.RLock()
+	defer m.m.RUnlock()
+	a, ok := m.actions[action]
+	if !ok {
+		return 0
+	}
+	return a.duration
+}
+
+// ActionValue returns the analog value [0.0 - 1.0] of the action.
+//
+// The value is the largest one among the bindings.
+// Keys, mouse buttons and gamepad buttons are 0 or 1. Analog standard gamepad buttons like triggers
+// return their values. Gamepad axes return their values in the direction of their thresholds,
+// e.g. a binding with the threshold -0.5 returns 0.8 when the axis value is -0.8.
+//
+// This function is concurrent-safe.
+func (m *Map) ActionValue(action string) float64 {
+	m.m.RLock()
+	defer m.m.RUnlock()
+	a, ok := m.actions[action]
+	if !ok {
+		return 0
+	}
+	return a.value
+}
+
+// Save writes the bindings of all the actions to w in JSON.
+//
+// This function is concurrent-safe.
+func (m *Map) Save(w io.Writer) error {
+	m.m.RLock()
+	bindings := map[string][]Binding{}
+	for name, a := range m.actions {
+		// Copy the bindings as Unbind modifies the slice in place after the lock is released.
+		bindings[name] = append([]Binding{}, a.bindings...)
+	}
+	m.m.RUnlock()
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(bindings)
+}
+
+// Load reads the bindings written by Save from r, and replaces all the actions with them.
+//
+// If Load returns an error, the map is not changed.
+//
+// This function is concurrent-safe.
+func (m *Map) Load(r io.Reader) error {
+	bindings := map[string][]Binding{}
+	if err := json.NewDecoder(r).Decode(&bindings); err != nil {
+		return err
+	}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+	m.actions = map[string]*actionState{}
+	for name, bs := range bindings {
+		a := &actionState{}
+		for _, b := range bs {
+			if !containsBinding(a.bindings, b) {
+				a.bindings = append(a.bindings, b)
+			}
+		}
+		m.actions[name] = a
+	}
+	return nil
+}
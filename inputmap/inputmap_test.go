@@ -0,0 +1,149 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputmap_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/inputmap"
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// update updates the map with the given input state. The state is given via the replayed input state.
+func update(m *Map, state *ui.InputState) {
+	ui.SetReplayedInputState(state)
+	defer ui.SetReplayedInputState(nil)
+	m.Update()
+}
+
+func TestMap(t *testing.T) {
+	m := NewMap()
+	m.Bind("jump", KeyBinding(ebiten.KeySpace), StandardGamepadButtonBinding(ebiten.StandardGamepadButtonRightBottom))
+	m.Bind("left", KeyBinding(ebiten.KeyLeft), StandardGamepadAxisBinding(ebiten.StandardGamepadAxisLeftStickHorizontal, -0.5))
+
+	gamepad := func(x float64, a bool) []ui.GamepadState {
+		buttons := make([]bool, ebiten.StandardGamepadButtonMax+1)
+		buttons[ebiten.StandardGamepadButtonRightBottom] = a
+		return []ui.GamepadState{
+			{
+				ID:       0,
+				Standard: true,
+				Axes:     []float64{x, 0, 0, 0},
+				Buttons:  buttons,
+			},
+		}
+	}
+
+	update(m, &ui.InputState{PressedKeys: []ui.Key{ui.Key(ebiten.KeySpace)}})
+	if !m.IsActionJustPressed("jump") {
+		t.Errorf("IsActionJustPressed(jump): got: false, want: true")
+	}
+	if m.IsActionPressed("left") {
+		t.Errorf("IsActionPressed(left): got: true, want: false")
+	}
+
+	update(m, &ui.InputState{Gamepads: gamepad(-0.3, true)})
+	if got, want := m.ActionPressDuration("jump"), 2; got != want {
+		t.Errorf("ActionPressDuration(jump): got: %d, want: %d", got, want)
+	}
+	if m.IsActionPressed("left") {
+		t.Errorf("IsActionPressed(left): got: true, want: false")
+	}
+	if got, want := m.ActionValue("left"), 0.3; got != want {
+		t.Errorf("ActionValue(left): got: %f, want: %f", got, want)
+	}
+
+	update(m, &ui.InputState{Gamepads: gamepad(-0.8, false)})
+	if !m.IsActionJustReleased("jump") {
+		t.Errorf("IsActionJustReleased(jump): got: false, want: true")
+	}
+	if !m.IsActionJustPressed("left") {
+		t.Errorf("IsActionJustPressed(left): got: false, want: true")
+	}
+
+	// Gamepads other than the specified one are ignored.
+	m.SetGamepadID(1)
+	update(m, &ui.InputState{Gamepads: gamepad(-0.8, true)})
+	if m.IsActionPressed("jump") || m.IsActionPressed("left") {
+		t.Errorf("the gamepad 0 must be ignored")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	m := NewMap()
+	m.Bind("fire", KeyBinding(ebiten.KeyZ), MouseButtonBinding(ebiten.MouseButtonLeft), GamepadButtonBinding(ebiten.GamepadButton2))
+	m.Bind("up", StandardGamepadAxisBinding(ebiten.StandardGamepadAxisLeftStickVertical, -0.5), GamepadAxisBinding(1, -0.25))
+	m.Unbind("up", GamepadAxisBinding(1, -0.25))
+
+	buf := &bytes.Buffer{}
+	if err := m.Save(buf); err != nil {
+		t.Fatal(err)
+	}
+	m2 := NewMap()
+	m2.Bind("dummy", KeyBinding(ebiten.KeyA))
+	if err := m2.Load(buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m2.Actions(), []string{"fire", "up"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Actions(): got: %v, want: %v", got, want)
+	}
+	for _, a := range m.Actions() {
+		if got, want := m2.Bindings(a), m.Bindings(a); !reflect.DeepEqual(got, want) {
+			t.Errorf("Bindings(%q): got: %v, want: %v", a, got, want)
+		}
+	}
+
+	for _, in := range []string{
+		`{"fire":[{"key":"NoSuchKey"}]}`,
+		`{"up":[{"gamepadAxis":1}]}`,
+		`{"up":[{}]}`,
+	} {
+		if err := m2.Load(bytes.NewBufferString(in)); err == nil {
+			t.Errorf("Load(%q) must return an error", in)
+		}
+	}
+	if got, want := m2.Actions(), []string{"fire", "up"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Actions() after failed loads: got: %v, want: %v", got, want)
+	}
+}
+
+func TestSaveWhileUnbinding(t *testing.T) {
+	m := NewMap()
+	m.Bind("fire", KeyBinding(ebiten.KeyZ), KeyBinding(ebiten.KeyX))
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			m.Unbind("fire", KeyBinding(ebiten.KeyZ))
+			m.Bind("fire", KeyBinding(ebiten.KeyZ))
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		if err := m.Save(&bytes.Buffer{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	<-done
+}
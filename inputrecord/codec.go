@@ -0,0 +1,315 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputrecord
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// The stream consists of the header and the frames.
+//
+// Each frame starts with a tag byte. frameSame means the state is the same as the previous frame's.
+// frameState is followed by the length of the encoded state as an uvarint and the encoded state.
+const (
+	magic   = "EBIR"
+	version = 2
+
+	frameSame  = 0
+	frameState = 1
+)
+
+var errCorrupted = errors.New("inputrecord: corrupted stream")
+
+type encoder struct {
+	buf bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (e *encoder) uvarint(v uint64) {
+	n := binary.PutUvarint(e.tmp[:], v)
+	e.buf.Write(e.tmp[:n])
+}
+
+func (e *encoder) varint(v int64) {
+	n := binary.PutVarint(e.tmp[:], v)
+	e.buf.Write(e.tmp[:n])
+}
+
+func (e *encoder) float(v float64) {
+	binary.LittleEndian.PutUint64(e.tmp[:8], math.Float64bits(v))
+	e.buf.Write(e.tmp[:8])
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *encoder) ints(vs []int) {
+	e.uvarint(uint64(len(vs)))
+	for _, v := range vs {
+		e.varint(int64(v))
+	}
+}
+
+func (e *encoder) bools(vs []bool) {
+	e.uvarint(uint64(len(vs)))
+	bs := make([]byte, (len(vs)+7)/8)
+	for i, v := range vs {
+		if v {
+			bs[i/8] |= 1 << uint(i%8)
+		}
+	}
+	e.buf.Write(bs)
+}
+
+func encodeState(s *ui.InputState) []byte {
+	e := &encoder{}
+	e.uvarint(uint64(len(s.PressedKeys)))
+	for _, k := range s.PressedKeys {
+		e.uvarint(uint64(k))
+	}
+	e.uvarint(uint64(len(s.PressedMouseButtons)))
+	for _, b := range s.PressedMouseButtons {
+		e.uvarint(uint64(b))
+	}
+	e.varint(int64(s.CursorX))
+	e.varint(int64(s.CursorY))
+	e.float(s.CursorDeltaX)
+	e.float(s.CursorDeltaY)
+	e.float(s.RawMouseDeltaX)
+	e.float(s.RawMouseDeltaY)
+	e.bools([]bool{s.RawMouseDeltaSupported})
+	e.float(s.WheelX)
+	e.float(s.WheelY)
+	e.uvarint(uint64(len(s.Runes)))
+	for _, r := range s.Runes {
+		e.varint(int64(r))
+	}
+	e.uvarint(uint64(len(s.DroppedFileNames)))
+	for _, f := range s.DroppedFileNames {
+		e.string(f)
+	}
+	e.uvarint(uint64(len(s.Gamepads)))
+	for _, g := range s.Gamepads {
+		e.varint(int64(g.ID))
+		e.string(g.Name)
+		e.string(g.GUID)
+		e.varint(int64(g.VendorID))
+		e.varint(int64(g.ProductID))
+		e.bools([]bool{g.Standard})
+		e.uvarint(uint64(len(g.Axes)))
+		for _, a := range g.Axes {
+			e.float(a)
+		}
+		e.bools(g.Buttons)
+	}
+	e.ints(s.JustConnectedGamepads)
+	e.ints(s.JustDisconnectedGamepads)
+	e.uvarint(uint64(len(s.TouchStates)))
+	for _, t := range s.TouchStates {
+		e.varint(int64(t.ID))
+		e.varint(int64(t.X))
+		e.varint(int64(t.Y))
+	}
+	e.bools([]bool{s.PenInRange, s.PenEraser})
+	e.varint(int64(s.PenX))
+	e.varint(int64(s.PenY))
+	e.float(s.PenPressureValue)
+	e.float(s.PenTiltX)
+	e.float(s.PenTiltY)
+	e.bools([]bool{s.CapsLockOn, s.NumLockOn})
+	e.string(s.IMECompositionText)
+	e.varint(int64(s.IMECompositionCaret))
+	return e.buf.Bytes()
+}
+
+type decoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		d.err = errCorrupted
+		return 0
+	}
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	if err != nil {
+		d.err = errCorrupted
+		return 0
+	}
+	return v
+}
+
+// len reads a length and checks that the rest of the data has at least the length bytes,
+// so that a corrupted length doesn't cause a huge allocation.
+func (d *decoder) len() int {
+	n := d.uvarint()
+	if d.err != nil {
+		return 0
+	}
+	if uint64(d.r.Len()) < n {
+		d.err = errCorrupted
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	bs := make([]byte, n)
+	if _, err := io.ReadFull(d.r, bs); err != nil {
+		d.err = errCorrupted
+		return nil
+	}
+	return bs
+}
+
+func (d *decoder) float() float64 {
+	bs := d.bytes(8)
+	if d.err != nil {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(bs))
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes(d.len()))
+}
+
+func (d *decoder) ints() []int {
+	n := d.len()
+	var vs []int
+	for i := 0; i < n && d.err == nil; i++ {
+		vs = append(vs, int(d.varint()))
+	}
+	return vs
+}
+
+func (d *decoder) bools() []bool {
+	n := d.uvarint()
+	if d.err != nil {
+		return nil
+	}
+	if uint64(d.r.Len())*8 < n {
+		d.err = errCorrupted
+		return nil
+	}
+	bs := d.bytes((int(n) + 7) / 8)
+	if d.err != nil {
+		return nil
+	}
+	vs := make([]bool, n)
+	for i := range vs {
+		vs[i] = bs[i/8]&(1<<uint(i%8)) != 0
+	}
+	return vs
+}
+
+func decodeState(data []byte) (*ui.InputState, error) {
+	d := &decoder{r: bytes.NewReader(data)}
+	s := &ui.InputState{}
+	for i, n := 0, d.len(); i < n && d.err == nil; i++ {
+		s.PressedKeys = append(s.PressedKeys, ui.Key(d.uvarint()))
+	}
+	for i, n := 0, d.len(); i < n && d.err == nil; i++ {
+		s.PressedMouseButtons = append(s.PressedMouseButtons, ui.MouseButton(d.uvarint()))
+	}
+	s.CursorX = int(d.varint())
+	s.CursorY = int(d.varint())
+	s.CursorDeltaX = d.float()
+	s.CursorDeltaY = d.float()
+	s.RawMouseDeltaX = d.float()
+	s.RawMouseDeltaY = d.float()
+	if b := d.bools(); len(b) == 1 {
+		s.RawMouseDeltaSupported = b[0]
+	}
+	s.WheelX = d.float()
+	s.WheelY = d.float()
+	for i, n := 0, d.len(); i < n && d.err == nil; i++ {
+		s.Runes = append(s.Runes, rune(d.varint()))
+	}
+	for i, n := 0, d.len(); i < n && d.err == nil; i++ {
+		s.DroppedFileNames = append(s.DroppedFileNames, d.string())
+	}
+	for i, n := 0, d.len(); i < n && d.err == nil; i++ {
+		g := ui.GamepadState{}
+		g.ID = int(d.varint())
+		g.Name = d.string()
+		g.GUID = d.string()
+		g.VendorID = int(d.varint())
+		g.ProductID = int(d.varint())
+		if b := d.bools(); len(b) == 1 {
+			g.Standard = b[0]
+		}
+		for j, n := 0, d.len(); j < n && d.err == nil; j++ {
+			g.Axes = append(g.Axes, d.float())
+		}
+		g.Buttons = d.bools()
+		s.Gamepads = append(s.Gamepads, g)
+	}
+	s.JustConnectedGamepads = d.ints()
+	s.JustDisconnectedGamepads = d.ints()
+	for i, n := 0, d.len(); i < n && d.err == nil; i++ {
+		t := ui.TouchState{}
+		t.ID = int(d.varint())
+		t.X = int(d.varint())
+		t.Y = int(d.varint())
+		s.TouchStates = append(s.TouchStates, t)
+	}
+	if b := d.bools(); len(b) == 2 {
+		s.PenInRange = b[0]
+		s.PenEraser = b[1]
+	}
+	s.PenX = int(d.varint())
+	s.PenY = int(d.varint())
+	s.PenPressureValue = d.float()
+	s.PenTiltX = d.float()
+	s.PenTiltY = d.float()
+	if b := d.bools(); len(b) == 2 {
+		s.CapsLockOn = b[0]
+		s.NumLockOn = b[1]
+	}
+	s.IMECompositionText = d.string()
+	s.IMECompositionCaret = int(d.varint())
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.r.Len() != 0 {
+		return nil, fmt.Errorf("inputrecord: %d extra bytes in a frame", d.r.Len())
+	}
+	return s, nil
+}
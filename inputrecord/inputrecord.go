@@ -0,0 +1,231 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inputrecord provides functions to record the input state at every tick and to replay it later.
+//
+// Replaying a recorded stream reproduces the same input state at the same tick,
+// e.g. to reproduce a bug report or to run automated gameplay tests.
+// While replaying, the input functions of the ebiten package and the inpututil package
+// report the recorded state instead of the actual input.
+//
+// The game is replayed deterministically only when the game's state depends on nothing but the input,
+// e.g. random number generators must be seeded with fixed values and
+// the game must not depend on the actual time.
+//
+// The replay still needs a running game, i.e. ebiten.Run must be called.
+package inputrecord
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/internal/hooks"
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+type recorder struct {
+	w    *bufio.Writer
+	prev []byte
+}
+
+type player struct {
+	r    *bufio.Reader
+	prev *ui.InputState
+}
+
+var (
+	theRecorder *recorder
+	thePlayer   *player
+	m           sync.Mutex
+)
+
+func init() {
+	hooks.AppendHookOnInputUpdate(update)
+}
+
+func update() error {
+	m.Lock()
+	defer m.Unlock()
+
+	if thePlayer != nil {
+		s, err := thePlayer.next()
+		if err == io.EOF {
+			thePlayer = nil
+			ui.SetReplayedInputState(nil)
+		} else if err != nil {
+			thePlayer = nil
+			ui.SetReplayedInputState(nil)
+			return err
+		} else {
+			ui.SetReplayedInputState(s)
+		}
+	}
+	if theRecorder != nil {
+		s := ui.ReplayedInputState()
+		if s == nil {
+			s = ui.CurrentInput().State()
+		}
+		if err := theRecorder.write(s); err != nil {
+			theRecorder = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// StartRecording starts to record the input state to w at every tick.
+//
+// The recording continues until StopRecording is called.
+// StartRecording returns an error when the input is already being recorded.
+//
+// This function is concurrent-safe.
+func StartRecording(w io.Writer) error {
+	m.Lock()
+	defer m.Unlock()
+	if theRecorder != nil {
+		return errors.New("inputrecord: the input is already being recorded")
+	}
+	r := &recorder{w: bufio.NewWriter(w)}
+	r.w.WriteString(magic)
+	r.w.WriteByte(version)
+	theRecorder = r
+	return nil
+}
+
+// StopRecording stops recording and flushes the recorded stream.
+//
+// StopRecording does nothing and returns nil when the input is not being recorded.
+//
+// This function is concurrent-safe.
+func StopRecording() error {
+	m.Lock()
+	defer m.Unlock()
+	if theRecorder == nil {
+		return nil
+	}
+	err := theRecorder.w.Flush()
+	theRecorder = nil
+	return err
+}
+
+// IsRecording returns a boolean value indicating whether the input is being recorded.
+//
+// This function is concurrent-safe.
+func IsRecording() bool {
+	m.Lock()
+	defer m.Unlock()
+	return theRecorder != nil
+}
+
+// StartReplaying starts to replay the input state recorded in r from the next tick.
+//
+// The replay stops when the stream reaches its end or StopReplaying is called.
+// StartReplaying returns an error when the stream is not a recorded stream or
+// the input is already being replayed.
+//
+// This function is concurrent-safe.
+func StartReplaying(r io.Reader) error {
+	m.Lock()
+	defer m.Unlock()
+	if thePlayer != nil {
+		return errors.New("inputrecord: the input is already being replayed")
+	}
+	p := &player{r: bufio.NewReader(r)}
+	h := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(p.r, h); err != nil {
+		return fmt.Errorf("inputrecord: reading the header failed: %v", err)
+	}
+	if string(h[:len(magic)]) != magic {
+		return errors.New("inputrecord: not a recorded stream")
+	}
+	if h[len(magic)] != version {
+		return fmt.Errorf("inputrecord: unsupported version: %d", h[len(magic)])
+	}
+	thePlayer = p
+	return nil
+}
+
+// StopReplaying stops replaying and restores the actual input.
+//
+// This function is concurrent-safe.
+func StopReplaying() {
+	m.Lock()
+	defer m.Unlock()
+	thePlayer = nil
+	ui.SetReplayedInputState(nil)
+}
+
+// IsReplaying returns a boolean value indicating whether the input is being replayed.
+//
+// This function is concurrent-safe.
+func IsReplaying() bool {
+	m.Lock()
+	defer m.Unlock()
+	return thePlayer != nil
+}
+
+func (r *recorder) write(s *ui.InputState) error {
+	data := encodeState(s)
+	if r.prev != nil && bytes.Equal(r.prev, data) {
+		return r.w.WriteByte(frameSame)
+	}
+	r.prev = data
+	if err := r.w.WriteByte(frameState); err != nil {
+		return err
+	}
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(len(data)))
+	if _, err := r.w.Write(tmp[:n]); err != nil {
+		return err
+	}
+	_, err := r.w.Write(data)
+	return err
+}
+
+func (p *player) next() (*ui.InputState, error) {
+	tag, err := p.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case frameSame:
+		if p.prev == nil {
+			return nil, errCorrupted
+		}
+		return p.prev, nil
+	case frameState:
+		n, err := binary.ReadUvarint(p.r)
+		if err != nil {
+			return nil, errCorrupted
+		}
+		// Don't allocate n bytes at once since n might be corrupted.
+		buf := &bytes.Buffer{}
+		if _, err := io.CopyN(buf, p.r, int64(n)); err != nil {
+			return nil, errCorrupted
+		}
+		s, err := decodeState(buf.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		p.prev = s
+		return s, nil
+	default:
+		return nil, errCorrupted
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inputrecord_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/inputrecord"
+	"github.com/hajimehoshi/ebiten/internal/hooks"
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	states := []*ui.InputState{
+		{},
+		{
+			PressedKeys:         []ui.Key{ui.KeyA, ui.KeyShift},
+			PressedMouseButtons: []ui.MouseButton{ui.MouseButtonLeft},
+			CursorX:             12,
+			CursorY:             -3,
+			CursorDeltaX:        0.5,
+			WheelY:              -1,
+			Runes:               []rune("aあ"),
+			IMECompositionText:  "にほん",
+			IMECompositionCaret: 2,
+			DroppedFileNames:    []string{"/tmp/foo.png"},
+			Gamepads: []ui.GamepadState{
+				{
+					ID:      1,
+					Name:    "Xbox 360 Controller",
+					Axes:    []float64{0.25, -1},
+					Buttons: []bool{true, false, false, false, false, false, false, false, true},
+				},
+			},
+			JustConnectedGamepads: []int{1},
+			TouchStates:           []ui.TouchState{{ID: 3, X: 100, Y: 200}},
+		},
+	}
+	// The same state is recorded twice to test the frames that are the same as the previous ones.
+	states = append(states, states[len(states)-1], states[0])
+
+	buf := &bytes.Buffer{}
+	if err := StartRecording(buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range states {
+		ui.SetReplayedInputState(s)
+		if err := hooks.RunBeforeUpdateHooks(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ui.SetReplayedInputState(nil)
+	if err := StopRecording(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := StartReplaying(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range states {
+		if err := hooks.RunBeforeUpdateHooks(); err != nil {
+			t.Fatal(err)
+		}
+		got := ui.ReplayedInputState()
+		want := *s
+		if want.PressedKeys == nil && want.Runes == nil {
+			// An empty state is decoded into an empty state.
+			want = ui.InputState{}
+		}
+		if got == nil || !reflect.DeepEqual(*got, want) {
+			t.Errorf("frame %d: got: %+v, want: %+v", i, got, want)
+		}
+	}
+	if err := hooks.RunBeforeUpdateHooks(); err != nil {
+		t.Fatal(err)
+	}
+	if IsReplaying() {
+		t.Errorf("IsReplaying() after the end of the stream: got: true, want: false")
+	}
+	if ui.ReplayedInputState() != nil {
+		t.Errorf("ui.ReplayedInputState() after the end of the stream: got: non-nil, want: nil")
+	}
+}
+
+func TestReplayCorruptedStream(t *testing.T) {
+	if err := StartReplaying(bytes.NewReader([]byte("foo"))); err == nil {
+		t.Errorf("StartReplaying with a too short stream must return an error")
+	}
+	if err := StartReplaying(bytes.NewReader([]byte("EBIR\x02\x01\xff\xff\xff\xff\x0f"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := hooks.RunBeforeUpdateHooks(); err == nil {
+		t.Errorf("replaying a corrupted frame must return an error")
+	}
+	if IsReplaying() {
+		t.Errorf("IsReplaying() after an error: got: true, want: false")
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inpututil
+
+import (
+	"math"
+)
+
+// AxialDeadzone applies the deadzone to the analog value v [-1.0 - 1.0] of an axis or a trigger, and returns the result.
+//
+// Values whose absolute values are less than inner are treated as 0, and values whose absolute values are
+// more than outer are treated as 1 or -1. The values between them are rescaled linearly so that
+// the result changes continuously from 0. outer must be more than inner, and 1 is used for no outer deadzone.
+//
+// As AxialDeadzone works on each axis independently, applying this to both the axes of a stick snaps
+// the direction to the axes around the center. Use RadialDeadzone for a stick to keep the direction.
+func AxialDeadzone(v, inner, outer float64) float64 {
+	a := math.Abs(v)
+	if a <= inner {
+		return 0
+	}
+	if a >= outer {
+		return math.Copysign(1, v)
+	}
+	return math.Copysign((a-inner)/(outer-inner), v)
+}
+
+// RadialDeadzone applies the deadzone to the stick position (x, y) based on its distance from the center,
+// and returns the result.
+//
+// The direction of the stick is kept, and the distance is processed in the same way as AxialDeadzone.
+// Then the distance of the result is at most 1.
+//
+// Many gamepads report a stick position outside the unit circle in diagonal directions, and
+// RadialDeadzone also corrects such positions.
+func RadialDeadzone(x, y, inner, outer float64) (float64, float64) {
+	d := math.Hypot(x, y)
+	if d <= inner {
+		return 0, 0
+	}
+	r := AxialDeadzone(d, inner, outer)
+	return x / d * r, y / d * r
+}
+
+// A ResponseCurve represents a function to shape the analog value.
+//
+// A ResponseCurve takes a value [0.0 - 1.0] and returns a value [0.0 - 1.0].
+// A ResponseCurve is expected to satisfy f(0) = 0 and f(1) = 1.
+type ResponseCurve func(v float64) float64
+
+// ResponseCurves
+var (
+	// LinearCurve returns the value as it is.
+	LinearCurve ResponseCurve = func(v float64) float64 { return v }
+
+	// QuadraticCurve and CubicCurve make fine movement around the center easier.
+	QuadraticCurve ResponseCurve = PowerCurve(2)
+	CubicCurve     ResponseCurve = PowerCurve(3)
+)
+
+// PowerCurve returns a ResponseCurve that raises the value to the power of exponent.
+//
+// An exponent more than 1 makes the response weaker around the center, and
+// an exponent less than 1 makes it stronger.
+//
+// PowerCurve panics if exponent is not positive.
+func PowerCurve(exponent float64) ResponseCurve {
+	if exponent <= 0 {
+		panic("inpututil: exponent must be positive")
+	}
+	return func(v float64) float64 {
+		return math.Pow(v, exponent)
+	}
+}
+
+// Apply applies the curve to the analog value v [-1.0 - 1.0] of an axis keeping its sign.
+func (c ResponseCurve) Apply(v float64) float64 {
+	return math.Copysign(clamp01(c(clamp01(math.Abs(v)))), v)
+}
+
+// ApplyStick applies the curve to the distance of the stick position (x, y) from the center keeping its direction.
+func (c ResponseCurve) ApplyStick(x, y float64) (float64, float64) {
+	d := math.Hypot(x, y)
+	if d == 0 {
+		return 0, 0
+	}
+	r := clamp01(c(clamp01(d)))
+	return x / d * r, y / d * r
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// An AnalogStick represents settings to process a gamepad stick.
+//
+// The zero value is not usable. Set Outer to 1 at least.
+type AnalogStick struct {
+	// Inner is the radius of the inner deadzone. 0.1 to 0.25 are typical values.
+	Inner float64
+
+	// Outer is the radius where the stick is treated as tilted fully.
+	Outer float64
+
+	// Axial represents whether the deadzone is applied to each axis independently.
+	// If Axial is false, the deadzone is radial.
+	Axial bool
+
+	// Curve is a response curve applied after the deadzone. If Curve is nil, LinearCurve is used.
+	Curve ResponseCurve
+}
+
+// Apply processes the stick position (x, y) with the settings, and returns the result.
+func (s *AnalogStick) Apply(x, y float64) (float64, float64) {
+	if s.Axial {
+		x, y = AxialDeadzone(x, s.Inner, s.Outer), AxialDeadzone(y, s.Inner, s.Outer)
+		if s.Curve == nil {
+			return x, y
+		}
+		return s.Curve.Apply(x), s.Curve.Apply(y)
+	}
+	x, y = RadialDeadzone(x, y, s.Inner, s.Outer)
+	if s.Curve == nil {
+		return x, y
+	}
+	return s.Curve.ApplyStick(x, y)
+}
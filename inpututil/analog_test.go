@@ -0,0 +1,81 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inpututil_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/inpututil"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAxialDeadzone(t *testing.T) {
+	cases := []struct {
+		In   float64
+		Want float64
+	}{
+		{0, 0},
+		{0.1, 0},
+		{-0.2, 0},
+		{0.6, 0.5},
+		{-0.6, -0.5},
+		{1, 1},
+		{-1, -1},
+	}
+	for _, c := range cases {
+		if got := AxialDeadzone(c.In, 0.2, 1); !near(got, c.Want) {
+			t.Errorf("AxialDeadzone(%f, 0.2, 1): got: %f, want: %f", c.In, got, c.Want)
+		}
+	}
+	if got := AxialDeadzone(0.95, 0.2, 0.9); got != 1 {
+		t.Errorf("AxialDeadzone(0.95, 0.2, 0.9): got: %f, want: 1", got)
+	}
+}
+
+func TestRadialDeadzone(t *testing.T) {
+	if x, y := RadialDeadzone(0.1, 0.1, 0.2, 1); x != 0 || y != 0 {
+		t.Errorf("RadialDeadzone(0.1, 0.1, 0.2, 1): got: (%f, %f), want: (0, 0)", x, y)
+	}
+	// The direction is kept.
+	x, y := RadialDeadzone(0.36, 0.48, 0.2, 1)
+	if !near(x, 0.3) || !near(y, 0.4) {
+		t.Errorf("RadialDeadzone(0.36, 0.48, 0.2, 1): got: (%f, %f), want: (0.3, 0.4)", x, y)
+	}
+	// The position outside the unit circle is corrected.
+	x, y = RadialDeadzone(1, 1, 0.2, 1)
+	if !near(math.Hypot(x, y), 1) || !near(x, y) {
+		t.Errorf("RadialDeadzone(1, 1, 0.2, 1): got: (%f, %f)", x, y)
+	}
+}
+
+func TestResponseCurve(t *testing.T) {
+	if got := QuadraticCurve.Apply(-0.5); !near(got, -0.25) {
+		t.Errorf("QuadraticCurve.Apply(-0.5): got: %f, want: -0.25", got)
+	}
+	x, y := CubicCurve.ApplyStick(0, 0.5)
+	if !near(x, 0) || !near(y, 0.125) {
+		t.Errorf("CubicCurve.ApplyStick(0, 0.5): got: (%f, %f), want: (0, 0.125)", x, y)
+	}
+
+	s := &AnalogStick{Inner: 0.2, Outer: 1, Curve: QuadraticCurve}
+	x, y = s.Apply(0.6, 0)
+	if !near(x, 0.25) || !near(y, 0) {
+		t.Errorf("Apply(0.6, 0): got: (%f, %f), want: (0.25, 0)", x, y)
+	}
+}
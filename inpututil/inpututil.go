@@ -0,0 +1,315 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inpututil provides utility functions of input like keyboard or mouse.
+//
+// The states are updated once before the game's update function is called at every tick,
+// so that a 'just pressed' state is true only at one tick even when the update function is
+// called several times in a frame.
+package inpututil
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/hooks"
+)
+
+type inputState struct {
+	keyDurations     []int
+	prevKeyDurations []int
+
+	mouseButtonDurations     map[ebiten.MouseButton]int
+	prevMouseButtonDurations map[ebiten.MouseButton]int
+
+	gamepadIDs     map[int]struct{}
+	prevGamepadIDs map[int]struct{}
+
+	gamepadButtonDurations     map[int][]int
+	prevGamepadButtonDurations map[int][]int
+
+	touchDurations     map[int]int
+	prevTouchDurations map[int]int
+
+	m sync.RWMutex
+}
+
+var theInputState = &inputState{
+	keyDurations:     make([]int, ebiten.KeyMax+1),
+	prevKeyDurations: make([]int, ebiten.KeyMax+1),
+
+	mouseButtonDurations:     map[ebiten.MouseButton]int{},
+	prevMouseButtonDurations: map[ebiten.MouseButton]int{},
+
+	gamepadIDs:     map[int]struct{}{},
+	prevGamepadIDs: map[int]struct{}{},
+
+	gamepadButtonDurations:     map[int][]int{},
+	prevGamepadButtonDurations: map[int][]int{},
+
+	touchDurations:     map[int]int{},
+	prevTouchDurations: map[int]int{},
+}
+
+func init() {
+	hooks.AppendHookOnBeforeUpdate(func() error {
+		theInputState.update()
+		return nil
+	})
+}
+
+func (i *inputState) update() {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	// Keyboard
+	copy(i.prevKeyDurations, i.keyDurations)
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if ebiten.IsKeyPressed(k) {
+			i.keyDurations[k]++
+		} else {
+			i.keyDurations[k] = 0
+		}
+	}
+
+	// Mouse
+	for b := ebiten.MouseButton(0); b <= ebiten.MouseButtonMax; b++ {
+		i.prevMouseButtonDurations[b] = i.mouseButtonDurations[b]
+		if ebiten.IsMouseButtonPressed(b) {
+			i.mouseButtonDurations[b]++
+		} else {
+			i.mouseButtonDurations[b] = 0
+		}
+	}
+
+	// Gamepads
+	i.prevGamepadIDs = i.gamepadIDs
+	i.gamepadIDs = map[int]struct{}{}
+	for _, id := range ebiten.GamepadIDs() {
+		i.gamepadIDs[id] = struct{}{}
+	}
+	i.prevGamepadButtonDurations = map[int][]int{}
+	for id, ds := range i.gamepadButtonDurations {
+		i.prevGamepadButtonDurations[id] = append([]int{}, ds...)
+	}
+	for id := range i.gamepadButtonDurations {
+		if _, ok := i.gamepadIDs[id]; !ok {
+			delete(i.gamepadButtonDurations, id)
+		}
+	}
+	for id := range i.gamepadIDs {
+		ds, ok := i.gamepadButtonDurations[id]
+		if !ok {
+			ds = make([]int, ebiten.GamepadButtonMax+1)
+			i.gamepadButtonDurations[id] = ds
+		}
+		for b := ebiten.GamepadButton(0); b <= ebiten.GamepadButtonMax; b++ {
+			if ebiten.IsGamepadButtonPressed(id, b) {
+				ds[b]++
+			} else {
+				ds[b] = 0
+			}
+		}
+	}
+
+	// Touches
+	i.prevTouchDurations = i.touchDurations
+	i.touchDurations = map[int]int{}
+	for _, id := range ebiten.TouchIDs() {
+		i.touchDurations[id] = i.prevTouchDurations[id] + 1
+	}
+}
+
+// IsKeyJustPressed returns a boolean value indicating
+// whether the given key is pressed just in the current frame.
+//
+// IsKeyJustPressed is concurrent safe.
+func IsKeyJustPressed(key ebiten.Key) bool {
+	return KeyPressDuration(key) == 1
+}
+
+// IsKeyJustReleased returns a boolean value indicating
+// whether the given key is released just in the current frame.
+//
+// IsKeyJustReleased is concurrent safe.
+func IsKeyJustReleased(key ebiten.Key) bool {
+	if key < 0 || ebiten.KeyMax < key {
+		return false
+	}
+	theInputState.m.RLock()
+	r := theInputState.keyDurations[key] == 0 && theInputState.prevKeyDurations[key] > 0
+	theInputState.m.RUnlock()
+	return r
+}
+
+// KeyPressDuration returns how long the key is pressed in frames.
+//
+// KeyPressDuration is concurrent safe.
+func KeyPressDuration(key ebiten.Key) int {
+	if key < 0 || ebiten.KeyMax < key {
+		return 0
+	}
+	theInputState.m.RLock()
+	s := theInputState.keyDurations[key]
+	theInputState.m.RUnlock()
+	return s
+}
+
+// IsMouseButtonJustPressed returns a boolean value indicating
+// whether the given mouse button is pressed just in the current frame.
+//
+// IsMouseButtonJustPressed is concurrent safe.
+func IsMouseButtonJustPressed(button ebiten.MouseButton) bool {
+	return MouseButtonPressDuration(button) == 1
+}
+
+// IsMouseButtonJustReleased returns a boolean value indicating
+// whether the given mouse button is released just in the current frame.
+//
+// IsMouseButtonJustReleased is concurrent safe.
+func IsMouseButtonJustReleased(button ebiten.MouseButton) bool {
+	theInputState.m.RLock()
+	r := theInputState.mouseButtonDurations[button] == 0 &&
+		theInputState.prevMouseButtonDurations[button] > 0
+	theInputState.m.RUnlock()
+	return r
+}
+
+// MouseButtonPressDuration returns how long the mouse button is pressed in frames.
+//
+// MouseButtonPressDuration is concurrent safe.
+func MouseButtonPressDuration(button ebiten.MouseButton) int {
+	theInputState.m.RLock()
+	s := theInputState.mouseButtonDurations[button]
+	theInputState.m.RUnlock()
+	return s
+}
+
+// IsGamepadJustDisconnected returns a boolean value indicating
+// whether the gamepad of the given id is released just in the current frame.
+//
+// IsGamepadJustDisconnected is concurrent safe.
+func IsGamepadJustDisconnected(id int) bool {
+	theInputState.m.RLock()
+	_, prev := theInputState.prevGamepadIDs[id]
+	_, current := theInputState.gamepadIDs[id]
+	theInputState.m.RUnlock()
+	return prev && !current
+}
+
+// JustConnectedGamepadIDs returns gamepad IDs that are connected just in the current frame.
+//
+// JustConnectedGamepadIDs might return nil when there is no connected gamepad.
+// The IDs are sorted in ascending order.
+//
+// JustConnectedGamepadIDs is concurrent safe.
+func JustConnectedGamepadIDs() []int {
+	var ids []int
+	theInputState.m.RLock()
+	for id := range theInputState.gamepadIDs {
+		if _, ok := theInputState.prevGamepadIDs[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	theInputState.m.RUnlock()
+	sort.Ints(ids)
+	return ids
+}
+
+// IsGamepadButtonJustPressed returns a boolean value indicating
+// whether the given gamepad button of the gamepad id is pressed just in the current frame.
+//
+// IsGamepadButtonJustPressed is concurrent safe.
+func IsGamepadButtonJustPressed(id int, button ebiten.GamepadButton) bool {
+	return GamepadButtonPressDuration(id, button) == 1
+}
+
+// IsGamepadButtonJustReleased returns a boolean value indicating
+// whether the given gamepad button of the gamepad id is released just in the current frame.
+//
+// IsGamepadButtonJustReleased is concurrent safe.
+func IsGamepadButtonJustReleased(id int, button ebiten.GamepadButton) bool {
+	if button < 0 || ebiten.GamepadButtonMax < button {
+		return false
+	}
+	theInputState.m.RLock()
+	defer theInputState.m.RUnlock()
+	prev := 0
+	if ds, ok := theInputState.prevGamepadButtonDurations[id]; ok {
+		prev = ds[button]
+	}
+	current := 0
+	if ds, ok := theInputState.gamepadButtonDurations[id]; ok {
+		current = ds[button]
+	}
+	return current == 0 && prev > 0
+}
+
+// GamepadButtonPressDuration returns how long the gamepad button of the gamepad id is pressed in frames.
+//
+// GamepadButtonPressDuration is concurrent safe.
+func GamepadButtonPressDuration(id int, button ebiten.GamepadButton) int {
+	if button < 0 || ebiten.GamepadButtonMax < button {
+		return 0
+	}
+	theInputState.m.RLock()
+	defer theInputState.m.RUnlock()
+	ds, ok := theInputState.gamepadButtonDurations[id]
+	if !ok {
+		return 0
+	}
+	return ds[button]
+}
+
+// JustPressedTouchIDs returns touch IDs that are created just in the current frame.
+//
+// JustPressedTouchIDs might return nil when there is no touch.
+// The IDs are sorted in ascending order.
+//
+// JustPressedTouchIDs is concurrent safe.
+func JustPressedTouchIDs() []int {
+	var ids []int
+	theInputState.m.RLock()
+	for id, s := range theInputState.touchDurations {
+		if s == 1 {
+			ids = append(ids, id)
+		}
+	}
+	theInputState.m.RUnlock()
+	sort.Ints(ids)
+	return ids
+}
+
+// IsTouchJustReleased returns a boolean value indicating
+// whether the given touch is released just in the current frame.
+//
+// IsTouchJustReleased is concurrent safe.
+func IsTouchJustReleased(id int) bool {
+	theInputState.m.RLock()
+	_, current := theInputState.touchDurations[id]
+	_, prev := theInputState.prevTouchDurations[id]
+	theInputState.m.RUnlock()
+	return prev && !current
+}
+
+// TouchPressDuration returns how long the touch remains in frames.
+//
+// TouchPressDuration is concurrent safe.
+func TouchPressDuration(id int) int {
+	theInputState.m.RLock()
+	s := theInputState.touchDurations[id]
+	theInputState.m.RUnlock()
+	return s
+}
@@ -20,8 +20,33 @@ import (
 	"github.com/hajimehoshi/ebiten/internal/sync"
 )
 
+// FPS is the default number of logical frames (ticks) per second.
+// The primary time is also counted in this unit.
 const FPS = 60
 
+// UncappedTPS represents that the logical frames are updated once per rendering frame.
+const UncappedTPS = -1
+
+// maxCount is the maximum number of logical frames in one rendering frame.
+const maxCount = 3
+
+// FrameSkipPolicy represents how Update treats the logical frames when rendering falls behind.
+type FrameSkipPolicy int
+
+const (
+	// FrameSkipPolicySkipDraw updates up to maxCount logical frames in one rendering frame,
+	// and carries the rest over to the next rendering frames.
+	FrameSkipPolicySkipDraw FrameSkipPolicy = iota
+
+	// FrameSkipPolicySlowDown updates at most one logical frame in one rendering frame,
+	// and discards the rest.
+	FrameSkipPolicySlowDown
+
+	// FrameSkipPolicyClamp updates up to maxCount logical frames in one rendering frame,
+	// and discards the rest.
+	FrameSkipPolicyClamp
+)
+
 var (
 	primaryTime     int64
 	lastPrimaryTime int64
@@ -32,8 +57,17 @@ var (
 	lastFPSUpdated int64
 	framesForFPS   int64
 
+	currentTPS  float64
+	ticksForTPS int64
+
+	lastTPS      int
+	tickProgress float64
+
 	ping func()
 
+	// nowFunc returns the current system time in nanoseconds. This can be replaced for testing.
+	nowFunc = now
+
 	m sync.Mutex
 )
 
@@ -44,6 +78,22 @@ func CurrentFPS() float64 {
 	return v
 }
 
+func CurrentTPS() float64 {
+	m.Lock()
+	v := currentTPS
+	m.Unlock()
+	return v
+}
+
+// TickProgress returns the progress of the current time from the latest logical frame, in [0, 1],
+// in the unit of a logical frame.
+func TickProgress() float64 {
+	m.Lock()
+	v := tickProgress
+	m.Unlock()
+	return v
+}
+
 func RegisterPing(pingFunc func()) {
 	m.Lock()
 	ping = pingFunc
@@ -57,31 +107,59 @@ func ProceedPrimaryTimer() {
 	m.Unlock()
 }
 
-func updateFPS(now int64) {
+func updateFPSAndTPS(now int64, count int) {
 	if lastFPSUpdated == 0 {
 		lastFPSUpdated = now
 	}
 	framesForFPS++
+	ticksForTPS += int64(count)
 	if time.Second > time.Duration(now-lastFPSUpdated) {
 		return
 	}
 	currentFPS = float64(framesForFPS) * float64(time.Second) / float64(now-lastFPSUpdated)
+	currentTPS = float64(ticksForTPS) * float64(time.Second) / float64(now-lastFPSUpdated)
 	lastFPSUpdated = now
 	framesForFPS = 0
+	ticksForTPS = 0
 }
 
 // Update updates the inner clock state and returns an integer value
 // indicating how many logical frames the game should update.
-func Update() int {
+//
+// tps is the number of logical frames per second.
+// If tps is UncappedTPS, Update always returns 1.
+// If tps is 0, Update always returns 0.
+//
+// policy specifies how to treat the logical frames exceeding the limit of one rendering frame.
+func Update(tps int, policy FrameSkipPolicy) int {
 	m.Lock()
 	defer m.Unlock()
 
-	n := now()
+	n := nowFunc()
 
 	if ping != nil {
 		ping()
 	}
 
+	if tps != lastTPS {
+		// The logical clock restarts when TPS is changed.
+		logicalTime = 0
+		lastTPS = tps
+		if tps > 0 {
+			frames = primaryTime * int64(tps) / FPS
+		}
+	}
+
+	if tps <= 0 {
+		count := 0
+		if tps == UncappedTPS {
+			count = 1
+		}
+		tickProgress = 0
+		updateFPSAndTPS(n, count)
+		return count
+	}
+
 	// Initialize logicalTime if needed.
 	if logicalTime == 0 {
 		logicalTime = n
@@ -89,6 +167,7 @@ func Update() int {
 
 	t := n - logicalTime
 	if t < 0 {
+		tickProgress = 0
 		return 0
 	}
 
@@ -110,8 +189,9 @@ func Update() int {
 
 	if primaryTime > 0 && lastPrimaryTime != primaryTime {
 		// If the primary clock is updated, use this.
-		if frames < primaryTime {
-			count = int(primaryTime - frames)
+		// The primary time is counted in FPS, so convert it to the logical frames.
+		if p := primaryTime * int64(tps) / FPS; frames < p {
+			count = int(p - frames)
 		}
 		lastPrimaryTime = primaryTime
 		sync = true
@@ -121,34 +201,66 @@ func Update() int {
 		// 2) the primary clock is not updated yet.
 		// As the primary clock can be updated discountinuously, the system clock is still needed.
 
-		if t > 5*int64(time.Second)/FPS {
+		if t > 5*int64(time.Second)/int64(tps) {
 			// The previous time is too old.
 			// Let's force to sync the logical time with the OS clock.
 			sync = true
 		} else {
-			count = int(t * FPS / int64(time.Second))
+			count = int(t * int64(tps) / int64(time.Second))
 		}
 	}
 
 	// Stabilize FPS.
-	if count == 0 && (int64(time.Second)/FPS/2) < t {
+	if count == 0 && (int64(time.Second)/int64(tps)/2) < t {
 		count = 1
 	}
-	if count == 2 && (int64(time.Second)/FPS*3/2) > t {
+	if count == 2 && (int64(time.Second)/int64(tps)*3/2) > t {
 		count = 1
 	}
-	if count > 3 {
-		count = 3
+
+	// Apply the frame skip policy.
+	// When the exceeding logical frames are discarded, the logical time is forced to sync.
+	discarded := false
+	switch policy {
+	case FrameSkipPolicySlowDown:
+		if count > 1 {
+			count = 1
+			discarded = true
+		}
+	case FrameSkipPolicyClamp:
+		if count > maxCount {
+			count = maxCount
+			discarded = true
+		}
+	default:
+		if count > maxCount {
+			count = maxCount
+		}
 	}
 
 	frames += int64(count)
+	if discarded {
+		sync = true
+		if p := primaryTime * int64(tps) / FPS; frames < p {
+			frames = p
+		}
+	}
 	if sync {
 		logicalTime = n
 	} else {
-		logicalTime += int64(count) * int64(time.Second) / FPS
+		logicalTime += int64(count) * int64(time.Second) / int64(tps)
+	}
+
+	// logicalTime can be ahead of the current time because of the stabilization.
+	tickProgress = float64(n-logicalTime) * float64(tps) / float64(time.Second)
+	if tickProgress < 0 {
+		tickProgress = 0
+	}
+	if tickProgress > 1 {
+		tickProgress = 1
 	}
 
-	updateFPS(n)
+	updateFPSAndTPS(n, count)
 
 	return count
 }
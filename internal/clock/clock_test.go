@@ -0,0 +1,201 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/hajimehoshi/ebiten/internal/clock"
+)
+
+// tick is the duration of a logical frame at FPS.
+const tick = int64(time.Second) / FPS
+
+type fakeTime struct {
+	t int64
+}
+
+func (f *fakeTime) now() int64 {
+	return f.t
+}
+
+func (f *fakeTime) proceed(ticks float64) {
+	f.t += int64(ticks * float64(tick))
+}
+
+// newFakeTime resets the clock with a fake system time. The returned function restores the system time.
+func newFakeTime(t *testing.T) (*fakeTime, func()) {
+	// The time must not be 0, as the logical time 0 means it is not initialized.
+	f := &fakeTime{t: int64(time.Second)}
+	restore := SetNowForTesting(f.now)
+	// The first Update initializes the logical time.
+	if got := Update(FPS, FrameSkipPolicySkipDraw); got != 0 {
+		t.Errorf("Update at the start: got: %d, want: 0", got)
+	}
+	return f, restore
+}
+
+func near(a, b float64) bool {
+	return a-b < 1e-6 && b-a < 1e-6
+}
+
+// The returned values of Update are the update counts in one rendering frame, that are also returned by
+// ebiten.UpdateCountInFrame.
+func TestUpdateFrameSkipPolicy(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Policy FrameSkipPolicy
+		// Counts are the returned values at the rendering frames after 4.5 ticks behind.
+		Counts []int
+	}{
+		{
+			Name:   "skip draw",
+			Policy: FrameSkipPolicySkipDraw,
+			// 3 ticks are updated at most, and the rest is carried over to the next frame.
+			Counts: []int{3, 1, 0},
+		},
+		{
+			Name:   "slow down",
+			Policy: FrameSkipPolicySlowDown,
+			// 1 tick is updated at most, and the rest is discarded.
+			Counts: []int{1, 0, 0},
+		},
+		{
+			Name:   "clamp",
+			Policy: FrameSkipPolicyClamp,
+			// 3 ticks are updated at most, and the rest is discarded.
+			Counts: []int{3, 0, 0},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			f, restore := newFakeTime(t)
+			defer restore()
+
+			// Rendering at the same rate as ticks updates one tick per frame.
+			for i := 0; i < 3; i++ {
+				f.proceed(1)
+				if got := Update(FPS, c.Policy); got != 1 {
+					t.Errorf("Update in time: got: %d, want: 1", got)
+				}
+			}
+
+			// Rendering falls behind by 4.5 ticks. Then, the next rendering frames happen without delay.
+			f.proceed(4.5)
+			for i, want := range c.Counts {
+				if got := Update(FPS, c.Policy); got != want {
+					t.Errorf("Update #%d after falling behind: got: %d, want: %d", i, got, want)
+				}
+			}
+
+			// After the catch-up, one tick is updated per frame again.
+			f.proceed(1)
+			if got := Update(FPS, c.Policy); got != 1 {
+				t.Errorf("Update after the catch-up: got: %d, want: 1", got)
+			}
+		})
+	}
+}
+
+func TestUpdateTooFarBehind(t *testing.T) {
+	// When the game is too far behind, the logical time is synced with the system time regardless of the policy.
+	for _, p := range []FrameSkipPolicy{FrameSkipPolicySkipDraw, FrameSkipPolicySlowDown, FrameSkipPolicyClamp} {
+		f, restore := newFakeTime(t)
+		f.proceed(10)
+		if got := Update(FPS, p); got != 1 {
+			t.Errorf("policy %d: Update after 10 ticks: got: %d, want: 1", p, got)
+		}
+		if got := Update(FPS, p); got != 0 {
+			t.Errorf("policy %d: Update after the sync: got: %d, want: 0", p, got)
+		}
+		restore()
+	}
+}
+
+func TestUpdateSpecialTPS(t *testing.T) {
+	f, restore := newFakeTime(t)
+	defer restore()
+	for i := 0; i < 3; i++ {
+		f.proceed(4.5)
+		if got := Update(UncappedTPS, FrameSkipPolicySkipDraw); got != 1 {
+			t.Errorf("Update with UncappedTPS: got: %d, want: 1", got)
+		}
+		if got := TickProgress(); got != 0 {
+			t.Errorf("TickProgress with UncappedTPS: got: %f, want: 0", got)
+		}
+		if got := Update(0, FrameSkipPolicySkipDraw); got != 0 {
+			t.Errorf("Update with TPS 0: got: %d, want: 0", got)
+		}
+	}
+}
+
+func TestTickProgress(t *testing.T) {
+	f, restore := newFakeTime(t)
+	defer restore()
+
+	// An update is forced at 0.75 ticks to stabilize FPS, and then the logical time is ahead of the system time.
+	f.proceed(0.75)
+	if got := Update(FPS, FrameSkipPolicySkipDraw); got != 1 {
+		t.Errorf("Update at 0.75 ticks: got: %d, want: 1", got)
+	}
+	if got := TickProgress(); got != 0 {
+		t.Errorf("TickProgress ahead of the system time: got: %f, want: 0", got)
+	}
+
+	// At 5.5 ticks, 3 ticks are updated and 1.5 ticks remain, which is clamped to 1.
+	f.proceed(4.75)
+	if got := Update(FPS, FrameSkipPolicySkipDraw); got != 3 {
+		t.Errorf("Update at 5.5 ticks: got: %d, want: 3", got)
+	}
+	if got := TickProgress(); got != 1 {
+		t.Errorf("TickProgress 1.5 ticks behind: got: %f, want: 1", got)
+	}
+
+	// The carried-over tick is updated, and a half tick remains.
+	if got := Update(FPS, FrameSkipPolicySkipDraw); got != 1 {
+		t.Errorf("Update of the carried-over tick: got: %d, want: 1", got)
+	}
+	if got, want := TickProgress(), 0.5; !near(got, want) {
+		t.Errorf("TickProgress after the carried-over tick: got: %f, want: %f", got, want)
+	}
+}
+
+func TestTickProgressTPSChange(t *testing.T) {
+	f, restore := newFakeTime(t)
+	defer restore()
+	f.proceed(1.5)
+	Update(FPS, FrameSkipPolicySkipDraw)
+	if got := TickProgress(); got == 0 {
+		t.Errorf("TickProgress before changing TPS: got: 0, want: non-zero")
+	}
+
+	// Changing TPS restarts the logical clock.
+	if got := Update(FPS/2, FrameSkipPolicySkipDraw); got != 0 {
+		t.Errorf("Update at changing TPS: got: %d, want: 0", got)
+	}
+	if got := TickProgress(); got != 0 {
+		t.Errorf("TickProgress at changing TPS: got: %f, want: 0", got)
+	}
+
+	// 1 tick at FPS is a half tick at FPS/2.
+	f.proceed(1)
+	if got := Update(FPS/2, FrameSkipPolicySkipDraw); got != 0 {
+		t.Errorf("Update after a half tick: got: %d, want: 0", got)
+	}
+	if got, want := TickProgress(), 0.5; !near(got, want) {
+		t.Errorf("TickProgress after a half tick: got: %f, want: %f", got, want)
+	}
+}
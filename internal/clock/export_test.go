@@ -0,0 +1,42 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+// SetNowForTesting resets the clock state and replaces the system time with f.
+// The returned function restores the system time.
+func SetNowForTesting(f func() int64) (restore func()) {
+	m.Lock()
+	defer m.Unlock()
+
+	primaryTime = 0
+	lastPrimaryTime = 0
+	frames = 0
+	logicalTime = 0
+	currentFPS = 0
+	lastFPSUpdated = 0
+	framesForFPS = 0
+	currentTPS = 0
+	ticksForTPS = 0
+	lastTPS = 0
+	tickProgress = 0
+	ping = nil
+
+	nowFunc = f
+	return func() {
+		m.Lock()
+		nowFunc = now
+		m.Unlock()
+	}
+}
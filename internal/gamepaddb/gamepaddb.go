@@ -0,0 +1,475 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gamepaddb manages the mappings from gamepads' raw buttons and axes to the standard layout.
+//
+// The mappings are in the format of SDL_GameControllerDB (https://github.com/gabomdq/SDL_GameControllerDB).
+package gamepaddb
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StandardButton represents a button in the standard layout.
+//
+// The values are the same as the indices of the W3C standard gamepad (https://www.w3.org/TR/gamepad/#remapping).
+type StandardButton int
+
+const (
+	StandardButtonRightBottom StandardButton = iota
+	StandardButtonRightRight
+	StandardButtonRightLeft
+	StandardButtonRightTop
+	StandardButtonFrontTopLeft
+	StandardButtonFrontTopRight
+	StandardButtonFrontBottomLeft
+	StandardButtonFrontBottomRight
+	StandardButtonCenterLeft
+	StandardButtonCenterRight
+	StandardButtonLeftStick
+	StandardButtonRightStick
+	StandardButtonLeftTop
+	StandardButtonLeftBottom
+	StandardButtonLeftLeft
+	StandardButtonLeftRight
+	StandardButtonCenterCenter
+	StandardButtonMax = StandardButtonCenterCenter
+)
+
+// StandardAxis represents an axis in the standard layout.
+//
+// The values are the same as the indices of the W3C standard gamepad.
+type StandardAxis int
+
+const (
+	StandardAxisLeftStickHorizontal StandardAxis = iota
+	StandardAxisLeftStickVertical
+	StandardAxisRightStickHorizontal
+	StandardAxisRightStickVertical
+	StandardAxisMax = StandardAxisRightStickVertical
+)
+
+// State represents the raw state of a gamepad.
+type State interface {
+	AxisNum() int
+	Axis(index int) float64
+	ButtonNum() int
+	Button(index int) bool
+}
+
+var sdlButtons = map[string]StandardButton{
+	"a":             StandardButtonRightBottom,
+	"b":             StandardButtonRightRight,
+	"x":             StandardButtonRightLeft,
+	"y":             StandardButtonRightTop,
+	"leftshoulder":  StandardButtonFrontTopLeft,
+	"rightshoulder": StandardButtonFrontTopRight,
+	"lefttrigger":   StandardButtonFrontBottomLeft,
+	"righttrigger":  StandardButtonFrontBottomRight,
+	"back":          StandardButtonCenterLeft,
+	"start":         StandardButtonCenterRight,
+	"leftstick":     StandardButtonLeftStick,
+	"rightstick":    StandardButtonRightStick,
+	"dpup":          StandardButtonLeftTop,
+	"dpdown":        StandardButtonLeftBottom,
+	"dpleft":        StandardButtonLeftLeft,
+	"dpright":       StandardButtonLeftRight,
+	"guide":         StandardButtonCenterCenter,
+}
+
+var sdlAxes = map[string]StandardAxis{
+	"leftx":  StandardAxisLeftStickHorizontal,
+	"lefty":  StandardAxisLeftStickVertical,
+	"rightx": StandardAxisRightStickHorizontal,
+	"righty": StandardAxisRightStickVertical,
+}
+
+type inputType int
+
+const (
+	inputTypeButton inputType = iota
+	inputTypeAxis
+	inputTypeHat
+)
+
+// input represents a raw button, a raw axis or a direction of a raw hat.
+type input struct {
+	typ   inputType
+	index int
+
+	// hatDir is the direction bit of the hat: 1 (up), 2 (right), 4 (down) or 8 (left).
+	hatDir int
+
+	// half is 1 or -1 when only the positive or negative half of the axis is used, or 0 otherwise.
+	half int
+
+	// invert is true when the axis value is inverted.
+	invert bool
+}
+
+// hatValue returns 1 if the hat of the input is in the direction, or 0 otherwise.
+//
+// GLFW 3.2 doesn't report hats separately. On Linux, each hat is reported as two axes
+// after the other axes. On the other platforms, each hat is reported as four buttons
+// (up, right, down and left) after the other buttons. hatNum is the number of the hats the mapping uses.
+func (in *input) hatValue(state State, hatNum int) float64 {
+	if hatsAsAxes() {
+		idx := state.AxisNum() - 2*hatNum + 2*in.index
+		if in.hatDir == 1 || in.hatDir == 4 {
+			idx++
+		}
+		v := state.Axis(idx)
+		if in.hatDir == 1 || in.hatDir == 8 {
+			v = -v
+		}
+		if v > 0.5 {
+			return 1
+		}
+		return 0
+	}
+	idx := state.ButtonNum() - 4*hatNum + 4*in.index
+	for d := in.hatDir; d > 1; d >>= 1 {
+		idx++
+	}
+	if state.Button(idx) {
+		return 1
+	}
+	return 0
+}
+
+// value returns the value of the input in [-1, 1] for a full axis, or in [0, 1] otherwise.
+func (in *input) value(state State, hatNum int) float64 {
+	switch in.typ {
+	case inputTypeButton:
+		if state.Button(in.index) {
+			return 1
+		}
+		return 0
+	case inputTypeHat:
+		return in.hatValue(state, hatNum)
+	}
+	v := state.Axis(in.index)
+	if in.invert {
+		v = -v
+	}
+	switch in.half {
+	case 1:
+		if v < 0 {
+			return 0
+		}
+	case -1:
+		if v > 0 {
+			return 0
+		}
+		v = -v
+	}
+	return v
+}
+
+// axisOutput represents an input mapped to a standard axis.
+type axisOutput struct {
+	input input
+
+	// half is 1 or -1 when the input is mapped only to the positive or negative half of the axis, or 0 otherwise.
+	half int
+}
+
+type mapping struct {
+	buttons map[StandardButton]input
+	axes    map[StandardAxis][]axisOutput
+
+	// hatNum is the number of the hats the mapping uses.
+	hatNum int
+}
+
+var (
+	// mappingsByGUID and mappingsByName are the mappings for the current platform.
+	mappingsByGUID = map[string]*mapping{}
+	mappingsByName = map[string]*mapping{}
+	m              sync.RWMutex
+)
+
+func init() {
+	if _, err := Update([]byte(builtinMappings)); err != nil {
+		panic(fmt.Sprintf("gamepaddb: parsing the built-in mappings failed: %v", err))
+	}
+}
+
+func currentPlatform() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "Windows"
+	case "darwin":
+		return "Mac OS X"
+	case "linux", "freebsd":
+		return "Linux"
+	}
+	return ""
+}
+
+// hatsAsAxes returns true if GLFW reports hats as axes on the current platform.
+func hatsAsAxes() bool {
+	return currentPlatform() == "Linux"
+}
+
+func parseInput(str string) (input, error) {
+	in := input{}
+	switch {
+	case strings.HasPrefix(str, "+"):
+		in.half = 1
+		str = str[1:]
+	case strings.HasPrefix(str, "-"):
+		in.half = -1
+		str = str[1:]
+	}
+	if strings.HasSuffix(str, "~") {
+		in.invert = true
+		str = str[:len(str)-1]
+	}
+	if len(str) < 2 {
+		return input{}, fmt.Errorf("gamepaddb: invalid input: %q", str)
+	}
+	switch str[0] {
+	case 'b':
+		in.typ = inputTypeButton
+	case 'a':
+		in.typ = inputTypeAxis
+	case 'h':
+		// A hat is like 'h0.4', where 4 is the direction bit.
+		tokens := strings.Split(str[1:], ".")
+		if len(tokens) != 2 {
+			return input{}, fmt.Errorf("gamepaddb: invalid input: %q", str)
+		}
+		idx, err := strconv.Atoi(tokens[0])
+		if err != nil || idx < 0 {
+			return input{}, fmt.Errorf("gamepaddb: invalid input: %q", str)
+		}
+		dir, err := strconv.Atoi(tokens[1])
+		if err != nil || (dir != 1 && dir != 2 && dir != 4 && dir != 8) {
+			return input{}, fmt.Errorf("gamepaddb: invalid input: %q", str)
+		}
+		in.typ = inputTypeHat
+		in.index = idx
+		in.hatDir = dir
+		return in, nil
+	default:
+		return input{}, fmt.Errorf("gamepaddb: unsupported input: %q", str)
+	}
+	idx, err := strconv.Atoi(str[1:])
+	if err != nil {
+		return input{}, fmt.Errorf("gamepaddb: invalid input: %q", str)
+	}
+	in.index = idx
+	return in, nil
+}
+
+// parseLine parses a line of SDL_GameControllerDB.
+//
+// parseLine returns a nil mapping when the line is for another platform.
+func parseLine(line string) (guid, name string, mp *mapping, err error) {
+	tokens := strings.Split(line, ",")
+	if len(tokens) < 2 {
+		return "", "", nil, fmt.Errorf("gamepaddb: invalid line: %q", line)
+	}
+	guid = strings.ToLower(tokens[0])
+	name = tokens[1]
+	mp = &mapping{
+		buttons: map[StandardButton]input{},
+		axes:    map[StandardAxis][]axisOutput{},
+	}
+	for _, token := range tokens[2:] {
+		if token == "" {
+			continue
+		}
+		kv := strings.SplitN(token, ":", 2)
+		if len(kv) != 2 {
+			return "", "", nil, fmt.Errorf("gamepaddb: invalid token: %q", token)
+		}
+		key, value := kv[0], kv[1]
+		if key == "platform" {
+			if value != currentPlatform() {
+				return guid, name, nil, nil
+			}
+			continue
+		}
+		half := 0
+		switch {
+		case strings.HasPrefix(key, "+"):
+			half = 1
+			key = key[1:]
+		case strings.HasPrefix(key, "-"):
+			half = -1
+			key = key[1:]
+		}
+		b, isButton := sdlButtons[key]
+		a, isAxis := sdlAxes[key]
+		if !isButton && !isAxis {
+			// Ignore unknown keys, e.g., paddles, for forward compatibility.
+			continue
+		}
+		in, err := parseInput(value)
+		if err != nil {
+			// The gamepad might still be usable without the input.
+			continue
+		}
+		if in.typ == inputTypeHat && mp.hatNum <= in.index {
+			mp.hatNum = in.index + 1
+		}
+		if isButton {
+			mp.buttons[b] = in
+			continue
+		}
+		mp.axes[a] = append(mp.axes[a], axisOutput{input: in, half: half})
+	}
+	return guid, name, mp, nil
+}
+
+// Update adds the mappings in the format of SDL_GameControllerDB.
+//
+// Each line is 'GUID,name,mapping...'. A mapping is used for gamepads whose GUIDs are the same as the given GUID.
+// As in SDL, the version part of a GUID is ignored when no mapping has the exact GUID.
+// As the GUIDs of gamepads are available only on Linux with GLFW 3.2, a mapping is also used
+// for gamepads whose names reported by the OS are the same as the given name when no GUID matches.
+// Lines for other platforms are ignored.
+//
+// Update returns true if at least one mapping is added.
+func Update(data []byte) (bool, error) {
+	type line struct {
+		guid string
+		name string
+		mp   *mapping
+	}
+	var added []line
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || l[0] == '#' {
+			continue
+		}
+		guid, name, mp, err := parseLine(l)
+		if err != nil {
+			return false, err
+		}
+		if mp == nil {
+			continue
+		}
+		added = append(added, line{guid: guid, name: name, mp: mp})
+	}
+	if err := s.Err(); err != nil {
+		return false, err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	for _, l := range added {
+		mappingsByGUID[l.guid] = l.mp
+		mappingsByName[l.name] = l.mp
+	}
+	return len(added) > 0, nil
+}
+
+// withoutVersion returns the GUID whose version part is zero.
+func withoutVersion(guid string) string {
+	if len(guid) != 32 {
+		return guid
+	}
+	return guid[:24] + "0000" + guid[28:]
+}
+
+// lookup returns the mapping for the gamepad (guid and name), or nil if there is none.
+//
+// lookup must be called with m locked.
+func lookup(guid, name string) *mapping {
+	guid = strings.ToLower(guid)
+	if mp, ok := mappingsByGUID[guid]; ok {
+		return mp
+	}
+	if mp, ok := mappingsByGUID[withoutVersion(guid)]; ok {
+		return mp
+	}
+	return mappingsByName[name]
+}
+
+// HasMapping returns true if the gamepad (guid and name) has a mapping to the standard layout.
+func HasMapping(guid, name string) bool {
+	m.RLock()
+	defer m.RUnlock()
+	return lookup(guid, name) != nil
+}
+
+// ButtonValue returns the value of the standard button in [0, 1].
+func ButtonValue(guid, name string, button StandardButton, state State) float64 {
+	m.RLock()
+	defer m.RUnlock()
+	mp := lookup(guid, name)
+	if mp == nil {
+		return 0
+	}
+	in, ok := mp.buttons[button]
+	if !ok {
+		return 0
+	}
+	v := in.value(state, mp.hatNum)
+	if in.typ == inputTypeAxis && in.half == 0 {
+		// A full axis like a trigger is in [-1, 1].
+		v = (v + 1) / 2
+	}
+	return v
+}
+
+// IsButtonPressed returns true if the standard button is pressed.
+func IsButtonPressed(guid, name string, button StandardButton, state State) bool {
+	const threshold = 0.5
+	return ButtonValue(guid, name, button, state) > threshold
+}
+
+// AxisValue returns the value of the standard axis in [-1, 1].
+func AxisValue(guid, name string, axis StandardAxis, state State) float64 {
+	m.RLock()
+	defer m.RUnlock()
+	mp := lookup(guid, name)
+	if mp == nil {
+		return 0
+	}
+	v := 0.0
+	for _, o := range mp.axes[axis] {
+		iv := o.input.value(state, mp.hatNum)
+		full := o.input.typ == inputTypeAxis && o.input.half == 0
+		if o.half == 0 {
+			if !full {
+				// A button or a half axis in [0, 1] is mapped to [-1, 1].
+				iv = iv*2 - 1
+			}
+		} else {
+			if full {
+				iv = (iv + 1) / 2
+			}
+			iv *= float64(o.half)
+		}
+		v += iv
+	}
+	if v < -1 {
+		return -1
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,235 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamepaddb_test
+
+import (
+	"runtime"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/internal/gamepaddb"
+)
+
+type testState struct {
+	axes    []float64
+	buttons []bool
+}
+
+func (s *testState) AxisNum() int {
+	return len(s.axes)
+}
+
+func (s *testState) Axis(index int) float64 {
+	if index < 0 || len(s.axes) <= index {
+		return 0
+	}
+	return s.axes[index]
+}
+
+func (s *testState) ButtonNum() int {
+	return len(s.buttons)
+}
+
+func (s *testState) Button(index int) bool {
+	if index < 0 || len(s.buttons) <= index {
+		return false
+	}
+	return s.buttons[index]
+}
+
+const (
+	testGUID = "00000000000000000000000000000000"
+	testName = "Ebiten Test Gamepad"
+)
+
+func TestUpdate(t *testing.T) {
+	ok, err := Update([]byte(`
+# comment
+00000000000000000000000000000000,Ebiten Test Gamepad,a:b1,b:b0,lefttrigger:a2,dpleft:-a3,dpright:+a3,leftx:a0,lefty:a1~,
+00000000000000000000000000000000,Ebiten Test Gamepad For Unknown Platform,a:b0,platform:Unknown,
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("Update() returned false, wanted true")
+	}
+	if !HasMapping("", testName) {
+		t.Errorf("HasMapping(%q) = false, wanted true", testName)
+	}
+	if name := "Ebiten Test Gamepad For Unknown Platform"; HasMapping("", name) {
+		t.Errorf("HasMapping(%q) = true, wanted false", name)
+	}
+
+	if _, err := Update([]byte("invalid")); err == nil {
+		t.Errorf("Update() must return an error for an invalid line")
+	}
+}
+
+func TestButtonValue(t *testing.T) {
+	if _, err := Update([]byte("00000000000000000000000000000000,Ebiten Test Gamepad,a:b1,b:b0,lefttrigger:a2,dpleft:-a3,dpright:+a3,\n")); err != nil {
+		t.Fatal(err)
+	}
+	s := &testState{
+		axes:    []float64{0, 0, 0, -0.5},
+		buttons: []bool{false, true},
+	}
+	testCases := []struct {
+		button   StandardButton
+		expected float64
+	}{
+		{StandardButtonRightBottom, 1},
+		{StandardButtonRightRight, 0},
+		{StandardButtonFrontBottomLeft, 0.5},
+		{StandardButtonLeftLeft, 0.5},
+		{StandardButtonLeftRight, 0},
+		{StandardButtonRightTop, 0},
+	}
+	for _, c := range testCases {
+		got := ButtonValue("", testName, c.button, s)
+		if got != c.expected {
+			t.Errorf("ButtonValue(%d) = %f, wanted %f", c.button, got, c.expected)
+		}
+	}
+	if !IsButtonPressed("", testName, StandardButtonRightBottom, s) {
+		t.Errorf("IsButtonPressed(StandardButtonRightBottom) = false, wanted true")
+	}
+	if IsButtonPressed("", "Unknown Gamepad", StandardButtonRightBottom, s) {
+		t.Errorf("IsButtonPressed for an unknown gamepad must return false")
+	}
+}
+
+func TestAxisValue(t *testing.T) {
+	if _, err := Update([]byte("00000000000000000000000000000000,Ebiten Test Gamepad,leftx:a0,lefty:a1~,+rightx:b0,-rightx:b1,\n")); err != nil {
+		t.Fatal(err)
+	}
+	s := &testState{
+		axes:    []float64{0.25, 0.5},
+		buttons: []bool{false, true},
+	}
+	testCases := []struct {
+		axis     StandardAxis
+		expected float64
+	}{
+		{StandardAxisLeftStickHorizontal, 0.25},
+		{StandardAxisLeftStickVertical, -0.5},
+		{StandardAxisRightStickHorizontal, -1},
+		{StandardAxisRightStickVertical, 0},
+	}
+	for _, c := range testCases {
+		got := AxisValue("", testName, c.axis, s)
+		if got != c.expected {
+			t.Errorf("AxisValue(%d) = %f, wanted %f", c.axis, got, c.expected)
+		}
+	}
+}
+
+func TestLookupByGUID(t *testing.T) {
+	if _, err := Update([]byte(`
+030000001234000078560000cdab0000,Ebiten Test GUID Gamepad,a:b0,
+03000000123400007856000000000000,Ebiten Test GUID Gamepad Any Version,a:b1,
+`)); err != nil {
+		t.Fatal(err)
+	}
+	s := &testState{
+		buttons: []bool{true, false},
+	}
+	testCases := []struct {
+		guid     string
+		name     string
+		expected bool
+	}{
+		// The exact GUID.
+		{"030000001234000078560000cdab0000", "", true},
+		{"030000001234000078560000CDAB0000", "", true},
+		// Another version matches the mapping of the version 0.
+		{"03000000123400007856000001000000", "", false},
+		// The name is used when no GUID matches.
+		{"03000000000000000000000000000000", "Ebiten Test GUID Gamepad", true},
+		// The GUID has priority over the name.
+		{"030000001234000078560000cdab0000", "Ebiten Test GUID Gamepad Any Version", true},
+	}
+	for _, c := range testCases {
+		if !HasMapping(c.guid, c.name) {
+			t.Errorf("HasMapping(%q, %q) = false, wanted true", c.guid, c.name)
+			continue
+		}
+		if got := IsButtonPressed(c.guid, c.name, StandardButtonRightBottom, s); got != c.expected {
+			t.Errorf("IsButtonPressed(%q, %q) = %t, wanted %t", c.guid, c.name, got, c.expected)
+		}
+	}
+	if guid := "03000000123400000000000000000000"; HasMapping(guid, "") {
+		t.Errorf("HasMapping(%q, \"\") = true, wanted false", guid)
+	}
+}
+
+func TestHat(t *testing.T) {
+	if _, err := Update([]byte(testGUID + ",Ebiten Test Gamepad,dpup:h0.1,dpright:h0.2,dpdown:h0.4,dpleft:h0.8,guide:h1.1,leftx:a0,\n")); err != nil {
+		t.Fatal(err)
+	}
+	// On Linux, GLFW 3.2 reports each hat as two axes after the other axes.
+	s := &testState{
+		axes: []float64{0.25, 1, 0, 0, -1},
+	}
+	testCases := []struct {
+		button   StandardButton
+		expected float64
+	}{
+		{StandardButtonLeftTop, 0},
+		{StandardButtonLeftRight, 1},
+		{StandardButtonLeftBottom, 0},
+		{StandardButtonLeftLeft, 0},
+		{StandardButtonCenterCenter, 1},
+	}
+	for _, c := range testCases {
+		if got := ButtonValue("", testName, c.button, s); got != c.expected {
+			t.Errorf("ButtonValue(%d) = %f, wanted %f", c.button, got, c.expected)
+		}
+	}
+	if got := AxisValue("", testName, StandardAxisLeftStickHorizontal, s); got != 0.25 {
+		t.Errorf("AxisValue(StandardAxisLeftStickHorizontal) = %f, wanted 0.25", got)
+	}
+
+	for _, str := range []string{"h0", "h0.3", "h-1.1", "h0.16"} {
+		if _, err := Update([]byte(testGUID + ",Ebiten Test Gamepad,dpup:" + str + ",\n")); err != nil {
+			t.Errorf("Update() with %q must ignore the input but returned %v", str, err)
+		}
+		if got := ButtonValue("", testName, StandardButtonLeftTop, s); got != 0 {
+			t.Errorf("ButtonValue(StandardButtonLeftTop) with %q = %f, wanted 0", str, got)
+		}
+	}
+}
+
+func TestBuiltinMappings(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the built-in mappings keyed by GUIDs are only for Linux")
+	}
+	guids := []string{
+		// Xbox 360 Controller with the xpad driver.
+		"030000005e0400008e02000014010000",
+		// DualShock 4 with the hid-sony driver.
+		"030000004c050000c405000011810000",
+		// DualSense with the hid-playstation driver via Bluetooth.
+		"050000004c050000e60c000000810000",
+	}
+	for _, guid := range guids {
+		if !HasMapping(guid, "") {
+			t.Errorf("HasMapping(%q, \"\") = false, wanted true", guid)
+		}
+	}
+	// DualShock 4 with an old hid-sony driver has another layout.
+	if guid := "030000004c050000c405000011010000"; HasMapping(guid, "") {
+		t.Errorf("HasMapping(%q, \"\") = true, wanted false", guid)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphics
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/internal/affine"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+)
+
+// CapturedImage represents an image that a captured command uses.
+type CapturedImage struct {
+	// ID identifies the image. ID is assigned when the image is captured at the first time.
+	ID     int
+	Width  int
+	Height int
+	Screen bool
+}
+
+// CapturedCommand represents an executed command recorded while capturing is enabled.
+type CapturedCommand struct {
+	// Type is "fill", "draw" or "replace-pixels".
+	Type string
+
+	Dst CapturedImage
+
+	// Srcs is the source images: the source image, the other source images of the custom shader, the palette and the mask.
+	Srcs []CapturedImage
+
+	// Bounds is the bounding rectangle of the vertices for "draw", or the replaced region for "replace-pixels".
+	Bounds image.Rectangle
+
+	Triangles int
+
+	// ColorM is the elements of the color matrix. ColorM is nil when the matrix is identity.
+	ColorM []float64
+
+	// MaxAlpha is the maximum of the alpha scales of the vertices.
+	MaxAlpha float32
+
+	Blend     opengl.Blend
+	Filter    opengl.Filter
+	Address   opengl.Address
+	DstRegion image.Rectangle
+	Shader    bool
+	Paletted  bool
+	Masked    bool
+
+	// Color is the color for "fill".
+	Color color.RGBA
+}
+
+// capturer records the executed commands.
+//
+// capturer is used only in commandQueue.Flush, and is guarded by the queue's mutex.
+type capturer struct {
+	enabled  bool
+	commands []CapturedCommand
+	lastID   int
+}
+
+// SetCaptureEnabled sets whether the executed commands are recorded.
+//
+// When capturing is disabled, the recorded commands are discarded.
+func SetCaptureEnabled(enabled bool) {
+	theCommandQueue.m.Lock()
+	theCommandQueue.capturer.enabled = enabled
+	if !enabled {
+		theCommandQueue.capturer.commands = nil
+	}
+	theCommandQueue.m.Unlock()
+}
+
+// TakeCapturedCommands returns the commands recorded since the last call and clears them.
+func TakeCapturedCommands() []CapturedCommand {
+	theCommandQueue.m.Lock()
+	cs := theCommandQueue.capturer.commands
+	theCommandQueue.capturer.commands = nil
+	theCommandQueue.m.Unlock()
+	return cs
+}
+
+func (c *capturer) image(img *Image) CapturedImage {
+	if img.id == 0 {
+		c.lastID++
+		img.id = c.lastID
+	}
+	return CapturedImage{
+		ID:     img.id,
+		Width:  img.width,
+		Height: img.height,
+		Screen: img.screen,
+	}
+}
+
+// capture records the command. vertices is the vertices of the command if the command is a drawImageCommand.
+func (c *capturer) capture(command command, vertices []float32) {
+	switch command := command.(type) {
+	case *fillCommand:
+		c.commands = append(c.commands, CapturedCommand{
+			Type:  "fill",
+			Dst:   c.image(command.dst),
+			Color: command.color,
+		})
+	case *replacePixelsCommand:
+		c.commands = append(c.commands, CapturedCommand{
+			Type:   "replace-pixels",
+			Dst:    c.image(command.dst),
+			Bounds: image.Rect(command.x, command.y, command.x+command.width, command.y+command.height),
+		})
+	case *drawImageCommand:
+		cc := CapturedCommand{
+			Type:      "draw",
+			Dst:       c.image(command.dst),
+			Triangles: command.indicesNum / 3,
+			Blend:     command.blend,
+			Filter:    command.filter,
+			Address:   command.address,
+			DstRegion: command.dstRegion,
+			Shader:    command.shader != nil,
+			Paletted:  command.palette != nil,
+			Masked:    command.mask.image != nil,
+		}
+		if command.src != nil {
+			cc.Srcs = append(cc.Srcs, c.image(command.src))
+		}
+		for _, src := range command.shaderSrcs.images {
+			if src != nil {
+				cc.Srcs = append(cc.Srcs, c.image(src))
+			}
+		}
+		if command.palette != nil {
+			cc.Srcs = append(cc.Srcs, c.image(command.palette))
+		}
+		if command.mask.image != nil {
+			cc.Srcs = append(cc.Srcs, c.image(command.mask.image))
+		}
+		if !command.color.Equals(&affine.ColorM{}) {
+			clr := command.color
+			cc.ColorM = append([]float64{}, clr.UnsafeElements()...)
+		}
+		cc.Bounds, cc.MaxAlpha = verticesBounds(vertices)
+		c.commands = append(c.commands, cc)
+	}
+}
+
+// verticesBounds returns the bounding rectangle of the vertices and the maximum of their alpha scales.
+func verticesBounds(vertices []float32) (image.Rectangle, float32) {
+	n := floatsPerVertex()
+	if len(vertices) < n {
+		return image.Rectangle{}, 0
+	}
+	x0, y0 := math.Inf(1), math.Inf(1)
+	x1, y1 := math.Inf(-1), math.Inf(-1)
+	var alpha float32
+	for i := 0; i+n <= len(vertices); i += n {
+		x, y := float64(vertices[i]), float64(vertices[i+1])
+		x0, y0 = math.Min(x0, x), math.Min(y0, y)
+		x1, y1 = math.Max(x1, x), math.Max(y1, y)
+		// The last element of a vertex is the alpha scale.
+		if a := vertices[i+n-1]; alpha < a {
+			alpha = a
+		}
+	}
+	r := image.Rect(int(math.Floor(x0)), int(math.Floor(y0)), int(math.Ceil(x1)), int(math.Ceil(y1)))
+	return r, alpha
+}
@@ -50,6 +50,19 @@ type commandQueue struct {
 	// vertices is never shrunk since re-extending a vertices buffer is heavy.
 	verticesNum int
 
+	// indices represents indices data for the vertices.
+	// Each index is relative to the first vertex of its command.
+	indices []uint16
+
+	// indicesNum represents the current length of indices.
+	indicesNum int
+
+	// tmpIndices is a buffer to send indices to OpenGL's element array buffer.
+	tmpIndices []uint16
+
+	// capturer records the executed commands for debugging.
+	capturer capturer
+
 	m sync.Mutex
 }
 
@@ -70,26 +83,101 @@ func (q *commandQueue) appendVertices(vertices []float32) {
 	q.verticesNum += len(vertices)
 }
 
+// appendIndices appends indices to the queue adding offset to each index.
+func (q *commandQueue) appendIndices(indices []uint16, offset uint16) {
+	if len(q.indices) < q.indicesNum+len(indices) {
+		n := q.indicesNum + len(indices) - len(q.indices)
+		q.indices = append(q.indices, make([]uint16, n)...)
+	}
+	for i := 0; i < len(indices); i++ {
+		q.indices[q.indicesNum+i] = indices[i] + offset
+	}
+	q.indicesNum += len(indices)
+}
+
 // EnqueueDrawImageCommand enqueues a drawing-image command.
-func (q *commandQueue) EnqueueDrawImageCommand(dst, src *Image, vertices []float32, clr *affine.ColorM, mode opengl.CompositeMode) {
+//
+// indices are relative to the first vertex of vertices.
+// srcRegion is the region in pixels of src to repeat with address. srcRegion is ignored when address is AddressDefault.
+// dstRegion is the region of dst to render to. If dstRegion is empty, the whole dst is the target.
+func (q *commandQueue) EnqueueDrawImageCommand(dst, src *Image, vertices []float32, indices []uint16, clr *affine.ColorM, blend opengl.Blend, filter opengl.Filter, address opengl.Address, srcRegion, dstRegion image.Rectangle) {
+	q.enqueueDrawCommand(dst, src, vertices, indices, clr, blend, filter, address, srcRegion, dstRegion, nil, nil, nil, shaderSources{}, maskSource{})
+}
+
+// EnqueueDrawPalettedImageCommand enqueues a drawing-image command with a palette.
+//
+// The red channel of src is regarded as an index of palette. src is always sampled with the nearest filter.
+func (q *commandQueue) EnqueueDrawPalettedImageCommand(dst, src, palette *Image, vertices []float32, indices []uint16, clr *affine.ColorM, blend opengl.Blend, address opengl.Address, srcRegion, dstRegion image.Rectangle) {
+	q.enqueueDrawCommand(dst, src, vertices, indices, clr, blend, opengl.Nearest, address, srcRegion, dstRegion, nil, nil, palette, shaderSources{}, maskSource{})
+}
+
+// EnqueueDrawMaskedImageCommand enqueues a drawing-image command with a mask.
+//
+// The mask's texel at (x, y) + maskOffset masks the destination pixel at (x, y),
+// and the destination pixels outside of maskRegion are fully masked.
+// palette can be nil. If palette is not nil, src is always sampled with the nearest filter.
+func (q *commandQueue) EnqueueDrawMaskedImageCommand(dst, src, palette, mask *Image, maskOffset image.Point, maskRegion image.Rectangle, vertices []float32, indices []uint16, clr *affine.ColorM, blend opengl.Blend, filter opengl.Filter, address opengl.Address, srcRegion, dstRegion image.Rectangle) {
+	if palette != nil {
+		filter = opengl.Nearest
+	}
+	m := maskSource{
+		image:  mask,
+		offset: maskOffset,
+		region: maskRegion,
+	}
+	q.enqueueDrawCommand(dst, src, vertices, indices, clr, blend, filter, address, srcRegion, dstRegion, nil, nil, palette, shaderSources{}, m)
+}
+
+// EnqueueDrawShaderCommand enqueues a drawing command with a custom shader.
+//
+// srcs are the source images, and each can be nil. offsets are the positions in pixels of srcs in their textures.
+// uniforms are the values of the shader's uniform variables in the declared order.
+func (q *commandQueue) EnqueueDrawShaderCommand(dst *Image, srcs [ShaderImageNum]*Image, offsets [ShaderImageNum]image.Point, vertices []float32, indices []uint16, shader *Shader, uniforms [][]float32, blend opengl.Blend, filter opengl.Filter, dstRegion image.Rectangle) {
+	s := shaderSources{offsets: offsets}
+	copy(s.images[:], srcs[1:])
+	q.enqueueDrawCommand(dst, srcs[0], vertices, indices, &affine.ColorM{}, blend, filter, opengl.AddressDefault, image.Rectangle{}, dstRegion, shader, uniforms, nil, s, maskSource{})
+}
+
+func (q *commandQueue) enqueueDrawCommand(dst, src *Image, vertices []float32, indices []uint16, clr *affine.ColorM, blend opengl.Blend, filter opengl.Filter, address opengl.Address, srcRegion, dstRegion image.Rectangle, shader *Shader, uniforms [][]float32, palette *Image, shaderSrcs shaderSources, mask maskSource) {
+	if len(vertices)/floatsPerVertex() > MaxVerticesNum {
+		panic(fmt.Sprintf("graphics: the number of vertices must be equal to or less than %d", MaxVerticesNum))
+	}
+	if len(indices) > MaxIndicesNum {
+		panic(fmt.Sprintf("graphics: the number of indices must be equal to or less than %d", MaxIndicesNum))
+	}
 	// Avoid defer for performance
 	q.m.Lock()
 	q.appendVertices(vertices)
 	if 0 < len(q.commands) {
 		if c, ok := q.commands[len(q.commands)-1].(*drawImageCommand); ok {
-			if c.canMerge(dst, src, clr, mode) {
+			if c.canMerge(dst, src, clr, blend, filter, address, srcRegion, dstRegion, shader, uniforms, palette, shaderSrcs, mask) &&
+				c.vertexCount()+len(vertices)/floatsPerVertex() <= MaxVerticesNum &&
+				c.indicesNum+len(indices) <= MaxIndicesNum {
+				q.appendIndices(indices, uint16(c.vertexCount()))
 				c.verticesNum += len(vertices)
+				c.indicesNum += len(indices)
 				q.m.Unlock()
 				return
 			}
 		}
 	}
+	q.appendIndices(indices, 0)
 	c := &drawImageCommand{
 		dst:         dst,
 		src:         src,
 		verticesNum: len(vertices),
+		indicesNum:  len(indices),
 		color:       *clr,
-		mode:        mode,
+		blend:       blend,
+		filter:      filter,
+		address:     address,
+		srcRegion:   srcRegion,
+		dstRegion:   dstRegion,
+		shader:      shader,
+		uniforms:    uniforms,
+		palette:     palette,
+		shaderSrcs:  shaderSrcs,
+		mask:        mask,
 	}
 	q.commands = append(q.commands, c)
 	q.m.Unlock()
@@ -105,32 +193,26 @@ func (q *commandQueue) Enqueue(command command) {
 }
 
 // commandGroups separates q.commands into some groups.
-// The number of quads of drawImageCommand in one groups must be equal to or less than
-// its limit (maxQuads).
+// The number of vertices and indices of drawImageCommands in one group must be equal to or less than
+// their limits (MaxVerticesNum and MaxIndicesNum).
 func (q *commandQueue) commandGroups() [][]command {
-	cs := q.commands
 	var gs [][]command
-	quads := 0
-	for 0 < len(cs) {
+	vertices := 0
+	indices := 0
+	for _, c := range q.commands {
 		if len(gs) == 0 {
 			gs = append(gs, []command{})
 		}
-		c := cs[0]
-		switch c := c.(type) {
-		case *drawImageCommand:
-			if maxQuads >= quads+c.quadsNum() {
-				quads += c.quadsNum()
-				break
+		if c, ok := c.(*drawImageCommand); ok {
+			if vertices+c.vertexCount() > MaxVerticesNum || indices+c.indicesNum > MaxIndicesNum {
+				gs = append(gs, []command{})
+				vertices = 0
+				indices = 0
 			}
-			cc := c.split(maxQuads - quads)
-			gs[len(gs)-1] = append(gs[len(gs)-1], cc[0])
-			cs[0] = cc[1]
-			quads = 0
-			gs = append(gs, []command{})
-			continue
+			vertices += c.vertexCount()
+			indices += c.indicesNum
 		}
 		gs[len(gs)-1] = append(gs[len(gs)-1], c)
-		cs = cs[1:]
 	}
 	return gs
 }
@@ -141,42 +223,56 @@ func (q *commandQueue) Flush() error {
 	defer q.m.Unlock()
 	// glViewport must be called at least at every frame on iOS.
 	opengl.GetContext().ResetViewportSize()
-	n := 0
-	lastN := 0
+	v := 0
+	idx := 0
 	for _, g := range q.commandGroups() {
+		// Indices in the queue are relative to their commands. Make them relative to the group.
+		q.tmpIndices = q.tmpIndices[:0]
+		lastV := v
 		for _, c := range g {
-			switch c := c.(type) {
-			case *drawImageCommand:
-				n += c.verticesNum
+			c, ok := c.(*drawImageCommand)
+			if !ok {
+				continue
 			}
+			base := uint16((v - lastV) / floatsPerVertex())
+			for _, i := range q.indices[idx : idx+c.indicesNum] {
+				q.tmpIndices = append(q.tmpIndices, i+base)
+			}
+			v += c.verticesNum
+			idx += c.indicesNum
 		}
-		if 0 < n-lastN {
-			opengl.GetContext().BufferSubData(opengl.ArrayBuffer, q.vertices[lastN:n])
-		}
-		// NOTE: WebGL doesn't seem to have Check gl.MAX_ELEMENTS_VERTICES or gl.MAX_ELEMENTS_INDICES so far.
-		// Let's use them to compare to len(quads) in the future.
-		if maxQuads < (n-lastN)*opengl.Float.SizeInBytes()/QuadVertexSizeInBytes() {
-			return fmt.Errorf("len(quads) must be equal to or less than %d", maxQuads)
+		if 0 < v-lastV {
+			opengl.GetContext().BufferSubData(opengl.ArrayBuffer, q.vertices[lastV:v])
+			opengl.GetContext().ElementArrayBufferSubData(q.tmpIndices)
 		}
 		numc := len(g)
 		indexOffsetInBytes := 0
+		cv := lastV
 		for _, c := range g {
 			if err := c.Exec(indexOffsetInBytes); err != nil {
 				return err
 			}
-			if c, ok := c.(*drawImageCommand); ok {
-				n := c.verticesNum * opengl.Float.SizeInBytes() / QuadVertexSizeInBytes()
-				indexOffsetInBytes += 6 * n * 2
+			dc, isDraw := c.(*drawImageCommand)
+			if q.capturer.enabled {
+				var vs []float32
+				if isDraw {
+					vs = q.vertices[cv : cv+dc.verticesNum]
+				}
+				q.capturer.capture(c, vs)
+			}
+			if isDraw {
+				indexOffsetInBytes += 2 * dc.indicesNum
+				cv += dc.verticesNum
 			}
 		}
 		if 0 < numc {
 			// Call glFlush to prevent black flicking (especially on Android (#226) and iOS).
 			opengl.GetContext().Flush()
 		}
-		lastN = n
 	}
 	q.commands = nil
 	q.verticesNum = 0
+	q.indicesNum = 0
 	return nil
 }
 
@@ -208,6 +304,15 @@ func (c *fillCommand) Exec(indexOffsetInBytes int) error {
 	if err := opengl.GetContext().FillFramebuffer(r, g, b, a); err != nil {
 		return err
 	}
+	c.dst.texture.invalidateMipmap()
+	if mf := c.dst.msaaFramebuffer; mf != nil {
+		mf.setAsViewport()
+		if err := opengl.GetContext().FillFramebuffer(r, g, b, a); err != nil {
+			return err
+		}
+	}
+	c.dst.msaaNewer = false
+	c.dst.textureNewer = false
 
 	// Flush is needed after filling (#419)
 	opengl.GetContext().Flush()
@@ -219,83 +324,229 @@ type drawImageCommand struct {
 	dst         *Image
 	src         *Image
 	verticesNum int
+	indicesNum  int
 	color       affine.ColorM
-	mode        opengl.CompositeMode
+	blend       opengl.Blend
+
+	// filter is the filter to sample src.
+	filter opengl.Filter
+
+	// address is the address mode to sample src, and srcRegion is the region of src to repeat.
+	address   opengl.Address
+	srcRegion image.Rectangle
+
+	// dstRegion is the region of dst to render to. An empty region means the whole dst.
+	dstRegion image.Rectangle
+
+	// shader is a custom shader. If shader is nil, the default shader is used.
+	shader   *Shader
+	uniforms [][]float32
+
+	// palette is the palette to look up the colors of src. If palette is nil, src's colors are used as they are.
+	palette *Image
+
+	// shaderSrcs is the source images of the custom shader other than src.
+	shaderSrcs shaderSources
+
+	// mask is the mask to multiply the result by. If mask.image is nil, the result is not masked.
+	mask maskSource
+}
+
+// shaderSources represents the source images of a custom shader other than the first one.
+type shaderSources struct {
+	// images is the second and later source images. Each can be nil.
+	images [ShaderImageNum - 1]*Image
+
+	// offsets is the positions in pixels of all the source images in their textures, including the first one.
+	offsets [ShaderImageNum]image.Point
+}
+
+// maskSource represents a mask of a drawing command.
+type maskSource struct {
+	// image is the mask image. Only the alpha channel is used.
+	image *Image
+
+	// offset is the offset in pixels from the destination positions to the mask's texels.
+	offset image.Point
+
+	// region is the region in pixels of the destination that the mask covers.
+	region image.Rectangle
+}
+
+// VertexSizeInBytes returns the size in bytes of one vertex.
+func VertexSizeInBytes() int {
+	return theArrayBufferLayout.totalBytes()
 }
 
 // QuadVertexSizeInBytes returns the size in bytes of vertices for a quadrangle.
 func QuadVertexSizeInBytes() int {
-	return 4 * theArrayBufferLayout.totalBytes()
+	return 4 * VertexSizeInBytes()
+}
+
+// floatsPerVertex returns the number of float values for one vertex.
+func floatsPerVertex() int {
+	return VertexSizeInBytes() / opengl.Float.SizeInBytes()
 }
 
 // Exec executes the drawImageCommand.
 func (c *drawImageCommand) Exec(indexOffsetInBytes int) error {
-	f, err := c.dst.createFramebufferIfNeeded()
+	if c.src != nil {
+		if err := c.src.resolveMSAA(); err != nil {
+			return err
+		}
+	}
+	for _, src := range c.shaderSrcs.images {
+		if src == nil {
+			continue
+		}
+		if err := src.resolveMSAA(); err != nil {
+			return err
+		}
+	}
+	if c.palette != nil {
+		if err := c.palette.resolveMSAA(); err != nil {
+			return err
+		}
+		if t := c.palette.texture; t.filter != opengl.Nearest {
+			opengl.GetContext().SetTextureFilter(t.native, opengl.Nearest)
+			t.filter = opengl.Nearest
+		}
+	}
+	if m := c.mask.image; m != nil {
+		if err := m.resolveMSAA(); err != nil {
+			return err
+		}
+		if t := m.texture; t.filter != opengl.Nearest {
+			opengl.GetContext().SetTextureFilter(t.native, opengl.Nearest)
+			t.filter = opengl.Nearest
+		}
+	}
+	f, err := c.dst.renderFramebuffer()
 	if err != nil {
 		return err
 	}
 	f.setAsViewport()
 
-	opengl.GetContext().BlendFunc(c.mode)
+	opengl.GetContext().SetBlend(c.blend)
+	_, h := c.dst.Size()
+	opengl.GetContext().SetScissor(f.scissorRect(c.dstRegion, h))
 
-	n := c.quadsNum()
-	if n == 0 {
+	if c.indicesNum == 0 {
 		return nil
 	}
-	_, h := c.dst.Size()
+	filter := c.filter
+	if c.address != opengl.AddressDefault && filter == opengl.Linear {
+		// Linear filtering is done in the shader so that the texels outside of the source region are not used.
+		filter = opengl.Nearest
+	}
+	if c.src != nil {
+		c.src.texture.prepareToSample(filter)
+	}
+	for _, src := range c.shaderSrcs.images {
+		if src != nil {
+			src.texture.prepareToSample(filter)
+		}
+	}
+	c.dst.texture.invalidateMipmap()
+
 	proj := f.projectionMatrix(h)
-	theOpenGLState.useProgram(proj, c.src.texture.native, c.color)
+	if c.shader != nil {
+		srcs := [ShaderImageNum]*Image{c.src}
+		copy(srcs[1:], c.shaderSrcs.images[:])
+		if err := theOpenGLState.useShaderProgram(proj, srcs, c.shaderSrcs.offsets, c.shader, c.uniforms); err != nil {
+			return err
+		}
+	} else {
+		sw, sh := emath.NextPowerOf2Int(c.src.width), emath.NextPowerOf2Int(c.src.height)
+		var palette *opengl.Texture
+		if c.palette != nil {
+			palette = &c.palette.texture.native
+		}
+		theOpenGLState.useProgram(proj, c.src.texture.native, sw, sh, c.color, c.address, c.filter, c.srcRegion, palette, c.mask)
+	}
 	// TODO: We should call glBindBuffer here?
 	// The buffer is already bound at begin() but it is counterintuitive.
-	opengl.GetContext().DrawElements(opengl.Triangles, 6*n, indexOffsetInBytes)
+	opengl.GetContext().DrawElements(opengl.Triangles, c.indicesNum, indexOffsetInBytes)
 	return nil
 }
 
-// split splits the drawImageCommand c into two drawImageCommands.
-//
-// split is called when the number of vertices reaches of the maximum and
-// a command is needed to be executed as another draw call.
-func (c *drawImageCommand) split(quadsNum int) [2]*drawImageCommand {
-	c1 := *c
-	c2 := *c
-	s := opengl.Float.SizeInBytes()
-	n := quadsNum * QuadVertexSizeInBytes() / s
-	c1.verticesNum = n
-	c2.verticesNum -= n
-	return [2]*drawImageCommand{&c1, &c2}
-}
-
 // canMerge returns a boolean value indicating whether the other drawImageCommand can be merged
 // with the drawImageCommand c.
-func (c *drawImageCommand) canMerge(dst, src *Image, clr *affine.ColorM, mode opengl.CompositeMode) bool {
+func (c *drawImageCommand) canMerge(dst, src *Image, clr *affine.ColorM, blend opengl.Blend, filter opengl.Filter, address opengl.Address, srcRegion, dstRegion image.Rectangle, shader *Shader, uniforms [][]float32, palette *Image, shaderSrcs shaderSources, mask maskSource) bool {
 	if c.dst != dst {
 		return false
 	}
+	if c.palette != palette {
+		return false
+	}
+	if c.mask != mask {
+		return false
+	}
+	if c.shaderSrcs != shaderSrcs {
+		return false
+	}
 	if c.src != src {
 		return false
 	}
 	if !c.color.Equals(clr) {
 		return false
 	}
-	if c.mode != mode {
+	if c.blend != blend {
 		return false
 	}
+	if c.filter != filter {
+		return false
+	}
+	if c.address != address {
+		return false
+	}
+	if c.address != opengl.AddressDefault && c.srcRegion != srcRegion {
+		return false
+	}
+	if c.dstRegion != dstRegion {
+		return false
+	}
+	if c.shader != shader {
+		return false
+	}
+	if len(c.uniforms) != len(uniforms) {
+		return false
+	}
+	for i := range uniforms {
+		if !areSameFloat32Array(c.uniforms[i], uniforms[i]) {
+			return false
+		}
+	}
 	return true
 }
 
-// quadsNum returns the number of quadrangles.
-func (c *drawImageCommand) quadsNum() int {
-	return c.verticesNum * opengl.Float.SizeInBytes() / QuadVertexSizeInBytes()
+// vertexCount returns the number of vertices.
+func (c *drawImageCommand) vertexCount() int {
+	return c.verticesNum / floatsPerVertex()
 }
 
 // replacePixelsCommand represents a command to replace pixels of an image.
 type replacePixelsCommand struct {
 	dst    *Image
 	pixels []uint8
+
+	// whole indicates whether the pixels cover the whole texture.
+	whole bool
+
+	x      int
+	y      int
+	width  int
+	height int
 }
 
 // Exec executes the replacePixelsCommand.
 func (c *replacePixelsCommand) Exec(indexOffsetInBytes int) error {
+	if !c.whole {
+		// The rest of the texture must be up to date.
+		if err := c.dst.resolveMSAA(); err != nil {
+			return err
+		}
+	}
 	f, err := c.dst.createFramebufferIfNeeded()
 	if err != nil {
 		return err
@@ -305,8 +556,11 @@ func (c *replacePixelsCommand) Exec(indexOffsetInBytes int) error {
 	// Filling with non black or white color is required here for glTexSubImage2D.
 	// Very mysterious but this actually works (Issue #186).
 	// This is needed even after fixing a shader bug at f537378f2a6a8ef56e1acf1c03034967b77c7b51.
-	if err := opengl.GetContext().FillFramebuffer(0, 0, 0.5, 1); err != nil {
-		return err
+	// Filling is skipped when only a region is replaced, or the rest of the texture would be lost.
+	if c.whole {
+		if err := opengl.GetContext().FillFramebuffer(0, 0, 0.5, 1); err != nil {
+			return err
+		}
 	}
 	// This is necessary on Android. We can't call glClear just before glTexSubImage2D without
 	// glFlush. glTexSubImage2D didn't work without this hack at least on Nexus 5x (#211).
@@ -314,7 +568,9 @@ func (c *replacePixelsCommand) Exec(indexOffsetInBytes int) error {
 	// TODO: Can we have a better way like optimizing commands?
 	opengl.GetContext().Flush()
 	opengl.GetContext().BindTexture(c.dst.texture.native)
-	opengl.GetContext().TexSubImage2D(c.pixels, emath.NextPowerOf2Int(c.dst.width), emath.NextPowerOf2Int(c.dst.height))
+	opengl.GetContext().TexSubImage2D(c.pixels, c.x, c.y, c.width, c.height)
+	c.dst.texture.invalidateMipmap()
+	c.dst.markTextureUpdated()
 	return nil
 }
 
@@ -328,6 +584,9 @@ func (c *disposeCommand) Exec(indexOffsetInBytes int) error {
 	if c.target.framebuffer != nil {
 		opengl.GetContext().DeleteFramebuffer(c.target.framebuffer.native)
 	}
+	if c.target.msaaFramebuffer != nil {
+		opengl.GetContext().DeleteMultisampleFramebuffer(c.target.msaaFramebuffer.native)
+	}
 	if c.target.texture != nil {
 		opengl.GetContext().DeleteTexture(c.target.texture.native)
 	}
@@ -360,6 +619,44 @@ func (c *newImageFromImageCommand) Exec(indexOffsetInBytes int) error {
 	}
 	c.result.texture = &texture{
 		native: native,
+		filter: c.filter,
+	}
+	return nil
+}
+
+// newCompressedImageCommand represents a command to create an image from compressed data.
+type newCompressedImageCommand struct {
+	result *Image
+	data   []uint8
+	width  int
+	height int
+	format opengl.CompressedFormat
+	filter opengl.Filter
+}
+
+// Exec executes the newCompressedImageCommand.
+func (c *newCompressedImageCommand) Exec(indexOffsetInBytes int) error {
+	if c.width < 1 {
+		return errors.New("graphics: width must be equal or more than 1.")
+	}
+	if c.height < 1 {
+		return errors.New("graphics: height must be equal or more than 1.")
+	}
+	if !opengl.GetContext().IsCompressedFormatAvailable(c.format) {
+		return fmt.Errorf("graphics: the compressed format %d is not available", c.format)
+	}
+	w := emath.NextPowerOf2Int(c.width)
+	h := emath.NextPowerOf2Int(c.height)
+	if len(c.data) != c.format.DataSizeInBytes(w, h) {
+		panic(fmt.Sprintf("graphics: invalid compressed data size: %d", len(c.data)))
+	}
+	native, err := opengl.GetContext().NewCompressedTexture(w, h, c.format, c.data, c.filter)
+	if err != nil {
+		return err
+	}
+	c.result.texture = &texture{
+		native: native,
+		filter: c.filter,
 	}
 	return nil
 }
@@ -370,6 +667,7 @@ type newImageCommand struct {
 	width  int
 	height int
 	filter opengl.Filter
+	hdr    bool
 }
 
 // Exec executes a newImageCommand.
@@ -382,12 +680,19 @@ func (c *newImageCommand) Exec(indexOffsetInBytes int) error {
 	if h < 1 {
 		return errors.New("graphics: height must be equal or more than 1.")
 	}
-	native, err := opengl.GetContext().NewTexture(w, h, nil, c.filter)
+	var native opengl.Texture
+	var err error
+	if c.hdr && opengl.GetContext().IsFloatTextureAvailable() {
+		native, err = opengl.GetContext().NewFloatTexture(w, h, c.filter)
+	} else {
+		native, err = opengl.GetContext().NewTexture(w, h, nil, c.filter)
+	}
 	if err != nil {
 		return err
 	}
 	c.result.texture = &texture{
 		native: native,
+		filter: c.filter,
 	}
 	return nil
 }
@@ -0,0 +1,161 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphics
+
+import (
+	"fmt"
+	"image"
+
+	emath "github.com/hajimehoshi/ebiten/internal/math"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+	eshader "github.com/hajimehoshi/ebiten/internal/shader"
+)
+
+// ShaderImageNum is the number of the source images of a custom shader.
+const ShaderImageNum = eshader.TextureNum
+
+// Shader represents a custom fragment shader.
+//
+// The OpenGL program is created lazily when the shader is used for drawing,
+// and is re-created after the OpenGL state is reset.
+type Shader struct {
+	program *eshader.Program
+
+	native     opengl.Program
+	generation int
+}
+
+// NewShader creates a new shader from the compiled program.
+func NewShader(program *eshader.Program) *Shader {
+	return &Shader{
+		program: program,
+	}
+}
+
+// Program returns the compiled program of the shader.
+func (s *Shader) Program() *eshader.Program {
+	return s.program
+}
+
+// Dispose disposes the shader.
+func (s *Shader) Dispose() {
+	c := &disposeShaderCommand{
+		target: s,
+	}
+	theCommandQueue.Enqueue(c)
+}
+
+// ensureNative creates the OpenGL program if needed.
+func (s *Shader) ensureNative() error {
+	if s.native != zeroProgram && s.generation == theOpenGLState.generation {
+		return nil
+	}
+	c := opengl.GetContext()
+	vs, err := c.NewShader(opengl.VertexShader, shader(shaderVertexModelview))
+	if err != nil {
+		return fmt.Errorf("graphics: shader compiling error:\n%s", err)
+	}
+	defer c.DeleteShader(vs)
+
+	fs, err := c.NewShader(opengl.FragmentShader, s.program.FragmentShader)
+	if err != nil {
+		return fmt.Errorf("graphics: shader compiling error:\n%s", err)
+	}
+	defer c.DeleteShader(fs)
+
+	p, err := c.NewProgram([]opengl.Shader{vs, fs})
+	if err != nil {
+		return err
+	}
+	s.native = p
+	s.generation = theOpenGLState.generation
+	return nil
+}
+
+func uniformType(t eshader.Type) opengl.UniformType {
+	switch t {
+	case eshader.Float:
+		return opengl.UniformFloat
+	case eshader.Vec2:
+		return opengl.UniformVec2
+	case eshader.Vec3:
+		return opengl.UniformVec3
+	case eshader.Vec4:
+		return opengl.UniformVec4
+	case eshader.Mat2:
+		return opengl.UniformMat2
+	case eshader.Mat3:
+		return opengl.UniformMat3
+	case eshader.Mat4:
+		return opengl.UniformMat4
+	default:
+		panic("not reach")
+	}
+}
+
+// useShaderProgram uses the program of the custom shader.
+//
+// srcs can be nil, and offsets are the positions in pixels of srcs in their textures.
+// uniforms are the values of the shader's uniform variables in the declared order.
+func (s *openGLState) useShaderProgram(proj []float32, srcs [ShaderImageNum]*Image, offsets [ShaderImageNum]image.Point, shader *Shader, uniforms [][]float32) error {
+	if err := shader.ensureNative(); err != nil {
+		return err
+	}
+	c := opengl.GetContext()
+	program := shader.native
+	s.switchProgram(program, eshader.TextureName, proj)
+
+	for i, u := range shader.program.Uniforms {
+		c.UniformVariable(program, u.GLSLName(), uniformType(u.Type), uniforms[i])
+	}
+
+	for i, src := range srcs {
+		size := []float32{0, 0}
+		if src != nil {
+			size[0] = float32(emath.NextPowerOf2Int(src.width))
+			size[1] = float32(emath.NextPowerOf2Int(src.height))
+			if i == 0 {
+				c.BindTexture(src.texture.native)
+			} else {
+				c.UniformInt(program, eshader.TextureNameAt(i), i)
+				c.BindTextureAt(i, src.texture.native)
+			}
+		}
+		c.UniformVariable(program, eshader.TextureSizeNameAt(i), opengl.UniformVec2, size)
+		offset := []float32{float32(offsets[i].X), float32(offsets[i].Y)}
+		c.UniformVariable(program, eshader.TextureOffsetNameAt(i), opengl.UniformVec2, offset)
+	}
+	return nil
+}
+
+// disposeShaderCommand represents a command to dispose a shader.
+type disposeShaderCommand struct {
+	target *Shader
+}
+
+// Exec executes the disposeShaderCommand.
+func (c *disposeShaderCommand) Exec(indexOffsetInBytes int) error {
+	s := c.target
+	if s.native == zeroProgram || s.generation != theOpenGLState.generation {
+		return nil
+	}
+	if theOpenGLState.lastProgram == s.native {
+		theArrayBufferLayout.disable(s.native)
+		theOpenGLState.lastProgram = zeroProgram
+	}
+	opengl.GetContext().DeleteProgram(s.native)
+	s.native = zeroProgram
+	return nil
+}
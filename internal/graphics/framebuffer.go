@@ -15,6 +15,8 @@
 package graphics
 
 import (
+	"image"
+
 	"github.com/hajimehoshi/ebiten/internal/opengl"
 	"github.com/hajimehoshi/ebiten/internal/web"
 )
@@ -118,3 +120,14 @@ func (f *framebuffer) projectionMatrix(height int) []float32 {
 	f.proMatrix = m
 	return f.proMatrix
 }
+
+// scissorRect converts the region r on the image to the region in the framebuffer's pixels for the scissor test.
+func (f *framebuffer) scissorRect(r image.Rectangle, height int) image.Rectangle {
+	if r.Empty() {
+		return image.Rectangle{}
+	}
+	if f.flipY {
+		r = image.Rect(r.Min.X, height-r.Max.Y, r.Max.X, height-r.Min.Y)
+	}
+	return r.Add(image.Pt(int(f.offsetX), int(f.offsetY)))
+}
@@ -28,30 +28,102 @@ type Image struct {
 	framebuffer *framebuffer
 	width       int
 	height      int
+
+	// antialias indicates whether the image is rendered with multisampling.
+	antialias bool
+
+	// msaaFramebuffer is the multisampled framebuffer for an anti-aliased image.
+	// msaaFramebuffer is created lazily at the first rendering.
+	msaaFramebuffer *framebuffer
+
+	// msaaNewer indicates whether msaaFramebuffer has content that is not resolved to the texture yet.
+	msaaNewer bool
+
+	// textureNewer indicates whether the texture has content that is not copied to msaaFramebuffer yet.
+	textureNewer bool
+
+	// hdr indicates whether the texture has floating point channels when available.
+	hdr bool
+
+	// screen indicates whether the image is the screen framebuffer, which has no texture.
+	screen bool
+
+	// compressedSize is the size in bytes of the compressed texture if the image is compressed.
+	compressedSize int
+
+	// id identifies the image in captured commands. id is 0 until the image is captured.
+	id int
 }
 
 // MaxImageSize is the maximum of width/height of an image.
 const MaxImageSize = defaultViewportSize
 
-func NewImage(width, height int, filter opengl.Filter) *Image {
+// NewImage creates an empty image.
+//
+// If antialias is true, the image is rendered with multisampling when available.
+func NewImage(width, height int, filter opengl.Filter, antialias bool) *Image {
 	i := &Image{
+		width:     width,
+		height:    height,
+		antialias: antialias,
+	}
+	c := &newImageCommand{
+		result: i,
 		width:  width,
 		height: height,
+		filter: filter,
+	}
+	theCommandQueue.Enqueue(c)
+	return i
+}
+
+// NewHDRImage creates an empty image with 16-bit floating point channels.
+//
+// If floating point textures are not available, the image has 8-bit channels as a regular image.
+func NewHDRImage(width, height int, filter opengl.Filter) *Image {
+	i := &Image{
+		width:  width,
+		height: height,
+		hdr:    true,
 	}
 	c := &newImageCommand{
 		result: i,
 		width:  width,
 		height: height,
 		filter: filter,
+		hdr:    true,
 	}
 	theCommandQueue.Enqueue(c)
 	return i
 }
 
-func NewImageFromImage(img *image.RGBA, width, height int, filter opengl.Filter) *Image {
+// NewCompressedImage creates an image from the compressed data of the whole texture.
+//
+// The texture size is the power of 2 of width/height, and data must be for the texture size.
+// A compressed image can't be a render target.
+func NewCompressedImage(data []uint8, width, height int, format opengl.CompressedFormat, filter opengl.Filter) *Image {
 	i := &Image{
+		width:          width,
+		height:         height,
+		compressedSize: len(data),
+	}
+	c := &newCompressedImageCommand{
+		result: i,
+		data:   data,
 		width:  width,
 		height: height,
+		format: format,
+		filter: filter,
+	}
+	theCommandQueue.Enqueue(c)
+	return i
+}
+
+func NewImageFromImage(img *image.RGBA, width, height int, filter opengl.Filter, antialias bool) *Image {
+	i := &Image{
+		width:     width,
+		height:    height,
+		antialias: antialias,
 	}
 	c := &newImageFromImageCommand{
 		result: i,
@@ -66,6 +138,7 @@ func NewScreenFramebufferImage(width, height int, offsetX, offsetY float64) *Ima
 	i := &Image{
 		width:  width,
 		height: height,
+		screen: true,
 	}
 	c := &newScreenFramebufferImageCommand{
 		result:  i,
@@ -89,6 +162,29 @@ func (i *Image) Size() (int, int) {
 	return i.width, i.height
 }
 
+// SizeInBytes returns the estimated size in bytes of the video memory the image uses.
+//
+// The size includes the texture and the multisampled framebuffer, but doesn't include mipmaps.
+// The screen framebuffer is not counted.
+func (i *Image) SizeInBytes() int {
+	if i.screen {
+		return 0
+	}
+	if i.compressedSize > 0 {
+		return i.compressedSize
+	}
+	w, h := math.NextPowerOf2Int(i.width), math.NextPowerOf2Int(i.height)
+	bpp := 4
+	if i.hdr {
+		bpp = 8
+	}
+	n := bpp * w * h
+	if i.antialias {
+		n += msaaSamples * 4 * w * h
+	}
+	return n
+}
+
 func (i *Image) Fill(r, g, b, a uint8) {
 	c := &fillCommand{
 		dst: i,
@@ -100,8 +196,35 @@ func (i *Image) Fill(r, g, b, a uint8) {
 	theCommandQueue.Enqueue(c)
 }
 
-func (i *Image) DrawImage(src *Image, vertices []float32, clr *affine.ColorM, mode opengl.CompositeMode) {
-	theCommandQueue.EnqueueDrawImageCommand(i, src, vertices, clr, mode)
+// DrawImage draws src on the image.
+//
+// src is sampled with address in srcRegion. srcRegion is ignored when address is AddressDefault.
+// Rendering is restricted to dstRegion. If dstRegion is empty, the whole image can be rendered.
+func (i *Image) DrawImage(src *Image, vertices []float32, indices []uint16, clr *affine.ColorM, blend opengl.Blend, filter opengl.Filter, address opengl.Address, srcRegion, dstRegion image.Rectangle) {
+	theCommandQueue.EnqueueDrawImageCommand(i, src, vertices, indices, clr, blend, filter, address, srcRegion, dstRegion)
+}
+
+// DrawPalettedImage draws src on the image looking up the colors of src in palette.
+//
+// The red channel of src is regarded as an index of palette, whose width must be PaletteSize.
+func (i *Image) DrawPalettedImage(src, palette *Image, vertices []float32, indices []uint16, clr *affine.ColorM, blend opengl.Blend, address opengl.Address, srcRegion, dstRegion image.Rectangle) {
+	theCommandQueue.EnqueueDrawPalettedImageCommand(i, src, palette, vertices, indices, clr, blend, address, srcRegion, dstRegion)
+}
+
+// DrawMaskedImage draws src on the image multiplying the result by the alpha of mask.
+//
+// The mask's texel at (x, y) + maskOffset masks the destination pixel at (x, y),
+// and the destination pixels outside of maskRegion are fully masked.
+// palette can be nil. If palette is not nil, the colors of src are looked up in palette as DrawPalettedImage does.
+func (i *Image) DrawMaskedImage(src, palette, mask *Image, maskOffset image.Point, maskRegion image.Rectangle, vertices []float32, indices []uint16, clr *affine.ColorM, blend opengl.Blend, filter opengl.Filter, address opengl.Address, srcRegion, dstRegion image.Rectangle) {
+	theCommandQueue.EnqueueDrawMaskedImageCommand(i, src, palette, mask, maskOffset, maskRegion, vertices, indices, clr, blend, filter, address, srcRegion, dstRegion)
+}
+
+// DrawShader draws the source images to the image with the custom shader.
+//
+// srcs can be nil. offsets are the positions in pixels of srcs in their textures.
+func (i *Image) DrawShader(srcs [ShaderImageNum]*Image, offsets [ShaderImageNum]image.Point, vertices []float32, indices []uint16, shader *Shader, uniforms [][]float32, blend opengl.Blend, filter opengl.Filter, dstRegion image.Rectangle) {
+	theCommandQueue.EnqueueDrawShaderCommand(i, srcs, offsets, vertices, indices, shader, uniforms, blend, filter, dstRegion)
 }
 
 func (i *Image) Pixels() ([]uint8, error) {
@@ -109,6 +232,9 @@ func (i *Image) Pixels() ([]uint8, error) {
 	if err := theCommandQueue.Flush(); err != nil {
 		return nil, err
 	}
+	if err := i.resolveMSAA(); err != nil {
+		return nil, err
+	}
 	f, err := i.createFramebufferIfNeeded()
 	if err != nil {
 		return nil, err
@@ -122,6 +248,25 @@ func (i *Image) ReplacePixels(p []uint8) {
 	c := &replacePixelsCommand{
 		dst:    i,
 		pixels: pixels,
+		whole:  true,
+		width:  math.NextPowerOf2Int(i.width),
+		height: math.NextPowerOf2Int(i.height),
+	}
+	theCommandQueue.Enqueue(c)
+}
+
+// ReplacePixelsRegion replaces the pixels in the region (x, y, width, height) of the image.
+// len(p) must be 4 * width * height.
+func (i *Image) ReplacePixelsRegion(p []uint8, x, y, width, height int) {
+	pixels := make([]uint8, len(p))
+	copy(pixels, p)
+	c := &replacePixelsCommand{
+		dst:    i,
+		pixels: pixels,
+		x:      x,
+		y:      y,
+		width:  width,
+		height: height,
 	}
 	theCommandQueue.Enqueue(c)
 }
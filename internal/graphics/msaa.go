@@ -0,0 +1,90 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphics
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/math"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+)
+
+// msaaSamples is the preferred number of samples for an anti-aliased image.
+const msaaSamples = 4
+
+// usesMSAA reports whether the image is rendered via a multisampled framebuffer.
+func (i *Image) usesMSAA() bool {
+	return i.antialias && opengl.GetContext().MaxSamples() > 0
+}
+
+// renderFramebuffer returns the framebuffer to render the image to.
+//
+// For an anti-aliased image, this is a multisampled framebuffer whose content is
+// resolved to the texture by resolveMSAA before the texture is used.
+func (i *Image) renderFramebuffer() (*framebuffer, error) {
+	if !i.usesMSAA() {
+		return i.createFramebufferIfNeeded()
+	}
+	w, h := math.NextPowerOf2Int(i.width), math.NextPowerOf2Int(i.height)
+	if i.msaaFramebuffer == nil {
+		samples := msaaSamples
+		if max := opengl.GetContext().MaxSamples(); samples > max {
+			samples = max
+		}
+		native, err := opengl.GetContext().NewMultisampleFramebuffer(w, h, samples)
+		if err != nil {
+			return nil, err
+		}
+		i.msaaFramebuffer = &framebuffer{
+			native: native,
+			width:  w,
+			height: h,
+		}
+		// The content of a new renderbuffer is undefined.
+		i.textureNewer = true
+	}
+	if i.textureNewer {
+		f, err := i.createFramebufferIfNeeded()
+		if err != nil {
+			return nil, err
+		}
+		opengl.GetContext().BlitFramebuffer(f.native, i.msaaFramebuffer.native, w, h)
+		i.textureNewer = false
+	}
+	i.msaaNewer = true
+	return i.msaaFramebuffer, nil
+}
+
+// resolveMSAA copies the content of the multisampled framebuffer to the texture if needed.
+//
+// resolveMSAA must be called before the texture is read.
+func (i *Image) resolveMSAA() error {
+	if !i.msaaNewer {
+		return nil
+	}
+	f, err := i.createFramebufferIfNeeded()
+	if err != nil {
+		return err
+	}
+	w, h := math.NextPowerOf2Int(i.width), math.NextPowerOf2Int(i.height)
+	opengl.GetContext().BlitFramebuffer(i.msaaFramebuffer.native, f.native, w, h)
+	i.texture.invalidateMipmap()
+	i.msaaNewer = false
+	return nil
+}
+
+// markTextureUpdated records that the texture is updated directly, not via the multisampled framebuffer.
+func (i *Image) markTextureUpdated() {
+	i.msaaNewer = false
+	i.textureNewer = i.msaaFramebuffer != nil
+}
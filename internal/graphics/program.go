@@ -16,8 +16,10 @@ package graphics
 
 import (
 	"fmt"
+	"image"
 
 	"github.com/hajimehoshi/ebiten/internal/affine"
+	emath "github.com/hajimehoshi/ebiten/internal/math"
 	"github.com/hajimehoshi/ebiten/internal/opengl"
 )
 
@@ -54,7 +56,7 @@ func (a *arrayBufferLayout) totalBytes() int {
 
 // newArrayBuffer creates OpenGL's buffer object for the array buffer.
 func (a *arrayBufferLayout) newArrayBuffer() opengl.Buffer {
-	return opengl.GetContext().NewArrayBuffer(a.totalBytes() * 4 * maxQuads)
+	return opengl.GetContext().NewArrayBuffer(a.totalBytes() * MaxVerticesNum)
 }
 
 // enable binds the array buffer the given program to use the array buffer.
@@ -96,21 +98,40 @@ var (
 				normalize: false,
 			},
 			{
-				name:      "geo_matrix_body",
+				name:      "color_scale",
 				dataType:  opengl.Float,
 				num:       4,
 				normalize: false,
 			},
-			{
-				name:      "geo_matrix_translation",
-				dataType:  opengl.Float,
-				num:       2,
-				normalize: false,
-			},
 		},
 	}
 )
 
+// programKey represents a variant of the program for rendering a texture.
+type programKey struct {
+	address opengl.Address
+
+	// filter is the filter that the shader emulates.
+	// filter is opengl.Nearest when the filter is not emulated.
+	filter opengl.Filter
+
+	// paletted indicates whether the colors of the texture are looked up in a palette.
+	paletted bool
+
+	// masked indicates whether the result is multiplied by the alpha of a mask texture.
+	masked bool
+}
+
+// newProgramKey returns the key of the program to render a texture with the given address mode and filter.
+//
+// Linear filtering is never emulated for paletted textures since indices must not be interpolated.
+func newProgramKey(address opengl.Address, filter opengl.Filter, paletted, masked bool) programKey {
+	if address == opengl.AddressDefault || filter != opengl.Linear || paletted {
+		return programKey{address, opengl.Nearest, paletted, masked}
+	}
+	return programKey{address, filter, paletted, masked}
+}
+
 // openGLState is a state for OpenGL.
 type openGLState struct {
 	// arrayBuffer is OpenGL's array buffer (vertices data).
@@ -119,13 +140,17 @@ type openGLState struct {
 	// elementArrayBuffer is OpenGL's element array buffer (indices data).
 	elementArrayBuffer opengl.Buffer
 
-	// programTexture is OpenGL's program for rendering a texture.
-	programTexture opengl.Program
+	// programs is OpenGL's programs for rendering a texture.
+	programs map[programKey]opengl.Program
 
 	lastProgram                opengl.Program
 	lastProjectionMatrix       []float32
 	lastColorMatrix            []float32
 	lastColorMatrixTranslation []float32
+
+	// generation is incremented whenever the state is reset.
+	// Programs of custom shaders created at older generations are invalid.
+	generation int
 }
 
 var (
@@ -137,8 +162,12 @@ var (
 )
 
 const (
-	indicesNum = 1 << 16
-	maxQuads   = indicesNum / 6
+	// MaxVerticesNum is the maximum number of vertices in one draw call.
+	// Indices are unsigned shorts and can't refer more vertices.
+	MaxVerticesNum = 1 << 16
+
+	// MaxIndicesNum is the maximum number of indices in one draw call.
+	MaxIndicesNum = 3 * MaxVerticesNum
 )
 
 // ResetGLState resets or initializes the current OpenGL state.
@@ -151,6 +180,7 @@ func (s *openGLState) reset() error {
 	if err := opengl.GetContext().Reset(); err != nil {
 		return err
 	}
+	s.generation++
 	s.lastProgram = zeroProgram
 	s.lastProjectionMatrix = nil
 	s.lastColorMatrix = nil
@@ -159,9 +189,10 @@ func (s *openGLState) reset() error {
 	// When context lost happens, deleting programs or buffers is not necessary.
 	// However, it is not assumed that reset is called only when context lost happens.
 	// Let's delete them explicitly.
-	if s.programTexture != zeroProgram {
-		opengl.GetContext().DeleteProgram(s.programTexture)
+	for _, p := range s.programs {
+		opengl.GetContext().DeleteProgram(p)
 	}
+	s.programs = map[programKey]opengl.Program{}
 	if s.arrayBuffer != zeroBuffer {
 		opengl.GetContext().DeleteBuffer(s.arrayBuffer)
 	}
@@ -175,32 +206,35 @@ func (s *openGLState) reset() error {
 	}
 	defer opengl.GetContext().DeleteShader(shaderVertexModelviewNative)
 
-	shaderFragmentTextureNative, err := opengl.GetContext().NewShader(opengl.FragmentShader, shader(shaderFragmentTexture))
-	if err != nil {
-		panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
-	}
-	defer opengl.GetContext().DeleteShader(shaderFragmentTextureNative)
-
-	s.programTexture, err = opengl.GetContext().NewProgram([]opengl.Shader{
-		shaderVertexModelviewNative,
-		shaderFragmentTextureNative,
-	})
-	if err != nil {
-		return err
+	for _, address := range []opengl.Address{opengl.AddressDefault, opengl.AddressRepeat, opengl.AddressMirroredRepeat} {
+		for _, filter := range []opengl.Filter{opengl.Nearest, opengl.Linear} {
+			for _, paletted := range []bool{false, true} {
+				for _, masked := range []bool{false, true} {
+					key := newProgramKey(address, filter, paletted, masked)
+					if _, ok := s.programs[key]; ok {
+						continue
+					}
+					shaderFragmentTextureNative, err := opengl.GetContext().NewShader(opengl.FragmentShader, textureFragmentShader(key.address, key.filter, key.paletted, key.masked))
+					if err != nil {
+						panic(fmt.Sprintf("graphics: shader compiling error:\n%s", err))
+					}
+					p, err := opengl.GetContext().NewProgram([]opengl.Shader{
+						shaderVertexModelviewNative,
+						shaderFragmentTextureNative,
+					})
+					opengl.GetContext().DeleteShader(shaderFragmentTextureNative)
+					if err != nil {
+						return err
+					}
+					s.programs[key] = p
+				}
+			}
+		}
 	}
 
 	s.arrayBuffer = theArrayBufferLayout.newArrayBuffer()
 
-	indices := make([]uint16, 6*maxQuads)
-	for i := uint16(0); i < maxQuads; i++ {
-		indices[6*i+0] = 4*i + 0
-		indices[6*i+1] = 4*i + 1
-		indices[6*i+2] = 4*i + 2
-		indices[6*i+3] = 4*i + 1
-		indices[6*i+4] = 4*i + 2
-		indices[6*i+5] = 4*i + 3
-	}
-	s.elementArrayBuffer = opengl.GetContext().NewElementArrayBuffer(indices)
+	s.elementArrayBuffer = opengl.GetContext().NewElementArrayBuffer(2 * MaxIndicesNum)
 
 	return nil
 }
@@ -218,11 +252,10 @@ func areSameFloat32Array(a, b []float32) bool {
 	return true
 }
 
-// useProgram uses the program (programTexture).
-func (s *openGLState) useProgram(proj []float32, texture opengl.Texture, colorM affine.ColorM) {
+// switchProgram makes the program current if needed.
+// textureName is the name of the sampler uniform variable of the program.
+func (s *openGLState) switchProgram(program opengl.Program, textureName string, proj []float32) {
 	c := opengl.GetContext()
-	program := s.programTexture
-
 	if s.lastProgram != program {
 		c.UseProgram(program)
 		if s.lastProgram != zeroProgram {
@@ -230,12 +263,12 @@ func (s *openGLState) useProgram(proj []float32, texture opengl.Texture, colorM
 		}
 		theArrayBufferLayout.enable(program)
 
-		s.lastProgram = s.programTexture
+		s.lastProgram = program
 		s.lastProjectionMatrix = nil
 		s.lastColorMatrix = nil
 		s.lastColorMatrixTranslation = nil
 		c.BindElementArrayBuffer(s.elementArrayBuffer)
-		c.UniformInt(program, "texture", 0)
+		c.UniformInt(program, textureName, 0)
 	}
 
 	if !areSameFloat32Array(s.lastProjectionMatrix, proj) {
@@ -245,6 +278,19 @@ func (s *openGLState) useProgram(proj []float32, texture opengl.Texture, colorM
 		}
 		copy(s.lastProjectionMatrix, proj)
 	}
+}
+
+// useProgram uses the program to render the texture with the given address mode and filter.
+//
+// sourceRegion is the region in pixels of the texture to repeat, and width and height are the size of the texture.
+// sourceRegion is ignored when address is AddressDefault.
+// If palette is not nil, the colors of the texture are looked up in the palette texture.
+// If mask.image is not nil, the result is multiplied by the alpha of the mask.
+func (s *openGLState) useProgram(proj []float32, texture opengl.Texture, width, height int, colorM affine.ColorM, address opengl.Address, filter opengl.Filter, sourceRegion image.Rectangle, palette *opengl.Texture, mask maskSource) {
+	c := opengl.GetContext()
+	key := newProgramKey(address, filter, palette != nil, mask.image != nil)
+	program := s.programs[key]
+	s.switchProgram(program, "texture", proj)
 
 	e := [4][5]float32{}
 	es := colorM.UnsafeElements()
@@ -278,6 +324,34 @@ func (s *openGLState) useProgram(proj []float32, texture opengl.Texture, colorM
 		copy(s.lastColorMatrixTranslation, colorMatrixTranslation)
 	}
 
+	if address != opengl.AddressDefault {
+		w, h := float32(width), float32(height)
+		r := sourceRegion
+		c.UniformFloats(program, "source_region", []float32{
+			float32(r.Min.X) / w, float32(r.Min.Y) / h, float32(r.Max.X) / w, float32(r.Max.Y) / h,
+		})
+		if key.filter == opengl.Linear {
+			c.UniformVariable(program, "texture_size", opengl.UniformVec2, []float32{w, h})
+		}
+	}
+
+	if palette != nil {
+		c.UniformInt(program, "palette", 1)
+		c.BindTextureAt(1, *palette)
+	}
+
+	if m := mask.image; m != nil {
+		r := mask.region
+		c.UniformFloats(program, "mask_region", []float32{
+			float32(r.Min.X), float32(r.Min.Y), float32(r.Max.X), float32(r.Max.Y),
+		})
+		c.UniformVariable(program, "mask_offset", opengl.UniformVec2, []float32{float32(mask.offset.X), float32(mask.offset.Y)})
+		mw, mh := emath.NextPowerOf2Int(m.width), emath.NextPowerOf2Int(m.height)
+		c.UniformVariable(program, "mask_texture_size", opengl.UniformVec2, []float32{float32(mw), float32(mh)})
+		c.UniformInt(program, "mask_texture", 2)
+		c.BindTextureAt(2, m.texture.native)
+	}
+
 	// We don't have to call gl.ActiveTexture here: GL_TEXTURE0 is the default active texture
 	// See also: https://www.opengl.org/sdk/docs/man2/xhtml/glActiveTexture.xml
 	c.BindTexture(texture)
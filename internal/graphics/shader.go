@@ -14,6 +14,10 @@
 
 package graphics
 
+import (
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+)
+
 type shaderId int
 
 const (
@@ -25,24 +29,55 @@ func shader(id shaderId) string {
 	return shaders[id]
 }
 
+// PaletteSize is the number of the colors in a palette.
+// A palette is an image whose size is (PaletteSize, 1).
+const PaletteSize = 256
+
+// textureFragmentShader returns the source of the fragment shader to render a texture
+// with the given address mode and filter.
+//
+// When the address mode is not AddressDefault, linear filtering is done in the shader
+// so that the neighbor texels are also taken from the source region.
+//
+// When paletted is true, the red channel of the texture is an index of the palette texture.
+//
+// When masked is true, the result is multiplied by the alpha of the mask texture at the destination position.
+func textureFragmentShader(address opengl.Address, filter opengl.Filter, paletted, masked bool) string {
+	defs := ""
+	switch address {
+	case opengl.AddressRepeat:
+		defs += "#define ADDRESS_REPEAT\n"
+	case opengl.AddressMirroredRepeat:
+		defs += "#define ADDRESS_MIRRORED_REPEAT\n"
+	}
+	if address != opengl.AddressDefault && filter == opengl.Linear {
+		defs += "#define FILTER_LINEAR\n"
+	}
+	if paletted {
+		defs += "#define PALETTE\n"
+	}
+	if masked {
+		defs += "#define MASK\n"
+	}
+	return defs + shader(shaderFragmentTexture)
+}
+
 var shaders = map[shaderId]string{
 	shaderVertexModelview: `
 uniform mat4 projection_matrix;
 attribute vec2 vertex;
 attribute vec2 tex_coord;
-attribute vec4 geo_matrix_body;
-attribute vec2 geo_matrix_translation;
+attribute vec4 color_scale;
 varying vec2 vertex_out_tex_coord;
+varying vec4 vertex_out_color_scale;
+// vertex_out_position is used by custom shaders.
+varying vec2 vertex_out_position;
 
 void main(void) {
   vertex_out_tex_coord = tex_coord;
-  mat4 geo_matrix = mat4(
-    vec4(geo_matrix_body[0], geo_matrix_body[2], 0, 0),
-    vec4(geo_matrix_body[1], geo_matrix_body[3], 0, 0),
-    vec4(0, 0, 1, 0),
-    vec4(geo_matrix_translation, 0, 1)
-  );
-  gl_Position = projection_matrix * geo_matrix * vec4(vertex, 0, 1);
+  vertex_out_color_scale = color_scale;
+  vertex_out_position = vertex;
+  gl_Position = projection_matrix * vec4(vertex, 0, 1);
 }
 `,
 	shaderFragmentTexture: `
@@ -58,9 +93,71 @@ uniform sampler2D texture;
 uniform mat4 color_matrix;
 uniform vec4 color_matrix_translation;
 varying vec2 vertex_out_tex_coord;
+varying vec4 vertex_out_color_scale;
+
+#if defined(PALETTE)
+// palette is a texture of 256x1 pixels.
+uniform sampler2D palette;
+#endif
+
+#if defined(MASK)
+uniform sampler2D mask_texture;
+uniform vec2 mask_texture_size;
+// mask_offset is the offset in pixels from the destination positions to the mask texels.
+uniform vec2 mask_offset;
+// mask_region is the region (x0, y0, x1, y1) in pixels of the destination that the mask covers.
+uniform vec4 mask_region;
+varying vec2 vertex_out_position;
+#endif
+
+#if defined(ADDRESS_REPEAT) || defined(ADDRESS_MIRRORED_REPEAT)
+
+// source_region is the region (x0, y0, x1, y1) of the texture to repeat in texture coordinates.
+uniform vec4 source_region;
+uniform vec2 texture_size;
+
+vec2 adjustTexel(vec2 p) {
+  vec2 o = source_region.xy;
+  vec2 s = source_region.zw - o;
+#if defined(ADDRESS_REPEAT)
+  return o + mod(p - o, s);
+#else
+  vec2 t = mod(p - o, 2.0 * s);
+  return o + s - abs(t - s);
+#endif
+}
+
+vec4 sampleTexture(vec2 p) {
+#if defined(FILTER_LINEAR)
+  vec2 p0 = p - 0.5 / texture_size;
+  vec2 p1 = p + 0.5 / texture_size;
+  vec2 rate = fract(p0 * texture_size);
+  vec4 c0 = texture2D(texture, adjustTexel(p0));
+  vec4 c1 = texture2D(texture, adjustTexel(vec2(p1.x, p0.y)));
+  vec4 c2 = texture2D(texture, adjustTexel(vec2(p0.x, p1.y)));
+  vec4 c3 = texture2D(texture, adjustTexel(p1));
+  return mix(mix(c0, c1, rate.x), mix(c2, c3, rate.x), rate.y);
+#else
+  return texture2D(texture, adjustTexel(p));
+#endif
+}
+
+#else
+
+vec4 sampleTexture(vec2 p) {
+  return texture2D(texture, p);
+}
+
+#endif
 
 void main(void) {
-  vec4 color = texture2D(texture, vertex_out_tex_coord);
+  vec4 color = sampleTexture(vertex_out_tex_coord);
+
+#if defined(PALETTE)
+  // The red channel is an index. Indexed pixels are opaque and so not premultiplied.
+  float index = floor(color.r * 255.0 + 0.5);
+  color = texture2D(palette, vec2((index + 0.5) / 256.0, 0.5));
+#endif
 
   // Un-premultiply alpha
   if (0.0 < color.a) {
@@ -69,9 +166,20 @@ void main(void) {
   // Apply the color matrix
   color = (color_matrix * color) + color_matrix_translation;
   color = clamp(color, 0.0, 1.0);
+  // Apply the vertex color
+  color *= vertex_out_color_scale;
   // Premultiply alpha
   color.rgb *= color.a;
 
+#if defined(MASK)
+  vec2 p = vertex_out_position;
+  if (p.x < mask_region.x || mask_region.z <= p.x || p.y < mask_region.y || mask_region.w <= p.y) {
+    color = vec4(0.0);
+  } else {
+    color *= texture2D(mask_texture, (p + mask_offset) / mask_texture_size).a;
+  }
+#endif
+
   gl_FragColor = color;
 }
 `,
@@ -21,4 +21,32 @@ import (
 // texture represents OpenGL's texture.
 type texture struct {
 	native opengl.Texture
+
+	// filter is the current filter of the texture.
+	filter opengl.Filter
+
+	// mipmapValid indicates whether the mipmaps are generated from the current content.
+	mipmapValid bool
+}
+
+// invalidateMipmap marks the mipmaps of the texture as outdated.
+// This must be called whenever the content of the texture is changed.
+func (t *texture) invalidateMipmap() {
+	if t == nil {
+		// The screen framebuffer doesn't have a texture.
+		return
+	}
+	t.mipmapValid = false
+}
+
+// prepareToSample sets the filter of the texture, and generates the mipmaps if the filter needs them.
+func (t *texture) prepareToSample(filter opengl.Filter) {
+	if filter.UsesMipmap() && !t.mipmapValid {
+		opengl.GetContext().GenerateMipmap(t.native)
+		t.mipmapValid = true
+	}
+	if t.filter != filter {
+		opengl.GetContext().SetTextureFilter(t.native, filter)
+		t.filter = filter
+	}
 }
@@ -0,0 +1,58 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hooks manages the functions called by Ebiten at specific timings.
+package hooks
+
+import (
+	"sync"
+)
+
+var (
+	onInputUpdateHooks  = []func() error{}
+	onBeforeUpdateHooks = []func() error{}
+	m                   sync.Mutex
+)
+
+// AppendHookOnInputUpdate appends a hook function that updates the input state at every tick.
+//
+// The hook functions appended by AppendHookOnInputUpdate are called before the ones appended by
+// AppendHookOnBeforeUpdate, so that all the hooks on before-update see the same input state.
+func AppendHookOnInputUpdate(f func() error) {
+	m.Lock()
+	onInputUpdateHooks = append(onInputUpdateHooks, f)
+	m.Unlock()
+}
+
+// AppendHookOnBeforeUpdate appends a hook function that is called before the game's update function
+// at every tick.
+func AppendHookOnBeforeUpdate(f func() error) {
+	m.Lock()
+	onBeforeUpdateHooks = append(onBeforeUpdateHooks, f)
+	m.Unlock()
+}
+
+// RunBeforeUpdateHooks calls the hook functions on input-update and then the ones on before-update.
+// The hook functions are called in the order they are appended.
+func RunBeforeUpdateHooks() error {
+	m.Lock()
+	hs := append(append([]func() error{}, onInputUpdateHooks...), onBeforeUpdateHooks...)
+	m.Unlock()
+	for _, h := range hs {
+		if err := h(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
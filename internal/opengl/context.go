@@ -14,9 +14,15 @@
 
 package opengl
 
+import (
+	"image"
+	"math"
+)
+
 var (
 	Nearest            Filter
 	Linear             Filter
+	LinearMipmap       Filter
 	VertexShader       ShaderType
 	FragmentShader     ShaderType
 	ArrayBuffer        BufferType
@@ -30,12 +36,34 @@ var (
 
 	zero             operation
 	one              operation
+	srcColor         operation
+	dstColor         operation
 	srcAlpha         operation
 	dstAlpha         operation
+	oneMinusSrcColor operation
+	oneMinusDstColor operation
 	oneMinusSrcAlpha operation
 	oneMinusDstAlpha operation
+
+	funcAdd             equation
+	funcSubtract        equation
+	funcReverseSubtract equation
 )
 
+// DriverInfo represents the information of the GL driver.
+type DriverInfo struct {
+	// API is the name of the GL API: "OpenGL", "OpenGL ES" or "WebGL".
+	API string
+
+	Vendor                 string
+	Renderer               string
+	Version                string
+	ShadingLanguageVersion string
+
+	// MaxTextureSize is the maximum width/height of a texture.
+	MaxTextureSize int
+}
+
 type Context struct {
 	locationCache      *locationCache
 	screenFramebuffer  Framebuffer // This might not be the default frame buffer '0' (e.g. iOS).
@@ -43,16 +71,82 @@ type Context struct {
 	lastTexture        Texture
 	lastViewportWidth  int
 	lastViewportHeight int
-	lastCompositeMode  CompositeMode
+	lastBlend          Blend
+	lastBlendValid     bool
+	lastScissor        image.Rectangle
+	lastScissorValid   bool
+	driverInfo         DriverInfo
+	compressedFormats  map[CompressedFormat]bool
+	maxAnisotropy      float32
 	context
 }
 
+// Anisotropic is the linear filter with mipmaps that are sampled anisotropically when available.
+//
+// Anisotropic has no GL enum of its own: Anisotropic is LinearMipmap with the maximum anisotropy.
+var Anisotropic = Filter(-1)
+
+// The enums of EXT_texture_filter_anisotropic.
+// The values are the same among OpenGL, OpenGL ES and WebGL.
+const (
+	textureMaxAnisotropy    = 0x84FE
+	maxTextureMaxAnisotropy = 0x84FF
+)
+
 var theContext *Context
 
+// srgbRequested indicates whether the sRGB color space is requested. See RequestSRGB.
+var srgbRequested bool
+
+// RequestSRGB requests that 8-bit textures are stored in the sRGB color space,
+// i.e., colors are decoded into the linear color space when sampled, blended in the linear color space,
+// and encoded into the sRGB color space when written.
+//
+// RequestSRGB must be called before the context is initialized.
+// Whether the request is satisfied can be checked with IsSRGBEnabled.
+func RequestSRGB(enabled bool) {
+	srgbRequested = enabled
+}
+
+// IsSRGBRequested reports whether the sRGB color space is requested.
+func IsSRGBRequested() bool {
+	return srgbRequested
+}
+
+// srgbToLinear converts an sRGB color component in [0, 1] into the linear color space.
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
 func GetContext() *Context {
 	return theContext
 }
 
+// IsCompressedFormatAvailable reports whether textures of the compressed format f are available.
+//
+// IsCompressedFormatAvailable is available after Reset is called.
+func (c *Context) IsCompressedFormatAvailable(f CompressedFormat) bool {
+	return c.compressedFormats[f]
+}
+
+// IsAnisotropicFilterAvailable reports whether anisotropic filtering is available.
+// If not, Anisotropic works in the same way as LinearMipmap.
+//
+// IsAnisotropicFilterAvailable is available after Reset is called.
+func (c *Context) IsAnisotropicFilterAvailable() bool {
+	return c.maxAnisotropy > 1
+}
+
+// DriverInfo returns the information of the GL driver.
+//
+// DriverInfo is available after Reset is called.
+func (c *Context) DriverInfo() DriverInfo {
+	return c.driverInfo
+}
+
 func (c *Context) BindTexture(t Texture) {
 	if c.lastTexture.equals(t) {
 		return
@@ -61,6 +155,75 @@ func (c *Context) BindTexture(t Texture) {
 	c.lastTexture = t
 }
 
+// BindTextureAt binds the texture t to the texture unit unit.
+//
+// The active texture unit is always 0 except in BindTextureAt.
+func (c *Context) BindTextureAt(unit int, t Texture) {
+	if unit == 0 {
+		c.BindTexture(t)
+		return
+	}
+	c.activeTextureImpl(unit)
+	c.bindTextureImpl(t)
+	c.activeTextureImpl(0)
+}
+
+// UsesMipmap reports whether the filter uses mipmaps.
+// A texture must have its mipmaps generated by GenerateMipmap before such a filter is used.
+func (f Filter) UsesMipmap() bool {
+	return f == LinearMipmap || f == Anisotropic
+}
+
+// minFilter returns the GL filter for minification.
+func (f Filter) minFilter() Filter {
+	if f == Anisotropic {
+		return LinearMipmap
+	}
+	return f
+}
+
+// magFilter returns the GL filter for magnification.
+// Mipmaps are not used for magnification.
+func (f Filter) magFilter() Filter {
+	if f == LinearMipmap || f == Anisotropic {
+		return Linear
+	}
+	return f
+}
+
+// anisotropy returns the anisotropy to sample with the filter.
+func (f Filter) anisotropy(max float32) float32 {
+	if f == Anisotropic {
+		return max
+	}
+	return 1
+}
+
+// SetBlend sets the blending function.
+func (c *Context) SetBlend(b Blend) {
+	if c.lastBlendValid && c.lastBlend == b {
+		return
+	}
+	c.lastBlend = b
+	c.lastBlendValid = true
+	c.setBlendImpl(b)
+}
+
+// SetScissor restricts rendering to the region r in the current framebuffer's pixels,
+// where (0, 0) is the bottom-left corner.
+// An empty region disables the restriction.
+func (c *Context) SetScissor(r image.Rectangle) {
+	if r.Empty() {
+		r = image.Rectangle{}
+	}
+	if c.lastScissorValid && c.lastScissor == r {
+		return
+	}
+	c.lastScissor = r
+	c.lastScissorValid = true
+	c.setScissorImpl(r)
+}
+
 func (c *Context) bindFramebuffer(f Framebuffer) {
 	if c.lastFramebuffer.equals(f) {
 		return
@@ -16,12 +16,15 @@
 // +build !js
 // +build !android
 // +build !ios
+// +build !gles
 
 package opengl
 
 import (
 	"errors"
 	"fmt"
+	"image"
+	"strings"
 
 	"github.com/go-gl/gl/v2.1/gl"
 )
@@ -57,6 +60,7 @@ func (p Program) id() programID {
 func init() {
 	Nearest = gl.NEAREST
 	Linear = gl.LINEAR
+	LinearMipmap = gl.LINEAR_MIPMAP_LINEAR
 	VertexShader = gl.VERTEX_SHADER
 	FragmentShader = gl.FRAGMENT_SHADER
 	ArrayBuffer = gl.ARRAY_BUFFER
@@ -70,15 +74,29 @@ func init() {
 
 	zero = gl.ZERO
 	one = gl.ONE
+	srcColor = gl.SRC_COLOR
+	dstColor = gl.DST_COLOR
 	srcAlpha = gl.SRC_ALPHA
 	dstAlpha = gl.DST_ALPHA
+	oneMinusSrcColor = gl.ONE_MINUS_SRC_COLOR
+	oneMinusDstColor = gl.ONE_MINUS_DST_COLOR
 	oneMinusSrcAlpha = gl.ONE_MINUS_SRC_ALPHA
 	oneMinusDstAlpha = gl.ONE_MINUS_DST_ALPHA
+
+	funcAdd = gl.FUNC_ADD
+	funcSubtract = gl.FUNC_SUBTRACT
+	funcReverseSubtract = gl.FUNC_REVERSE_SUBTRACT
 }
 
 type context struct {
 	init            bool
 	runOnMainThread func(func() error) error
+	maxSamples      int
+	floatTexture    bool
+	srgb            bool
+
+	// renderbuffers is a map from multisampled framebuffers to their renderbuffers.
+	renderbuffers map[Framebuffer]uint32
 }
 
 func Init(runOnMainThread func(func() error) error) {
@@ -110,34 +128,141 @@ func (c *Context) Reset() error {
 	c.lastFramebuffer = invalidFramebuffer
 	c.lastViewportWidth = 0
 	c.lastViewportHeight = 0
-	c.lastCompositeMode = CompositeModeUnknown
+	c.lastBlendValid = false
+	c.lastScissorValid = false
 	_ = c.runOnContextThread(func() error {
 		gl.Enable(gl.BLEND)
 		return nil
 	})
-	c.BlendFunc(CompositeModeSourceOver)
+	c.SetBlend(CompositeModeSourceOver.Blend())
 	_ = c.runOnContextThread(func() error {
 		f := int32(0)
 		gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &f)
 		c.screenFramebuffer = Framebuffer(f)
+
+		// GL_MAX_SAMPLES is unknown and causes an error when multisampling is not supported.
+		// Then s remains 0.
+		s := int32(0)
+		gl.GetIntegerv(gl.MAX_SAMPLES, &s)
+		gl.GetError()
+		c.maxSamples = int(s)
+
+		m := int32(0)
+		gl.GetIntegerv(gl.MAX_TEXTURE_SIZE, &m)
+		c.driverInfo = DriverInfo{
+			API:                    "OpenGL",
+			Vendor:                 gl.GoStr(gl.GetString(gl.VENDOR)),
+			Renderer:               gl.GoStr(gl.GetString(gl.RENDERER)),
+			Version:                gl.GoStr(gl.GetString(gl.VERSION)),
+			ShadingLanguageVersion: gl.GoStr(gl.GetString(gl.SHADING_LANGUAGE_VERSION)),
+			MaxTextureSize:         int(m),
+		}
+
+		exts := " " + gl.GoStr(gl.GetString(gl.EXTENSIONS)) + " "
+		c.floatTexture = strings.Contains(exts, " GL_ARB_texture_float ")
+
+		c.maxAnisotropy = 0
+		if strings.Contains(exts, " GL_EXT_texture_filter_anisotropic ") {
+			gl.GetFloatv(maxTextureMaxAnisotropy, &c.maxAnisotropy)
+		}
+
+		s3tc := strings.Contains(exts, " GL_EXT_texture_compression_s3tc ")
+		// ETC2 is a core feature as of OpenGL 4.3.
+		v := c.driverInfo.Version
+		etc2 := strings.Contains(exts, " GL_ARB_ES3_compatibility ") ||
+			(len(v) >= 3 && (v[0] > '4' || (v[0] == '4' && v[1] == '.' && v[2] >= '3')))
+		c.compressedFormats = map[CompressedFormat]bool{
+			CompressedFormatDXT1:     s3tc,
+			CompressedFormatDXT5:     s3tc,
+			CompressedFormatETC2RGB:  etc2,
+			CompressedFormatETC2RGBA: etc2,
+		}
+
+		// sRGB textures are core features as of OpenGL 2.1, but sRGB framebuffers are not.
+		c.srgb = false
+		if srgbRequested {
+			if (v != "" && v[0] >= '3') ||
+				strings.Contains(exts, " GL_ARB_framebuffer_sRGB ") ||
+				strings.Contains(exts, " GL_EXT_framebuffer_sRGB ") {
+				c.srgb = true
+				gl.Enable(gl.FRAMEBUFFER_SRGB)
+			}
+		}
 		return nil
 	})
+	c.renderbuffers = map[Framebuffer]uint32{}
 	return nil
 }
 
-func (c *Context) BlendFunc(mode CompositeMode) {
+func (c *Context) setBlendImpl(b Blend) {
 	_ = c.runOnContextThread(func() error {
-		if c.lastCompositeMode == mode {
+		gl.BlendFuncSeparate(uint32(b.SourceRGB.operation()), uint32(b.DestinationRGB.operation()),
+			uint32(b.SourceAlpha.operation()), uint32(b.DestinationAlpha.operation()))
+		gl.BlendEquationSeparate(uint32(b.OperationRGB.equation()), uint32(b.OperationAlpha.equation()))
+		return nil
+	})
+}
+
+func (c *Context) setScissorImpl(r image.Rectangle) {
+	_ = c.runOnContextThread(func() error {
+		if r.Empty() {
+			gl.Disable(gl.SCISSOR_TEST)
 			return nil
 		}
-		c.lastCompositeMode = mode
-		s, d := operations(mode)
-		gl.BlendFunc(uint32(s), uint32(d))
+		gl.Enable(gl.SCISSOR_TEST)
+		gl.Scissor(int32(r.Min.X), int32(r.Min.Y), int32(r.Dx()), int32(r.Dy()))
 		return nil
 	})
 }
 
 func (c *Context) NewTexture(width, height int, pixels []uint8, filter Filter) (Texture, error) {
+	if c.srgb {
+		return c.newTexture(width, height, gl.SRGB8_ALPHA8, pixels, filter)
+	}
+	return c.newTexture(width, height, gl.RGBA, pixels, filter)
+}
+
+// IsSRGBEnabled reports whether 8-bit textures are in the sRGB color space. See also RequestSRGB.
+func (c *Context) IsSRGBEnabled() bool {
+	return c.srgb
+}
+
+// IsFloatTextureAvailable reports whether textures with 16-bit floating point channels are available.
+func (c *Context) IsFloatTextureAvailable() bool {
+	return c.floatTexture
+}
+
+// NewFloatTexture creates an empty texture with 16-bit floating point channels.
+// NewFloatTexture must be called only when IsFloatTextureAvailable returns true.
+func (c *Context) NewFloatTexture(width, height int, filter Filter) (Texture, error) {
+	return c.newTexture(width, height, gl.RGBA16F_ARB, nil, filter)
+}
+
+// NewCompressedTexture creates a texture with the compressed data of the whole texture.
+// NewCompressedTexture must be called only when IsCompressedFormatAvailable(format) returns true.
+func (c *Context) NewCompressedTexture(width, height int, format CompressedFormat, data []uint8, filter Filter) (Texture, error) {
+	var texture Texture
+	if err := c.runOnContextThread(func() error {
+		var t uint32
+		gl.GenTextures(1, &t)
+		if t <= 0 {
+			return errors.New("opengl: creating texture failed")
+		}
+		texture = Texture(t)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	c.BindTexture(texture)
+	_ = c.runOnContextThread(func() error {
+		c.setTextureFilterImpl(filter)
+		gl.CompressedTexImage2D(gl.TEXTURE_2D, 0, uint32(format.internalFormat()), int32(width), int32(height), 0, int32(len(data)), gl.Ptr(data))
+		return nil
+	})
+	return texture, nil
+}
+
+func (c *Context) newTexture(width, height int, internalFormat int32, pixels []uint8, filter Filter) (Texture, error) {
 	var texture Texture
 	if err := c.runOnContextThread(func() error {
 		var t uint32
@@ -154,8 +279,7 @@ func (c *Context) NewTexture(width, height int, pixels []uint8, filter Filter) (
 	}
 	c.BindTexture(texture)
 	_ = c.runOnContextThread(func() error {
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, int32(filter))
-		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, int32(filter))
+		c.setTextureFilterImpl(filter)
 		//gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP)
 		//gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP)
 
@@ -163,12 +287,38 @@ func (c *Context) NewTexture(width, height int, pixels []uint8, filter Filter) (
 		if pixels != nil {
 			p = pixels
 		}
-		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(p))
+		gl.TexImage2D(gl.TEXTURE_2D, 0, internalFormat, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(p))
 		return nil
 	})
 	return texture, nil
 }
 
+func (c *Context) SetTextureFilter(t Texture, filter Filter) {
+	c.BindTexture(t)
+	_ = c.runOnContextThread(func() error {
+		c.setTextureFilterImpl(filter)
+		return nil
+	})
+}
+
+// setTextureFilterImpl sets the filter of the bound texture.
+// setTextureFilterImpl must be called on the context thread.
+func (c *Context) setTextureFilterImpl(filter Filter) {
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, int32(filter.magFilter()))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, int32(filter.minFilter()))
+	if c.maxAnisotropy > 1 {
+		gl.TexParameterf(gl.TEXTURE_2D, textureMaxAnisotropy, filter.anisotropy(c.maxAnisotropy))
+	}
+}
+
+func (c *Context) GenerateMipmap(t Texture) {
+	c.BindTexture(t)
+	_ = c.runOnContextThread(func() error {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+		return nil
+	})
+}
+
 func (c *Context) bindFramebufferImpl(f Framebuffer) {
 	_ = c.runOnContextThread(func() error {
 		gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(f))
@@ -206,6 +356,13 @@ func (c *Context) bindTextureImpl(t Texture) {
 	})
 }
 
+func (c *Context) activeTextureImpl(unit int) {
+	_ = c.runOnContextThread(func() error {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(unit))
+		return nil
+	})
+}
+
 func (c *Context) DeleteTexture(t Texture) {
 	_ = c.runOnContextThread(func() error {
 		tt := uint32(t)
@@ -229,9 +386,9 @@ func (c *Context) IsTexture(t Texture) bool {
 	return r
 }
 
-func (c *Context) TexSubImage2D(p []uint8, width, height int) {
+func (c *Context) TexSubImage2D(p []uint8, x, y, width, height int) {
 	_ = c.runOnContextThread(func() error {
-		gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(p))
+		gl.TexSubImage2D(gl.TEXTURE_2D, 0, int32(x), int32(y), int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(p))
 		return nil
 	})
 }
@@ -274,6 +431,84 @@ func (c *Context) NewFramebuffer(texture Texture) (Framebuffer, error) {
 	return framebuffer, nil
 }
 
+func (c *Context) MaxSamples() int {
+	return c.maxSamples
+}
+
+func (c *Context) NewMultisampleFramebuffer(width, height, samples int) (Framebuffer, error) {
+	if c.maxSamples == 0 {
+		return 0, errors.New("opengl: multisampling is not supported")
+	}
+	var framebuffer Framebuffer
+	if err := c.runOnContextThread(func() error {
+		var r uint32
+		gl.GenRenderbuffers(1, &r)
+		gl.BindRenderbuffer(gl.RENDERBUFFER, r)
+		format := uint32(gl.RGBA8)
+		if c.srgb {
+			format = gl.SRGB8_ALPHA8
+		}
+		gl.RenderbufferStorageMultisample(gl.RENDERBUFFER, int32(samples), format, int32(width), int32(height))
+		gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+		var f uint32
+		gl.GenFramebuffers(1, &f)
+		if f <= 0 {
+			gl.DeleteRenderbuffers(1, &r)
+			return errors.New("opengl: creating framebuffer failed: gl.IsFramebuffer returns false")
+		}
+		gl.BindFramebuffer(gl.FRAMEBUFFER, f)
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, r)
+		s := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+		if s != gl.FRAMEBUFFER_COMPLETE {
+			gl.DeleteFramebuffers(1, &f)
+			gl.DeleteRenderbuffers(1, &r)
+			return fmt.Errorf("opengl: creating multisampled framebuffer failed: %v", s)
+		}
+		framebuffer = Framebuffer(f)
+		c.renderbuffers[framebuffer] = r
+		return nil
+	}); err != nil {
+		c.lastFramebuffer = invalidFramebuffer
+		return 0, err
+	}
+	c.lastFramebuffer = framebuffer
+	return framebuffer, nil
+}
+
+func (c *Context) DeleteMultisampleFramebuffer(f Framebuffer) {
+	c.DeleteFramebuffer(f)
+	_ = c.runOnContextThread(func() error {
+		r, ok := c.renderbuffers[f]
+		if !ok {
+			return nil
+		}
+		gl.DeleteRenderbuffers(1, &r)
+		delete(c.renderbuffers, f)
+		return nil
+	})
+}
+
+func (c *Context) BlitFramebuffer(src, dst Framebuffer, width, height int) {
+	// Blitting is affected by the scissor test.
+	c.SetScissor(image.Rectangle{})
+	_ = c.runOnContextThread(func() error {
+		gl.BindFramebuffer(gl.READ_FRAMEBUFFER, uint32(src))
+		gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, uint32(dst))
+		w, h := int32(width), int32(height)
+		if c.srgb {
+			// Copy the pixels as they are without conversions between the color spaces.
+			gl.Disable(gl.FRAMEBUFFER_SRGB)
+			defer gl.Enable(gl.FRAMEBUFFER_SRGB)
+		}
+		gl.BlitFramebuffer(0, 0, w, h, 0, 0, w, h, gl.COLOR_BUFFER_BIT, gl.NEAREST)
+		return nil
+	})
+	// The read and the draw framebuffers are now different.
+	// Bind a framebuffer again at the next time.
+	c.lastFramebuffer = invalidFramebuffer
+}
+
 func (c *Context) setViewportImpl(width, height int) {
 	_ = c.runOnContextThread(func() error {
 		gl.Viewport(0, 0, int32(width), int32(height))
@@ -282,6 +517,14 @@ func (c *Context) setViewportImpl(width, height int) {
 }
 
 func (c *Context) FillFramebuffer(r, g, b, a float64) error {
+	// Clearing is affected by the scissor test.
+	c.SetScissor(image.Rectangle{})
+	if c.srgb && a > 0 {
+		// The clear color is regarded as a linear color and encoded into sRGB.
+		r = srgbToLinear(r/a) * a
+		g = srgbToLinear(g/a) * a
+		b = srgbToLinear(b/a) * a
+	}
 	return c.runOnContextThread(func() error {
 		gl.ClearColor(float32(r), float32(g), float32(b), float32(a))
 		gl.Clear(gl.COLOR_BUFFER_BIT)
@@ -386,11 +629,9 @@ func (c *Context) DeleteProgram(p Program) {
 }
 
 func (c *Context) getUniformLocationImpl(p Program, location string) uniformLocation {
-	uniform := uniformLocation(gl.GetUniformLocation(uint32(p), gl.Str(location+"\x00")))
-	if uniform == -1 {
-		panic("opengl: invalid uniform location: " + location)
-	}
-	return uniform
+	// -1 is returned when the uniform variable doesn't exist or is optimized out.
+	// This is not an error since -1 is silently ignored by the uniform functions.
+	return uniformLocation(gl.GetUniformLocation(uint32(p), gl.Str(location+"\x00")))
 }
 
 func (c *Context) UniformInt(p Program, location string, v int) {
@@ -416,17 +657,44 @@ func (c *Context) UniformFloats(p Program, location string, v []float32) {
 	})
 }
 
+func (c *Context) UniformVariable(p Program, location string, typ UniformType, v []float32) {
+	_ = c.runOnContextThread(func() error {
+		l := int32(c.locationCache.GetUniformLocation(c, p, location))
+		ptr := (*float32)(gl.Ptr(v))
+		switch typ {
+		case UniformFloat:
+			gl.Uniform1fv(l, 1, ptr)
+		case UniformVec2:
+			gl.Uniform2fv(l, 1, ptr)
+		case UniformVec3:
+			gl.Uniform3fv(l, 1, ptr)
+		case UniformVec4:
+			gl.Uniform4fv(l, 1, ptr)
+		case UniformMat2:
+			gl.UniformMatrix2fv(l, 1, false, ptr)
+		case UniformMat3:
+			gl.UniformMatrix3fv(l, 1, false, ptr)
+		case UniformMat4:
+			gl.UniformMatrix4fv(l, 1, false, ptr)
+		default:
+			panic("not reach")
+		}
+		return nil
+	})
+}
+
 func (c *Context) getAttribLocationImpl(p Program, location string) attribLocation {
-	attrib := attribLocation(gl.GetAttribLocation(uint32(p), gl.Str(location+"\x00")))
-	if attrib == -1 {
-		panic("opengl: invalid attrib location: " + location)
-	}
-	return attrib
+	// -1 is returned when the attribute is optimized out, which can happen with custom shaders.
+	// Such attributes are skipped.
+	return attribLocation(gl.GetAttribLocation(uint32(p), gl.Str(location+"\x00")))
 }
 
 func (c *Context) VertexAttribPointer(p Program, location string, size int, dataType DataType, normalize bool, stride int, offset int) {
 	_ = c.runOnContextThread(func() error {
 		l := c.locationCache.GetAttribLocation(c, p, location)
+		if l == -1 {
+			return nil
+		}
 		gl.VertexAttribPointer(uint32(l), int32(size), uint32(dataType), normalize, int32(stride), gl.PtrOffset(offset))
 		return nil
 	})
@@ -435,6 +703,9 @@ func (c *Context) VertexAttribPointer(p Program, location string, size int, data
 func (c *Context) EnableVertexAttribArray(p Program, location string) {
 	_ = c.runOnContextThread(func() error {
 		l := c.locationCache.GetAttribLocation(c, p, location)
+		if l == -1 {
+			return nil
+		}
 		gl.EnableVertexAttribArray(uint32(l))
 		return nil
 	})
@@ -443,6 +714,9 @@ func (c *Context) EnableVertexAttribArray(p Program, location string) {
 func (c *Context) DisableVertexAttribArray(p Program, location string) {
 	_ = c.runOnContextThread(func() error {
 		l := c.locationCache.GetAttribLocation(c, p, location)
+		if l == -1 {
+			return nil
+		}
 		gl.DisableVertexAttribArray(uint32(l))
 		return nil
 	})
@@ -461,13 +735,13 @@ func (c *Context) NewArrayBuffer(size int) Buffer {
 	return buffer
 }
 
-func (c *Context) NewElementArrayBuffer(indices []uint16) Buffer {
+func (c *Context) NewElementArrayBuffer(size int) Buffer {
 	var buffer Buffer
 	_ = c.runOnContextThread(func() error {
 		var b uint32
 		gl.GenBuffers(1, &b)
 		gl.BindBuffer(uint32(ElementArrayBuffer), b)
-		gl.BufferData(uint32(ElementArrayBuffer), 2*len(indices), gl.Ptr(indices), uint32(StaticDraw))
+		gl.BufferData(uint32(ElementArrayBuffer), size, nil, uint32(DynamicDraw))
 		buffer = Buffer(b)
 		return nil
 	})
@@ -488,6 +762,13 @@ func (c *Context) BufferSubData(bufferType BufferType, data []float32) {
 	})
 }
 
+func (c *Context) ElementArrayBufferSubData(data []uint16) {
+	_ = c.runOnContextThread(func() error {
+		gl.BufferSubData(uint32(ElementArrayBuffer), 0, len(data)*2, gl.Ptr(data))
+		return nil
+	})
+}
+
 func (c *Context) DeleteBuffer(b Buffer) {
 	_ = c.runOnContextThread(func() error {
 		bb := uint32(b)
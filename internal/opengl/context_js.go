@@ -19,6 +19,7 @@ package opengl
 import (
 	"errors"
 	"fmt"
+	"image"
 
 	"github.com/gopherjs/gopherjs/js"
 	"github.com/gopherjs/webgl"
@@ -77,6 +78,7 @@ func init() {
 	c := js.Global.Get("WebGLRenderingContext").Get("prototype")
 	Nearest = Filter(c.Get("NEAREST").Int())
 	Linear = Filter(c.Get("LINEAR").Int())
+	LinearMipmap = Filter(c.Get("LINEAR_MIPMAP_LINEAR").Int())
 	VertexShader = ShaderType(c.Get("VERTEX_SHADER").Int())
 	FragmentShader = ShaderType(c.Get("FRAGMENT_SHADER").Int())
 	ArrayBuffer = BufferType(c.Get("ARRAY_BUFFER").Int())
@@ -90,10 +92,18 @@ func init() {
 
 	zero = operation(c.Get("ZERO").Int())
 	one = operation(c.Get("ONE").Int())
+	srcColor = operation(c.Get("SRC_COLOR").Int())
+	dstColor = operation(c.Get("DST_COLOR").Int())
 	srcAlpha = operation(c.Get("SRC_ALPHA").Int())
 	dstAlpha = operation(c.Get("DST_ALPHA").Int())
+	oneMinusSrcColor = operation(c.Get("ONE_MINUS_SRC_COLOR").Int())
+	oneMinusDstColor = operation(c.Get("ONE_MINUS_DST_COLOR").Int())
 	oneMinusSrcAlpha = operation(c.Get("ONE_MINUS_SRC_ALPHA").Int())
 	oneMinusDstAlpha = operation(c.Get("ONE_MINUS_DST_ALPHA").Int())
+
+	funcAdd = equation(c.Get("FUNC_ADD").Int())
+	funcSubtract = equation(c.Get("FUNC_SUBTRACT").Int())
+	funcReverseSubtract = equation(c.Get("FUNC_REVERSE_SUBTRACT").Int())
 }
 
 type context struct {
@@ -147,23 +157,41 @@ func (c *Context) Reset() error {
 	c.lastFramebuffer = invalidFramebuffer
 	c.lastViewportWidth = 0
 	c.lastViewportHeight = 0
-	c.lastCompositeMode = CompositeModeUnknown
-	gl := c.gl
+	c.lastBlendValid = false
+	c.lastScissorValid = false
+	gl := c.gl
+	s3tc := gl.GetExtension("WEBGL_compressed_texture_s3tc") != nil
+	etc2 := gl.GetExtension("WEBGL_compressed_texture_etc") != nil
+	c.maxAnisotropy = 0
+	if gl.GetExtension("EXT_texture_filter_anisotropic") != nil {
+		c.maxAnisotropy = float32(gl.GetParameter(maxTextureMaxAnisotropy).Float())
+	}
+	c.compressedFormats = map[CompressedFormat]bool{
+		CompressedFormatDXT1:     s3tc,
+		CompressedFormatDXT5:     s3tc,
+		CompressedFormatETC2RGB:  etc2,
+		CompressedFormatETC2RGBA: etc2,
+	}
 	gl.Enable(gl.BLEND)
-	c.BlendFunc(CompositeModeSourceOver)
+	c.SetBlend(CompositeModeSourceOver.Blend())
 	f := gl.GetParameter(gl.FRAMEBUFFER_BINDING)
 	c.screenFramebuffer = Framebuffer{f}
+	c.driverInfo = DriverInfo{
+		API:                    "WebGL",
+		Vendor:                 gl.GetParameter(gl.VENDOR).String(),
+		Renderer:               gl.GetParameter(gl.RENDERER).String(),
+		Version:                gl.GetParameter(gl.VERSION).String(),
+		ShadingLanguageVersion: gl.GetParameter(gl.SHADING_LANGUAGE_VERSION).String(),
+		MaxTextureSize:         gl.GetParameter(gl.MAX_TEXTURE_SIZE).Int(),
+	}
 	return nil
 }
 
-func (c *Context) BlendFunc(mode CompositeMode) {
-	if c.lastCompositeMode == mode {
-		return
-	}
-	c.lastCompositeMode = mode
-	s, d := operations(mode)
+func (c *Context) setBlendImpl(b Blend) {
 	gl := c.gl
-	gl.BlendFunc(int(s), int(d))
+	gl.Call("blendFuncSeparate", int(b.SourceRGB.operation()), int(b.DestinationRGB.operation()),
+		int(b.SourceAlpha.operation()), int(b.DestinationAlpha.operation()))
+	gl.Call("blendEquationSeparate", int(b.OperationRGB.equation()), int(b.OperationAlpha.equation()))
 }
 
 func (c *Context) NewTexture(width, height int, pixels []uint8, filter Filter) (Texture, error) {
@@ -175,8 +203,7 @@ func (c *Context) NewTexture(width, height int, pixels []uint8, filter Filter) (
 	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
 	c.BindTexture(Texture{t})
 
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, int(filter))
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, int(filter))
+	c.setTextureFilterImpl(filter)
 
 	// TODO: Can we use glTexSubImage2D with linear filtering?
 
@@ -192,11 +219,83 @@ func (c *Context) NewTexture(width, height int, pixels []uint8, filter Filter) (
 	return Texture{t}, nil
 }
 
+// NewCompressedTexture creates a texture with the compressed data of the whole texture.
+// NewCompressedTexture must be called only when IsCompressedFormatAvailable(format) returns true.
+func (c *Context) NewCompressedTexture(width, height int, format CompressedFormat, data []uint8, filter Filter) (Texture, error) {
+	gl := c.gl
+	t := gl.CreateTexture()
+	if t == nil {
+		return Texture{nil}, errors.New("opengl: glGenTexture failed")
+	}
+	c.BindTexture(Texture{t})
+
+	c.setTextureFilterImpl(filter)
+
+	// void compressedTexImage2D(GLenum target, GLint level, GLenum internalformat,
+	//     GLsizei width, GLsizei height, GLint border, ArrayBufferView data);
+	gl.Call("compressedTexImage2D", gl.TEXTURE_2D, 0, format.internalFormat(), width, height, 0, data)
+
+	return Texture{t}, nil
+}
+
+func (c *Context) SetTextureFilter(t Texture, filter Filter) {
+	c.BindTexture(t)
+	c.setTextureFilterImpl(filter)
+}
+
+// setTextureFilterImpl sets the filter of the bound texture.
+func (c *Context) setTextureFilterImpl(filter Filter) {
+	gl := c.gl
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, int(filter.magFilter()))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, int(filter.minFilter()))
+	if c.maxAnisotropy > 1 {
+		gl.Call("texParameterf", gl.TEXTURE_2D, textureMaxAnisotropy, filter.anisotropy(c.maxAnisotropy))
+	}
+}
+
+func (c *Context) GenerateMipmap(t Texture) {
+	c.BindTexture(t)
+	gl := c.gl
+	gl.Call("generateMipmap", gl.TEXTURE_2D)
+}
+
 func (c *Context) bindFramebufferImpl(f Framebuffer) {
 	gl := c.gl
 	gl.BindFramebuffer(gl.FRAMEBUFFER, f.Object)
 }
 
+// MaxSamples returns 0 since WebGL 1 doesn't support multisampled framebuffers.
+func (c *Context) MaxSamples() int {
+	return 0
+}
+
+// IsFloatTextureAvailable returns false since WebGL 1 doesn't support rendering to floating point textures
+// without extensions.
+func (c *Context) IsFloatTextureAvailable() bool {
+	return false
+}
+
+func (c *Context) NewFloatTexture(width, height int, filter Filter) (Texture, error) {
+	panic("opengl: NewFloatTexture is not supported")
+}
+
+// IsSRGBEnabled returns false since WebGL 1 doesn't support sRGB framebuffers without extensions.
+func (c *Context) IsSRGBEnabled() bool {
+	return false
+}
+
+func (c *Context) NewMultisampleFramebuffer(width, height, samples int) (Framebuffer, error) {
+	return Framebuffer{}, errors.New("opengl: multisampling is not supported")
+}
+
+func (c *Context) DeleteMultisampleFramebuffer(f Framebuffer) {
+	c.DeleteFramebuffer(f)
+}
+
+func (c *Context) BlitFramebuffer(src, dst Framebuffer, width, height int) {
+	panic("opengl: BlitFramebuffer is not supported")
+}
+
 func (c *Context) FramebufferPixels(f Framebuffer, width, height int) ([]uint8, error) {
 	gl := c.gl
 
@@ -215,6 +314,11 @@ func (c *Context) bindTextureImpl(t Texture) {
 	gl.BindTexture(gl.TEXTURE_2D, t.Object)
 }
 
+func (c *Context) activeTextureImpl(unit int) {
+	gl := c.gl
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+}
+
 func (c *Context) DeleteTexture(t Texture) {
 	gl := c.gl
 	if !gl.IsTexture(t.Object) {
@@ -232,12 +336,12 @@ func (c *Context) IsTexture(t Texture) bool {
 	return b
 }
 
-func (c *Context) TexSubImage2D(p []uint8, width, height int) {
+func (c *Context) TexSubImage2D(p []uint8, x, y, width, height int) {
 	gl := c.gl
 	// void texSubImage2D(GLenum target, GLint level, GLint xoffset, GLint yoffset,
 	//                    GLsizei width, GLsizei height,
 	//                    GLenum format, GLenum type, ArrayBufferView? pixels);
-	gl.Call("texSubImage2D", gl.TEXTURE_2D, 0, 0, 0, width, height, gl.RGBA, gl.UNSIGNED_BYTE, p)
+	gl.Call("texSubImage2D", gl.TEXTURE_2D, 0, x, y, width, height, gl.RGBA, gl.UNSIGNED_BYTE, p)
 }
 
 func (c *Context) NewFramebuffer(t Texture) (Framebuffer, error) {
@@ -259,7 +363,19 @@ func (c *Context) setViewportImpl(width, height int) {
 	gl.Viewport(0, 0, width, height)
 }
 
+func (c *Context) setScissorImpl(r image.Rectangle) {
+	gl := c.gl
+	if r.Empty() {
+		gl.Disable(gl.SCISSOR_TEST)
+		return
+	}
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+}
+
 func (c *Context) FillFramebuffer(r, g, b, a float64) error {
+	// Clearing is affected by the scissor test.
+	c.SetScissor(image.Rectangle{})
 	// TODO: Use f?
 	gl := c.gl
 	gl.ClearColor(float32(r), float32(g), float32(b), float32(a))
@@ -361,26 +477,60 @@ func (c *Context) UniformFloats(p Program, location string, v []float32) {
 	}
 }
 
+func (c *Context) UniformVariable(p Program, location string, typ UniformType, v []float32) {
+	gl := c.gl
+	l := c.locationCache.GetUniformLocation(c, p, location)
+	switch typ {
+	case UniformFloat:
+		gl.Call("uniform1fv", l.Object, v)
+	case UniformVec2:
+		gl.Call("uniform2fv", l.Object, v)
+	case UniformVec3:
+		gl.Call("uniform3fv", l.Object, v)
+	case UniformVec4:
+		gl.Call("uniform4fv", l.Object, v)
+	case UniformMat2:
+		gl.Call("uniformMatrix2fv", l.Object, false, v)
+	case UniformMat3:
+		gl.Call("uniformMatrix3fv", l.Object, false, v)
+	case UniformMat4:
+		gl.UniformMatrix4fv(l.Object, false, v)
+	default:
+		panic("not reach")
+	}
+}
+
 func (c *Context) getAttribLocationImpl(p Program, location string) attribLocation {
 	gl := c.gl
+	// -1 is returned when the attribute is optimized out, which can happen with custom shaders.
+	// Such attributes are skipped.
 	return attribLocation(gl.GetAttribLocation(p.Object, location))
 }
 
 func (c *Context) VertexAttribPointer(p Program, location string, size int, dataType DataType, normalize bool, stride int, offset int) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
+	if l == -1 {
+		return
+	}
 	gl.VertexAttribPointer(int(l), size, int(dataType), normalize, stride, offset)
 }
 
 func (c *Context) EnableVertexAttribArray(p Program, location string) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
+	if l == -1 {
+		return
+	}
 	gl.EnableVertexAttribArray(int(l))
 }
 
 func (c *Context) DisableVertexAttribArray(p Program, location string) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
+	if l == -1 {
+		return
+	}
 	gl.DisableVertexAttribArray(int(l))
 }
 
@@ -392,11 +542,11 @@ func (c *Context) NewArrayBuffer(size int) Buffer {
 	return Buffer{b}
 }
 
-func (c *Context) NewElementArrayBuffer(indices []uint16) Buffer {
+func (c *Context) NewElementArrayBuffer(size int) Buffer {
 	gl := c.gl
 	b := gl.CreateBuffer()
 	gl.BindBuffer(int(ElementArrayBuffer), b)
-	gl.BufferData(int(ElementArrayBuffer), indices, int(StaticDraw))
+	gl.BufferData(int(ElementArrayBuffer), size, int(DynamicDraw))
 	return Buffer{b}
 }
 
@@ -410,6 +560,11 @@ func (c *Context) BufferSubData(bufferType BufferType, data []float32) {
 	gl.BufferSubData(int(bufferType), 0, data)
 }
 
+func (c *Context) ElementArrayBufferSubData(data []uint16) {
+	gl := c.gl
+	gl.BufferSubData(int(ElementArrayBuffer), 0, data)
+}
+
 func (c *Context) DeleteBuffer(b Buffer) {
 	gl := c.gl
 	gl.DeleteBuffer(b.Object)
@@ -12,14 +12,17 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// +build android ios
+// +build android ios gles
+// +build !js
 
 package opengl
 
 import (
 	"errors"
 	"fmt"
+	"image"
 	"math"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/internal/endian"
 	mgl "golang.org/x/mobile/gl"
@@ -56,6 +59,7 @@ func (p Program) id() programID {
 func init() {
 	Nearest = mgl.NEAREST
 	Linear = mgl.LINEAR
+	LinearMipmap = mgl.LINEAR_MIPMAP_LINEAR
 	VertexShader = mgl.VERTEX_SHADER
 	FragmentShader = mgl.FRAGMENT_SHADER
 	ArrayBuffer = mgl.ARRAY_BUFFER
@@ -69,10 +73,18 @@ func init() {
 
 	zero = mgl.ZERO
 	one = mgl.ONE
+	srcColor = mgl.SRC_COLOR
+	dstColor = mgl.DST_COLOR
 	srcAlpha = mgl.SRC_ALPHA
 	dstAlpha = mgl.DST_ALPHA
+	oneMinusSrcColor = mgl.ONE_MINUS_SRC_COLOR
+	oneMinusDstColor = mgl.ONE_MINUS_DST_COLOR
 	oneMinusSrcAlpha = mgl.ONE_MINUS_SRC_ALPHA
 	oneMinusDstAlpha = mgl.ONE_MINUS_DST_ALPHA
+
+	funcAdd = mgl.FUNC_ADD
+	funcSubtract = mgl.FUNC_SUBTRACT
+	funcReverseSubtract = mgl.FUNC_REVERSE_SUBTRACT
 }
 
 type context struct {
@@ -102,29 +114,63 @@ loop:
 	return nil
 }
 
+// WorkAvailable returns a channel that receives a value when GL calls are waiting to be done by DoPendingWork.
+func (c *Context) WorkAvailable() <-chan struct{} {
+	return c.worker.WorkAvailable()
+}
+
+// DoPendingWork does the waiting GL calls.
+//
+// DoPendingWork must be called on the thread where the GL context is current.
+func (c *Context) DoPendingWork() {
+	c.worker.DoWork()
+}
+
 func (c *Context) Reset() error {
 	c.locationCache = newLocationCache()
 	c.lastTexture = invalidTexture
 	c.lastFramebuffer = invalidFramebuffer
 	c.lastViewportWidth = 0
 	c.lastViewportHeight = 0
-	c.lastCompositeMode = CompositeModeUnknown
+	c.lastBlendValid = false
+	c.lastScissorValid = false
 	c.gl.Enable(mgl.BLEND)
-	c.BlendFunc(CompositeModeSourceOver)
+	c.SetBlend(CompositeModeSourceOver.Blend())
 	f := c.gl.GetInteger(mgl.FRAMEBUFFER_BINDING)
 	c.screenFramebuffer = Framebuffer(mgl.Framebuffer{uint32(f)})
+	c.driverInfo = DriverInfo{
+		API:                    "OpenGL ES",
+		Vendor:                 c.gl.GetString(mgl.VENDOR),
+		Renderer:               c.gl.GetString(mgl.RENDERER),
+		Version:                c.gl.GetString(mgl.VERSION),
+		ShadingLanguageVersion: c.gl.GetString(mgl.SHADING_LANGUAGE_VERSION),
+		MaxTextureSize:         c.gl.GetInteger(mgl.MAX_TEXTURE_SIZE),
+	}
+	exts := " " + c.gl.GetString(mgl.EXTENSIONS) + " "
+	s3tc := strings.Contains(exts, " GL_EXT_texture_compression_s3tc ")
+	c.maxAnisotropy = 0
+	if strings.Contains(exts, " GL_EXT_texture_filter_anisotropic ") {
+		a := make([]float32, 1)
+		c.gl.GetFloatv(a, maxTextureMaxAnisotropy)
+		c.maxAnisotropy = a[0]
+	}
+	// ETC2 is a core feature as of OpenGL ES 3.0.
+	etc2 := strings.HasPrefix(c.driverInfo.Version, "OpenGL ES 3")
+	c.compressedFormats = map[CompressedFormat]bool{
+		CompressedFormatDXT1:     s3tc,
+		CompressedFormatDXT5:     s3tc,
+		CompressedFormatETC2RGB:  etc2,
+		CompressedFormatETC2RGBA: etc2,
+	}
 	// TODO: Need to update screenFramebufferWidth/Height?
 	return nil
 }
 
-func (c *Context) BlendFunc(mode CompositeMode) {
+func (c *Context) setBlendImpl(b Blend) {
 	gl := c.gl
-	if c.lastCompositeMode == mode {
-		return
-	}
-	c.lastCompositeMode = mode
-	s, d := operations(mode)
-	gl.BlendFunc(mgl.Enum(s), mgl.Enum(d))
+	gl.BlendFuncSeparate(mgl.Enum(b.SourceRGB.operation()), mgl.Enum(b.DestinationRGB.operation()),
+		mgl.Enum(b.SourceAlpha.operation()), mgl.Enum(b.DestinationAlpha.operation()))
+	gl.BlendEquationSeparate(mgl.Enum(b.OperationRGB.equation()), mgl.Enum(b.OperationAlpha.equation()))
 }
 
 func (c *Context) NewTexture(width, height int, pixels []uint8, filter Filter) (Texture, error) {
@@ -136,8 +182,7 @@ func (c *Context) NewTexture(width, height int, pixels []uint8, filter Filter) (
 	gl.PixelStorei(mgl.UNPACK_ALIGNMENT, 4)
 	c.BindTexture(Texture(t))
 
-	gl.TexParameteri(mgl.TEXTURE_2D, mgl.TEXTURE_MAG_FILTER, int(filter))
-	gl.TexParameteri(mgl.TEXTURE_2D, mgl.TEXTURE_MIN_FILTER, int(filter))
+	c.setTextureFilterImpl(filter)
 
 	var p []uint8
 	if pixels != nil {
@@ -148,11 +193,80 @@ func (c *Context) NewTexture(width, height int, pixels []uint8, filter Filter) (
 	return Texture(t), nil
 }
 
+// NewCompressedTexture creates a texture with the compressed data of the whole texture.
+// NewCompressedTexture must be called only when IsCompressedFormatAvailable(format) returns true.
+func (c *Context) NewCompressedTexture(width, height int, format CompressedFormat, data []uint8, filter Filter) (Texture, error) {
+	gl := c.gl
+	t := gl.CreateTexture()
+	if t.Value <= 0 {
+		return Texture{}, errors.New("opengl: creating texture failed")
+	}
+	c.BindTexture(Texture(t))
+
+	c.setTextureFilterImpl(filter)
+	gl.CompressedTexImage2D(mgl.TEXTURE_2D, 0, mgl.Enum(format.internalFormat()), width, height, 0, data)
+
+	return Texture(t), nil
+}
+
+func (c *Context) SetTextureFilter(t Texture, filter Filter) {
+	c.BindTexture(t)
+	c.setTextureFilterImpl(filter)
+}
+
+// setTextureFilterImpl sets the filter of the bound texture.
+func (c *Context) setTextureFilterImpl(filter Filter) {
+	gl := c.gl
+	gl.TexParameteri(mgl.TEXTURE_2D, mgl.TEXTURE_MAG_FILTER, int(filter.magFilter()))
+	gl.TexParameteri(mgl.TEXTURE_2D, mgl.TEXTURE_MIN_FILTER, int(filter.minFilter()))
+	if c.maxAnisotropy > 1 {
+		gl.TexParameterf(mgl.TEXTURE_2D, textureMaxAnisotropy, filter.anisotropy(c.maxAnisotropy))
+	}
+}
+
+func (c *Context) GenerateMipmap(t Texture) {
+	c.BindTexture(t)
+	gl := c.gl
+	gl.GenerateMipmap(mgl.TEXTURE_2D)
+}
+
 func (c *Context) bindFramebufferImpl(f Framebuffer) {
 	gl := c.gl
 	gl.BindFramebuffer(mgl.FRAMEBUFFER, mgl.Framebuffer(f))
 }
 
+// MaxSamples returns 0 since OpenGL ES 2 doesn't support multisampled framebuffers.
+func (c *Context) MaxSamples() int {
+	return 0
+}
+
+// IsFloatTextureAvailable returns false since OpenGL ES 2 doesn't support rendering to floating point textures
+// without extensions.
+func (c *Context) IsFloatTextureAvailable() bool {
+	return false
+}
+
+func (c *Context) NewFloatTexture(width, height int, filter Filter) (Texture, error) {
+	panic("opengl: NewFloatTexture is not supported")
+}
+
+// IsSRGBEnabled returns false since OpenGL ES 2 doesn't support sRGB framebuffers without extensions.
+func (c *Context) IsSRGBEnabled() bool {
+	return false
+}
+
+func (c *Context) NewMultisampleFramebuffer(width, height, samples int) (Framebuffer, error) {
+	return Framebuffer{}, errors.New("opengl: multisampling is not supported")
+}
+
+func (c *Context) DeleteMultisampleFramebuffer(f Framebuffer) {
+	c.DeleteFramebuffer(f)
+}
+
+func (c *Context) BlitFramebuffer(src, dst Framebuffer, width, height int) {
+	panic("opengl: BlitFramebuffer is not supported")
+}
+
 func (c *Context) FramebufferPixels(f Framebuffer, width, height int) ([]uint8, error) {
 	gl := c.gl
 	gl.Flush()
@@ -172,6 +286,11 @@ func (c *Context) bindTextureImpl(t Texture) {
 	gl.BindTexture(mgl.TEXTURE_2D, mgl.Texture(t))
 }
 
+func (c *Context) activeTextureImpl(unit int) {
+	gl := c.gl
+	gl.ActiveTexture(mgl.Enum(mgl.TEXTURE0 + unit))
+}
+
 func (c *Context) DeleteTexture(t Texture) {
 	gl := c.gl
 	if !gl.IsTexture(mgl.Texture(t)) {
@@ -188,9 +307,13 @@ func (c *Context) IsTexture(t Texture) bool {
 	return gl.IsTexture(mgl.Texture(t))
 }
 
-func (c *Context) TexSubImage2D(p []uint8, width, height int) {
+func (c *Context) TexSubImage2D(p []uint8, x, y, width, height int) {
 	gl := c.gl
-	gl.TexSubImage2D(mgl.TEXTURE_2D, 0, 0, 0, width, height, mgl.RGBA, mgl.UNSIGNED_BYTE, p)
+	gl.TexSubImage2D(mgl.TEXTURE_2D, 0, x, y, width, height, mgl.RGBA, mgl.UNSIGNED_BYTE, p)
+}
+
+func (c *Context) BindScreenFramebuffer() {
+	c.bindFramebuffer(c.screenFramebuffer)
 }
 
 func (c *Context) NewFramebuffer(texture Texture) (Framebuffer, error) {
@@ -220,7 +343,19 @@ func (c *Context) setViewportImpl(width, height int) {
 	gl.Viewport(0, 0, width, height)
 }
 
+func (c *Context) setScissorImpl(r image.Rectangle) {
+	gl := c.gl
+	if r.Empty() {
+		gl.Disable(mgl.SCISSOR_TEST)
+		return
+	}
+	gl.Enable(mgl.SCISSOR_TEST)
+	gl.Scissor(int32(r.Min.X), int32(r.Min.Y), int32(r.Dx()), int32(r.Dy()))
+}
+
 func (c *Context) FillFramebuffer(r, g, b, a float64) error {
+	// Clearing is affected by the scissor test.
+	c.SetScissor(image.Rectangle{})
 	gl := c.gl
 	gl.ClearColor(float32(r), float32(g), float32(b), float32(a))
 	gl.Clear(mgl.COLOR_BUFFER_BIT)
@@ -298,11 +433,9 @@ func (c *Context) DeleteProgram(p Program) {
 
 func (c *Context) getUniformLocationImpl(p Program, location string) uniformLocation {
 	gl := c.gl
-	u := uniformLocation(gl.GetUniformLocation(mgl.Program(p), location))
-	if u.Value == -1 {
-		panic("invalid uniform location: " + location)
-	}
-	return u
+	// -1 is returned when the uniform variable doesn't exist or is optimized out.
+	// This is not an error since -1 is silently ignored by the uniform functions.
+	return uniformLocation(gl.GetUniformLocation(mgl.Program(p), location))
 }
 
 func (c *Context) UniformInt(p Program, location string, v int) {
@@ -323,30 +456,60 @@ func (c *Context) UniformFloats(p Program, location string, v []float32) {
 	}
 }
 
-func (c *Context) getAttribLocationImpl(p Program, location string) attribLocation {
+func (c *Context) UniformVariable(p Program, location string, typ UniformType, v []float32) {
 	gl := c.gl
-	a := attribLocation(gl.GetAttribLocation(mgl.Program(p), location))
-	if a.Value == ^uint(0) {
-		panic("invalid attrib location: " + location)
+	l := mgl.Uniform(c.locationCache.GetUniformLocation(c, p, location))
+	switch typ {
+	case UniformFloat:
+		gl.Uniform1fv(l, v)
+	case UniformVec2:
+		gl.Uniform2fv(l, v)
+	case UniformVec3:
+		gl.Uniform3fv(l, v)
+	case UniformVec4:
+		gl.Uniform4fv(l, v)
+	case UniformMat2:
+		gl.UniformMatrix2fv(l, v)
+	case UniformMat3:
+		gl.UniformMatrix3fv(l, v)
+	case UniformMat4:
+		gl.UniformMatrix4fv(l, v)
+	default:
+		panic("not reach")
 	}
-	return a
+}
+
+func (c *Context) getAttribLocationImpl(p Program, location string) attribLocation {
+	gl := c.gl
+	// ^uint(0) is returned when the attribute is optimized out, which can happen with custom shaders.
+	// Such attributes are skipped.
+	return attribLocation(gl.GetAttribLocation(mgl.Program(p), location))
 }
 
 func (c *Context) VertexAttribPointer(p Program, location string, size int, dataType DataType, normalize bool, stride int, offset int) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
+	if l.Value == ^uint(0) {
+		return
+	}
 	gl.VertexAttribPointer(mgl.Attrib(l), size, mgl.Enum(dataType), normalize, stride, offset)
 }
 
 func (c *Context) EnableVertexAttribArray(p Program, location string) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
+	if l.Value == ^uint(0) {
+		return
+	}
 	gl.EnableVertexAttribArray(mgl.Attrib(l))
 }
 
 func (c *Context) DisableVertexAttribArray(p Program, location string) {
 	gl := c.gl
 	l := c.locationCache.GetAttribLocation(c, p, location)
+	if l.Value == ^uint(0) {
+		return
+	}
 	gl.DisableVertexAttribArray(mgl.Attrib(l))
 }
 
@@ -368,11 +531,11 @@ func (c *Context) NewArrayBuffer(size int) Buffer {
 	return Buffer(b)
 }
 
-func (c *Context) NewElementArrayBuffer(indices []uint16) Buffer {
+func (c *Context) NewElementArrayBuffer(size int) Buffer {
 	gl := c.gl
 	b := gl.CreateBuffer()
 	gl.BindBuffer(mgl.Enum(ElementArrayBuffer), b)
-	gl.BufferData(mgl.Enum(ElementArrayBuffer), uint16ToBytes(indices), mgl.Enum(StaticDraw))
+	gl.BufferInit(mgl.Enum(ElementArrayBuffer), size, mgl.Enum(DynamicDraw))
 	return Buffer(b)
 }
 
@@ -409,6 +572,11 @@ func (c *Context) BufferSubData(bufferType BufferType, data []float32) {
 	gl.BufferSubData(mgl.Enum(bufferType), 0, float32ToBytes(data))
 }
 
+func (c *Context) ElementArrayBufferSubData(data []uint16) {
+	gl := c.gl
+	gl.BufferSubData(mgl.Enum(ElementArrayBuffer), 0, uint16ToBytes(data))
+}
+
 func (c *Context) DeleteBuffer(b Buffer) {
 	gl := c.gl
 	gl.DeleteBuffer(mgl.Buffer(b))
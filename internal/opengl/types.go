@@ -20,6 +20,73 @@ type BufferType int
 type BufferUsage int
 type Mode int
 type operation int
+type equation int
+
+// Address represents how a texture is sampled outside of the source region.
+// Other address modes than AddressDefault are emulated in the fragment shader
+// so that they work with a region of a texture, e.g., an image in an atlas.
+type Address int
+
+const (
+	// AddressDefault samples the texture as it is.
+	AddressDefault Address = iota
+
+	// AddressRepeat repeats the source region.
+	AddressRepeat
+
+	// AddressMirroredRepeat repeats the source region, mirroring it at every other repetition.
+	AddressMirroredRepeat
+)
+
+// CompressedFormat represents a format of GPU-compressed textures.
+// All the formats compress each block of 4x4 pixels into a fixed size.
+type CompressedFormat int
+
+const (
+	// CompressedFormatDXT1 is S3TC DXT1 (BC1) with 1-bit alpha.
+	CompressedFormatDXT1 CompressedFormat = iota
+
+	// CompressedFormatDXT5 is S3TC DXT5 (BC3) with interpolated alpha.
+	CompressedFormatDXT5
+
+	// CompressedFormatETC2RGB is ETC2 RGB8 without alpha.
+	CompressedFormatETC2RGB
+
+	// CompressedFormatETC2RGBA is ETC2 RGBA8 with EAC alpha.
+	CompressedFormatETC2RGBA
+)
+
+// BlockSizeInBytes returns the size in bytes of a block of 4x4 pixels.
+func (f CompressedFormat) BlockSizeInBytes() int {
+	switch f {
+	case CompressedFormatDXT1, CompressedFormatETC2RGB:
+		return 8
+	case CompressedFormatDXT5, CompressedFormatETC2RGBA:
+		return 16
+	}
+	panic("not reached")
+}
+
+// DataSizeInBytes returns the size in bytes of the compressed data of width x height pixels.
+func (f CompressedFormat) DataSizeInBytes(width, height int) int {
+	return ((width + 3) / 4) * ((height + 3) / 4) * f.BlockSizeInBytes()
+}
+
+// internalFormat returns the GL enum of the format.
+// The values are the same among OpenGL, OpenGL ES and WebGL.
+func (f CompressedFormat) internalFormat() int {
+	switch f {
+	case CompressedFormatDXT1:
+		return 0x83F1 // GL_COMPRESSED_RGBA_S3TC_DXT1_EXT
+	case CompressedFormatDXT5:
+		return 0x83F3 // GL_COMPRESSED_RGBA_S3TC_DXT5_EXT
+	case CompressedFormatETC2RGB:
+		return 0x9274 // GL_COMPRESSED_RGB8_ETC2
+	case CompressedFormatETC2RGBA:
+		return 0x9278 // GL_COMPRESSED_RGBA8_ETC2_EAC
+	}
+	panic("not reached")
+}
 
 type CompositeMode int
 
@@ -37,42 +104,149 @@ const (
 	CompositeModeDestinationAtop
 	CompositeModeXor
 	CompositeModeLighter
-	CompositeModeUnknown
+	CompositeModeMultiply
+)
+
+// BlendFactor represents a factor of blending.
+type BlendFactor int
+
+const (
+	BlendFactorZero BlendFactor = iota
+	BlendFactorOne
+	BlendFactorSourceColor
+	BlendFactorOneMinusSourceColor
+	BlendFactorSourceAlpha
+	BlendFactorOneMinusSourceAlpha
+	BlendFactorDestinationColor
+	BlendFactorOneMinusDestinationColor
+	BlendFactorDestinationAlpha
+	BlendFactorOneMinusDestinationAlpha
 )
 
-func operations(mode CompositeMode) (src operation, dst operation) {
+func (f BlendFactor) operation() operation {
+	switch f {
+	case BlendFactorZero:
+		return zero
+	case BlendFactorOne:
+		return one
+	case BlendFactorSourceColor:
+		return srcColor
+	case BlendFactorOneMinusSourceColor:
+		return oneMinusSrcColor
+	case BlendFactorSourceAlpha:
+		return srcAlpha
+	case BlendFactorOneMinusSourceAlpha:
+		return oneMinusSrcAlpha
+	case BlendFactorDestinationColor:
+		return dstColor
+	case BlendFactorOneMinusDestinationColor:
+		return oneMinusDstColor
+	case BlendFactorDestinationAlpha:
+		return dstAlpha
+	case BlendFactorOneMinusDestinationAlpha:
+		return oneMinusDstAlpha
+	default:
+		panic("not reach")
+	}
+}
+
+// BlendOperation represents an operation to combine the source and the destination.
+type BlendOperation int
+
+const (
+	BlendOperationAdd BlendOperation = iota
+	BlendOperationSubtract
+	BlendOperationReverseSubtract
+)
+
+func (o BlendOperation) equation() equation {
+	switch o {
+	case BlendOperationAdd:
+		return funcAdd
+	case BlendOperationSubtract:
+		return funcSubtract
+	case BlendOperationReverseSubtract:
+		return funcReverseSubtract
+	default:
+		panic("not reach")
+	}
+}
+
+// Blend represents a blending function.
+//
+// The result color is calculated as OperationRGB(source * SourceRGB, destination * DestinationRGB)
+// and OperationAlpha(source * SourceAlpha, destination * DestinationAlpha).
+// Colors are alpha-premultiplied.
+type Blend struct {
+	SourceRGB        BlendFactor
+	SourceAlpha      BlendFactor
+	DestinationRGB   BlendFactor
+	DestinationAlpha BlendFactor
+	OperationRGB     BlendOperation
+	OperationAlpha   BlendOperation
+}
+
+func newBlend(src, dst BlendFactor) Blend {
+	return Blend{
+		SourceRGB:        src,
+		SourceAlpha:      src,
+		DestinationRGB:   dst,
+		DestinationAlpha: dst,
+		OperationRGB:     BlendOperationAdd,
+		OperationAlpha:   BlendOperationAdd,
+	}
+}
+
+// Blend returns the blending function of the composite mode.
+func (mode CompositeMode) Blend() Blend {
 	switch mode {
 	case CompositeModeSourceOver:
-		return one, oneMinusSrcAlpha
+		return newBlend(BlendFactorOne, BlendFactorOneMinusSourceAlpha)
 	case CompositeModeClear:
-		return zero, zero
+		return newBlend(BlendFactorZero, BlendFactorZero)
 	case CompositeModeCopy:
-		return one, zero
+		return newBlend(BlendFactorOne, BlendFactorZero)
 	case CompositeModeDestination:
-		return zero, one
+		return newBlend(BlendFactorZero, BlendFactorOne)
 	case CompositeModeDestinationOver:
-		return oneMinusDstAlpha, one
+		return newBlend(BlendFactorOneMinusDestinationAlpha, BlendFactorOne)
 	case CompositeModeSourceIn:
-		return dstAlpha, zero
+		return newBlend(BlendFactorDestinationAlpha, BlendFactorZero)
 	case CompositeModeDestinationIn:
-		return zero, srcAlpha
+		return newBlend(BlendFactorZero, BlendFactorSourceAlpha)
 	case CompositeModeSourceOut:
-		return oneMinusDstAlpha, zero
+		return newBlend(BlendFactorOneMinusDestinationAlpha, BlendFactorZero)
 	case CompositeModeDestinationOut:
-		return zero, oneMinusSrcAlpha
+		return newBlend(BlendFactorZero, BlendFactorOneMinusSourceAlpha)
 	case CompositeModeSourceAtop:
-		return dstAlpha, oneMinusSrcAlpha
+		return newBlend(BlendFactorDestinationAlpha, BlendFactorOneMinusSourceAlpha)
 	case CompositeModeDestinationAtop:
-		return oneMinusDstAlpha, srcAlpha
+		return newBlend(BlendFactorOneMinusDestinationAlpha, BlendFactorSourceAlpha)
 	case CompositeModeXor:
-		return oneMinusDstAlpha, oneMinusSrcAlpha
+		return newBlend(BlendFactorOneMinusDestinationAlpha, BlendFactorOneMinusSourceAlpha)
 	case CompositeModeLighter:
-		return one, one
+		return newBlend(BlendFactorOne, BlendFactorOne)
+	case CompositeModeMultiply:
+		// c = cs * cd + cd * (1 - as), which is source-over with the source multiplied by the destination.
+		return newBlend(BlendFactorDestinationColor, BlendFactorOneMinusSourceAlpha)
 	default:
 		panic("not reach")
 	}
 }
 
+// UniformType represents the type of a uniform variable of custom shaders.
+type UniformType int
+
+const (
+	UniformFloat UniformType = iota
+	UniformVec2
+	UniformVec3
+	UniformVec4
+	UniformMat2
+	UniformMat3
+	UniformMat4
+)
+
 type DataType int
 
 func (d DataType) SizeInBytes() int {
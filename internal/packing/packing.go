@@ -0,0 +1,159 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package packing offers a rectangle packing algorithm for texture atlases.
+package packing
+
+// Page represents a square region where rectangles are packed.
+//
+// Page is not concurrent-safe.
+type Page struct {
+	root    *Node
+	size    int
+	maxSize int
+}
+
+// Node represents a region in a page.
+type Node struct {
+	x      int
+	y      int
+	width  int
+	height int
+	used   bool
+
+	parent *Node
+	child0 *Node
+	child1 *Node
+}
+
+// NewPage returns a new empty page.
+//
+// size is the initial size of the page, and maxSize is the maximum size that the page can be extended to.
+// Both must be powers of 2.
+func NewPage(size, maxSize int) *Page {
+	if size <= 0 || size&(size-1) != 0 {
+		panic("packing: size must be a power of 2")
+	}
+	if maxSize < size || maxSize&(maxSize-1) != 0 {
+		panic("packing: maxSize must be a power of 2 and not less than size")
+	}
+	return &Page{
+		size:    size,
+		maxSize: maxSize,
+	}
+}
+
+// Size returns the current size of the page.
+func (p *Page) Size() int {
+	return p.size
+}
+
+// IsEmpty reports whether the page has no allocated regions.
+func (p *Page) IsEmpty() bool {
+	return p.root == nil || (!p.root.used && p.root.child0 == nil)
+}
+
+// Region returns the position and the size of the node.
+func (n *Node) Region() (x, y, width, height int) {
+	return n.x, n.y, n.width, n.height
+}
+
+func (n *Node) canFree() bool {
+	if n.used {
+		return false
+	}
+	if n.child0 == nil && n.child1 == nil {
+		return true
+	}
+	return n.child0.canFree() && n.child1.canFree()
+}
+
+func (p *Page) alloc(n *Node, width, height int) *Node {
+	if n.width < width || n.height < height {
+		return nil
+	}
+	if n.used {
+		return nil
+	}
+	if n.child0 == nil && n.child1 == nil {
+		if n.width == width && n.height == height {
+			n.used = true
+			return n
+		}
+		// Split the node along the direction that leaves the bigger remainder.
+		if n.width-width >= n.height-height {
+			n.child0 = &Node{x: n.x, y: n.y, width: width, height: n.height, parent: n}
+			n.child1 = &Node{x: n.x + width, y: n.y, width: n.width - width, height: n.height, parent: n}
+		} else {
+			n.child0 = &Node{x: n.x, y: n.y, width: n.width, height: height, parent: n}
+			n.child1 = &Node{x: n.x, y: n.y + height, width: n.width, height: n.height - height, parent: n}
+		}
+		return p.alloc(n.child0, width, height)
+	}
+	if c := p.alloc(n.child0, width, height); c != nil {
+		return c
+	}
+	return p.alloc(n.child1, width, height)
+}
+
+// Alloc allocates a region of the given size in the page.
+// Alloc returns nil if there is no room for the region.
+func (p *Page) Alloc(width, height int) *Node {
+	if width <= 0 || height <= 0 {
+		panic("packing: width and height must be positive")
+	}
+	if p.root == nil {
+		p.root = &Node{width: p.size, height: p.size}
+	}
+	return p.alloc(p.root, width, height)
+}
+
+// Free releases the region of the node.
+func (p *Page) Free(node *Node) {
+	if node.child0 != nil || node.child1 != nil {
+		panic("packing: can't free a node with children")
+	}
+	node.used = false
+	// Merge the empty siblings so that the bigger region can be allocated later.
+	for n := node.parent; n != nil && n.canFree(); n = n.parent {
+		n.child0 = nil
+		n.child1 = nil
+	}
+}
+
+// Extend doubles the size of the page keeping the allocated regions.
+// Extend returns false if the page can't be extended more.
+func (p *Page) Extend() bool {
+	if p.size >= p.maxSize {
+		return false
+	}
+	s := p.size
+	p.size *= 2
+	if p.IsEmpty() {
+		p.root = &Node{width: p.size, height: p.size}
+		return true
+	}
+
+	// The old root is placed at the left-upper corner.
+	root := &Node{width: 2 * s, height: 2 * s}
+	left := &Node{width: s, height: 2 * s, parent: root}
+	right := &Node{x: s, width: s, height: 2 * s, parent: root}
+	root.child0, root.child1 = left, right
+	old := p.root
+	old.parent = left
+	left.child0 = old
+	left.child1 = &Node{y: s, width: s, height: s, parent: left}
+	p.root = root
+	return true
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package packing_test
+
+import (
+	"image"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/internal/packing"
+)
+
+func region(n *Node) image.Rectangle {
+	x, y, w, h := n.Region()
+	return image.Rect(x, y, x+w, y+h)
+}
+
+func TestPageAlloc(t *testing.T) {
+	p := NewPage(64, 64)
+	var nodes []*Node
+	for i := 0; i < 16; i++ {
+		n := p.Alloc(16, 16)
+		if n == nil {
+			t.Fatalf("Alloc #%d: got: nil", i)
+		}
+		for _, m := range nodes {
+			if region(n).Overlaps(region(m)) {
+				t.Errorf("Alloc #%d: %v overlaps %v", i, region(n), region(m))
+			}
+		}
+		if !region(n).In(image.Rect(0, 0, 64, 64)) {
+			t.Errorf("Alloc #%d: %v is out of the page", i, region(n))
+		}
+		nodes = append(nodes, n)
+	}
+	if n := p.Alloc(1, 1); n != nil {
+		t.Errorf("Alloc on a full page: got: %v, want: nil", region(n))
+	}
+
+	p.Free(nodes[5])
+	if n := p.Alloc(16, 16); n == nil || region(n) != region(nodes[5]) {
+		t.Errorf("Alloc after Free: got: %v, want: %v", n, region(nodes[5]))
+	}
+}
+
+func TestPageFree(t *testing.T) {
+	p := NewPage(64, 64)
+	var nodes []*Node
+	for i := 0; i < 4; i++ {
+		nodes = append(nodes, p.Alloc(32, 32))
+	}
+	for _, n := range nodes {
+		p.Free(n)
+	}
+	if !p.IsEmpty() {
+		t.Errorf("IsEmpty: got: false, want: true")
+	}
+	// The merged region can be allocated as a whole.
+	if n := p.Alloc(64, 64); n == nil {
+		t.Errorf("Alloc(64, 64) after Free: got: nil")
+	}
+}
+
+func TestPageExtend(t *testing.T) {
+	p := NewPage(16, 32)
+	n0 := p.Alloc(16, 16)
+	if n := p.Alloc(8, 8); n != nil {
+		t.Fatalf("Alloc on a full page: got: %v, want: nil", region(n))
+	}
+	if !p.Extend() {
+		t.Fatal("Extend: got: false, want: true")
+	}
+	if got, want := p.Size(), 32; got != want {
+		t.Errorf("Size: got: %d, want: %d", got, want)
+	}
+	if got, want := region(n0), image.Rect(0, 0, 16, 16); got != want {
+		t.Errorf("region after Extend: got: %v, want: %v", got, want)
+	}
+	for i := 0; i < 3; i++ {
+		n := p.Alloc(16, 16)
+		if n == nil {
+			t.Fatalf("Alloc #%d after Extend: got: nil", i)
+		}
+		if region(n).Overlaps(region(n0)) {
+			t.Errorf("Alloc #%d after Extend: %v overlaps %v", i, region(n), region(n0))
+		}
+	}
+	if p.Extend() {
+		t.Errorf("Extend beyond maxSize: got: true, want: false")
+	}
+}
@@ -65,4 +65,16 @@
 // For instance, the game screen passed via the update function is a volatile image.
 // A volatile image doesn't have to record the drawing history.
 // If a source image of DrawImage is a volatile image, the target always becomes stale.
+//
+// * Restoring
+//
+// When context lost is detected at the start of a frame, Restore re-creates the OpenGL state,
+// and then re-creates the textures of all the images in the topological order of the dependencies:
+// an image is restored from its base pixels or color, and then its draw image history is replayed
+// with the already restored source images.
+// A volatile image is restored as a cleared image, and a compressed image is restored from its compressed data.
+// Drawing that is not recorded in the history, like drawing with a palette, makes the target stale
+// so that the target is restored from the pixels read from GPU.
+//
+// Context lost happens only on mobiles and browsers. On desktops, restoring is disabled.
 package restorable
@@ -29,6 +29,20 @@ import (
 // MaxImageSize represents the maximum width/height of an image.
 const MaxImageSize = graphics.MaxImageSize
 
+// PaletteSize represents the number of the colors in a palette.
+const PaletteSize = graphics.PaletteSize
+
+// MaxVerticesNum and MaxIndicesNum represent the maximum numbers of vertices and indices in one draw call.
+const (
+	MaxVerticesNum = graphics.MaxVerticesNum
+	MaxIndicesNum  = graphics.MaxIndicesNum
+)
+
+// VertexSizeInBytes returns the byte size of one vertex.
+func VertexSizeInBytes() int {
+	return graphics.VertexSizeInBytes()
+}
+
 // QuadVertexSizeInBytes returns the byte size of vertices for a quadrilateral.
 func QuadVertexSizeInBytes() int {
 	return graphics.QuadVertexSizeInBytes()
@@ -38,22 +52,63 @@ func QuadVertexSizeInBytes() int {
 type drawImageHistoryItem struct {
 	image    *Image
 	vertices []float32
+	indices  []uint16
 	colorm   affine.ColorM
-	mode     opengl.CompositeMode
+	blend    opengl.Blend
+	filter   opengl.Filter
+
+	// address is the address mode to sample image, and srcRegion is the region of image to repeat.
+	address   opengl.Address
+	srcRegion image.Rectangle
+
+	// dstRegion is the region of the image to render to.
+	dstRegion image.Rectangle
+
+	// shader is a custom shader. If shader is not nil, colorm is not used and image can be nil.
+	shader   *Shader
+	uniforms [][]float32
 }
 
 // canMerge returns a boolean value indicating whether the drawImageHistoryItem d
 // can be merged with the given conditions.
-func (d *drawImageHistoryItem) canMerge(image *Image, colorm *affine.ColorM, mode opengl.CompositeMode) bool {
-	if d.image != image {
+func (d *drawImageHistoryItem) canMerge(img *Image, colorm *affine.ColorM, blend opengl.Blend, filter opengl.Filter, address opengl.Address, srcRegion, dstRegion image.Rectangle, shader *Shader, uniforms [][]float32) bool {
+	if d.image != img {
 		return false
 	}
 	if !d.colorm.Equals(colorm) {
 		return false
 	}
-	if d.mode != mode {
+	if d.blend != blend {
+		return false
+	}
+	if d.filter != filter {
+		return false
+	}
+	if d.address != address {
+		return false
+	}
+	if d.srcRegion != srcRegion {
+		return false
+	}
+	if d.dstRegion != dstRegion {
 		return false
 	}
+	if d.shader != shader {
+		return false
+	}
+	if len(d.uniforms) != len(uniforms) {
+		return false
+	}
+	for i, u := range uniforms {
+		if len(d.uniforms[i]) != len(u) {
+			return false
+		}
+		for j, v := range u {
+			if d.uniforms[i][j] != v {
+				return false
+			}
+		}
+	}
 	return true
 }
 
@@ -79,6 +134,17 @@ type Image struct {
 	// screen indicates whether the image is used as an actual screen.
 	screen bool
 
+	// antialias indicates whether the image is rendered with multisampling.
+	antialias bool
+
+	// hdr indicates whether the image has floating point channels.
+	hdr bool
+
+	// compressedData is the compressed data of the whole texture if the image is compressed.
+	// A compressed image is never rendered to, and is restored from compressedData.
+	compressedData   []uint8
+	compressedFormat opengl.CompressedFormat
+
 	offsetX float64
 	offsetY float64
 }
@@ -86,7 +152,7 @@ type Image struct {
 // NewImage creates an empty image with the given size and filter.
 func NewImage(width, height int, filter opengl.Filter, volatile bool) *Image {
 	i := &Image{
-		image:    graphics.NewImage(width, height, filter),
+		image:    graphics.NewImage(width, height, filter, false),
 		filter:   filter,
 		volatile: volatile,
 	}
@@ -95,6 +161,64 @@ func NewImage(width, height int, filter opengl.Filter, volatile bool) *Image {
 	return i
 }
 
+// NewAntialiasedImage creates an empty image that is rendered with multisampling when available.
+func NewAntialiasedImage(width, height int, filter opengl.Filter, volatile bool) *Image {
+	i := &Image{
+		image:     graphics.NewImage(width, height, filter, true),
+		filter:    filter,
+		antialias: true,
+		volatile:  volatile,
+	}
+	theImages.add(i)
+	runtime.SetFinalizer(i, (*Image).Dispose)
+	return i
+}
+
+// NewHDRImage creates an empty image with 16-bit floating point channels when available.
+//
+// Note that the pixels are restored with 8-bit precision when GL context is lost.
+func NewHDRImage(width, height int, filter opengl.Filter, volatile bool) *Image {
+	i := &Image{
+		image:    graphics.NewHDRImage(width, height, filter),
+		filter:   filter,
+		hdr:      true,
+		volatile: volatile,
+	}
+	theImages.add(i)
+	runtime.SetFinalizer(i, (*Image).Dispose)
+	return i
+}
+
+// NewCompressedImage creates an image from the compressed data of the whole texture.
+//
+// The pixels of a compressed image can't be read: At and ReadPixels return transparent pixels.
+func NewCompressedImage(data []uint8, width, height int, format opengl.CompressedFormat, filter opengl.Filter) *Image {
+	i := &Image{
+		image:            graphics.NewCompressedImage(data, width, height, format, filter),
+		filter:           filter,
+		compressedData:   data,
+		compressedFormat: format,
+	}
+	theImages.add(i)
+	runtime.SetFinalizer(i, (*Image).Dispose)
+	return i
+}
+
+// SizeInBytes returns the estimated size in bytes of the video memory the image uses.
+//
+// SizeInBytes returns 0 when the image is disposed or the image is the screen.
+func (i *Image) SizeInBytes() int {
+	if i.image == nil || i.screen {
+		return 0
+	}
+	return i.image.SizeInBytes()
+}
+
+// IsCompressed reports whether the image is created from compressed data.
+func (i *Image) IsCompressed() bool {
+	return i.compressedData != nil
+}
+
 // NewImageFromImage creates an image with source image.
 func NewImageFromImage(source image.Image, filter opengl.Filter) *Image {
 	size := source.Bounds().Size()
@@ -106,7 +230,7 @@ func NewImageFromImage(source image.Image, filter opengl.Filter) *Image {
 		copy(p[j*w2*4:(j+1)*w2*4], rgbaImg.Pix[j*rgbaImg.Stride:])
 	}
 	i := &Image{
-		image:      graphics.NewImageFromImage(rgbaImg, width, height, filter),
+		image:      graphics.NewImageFromImage(rgbaImg, width, height, filter, false),
 		basePixels: p,
 		filter:     filter,
 	}
@@ -129,6 +253,11 @@ func NewScreenFramebufferImage(width, height int, offsetX, offsetY float64) *Ima
 	return i
 }
 
+// Filter returns the image's default filter.
+func (i *Image) Filter() opengl.Filter {
+	return i.filter
+}
+
 // BasePixelsForTesting returns the image's basePixels for testing.
 func (i *Image) BasePixelsForTesting() []uint8 {
 	return i.basePixels
@@ -182,26 +311,124 @@ func (i *Image) ReplacePixels(pixels []uint8) {
 	i.stale = false
 }
 
+// ReplacePixelsRegion replaces the pixels in the region (x, y, width, height) with the given pixels slice.
+// len(pixels) must be 4 * width * height.
+func (i *Image) ReplacePixelsRegion(pixels []uint8, x, y, width, height int) {
+	theImages.makeStaleIfDependingOn(i)
+	i.image.ReplacePixelsRegion(pixels, x, y, width, height)
+	if i.stale {
+		return
+	}
+	if len(i.drawImageHistory) > 0 {
+		// The region can't be restored on top of the history.
+		// The pixels will be read from GPU instead.
+		i.makeStale()
+		return
+	}
+	w, h := i.image.Size()
+	w2, h2 := math.NextPowerOf2Int(w), math.NextPowerOf2Int(h)
+	if i.basePixels == nil {
+		i.basePixels = make([]uint8, 4*w2*h2)
+		if i.baseColor != (color.RGBA{}) {
+			for idx := 0; idx < len(i.basePixels)/4; idx++ {
+				i.basePixels[4*idx] = i.baseColor.R
+				i.basePixels[4*idx+1] = i.baseColor.G
+				i.basePixels[4*idx+2] = i.baseColor.B
+				i.basePixels[4*idx+3] = i.baseColor.A
+			}
+		}
+		i.baseColor = color.RGBA{}
+	}
+	for j := 0; j < height; j++ {
+		copy(i.basePixels[4*((y+j)*w2+x):], pixels[4*j*width:4*(j+1)*width])
+	}
+}
+
 // DrawImage draws a given image img to the image.
-func (i *Image) DrawImage(img *Image, vertices []float32, colorm *affine.ColorM, mode opengl.CompositeMode) {
+//
+// indices are relative to the first vertex of vertices.
+// filter and address are used to sample img, and srcRegion is the region of img to repeat.
+// srcRegion is ignored when address is AddressDefault.
+// dstRegion is the region of the image to render to. If dstRegion is empty, the whole image is the target.
+func (i *Image) DrawImage(img *Image, vertices []float32, indices []uint16, colorm *affine.ColorM, blend opengl.Blend, filter opengl.Filter, address opengl.Address, srcRegion, dstRegion image.Rectangle) {
 	theImages.makeStaleIfDependingOn(i)
 	if img.stale || img.volatile || !IsRestoringEnabled() {
 		i.makeStale()
 	} else {
-		i.appendDrawImageHistory(img, vertices, colorm, mode)
+		i.appendDrawImageHistory(img, vertices, indices, colorm, blend, filter, address, srcRegion, dstRegion, nil, nil)
+	}
+	i.image.DrawImage(img.image, vertices, indices, colorm, blend, filter, address, srcRegion, dstRegion)
+}
+
+// DrawPalettedImage draws the given image img to the image looking up the colors of img in palette.
+//
+// The red channel of img is regarded as an index of palette, whose width must be PaletteSize.
+// The history doesn't record palettes, so the image becomes stale.
+func (i *Image) DrawPalettedImage(img, palette *Image, vertices []float32, indices []uint16, colorm *affine.ColorM, blend opengl.Blend, address opengl.Address, srcRegion, dstRegion image.Rectangle) {
+	theImages.makeStaleIfDependingOn(i)
+	i.makeStale()
+	i.image.DrawPalettedImage(img.image, palette.image, vertices, indices, colorm, blend, address, srcRegion, dstRegion)
+}
+
+// DrawMaskedImage draws the given image img to the image multiplying the result by the alpha of mask.
+//
+// The mask's pixel at (x, y) + maskOffset masks the destination pixel at (x, y),
+// and the destination pixels outside of maskRegion are fully masked.
+// palette can be nil. If palette is not nil, the colors of img are looked up in palette.
+// The history doesn't record masks, so the image becomes stale.
+func (i *Image) DrawMaskedImage(img, palette, mask *Image, maskOffset image.Point, maskRegion image.Rectangle, vertices []float32, indices []uint16, colorm *affine.ColorM, blend opengl.Blend, filter opengl.Filter, address opengl.Address, srcRegion, dstRegion image.Rectangle) {
+	theImages.makeStaleIfDependingOn(i)
+	i.makeStale()
+	var p *graphics.Image
+	if palette != nil {
+		p = palette.image
+	}
+	i.image.DrawMaskedImage(img.image, p, mask.image, maskOffset, maskRegion, vertices, indices, colorm, blend, filter, address, srcRegion, dstRegion)
+}
+
+// DrawShader draws the given images imgs to the image with the custom shader.
+//
+// imgs can be nil, and offsets are the positions in pixels of imgs in their images.
+// uniforms are the values of the shader's uniform variables in the declared order.
+//
+// The history records only the first image, so the image becomes stale when the other images are used.
+func (i *Image) DrawShader(imgs [ShaderImageNum]*Image, offsets [ShaderImageNum]image.Point, vertices []float32, indices []uint16, shader *Shader, uniforms [][]float32, blend opengl.Blend, filter opengl.Filter, dstRegion image.Rectangle) {
+	theImages.makeStaleIfDependingOn(i)
+	var srcs [ShaderImageNum]*graphics.Image
+	multiple := false
+	for idx, img := range imgs {
+		if img == nil {
+			continue
+		}
+		srcs[idx] = img.image
+		if idx > 0 {
+			multiple = true
+		}
+	}
+	img := imgs[0]
+	if multiple || (img != nil && (img.stale || img.volatile)) || !IsRestoringEnabled() {
+		i.makeStale()
+	} else {
+		i.appendDrawImageHistory(img, vertices, indices, &affine.ColorM{}, blend, filter, opengl.AddressDefault, image.Rectangle{}, dstRegion, shader, uniforms)
 	}
-	i.image.DrawImage(img.image, vertices, colorm, mode)
+	i.image.DrawShader(srcs, offsets, vertices, indices, shader.shader, uniforms, blend, filter, dstRegion)
 }
 
 // appendDrawImageHistory appends a draw-image history item to the image.
-func (i *Image) appendDrawImageHistory(image *Image, vertices []float32, colorm *affine.ColorM, mode opengl.CompositeMode) {
+func (i *Image) appendDrawImageHistory(img *Image, vertices []float32, indices []uint16, colorm *affine.ColorM, blend opengl.Blend, filter opengl.Filter, address opengl.Address, srcRegion, dstRegion image.Rectangle, shader *Shader, uniforms [][]float32) {
 	if i.stale || i.volatile {
 		return
 	}
 	if len(i.drawImageHistory) > 0 {
 		last := i.drawImageHistory[len(i.drawImageHistory)-1]
-		if last.canMerge(image, colorm, mode) {
+		n := len(last.vertices) * 4 / VertexSizeInBytes()
+		if last.canMerge(img, colorm, blend, filter, address, srcRegion, dstRegion, shader, uniforms) &&
+			n+len(vertices)*4/VertexSizeInBytes() <= MaxVerticesNum &&
+			len(last.indices)+len(indices) <= MaxIndicesNum {
 			last.vertices = append(last.vertices, vertices...)
+			for _, idx := range indices {
+				last.indices = append(last.indices, idx+uint16(n))
+			}
 			return
 		}
 	}
@@ -213,10 +440,17 @@ func (i *Image) appendDrawImageHistory(image *Image, vertices []float32, colorm
 	// All images must be resolved and not stale each after frame.
 	// So we don't have to care if image is stale or not here.
 	item := &drawImageHistoryItem{
-		image:    image,
-		vertices: vertices,
-		colorm:   *colorm,
-		mode:     mode,
+		image:     img,
+		vertices:  vertices,
+		indices:   append([]uint16{}, indices...),
+		colorm:    *colorm,
+		blend:     blend,
+		filter:    filter,
+		address:   address,
+		srcRegion: srcRegion,
+		dstRegion: dstRegion,
+		shader:    shader,
+		uniforms:  uniforms,
 	}
 	i.drawImageHistory = append(i.drawImageHistory, item)
 }
@@ -230,6 +464,9 @@ func (i *Image) At(x, y int) (color.RGBA, error) {
 	if x < 0 || y < 0 || w2 <= x || h2 <= y {
 		return color.RGBA{}, nil
 	}
+	if i.IsCompressed() {
+		return color.RGBA{}, nil
+	}
 	if i.basePixels == nil || i.drawImageHistory != nil || i.stale {
 		if err := i.readPixelsFromGPU(i.image); err != nil {
 			return color.RGBA{}, err
@@ -240,6 +477,33 @@ func (i *Image) At(x, y int) (color.RGBA, error) {
 	return color.RGBA{r, g, b, a}, nil
 }
 
+// ReadPixels copies the pixels in the region (x, y, width, height) into pixels.
+// len(pixels) must be 4 * width * height, and the region must be inside the image.
+//
+// The pixels are read from GPU at most once until the image is modified.
+//
+// Note that this must not be called until context is available.
+func (i *Image) ReadPixels(pixels []uint8, x, y, width, height int) error {
+	if i.IsCompressed() {
+		for idx := range pixels {
+			pixels[idx] = 0
+		}
+		return nil
+	}
+	if i.basePixels == nil || i.drawImageHistory != nil || i.stale {
+		if err := i.readPixelsFromGPU(i.image); err != nil {
+			return err
+		}
+	}
+	w, _ := i.image.Size()
+	w2 := math.NextPowerOf2Int(w)
+	for j := 0; j < height; j++ {
+		idx := 4*x + 4*(y+j)*w2
+		copy(pixels[4*j*width:4*(j+1)*width], i.basePixels[idx:idx+4*width])
+	}
+	return nil
+}
+
 // makeStaleIfDependingOn makes the image stale if the image depends on target.
 func (i *Image) makeStaleIfDependingOn(target *Image) {
 	if i.stale {
@@ -291,6 +555,9 @@ func (i *Image) dependsOn(target *Image) bool {
 func (i *Image) dependingImages() map[*Image]struct{} {
 	r := map[*Image]struct{}{}
 	for _, c := range i.drawImageHistory {
+		if c.image == nil {
+			continue
+		}
 		r[c.image] = struct{}{}
 	}
 	return r
@@ -318,13 +585,21 @@ func (i *Image) restore() error {
 		return nil
 	}
 	if i.volatile {
-		i.image = graphics.NewImage(w, h, i.filter)
+		if i.hdr {
+			i.image = graphics.NewHDRImage(w, h, i.filter)
+		} else {
+			i.image = graphics.NewImage(w, h, i.filter, i.antialias)
+		}
 		i.basePixels = nil
 		i.baseColor = color.RGBA{}
 		i.drawImageHistory = nil
 		i.stale = false
 		return nil
 	}
+	if i.IsCompressed() {
+		i.image = graphics.NewCompressedImage(i.compressedData, w, h, i.compressedFormat, i.filter)
+		return nil
+	}
 	if i.stale {
 		// TODO: panic here?
 		return errors.New("restorable: pixels must not be stale when restoring")
@@ -336,7 +611,13 @@ func (i *Image) restore() error {
 			copy(img.Pix[j*img.Stride:], i.basePixels[j*w2*4:(j+1)*w2*4])
 		}
 	}
-	gimg := graphics.NewImageFromImage(img, w, h, i.filter)
+	var gimg *graphics.Image
+	if i.hdr {
+		gimg = graphics.NewHDRImage(w, h, i.filter)
+		gimg.ReplacePixels(img.Pix)
+	} else {
+		gimg = graphics.NewImageFromImage(img, w, h, i.filter, i.antialias)
+	}
 	if i.baseColor != (color.RGBA{}) {
 		if i.basePixels != nil {
 			panic("not reached")
@@ -345,10 +626,19 @@ func (i *Image) restore() error {
 	}
 	for _, c := range i.drawImageHistory {
 		// All dependencies must be already resolved.
-		if c.image.hasDependency() {
+		if c.image != nil && c.image.hasDependency() {
 			panic("not reached")
 		}
-		gimg.DrawImage(c.image.image, c.vertices, &c.colorm, c.mode)
+		if c.shader != nil {
+			// Only the first image is recorded, and then its offset doesn't matter.
+			var srcs [ShaderImageNum]*graphics.Image
+			if c.image != nil {
+				srcs[0] = c.image.image
+			}
+			gimg.DrawShader(srcs, [ShaderImageNum]image.Point{}, c.vertices, c.indices, c.shader.shader, c.uniforms, c.blend, c.filter, c.dstRegion)
+			continue
+		}
+		gimg.DrawImage(c.image.image, c.vertices, c.indices, &c.colorm, c.blend, c.filter, c.address, c.srcRegion, c.dstRegion)
 	}
 	i.image = gimg
 
@@ -370,6 +660,7 @@ func (i *Image) Dispose() {
 	theImages.makeStaleIfDependingOn(i)
 	i.image.Dispose()
 	i.image = nil
+	i.compressedData = nil
 	i.basePixels = nil
 	i.baseColor = color.RGBA{}
 	i.drawImageHistory = nil
@@ -77,6 +77,22 @@ func ClearVolatileImages() {
 	theImages.clearVolatileImages()
 }
 
+// ReadMemoryStats returns the number of the images except for the screen and
+// the estimated total size in bytes of their video memory.
+func ReadMemoryStats() (count, sizeInBytes int) {
+	return theImages.readMemoryStats()
+}
+
+// SetCaptureEnabled sets whether the executed drawing commands are recorded for debugging.
+func SetCaptureEnabled(enabled bool) {
+	graphics.SetCaptureEnabled(enabled)
+}
+
+// TakeCapturedCommands returns the drawing commands recorded since the last call and clears them.
+func TakeCapturedCommands() []graphics.CapturedCommand {
+	return graphics.TakeCapturedCommands()
+}
+
 // add adds img to the images.
 func (i *images) add(img *Image) {
 	i.m.Lock()
@@ -91,6 +107,21 @@ func (i *images) remove(img *Image) {
 	delete(i.images, img)
 }
 
+// readMemoryStats returns the number of the images and their total size in bytes.
+func (i *images) readMemoryStats() (int, int) {
+	i.m.Lock()
+	defer i.m.Unlock()
+	count, size := 0, 0
+	for img := range i.images {
+		if img.screen {
+			continue
+		}
+		count++
+		size += img.SizeInBytes()
+	}
+	return count, size
+}
+
 // resolveStaleImages resolves stale images.
 func (i *images) resolveStaleImages() error {
 	i.m.Lock()
@@ -70,19 +70,20 @@ func TestRestore(t *testing.T) {
 }
 
 func vertices(sw, sh int, x, y int) []float32 {
-	const a, b, c, d = 1, 0, 0, 1
 	swf := float32(sw)
 	shf := float32(sh)
 	tx := float32(x)
 	ty := float32(y)
 	return []float32{
-		0, 0, 0, 0, a, b, c, d, tx, ty,
-		0, shf, 0, 1, a, b, c, d, tx, ty,
-		swf, 0, 1, 0, a, b, c, d, tx, ty,
-		swf, shf, 1, 1, a, b, c, d, tx, ty,
+		tx, ty, 0, 0, 1, 1, 1, 1,
+		tx, shf + ty, 0, 1, 1, 1, 1, 1,
+		swf + tx, ty, 1, 0, 1, 1, 1, 1,
+		swf + tx, shf + ty, 1, 1, 1, 1, 1, 1,
 	}
 }
 
+var quadIndices = []uint16{0, 1, 2, 1, 2, 3}
+
 func TestRestoreChain(t *testing.T) {
 	const num = 10
 	imgs := []*Image{}
@@ -99,7 +100,7 @@ func TestRestoreChain(t *testing.T) {
 	clr := color.RGBA{0x00, 0x00, 0x00, 0xff}
 	imgs[0].Fill(clr.R, clr.G, clr.B, clr.A)
 	for i := 0; i < num-1; i++ {
-		imgs[i+1].DrawImage(imgs[i], vertices(1, 1, 0, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
+		imgs[i+1].DrawImage(imgs[i], vertices(1, 1, 0, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
 	}
 	if err := ResolveStaleImages(); err != nil {
 		t.Fatal(err)
@@ -134,10 +135,10 @@ func TestRestoreOverrideSource(t *testing.T) {
 	clr0 := color.RGBA{0x00, 0x00, 0x00, 0xff}
 	clr1 := color.RGBA{0x00, 0x00, 0x01, 0xff}
 	img1.Fill(clr0.R, clr0.G, clr0.B, clr0.A)
-	img2.DrawImage(img1, vertices(1, 1, 0, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
-	img3.DrawImage(img2, vertices(1, 1, 0, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
+	img2.DrawImage(img1, vertices(1, 1, 0, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	img3.DrawImage(img2, vertices(1, 1, 0, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
 	img0.Fill(clr1.R, clr1.G, clr1.B, clr1.A)
-	img1.DrawImage(img0, vertices(1, 1, 0, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
+	img1.DrawImage(img0, vertices(1, 1, 0, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
 	if err := ResolveStaleImages(); err != nil {
 		t.Fatal(err)
 	}
@@ -215,15 +216,15 @@ func TestRestoreComplexGraph(t *testing.T) {
 		img1.Dispose()
 		img0.Dispose()
 	}()
-	img3.DrawImage(img0, vertices(4, 1, 0, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
-	img3.DrawImage(img1, vertices(4, 1, 1, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
-	img4.DrawImage(img1, vertices(4, 1, 1, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
-	img4.DrawImage(img2, vertices(4, 1, 2, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
-	img5.DrawImage(img3, vertices(4, 1, 0, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
-	img6.DrawImage(img3, vertices(4, 1, 0, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
-	img6.DrawImage(img4, vertices(4, 1, 1, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
-	img7.DrawImage(img2, vertices(4, 1, 0, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
-	img7.DrawImage(img3, vertices(4, 1, 2, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
+	img3.DrawImage(img0, vertices(4, 1, 0, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	img3.DrawImage(img1, vertices(4, 1, 1, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	img4.DrawImage(img1, vertices(4, 1, 1, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	img4.DrawImage(img2, vertices(4, 1, 2, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	img5.DrawImage(img3, vertices(4, 1, 0, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	img6.DrawImage(img3, vertices(4, 1, 0, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	img6.DrawImage(img4, vertices(4, 1, 1, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	img7.DrawImage(img2, vertices(4, 1, 0, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	img7.DrawImage(img3, vertices(4, 1, 2, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
 	if err := ResolveStaleImages(); err != nil {
 		t.Fatal(err)
 	}
@@ -303,8 +304,8 @@ func TestRestoreRecursive(t *testing.T) {
 		img1.Dispose()
 		img0.Dispose()
 	}()
-	img1.DrawImage(img0, vertices(4, 1, 1, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
-	img0.DrawImage(img1, vertices(4, 1, 1, 0), &affine.ColorM{}, opengl.CompositeModeSourceOver)
+	img1.DrawImage(img0, vertices(4, 1, 1, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	img0.DrawImage(img1, vertices(4, 1, 1, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
 	if err := ResolveStaleImages(); err != nil {
 		t.Fatal(err)
 	}
@@ -341,4 +342,83 @@ func TestRestoreRecursive(t *testing.T) {
 	}
 }
 
-// TODO: How about volatile/screen images?
+func TestRestoreReplacePixelsRegion(t *testing.T) {
+	img := NewImage(4, 1, opengl.Nearest, false)
+	img.Fill(0xff, 0, 0, 0xff)
+	defer func() {
+		img.Dispose()
+	}()
+	img.ReplacePixelsRegion([]uint8{0, 0, 0xff, 0xff, 0, 0, 0xff, 0xff}, 1, 0, 2, 1)
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	for i, want := range []color.RGBA{red, blue, blue, red} {
+		got := uint8SliceToColor(img.BasePixelsForTesting(), i)
+		if got != want {
+			t.Errorf("[%d]: got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestRestoreVolatileSource(t *testing.T) {
+	src := NewImage(1, 1, opengl.Nearest, true)
+	dst := NewImage(1, 1, opengl.Nearest, false)
+	dst.Fill(0, 0, 0, 0)
+	defer func() {
+		src.Dispose()
+		dst.Dispose()
+	}()
+	src.Fill(0xff, 0, 0, 0xff)
+	// dst becomes stale since src is not restored with its pixels.
+	dst.DrawImage(src, vertices(1, 1, 0, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeSourceOver.Blend(), opengl.Nearest, opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+	want := color.RGBA{0xff, 0, 0, 0xff}
+	got := uint8SliceToColor(dst.BasePixelsForTesting(), 0)
+	if got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if src.BasePixelsForTesting() != nil {
+		t.Errorf("the base pixels of a volatile image must be nil")
+	}
+}
+
+func TestRestorePalettedImage(t *testing.T) {
+	src := NewImage(1, 1, opengl.Nearest, false)
+	palette := NewImage(PaletteSize, 1, opengl.Nearest, false)
+	dst := NewImage(1, 1, opengl.Nearest, false)
+	dst.Fill(0, 0, 0, 0)
+	defer func() {
+		src.Dispose()
+		palette.Dispose()
+		dst.Dispose()
+	}()
+	// The index 1 is blue.
+	src.ReplacePixels([]uint8{1, 1, 1, 0xff})
+	pix := make([]uint8, 4*PaletteSize)
+	copy(pix[4:], []uint8{0, 0, 0xff, 0xff})
+	palette.ReplacePixels(pix)
+	dst.DrawPalettedImage(src, palette, vertices(1, 1, 0, 0), quadIndices, &affine.ColorM{}, opengl.CompositeModeCopy.Blend(), opengl.AddressDefault, image.Rectangle{}, image.Rectangle{})
+	if err := ResolveStaleImages(); err != nil {
+		t.Fatal(err)
+	}
+	if err := Restore(); err != nil {
+		t.Fatal(err)
+	}
+	want := color.RGBA{0, 0, 0xff, 0xff}
+	got := uint8SliceToColor(dst.BasePixelsForTesting(), 0)
+	if got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TODO: How about screen images?
@@ -0,0 +1,48 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restorable
+
+import (
+	"github.com/hajimehoshi/ebiten/internal/graphics"
+	eshader "github.com/hajimehoshi/ebiten/internal/shader"
+)
+
+// ShaderImageNum is the number of the source images of a custom shader.
+const ShaderImageNum = graphics.ShaderImageNum
+
+// Shader represents a custom shader.
+//
+// Unlike images, a shader doesn't have to be restored explicitly:
+// the OpenGL program is re-created from the compiled program when it is used after the context is lost.
+type Shader struct {
+	shader *graphics.Shader
+}
+
+// NewShader creates a shader from the compiled program.
+func NewShader(program *eshader.Program) *Shader {
+	return &Shader{
+		shader: graphics.NewShader(program),
+	}
+}
+
+// Program returns the compiled program of the shader.
+func (s *Shader) Program() *eshader.Program {
+	return s.shader.Program()
+}
+
+// Dispose disposes the shader.
+func (s *Shader) Dispose() {
+	s.shader.Dispose()
+}
@@ -0,0 +1,151 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shader
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+type builtinFunc func(c *compiler, e *ast.CallExpr, args []value) (value, error)
+
+type resultKind int
+
+const (
+	resultSame resultKind = iota
+	resultFloat
+)
+
+// genericFunc returns a built-in function taking float or vector arguments.
+//
+// argsNums is the allowed numbers of the arguments.
+// If scalar is true, float arguments can be mixed with vector arguments.
+func genericFunc(name string, argsNums []int, result resultKind, scalar bool) builtinFunc {
+	return func(c *compiler, e *ast.CallExpr, args []value) (value, error) {
+		ok := false
+		for _, n := range argsNums {
+			if len(args) == n {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return value{}, c.errorf(e, "wrong number of arguments in call to %s: %d", name, len(args))
+		}
+		t := Float
+		for i := range args {
+			if args[i].typ.isUntyped() {
+				code, err := c.convert(e.Args[i], args[i], Float)
+				if err != nil {
+					return value{}, err
+				}
+				args[i] = value{code: code, typ: Float}
+			}
+			a := args[i].typ
+			if a != Float && !a.isVec() {
+				return value{}, c.errorf(e.Args[i], "cannot use %s (type %s) as float or vector in call to %s", nodeString(e.Args[i]), a.name(), name)
+			}
+			if a.isVec() {
+				if t != Float && t != a {
+					return value{}, c.errorf(e, "mismatched types %s and %s in call to %s", t, a, name)
+				}
+				t = a
+			}
+		}
+		if !scalar && t != Float {
+			for i, a := range args {
+				if a.typ != t {
+					return value{}, c.errorf(e.Args[i], "mismatched types %s and %s in call to %s", t, a.typ, name)
+				}
+			}
+		}
+		if result == resultFloat {
+			t = Float
+		}
+		return value{code: fmt.Sprintf("%s(%s)", name, joinCodes(args)), typ: t}, nil
+	}
+}
+
+var builtinFuncs = map[string]builtinFunc{}
+
+func init() {
+	for _, name := range []string{
+		"sin", "cos", "tan", "asin", "acos",
+		"exp", "log", "exp2", "log2", "sqrt", "inversesqrt",
+		"abs", "sign", "floor", "ceil", "fract",
+		"normalize", "radians", "degrees",
+	} {
+		builtinFuncs[name] = genericFunc(name, []int{1}, resultSame, false)
+	}
+	builtinFuncs["atan"] = genericFunc("atan", []int{1, 2}, resultSame, false)
+	builtinFuncs["pow"] = genericFunc("pow", []int{2}, resultSame, false)
+	builtinFuncs["reflect"] = genericFunc("reflect", []int{2}, resultSame, false)
+	builtinFuncs["faceforward"] = genericFunc("faceforward", []int{3}, resultSame, false)
+	for _, name := range []string{"mod", "min", "max", "step"} {
+		builtinFuncs[name] = genericFunc(name, []int{2}, resultSame, true)
+	}
+	for _, name := range []string{"clamp", "mix", "smoothstep", "refract"} {
+		builtinFuncs[name] = genericFunc(name, []int{3}, resultSame, true)
+	}
+	builtinFuncs["length"] = genericFunc("length", []int{1}, resultFloat, false)
+	builtinFuncs["distance"] = genericFunc("distance", []int{2}, resultFloat, false)
+	builtinFuncs["dot"] = genericFunc("dot", []int{2}, resultFloat, false)
+
+	builtinFuncs["cross"] = func(c *compiler, e *ast.CallExpr, args []value) (value, error) {
+		if len(args) != 2 || args[0].typ != Vec3 || args[1].typ != Vec3 {
+			return value{}, c.errorf(e, "cross takes two vec3 arguments")
+		}
+		return value{code: fmt.Sprintf("cross(%s)", joinCodes(args)), typ: Vec3}, nil
+	}
+
+	// imageSrc0At returns the color of the source image at the given texel position.
+	builtinFuncs["imageSrc0At"] = func(c *compiler, e *ast.CallExpr, args []value) (value, error) {
+		if len(args) != 1 || args[0].typ != Vec2 {
+			return value{}, c.errorf(e, "imageSrc0At takes one vec2 argument")
+		}
+		return value{code: fmt.Sprintf("texture2D(%s, %s)", TextureName, args[0].code), typ: Vec4}, nil
+	}
+
+	// imageSrc1At and later return the color of the other source images at the given texel position of the first image.
+	// The texel position is converted since the images can be at different positions in different sized textures.
+	for i := 1; i < TextureNum; i++ {
+		i := i
+		name := fmt.Sprintf("imageSrc%dAt", i)
+		builtinFuncs[name] = func(c *compiler, e *ast.CallExpr, args []value) (value, error) {
+			if len(args) != 1 || args[0].typ != Vec2 {
+				return value{}, c.errorf(e, "%s takes one vec2 argument", name)
+			}
+			pos := fmt.Sprintf("((%s) * %s - %s + %s) / %s", args[0].code, TextureSizeName, TextureOffsetNameAt(0), TextureOffsetNameAt(i), TextureSizeNameAt(i))
+			return value{code: fmt.Sprintf("texture2D(%s, %s)", TextureNameAt(i), pos), typ: Vec4}, nil
+		}
+	}
+
+	// imageSrcTextureSize returns the size of the source texture.
+	// The texture size can be bigger than the image size.
+	builtinFuncs["imageSrcTextureSize"] = func(c *compiler, e *ast.CallExpr, args []value) (value, error) {
+		if len(args) != 0 {
+			return value{}, c.errorf(e, "imageSrcTextureSize takes no arguments")
+		}
+		return value{code: TextureSizeName, typ: Vec2}, nil
+	}
+
+	// discard discards the current fragment.
+	builtinFuncs["discard"] = func(c *compiler, e *ast.CallExpr, args []value) (value, error) {
+		if len(args) != 0 {
+			return value{}, c.errorf(e, "discard takes no arguments")
+		}
+		return value{code: "discard", typ: None}, nil
+	}
+}
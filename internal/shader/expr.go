@@ -0,0 +1,434 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shader
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// value is a compiled expression.
+type value struct {
+	code string
+	typ  Type
+
+	// literal reports whether the code is a literal of a number.
+	literal bool
+
+	// variable reports whether the value is a local variable that can be assigned.
+	variable bool
+}
+
+// convert returns the code of v converted to the type t.
+// Only untyped constants can be converted implicitly.
+func (c *compiler) convert(n ast.Node, v value, t Type) (string, error) {
+	if v.typ == t {
+		return v.code, nil
+	}
+	switch {
+	case v.typ == untypedInt && t == Int:
+		return v.code, nil
+	case v.typ == untypedInt && t == Float:
+		if v.literal {
+			return v.code + ".0", nil
+		}
+		return "float(" + v.code + ")", nil
+	case v.typ == untypedFloat && t == Float:
+		return v.code, nil
+	}
+	return "", c.errorf(n, "cannot use %s (type %s) as type %s", nodeString(n), v.typ.name(), t.name())
+}
+
+// name returns the type name in the shading language.
+func (t Type) name() string {
+	switch t {
+	case untypedInt:
+		return "untyped int"
+	case untypedFloat:
+		return "untyped float"
+	case None:
+		return "no value"
+	}
+	return t.String()
+}
+
+func (c *compiler) expr(e ast.Expr) (value, error) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			if _, err := strconv.ParseInt(e.Value, 0, 32); err != nil {
+				return value{}, c.errorf(e, "invalid integer constant: %s", e.Value)
+			}
+			return value{code: e.Value, typ: untypedInt, literal: true}, nil
+		case token.FLOAT:
+			if strings.HasPrefix(e.Value, "0x") || strings.HasPrefix(e.Value, "0X") || strings.Contains(e.Value, "_") {
+				return value{}, c.errorf(e, "unsupported float constant: %s", e.Value)
+			}
+			code := e.Value
+			if strings.HasPrefix(code, ".") {
+				code = "0" + code
+			}
+			return value{code: code, typ: untypedFloat, literal: true}, nil
+		}
+		return value{}, c.errorf(e, "unsupported literal: %s", e.Value)
+	case *ast.Ident:
+		switch e.Name {
+		case "true", "false":
+			return value{code: e.Name, typ: Bool}, nil
+		}
+		v, ok := c.lookup(e.Name)
+		if !ok {
+			return value{}, c.errorf(e, "undefined: %s", e.Name)
+		}
+		return v, nil
+	case *ast.ParenExpr:
+		v, err := c.expr(e.X)
+		if err != nil {
+			return value{}, err
+		}
+		return value{code: "(" + v.code + ")", typ: v.typ}, nil
+	case *ast.UnaryExpr:
+		return c.unaryExpr(e)
+	case *ast.BinaryExpr:
+		return c.binaryExpr(e)
+	case *ast.CallExpr:
+		return c.callExpr(e)
+	case *ast.SelectorExpr:
+		return c.selectorExpr(e)
+	case *ast.IndexExpr:
+		return c.indexExpr(e)
+	}
+	return value{}, c.errorf(e, "unsupported expression: %s", nodeString(e))
+}
+
+func (c *compiler) unaryExpr(e *ast.UnaryExpr) (value, error) {
+	v, err := c.expr(e.X)
+	if err != nil {
+		return value{}, err
+	}
+	switch e.Op {
+	case token.ADD, token.SUB:
+		if !v.typ.isNumeric() {
+			return value{}, c.errorf(e, "invalid operation: operator %s not defined on %s", e.Op, v.typ.name())
+		}
+		if e.Op == token.ADD {
+			return value{code: v.code, typ: v.typ, literal: v.literal}, nil
+		}
+		return value{code: "-" + v.code, typ: v.typ, literal: v.literal}, nil
+	case token.NOT:
+		if v.typ != Bool {
+			return value{}, c.errorf(e, "invalid operation: operator ! not defined on %s", v.typ.name())
+		}
+		return value{code: "!" + v.code, typ: Bool}, nil
+	}
+	return value{}, c.errorf(e, "unsupported operator: %s", e.Op)
+}
+
+// adapt converts v to a typed value if v is an untyped constant and other is typed.
+func (c *compiler) adapt(n ast.Node, v value, other Type) (value, error) {
+	if !v.typ.isUntyped() || other.isUntyped() {
+		return v, nil
+	}
+	t := Float
+	if other == Int {
+		t = Int
+	}
+	code, err := c.convert(n, v, t)
+	if err != nil {
+		return value{}, err
+	}
+	return value{code: code, typ: t}, nil
+}
+
+func (c *compiler) binaryExpr(e *ast.BinaryExpr) (value, error) {
+	l, err := c.expr(e.X)
+	if err != nil {
+		return value{}, err
+	}
+	r, err := c.expr(e.Y)
+	if err != nil {
+		return value{}, err
+	}
+	t, err := c.binaryOp(e, e.Op, &l, &r)
+	if err != nil {
+		return value{}, err
+	}
+	return value{code: l.code + " " + e.Op.String() + " " + r.code, typ: t}, nil
+}
+
+// binaryOp returns the result type of the binary operation.
+// binaryOp converts the untyped operands to the appropriate types.
+func (c *compiler) binaryOp(n ast.Node, op token.Token, l, r *value) (Type, error) {
+	var err error
+	if *l, err = c.adapt(n, *l, r.typ); err != nil {
+		return None, err
+	}
+	if *r, err = c.adapt(n, *r, l.typ); err != nil {
+		return None, err
+	}
+	lt, rt := l.typ, r.typ
+
+	mismatched := func() (Type, error) {
+		return None, c.errorf(n, "invalid operation: mismatched types %s and %s", lt.name(), rt.name())
+	}
+
+	switch op {
+	case token.ADD, token.SUB, token.MUL, token.QUO:
+		if !lt.isNumeric() || !rt.isNumeric() {
+			return mismatched()
+		}
+		if lt.isUntyped() && rt.isUntyped() {
+			if lt == untypedFloat || rt == untypedFloat {
+				if op == token.QUO || lt != rt {
+					// Make the division a float division.
+					if l.typ == untypedInt {
+						l.code, _ = c.convert(n, *l, Float)
+					}
+					if r.typ == untypedInt {
+						r.code, _ = c.convert(n, *r, Float)
+					}
+				}
+				return untypedFloat, nil
+			}
+			return untypedInt, nil
+		}
+		if lt == rt {
+			return lt, nil
+		}
+		// A float scalar can be an operand with a vector or a matrix.
+		if lt == Float && (rt.isVec() || rt.isMat()) {
+			return rt, nil
+		}
+		if rt == Float && (lt.isVec() || lt.isMat()) {
+			return lt, nil
+		}
+		if op == token.MUL {
+			if lt.isMat() && rt.isVec() && lt.dim() == rt.dim() {
+				return rt, nil
+			}
+			if lt.isVec() && rt.isMat() && lt.dim() == rt.dim() {
+				return lt, nil
+			}
+		}
+		return mismatched()
+	case token.REM:
+		return None, c.errorf(n, "operator %% is not supported; use mod instead")
+	case token.LSS, token.GTR, token.LEQ, token.GEQ:
+		if lt != rt || !(lt == Int || lt.isFloatLike()) {
+			return mismatched()
+		}
+		return Bool, nil
+	case token.EQL, token.NEQ:
+		if lt != rt {
+			return mismatched()
+		}
+		return Bool, nil
+	case token.LAND, token.LOR:
+		if lt != Bool || rt != Bool {
+			return mismatched()
+		}
+		return Bool, nil
+	}
+	return None, c.errorf(n, "unsupported operator: %s", op)
+}
+
+func (c *compiler) args(args []ast.Expr) ([]value, error) {
+	vs := make([]value, 0, len(args))
+	for _, a := range args {
+		v, err := c.expr(a)
+		if err != nil {
+			return nil, err
+		}
+		if v.typ == None {
+			return nil, c.errorf(a, "%s used as value", nodeString(a))
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+func joinCodes(vs []value) string {
+	codes := make([]string, len(vs))
+	for i, v := range vs {
+		codes[i] = v.code
+	}
+	return strings.Join(codes, ", ")
+}
+
+func (c *compiler) callExpr(e *ast.CallExpr) (value, error) {
+	f, ok := e.Fun.(*ast.Ident)
+	if !ok {
+		return value{}, c.errorf(e, "unsupported function call: %s", nodeString(e))
+	}
+	if e.Ellipsis != token.NoPos {
+		return value{}, c.errorf(e, "variadic call is not supported")
+	}
+	name := f.Name
+	args, err := c.args(e.Args)
+	if err != nil {
+		return value{}, err
+	}
+
+	if t, ok := typeNames[name]; ok {
+		return c.construct(e, t, args)
+	}
+	if b, ok := builtinFuncs[name]; ok {
+		return b(c, e, args)
+	}
+	fn, ok := c.funcs[name]
+	if !ok {
+		if _, ok := c.lookup(name); ok {
+			return value{}, c.errorf(e, "cannot call non-function %s", name)
+		}
+		return value{}, c.errorf(e, "undefined: %s", name)
+	}
+	if name == "Fragment" {
+		return value{}, c.errorf(e, "Fragment can't be called")
+	}
+	if len(args) != len(fn.params) {
+		return value{}, c.errorf(e, "wrong number of arguments in call to %s: %d for %d", name, len(args), len(fn.params))
+	}
+	for i := range args {
+		code, err := c.convert(e.Args[i], args[i], fn.params[i])
+		if err != nil {
+			return value{}, err
+		}
+		args[i].code = code
+	}
+	return value{code: fmt.Sprintf("F_%s(%s)", name, joinCodes(args)), typ: fn.result}, nil
+}
+
+// construct returns a type conversion or a vector/matrix constructor.
+func (c *compiler) construct(e *ast.CallExpr, t Type, args []value) (value, error) {
+	if len(args) == 0 {
+		return value{}, c.errorf(e, "missing argument in conversion to %s", t)
+	}
+	switch t {
+	case Bool, Int, Float:
+		if len(args) != 1 {
+			return value{}, c.errorf(e, "too many arguments in conversion to %s", t)
+		}
+		a := args[0]
+		if a.typ == t {
+			return a, nil
+		}
+		if a.typ.isUntyped() && (t == Int || t == Float) {
+			code, err := c.convert(e.Args[0], a, t)
+			if err != nil && t == Int {
+				// An untyped float constant can be truncated explicitly.
+				code, err = "int("+a.code+")", nil
+			}
+			if err != nil {
+				return value{}, err
+			}
+			return value{code: code, typ: t}, nil
+		}
+		if !(a.typ == Bool || a.typ == Int || a.typ == Float) {
+			return value{}, c.errorf(e, "cannot convert %s (type %s) to type %s", nodeString(e.Args[0]), a.typ.name(), t)
+		}
+		return value{code: fmt.Sprintf("%s(%s)", t, a.code), typ: t}, nil
+	}
+
+	n := 0
+	for i := range args {
+		if args[i].typ.isUntyped() {
+			code, err := c.convert(e.Args[i], args[i], Float)
+			if err != nil {
+				return value{}, err
+			}
+			args[i] = value{code: code, typ: Float}
+		}
+		a := args[i].typ
+		switch {
+		case a == Float || a == Int || a == Bool:
+			n++
+		case a.isVec():
+			n += a.dim()
+		case a.isMat() && len(args) == 1:
+			n = t.FloatsNum()
+		default:
+			return value{}, c.errorf(e.Args[i], "cannot use %s (type %s) in %s constructor", nodeString(e.Args[i]), a.name(), t)
+		}
+	}
+	if !(len(args) == 1 && n == 1) && n != t.FloatsNum() {
+		return value{}, c.errorf(e, "wrong number of components in %s constructor: %d for %d", t, n, t.FloatsNum())
+	}
+	return value{code: fmt.Sprintf("%s(%s)", t, joinCodes(args)), typ: t}, nil
+}
+
+func (c *compiler) selectorExpr(e *ast.SelectorExpr) (value, error) {
+	v, err := c.expr(e.X)
+	if err != nil {
+		return value{}, err
+	}
+	if !v.typ.isVec() {
+		return value{}, c.errorf(e, "%s.%s undefined (type %s has no field or method %s)", nodeString(e.X), e.Sel.Name, v.typ.name(), e.Sel.Name)
+	}
+	s := e.Sel.Name
+	if len(s) > 4 || !isSwizzle(s, v.typ.dim()) {
+		return value{}, c.errorf(e.Sel, "invalid swizzle: %s", s)
+	}
+	return value{code: v.code + "." + s, typ: vecType(len(s))}, nil
+}
+
+// isSwizzle reports whether s is a valid swizzle for a vector with n components.
+// All the characters must belong to the same set of xyzw, rgba or stpq.
+func isSwizzle(s string, n int) bool {
+	for _, set := range []string{"xyzw", "rgba", "stpq"} {
+		ok := true
+		for _, r := range s {
+			i := strings.IndexRune(set, r)
+			if i < 0 || i >= n {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *compiler) indexExpr(e *ast.IndexExpr) (value, error) {
+	v, err := c.expr(e.X)
+	if err != nil {
+		return value{}, err
+	}
+	i, err := c.expr(e.Index)
+	if err != nil {
+		return value{}, err
+	}
+	idx, err := c.convert(e.Index, i, Int)
+	if err != nil {
+		return value{}, err
+	}
+	if i.typ == untypedInt {
+		if n, _ := strconv.ParseInt(i.code, 0, 32); i.literal && (n < 0 || int(n) >= v.typ.dim()) {
+			return value{}, c.errorf(e.Index, "index %s out of range", i.code)
+		}
+	}
+	switch {
+	case v.typ.isVec():
+		return value{code: fmt.Sprintf("%s[%s]", v.code, idx), typ: Float}, nil
+	case v.typ.isMat():
+		return value{code: fmt.Sprintf("%s[%s]", v.code, idx), typ: vecType(v.typ.dim())}, nil
+	}
+	return value{}, c.errorf(e, "invalid operation: %s (type %s does not support indexing)", nodeString(e.X), v.typ.name())
+}
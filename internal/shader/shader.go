@@ -0,0 +1,470 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shader provides a compiler of the Ebiten shading language.
+//
+// The shading language is a subset of Go. A shader program is a Go source file that has a function Fragment:
+//
+//     package main
+//
+//     var Time float
+//
+//     func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+//         return imageSrc0At(texCoord) * color
+//     }
+//
+// The compiler converts the program into a GLSL fragment shader.
+package shader
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The names of the variables in the generated GLSL.
+// The varyings must match with the vertex shader in package graphics.
+const (
+	// TextureNum is the number of the source images.
+	TextureNum = 4
+
+	// TextureName is the name of the sampler of the first source image.
+	TextureName = "T0"
+
+	// TextureSizeName is the name of the uniform representing the size of the first source texture.
+	TextureSizeName = "T0Size"
+
+	varyingTexCoord   = "vertex_out_tex_coord"
+	varyingColorScale = "vertex_out_color_scale"
+	varyingPosition   = "vertex_out_position"
+)
+
+// TextureNameAt returns the name of the sampler of the i-th source image.
+func TextureNameAt(i int) string {
+	return fmt.Sprintf("T%d", i)
+}
+
+// TextureSizeNameAt returns the name of the uniform representing the size of the i-th source texture.
+func TextureSizeNameAt(i int) string {
+	return fmt.Sprintf("T%dSize", i)
+}
+
+// TextureOffsetNameAt returns the name of the uniform representing the position in pixels
+// of the i-th source image in its texture.
+func TextureOffsetNameAt(i int) string {
+	return fmt.Sprintf("T%dOffset", i)
+}
+
+// Uniform represents a uniform variable declared in a shader program.
+type Uniform struct {
+	// Name is the name of the variable in the shader program.
+	Name string
+
+	// Type is the type of the variable.
+	Type Type
+}
+
+// GLSLName returns the name of the uniform variable in the generated GLSL.
+func (u *Uniform) GLSLName() string {
+	return "U_" + u.Name
+}
+
+// Program represents a compiled shader program.
+type Program struct {
+	// Uniforms is the uniform variables in the declared order.
+	Uniforms []Uniform
+
+	// FragmentShader is the source of the GLSL fragment shader.
+	FragmentShader string
+}
+
+// Compile compiles the shader program src.
+func Compile(src []byte) (*Program, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiler{
+		fset:     fset,
+		uniforms: map[string]Type{},
+		consts:   map[string]value{},
+		funcs:    map[string]*function{},
+	}
+	if err := c.compile(f); err != nil {
+		return nil, err
+	}
+	return &Program{
+		Uniforms:       c.uniformList,
+		FragmentShader: c.buf.String(),
+	}, nil
+}
+
+type function struct {
+	decl   *ast.FuncDecl
+	params []Type
+	result Type
+}
+
+type compiler struct {
+	fset *token.FileSet
+	buf  bytes.Buffer
+
+	uniformList []Uniform
+	uniforms    map[string]Type
+	consts      map[string]value
+	funcs       map[string]*function
+	funcOrder   []string
+
+	// scopes is the stack of local variables and constants.
+	scopes []map[string]value
+
+	// current is the function being compiled.
+	current *function
+}
+
+type compileError struct {
+	pos token.Position
+	msg string
+}
+
+func (e *compileError) Error() string {
+	return fmt.Sprintf("%s: %s", e.pos, e.msg)
+}
+
+func (c *compiler) errorf(n ast.Node, format string, args ...interface{}) error {
+	p := c.fset.Position(n.Pos())
+	p.Filename = "shader"
+	return &compileError{pos: p, msg: fmt.Sprintf(format, args...)}
+}
+
+func (c *compiler) compile(f *ast.File) error {
+	var globalConsts []string
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.GenDecl:
+			switch d.Tok {
+			case token.IMPORT:
+				return c.errorf(d, "import is not supported")
+			case token.TYPE:
+				return c.errorf(d, "type declaration is not supported")
+			case token.VAR:
+				if err := c.uniformDecl(d); err != nil {
+					return err
+				}
+			case token.CONST:
+				lines, err := c.valueDecl(d, true)
+				if err != nil {
+					return err
+				}
+				globalConsts = append(globalConsts, lines...)
+			}
+		case *ast.FuncDecl:
+			if err := c.funcSignature(d); err != nil {
+				return err
+			}
+		}
+	}
+
+	fr, ok := c.funcs["Fragment"]
+	if !ok {
+		return c.errorf(f, "function Fragment is not found")
+	}
+	if len(fr.params) != 3 || fr.params[0] != Vec4 || fr.params[1] != Vec2 || fr.params[2] != Vec4 || fr.result != Vec4 {
+		return c.errorf(fr.decl, "Fragment must be func(position vec4, texCoord vec2, color vec4) vec4")
+	}
+
+	c.buf.WriteString(`#if defined(GL_ES)
+#if defined(GL_FRAGMENT_PRECISION_HIGH)
+precision highp float;
+#else
+precision mediump float;
+#endif
+#else
+#define lowp
+#define mediump
+#define highp
+#endif
+
+`)
+	for i := 0; i < TextureNum; i++ {
+		fmt.Fprintf(&c.buf, "uniform sampler2D %s;\n", TextureNameAt(i))
+		fmt.Fprintf(&c.buf, "uniform vec2 %s;\n", TextureSizeNameAt(i))
+		fmt.Fprintf(&c.buf, "uniform vec2 %s;\n", TextureOffsetNameAt(i))
+	}
+	fmt.Fprintf(&c.buf, "varying vec2 %s;\n", varyingTexCoord)
+	fmt.Fprintf(&c.buf, "varying vec4 %s;\n", varyingColorScale)
+	fmt.Fprintf(&c.buf, "varying vec2 %s;\n", varyingPosition)
+	for _, u := range c.uniformList {
+		fmt.Fprintf(&c.buf, "uniform %s %s;\n", u.Type, u.GLSLName())
+	}
+	for _, l := range globalConsts {
+		c.buf.WriteString(l + "\n")
+	}
+	c.buf.WriteString("\n")
+
+	// Declare the prototypes first so that the functions can be called in any order.
+	for _, name := range c.funcOrder {
+		c.buf.WriteString(c.prototype(name) + ";\n")
+	}
+	for _, name := range c.funcOrder {
+		c.buf.WriteString("\n")
+		if err := c.funcBody(name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(&c.buf, `
+void main(void) {
+  gl_FragColor = F_Fragment(vec4(%s, 0.0, 1.0), %s, %s);
+}
+`, varyingPosition, varyingTexCoord, varyingColorScale)
+	return nil
+}
+
+func (c *compiler) parseType(e ast.Expr) (Type, error) {
+	if i, ok := e.(*ast.Ident); ok {
+		if t, ok := typeNames[i.Name]; ok {
+			return t, nil
+		}
+	}
+	return None, c.errorf(e, "unknown type: %s", nodeString(e))
+}
+
+func nodeString(n ast.Node) string {
+	switch n := n.(type) {
+	case *ast.Ident:
+		return n.Name
+	case *ast.BasicLit:
+		return n.Value
+	case *ast.SelectorExpr:
+		return nodeString(n.X) + "." + n.Sel.Name
+	case *ast.CallExpr:
+		return nodeString(n.Fun) + "(...)"
+	}
+	return fmt.Sprintf("%T", n)
+}
+
+func isExported(name string) bool {
+	r, _ := utf8.DecodeRuneInString(name)
+	return unicode.IsUpper(r)
+}
+
+func (c *compiler) uniformDecl(d *ast.GenDecl) error {
+	for _, s := range d.Specs {
+		s := s.(*ast.ValueSpec)
+		if s.Type == nil {
+			return c.errorf(s, "a uniform variable must have an explicit type")
+		}
+		if len(s.Values) > 0 {
+			return c.errorf(s, "a uniform variable can't have an initial value")
+		}
+		t, err := c.parseType(s.Type)
+		if err != nil {
+			return err
+		}
+		if t.FloatsNum() == 0 {
+			return c.errorf(s, "a uniform variable must be float, vector or matrix type")
+		}
+		for _, n := range s.Names {
+			if !isExported(n.Name) {
+				return c.errorf(n, "a uniform variable name must start with an upper case letter: %s", n.Name)
+			}
+			if c.defined(n.Name) {
+				return c.errorf(n, "%s is redeclared", n.Name)
+			}
+			c.uniformList = append(c.uniformList, Uniform{Name: n.Name, Type: t})
+			c.uniforms[n.Name] = t
+		}
+	}
+	return nil
+}
+
+func (c *compiler) defined(name string) bool {
+	if _, ok := c.uniforms[name]; ok {
+		return true
+	}
+	if _, ok := c.consts[name]; ok {
+		return true
+	}
+	if _, ok := c.funcs[name]; ok {
+		return true
+	}
+	return false
+}
+
+func (c *compiler) funcSignature(d *ast.FuncDecl) error {
+	if d.Recv != nil {
+		return c.errorf(d, "method is not supported")
+	}
+	name := d.Name.Name
+	if c.defined(name) {
+		return c.errorf(d.Name, "%s is redeclared", name)
+	}
+	if _, ok := builtinFuncs[name]; ok {
+		return c.errorf(d.Name, "%s is a built-in function", name)
+	}
+	f := &function{decl: d}
+	for _, p := range d.Type.Params.List {
+		t, err := c.parseType(p.Type)
+		if err != nil {
+			return err
+		}
+		n := len(p.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			f.params = append(f.params, t)
+		}
+	}
+	if r := d.Type.Results; r != nil && len(r.List) > 0 {
+		if len(r.List) > 1 || len(r.List[0].Names) > 1 {
+			return c.errorf(r, "multiple return values are not supported")
+		}
+		if len(r.List[0].Names) > 0 {
+			return c.errorf(r, "named return values are not supported")
+		}
+		t, err := c.parseType(r.List[0].Type)
+		if err != nil {
+			return err
+		}
+		f.result = t
+	}
+	c.funcs[name] = f
+	c.funcOrder = append(c.funcOrder, name)
+	return nil
+}
+
+func (c *compiler) prototype(name string) string {
+	f := c.funcs[name]
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s F_%s(", f.result, name)
+	i := 0
+	for _, p := range f.decl.Type.Params.List {
+		for _, n := range p.Names {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "in %s L_%s", f.params[i], n.Name)
+			i++
+		}
+		if len(p.Names) == 0 {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			fmt.Fprintf(&buf, "in %s", f.params[i])
+			i++
+		}
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func (c *compiler) funcBody(name string) error {
+	f := c.funcs[name]
+	if f.decl.Body == nil {
+		return c.errorf(f.decl, "function %s must have a body", name)
+	}
+	c.current = f
+	defer func() {
+		c.current = nil
+	}()
+
+	c.pushScope()
+	defer c.popScope()
+	i := 0
+	for _, p := range f.decl.Type.Params.List {
+		for _, n := range p.Names {
+			c.scopes[len(c.scopes)-1][n.Name] = value{code: "L_" + n.Name, typ: f.params[i], variable: true}
+			i++
+		}
+	}
+
+	lines, err := c.block(f.decl.Body.List)
+	if err != nil {
+		return err
+	}
+	if f.result != None && !terminates(f.decl.Body.List) {
+		return c.errorf(f.decl.Body, "missing return at the end of function %s", name)
+	}
+	c.buf.WriteString(c.prototype(name) + " {\n")
+	for _, l := range lines {
+		c.buf.WriteString("  " + l + "\n")
+	}
+	c.buf.WriteString("}\n")
+	return nil
+}
+
+// terminates reports whether the statements end with a return statement.
+func terminates(stmts []ast.Stmt) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+	switch s := stmts[len(stmts)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BlockStmt:
+		return terminates(s.List)
+	case *ast.IfStmt:
+		if s.Else == nil {
+			return false
+		}
+		if !terminates(s.Body.List) {
+			return false
+		}
+		switch e := s.Else.(type) {
+		case *ast.BlockStmt:
+			return terminates(e.List)
+		case *ast.IfStmt:
+			return terminates([]ast.Stmt{e})
+		}
+	case *ast.ExprStmt:
+		// discard() terminates the function.
+		if call, ok := s.X.(*ast.CallExpr); ok {
+			if i, ok := call.Fun.(*ast.Ident); ok && i.Name == "discard" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *compiler) pushScope() {
+	c.scopes = append(c.scopes, map[string]value{})
+}
+
+func (c *compiler) popScope() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+// lookup returns the value of the identifier.
+func (c *compiler) lookup(name string) (value, bool) {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if v, ok := c.scopes[i][name]; ok {
+			return v, true
+		}
+	}
+	if v, ok := c.consts[name]; ok {
+		return v, true
+	}
+	if t, ok := c.uniforms[name]; ok {
+		return value{code: "U_" + name, typ: t}, true
+	}
+	return value{}, false
+}
@@ -0,0 +1,212 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shader_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/internal/shader"
+)
+
+func TestCompileUniforms(t *testing.T) {
+	p, err := Compile([]byte(`package main
+
+var Time float
+var Cursor, Size vec2
+var Matrix mat4
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	return imageSrc0At(texCoord) * color
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Uniform{
+		{Name: "Time", Type: Float},
+		{Name: "Cursor", Type: Vec2},
+		{Name: "Size", Type: Vec2},
+		{Name: "Matrix", Type: Mat4},
+	}
+	if len(p.Uniforms) != len(want) {
+		t.Fatalf("len(p.Uniforms): got: %d, want: %d", len(p.Uniforms), len(want))
+	}
+	for i, u := range p.Uniforms {
+		if u != want[i] {
+			t.Errorf("p.Uniforms[%d]: got: %v, want: %v", i, u, want[i])
+		}
+	}
+	for _, s := range []string{
+		"uniform float U_Time;",
+		"uniform vec2 U_Cursor;",
+		"uniform vec2 U_Size;",
+		"uniform mat4 U_Matrix;",
+		"return texture2D(T0, L_texCoord) * L_color;",
+		"gl_FragColor = F_Fragment(",
+	} {
+		if !strings.Contains(p.FragmentShader, s) {
+			t.Errorf("the fragment shader doesn't contain %q:\n%s", s, p.FragmentShader)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	cases := []struct {
+		Body string
+		Want string
+	}{
+		{
+			Body: "return vec4(1)",
+			Want: "return vec4(1.0);",
+		},
+		{
+			Body: "x := 1\n_ = x\nreturn vec4(x)",
+			Want: "int L_x = 1;",
+		},
+		{
+			Body: "x := 1.5 * 2\nreturn vec4(x)",
+			Want: "float L_x = 1.5 * 2.0;",
+		},
+		{
+			Body: "x := 1 / 2\nreturn vec4(x)",
+			Want: "int L_x = 1 / 2;",
+		},
+		{
+			Body: "var x vec2\nreturn vec4(x, 0, 0)",
+			Want: "vec2 L_x = vec2(0.0);",
+		},
+		{
+			Body: "const x = 2\nreturn color * x",
+			Want: "return L_color * 2.0;",
+		},
+		{
+			Body: "c := color\nc.rgb *= 0.5\nreturn c",
+			Want: "L_c.rgb *= 0.5;",
+		},
+		{
+			Body: "if color.a == 0 {\ndiscard()\n}\nreturn color",
+			Want: "discard;",
+		},
+		{
+			Body: "s := 0.0\nfor i := 0; i < 4; i++ {\ns += float(i)\n}\nreturn vec4(s)",
+			Want: "for (int L_i = 0; L_i < 4; L_i++) {",
+		},
+		{
+			Body: "return vec4(imageSrcTextureSize(), length(texCoord), 1)",
+			Want: "return vec4(T0Size, length(L_texCoord), 1.0);",
+		},
+		{
+			Body: "return imageSrc0At(texCoord) * imageSrc1At(texCoord).a",
+			Want: "return texture2D(T0, L_texCoord) * texture2D(T1, ((L_texCoord) * T0Size - T0Offset + T1Offset) / T1Size).a;",
+		},
+		{
+			Body: "return vec4(mod(position.xy, 2.0), clamp(color.ba, 0, 1))",
+			Want: "return vec4(mod(L_position.xy, 2.0), clamp(L_color.ba, 0.0, 1.0));",
+		},
+	}
+	for _, c := range cases {
+		src := "package main\n\nfunc Fragment(position vec4, texCoord vec2, color vec4) vec4 {\n" + c.Body + "\n}\n"
+		p, err := Compile([]byte(src))
+		if err != nil {
+			t.Errorf("Compile(%q) returned error: %v", c.Body, err)
+			continue
+		}
+		if !strings.Contains(p.FragmentShader, c.Want) {
+			t.Errorf("Compile(%q) doesn't contain %q:\n%s", c.Body, c.Want, p.FragmentShader)
+		}
+	}
+}
+
+func TestCompileFunctions(t *testing.T) {
+	p, err := Compile([]byte(`package main
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	return vec4(half(color.r), 0, 0, 1)
+}
+
+func half(x float) float {
+	return x / 2
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"float F_half(in float L_x);",
+		"return vec4(F_half(L_color.r), 0.0, 0.0, 1.0);",
+		"return L_x / 2.0;",
+	} {
+		if !strings.Contains(p.FragmentShader, s) {
+			t.Errorf("the fragment shader doesn't contain %q:\n%s", s, p.FragmentShader)
+		}
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	cases := []string{
+		// No Fragment function
+		"package main\n",
+		// Wrong signature
+		"package main\nfunc Fragment(position vec4) vec4 {\nreturn position\n}\n",
+		// Import
+		"package main\nimport \"math\"\n",
+		// Lower-case uniform
+		"package main\nvar time float\n",
+		// Uniform with a value
+		"package main\nvar Time float = 1\n",
+		// Unknown type
+		"package main\nvar Time float64\n",
+	}
+	for _, src := range cases {
+		if _, err := Compile([]byte(src)); err == nil {
+			t.Errorf("Compile(%q) must return an error but not", src)
+		}
+	}
+
+	bodies := []string{
+		// Missing return
+		"_ = color",
+		// Undefined
+		"return foo",
+		// Type mismatch
+		"return vec3(1)",
+		"x := 1\nreturn color * x",
+		"return color + texCoord",
+		// Assignment to a parameter's swizzle is allowed, but not to a uniform or a constant.
+		"const x = 1\nx = 2\nreturn color",
+		// Wrong number of components
+		"return vec4(1, 2, 3)",
+		// Invalid swizzle
+		"return vec4(texCoord.xyz, 1)",
+		"return vec4(color.xyba)",
+		// Unsupported statements
+		"for {\n}\nreturn color",
+		"switch {\n}\nreturn color",
+		"a, b := 1, 2\na, b = b, a\nreturn color",
+		// Remainder operator
+		"x := 5 % 2\n_ = x\nreturn color",
+		// Non-bool condition
+		"if 1 {\n}\nreturn color",
+		// Calling Fragment
+		"return Fragment(position, texCoord, color)",
+	}
+	for _, b := range bodies {
+		src := "package main\n\nvar Time float\n\nfunc Fragment(position vec4, texCoord vec2, color vec4) vec4 {\n" + b + "\n}\n"
+		if _, err := Compile([]byte(src)); err == nil {
+			t.Errorf("Compile(%q) must return an error but not", b)
+		}
+	}
+}
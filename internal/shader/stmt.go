@@ -0,0 +1,471 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shader
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+func indent(lines []string) []string {
+	r := make([]string, len(lines))
+	for i, l := range lines {
+		r[i] = "  " + l
+	}
+	return r
+}
+
+// block compiles the statements in a new scope and returns the lines of GLSL.
+func (c *compiler) block(stmts []ast.Stmt) ([]string, error) {
+	c.pushScope()
+	defer c.popScope()
+	var lines []string
+	for _, s := range stmts {
+		ls, err := c.stmt(s)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, ls...)
+	}
+	return lines, nil
+}
+
+func (c *compiler) declareLocal(n *ast.Ident, v value) error {
+	s := c.scopes[len(c.scopes)-1]
+	if _, ok := s[n.Name]; ok {
+		return c.errorf(n, "%s redeclared in this block", n.Name)
+	}
+	if v.typ == None {
+		return c.errorf(n, "%s can't be declared without a type", n.Name)
+	}
+	s[n.Name] = v
+	return nil
+}
+
+func zeroValue(t Type) string {
+	switch t {
+	case Bool:
+		return "false"
+	case Int:
+		return "0"
+	case Float:
+		return "0.0"
+	}
+	return fmt.Sprintf("%s(0.0)", t)
+}
+
+// valueDecl compiles a var or const declaration.
+// If global is true, the declaration is at the top level.
+func (c *compiler) valueDecl(d *ast.GenDecl, global bool) ([]string, error) {
+	var lines []string
+	for _, s := range d.Specs {
+		s := s.(*ast.ValueSpec)
+		if len(s.Values) > 0 && len(s.Values) != len(s.Names) {
+			return nil, c.errorf(s, "assignment mismatch: %d variables but %d values", len(s.Names), len(s.Values))
+		}
+		if d.Tok == token.CONST && len(s.Values) == 0 {
+			return nil, c.errorf(s, "missing constant value")
+		}
+		var t Type
+		if s.Type != nil {
+			var err error
+			t, err = c.parseType(s.Type)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Evaluate the values before declaring the names.
+		vs := make([]value, len(s.Names))
+		for i := range s.Names {
+			if len(s.Values) == 0 {
+				vs[i] = value{code: zeroValue(t), typ: t}
+				continue
+			}
+			v, err := c.expr(s.Values[i])
+			if err != nil {
+				return nil, err
+			}
+			if d.Tok == token.CONST && t == None && v.typ.isUntyped() {
+				// An untyped constant is embedded where it is used.
+				if !v.literal {
+					v.code = "(" + v.code + ")"
+				}
+				vs[i] = value{code: v.code, typ: v.typ, literal: v.literal}
+				continue
+			}
+			vt := t
+			if vt == None {
+				vt = v.typ.defaultType()
+			}
+			code, err := c.convert(s.Values[i], v, vt)
+			if err != nil {
+				return nil, err
+			}
+			vs[i] = value{code: code, typ: vt}
+		}
+
+		for i, n := range s.Names {
+			if n.Name == "_" {
+				continue
+			}
+			v := vs[i]
+			if v.typ == None {
+				return nil, c.errorf(n, "%s can't be declared without a type", n.Name)
+			}
+			if v.typ.isUntyped() {
+				if global {
+					if c.defined(n.Name) {
+						return nil, c.errorf(n, "%s is redeclared", n.Name)
+					}
+					c.consts[n.Name] = v
+					continue
+				}
+				if err := c.declareLocal(n, v); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			prefix := ""
+			if d.Tok == token.CONST {
+				prefix = "const "
+			}
+			if global {
+				if c.defined(n.Name) {
+					return nil, c.errorf(n, "%s is redeclared", n.Name)
+				}
+				c.consts[n.Name] = value{code: "C_" + n.Name, typ: v.typ}
+				lines = append(lines, fmt.Sprintf("%s%s C_%s = %s;", prefix, v.typ, n.Name, v.code))
+				continue
+			}
+			if err := c.declareLocal(n, value{code: "L_" + n.Name, typ: v.typ, variable: d.Tok == token.VAR}); err != nil {
+				return nil, err
+			}
+			lines = append(lines, fmt.Sprintf("%s%s L_%s = %s;", prefix, v.typ, n.Name, v.code))
+		}
+	}
+	return lines, nil
+}
+
+// isVariable reports whether e is a local variable or a part of a local variable.
+func (c *compiler) isVariable(e ast.Expr) bool {
+	switch e := e.(type) {
+	case *ast.Ident:
+		v, ok := c.lookup(e.Name)
+		return ok && v.variable
+	case *ast.SelectorExpr:
+		return c.isVariable(e.X)
+	case *ast.IndexExpr:
+		return c.isVariable(e.X)
+	case *ast.ParenExpr:
+		return c.isVariable(e.X)
+	}
+	return false
+}
+
+// lvalue compiles an expression that can be assigned.
+func (c *compiler) lvalue(e ast.Expr) (value, error) {
+	if !c.isVariable(e) {
+		return value{}, c.errorf(e, "cannot assign to %s", nodeString(e))
+	}
+	return c.expr(e)
+}
+
+func (c *compiler) stmt(s ast.Stmt) ([]string, error) {
+	switch s := s.(type) {
+	case *ast.DeclStmt:
+		d, ok := s.Decl.(*ast.GenDecl)
+		if !ok || (d.Tok != token.VAR && d.Tok != token.CONST) {
+			return nil, c.errorf(s, "unsupported declaration")
+		}
+		return c.valueDecl(d, false)
+	case *ast.AssignStmt:
+		return c.assignStmt(s)
+	case *ast.IncDecStmt:
+		v, err := c.lvalue(s.X)
+		if err != nil {
+			return nil, err
+		}
+		if v.typ != Int && v.typ != Float {
+			return nil, c.errorf(s, "invalid operation: %s%s (non-numeric type %s)", nodeString(s.X), s.Tok, v.typ.name())
+		}
+		return []string{v.code + s.Tok.String() + ";"}, nil
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return nil, c.errorf(s, "%s is not used", nodeString(s.X))
+		}
+		v, err := c.expr(call)
+		if err != nil {
+			return nil, err
+		}
+		return []string{v.code + ";"}, nil
+	case *ast.ReturnStmt:
+		f := c.current
+		if f.result == None {
+			if len(s.Results) != 0 {
+				return nil, c.errorf(s, "too many arguments to return")
+			}
+			return []string{"return;"}, nil
+		}
+		if len(s.Results) != 1 {
+			return nil, c.errorf(s, "wrong number of return values")
+		}
+		v, err := c.expr(s.Results[0])
+		if err != nil {
+			return nil, err
+		}
+		code, err := c.convert(s.Results[0], v, f.result)
+		if err != nil {
+			return nil, err
+		}
+		return []string{"return " + code + ";"}, nil
+	case *ast.BlockStmt:
+		lines, err := c.block(s.List)
+		if err != nil {
+			return nil, err
+		}
+		return append(append([]string{"{"}, indent(lines)...), "}"), nil
+	case *ast.IfStmt:
+		return c.ifStmt(s)
+	case *ast.ForStmt:
+		return c.forStmt(s)
+	case *ast.BranchStmt:
+		if s.Label != nil {
+			return nil, c.errorf(s, "label is not supported")
+		}
+		switch s.Tok {
+		case token.BREAK:
+			return []string{"break;"}, nil
+		case token.CONTINUE:
+			return []string{"continue;"}, nil
+		}
+		return nil, c.errorf(s, "%s is not supported", s.Tok)
+	case *ast.EmptyStmt:
+		return nil, nil
+	}
+	return nil, c.errorf(s, "unsupported statement: %s", nodeString(s))
+}
+
+func (c *compiler) assignStmt(s *ast.AssignStmt) ([]string, error) {
+	if len(s.Lhs) != len(s.Rhs) {
+		return nil, c.errorf(s, "assignment mismatch: %d variables but %d values", len(s.Lhs), len(s.Rhs))
+	}
+
+	if s.Tok == token.DEFINE {
+		vs, err := c.args(s.Rhs)
+		if err != nil {
+			return nil, err
+		}
+		var lines []string
+		isNew := false
+		for i, l := range s.Lhs {
+			n, ok := l.(*ast.Ident)
+			if !ok {
+				return nil, c.errorf(l, "non-name %s on left side of :=", nodeString(l))
+			}
+			if n.Name == "_" {
+				continue
+			}
+			if v, ok := c.scopes[len(c.scopes)-1][n.Name]; ok {
+				if !v.variable {
+					return nil, c.errorf(n, "cannot assign to %s", n.Name)
+				}
+				code, err := c.convert(s.Rhs[i], vs[i], v.typ)
+				if err != nil {
+					return nil, err
+				}
+				lines = append(lines, fmt.Sprintf("L_%s = %s;", n.Name, code))
+				continue
+			}
+			isNew = true
+			t := vs[i].typ.defaultType()
+			code, err := c.convert(s.Rhs[i], vs[i], t)
+			if err != nil {
+				return nil, err
+			}
+			if err := c.declareLocal(n, value{code: "L_" + n.Name, typ: t, variable: true}); err != nil {
+				return nil, err
+			}
+			lines = append(lines, fmt.Sprintf("%s L_%s = %s;", t, n.Name, code))
+		}
+		if !isNew {
+			return nil, c.errorf(s, "no new variables on left side of :=")
+		}
+		return lines, nil
+	}
+
+	if len(s.Lhs) != 1 {
+		return nil, c.errorf(s, "multiple assignment is not supported")
+	}
+	if i, ok := s.Lhs[0].(*ast.Ident); ok && i.Name == "_" {
+		return nil, nil
+	}
+	l, err := c.lvalue(s.Lhs[0])
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.expr(s.Rhs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Tok == token.ASSIGN {
+		code, err := c.convert(s.Rhs[0], r, l.typ)
+		if err != nil {
+			return nil, err
+		}
+		return []string{fmt.Sprintf("%s = %s;", l.code, code)}, nil
+	}
+
+	var op token.Token
+	switch s.Tok {
+	case token.ADD_ASSIGN:
+		op = token.ADD
+	case token.SUB_ASSIGN:
+		op = token.SUB
+	case token.MUL_ASSIGN:
+		op = token.MUL
+	case token.QUO_ASSIGN:
+		op = token.QUO
+	case token.REM_ASSIGN:
+		op = token.REM
+	default:
+		return nil, c.errorf(s, "unsupported operator: %s", s.Tok)
+	}
+	lt := l.typ
+	t, err := c.binaryOp(s, op, &l, &r)
+	if err != nil {
+		return nil, err
+	}
+	if t != lt {
+		return nil, c.errorf(s, "cannot use %s (type %s) as type %s in assignment", nodeString(s.Rhs[0]), t.name(), lt.name())
+	}
+	return []string{fmt.Sprintf("%s %s %s;", l.code, s.Tok, r.code)}, nil
+}
+
+func (c *compiler) cond(e ast.Expr) (string, error) {
+	v, err := c.expr(e)
+	if err != nil {
+		return "", err
+	}
+	if v.typ != Bool {
+		return "", c.errorf(e, "non-bool %s (type %s) used as condition", nodeString(e), v.typ.name())
+	}
+	return v.code, nil
+}
+
+// simpleStmt compiles a statement that is a part of an if or a for statement.
+func (c *compiler) simpleStmt(s ast.Stmt) (string, error) {
+	lines, err := c.stmt(s)
+	if err != nil {
+		return "", err
+	}
+	if len(lines) != 1 {
+		return "", c.errorf(s, "unsupported statement: %s", nodeString(s))
+	}
+	return strings.TrimSuffix(lines[0], ";"), nil
+}
+
+func (c *compiler) ifStmt(s *ast.IfStmt) ([]string, error) {
+	// The variables declared at the init statement are visible in the else branches.
+	c.pushScope()
+	defer c.popScope()
+
+	var init []string
+	if s.Init != nil {
+		var err error
+		init, err = c.stmt(s.Init)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cond, err := c.cond(s.Cond)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.block(s.Body.List)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{fmt.Sprintf("if (%s) {", cond)}
+	lines = append(lines, indent(body)...)
+	switch e := s.Else.(type) {
+	case nil:
+		lines = append(lines, "}")
+	case *ast.BlockStmt:
+		els, err := c.block(e.List)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "} else {")
+		lines = append(lines, indent(els)...)
+		lines = append(lines, "}")
+	case *ast.IfStmt:
+		els, err := c.ifStmt(e)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "} else {")
+		lines = append(lines, indent(els)...)
+		lines = append(lines, "}")
+	}
+	if len(init) == 0 {
+		return lines, nil
+	}
+	r := append([]string{"{"}, indent(init)...)
+	r = append(r, indent(lines)...)
+	return append(r, "}"), nil
+}
+
+// forStmt compiles a for statement.
+// Note that GLSL ES 1.0 restricts for loops: the loop index must be initialized with a constant
+// and compared with a constant.
+func (c *compiler) forStmt(s *ast.ForStmt) ([]string, error) {
+	if s.Cond == nil {
+		return nil, c.errorf(s, "for statement without a condition is not supported")
+	}
+	c.pushScope()
+	defer c.popScope()
+
+	var init, post string
+	if s.Init != nil {
+		var err error
+		init, err = c.simpleStmt(s.Init)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cond, err := c.cond(s.Cond)
+	if err != nil {
+		return nil, err
+	}
+	if s.Post != nil {
+		post, err = c.simpleStmt(s.Post)
+		if err != nil {
+			return nil, err
+		}
+	}
+	body, err := c.block(s.Body.List)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{fmt.Sprintf("for (%s; %s; %s) {", init, cond, post)}
+	lines = append(lines, indent(body)...)
+	return append(lines, "}"), nil
+}
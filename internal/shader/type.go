@@ -0,0 +1,158 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shader
+
+import (
+	"fmt"
+)
+
+// Type represents a type in the shading language.
+type Type int
+
+// Types
+const (
+	None Type = iota
+	Bool
+	Int
+	Float
+	Vec2
+	Vec3
+	Vec4
+	Mat2
+	Mat3
+	Mat4
+
+	// untypedInt and untypedFloat are the types of constants without explicit types.
+	// These are converted to Int or Float depending on the context.
+	untypedInt
+	untypedFloat
+)
+
+var typeNames = map[string]Type{
+	"bool":  Bool,
+	"int":   Int,
+	"float": Float,
+	"vec2":  Vec2,
+	"vec3":  Vec3,
+	"vec4":  Vec4,
+	"mat2":  Mat2,
+	"mat3":  Mat3,
+	"mat4":  Mat4,
+}
+
+// String returns the GLSL name of the type.
+func (t Type) String() string {
+	switch t {
+	case None:
+		return "void"
+	case Bool:
+		return "bool"
+	case Int, untypedInt:
+		return "int"
+	case Float, untypedFloat:
+		return "float"
+	case Vec2:
+		return "vec2"
+	case Vec3:
+		return "vec3"
+	case Vec4:
+		return "vec4"
+	case Mat2:
+		return "mat2"
+	case Mat3:
+		return "mat3"
+	case Mat4:
+		return "mat4"
+	}
+	panic(fmt.Sprintf("shader: invalid type: %d", t))
+}
+
+// FloatsNum returns the number of float values to represent a value of the type.
+// FloatsNum returns 0 for non-float types.
+func (t Type) FloatsNum() int {
+	switch t {
+	case Float:
+		return 1
+	case Vec2:
+		return 2
+	case Vec3:
+		return 3
+	case Vec4, Mat2:
+		return 4
+	case Mat3:
+		return 9
+	case Mat4:
+		return 16
+	}
+	return 0
+}
+
+func (t Type) isUntyped() bool {
+	return t == untypedInt || t == untypedFloat
+}
+
+func (t Type) isFloatLike() bool {
+	return t == Float || t == untypedFloat || t == untypedInt
+}
+
+func (t Type) isNumeric() bool {
+	return t == Int || t == untypedInt || t.isFloatLike() || t.isVec() || t.isMat()
+}
+
+func (t Type) isVec() bool {
+	return t == Vec2 || t == Vec3 || t == Vec4
+}
+
+func (t Type) isMat() bool {
+	return t == Mat2 || t == Mat3 || t == Mat4
+}
+
+// dim returns the number of components of a vector or the number of columns of a matrix.
+func (t Type) dim() int {
+	switch t {
+	case Vec2, Mat2:
+		return 2
+	case Vec3, Mat3:
+		return 3
+	case Vec4, Mat4:
+		return 4
+	}
+	return 1
+}
+
+func vecType(n int) Type {
+	switch n {
+	case 1:
+		return Float
+	case 2:
+		return Vec2
+	case 3:
+		return Vec3
+	case 4:
+		return Vec4
+	}
+	return None
+}
+
+// defaultType returns the type of an untyped constant when the constant is used without any context.
+func (t Type) defaultType() Type {
+	switch t {
+	case untypedInt:
+		return Int
+	case untypedFloat:
+		return Float
+	}
+	return t
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !android
+// +build !js
+
+package ui
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// joystickSysfsPath returns the sysfs path of the joystick device for the gamepad (id),
+// e.g. /sys/class/input/js0.
+//
+// GLFW 3.2 doesn't expose the device paths. As GLFW sorts the initial joysticks by their paths,
+// the device is assumed to be the n-th joystick with the same name where n is the number of
+// the gamepads with the same name whose IDs are less than id.
+func joystickSysfsPath(names []string, id int) string {
+	n := 0
+	for i := 0; i < id; i++ {
+		if names[i] == names[id] {
+			n++
+		}
+	}
+
+	js, _ := filepath.Glob("/sys/class/input/js*")
+	sort.Strings(js)
+	for _, j := range js {
+		name, err := ioutil.ReadFile(filepath.Join(j, "device", "name"))
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(name)) != names[id] {
+			continue
+		}
+		if n > 0 {
+			n--
+			continue
+		}
+		return j
+	}
+	return ""
+}
+
+// gamepadDeviceInfo returns the identification of the gamepad (id) read from sysfs.
+func gamepadDeviceInfo(names []string, id int) gamepadDevice {
+	j := joystickSysfsPath(names, id)
+	if j == "" {
+		return gamepadDevice{}
+	}
+	read := func(name string) int {
+		b, err := ioutil.ReadFile(filepath.Join(j, "device", "id", name))
+		if err != nil {
+			return 0
+		}
+		v, err := strconv.ParseInt(strings.TrimSpace(string(b)), 16, 32)
+		if err != nil {
+			return 0
+		}
+		return int(v)
+	}
+	return gamepadDevice{
+		bustype: read("bustype"),
+		vendor:  read("vendor"),
+		product: read("product"),
+		version: read("version"),
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !js
+
+package ui
+
+import (
+	"sync"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+const (
+	wmIMEEndComposition = 0x010E
+	wmIMEComposition    = 0x010F
+
+	gcsCompStr   = 0x0008
+	gcsCursorPos = 0x0080
+)
+
+var (
+	immGetCompositionStringProc = imm32.NewProc("ImmGetCompositionStringW")
+
+	nativeIMEComposition string
+	nativeIMECaret       int
+	imeM                 sync.Mutex
+)
+
+// enableIMEComposition makes the window receive WM_IME_COMPOSITION messages by subclassing the window,
+// since GLFW 3.2 doesn't report the composition text.
+func enableIMEComposition(window *glfw.Window) {
+	if immGetCompositionStringProc.Find() != nil {
+		return
+	}
+	subclassWindow(window)
+}
+
+// handleIMEComposition handles a WM_IME_COMPOSITION message.
+// The message must be passed to the original window procedure after this so that
+// the IME shows its windows and the committed text is sent as WM_CHAR messages.
+func handleIMEComposition(hwnd, lParam uintptr) {
+	if lParam&gcsCompStr == 0 {
+		// The composition text is not changed, or the text is only committed.
+		if lParam&gcsCursorPos == 0 {
+			setIMEComposition("", 0)
+		}
+		return
+	}
+	himc, _, _ := syscall.Syscall(immGetContextProc.Addr(), 1, hwnd, 0, 0)
+	if himc == 0 {
+		return
+	}
+	defer syscall.Syscall(immReleaseContextProc.Addr(), 2, hwnd, himc, 0)
+
+	// The length is in bytes.
+	n, _, _ := syscall.Syscall6(immGetCompositionStringProc.Addr(), 4, himc, gcsCompStr, 0, 0, 0, 0)
+	if int32(n) <= 0 {
+		setIMEComposition("", 0)
+		return
+	}
+	buf := make([]uint16, int32(n)/2)
+	syscall.Syscall6(immGetCompositionStringProc.Addr(), 4, himc, gcsCompStr, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)*2), 0, 0)
+
+	// The caret position is in UTF-16 code units.
+	c, _, _ := syscall.Syscall6(immGetCompositionStringProc.Addr(), 4, himc, gcsCursorPos, 0, 0, 0, 0)
+	caret := int(int32(c))
+	if caret < 0 {
+		caret = 0
+	}
+	if caret > len(buf) {
+		caret = len(buf)
+	}
+	setIMEComposition(string(utf16.Decode(buf)), len(utf16.Decode(buf[:caret])))
+}
+
+func setIMEComposition(text string, caret int) {
+	imeM.Lock()
+	nativeIMEComposition = text
+	nativeIMECaret = caret
+	imeM.Unlock()
+}
+
+// currentIMEComposition returns the text being composed and the caret position in runes.
+func currentIMEComposition() (text string, caret int) {
+	imeM.Lock()
+	defer imeM.Unlock()
+	return nativeIMEComposition, nativeIMECaret
+}
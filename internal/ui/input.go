@@ -14,6 +14,13 @@
 
 package ui
 
+import (
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/hajimehoshi/ebiten/internal/gamepaddb"
+)
+
 var currentInput = &Input{}
 
 type Touch interface {
@@ -40,43 +47,178 @@ func (i *Input) GamepadIDs() []int {
 			r = append(r, id)
 		}
 	}
-	return r
+	return append(r, virtualGamepadIDs()...)
+}
+
+// gamepad returns the gamepad of the given ID, or nil if the gamepad doesn't exist.
+// This must be called with i.m locked.
+func (i *Input) gamepad(id int) *gamePad {
+	if id < 0 {
+		return nil
+	}
+	if id < len(i.gamepads) {
+		if !i.gamepads[id].valid {
+			return nil
+		}
+		return &i.gamepads[id]
+	}
+	return virtualGamepad(id)
+}
+
+func (i *Input) GamepadName(id int) string {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	g := i.gamepad(id)
+	if g == nil {
+		return ""
+	}
+	return g.name
+}
+
+func (i *Input) GamepadGUID(id int) string {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	g := i.gamepad(id)
+	if g == nil {
+		return ""
+	}
+	return g.device.guid(g.name)
+}
+
+func (i *Input) GamepadVendorProductID(id int) (vendor, product int) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	g := i.gamepad(id)
+	if g == nil {
+		return 0, 0
+	}
+	return g.device.vendor, g.device.product
 }
 
 func (i *Input) GamepadAxisNum(id int) int {
 	i.m.RLock()
 	defer i.m.RUnlock()
-	if len(i.gamepads) <= id {
+	g := i.gamepad(id)
+	if g == nil {
 		return 0
 	}
-	return i.gamepads[id].axisNum
+	return g.axisNum
 }
 
 func (i *Input) GamepadAxis(id int, axis int) float64 {
 	i.m.RLock()
 	defer i.m.RUnlock()
-	if len(i.gamepads) <= id {
+	g := i.gamepad(id)
+	if g == nil {
 		return 0
 	}
-	return i.gamepads[id].axes[axis]
+	return g.Axis(axis)
 }
 
 func (i *Input) GamepadButtonNum(id int) int {
 	i.m.RLock()
 	defer i.m.RUnlock()
-	if len(i.gamepads) <= id {
+	g := i.gamepad(id)
+	if g == nil {
 		return 0
 	}
-	return i.gamepads[id].buttonNum
+	return g.buttonNum
 }
 
 func (i *Input) IsGamepadButtonPressed(id int, button GamepadButton) bool {
 	i.m.RLock()
 	defer i.m.RUnlock()
-	if len(i.gamepads) <= id {
+	g := i.gamepad(id)
+	if g == nil {
+		return false
+	}
+	if button < 0 || int(button) >= len(g.buttonPressed) {
+		return false
+	}
+	return g.buttonPressed[button]
+}
+
+func (i *Input) IsStandardGamepadLayoutAvailable(id int) bool {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	g := i.gamepad(id)
+	if g == nil {
 		return false
 	}
-	return i.gamepads[id].buttonPressed[button]
+	return g.standard || gamepaddb.HasMapping(g.device.guid(g.name), g.name)
+}
+
+func (i *Input) StandardGamepadButtonValue(id int, button gamepaddb.StandardButton) float64 {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	g := i.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	if g.standard {
+		if g.Button(int(button)) {
+			return 1
+		}
+		return 0
+	}
+	return gamepaddb.ButtonValue(g.device.guid(g.name), g.name, button, g)
+}
+
+func (i *Input) IsStandardGamepadButtonPressed(id int, button gamepaddb.StandardButton) bool {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	g := i.gamepad(id)
+	if g == nil {
+		return false
+	}
+	if g.standard {
+		return g.Button(int(button))
+	}
+	return gamepaddb.IsButtonPressed(g.device.guid(g.name), g.name, button, g)
+}
+
+func (i *Input) StandardGamepadAxisValue(id int, axis gamepaddb.StandardAxis) float64 {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	g := i.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	if g.standard {
+		return g.Axis(int(axis))
+	}
+	return gamepaddb.AxisValue(g.device.guid(g.name), g.name, axis, g)
+}
+
+func clampMagnitude(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func (in *Input) TouchIDs() []int {
+	in.m.RLock()
+	defer in.m.RUnlock()
+	ids := make([]int, len(in.touches))
+	for i, t := range in.touches {
+		ids[i] = t.id
+	}
+	return ids
+}
+
+func (in *Input) TouchPosition(id int) (x, y int) {
+	in.m.RLock()
+	defer in.m.RUnlock()
+	for _, t := range in.touches {
+		if t.id == id {
+			return adjustCursorPosition(t.x, t.y)
+		}
+	}
+	return 0, 0
 }
 
 func (in *Input) Touches() []Touch {
@@ -89,12 +231,119 @@ func (in *Input) Touches() []Touch {
 	return t
 }
 
+func (i *Input) IsPenInRange() bool {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.pen.inRange
+}
+
+func (i *Input) PenPosition() (x, y int) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	if !i.pen.inRange {
+		return 0, 0
+	}
+	return adjustCursorPosition(i.pen.x, i.pen.y)
+}
+
+func (i *Input) PenPressure() float64 {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.pen.pressure
+}
+
+func (i *Input) PenTilt() (x, y float64) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.pen.tiltX, i.pen.tiltY
+}
+
+func (i *Input) IsPenEraser() bool {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.pen.eraser
+}
+
+func (i *Input) IsCapsLockOn() bool {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.capsLock
+}
+
+func (i *Input) IsNumLockOn() bool {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.numLock
+}
+
+// pen is a pen's state. The position is in the same unit as the cursor position before being adjusted.
+type pen struct {
+	inRange  bool
+	x        int
+	y        int
+	pressure float64
+	tiltX    float64
+	tiltY    float64
+	eraser   bool
+}
+
+// gamepadDevice is the identification of a gamepad device. The zero values mean unknown.
+type gamepadDevice struct {
+	bustype int
+	vendor  int
+	product int
+	version int
+}
+
+// guid returns the GUID in the same format as SDL 2.
+//
+// When the vendor and the product IDs are unknown, the GUID is made from the name in the same way as SDL.
+func (d *gamepadDevice) guid(name string) string {
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint16(b[0:], uint16(d.bustype))
+	if d.vendor != 0 && d.product != 0 {
+		binary.LittleEndian.PutUint16(b[4:], uint16(d.vendor))
+		binary.LittleEndian.PutUint16(b[8:], uint16(d.product))
+		binary.LittleEndian.PutUint16(b[12:], uint16(d.version))
+	} else {
+		copy(b[4:], name)
+	}
+	return hex.EncodeToString(b)
+}
+
 type gamePad struct {
 	valid         bool
+	name          string
+	device        gamepadDevice
 	axisNum       int
 	axes          [16]float64
 	buttonNum     int
 	buttonPressed [256]bool
+
+	// standard is true when the gamepad's buttons and axes are already in the standard layout.
+	standard bool
+}
+
+func (g *gamePad) AxisNum() int {
+	return g.axisNum
+}
+
+func (g *gamePad) Axis(index int) float64 {
+	if index < 0 || g.axisNum <= index || len(g.axes) <= index {
+		return 0
+	}
+	return g.axes[index]
+}
+
+func (g *gamePad) ButtonNum() int {
+	return g.buttonNum
+}
+
+func (g *gamePad) Button(index int) bool {
+	if index < 0 || g.buttonNum <= index || len(g.buttonPressed) <= index {
+		return false
+	}
+	return g.buttonPressed[index]
 }
 
 type touch struct {
@@ -21,20 +21,77 @@ package ui
 
 import (
 	"sync"
+	"time"
 	"unicode"
 
 	glfw "github.com/go-gl/glfw/v3.2/glfw"
 )
 
 type Input struct {
-	keyPressed         map[glfw.Key]bool
-	mouseButtonPressed map[glfw.MouseButton]bool
-	cursorX            int
-	cursorY            int
-	gamepads           [16]gamePad
-	touches            []touch // This is not updated until GLFW 3.3 is available (#417)
-	runeBuffer         []rune
-	m                  sync.RWMutex
+	keyPressed           map[glfw.Key]bool
+	mouseButtonPressed   map[glfw.MouseButton]bool
+	cursorX              int
+	cursorY              int
+	gamepads             [16]gamePad
+	connectedGamepads    []int
+	disconnectedGamepads []int
+	touches              []touch // This is updated only on Windows so far (#417)
+	pen                  pen     // This is updated only on Windows so far
+	runeBuffer           []rune
+	droppedFiles         []string
+	cursorDeltaX         float64
+	cursorDeltaY         float64
+	wheelX               float64
+	wheelY               float64
+	rawMouseDeltaX       float64
+	rawMouseDeltaY       float64
+	rawMouseSupported    bool
+	imeComposition       string
+	imeCaret             int
+	prevCursorX          float64
+	prevCursorY          float64
+	prevCursorValid      bool
+	capsLock             bool
+	numLock              bool
+	m                    sync.RWMutex
+}
+
+func (i *Input) CursorDelta() (float64, float64) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.cursorDeltaX, i.cursorDeltaY
+}
+
+func (i *Input) Wheel() (float64, float64) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.wheelX, i.wheelY
+}
+
+func (i *Input) RawMouseDelta() (float64, float64) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.rawMouseDeltaX, i.rawMouseDeltaY
+}
+
+func (i *Input) IsRawMouseDeltaSupported() bool {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.rawMouseSupported
+}
+
+func (i *Input) resetCursorDelta() {
+	i.m.Lock()
+	defer i.m.Unlock()
+	i.cursorDeltaX = 0
+	i.cursorDeltaY = 0
+	i.prevCursorValid = false
+}
+
+func (i *Input) IMEComposition() (string, int) {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.imeComposition, i.imeCaret
 }
 
 func (i *Input) RuneBuffer() []rune {
@@ -43,6 +100,40 @@ func (i *Input) RuneBuffer() []rune {
 	return i.runeBuffer
 }
 
+func (i *Input) DroppedFiles() []string {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.droppedFiles
+}
+
+func (i *Input) JustConnectedGamepadIDs() []int {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.connectedGamepads
+}
+
+func (i *Input) JustDisconnectedGamepadIDs() []int {
+	i.m.RLock()
+	defer i.m.RUnlock()
+	return i.disconnectedGamepads
+}
+
+func (i *Input) VibrateGamepad(id int, duration time.Duration, strongMagnitude, weakMagnitude float64) {
+	i.m.RLock()
+	if id < 0 || len(i.gamepads) <= id || !i.gamepads[id].valid {
+		i.m.RUnlock()
+		return
+	}
+	names := make([]string, len(i.gamepads))
+	for j := range i.gamepads {
+		if i.gamepads[j].valid {
+			names[j] = i.gamepads[j].name
+		}
+	}
+	i.m.RUnlock()
+	vibrateGamepad(names, id, duration, clampMagnitude(strongMagnitude), clampMagnitude(weakMagnitude))
+}
+
 func (i *Input) IsKeyPressed(key Key) bool {
 	i.m.RLock()
 	defer i.m.RUnlock()
@@ -81,6 +172,11 @@ var glfwMouseButtonToMouseButton = map[glfw.MouseButton]MouseButton{
 	glfw.MouseButtonLeft:   MouseButtonLeft,
 	glfw.MouseButtonRight:  MouseButtonRight,
 	glfw.MouseButtonMiddle: MouseButtonMiddle,
+	glfw.MouseButton4:      MouseButton4,
+	glfw.MouseButton5:      MouseButton5,
+	glfw.MouseButton6:      MouseButton6,
+	glfw.MouseButton7:      MouseButton7,
+	glfw.MouseButton8:      MouseButton8,
 }
 
 func (i *Input) update(window *glfw.Window, scale float64) {
@@ -95,6 +191,17 @@ func (i *Input) update(window *glfw.Window, scale float64) {
 				i.m.Unlock()
 			}
 		})
+		window.SetDropCallback(func(w *glfw.Window, names []string) {
+			i.m.Lock()
+			i.droppedFiles = append(i.droppedFiles, names...)
+			i.m.Unlock()
+		})
+		window.SetScrollCallback(func(w *glfw.Window, xoff, yoff float64) {
+			i.m.Lock()
+			i.wheelX += xoff
+			i.wheelY += yoff
+			i.m.Unlock()
+		})
 	}
 	if i.keyPressed == nil {
 		i.keyPressed = map[glfw.Key]bool{}
@@ -111,12 +218,57 @@ func (i *Input) update(window *glfw.Window, scale float64) {
 	x, y := window.GetCursorPos()
 	i.cursorX = int(x / scale)
 	i.cursorY = int(y / scale)
+	if i.prevCursorValid {
+		i.cursorDeltaX += (x - i.prevCursorX) / scale
+		i.cursorDeltaY += (y - i.prevCursorY) / scale
+	}
+	i.prevCursorX, i.prevCursorY = x, y
+	i.prevCursorValid = true
+	if dx, dy, ok := takeRawMouseDelta(); ok {
+		i.rawMouseDeltaX += float64(dx)
+		i.rawMouseDeltaY += float64(dy)
+		i.rawMouseSupported = true
+	}
+	i.imeComposition, i.imeCaret = currentIMEComposition()
+	ts := currentTouches()
+	i.touches = make([]touch, len(ts))
+	for j, t := range ts {
+		i.touches[j] = touch{
+			id: t.id,
+			x:  int(t.x / scale),
+			y:  int(t.y / scale),
+		}
+	}
+	p := currentPen()
+	i.pen = pen{
+		inRange:  p.inRange,
+		x:        int(p.x / scale),
+		y:        int(p.y / scale),
+		pressure: p.pressure,
+		tiltX:    p.tiltX,
+		tiltY:    p.tiltY,
+		eraser:   p.eraser,
+	}
+	i.capsLock, i.numLock = lockKeyStates()
 	for id := glfw.Joystick(0); id < glfw.Joystick(len(i.gamepads)); id++ {
-		i.gamepads[id].valid = false
-		if !glfw.JoystickPresent(id) {
+		present := glfw.JoystickPresent(id)
+		if present != i.gamepads[id].valid {
+			if present {
+				i.gamepads[id].name = glfw.GetJoystickName(id)
+				names := make([]string, id+1)
+				for j := range names {
+					names[j] = i.gamepads[j].name
+				}
+				i.gamepads[id].device = gamepadDeviceInfo(names, int(id))
+				i.connectedGamepads = append(i.connectedGamepads, int(id))
+			} else {
+				i.disconnectedGamepads = append(i.disconnectedGamepads, int(id))
+			}
+		}
+		i.gamepads[id].valid = present
+		if !present {
 			continue
 		}
-		i.gamepads[id].valid = true
 
 		axes32 := glfw.GetJoystickAxes(id)
 		i.gamepads[id].axisNum = len(axes32)
@@ -17,6 +17,10 @@
 package ui
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gopherjs/gopherjs/js"
 )
 
@@ -28,21 +32,96 @@ func (m mockRWLock) RLock()   {}
 func (m mockRWLock) RUnlock() {}
 
 type Input struct {
-	keyPressed         map[string]bool
-	keyPressedEdge     map[int]bool
-	mouseButtonPressed map[int]bool
-	cursorX            int
-	cursorY            int
-	gamepads           [16]gamePad
-	touches            []touch
-	runeBuffer         []rune
-	m                  mockRWLock
+	keyPressed           map[string]bool
+	keyPressedEdge       map[int]bool
+	mouseButtonPressed   map[int]bool
+	cursorX              int
+	cursorY              int
+	gamepads             [16]gamePad
+	connectedGamepads    []int
+	disconnectedGamepads []int
+	touches              []touch
+	pen                  pen
+	runeBuffer           []rune
+	droppedFiles         []string
+	cursorDeltaX         float64
+	cursorDeltaY         float64
+	wheelX               float64
+	wheelY               float64
+	rawMouseDeltaX       float64
+	rawMouseDeltaY       float64
+	unadjustedMovement   bool
+	capsLock             bool
+	numLock              bool
+	m                    mockRWLock
+}
+
+func (i *Input) CursorDelta() (float64, float64) {
+	return i.cursorDeltaX, i.cursorDeltaY
+}
+
+func (i *Input) Wheel() (float64, float64) {
+	return i.wheelX, i.wheelY
+}
+
+func (i *Input) RawMouseDelta() (float64, float64) {
+	return i.rawMouseDeltaX, i.rawMouseDeltaY
+}
+
+func (i *Input) IsRawMouseDeltaSupported() bool {
+	return i.unadjustedMovement && js.Global.Get("document").Get("pointerLockElement") == canvas
+}
+
+func (i *Input) IMEComposition() (string, int) {
+	return "", 0
 }
 
 func (i *Input) RuneBuffer() []rune {
 	return i.runeBuffer
 }
 
+func (i *Input) DroppedFiles() []string {
+	return i.droppedFiles
+}
+
+func (i *Input) JustConnectedGamepadIDs() []int {
+	return i.connectedGamepads
+}
+
+func (i *Input) JustDisconnectedGamepadIDs() []int {
+	return i.disconnectedGamepads
+}
+
+func (i *Input) VibrateGamepad(id int, duration time.Duration, strongMagnitude, weakMagnitude float64) {
+	if id < 0 || len(i.gamepads) <= id || !i.gamepads[id].valid {
+		return
+	}
+	nav := js.Global.Get("navigator")
+	if nav.Get("getGamepads") == js.Undefined {
+		return
+	}
+	gamepad := nav.Call("getGamepads").Index(id)
+	if gamepad == js.Undefined || gamepad == nil {
+		return
+	}
+	// vibrationActuator is not available on all the browsers.
+	a := gamepad.Get("vibrationActuator")
+	if a == js.Undefined || a == nil {
+		return
+	}
+	if duration <= 0 {
+		if a.Get("reset") != js.Undefined {
+			a.Call("reset")
+		}
+		return
+	}
+	a.Call("playEffect", "dual-rumble", map[string]interface{}{
+		"duration":        float64(duration) / float64(time.Millisecond),
+		"strongMagnitude": clampMagnitude(strongMagnitude),
+		"weakMagnitude":   clampMagnitude(weakMagnitude),
+	})
+}
+
 func (i *Input) IsKeyPressed(key Key) bool {
 	if i.keyPressed != nil {
 		for _, c := range keyToCodes[key] {
@@ -68,6 +147,8 @@ var codeToMouseButton = map[int]MouseButton{
 	0: MouseButtonLeft,
 	1: MouseButtonMiddle,
 	2: MouseButtonRight,
+	3: MouseButton4,
+	4: MouseButton5,
 }
 
 func (i *Input) IsMouseButtonPressed(button MouseButton) bool {
@@ -113,6 +194,15 @@ func (i *Input) keyUpEdge(code int) {
 	i.keyPressedEdge[code] = false
 }
 
+// setLockKeyStates updates the states of Caps Lock and Num Lock from the event e.
+func (i *Input) setLockKeyStates(e *js.Object) {
+	if e.Get("getModifierState") == js.Undefined {
+		return
+	}
+	i.capsLock = e.Call("getModifierState", "CapsLock").Bool()
+	i.numLock = e.Call("getModifierState", "NumLock").Bool()
+}
+
 func (i *Input) mouseDown(code int) {
 	if i.mouseButtonPressed == nil {
 		i.mouseButtonPressed = map[int]bool{}
@@ -138,13 +228,27 @@ func (i *Input) updateGamepads() {
 	}
 	gamepads := nav.Call("getGamepads")
 	l := gamepads.Get("length").Int()
-	for id := 0; id < l; id++ {
-		i.gamepads[id].valid = false
-		gamepad := gamepads.Index(id)
-		if gamepad == js.Undefined || gamepad == nil {
+	for id := 0; id < len(i.gamepads); id++ {
+		var gamepad *js.Object
+		if id < l {
+			gamepad = gamepads.Index(id)
+		}
+		present := gamepad != js.Undefined && gamepad != nil
+		if present != i.gamepads[id].valid {
+			if present {
+				i.gamepads[id].name = gamepad.Get("id").String()
+				i.gamepads[id].device = parseGamepadID(i.gamepads[id].name)
+				// The browser has already remapped the buttons and the axes when mapping is "standard".
+				i.gamepads[id].standard = gamepad.Get("mapping").String() == "standard"
+				i.connectedGamepads = append(i.connectedGamepads, id)
+			} else {
+				i.disconnectedGamepads = append(i.disconnectedGamepads, id)
+			}
+		}
+		i.gamepads[id].valid = present
+		if !present {
 			continue
 		}
-		i.gamepads[id].valid = true
 
 		axes := gamepad.Get("axes")
 		axesNum := axes.Get("length").Int()
@@ -174,3 +278,33 @@ func (i *Input) updateTouches(t []touch) {
 	i.touches = make([]touch, len(t))
 	copy(i.touches, t)
 }
+
+// parseGamepadID parses the vendor and the product IDs in the id of a gamepad.
+//
+// For example, Chrome reports "Xbox 360 Controller (STANDARD GAMEPAD Vendor: 045e Product: 028e)" and
+// Firefox reports "045e-028e-Xbox 360 Controller".
+func parseGamepadID(id string) gamepadDevice {
+	parse := func(s string) int {
+		if len(s) < 4 {
+			return 0
+		}
+		v, err := strconv.ParseInt(s[:4], 16, 32)
+		if err != nil {
+			return 0
+		}
+		return int(v)
+	}
+	if v, p := strings.Index(id, "Vendor: "), strings.Index(id, "Product: "); v >= 0 && p >= 0 {
+		return gamepadDevice{
+			vendor:  parse(id[v+len("Vendor: "):]),
+			product: parse(id[p+len("Product: "):]),
+		}
+	}
+	if len(id) > 10 && id[4] == '-' && id[9] == '-' {
+		return gamepadDevice{
+			vendor:  parse(id[0:4]),
+			product: parse(id[5:9]),
+		}
+	}
+	return gamepadDevice{}
+}
@@ -18,6 +18,7 @@ package ui
 
 import (
 	"sync"
+	"time"
 )
 
 type Input struct {
@@ -25,13 +26,52 @@ type Input struct {
 	cursorY  int
 	gamepads [16]gamePad
 	touches  []touch
+	pen      pen
+	capsLock bool
+	numLock  bool
 	m        sync.RWMutex
 }
 
+func (i *Input) IMEComposition() (string, int) {
+	return "", 0
+}
+
 func (i *Input) RuneBuffer() []rune {
 	return nil
 }
 
+func (i *Input) DroppedFiles() []string {
+	return nil
+}
+
+func (i *Input) CursorDelta() (float64, float64) {
+	return 0, 0
+}
+
+func (i *Input) Wheel() (float64, float64) {
+	return 0, 0
+}
+
+func (i *Input) RawMouseDelta() (float64, float64) {
+	return 0, 0
+}
+
+func (i *Input) IsRawMouseDeltaSupported() bool {
+	return false
+}
+
+func (i *Input) JustConnectedGamepadIDs() []int {
+	return []int{}
+}
+
+func (i *Input) JustDisconnectedGamepadIDs() []int {
+	return []int{}
+}
+
+func (i *Input) VibrateGamepad(id int, duration time.Duration, strongMagnitude, weakMagnitude float64) {
+	// Do nothing
+}
+
 func (i *Input) IsKeyPressed(key Key) bool {
 	return false
 }
@@ -0,0 +1,408 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/internal/gamepaddb"
+)
+
+// InputState is a platform-independent snapshot of the input state at a tick.
+//
+// The positions are already adjusted in the same way as CursorPosition.
+type InputState struct {
+	PressedKeys              []Key
+	PressedMouseButtons      []MouseButton
+	CursorX                  int
+	CursorY                  int
+	CursorDeltaX             float64
+	CursorDeltaY             float64
+	RawMouseDeltaX           float64
+	RawMouseDeltaY           float64
+	RawMouseDeltaSupported   bool
+	WheelX                   float64
+	WheelY                   float64
+	Runes                    []rune
+	IMECompositionText       string
+	IMECompositionCaret      int
+	DroppedFileNames         []string
+	Gamepads                 []GamepadState
+	JustConnectedGamepads    []int
+	JustDisconnectedGamepads []int
+	TouchStates              []TouchState
+	PenInRange               bool
+	PenX                     int
+	PenY                     int
+	PenPressureValue         float64
+	PenTiltX                 float64
+	PenTiltY                 float64
+	PenEraser                bool
+	CapsLockOn               bool
+	NumLockOn                bool
+}
+
+type GamepadState struct {
+	ID        int
+	Name      string
+	GUID      string
+	VendorID  int
+	ProductID int
+	Standard  bool
+	Axes      []float64
+	Buttons   []bool
+}
+
+type TouchState struct {
+	ID int
+	X  int
+	Y  int
+}
+
+var (
+	replayedInputState  *InputState
+	replayedInputStateM sync.RWMutex
+)
+
+// ReplayedInputState returns the input state set by SetReplayedInputState.
+// ReplayedInputState returns nil when no input is replayed.
+func ReplayedInputState() *InputState {
+	replayedInputStateM.RLock()
+	defer replayedInputStateM.RUnlock()
+	return replayedInputState
+}
+
+// SetReplayedInputState sets the input state that is used instead of the actual input.
+// Passing nil restores the actual input.
+func SetReplayedInputState(state *InputState) {
+	replayedInputStateM.Lock()
+	replayedInputState = state
+	replayedInputStateM.Unlock()
+}
+
+// State returns the snapshot of the current input state.
+func (i *Input) State() *InputState {
+	s := &InputState{}
+	for k := Key(0); k <= KeyMax; k++ {
+		if i.IsKeyPressed(k) {
+			s.PressedKeys = append(s.PressedKeys, k)
+		}
+	}
+	for b := MouseButton(0); b <= MouseButtonMax; b++ {
+		if i.IsMouseButtonPressed(b) {
+			s.PressedMouseButtons = append(s.PressedMouseButtons, b)
+		}
+	}
+	s.CursorX, s.CursorY = i.CursorPosition()
+	s.CursorDeltaX, s.CursorDeltaY = i.CursorDelta()
+	s.RawMouseDeltaX, s.RawMouseDeltaY = i.RawMouseDelta()
+	s.RawMouseDeltaSupported = i.IsRawMouseDeltaSupported()
+	s.WheelX, s.WheelY = i.Wheel()
+	s.Runes = append(s.Runes, i.RuneBuffer()...)
+	s.IMECompositionText, s.IMECompositionCaret = i.IMEComposition()
+	s.DroppedFileNames = append(s.DroppedFileNames, i.DroppedFiles()...)
+	s.JustConnectedGamepads = append(s.JustConnectedGamepads, i.JustConnectedGamepadIDs()...)
+	s.JustDisconnectedGamepads = append(s.JustDisconnectedGamepads, i.JustDisconnectedGamepadIDs()...)
+	for _, id := range i.TouchIDs() {
+		x, y := i.TouchPosition(id)
+		s.TouchStates = append(s.TouchStates, TouchState{ID: id, X: x, Y: y})
+	}
+
+	s.PenInRange = i.IsPenInRange()
+	s.PenX, s.PenY = i.PenPosition()
+	s.PenPressureValue = i.PenPressure()
+	s.PenTiltX, s.PenTiltY = i.PenTilt()
+	s.PenEraser = i.IsPenEraser()
+	s.CapsLockOn = i.IsCapsLockOn()
+	s.NumLockOn = i.IsNumLockOn()
+
+	ids := i.GamepadIDs()
+	i.m.RLock()
+	defer i.m.RUnlock()
+	for _, id := range ids {
+		g := i.gamepad(id)
+		if g == nil {
+			continue
+		}
+		gs := GamepadState{
+			ID:        id,
+			Name:      g.name,
+			GUID:      g.device.guid(g.name),
+			VendorID:  g.device.vendor,
+			ProductID: g.device.product,
+			Standard:  g.standard,
+			Axes:      make([]float64, g.axisNum),
+			Buttons:   make([]bool, g.buttonNum),
+		}
+		for a := range gs.Axes {
+			gs.Axes[a] = g.Axis(a)
+		}
+		for b := range gs.Buttons {
+			gs.Buttons[b] = g.Button(b)
+		}
+		s.Gamepads = append(s.Gamepads, gs)
+	}
+	return s
+}
+
+func (s *InputState) IMEComposition() (string, int) {
+	return s.IMECompositionText, s.IMECompositionCaret
+}
+
+func (s *InputState) RuneBuffer() []rune {
+	return s.Runes
+}
+
+func (s *InputState) CursorDelta() (float64, float64) {
+	return s.CursorDeltaX, s.CursorDeltaY
+}
+
+func (s *InputState) RawMouseDelta() (float64, float64) {
+	return s.RawMouseDeltaX, s.RawMouseDeltaY
+}
+
+func (s *InputState) IsRawMouseDeltaSupported() bool {
+	return s.RawMouseDeltaSupported
+}
+
+func (s *InputState) Wheel() (float64, float64) {
+	return s.WheelX, s.WheelY
+}
+
+func (s *InputState) DroppedFiles() []string {
+	return s.DroppedFileNames
+}
+
+func (s *InputState) IsKeyPressed(key Key) bool {
+	for _, k := range s.PressedKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *InputState) CursorPosition() (x, y int) {
+	return s.CursorX, s.CursorY
+}
+
+func (s *InputState) IsMouseButtonPressed(button MouseButton) bool {
+	for _, b := range s.PressedMouseButtons {
+		if b == button {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *InputState) gamepad(id int) *GamepadState {
+	for i := range s.Gamepads {
+		if s.Gamepads[i].ID == id {
+			return &s.Gamepads[i]
+		}
+	}
+	return nil
+}
+
+func (s *InputState) GamepadIDs() []int {
+	r := []int{}
+	for _, g := range s.Gamepads {
+		r = append(r, g.ID)
+	}
+	return r
+}
+
+func (s *InputState) JustConnectedGamepadIDs() []int {
+	return s.JustConnectedGamepads
+}
+
+func (s *InputState) JustDisconnectedGamepadIDs() []int {
+	return s.JustDisconnectedGamepads
+}
+
+func (s *InputState) GamepadName(id int) string {
+	g := s.gamepad(id)
+	if g == nil {
+		return ""
+	}
+	return g.Name
+}
+
+func (s *InputState) GamepadGUID(id int) string {
+	g := s.gamepad(id)
+	if g == nil {
+		return ""
+	}
+	return g.GUID
+}
+
+func (s *InputState) GamepadVendorProductID(id int) (vendor, product int) {
+	g := s.gamepad(id)
+	if g == nil {
+		return 0, 0
+	}
+	return g.VendorID, g.ProductID
+}
+
+func (s *InputState) GamepadAxisNum(id int) int {
+	g := s.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	return len(g.Axes)
+}
+
+func (s *InputState) GamepadAxis(id int, axis int) float64 {
+	g := s.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	return g.Axis(axis)
+}
+
+func (s *InputState) GamepadButtonNum(id int) int {
+	g := s.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	return len(g.Buttons)
+}
+
+func (s *InputState) IsGamepadButtonPressed(id int, button GamepadButton) bool {
+	g := s.gamepad(id)
+	if g == nil {
+		return false
+	}
+	return g.Button(int(button))
+}
+
+func (s *InputState) IsStandardGamepadLayoutAvailable(id int) bool {
+	g := s.gamepad(id)
+	if g == nil {
+		return false
+	}
+	return g.Standard || gamepaddb.HasMapping(g.GUID, g.Name)
+}
+
+func (s *InputState) StandardGamepadButtonValue(id int, button gamepaddb.StandardButton) float64 {
+	g := s.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	if g.Standard {
+		if g.Button(int(button)) {
+			return 1
+		}
+		return 0
+	}
+	return gamepaddb.ButtonValue(g.GUID, g.Name, button, g)
+}
+
+func (s *InputState) IsStandardGamepadButtonPressed(id int, button gamepaddb.StandardButton) bool {
+	g := s.gamepad(id)
+	if g == nil {
+		return false
+	}
+	if g.Standard {
+		return g.Button(int(button))
+	}
+	return gamepaddb.IsButtonPressed(g.GUID, g.Name, button, g)
+}
+
+func (s *InputState) StandardGamepadAxisValue(id int, axis gamepaddb.StandardAxis) float64 {
+	g := s.gamepad(id)
+	if g == nil {
+		return 0
+	}
+	if g.Standard {
+		return g.Axis(int(axis))
+	}
+	return gamepaddb.AxisValue(g.GUID, g.Name, axis, g)
+}
+
+func (s *InputState) TouchIDs() []int {
+	ids := make([]int, len(s.TouchStates))
+	for i, t := range s.TouchStates {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+func (s *InputState) TouchPosition(id int) (x, y int) {
+	for _, t := range s.TouchStates {
+		if t.ID == id {
+			return t.X, t.Y
+		}
+	}
+	return 0, 0
+}
+
+func (s *InputState) Touches() []Touch {
+	t := make([]Touch, len(s.TouchStates))
+	for i := range s.TouchStates {
+		t[i] = &touch{id: s.TouchStates[i].ID, x: s.TouchStates[i].X, y: s.TouchStates[i].Y}
+	}
+	return t
+}
+
+func (s *InputState) IsPenInRange() bool {
+	return s.PenInRange
+}
+
+func (s *InputState) PenPosition() (x, y int) {
+	return s.PenX, s.PenY
+}
+
+func (s *InputState) PenPressure() float64 {
+	return s.PenPressureValue
+}
+
+func (s *InputState) PenTilt() (x, y float64) {
+	return s.PenTiltX, s.PenTiltY
+}
+
+func (s *InputState) IsPenEraser() bool {
+	return s.PenEraser
+}
+
+func (s *InputState) IsCapsLockOn() bool {
+	return s.CapsLockOn
+}
+
+func (s *InputState) IsNumLockOn() bool {
+	return s.NumLockOn
+}
+
+func (g *GamepadState) AxisNum() int {
+	return len(g.Axes)
+}
+
+func (g *GamepadState) Axis(index int) float64 {
+	if index < 0 || len(g.Axes) <= index {
+		return 0
+	}
+	return g.Axes[index]
+}
+
+func (g *GamepadState) ButtonNum() int {
+	return len(g.Buttons)
+}
+
+func (g *GamepadState) Button(index int) bool {
+	if index < 0 || len(g.Buttons) <= index {
+		return false
+	}
+	return g.Buttons[index]
+}
@@ -97,4 +97,5 @@ const (
 	KeySpace
 	KeyTab
 	KeyUp
+	KeyMax = KeyUp
 )
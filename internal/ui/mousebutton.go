@@ -20,4 +20,10 @@ const (
 	MouseButtonLeft MouseButton = iota
 	MouseButtonRight
 	MouseButtonMiddle
+	MouseButton4
+	MouseButton5
+	MouseButton6
+	MouseButton7
+	MouseButton8
+	MouseButtonMax = MouseButton8
 )
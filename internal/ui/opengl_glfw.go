@@ -0,0 +1,48 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build darwin freebsd linux windows
+// +build !js
+// +build !android
+// +build !ios
+// +build !gles
+
+package ui
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+)
+
+// setGraphicsContextHints sets the window hints to create an OpenGL 2.1 context.
+func setGraphicsContextHints() {
+	glfw.WindowHint(glfw.ContextVersionMajor, 2)
+	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+}
+
+// initGraphicsContext initializes the GL context. GL calls are sent to the main thread by runOnMainThread.
+//
+// This must be called on the main thread.
+func initGraphicsContext() {
+	opengl.Init(currentUI.runOnMainThread)
+}
+
+// graphicsWorkAvailable returns nil since there are no GL calls waiting other than runOnMainThread's.
+func graphicsWorkAvailable() <-chan struct{} {
+	return nil
+}
+
+// doGraphicsWork does nothing.
+func doGraphicsWork() {
+}
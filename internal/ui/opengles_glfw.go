@@ -0,0 +1,55 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build darwin freebsd linux windows
+// +build !js
+// +build !android
+// +build !ios
+// +build gles
+
+package ui
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+)
+
+// setGraphicsContextHints sets the window hints to create an OpenGL ES 2.0 context with EGL.
+//
+// On Windows, ANGLE's libEGL.dll and libGLESv2.dll are used.
+func setGraphicsContextHints() {
+	glfw.WindowHint(glfw.ClientAPI, glfw.OpenGLESAPI)
+	glfw.WindowHint(glfw.ContextCreationAPI, glfw.EGLContextAPI)
+	glfw.WindowHint(glfw.ContextVersionMajor, 2)
+	glfw.WindowHint(glfw.ContextVersionMinor, 0)
+}
+
+// initGraphicsContext initializes the GL context. GL calls wait until doGraphicsWork does them on the main thread.
+//
+// This must be called on the main thread.
+func initGraphicsContext() {
+	opengl.Init()
+}
+
+// graphicsWorkAvailable returns a channel that receives a value when GL calls are waiting.
+func graphicsWorkAvailable() <-chan struct{} {
+	return opengl.GetContext().WorkAvailable()
+}
+
+// doGraphicsWork does the waiting GL calls.
+//
+// This must be called on the main thread.
+func doGraphicsWork() {
+	opengl.GetContext().DoPendingWork()
+}
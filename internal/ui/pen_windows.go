@@ -0,0 +1,149 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !js
+
+package ui
+
+import (
+	"encoding/binary"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+const (
+	wmPointerUpdate = 0x0245
+	wmPointerDown   = 0x0246
+	wmPointerUp     = 0x0247
+	wmPointerEnter  = 0x0249
+	wmPointerLeave  = 0x024A
+
+	ptPen = 3
+
+	pointerFlagInRange   = 0x00000002
+	pointerFlagInContact = 0x00000004
+
+	penFlagInverted = 0x00000002
+	penFlagEraser   = 0x00000004
+
+	penMaskPressure = 0x00000001
+	penMaskTiltX    = 0x00000004
+	penMaskTiltY    = 0x00000008
+
+	// pointerInfoSize is the size of POINTER_INFO, which is aligned to 8 bytes on both 32bit and 64bit.
+	pointerInfoSize = (76 + 2*unsafe.Sizeof(uintptr(0)) + 7) &^ 7
+
+	// pointerFlagsOffset is the offset of pointerFlags in POINTER_INFO.
+	pointerFlagsOffset = 12
+)
+
+// pointerPenInfo is POINTER_PEN_INFO.
+type pointerPenInfo struct {
+	pointerInfo [pointerInfoSize]byte
+	penFlags    uint32
+	penMask     uint32
+	pressure    uint32
+	rotation    uint32
+	tiltX       int32
+	tiltY       int32
+}
+
+// nativePen is a pen's state. The unit of the position is physical pixel in the client area.
+type nativePen struct {
+	inRange  bool
+	x        float64
+	y        float64
+	pressure float64
+	tiltX    float64
+	tiltY    float64
+	eraser   bool
+}
+
+var (
+	getPointerTypeProc    = user32.NewProc("GetPointerType")
+	getPointerPenInfoProc = user32.NewProc("GetPointerPenInfo")
+
+	theNativePen nativePen
+	penM         sync.Mutex
+)
+
+// enablePen makes the window receive WM_POINTER messages for pens by subclassing the window,
+// since GLFW 3.2 doesn't support pens.
+//
+// This does nothing before Windows 8.
+func enablePen(window *glfw.Window) {
+	if getPointerTypeProc.Find() != nil || getPointerPenInfoProc.Find() != nil {
+		return
+	}
+	subclassWindow(window)
+}
+
+// handlePointer handles a WM_POINTER message for pens.
+// The message must be passed to the original window procedure after this so that
+// the pen works as a mouse.
+func handlePointer(hwnd, msg, wParam, lParam uintptr) {
+	id := wParam & 0xffff
+	t := uint32(0)
+	if r, _, _ := syscall.Syscall(getPointerTypeProc.Addr(), 2, id, uintptr(unsafe.Pointer(&t)), 0); r == 0 || t != ptPen {
+		return
+	}
+
+	penM.Lock()
+	defer penM.Unlock()
+
+	if msg == wmPointerLeave {
+		theNativePen = nativePen{}
+		return
+	}
+
+	var info pointerPenInfo
+	if r, _, _ := syscall.Syscall(getPointerPenInfoProc.Addr(), 2, id, uintptr(unsafe.Pointer(&info)), 0); r == 0 {
+		return
+	}
+	flags := binary.LittleEndian.Uint32(info.pointerInfo[pointerFlagsOffset:])
+
+	// The position is in screen coordinates.
+	p := point{
+		x: int32(int16(lParam & 0xffff)),
+		y: int32(int16((lParam >> 16) & 0xffff)),
+	}
+	syscall.Syscall(screenToClientProc.Addr(), 2, hwnd, uintptr(unsafe.Pointer(&p)), 0)
+
+	pen := nativePen{
+		inRange: flags&pointerFlagInRange != 0,
+		x:       float64(p.x),
+		y:       float64(p.y),
+		eraser:  info.penFlags&(penFlagInverted|penFlagEraser) != 0,
+	}
+	if flags&pointerFlagInContact != 0 && info.penMask&penMaskPressure != 0 {
+		// The pressure is in [0, 1024].
+		pen.pressure = float64(info.pressure) / 1024
+	}
+	if info.penMask&penMaskTiltX != 0 {
+		pen.tiltX = float64(info.tiltX)
+	}
+	if info.penMask&penMaskTiltY != 0 {
+		pen.tiltY = float64(info.tiltY)
+	}
+	theNativePen = pen
+}
+
+func currentPen() nativePen {
+	penM.Lock()
+	defer penM.Unlock()
+	return theNativePen
+}
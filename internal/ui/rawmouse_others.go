@@ -0,0 +1,32 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build darwin freebsd linux
+// +build !js
+// +build !android
+// +build !ios
+
+package ui
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+func enableRawMouseMotion(window *glfw.Window) {
+	// TODO: Implement this e.g. with XInput2 raw events on X11.
+}
+
+func takeRawMouseDelta() (dx, dy int, ok bool) {
+	return 0, 0, false
+}
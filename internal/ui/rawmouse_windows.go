@@ -0,0 +1,128 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !js
+
+package ui
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+const (
+	wmInput = 0x00FF
+
+	ridInput          = 0x10000003
+	rimTypeMouse      = 0
+	mouseMoveAbsolute = 0x01
+
+	hidUsagePageGeneric = 0x01
+	hidUsageMouse       = 0x02
+)
+
+// rawInputDevice is RAWINPUTDEVICE.
+type rawInputDevice struct {
+	usUsagePage uint16
+	usUsage     uint16
+	dwFlags     uint32
+	hwndTarget  uintptr
+}
+
+// rawInputHeader is RAWINPUTHEADER.
+type rawInputHeader struct {
+	dwType  uint32
+	dwSize  uint32
+	hDevice uintptr
+	wParam  uintptr
+}
+
+// rawMouse is RAWMOUSE.
+type rawMouse struct {
+	usFlags            uint16
+	_                  uint16
+	ulButtons          uint32
+	ulRawButtons       uint32
+	lLastX             int32
+	lLastY             int32
+	ulExtraInformation uint32
+}
+
+// rawInput is RAWINPUT for mouses.
+type rawInput struct {
+	header rawInputHeader
+	mouse  rawMouse
+}
+
+var (
+	registerRawInputDevicesProc = user32.NewProc("RegisterRawInputDevices")
+	getRawInputDataProc         = user32.NewProc("GetRawInputData")
+
+	rawMouseMotionEnabled bool
+	nativeRawMouseDeltaX  int
+	nativeRawMouseDeltaY  int
+	rawMouseM             sync.Mutex
+)
+
+// enableRawMouseMotion makes the window receive WM_INPUT messages for mouses by subclassing the window,
+// since GLFW 3.2 doesn't support raw mouse motion.
+func enableRawMouseMotion(window *glfw.Window) {
+	if registerRawInputDevicesProc.Find() != nil || getRawInputDataProc.Find() != nil {
+		return
+	}
+	d := rawInputDevice{
+		usUsagePage: hidUsagePageGeneric,
+		usUsage:     hidUsageMouse,
+		hwndTarget:  windowHandle(window),
+	}
+	if r, _, _ := syscall.Syscall(registerRawInputDevicesProc.Addr(), 3, uintptr(unsafe.Pointer(&d)), 1, unsafe.Sizeof(d)); r == 0 {
+		return
+	}
+	subclassWindow(window)
+	rawMouseM.Lock()
+	rawMouseMotionEnabled = true
+	rawMouseM.Unlock()
+}
+
+func handleRawInput(lParam uintptr) {
+	var in rawInput
+	size := uint32(unsafe.Sizeof(in))
+	if r, _, _ := syscall.Syscall6(getRawInputDataProc.Addr(), 5, lParam, ridInput, uintptr(unsafe.Pointer(&in)), uintptr(unsafe.Pointer(&size)), unsafe.Sizeof(in.header), 0); int32(r) <= 0 {
+		return
+	}
+	if in.header.dwType != rimTypeMouse {
+		return
+	}
+	// Absolute positions are reported e.g. by remote desktops and tablets. Ignore them.
+	if in.mouse.usFlags&mouseMoveAbsolute != 0 {
+		return
+	}
+	rawMouseM.Lock()
+	nativeRawMouseDeltaX += int(in.mouse.lLastX)
+	nativeRawMouseDeltaY += int(in.mouse.lLastY)
+	rawMouseM.Unlock()
+}
+
+// takeRawMouseDelta returns the raw mouse motion since the previous call.
+// The second returned value is false when raw mouse motion is not supported.
+func takeRawMouseDelta() (dx, dy int, ok bool) {
+	rawMouseM.Lock()
+	defer rawMouseM.Unlock()
+	dx, dy = nativeRawMouseDeltaX, nativeRawMouseDeltaY
+	nativeRawMouseDeltaX, nativeRawMouseDeltaY = 0, 0
+	return dx, dy, rawMouseMotionEnabled
+}
@@ -0,0 +1,132 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !js
+
+package ui
+
+import (
+	"sort"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+const (
+	wmTouch = 0x0240
+
+	touchEventfDown = 0x0002
+	touchEventfUp   = 0x0004
+)
+
+// touchInput is TOUCHINPUT.
+type touchInput struct {
+	x           int32
+	y           int32
+	hSource     uintptr
+	dwID        uint32
+	dwFlags     uint32
+	dwMask      uint32
+	dwTime      uint32
+	dwExtraInfo uintptr
+	cxContact   uint32
+	cyContact   uint32
+}
+
+// nativeTouch is a touch in the client area. The unit is physical pixel.
+type nativeTouch struct {
+	id int
+	x  float64
+	y  float64
+}
+
+var (
+	registerTouchWindowProc   = user32.NewProc("RegisterTouchWindow")
+	getTouchInputInfoProc     = user32.NewProc("GetTouchInputInfo")
+	closeTouchInputHandleProc = user32.NewProc("CloseTouchInputHandle")
+	screenToClientProc        = user32.NewProc("ScreenToClient")
+
+	// nativeTouches is the current touches. The keys are the touch IDs OS gives.
+	nativeTouches = map[uint32]nativeTouch{}
+	touchM        sync.Mutex
+)
+
+// enableTouch makes the window receive WM_TOUCH messages by subclassing the window,
+// since GLFW 3.2 doesn't support touches.
+//
+// This does nothing before Windows 7.
+func enableTouch(window *glfw.Window) {
+	if registerTouchWindowProc.Find() != nil {
+		return
+	}
+	h := windowHandle(window)
+	if r, _, _ := syscall.Syscall(registerTouchWindowProc.Addr(), 2, h, 0, 0); r == 0 {
+		return
+	}
+	subclassWindow(window)
+}
+
+// handleTouch handles a WM_TOUCH message, and returns false when the message is not handled.
+func handleTouch(hwnd, wParam, lParam uintptr) bool {
+	n := int(wParam & 0xffff)
+	inputs := make([]touchInput, n)
+	if r, _, _ := syscall.Syscall6(getTouchInputInfoProc.Addr(), 4, lParam, uintptr(n), uintptr(unsafe.Pointer(&inputs[0])), unsafe.Sizeof(touchInput{}), 0, 0); r == 0 {
+		return false
+	}
+
+	touchM.Lock()
+	for _, in := range inputs {
+		if in.dwFlags&touchEventfUp != 0 {
+			delete(nativeTouches, in.dwID)
+			continue
+		}
+		// The position is in hundredths of a physical screen pixel.
+		p := point{
+			x: in.x / 100,
+			y: in.y / 100,
+		}
+		syscall.Syscall(screenToClientProc.Addr(), 2, hwnd, uintptr(unsafe.Pointer(&p)), 0)
+		if _, ok := nativeTouches[in.dwID]; !ok && in.dwFlags&touchEventfDown == 0 {
+			// Ignore a touch whose beginning was not received.
+			continue
+		}
+		nativeTouches[in.dwID] = nativeTouch{
+			id: int(in.dwID),
+			x:  float64(p.x) + float64(in.x%100)/100,
+			y:  float64(p.y) + float64(in.y%100)/100,
+		}
+	}
+	touchM.Unlock()
+
+	syscall.Syscall(closeTouchInputHandleProc.Addr(), 1, lParam, 0, 0)
+	return true
+}
+
+// currentTouches returns the current touches ordered by their IDs.
+func currentTouches() []nativeTouch {
+	touchM.Lock()
+	defer touchM.Unlock()
+	ids := make([]int, 0, len(nativeTouches))
+	for id := range nativeTouches {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	ts := make([]nativeTouch, len(ids))
+	for i, id := range ids {
+		ts[i] = nativeTouches[uint32(id)]
+	}
+	return ts
+}
@@ -14,12 +14,30 @@
 
 package ui
 
+import (
+	"image"
+)
+
 type GraphicsContext interface {
 	SetSize(width, height int, scale float64)
 	Update(afterFrameUpdate func()) error
 	Invalidate()
 }
 
+type VideoMode struct {
+	Width       int
+	Height      int
+	RefreshRate int
+}
+
+type Monitor struct {
+	ID          int
+	Name        string
+	Bounds      image.Rectangle
+	WorkArea    image.Rectangle
+	DeviceScale float64
+}
+
 type RegularTermination struct {
 }
 
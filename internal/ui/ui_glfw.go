@@ -23,6 +23,7 @@ import (
 	"image"
 	"math"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,33 +32,79 @@ import (
 )
 
 type userInterface struct {
-	title                string
-	window               *glfw.Window
-	width                int
-	windowWidth          int
-	height               int
-	scale                float64
-	cachedDeviceScale    float64
-	cachedGLFWScale      float64
-	fullscreenScale      float64
-	funcs                chan func()
-	running              bool
-	sizeChanged          bool
-	origPosX             int
-	origPosY             int
-	initFullscreen       bool
-	initCursorVisible    bool
-	initIconImages       []image.Image
-	runnableInBackground bool
-	m                    sync.Mutex
+	title                  string
+	window                 *glfw.Window
+	width                  int
+	windowWidth            int
+	height                 int
+	scale                  float64
+	cachedDeviceScale      float64
+	cachedGLFWScale        float64
+	fullscreenScale        float64
+	funcs                  chan func()
+	running                bool
+	sizeChanged            bool
+	origPosX               int
+	origPosY               int
+	initWindowPosX         int
+	initWindowPosY         int
+	initWindowPosSet       bool
+	initFullscreen         bool
+	initCursorMode         CursorMode
+	initIconImages         []image.Image
+	runnableInBackground   bool
+	fullscreenMonitorID    int
+	fullscreenBorderless   bool
+	inBorderlessFullscreen bool
+	integerScaling         bool
+	fpsMode                FPSMode
+	frameScheduled         bool
+	decorated              bool
+	floating               bool
+	iconified              bool
+	initWindowMaximized    bool
+	minWindowWidthLimit    int
+	minWindowHeightLimit   int
+	maxWindowWidthLimit    int
+	maxWindowHeightLimit   int
+	windowAspectNumer      int
+	windowAspectDenom      int
+	initScreenTransparent  bool
+	windowOpacity          float64
+	cursor                 *glfw.Cursor
+	initCursorImage        image.Image
+	initCursorHotX         int
+	initCursorHotY         int
+	initCursorShape        CursorShape
+	focusChangedCallback   func(focused bool)
+	lastFocused            bool
+	windowClosingHandled   bool
+	windowBeingClosed      bool
+	windowMoved            bool
+	deviceScaleCallback    func(scale float64)
+	monitorsChanged        bool
+	monitorsCallback       func()
+	m                      sync.Mutex
 }
 
 var (
 	currentUI = &userInterface{
-		sizeChanged:       true,
-		origPosX:          -1,
-		origPosY:          -1,
-		initCursorVisible: true,
+		sizeChanged:   true,
+		origPosX:      -1,
+		origPosY:      -1,
+		fpsMode:       FPSModeVsyncOn,
+		decorated:     true,
+		windowOpacity: 1,
+		lastFocused:   true,
+
+		frameScheduled: true,
+
+		minWindowWidthLimit:  -1,
+		minWindowHeightLimit: -1,
+		maxWindowWidthLimit:  -1,
+		maxWindowHeightLimit: -1,
+		windowAspectNumer:    -1,
+		windowAspectDenom:    -1,
 	}
 	currentUIInitialized = make(chan struct{})
 )
@@ -72,8 +119,16 @@ func initialize() error {
 	}
 	glfw.WindowHint(glfw.Visible, glfw.False)
 	glfw.WindowHint(glfw.Resizable, glfw.False)
-	glfw.WindowHint(glfw.ContextVersionMajor, 2)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+	setGraphicsContextHints()
+	if !currentUI.isDecorated() {
+		glfw.WindowHint(glfw.Decorated, glfw.False)
+	}
+	if currentUI.isFloating() {
+		glfw.WindowHint(glfw.Floating, glfw.True)
+	}
+	if opengl.IsSRGBRequested() {
+		glfw.WindowHint(glfw.SRGBCapable, glfw.True)
+	}
 
 	// As start, create an window with temporary size to create OpenGL context thread.
 	window, err := glfw.CreateWindow(16, 16, "", nil, nil)
@@ -85,17 +140,43 @@ func initialize() error {
 	currentUI.funcs = make(chan func())
 
 	currentUI.window.MakeContextCurrent()
+	// The GL context must be initialized before Run sets the screen size, which requires swapping buffers.
+	initGraphicsContext()
 
-	mode := glfw.CursorNormal
-	if !currentUI.isInitCursorVisible() {
-		mode = glfw.CursorHidden
+	if currentUI.isInitScreenTransparent() {
+		setWindowTransparent(currentUI.window)
+	}
+	if o := currentUI.getWindowOpacity(); o < 1 {
+		setWindowOpacity(currentUI.window, o)
 	}
+
 	if i := currentUI.getInitIconImages(); i != nil {
 		currentUI.window.SetIcon(i)
 	}
-	currentUI.window.SetInputMode(glfw.CursorMode, mode)
+	if img, x, y := currentUI.getInitCursorImage(); img != nil {
+		currentUI.setCursorImage(img, x, y)
+	} else if s := currentUI.getInitCursorShape(); s != CursorShapeDefault {
+		currentUI.setCursorShape(s)
+	}
+	currentUI.window.SetInputMode(glfw.CursorMode, glfwCursorModes[currentUI.getInitCursorMode()])
 	currentUI.window.SetInputMode(glfw.StickyMouseButtonsMode, glfw.True)
 	currentUI.window.SetInputMode(glfw.StickyKeysMode, glfw.True)
+	currentUI.window.SetIconifyCallback(func(_ *glfw.Window, iconified bool) {
+		currentUI.iconified = iconified
+	})
+	enableTouch(currentUI.window)
+	enableRawMouseMotion(currentUI.window)
+	enablePen(currentUI.window)
+	enableIMEComposition(currentUI.window)
+	currentUI.window.SetPosCallback(func(_ *glfw.Window, _, _ int) {
+		// The window might be moved to another monitor with a different device scale.
+		currentUI.windowMoved = true
+	})
+	glfw.SetMonitorCallback(func(_ *glfw.Monitor, _ glfw.MonitorEvent) {
+		currentUI.monitorsChanged = true
+		// The monitor where the window is might be disconnected.
+		currentUI.windowMoved = true
+	})
 	return nil
 }
 
@@ -115,7 +196,11 @@ func RunMainThreadLoop(ch <-chan error) error {
 	for {
 		select {
 		case f := <-currentUI.funcs:
+			// The waiting GL calls must be done before f, e.g. before swapping buffers.
+			doGraphicsWork()
 			f()
+		case <-graphicsWorkAvailable():
+			doGraphicsWork()
 		case err := <-ch:
 			// ch returns a value not only when an error occur but also it is closed.
 			return err
@@ -136,138 +221,1145 @@ func (u *userInterface) setRunning(running bool) {
 	u.m.Unlock()
 }
 
-func (u *userInterface) isInitFullscreen() bool {
-	u.m.Lock()
-	v := u.initFullscreen
-	u.m.Unlock()
-	return v
+func (u *userInterface) isInitFullscreen() bool {
+	u.m.Lock()
+	v := u.initFullscreen
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setInitFullscreen(initFullscreen bool) {
+	u.m.Lock()
+	u.initFullscreen = initFullscreen
+	u.m.Unlock()
+}
+
+func (u *userInterface) getInitCursorMode() CursorMode {
+	u.m.Lock()
+	v := u.initCursorMode
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setInitCursorMode(mode CursorMode) {
+	u.m.Lock()
+	u.initCursorMode = mode
+	u.m.Unlock()
+}
+
+func (u *userInterface) isRunnableInBackground() bool {
+	u.m.Lock()
+	v := u.runnableInBackground
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setRunnableInBackground(runnableInBackground bool) {
+	u.m.Lock()
+	u.runnableInBackground = runnableInBackground
+	u.m.Unlock()
+}
+
+func (u *userInterface) getFPSMode() FPSMode {
+	u.m.Lock()
+	v := u.fpsMode
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setFPSMode(mode FPSMode) {
+	u.m.Lock()
+	u.fpsMode = mode
+	// Render at least one frame with the new mode.
+	u.frameScheduled = true
+	u.m.Unlock()
+}
+
+func (u *userInterface) isVsyncEnabled() bool {
+	return u.getFPSMode() == FPSModeVsyncOn
+}
+
+func (u *userInterface) scheduleFrame() {
+	u.m.Lock()
+	u.frameScheduled = true
+	u.m.Unlock()
+}
+
+// takeFrameScheduled reports whether a frame is scheduled, and resets the state.
+func (u *userInterface) takeFrameScheduled() bool {
+	u.m.Lock()
+	v := u.frameScheduled
+	u.frameScheduled = false
+	u.m.Unlock()
+	return v
+}
+
+// updateVsync applies the current vsync setting to the OpenGL context.
+//
+// This must be called on the main thread.
+func (u *userInterface) updateVsync() {
+	if u.isVsyncEnabled() {
+		glfw.SwapInterval(1)
+		return
+	}
+	glfw.SwapInterval(0)
+}
+
+func (u *userInterface) isDecorated() bool {
+	u.m.Lock()
+	v := u.decorated
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setDecorated(decorated bool) {
+	u.m.Lock()
+	u.decorated = decorated
+	u.m.Unlock()
+}
+
+func (u *userInterface) isFloating() bool {
+	u.m.Lock()
+	v := u.floating
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setFloating(floating bool) {
+	u.m.Lock()
+	u.floating = floating
+	u.m.Unlock()
+}
+
+func (u *userInterface) getWindowSizeLimits() (minw, minh, maxw, maxh int) {
+	u.m.Lock()
+	minw, minh = u.minWindowWidthLimit, u.minWindowHeightLimit
+	maxw, maxh = u.maxWindowWidthLimit, u.maxWindowHeightLimit
+	u.m.Unlock()
+	return
+}
+
+func (u *userInterface) setWindowSizeLimits(minw, minh, maxw, maxh int) {
+	u.m.Lock()
+	u.minWindowWidthLimit, u.minWindowHeightLimit = minw, minh
+	u.maxWindowWidthLimit, u.maxWindowHeightLimit = maxw, maxh
+	u.m.Unlock()
+}
+
+// updateWindowSizeLimits applies the current window size limits to the window.
+//
+// This must be called on the main thread.
+func (u *userInterface) updateWindowSizeLimits() {
+	toGLFW := func(v int) int {
+		if v < 0 {
+			return glfw.DontCare
+		}
+		return int(float64(v) * u.glfwScale())
+	}
+	minw, minh, maxw, maxh := u.getWindowSizeLimits()
+	u.window.SetSizeLimits(toGLFW(minw), toGLFW(minh), toGLFW(maxw), toGLFW(maxh))
+}
+
+func (u *userInterface) getWindowAspectRatio() (numer, denom int) {
+	u.m.Lock()
+	numer, denom = u.windowAspectNumer, u.windowAspectDenom
+	u.m.Unlock()
+	return
+}
+
+func (u *userInterface) setWindowAspectRatio(numer, denom int) {
+	u.m.Lock()
+	u.windowAspectNumer, u.windowAspectDenom = numer, denom
+	u.m.Unlock()
+}
+
+// updateWindowAspectRatio applies the current window aspect ratio to the window.
+//
+// This must be called on the main thread.
+func (u *userInterface) updateWindowAspectRatio() {
+	numer, denom := u.getWindowAspectRatio()
+	if numer <= 0 || denom <= 0 {
+		u.window.SetAspectRatio(glfw.DontCare, glfw.DontCare)
+		return
+	}
+	u.window.SetAspectRatio(numer, denom)
+}
+
+func (u *userInterface) getInitWindowPosition() (x, y int, ok bool) {
+	u.m.Lock()
+	x, y, ok = u.initWindowPosX, u.initWindowPosY, u.initWindowPosSet
+	u.m.Unlock()
+	return
+}
+
+func (u *userInterface) setInitWindowPosition(x, y int) {
+	u.m.Lock()
+	u.initWindowPosX, u.initWindowPosY = x, y
+	u.initWindowPosSet = true
+	u.m.Unlock()
+}
+
+func (u *userInterface) isInitWindowMaximized() bool {
+	u.m.Lock()
+	v := u.initWindowMaximized
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setInitWindowMaximized(maximized bool) {
+	u.m.Lock()
+	u.initWindowMaximized = maximized
+	u.m.Unlock()
+}
+
+func (u *userInterface) isInitScreenTransparent() bool {
+	u.m.Lock()
+	v := u.initScreenTransparent
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setInitScreenTransparent(transparent bool) {
+	u.m.Lock()
+	u.initScreenTransparent = transparent
+	u.m.Unlock()
+}
+
+func (u *userInterface) getWindowOpacity() float64 {
+	u.m.Lock()
+	v := u.windowOpacity
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setWindowOpacity(opacity float64) {
+	u.m.Lock()
+	u.windowOpacity = opacity
+	u.m.Unlock()
+}
+
+func (u *userInterface) getInitIconImages() []image.Image {
+	u.m.Lock()
+	i := u.initIconImages
+	u.m.Unlock()
+	return i
+}
+
+func (u *userInterface) setInitIconImages(iconImages []image.Image) {
+	u.m.Lock()
+	u.initIconImages = iconImages
+	u.m.Unlock()
+}
+
+func (u *userInterface) getInitCursorImage() (image.Image, int, int) {
+	u.m.Lock()
+	img, x, y := u.initCursorImage, u.initCursorHotX, u.initCursorHotY
+	u.m.Unlock()
+	return img, x, y
+}
+
+func (u *userInterface) setInitCursorImage(img image.Image, hotX, hotY int) {
+	u.m.Lock()
+	u.initCursorImage, u.initCursorHotX, u.initCursorHotY = img, hotX, hotY
+	u.initCursorShape = CursorShapeDefault
+	u.m.Unlock()
+}
+
+func (u *userInterface) getInitCursorShape() CursorShape {
+	u.m.Lock()
+	s := u.initCursorShape
+	u.m.Unlock()
+	return s
+}
+
+func (u *userInterface) setInitCursorShape(shape CursorShape) {
+	u.m.Lock()
+	u.initCursorShape = shape
+	u.initCursorImage = nil
+	u.m.Unlock()
+}
+
+// setCursor replaces the cursor of the window and destroys the previous one.
+// If c is nil, the cursor reverts to the default one.
+//
+// This must be called on the main thread.
+func (u *userInterface) setCursor(c *glfw.Cursor) {
+	u.window.SetCursor(c)
+	if u.cursor != nil {
+		u.cursor.Destroy()
+	}
+	u.cursor = c
+}
+
+// setCursorImage replaces the cursor of the window with the given image.
+// If img is nil, the cursor reverts to the default one.
+//
+// This must be called on the main thread.
+func (u *userInterface) setCursorImage(img image.Image, hotX, hotY int) {
+	if img == nil {
+		u.setCursor(nil)
+		return
+	}
+	u.setCursor(glfw.CreateCursor(img, hotX, hotY))
+}
+
+var glfwCursorShapes = map[CursorShape]glfw.StandardCursor{
+	CursorShapeText:      glfw.IBeamCursor,
+	CursorShapeCrosshair: glfw.CrosshairCursor,
+	CursorShapePointer:   glfw.HandCursor,
+	CursorShapeEWResize:  glfw.HResizeCursor,
+	CursorShapeNSResize:  glfw.VResizeCursor,
+}
+
+// setCursorShape replaces the cursor of the window with the given standard shape.
+//
+// This must be called on the main thread.
+func (u *userInterface) setCursorShape(shape CursorShape) {
+	s, ok := glfwCursorShapes[shape]
+	if !ok {
+		// CursorShapeDefault and unknown shapes.
+		u.setCursor(nil)
+		return
+	}
+	u.setCursor(glfw.CreateStandardCursor(s))
+}
+
+func (u *userInterface) getFocusChangedCallback() func(focused bool) {
+	u.m.Lock()
+	f := u.focusChangedCallback
+	u.m.Unlock()
+	return f
+}
+
+func (u *userInterface) setFocusChangedCallback(f func(focused bool)) {
+	u.m.Lock()
+	u.focusChangedCallback = f
+	u.m.Unlock()
+}
+
+// updateFocus calls the focus-changed callback if the focus state is changed.
+//
+// This must be called on the game's goroutine, not on the main thread.
+func (u *userInterface) updateFocus(focused bool) {
+	if u.lastFocused == focused {
+		return
+	}
+	u.lastFocused = focused
+	if f := u.getFocusChangedCallback(); f != nil {
+		f(focused)
+	}
+}
+
+func (u *userInterface) isWindowClosingHandled() bool {
+	u.m.Lock()
+	v := u.windowClosingHandled
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setWindowClosingHandled(handled bool) {
+	u.m.Lock()
+	u.windowClosingHandled = handled
+	u.m.Unlock()
+}
+
+func (u *userInterface) isWindowBeingClosed() bool {
+	u.m.Lock()
+	v := u.windowBeingClosed
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setWindowBeingClosed(closed bool) {
+	u.m.Lock()
+	u.windowBeingClosed = closed
+	u.m.Unlock()
+}
+
+func (u *userInterface) getDeviceScaleCallback() func(scale float64) {
+	u.m.Lock()
+	f := u.deviceScaleCallback
+	u.m.Unlock()
+	return f
+}
+
+func (u *userInterface) setDeviceScaleCallback(f func(scale float64)) {
+	u.m.Lock()
+	u.deviceScaleCallback = f
+	u.m.Unlock()
+}
+
+func (u *userInterface) getMonitorsCallback() func() {
+	u.m.Lock()
+	f := u.monitorsCallback
+	u.m.Unlock()
+	return f
+}
+
+func (u *userInterface) setMonitorsCallback(f func()) {
+	u.m.Lock()
+	u.monitorsCallback = f
+	u.m.Unlock()
+}
+
+// updateDeviceScale updates the cached device scale with the scale of the monitor
+// where the window is, and returns true if the scale is changed.
+//
+// This must be called on the main thread.
+func (u *userInterface) updateDeviceScale() bool {
+	s := windowDeviceScale(u.window)
+	if s == u.deviceScale() {
+		return false
+	}
+	u.cachedDeviceScale = s
+	u.cachedGLFWScale = windowGLFWScale(u.window)
+	if u.fullscreen() {
+		u.fullscreenScale = 0
+		u.sizeChanged = true
+		return true
+	}
+	// Resize the window with the new scale.
+	u.forceSetScreenSize(u.width, u.height, u.scale, false)
+	return true
+}
+
+func (u *userInterface) getFullscreenMonitorID() int {
+	u.m.Lock()
+	v := u.fullscreenMonitorID
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setFullscreenMonitorID(id int) {
+	u.m.Lock()
+	u.fullscreenMonitorID = id
+	u.m.Unlock()
+}
+
+func (u *userInterface) isFullscreenBorderless() bool {
+	u.m.Lock()
+	v := u.fullscreenBorderless
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setFullscreenBorderless(borderless bool) {
+	u.m.Lock()
+	u.fullscreenBorderless = borderless
+	u.m.Unlock()
+}
+
+func (u *userInterface) isIntegerScalingEnabled() bool {
+	u.m.Lock()
+	v := u.integerScaling
+	u.m.Unlock()
+	return v
+}
+
+func (u *userInterface) setIntegerScalingEnabled(enabled bool) {
+	u.m.Lock()
+	u.integerScaling = enabled
+	u.m.Unlock()
+}
+
+// fullscreenMonitor returns the monitor used for fullscreen mode.
+//
+// If the specified monitor is not connected, the primary monitor is returned.
+//
+// This must be called on the main thread.
+func (u *userInterface) fullscreenMonitor() *glfw.Monitor {
+	ms := glfw.GetMonitors()
+	if id := u.getFullscreenMonitorID(); 0 <= id && id < len(ms) {
+		return ms[id]
+	}
+	return glfw.GetPrimaryMonitor()
+}
+
+// currentMonitor returns the monitor where the window is.
+//
+// In windowed mode, the monitor that contains the center of the window is returned.
+// If there is no such monitor, the primary monitor is returned.
+//
+// This must be called on the main thread.
+func (u *userInterface) currentMonitor() *glfw.Monitor {
+	if m := u.window.GetMonitor(); m != nil {
+		return m
+	}
+	if u.inBorderlessFullscreen {
+		return u.fullscreenMonitor()
+	}
+	x, y := u.window.GetPos()
+	w, h := u.window.GetSize()
+	cx, cy := x+w/2, y+h/2
+	for _, m := range glfw.GetMonitors() {
+		mx, my := m.GetPos()
+		v := m.GetVideoMode()
+		if mx <= cx && cx < mx+v.Width && my <= cy && cy < my+v.Height {
+			return m
+		}
+	}
+	return glfw.GetPrimaryMonitor()
+}
+
+// attachWindowToMonitor makes the window fill the given monitor.
+//
+// In borderless fullscreen mode, the window is not attached to the monitor actually,
+// but the decoration is removed and the window covers the whole monitor.
+//
+// This must be called on the main thread.
+func (u *userInterface) attachWindowToMonitor(m *glfw.Monitor) {
+	v := m.GetVideoMode()
+	if !u.isFullscreenBorderless() {
+		u.window.SetMonitor(m, 0, 0, v.Width, v.Height, v.RefreshRate)
+		return
+	}
+	// GLFW 3.2 doesn't have an API to change decoration of an existing window.
+	// Change it in a platform-specific way.
+	setWindowDecorated(u.window, false)
+	x, y := m.GetPos()
+	setWindowBounds(u.window, x, y, v.Width, v.Height)
+	u.inBorderlessFullscreen = true
+}
+
+func (u *userInterface) runOnMainThread(f func() error) error {
+	if u.funcs == nil {
+		// already closed
+		return nil
+	}
+	ch := make(chan struct{})
+	var err error
+	u.funcs <- func() {
+		err = f()
+		close(ch)
+	}
+	<-ch
+	return err
+}
+
+func SetScreenSize(width, height int) bool {
+	u := currentUI
+	if !u.isRunning() {
+		panic("ui: Run is not called yet")
+	}
+	r := false
+	_ = u.runOnMainThread(func() error {
+		r = u.setScreenSize(width, height, u.scale, u.fullscreen())
+		return nil
+	})
+	return r
+}
+
+func SetScreenScale(scale float64) bool {
+	u := currentUI
+	if !u.isRunning() {
+		panic("ui: Run is not called yet")
+	}
+	r := false
+	_ = u.runOnMainThread(func() error {
+		r = u.setScreenSize(u.width, u.height, scale, u.fullscreen())
+		return nil
+	})
+	return r
+}
+
+func SetScreenSizeAndScale(width, height int, scale float64) bool {
+	u := currentUI
+	if !u.isRunning() {
+		panic("ui: Run is not called yet")
+	}
+	r := false
+	_ = u.runOnMainThread(func() error {
+		r = u.setScreenSize(width, height, scale, u.fullscreen())
+		return nil
+	})
+	return r
+}
+
+func ScreenScale() float64 {
+	u := currentUI
+	if !u.isRunning() {
+		return 0
+	}
+	s := 0.0
+	_ = u.runOnMainThread(func() error {
+		s = u.scale
+		return nil
+	})
+	return s
+}
+
+func (u *userInterface) fullscreen() bool {
+	if !u.isRunning() {
+		panic("not reached")
+	}
+	return u.window.GetMonitor() != nil || u.inBorderlessFullscreen
+}
+
+func IsFullscreen() bool {
+	u := currentUI
+	if !u.isRunning() {
+		return u.isInitFullscreen()
+	}
+	return u.fullscreen()
+}
+
+func SetFullscreen(fullscreen bool) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setInitFullscreen(fullscreen)
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		u := currentUI
+		u.setScreenSize(u.width, u.height, u.scale, fullscreen)
+		return nil
+	})
+}
+
+func MonitorIDs() []int {
+	u := currentUI
+	if !u.isRunning() {
+		return []int{}
+	}
+	r := []int{}
+	_ = u.runOnMainThread(func() error {
+		for id := range glfw.GetMonitors() {
+			r = append(r, id)
+		}
+		return nil
+	})
+	return r
+}
+
+func Monitors() []Monitor {
+	u := currentUI
+	if !u.isRunning() {
+		return []Monitor{}
+	}
+	r := []Monitor{}
+	_ = u.runOnMainThread(func() error {
+		for id, m := range glfw.GetMonitors() {
+			s := monitorGLFWScale(m)
+			toRect := func(x, y, width, height int) image.Rectangle {
+				x0 := int(float64(x) / s)
+				y0 := int(float64(y) / s)
+				x1 := int(float64(x+width) / s)
+				y1 := int(float64(y+height) / s)
+				return image.Rect(x0, y0, x1, y1)
+			}
+			x, y := m.GetPos()
+			v := m.GetVideoMode()
+			wx, wy, ww, wh := monitorWorkArea(m)
+			r = append(r, Monitor{
+				ID:          id,
+				Name:        m.GetName(),
+				Bounds:      toRect(x, y, v.Width, v.Height),
+				WorkArea:    toRect(wx, wy, ww, wh),
+				DeviceScale: monitorDeviceScale(m),
+			})
+		}
+		return nil
+	})
+	return r
+}
+
+func SetMonitorsChangedCallback(f func()) {
+	currentUI.setMonitorsCallback(f)
+}
+
+func MonitorName(id int) string {
+	u := currentUI
+	if !u.isRunning() {
+		return ""
+	}
+	name := ""
+	_ = u.runOnMainThread(func() error {
+		ms := glfw.GetMonitors()
+		if id < 0 || len(ms) <= id {
+			return nil
+		}
+		name = ms[id].GetName()
+		return nil
+	})
+	return name
+}
+
+func CurrentMonitor() int {
+	u := currentUI
+	if !u.isRunning() {
+		return 0
+	}
+	id := 0
+	_ = u.runOnMainThread(func() error {
+		c := u.currentMonitor()
+		for i, m := range glfw.GetMonitors() {
+			if m == c {
+				id = i
+				break
+			}
+		}
+		return nil
+	})
+	return id
+}
+
+func MonitorRefreshRate() int {
+	u := currentUI
+	if !u.isRunning() {
+		return 0
+	}
+	r := 0
+	_ = u.runOnMainThread(func() error {
+		r = u.currentMonitor().GetVideoMode().RefreshRate
+		return nil
+	})
+	return r
+}
+
+func MonitorVideoMode(id int) VideoMode {
+	u := currentUI
+	if !u.isRunning() {
+		return VideoMode{}
+	}
+	mode := VideoMode{}
+	_ = u.runOnMainThread(func() error {
+		ms := glfw.GetMonitors()
+		if id < 0 || len(ms) <= id {
+			return nil
+		}
+		v := ms[id].GetVideoMode()
+		mode = VideoMode{
+			Width:       v.Width,
+			Height:      v.Height,
+			RefreshRate: v.RefreshRate,
+		}
+		return nil
+	})
+	return mode
+}
+
+func MonitorVideoModes(id int) []VideoMode {
+	u := currentUI
+	if !u.isRunning() {
+		return []VideoMode{}
+	}
+	modes := []VideoMode{}
+	_ = u.runOnMainThread(func() error {
+		ms := glfw.GetMonitors()
+		if id < 0 || len(ms) <= id {
+			return nil
+		}
+		for _, v := range ms[id].GetVideoModes() {
+			modes = append(modes, VideoMode{
+				Width:       v.Width,
+				Height:      v.Height,
+				RefreshRate: v.RefreshRate,
+			})
+		}
+		return nil
+	})
+	return modes
+}
+
+func FullscreenMonitor() int {
+	return currentUI.getFullscreenMonitorID()
+}
+
+func SetFullscreenMonitor(id int) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setFullscreenMonitorID(id)
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		if u.getFullscreenMonitorID() == id {
+			return nil
+		}
+		u.setFullscreenMonitorID(id)
+		if !u.fullscreen() {
+			return nil
+		}
+		// Move the window to the new monitor.
+		u.swapBuffers()
+		u.attachWindowToMonitor(u.fullscreenMonitor())
+		// SwapInterval must be called after SetMonitor (#375).
+		u.updateVsync()
+		u.fullscreenScale = 0
+		u.sizeChanged = true
+		return nil
+	})
+}
+
+func IsFullscreenBorderless() bool {
+	return currentUI.isFullscreenBorderless()
+}
+
+func SetFullscreenBorderless(borderless bool) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setFullscreenBorderless(borderless)
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		if u.isFullscreenBorderless() == borderless {
+			return nil
+		}
+		if !u.fullscreen() {
+			u.setFullscreenBorderless(borderless)
+			return nil
+		}
+		// Leave fullscreen mode once and enter it again with the new mode.
+		u.setScreenSize(u.width, u.height, u.scale, false)
+		u.setFullscreenBorderless(borderless)
+		u.setScreenSize(u.width, u.height, u.scale, true)
+		return nil
+	})
+}
+
+func IsIntegerScalingEnabled() bool {
+	return currentUI.isIntegerScalingEnabled()
+}
+
+func SetIntegerScalingEnabled(enabled bool) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setIntegerScalingEnabled(enabled)
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		if u.isIntegerScalingEnabled() == enabled {
+			return nil
+		}
+		u.setIntegerScalingEnabled(enabled)
+		if u.fullscreen() {
+			u.fullscreenScale = 0
+			u.sizeChanged = true
+		}
+		return nil
+	})
+}
+
+func IsVsyncEnabled() bool {
+	return currentUI.isVsyncEnabled()
+}
+
+func SetVsyncEnabled(enabled bool) {
+	if enabled {
+		SetFPSMode(FPSModeVsyncOn)
+		return
+	}
+	SetFPSMode(FPSModeVsyncOffMaximum)
+}
+
+func GetFPSMode() FPSMode {
+	return currentUI.getFPSMode()
+}
+
+func SetFPSMode(mode FPSMode) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setFPSMode(mode)
+		return
+	}
+	// Wake up the main thread in case it is waiting for events.
+	glfw.PostEmptyEvent()
+	_ = u.runOnMainThread(func() error {
+		u.setFPSMode(mode)
+		u.updateVsync()
+		return nil
+	})
+}
+
+func ScheduleFrame() {
+	u := currentUI
+	u.scheduleFrame()
+	if u.isRunning() {
+		// PostEmptyEvent can be called from any goroutine.
+		glfw.PostEmptyEvent()
+	}
+}
+
+func IsWindowDecorated() bool {
+	return currentUI.isDecorated()
+}
+
+func SetWindowDecorated(decorated bool) {
+	u := currentUI
+	if !u.isRunning() {
+		// The decoration is applied as a window hint at initialize.
+		u.setDecorated(decorated)
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		if u.isDecorated() == decorated {
+			return nil
+		}
+		u.setDecorated(decorated)
+		if u.fullscreen() {
+			// The decoration is applied when the window comes back from fullscreen mode.
+			return nil
+		}
+		// GLFW 3.2 doesn't have an API to change decoration of an existing window
+		// and recreating the window would lose the OpenGL context.
+		// Change it in a platform-specific way.
+		setWindowDecorated(u.window, decorated)
+		return nil
+	})
+}
+
+func IsWindowFloating() bool {
+	return currentUI.isFloating()
+}
+
+func SetWindowFloating(floating bool) {
+	u := currentUI
+	if !u.isRunning() {
+		// The floating state is applied as a window hint at initialize.
+		u.setFloating(floating)
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		if u.isFloating() == floating {
+			return nil
+		}
+		u.setFloating(floating)
+		if u.fullscreen() {
+			// The floating state is applied when the window comes back from fullscreen mode.
+			return nil
+		}
+		// GLFW 3.2 doesn't have an API to change the floating state of an existing window.
+		setWindowFloating(u.window, floating)
+		return nil
+	})
+}
+
+func IsWindowMinimized() bool {
+	u := currentUI
+	if !u.isRunning() {
+		return false
+	}
+	v := false
+	_ = u.runOnMainThread(func() error {
+		v = u.window.GetAttrib(glfw.Iconified) == glfw.True
+		return nil
+	})
+	return v
+}
+
+func IsWindowMaximized() bool {
+	u := currentUI
+	if !u.isRunning() {
+		return u.isInitWindowMaximized()
+	}
+	v := false
+	_ = u.runOnMainThread(func() error {
+		v = u.window.GetAttrib(glfw.Maximized) == glfw.True
+		return nil
+	})
+	return v
+}
+
+func KeyName(key Key) string {
+	u := currentUI
+	if !u.isRunning() {
+		return ""
+	}
+	name := ""
+	_ = u.runOnMainThread(func() error {
+		for gk, k := range glfwKeyCodeToKey {
+			if k != key {
+				continue
+			}
+			// GetKeyName returns the name only for printable keys.
+			if n := glfw.GetKeyName(gk, 0); n != "" {
+				name = strings.ToLower(n)
+				break
+			}
+		}
+		return nil
+	})
+	return name
+}
+
+func MinimizeWindow() {
+	u := currentUI
+	if !u.isRunning() {
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		_ = u.window.Iconify()
+		return nil
+	})
+}
+
+func MaximizeWindow() {
+	u := currentUI
+	if !u.isRunning() {
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		if u.fullscreen() {
+			return nil
+		}
+		_ = u.window.Maximize()
+		return nil
+	})
+}
+
+func SetWindowMaximized(maximized bool) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setInitWindowMaximized(maximized)
+		return
+	}
+	if maximized {
+		MaximizeWindow()
+		return
+	}
+	RestoreWindow()
 }
 
-func (u *userInterface) setInitFullscreen(initFullscreen bool) {
-	u.m.Lock()
-	u.initFullscreen = initFullscreen
-	u.m.Unlock()
+func RestoreWindow() {
+	u := currentUI
+	if !u.isRunning() {
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		_ = u.window.Restore()
+		return nil
+	})
 }
 
-func (u *userInterface) isInitCursorVisible() bool {
-	u.m.Lock()
-	v := u.initCursorVisible
-	u.m.Unlock()
-	return v
+func WindowSizeLimits() (minw, minh, maxw, maxh int) {
+	return currentUI.getWindowSizeLimits()
 }
 
-func (u *userInterface) setInitCursorVisible(visible bool) {
-	u.m.Lock()
-	u.initCursorVisible = visible
-	u.m.Unlock()
+func SetWindowSizeLimits(minw, minh, maxw, maxh int) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setWindowSizeLimits(minw, minh, maxw, maxh)
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		u.setWindowSizeLimits(minw, minh, maxw, maxh)
+		u.updateWindowSizeLimits()
+		return nil
+	})
 }
 
-func (u *userInterface) isRunnableInBackground() bool {
-	u.m.Lock()
-	v := u.runnableInBackground
-	u.m.Unlock()
-	return v
+func WindowAspectRatio() (numer, denom int) {
+	return currentUI.getWindowAspectRatio()
 }
 
-func (u *userInterface) setRunnableInBackground(runnableInBackground bool) {
-	u.m.Lock()
-	u.runnableInBackground = runnableInBackground
-	u.m.Unlock()
+func SetWindowAspectRatio(numer, denom int) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setWindowAspectRatio(numer, denom)
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		u.setWindowAspectRatio(numer, denom)
+		u.updateWindowAspectRatio()
+		return nil
+	})
 }
 
-func (u *userInterface) getInitIconImages() []image.Image {
-	u.m.Lock()
-	i := u.initIconImages
-	u.m.Unlock()
-	return i
+func IsScreenTransparent() bool {
+	return currentUI.isInitScreenTransparent()
 }
 
-func (u *userInterface) setInitIconImages(iconImages []image.Image) {
-	u.m.Lock()
-	u.initIconImages = iconImages
-	u.m.Unlock()
+func SetScreenTransparent(transparent bool) {
+	u := currentUI
+	if u.isRunning() {
+		// The transparency can be specified only before the window is created.
+		return
+	}
+	u.setInitScreenTransparent(transparent)
 }
 
-func (u *userInterface) runOnMainThread(f func() error) error {
-	if u.funcs == nil {
-		// already closed
-		return nil
-	}
-	ch := make(chan struct{})
-	var err error
-	u.funcs <- func() {
-		err = f()
-		close(ch)
-	}
-	<-ch
-	return err
+func WindowOpacity() float64 {
+	return currentUI.getWindowOpacity()
 }
 
-func SetScreenSize(width, height int) bool {
+func SetWindowOpacity(opacity float64) {
+	if opacity < 0 {
+		opacity = 0
+	}
+	if opacity > 1 {
+		opacity = 1
+	}
 	u := currentUI
 	if !u.isRunning() {
-		panic("ui: Run is not called yet")
+		u.setWindowOpacity(opacity)
+		return
 	}
-	r := false
 	_ = u.runOnMainThread(func() error {
-		r = u.setScreenSize(width, height, u.scale, u.fullscreen())
+		u.setWindowOpacity(opacity)
+		setWindowOpacity(u.window, opacity)
 		return nil
 	})
-	return r
 }
 
-func SetScreenScale(scale float64) bool {
+func SetIMEPosition(x, y int) {
 	u := currentUI
 	if !u.isRunning() {
-		panic("ui: Run is not called yet")
+		return
 	}
-	r := false
+	ox, oy := ScreenOffset()
 	_ = u.runOnMainThread(func() error {
-		r = u.setScreenSize(u.width, u.height, scale, u.fullscreen())
+		// Convert the position on the screen to the position in the window's client area.
+		s := u.actualScreenScale() / u.deviceScale() * u.glfwScale()
+		cx := int(ox/u.deviceScale()*u.glfwScale() + float64(x)*s)
+		cy := int(oy/u.deviceScale()*u.glfwScale() + float64(y)*s)
+		setIMEPosition(u.window, cx, cy)
 		return nil
 	})
-	return r
 }
 
-func ScreenScale() float64 {
+func IsFocused() bool {
 	u := currentUI
 	if !u.isRunning() {
-		return 0
+		return false
 	}
-	s := 0.0
+	v := false
 	_ = u.runOnMainThread(func() error {
-		s = u.scale
+		v = u.window.GetAttrib(glfw.Focused) != 0
 		return nil
 	})
-	return s
+	return v
 }
 
-func (u *userInterface) fullscreen() bool {
-	if !u.isRunning() {
-		panic("not reached")
-	}
-	return u.window.GetMonitor() != nil
+func SetFocusChangedCallback(f func(focused bool)) {
+	currentUI.setFocusChangedCallback(f)
 }
 
-func IsFullscreen() bool {
-	u := currentUI
-	if !u.isRunning() {
-		return u.isInitFullscreen()
-	}
-	return u.fullscreen()
+func IsWindowClosingHandled() bool {
+	return currentUI.isWindowClosingHandled()
 }
 
-func SetFullscreen(fullscreen bool) {
+func SetWindowClosingHandled(handled bool) {
+	currentUI.setWindowClosingHandled(handled)
+}
+
+func IsWindowBeingClosed() bool {
+	return currentUI.isWindowBeingClosed()
+}
+
+func DeviceScaleFactor() float64 {
 	u := currentUI
 	if !u.isRunning() {
-		u.setInitFullscreen(fullscreen)
-		return
+		return deviceScale()
 	}
+	s := 0.0
 	_ = u.runOnMainThread(func() error {
-		u := currentUI
-		u.setScreenSize(u.width, u.height, u.scale, fullscreen)
+		s = u.deviceScale()
 		return nil
 	})
+	return s
+}
+
+func SetDeviceScaleChangedCallback(f func(scale float64)) {
+	currentUI.setDeviceScaleCallback(f)
 }
 
 func SetRunnableInBackground(runnableInBackground bool) {
@@ -278,6 +1370,31 @@ func IsRunnableInBackground() bool {
 	return currentUI.isRunnableInBackground()
 }
 
+func NativeWindowHandle() uintptr {
+	u := currentUI
+	if !u.isRunning() {
+		return 0
+	}
+	h := uintptr(0)
+	_ = u.runOnMainThread(func() error {
+		h = nativeWindowHandle(u.window)
+		return nil
+	})
+	return h
+}
+
+func SetWindowTitle(title string) {
+	u := currentUI
+	if !u.isRunning() {
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		u.title = title
+		u.window.SetTitle(title)
+		return nil
+	})
+}
+
 func SetWindowIcon(iconImages []image.Image) {
 	if !currentUI.isRunning() {
 		currentUI.setInitIconImages(iconImages)
@@ -289,6 +1406,82 @@ func SetWindowIcon(iconImages []image.Image) {
 	})
 }
 
+// WindowPosition returns the position of the window's upper-left corner in device-independent pixels.
+//
+// In fullscreen mode, WindowPosition returns the position where the window is placed
+// after fullscreen mode is turned off.
+func WindowPosition() (int, int) {
+	u := currentUI
+	if !u.isRunning() {
+		return 0, 0
+	}
+	x, y := 0, 0
+	_ = u.runOnMainThread(func() error {
+		if u.fullscreen() {
+			x, y = u.origPosX, u.origPosY
+		} else {
+			x, y = u.window.GetPos()
+		}
+		s := u.glfwScale()
+		x = int(float64(x) / s)
+		y = int(float64(y) / s)
+		return nil
+	})
+	return x, y
+}
+
+func SetWindowPosition(x, y int) {
+	u := currentUI
+	if !u.isRunning() {
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		s := u.glfwScale()
+		gx := int(float64(x) * s)
+		gy := int(float64(y) * s)
+		if u.fullscreen() {
+			// The position is applied when the window comes back from fullscreen mode.
+			u.origPosX, u.origPosY = gx, gy
+			return nil
+		}
+		u.window.SetPos(gx, gy)
+		return nil
+	})
+}
+
+func SetInitialWindowPosition(x, y int) {
+	u := currentUI
+	if u.isRunning() {
+		return
+	}
+	u.setInitWindowPosition(x, y)
+}
+
+// OutsideSize returns the actual size of the window, or the size of the monitor in fullscreen mode.
+// The unit is device-independent pixel.
+//
+// OutsideSize returns (0, 0) before Run is called.
+func OutsideSize() (int, int) {
+	u := currentUI
+	if !u.isRunning() {
+		return 0, 0
+	}
+	w, h := 0, 0
+	_ = u.runOnMainThread(func() error {
+		if u.fullscreen() {
+			v := u.fullscreenMonitor().GetVideoMode()
+			w = int(float64(v.Width) / u.glfwScale())
+			h = int(float64(v.Height) / u.glfwScale())
+			return nil
+		}
+		ww, wh := u.window.GetSize()
+		w = int(float64(ww) / u.glfwScale())
+		h = int(float64(wh) / u.glfwScale())
+		return nil
+	})
+	return w, h
+}
+
 func ScreenOffset() (float64, float64) {
 	u := currentUI
 	if !u.isRunning() {
@@ -302,9 +1495,8 @@ func ScreenOffset() (float64, float64) {
 	}
 	ox := 0.0
 	oy := 0.0
-	m := glfw.GetPrimaryMonitor()
-	v := m.GetVideoMode()
 	_ = u.runOnMainThread(func() error {
+		v := u.fullscreenMonitor().GetVideoMode()
 		ox = (float64(v.Width)*u.deviceScale()/u.glfwScale() - float64(u.width)*u.actualScreenScale()) / 2
 		oy = (float64(v.Height)*u.deviceScale()/u.glfwScale() - float64(u.height)*u.actualScreenScale()) / 2
 		return nil
@@ -326,31 +1518,81 @@ func adjustCursorPosition(x, y int) (int, int) {
 	return x - int(ox/s), y - int(oy/s)
 }
 
+var glfwCursorModes = map[CursorMode]int{
+	CursorModeVisible:  glfw.CursorNormal,
+	CursorModeHidden:   glfw.CursorHidden,
+	CursorModeCaptured: glfw.CursorDisabled,
+}
+
 func IsCursorVisible() bool {
+	return GetCursorMode() == CursorModeVisible
+}
+
+func SetCursorVisibility(visible bool) {
+	if visible {
+		SetCursorMode(CursorModeVisible)
+		return
+	}
+	SetCursorMode(CursorModeHidden)
+}
+
+func GetCursorMode() CursorMode {
 	u := currentUI
 	if !u.isRunning() {
-		return u.isInitCursorVisible()
+		return u.getInitCursorMode()
 	}
-	v := false
+	mode := CursorModeVisible
 	_ = currentUI.runOnMainThread(func() error {
-		v = currentUI.window.GetInputMode(glfw.CursorMode) == glfw.CursorNormal
+		m := currentUI.window.GetInputMode(glfw.CursorMode)
+		for cm, gm := range glfwCursorModes {
+			if m == gm {
+				mode = cm
+				break
+			}
+		}
 		return nil
 	})
-	return v
+	return mode
 }
 
-func SetCursorVisibility(visible bool) {
+func SetCursorMode(mode CursorMode) {
 	u := currentUI
 	if !u.isRunning() {
-		u.setInitCursorVisible(visible)
+		u.setInitCursorMode(mode)
 		return
 	}
 	_ = currentUI.runOnMainThread(func() error {
-		c := glfw.CursorNormal
-		if !visible {
-			c = glfw.CursorHidden
+		m, ok := glfwCursorModes[mode]
+		if !ok {
+			return nil
 		}
-		currentUI.window.SetInputMode(glfw.CursorMode, c)
+		currentUI.window.SetInputMode(glfw.CursorMode, m)
+		// The cursor might jump when the mode is changed. Don't treat this as a move.
+		currentInput.resetCursorDelta()
+		return nil
+	})
+}
+
+func SetCursorImage(img image.Image, hotX, hotY int) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setInitCursorImage(img, hotX, hotY)
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		u.setCursorImage(img, hotX, hotY)
+		return nil
+	})
+}
+
+func SetCursorShape(shape CursorShape) {
+	u := currentUI
+	if !u.isRunning() {
+		u.setInitCursorShape(shape)
+		return
+	}
+	_ = u.runOnMainThread(func() error {
+		u.setCursorShape(shape)
 		return nil
 	})
 }
@@ -359,9 +1601,6 @@ func Run(width, height int, scale float64, title string, g GraphicsContext) erro
 	<-currentUIInitialized
 
 	u := currentUI
-	// GLContext must be created before setting the screen size, which requires
-	// swapping buffers.
-	opengl.Init(currentUI.runOnMainThread)
 	_ = u.runOnMainThread(func() error {
 		m := glfw.GetPrimaryMonitor()
 		v := m.GetVideoMode()
@@ -369,15 +1608,30 @@ func Run(width, height int, scale float64, title string, g GraphicsContext) erro
 		// The game is in window mode (not fullscreen mode) at the first state.
 		// Don't refer u.initFullscreen here to avoid some GLFW problems.
 		u.setScreenSize(width, height, scale, false)
+		u.updateWindowSizeLimits()
+		u.updateWindowAspectRatio()
 		u.title = title
 		u.window.SetTitle(title)
 		u.window.Show()
 
-		w, h := u.glfwSize()
-		x := (v.Width - w) / 2
-		y := (v.Height - h) / 3
-		x, y = adjustWindowPosition(x, y)
-		u.window.SetPos(x, y)
+		if x, y, ok := u.getInitWindowPosition(); ok {
+			// The position is used as it is so that the previous placement,
+			// even on a monitor at negative coordinates, can be restored.
+			s := u.glfwScale()
+			u.window.SetPos(int(float64(x)*s), int(float64(y)*s))
+		} else {
+			w, h := u.glfwSize()
+			x := (v.Width - w) / 2
+			y := (v.Height - h) / 3
+			x, y = adjustWindowPosition(x, y)
+			u.window.SetPos(x, y)
+		}
+
+		// The window is maximized after being positioned so that restoring it
+		// brings it back to the centered position.
+		if u.isInitWindowMaximized() && !u.isInitFullscreen() {
+			_ = u.window.Maximize()
+		}
 		return nil
 	})
 	return u.loop(g)
@@ -385,14 +1639,14 @@ func Run(width, height int, scale float64, title string, g GraphicsContext) erro
 
 func (u *userInterface) glfwScale() float64 {
 	if u.cachedGLFWScale == 0 {
-		u.cachedGLFWScale = glfwScale()
+		u.cachedGLFWScale = windowGLFWScale(u.window)
 	}
 	return u.cachedGLFWScale
 }
 
 func (u *userInterface) deviceScale() float64 {
 	if u.cachedDeviceScale == 0 {
-		u.cachedDeviceScale = deviceScale()
+		u.cachedDeviceScale = windowDeviceScale(u.window)
 	}
 	return u.cachedDeviceScale
 }
@@ -408,14 +1662,20 @@ func (u *userInterface) getScale() float64 {
 		return u.scale
 	}
 	if u.fullscreenScale == 0 {
-		m := glfw.GetPrimaryMonitor()
-		v := m.GetVideoMode()
+		v := u.fullscreenMonitor().GetVideoMode()
 		sw := float64(v.Width) / u.glfwScale() / float64(u.width)
 		sh := float64(v.Height) / u.glfwScale() / float64(u.height)
 		s := sw
 		if s > sh {
 			s = sh
 		}
+		if u.isIntegerScalingEnabled() {
+			// Snap the scale in device pixels to an integer so that all the pixels of the screen have the same size.
+			// The rest of the monitor is letterboxed.
+			if d := u.deviceScale(); s*d >= 1 {
+				s = math.Floor(s*d) / d
+			}
+		}
 		u.fullscreenScale = s
 	}
 	return u.fullscreenScale
@@ -430,24 +1690,58 @@ func (u *userInterface) pollEvents() {
 	currentInput.update(u.window, u.getScale()*u.glfwScale())
 }
 
+// waitEvents is similar to pollEvents, but blocks until at least one event is received or a frame is scheduled.
+func (u *userInterface) waitEvents() {
+	glfw.WaitEvents()
+	currentInput.update(u.window, u.getScale()*u.glfwScale())
+}
+
 func (u *userInterface) update(g GraphicsContext) error {
 	shouldClose := false
 	_ = u.runOnMainThread(func() error {
 		shouldClose = u.window.ShouldClose()
+		if shouldClose && u.isWindowClosingHandled() {
+			// Let the game decide whether the window is closed.
+			u.window.SetShouldClose(false)
+			u.setWindowBeingClosed(true)
+			shouldClose = false
+		}
 		return nil
 	})
 	if shouldClose {
 		return &RegularTermination{}
 	}
 
+	deviceScaleChanged := false
+	deviceScale := 0.0
+	monitorsChanged := false
 	_ = u.runOnMainThread(func() error {
 		if u.isInitFullscreen() {
 			u := currentUI
 			u.setScreenSize(u.width, u.height, u.scale, true)
 			u.setInitFullscreen(false)
 		}
+		if u.windowMoved {
+			u.windowMoved = false
+			deviceScaleChanged = u.updateDeviceScale()
+			deviceScale = u.deviceScale()
+		}
+		if u.monitorsChanged {
+			u.monitorsChanged = false
+			monitorsChanged = true
+		}
 		return nil
 	})
+	if monitorsChanged {
+		if f := u.getMonitorsCallback(); f != nil {
+			f()
+		}
+	}
+	if deviceScaleChanged {
+		if f := u.getDeviceScaleCallback(); f != nil {
+			f(deviceScale)
+		}
+	}
 
 	actualScale := 0.0
 	sizeChanged := false
@@ -464,8 +1758,20 @@ func (u *userInterface) update(g GraphicsContext) error {
 		g.SetSize(u.width, u.height, actualScale)
 	}
 
+	focused := false
+	_ = u.runOnMainThread(func() error {
+		// With FPSModeVsyncOffMinimum, a frame is rendered only when an event happens or a frame is scheduled.
+		if !u.takeFrameScheduled() && u.getFPSMode() == FPSModeVsyncOffMinimum {
+			u.waitEvents()
+		} else {
+			u.pollEvents()
+		}
+		focused = u.window.GetAttrib(glfw.Focused) != 0
+		return nil
+	})
+	u.updateFocus(focused)
+
 	_ = u.runOnMainThread(func() error {
-		u.pollEvents()
 		for !u.isRunnableInBackground() && u.window.GetAttrib(glfw.Focused) == 0 {
 			// Wait for an arbitrary period to avoid busy loop.
 			time.Sleep(time.Second / 60)
@@ -474,10 +1780,23 @@ func (u *userInterface) update(g GraphicsContext) error {
 				return nil
 			}
 		}
+		focused = u.window.GetAttrib(glfw.Focused) != 0
 		return nil
 	})
+	u.updateFocus(focused)
+
 	if err := g.Update(func() {
 		currentInput.runeBuffer = currentInput.runeBuffer[:0]
+		currentInput.droppedFiles = nil
+		currentInput.connectedGamepads = nil
+		currentInput.disconnectedGamepads = nil
+		currentInput.cursorDeltaX = 0
+		currentInput.cursorDeltaY = 0
+		currentInput.rawMouseDeltaX = 0
+		currentInput.rawMouseDeltaY = 0
+		currentInput.wheelX = 0
+		currentInput.wheelY = 0
+		u.setWindowBeingClosed(false)
 	}); err != nil {
 		return err
 	}
@@ -495,6 +1814,17 @@ func (u *userInterface) loop(g GraphicsContext) error {
 		if err := u.update(g); err != nil {
 			return err
 		}
+		iconified := false
+		_ = u.runOnMainThread(func() error {
+			iconified = u.iconified
+			return nil
+		})
+		if iconified {
+			// Nothing is visible while the window is iconified.
+			// Skip swapping buffers and wait for an arbitrary period to avoid busy loop.
+			time.Sleep(time.Second / 60)
+			continue
+		}
 		// The bound framebuffer must be the default one (0) before swapping buffers.
 		opengl.GetContext().BindScreenFramebuffer()
 		_ = u.runOnMainThread(func() error {
@@ -512,7 +1842,11 @@ func (u *userInterface) setScreenSize(width, height int, scale float64, fullscre
 	if u.width == width && u.height == height && u.scale == scale && u.fullscreen() == fullscreen {
 		return false
 	}
+	u.forceSetScreenSize(width, height, scale, fullscreen)
+	return true
+}
 
+func (u *userInterface) forceSetScreenSize(width, height int, scale float64, fullscreen bool) {
 	// On Windows, giving a too small width doesn't call a callback (#165).
 	// To prevent hanging up, return asap if the width is too small.
 	// 252 is an arbitrary number and I guess this is small enough.
@@ -536,16 +1870,27 @@ func (u *userInterface) setScreenSize(width, height int, scale float64, fullscre
 		if u.origPosX < 0 && u.origPosY < 0 {
 			u.origPosX, u.origPosY = u.window.GetPos()
 		}
-		m := glfw.GetPrimaryMonitor()
-		v := m.GetVideoMode()
-		u.window.SetMonitor(m, 0, 0, v.Width, v.Height, v.RefreshRate)
+		u.attachWindowToMonitor(u.fullscreenMonitor())
 	} else {
+		if u.inBorderlessFullscreen {
+			setWindowDecorated(u.window, u.isDecorated())
+			u.inBorderlessFullscreen = false
+			if u.origPosX >= 0 && u.origPosY >= 0 {
+				u.window.SetPos(u.origPosX, u.origPosY)
+			}
+			u.origPosX = -1
+			u.origPosY = -1
+		}
 		if u.origPosX >= 0 && u.origPosY >= 0 {
 			x := u.origPosX
 			y := u.origPosY
 			u.window.SetMonitor(nil, x, y, 16, 16, 0)
 			u.origPosX = -1
 			u.origPosY = -1
+			// SetMonitor might reset the window style and level to the initial ones.
+			// Apply the current decoration and floating state again.
+			setWindowDecorated(u.window, u.isDecorated())
+			setWindowFloating(u.window, u.isFloating())
 		}
 
 		oldW, oldH := u.window.GetSize()
@@ -577,9 +1922,8 @@ func (u *userInterface) setScreenSize(width, height int, scale float64, fullscre
 	// TODO: (#405) If triple buffering is needed, SwapInterval(0) should be called,
 	// but is this correct? If glfw.SwapInterval(0) and the driver doesn't support triple
 	// buffering, what will happen?
-	glfw.SwapInterval(1)
+	u.updateVsync()
 
 	// TODO: Rename this variable?
 	u.sizeChanged = true
-	return true
 }
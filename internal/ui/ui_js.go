@@ -17,9 +17,15 @@
 package ui
 
 import (
+	"bytes"
+	"encoding/base64"
 	"image"
+	"image/png"
+	"math"
 	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/gopherjs/gopherjs/js"
 	"github.com/hajimehoshi/ebiten/internal/opengl"
@@ -27,21 +33,38 @@ import (
 
 var canvas *js.Object
 
+// keyNames is a map from key codes to the names on the current keyboard layout.
+var keyNames = map[string]string{}
+
 type userInterface struct {
 	width                int
 	height               int
 	scale                float64
 	fullscreen           bool
 	runnableInBackground bool
+	integerScaling       bool
 
 	deviceScale float64
 	sizeChanged bool
 	windowFocus bool
+
+	// cursor is the CSS cursor value used when the cursor is visible.
+	cursor string
+
+	cursorCaptured bool
+
+	focusChangedCallback func(focused bool)
+	lastFocused          bool
+
+	fpsMode        FPSMode
+	frameScheduled bool
 }
 
 var currentUI = &userInterface{
-	sizeChanged: true,
-	windowFocus: true,
+	sizeChanged:    true,
+	windowFocus:    true,
+	lastFocused:    true,
+	frameScheduled: true,
 }
 
 // NOTE: This returns true even when the browser is not active.
@@ -57,6 +80,10 @@ func SetScreenScale(scale float64) bool {
 	return currentUI.setScreenSize(currentUI.width, currentUI.height, scale, currentUI.fullscreen)
 }
 
+func SetScreenSizeAndScale(width, height int, scale float64) bool {
+	return currentUI.setScreenSize(width, height, scale, currentUI.fullscreen)
+}
+
 func ScreenScale() float64 {
 	return currentUI.scale
 }
@@ -69,6 +96,204 @@ func IsFullscreen() bool {
 	return currentUI.fullscreen
 }
 
+func MonitorIDs() []int {
+	return []int{}
+}
+
+func Monitors() []Monitor {
+	return []Monitor{}
+}
+
+func SetMonitorsChangedCallback(f func()) {
+	// Do nothing
+}
+
+func KeyName(key Key) string {
+	for _, c := range keyToCodes[key] {
+		if n, ok := keyNames[c]; ok {
+			return n
+		}
+	}
+	return ""
+}
+
+func MonitorName(id int) string {
+	return ""
+}
+
+func CurrentMonitor() int {
+	return 0
+}
+
+func MonitorRefreshRate() int {
+	return 0
+}
+
+func MonitorVideoMode(id int) VideoMode {
+	return VideoMode{}
+}
+
+func MonitorVideoModes(id int) []VideoMode {
+	return []VideoMode{}
+}
+
+func FullscreenMonitor() int {
+	return 0
+}
+
+func SetFullscreenMonitor(id int) {
+	// Do nothing
+}
+
+func IsFullscreenBorderless() bool {
+	return false
+}
+
+func SetFullscreenBorderless(borderless bool) {
+	// Do nothing
+}
+
+func IsIntegerScalingEnabled() bool {
+	return currentUI.integerScaling
+}
+
+func SetIntegerScalingEnabled(enabled bool) {
+	u := currentUI
+	if u.integerScaling == enabled {
+		return
+	}
+	u.integerScaling = enabled
+	if u.fullscreen && u.width > 0 && u.height > 0 {
+		u.updateScreenSize()
+	}
+}
+
+func IsVsyncEnabled() bool {
+	return true
+}
+
+func SetVsyncEnabled(enabled bool) {
+	// Do nothing
+}
+
+func GetFPSMode() FPSMode {
+	return currentUI.fpsMode
+}
+
+func SetFPSMode(mode FPSMode) {
+	// As requestAnimationFrame always follows the display, FPSModeVsyncOffMaximum works as FPSModeVsyncOn.
+	currentUI.fpsMode = mode
+	currentUI.frameScheduled = true
+}
+
+func ScheduleFrame() {
+	currentUI.frameScheduled = true
+}
+
+func IsWindowDecorated() bool {
+	return false
+}
+
+func SetWindowDecorated(decorated bool) {
+	// Do nothing
+}
+
+func IsWindowFloating() bool {
+	return false
+}
+
+func SetWindowFloating(floating bool) {
+	// Do nothing
+}
+
+func IsWindowMinimized() bool {
+	return false
+}
+
+func IsWindowMaximized() bool {
+	return false
+}
+
+func MinimizeWindow() {
+	// Do nothing
+}
+
+func MaximizeWindow() {
+	// Do nothing
+}
+
+func SetWindowMaximized(maximized bool) {
+	// Do nothing
+}
+
+func RestoreWindow() {
+	// Do nothing
+}
+
+func WindowSizeLimits() (minw, minh, maxw, maxh int) {
+	return -1, -1, -1, -1
+}
+
+func SetWindowSizeLimits(minw, minh, maxw, maxh int) {
+	// Do nothing
+}
+
+func WindowAspectRatio() (numer, denom int) {
+	return -1, -1
+}
+
+func SetWindowAspectRatio(numer, denom int) {
+	// Do nothing
+}
+
+func IsScreenTransparent() bool {
+	return false
+}
+
+func SetScreenTransparent(transparent bool) {
+	// Do nothing
+}
+
+func WindowOpacity() float64 {
+	return 1
+}
+
+func SetWindowOpacity(opacity float64) {
+	// Do nothing
+}
+
+func SetIMEPosition(x, y int) {
+	// Do nothing
+}
+
+func IsFocused() bool {
+	return currentUI.windowFocus
+}
+
+func IsWindowClosingHandled() bool {
+	return false
+}
+
+func SetWindowClosingHandled(handled bool) {
+	// Do nothing
+}
+
+func IsWindowBeingClosed() bool {
+	return false
+}
+
+func SetFocusChangedCallback(f func(focused bool)) {
+	currentUI.focusChangedCallback = f
+}
+
+func DeviceScaleFactor() float64 {
+	return devicePixelRatio()
+}
+
+func SetDeviceScaleChangedCallback(f func(scale float64)) {
+	// Do nothing
+}
+
 func SetRunnableInBackground(runnableInBackground bool) {
 	currentUI.runnableInBackground = runnableInBackground
 }
@@ -77,6 +302,18 @@ func IsRunnableInBackground() bool {
 	return currentUI.runnableInBackground
 }
 
+// OutsideSize returns the size of the document's body, where the canvas is placed.
+// The unit is device-independent pixel.
+//
+// OutsideSize returns (0, 0) when the body is not available yet.
+func OutsideSize() (int, int) {
+	body := js.Global.Get("document").Get("body")
+	if body == nil || body == js.Undefined {
+		return 0, 0
+	}
+	return body.Get("clientWidth").Int(), body.Get("clientHeight").Int()
+}
+
 func ScreenOffset() (float64, float64) {
 	return 0, 0
 }
@@ -92,16 +329,108 @@ func IsCursorVisible() bool {
 
 func SetCursorVisibility(visibility bool) {
 	if visibility {
-		canvas.Get("style").Set("cursor", "auto")
+		canvas.Get("style").Set("cursor", currentUI.visibleCursor())
 	} else {
 		canvas.Get("style").Set("cursor", "none")
 	}
 }
 
+func GetCursorMode() CursorMode {
+	if currentUI.cursorCaptured {
+		return CursorModeCaptured
+	}
+	if IsCursorVisible() {
+		return CursorModeVisible
+	}
+	return CursorModeHidden
+}
+
+func SetCursorMode(mode CursorMode) {
+	u := currentUI
+	switch mode {
+	case CursorModeVisible:
+		SetCursorVisibility(true)
+	case CursorModeHidden, CursorModeCaptured:
+		SetCursorVisibility(false)
+	default:
+		return
+	}
+	u.cursorCaptured = mode == CursorModeCaptured
+	if u.cursorCaptured {
+		// This might fail without a user action. Then the pointer is locked at the next click.
+		requestPointerLock()
+		return
+	}
+	if js.Global.Get("document").Get("pointerLockElement") == canvas {
+		js.Global.Get("document").Call("exitPointerLock")
+	}
+}
+
+func (u *userInterface) visibleCursor() string {
+	if u.cursor == "" {
+		return "auto"
+	}
+	return u.cursor
+}
+
+func SetCursorImage(img image.Image, hotX, hotY int) {
+	u := currentUI
+	if img == nil {
+		u.cursor = ""
+	} else {
+		b := &bytes.Buffer{}
+		if err := png.Encode(b, img); err != nil {
+			panic(err)
+		}
+		url := "data:image/png;base64," + base64.StdEncoding.EncodeToString(b.Bytes())
+		u.cursor = "url(" + url + ") " + strconv.Itoa(hotX) + " " + strconv.Itoa(hotY) + ", auto"
+	}
+	if IsCursorVisible() {
+		canvas.Get("style").Set("cursor", u.visibleCursor())
+	}
+}
+
+var cssCursorShapes = map[CursorShape]string{
+	CursorShapeText:      "text",
+	CursorShapeCrosshair: "crosshair",
+	CursorShapePointer:   "pointer",
+	CursorShapeEWResize:  "ew-resize",
+	CursorShapeNSResize:  "ns-resize",
+}
+
+func SetCursorShape(shape CursorShape) {
+	u := currentUI
+	// An empty string is used for CursorShapeDefault and unknown shapes.
+	u.cursor = cssCursorShapes[shape]
+	if IsCursorVisible() {
+		canvas.Get("style").Set("cursor", u.visibleCursor())
+	}
+}
+
+func NativeWindowHandle() uintptr {
+	return 0
+}
+
+func SetWindowTitle(title string) {
+	js.Global.Get("document").Set("title", title)
+}
+
 func SetWindowIcon(iconImages []image.Image) {
 	// Do nothing
 }
 
+func WindowPosition() (int, int) {
+	return 0, 0
+}
+
+func SetWindowPosition(x, y int) {
+	// Do nothing
+}
+
+func SetInitialWindowPosition(x, y int) {
+	// Do nothing
+}
+
 func (u *userInterface) getScale() float64 {
 	if !u.fullscreen {
 		return u.scale
@@ -112,10 +441,17 @@ func (u *userInterface) getScale() float64 {
 	bh := body.Get("clientHeight").Float()
 	sw := bw / float64(u.width)
 	sh := bh / float64(u.height)
-	if sw > sh {
-		return sh
+	s := sw
+	if s > sh {
+		s = sh
+	}
+	if u.integerScaling {
+		// Snap the scale in device pixels to an integer so that all the pixels of the screen have the same size.
+		if d := u.deviceScale; s*d >= 1 {
+			s = math.Floor(s*d) / d
+		}
 	}
-	return sw
+	return s
 }
 
 func (u *userInterface) actualScreenScale() float64 {
@@ -123,6 +459,12 @@ func (u *userInterface) actualScreenScale() float64 {
 }
 
 func (u *userInterface) update(g GraphicsContext) error {
+	if u.lastFocused != u.windowFocus {
+		u.lastFocused = u.windowFocus
+		if u.focusChangedCallback != nil {
+			u.focusChangedCallback(u.windowFocus)
+		}
+	}
 	if !u.runnableInBackground && !u.windowFocus {
 		return nil
 	}
@@ -133,11 +475,25 @@ func (u *userInterface) update(g GraphicsContext) error {
 	currentInput.updateGamepads()
 	if u.sizeChanged {
 		u.sizeChanged = false
+		u.frameScheduled = true
 		g.SetSize(u.width, u.height, u.actualScreenScale())
 		return nil
 	}
+	if u.fpsMode == FPSModeVsyncOffMinimum && !u.frameScheduled {
+		return nil
+	}
+	u.frameScheduled = false
 	if err := g.Update(func() {
 		currentInput.runeBuffer = nil
+		currentInput.droppedFiles = nil
+		currentInput.connectedGamepads = nil
+		currentInput.disconnectedGamepads = nil
+		currentInput.cursorDeltaX = 0
+		currentInput.cursorDeltaY = 0
+		currentInput.rawMouseDeltaX = 0
+		currentInput.rawMouseDeltaY = 0
+		currentInput.wheelX = 0
+		currentInput.wheelY = 0
 	}); err != nil {
 		return err
 	}
@@ -244,7 +600,15 @@ func initialize() error {
 	canvas.Get("style").Set("outline", "none")
 
 	// Keyboard
+	if kb := js.Global.Get("navigator").Get("keyboard"); kb != js.Undefined && kb.Get("getLayoutMap") != js.Undefined {
+		kb.Call("getLayoutMap").Call("then", func(m *js.Object) {
+			m.Call("forEach", func(name, code string) {
+				keyNames[code] = name
+			})
+		})
+	}
 	canvas.Call("addEventListener", "keydown", func(e *js.Object) {
+		currentInput.setLockKeyStates(e)
 		c := e.Get("code")
 		if c == js.Undefined {
 			code := e.Get("keyCode").Int()
@@ -268,6 +632,13 @@ func initialize() error {
 			cs == keyToCodes[KeyTab][0] {
 			e.Call("preventDefault")
 		}
+		// Learn the key names from the events in case navigator.keyboard is not available.
+		if k := e.Get("key"); k != js.Undefined && !e.Get("shiftKey").Bool() && !e.Get("altKey").Bool() &&
+			!e.Get("ctrlKey").Bool() && !e.Get("metaKey").Bool() {
+			if n := k.String(); utf8.RuneCountInString(n) == 1 {
+				keyNames[cs] = strings.ToLower(n)
+			}
+		}
 		currentInput.keyDown(cs)
 	})
 	canvas.Call("addEventListener", "keypress", func(e *js.Object) {
@@ -278,6 +649,7 @@ func initialize() error {
 	})
 	canvas.Call("addEventListener", "keyup", func(e *js.Object) {
 		e.Call("preventDefault")
+		currentInput.setLockKeyStates(e)
 		if e.Get("code") == js.Undefined {
 			// Assume that UA is Edge.
 			code := e.Get("keyCode").Int()
@@ -292,7 +664,11 @@ func initialize() error {
 		e.Call("preventDefault")
 		button := e.Get("button").Int()
 		currentInput.mouseDown(button)
+		currentInput.setLockKeyStates(e)
 		setMouseCursorFromEvent(e)
+		if currentUI.cursorCaptured && js.Global.Get("document").Get("pointerLockElement") != canvas {
+			requestPointerLock()
+		}
 	})
 	canvas.Call("addEventListener", "mouseup", func(e *js.Object) {
 		e.Call("preventDefault")
@@ -304,6 +680,21 @@ func initialize() error {
 		e.Call("preventDefault")
 		setMouseCursorFromEvent(e)
 	})
+	canvas.Call("addEventListener", "wheel", func(e *js.Object) {
+		e.Call("preventDefault")
+		// Convert the deltas to the same unit as GLFW's, where one notch is about 1 and upward is positive.
+		var unit float64
+		switch e.Get("deltaMode").Int() {
+		case 0: // DOM_DELTA_PIXEL
+			unit = 100
+		case 1: // DOM_DELTA_LINE
+			unit = 3
+		default: // DOM_DELTA_PAGE
+			unit = 1
+		}
+		currentInput.wheelX -= e.Get("deltaX").Float() / unit
+		currentInput.wheelY -= e.Get("deltaY").Float() / unit
+	})
 	canvas.Call("addEventListener", "contextmenu", func(e *js.Object) {
 		e.Call("preventDefault")
 	})
@@ -322,6 +713,38 @@ func initialize() error {
 		currentInput.updateTouches(touchEventToTouches(e))
 	})
 
+	// Pen
+	for _, t := range []string{"pointerdown", "pointermove", "pointerup"} {
+		canvas.Call("addEventListener", t, func(e *js.Object) {
+			if e.Get("pointerType").String() != "pen" {
+				return
+			}
+			setPenFromEvent(e)
+		})
+	}
+	canvas.Call("addEventListener", "pointerleave", func(e *js.Object) {
+		if e.Get("pointerType").String() != "pen" {
+			return
+		}
+		currentInput.pen = pen{}
+	})
+
+	// Drag and drop
+	canvas.Call("addEventListener", "dragover", func(e *js.Object) {
+		// preventDefault is required to accept dropping.
+		e.Call("preventDefault")
+	})
+	canvas.Call("addEventListener", "drop", func(e *js.Object) {
+		e.Call("preventDefault")
+		files := e.Get("dataTransfer").Get("files")
+		for i := 0; i < files.Length(); i++ {
+			// Local paths are not available on browsers.
+			// Use object URLs instead so that the files can be read by XMLHttpRequest.
+			url := js.Global.Get("URL").Call("createObjectURL", files.Index(i)).String()
+			currentInput.droppedFiles = append(currentInput.droppedFiles, url)
+		}
+	})
+
 	// Gamepad
 	window.Call("addEventListener", "gamepadconnected", func(e *js.Object) {
 		// Do nothing.
@@ -334,11 +757,46 @@ func initialize() error {
 		// Do nothing.
 	})
 
+	// With FPSModeVsyncOffMinimum, a frame is rendered only when an event happens or a frame is scheduled.
+	for _, t := range []string{"focus", "blur", "resize", "gamepadconnected", "gamepaddisconnected"} {
+		window.Call("addEventListener", t, ScheduleFrame)
+	}
+	for _, t := range []string{"keydown", "keyup", "mousedown", "mouseup", "mousemove", "wheel", "touchstart", "touchend", "touchmove", "pointerleave", "drop"} {
+		canvas.Call("addEventListener", t, ScheduleFrame)
+	}
+
 	return nil
 }
 
+// requestPointerLock locks the pointer.
+// The unadjusted movement is requested for raw mouse motion when the browser supports it.
+func requestPointerLock() {
+	p := canvas.Call("requestPointerLock", map[string]interface{}{
+		"unadjustedMovement": true,
+	})
+	if p == js.Undefined || p == nil {
+		// The browser doesn't support the options.
+		currentInput.unadjustedMovement = false
+		return
+	}
+	p.Call("then", func() {
+		currentInput.unadjustedMovement = true
+	}, func() {
+		currentInput.unadjustedMovement = false
+		canvas.Call("requestPointerLock")
+	})
+}
+
 func setMouseCursorFromEvent(e *js.Object) {
 	scale := currentUI.getScale()
+	if dx := e.Get("movementX"); dx != js.Undefined {
+		currentInput.cursorDeltaX += dx.Float() / scale
+		currentInput.cursorDeltaY += e.Get("movementY").Float() / scale
+		if currentInput.unadjustedMovement && js.Global.Get("document").Get("pointerLockElement") == canvas {
+			currentInput.rawMouseDeltaX += dx.Float()
+			currentInput.rawMouseDeltaY += e.Get("movementY").Float()
+		}
+	}
 	rect := canvas.Call("getBoundingClientRect")
 	x, y := e.Get("clientX").Int(), e.Get("clientY").Int()
 	x -= rect.Get("left").Int()
@@ -346,6 +804,24 @@ func setMouseCursorFromEvent(e *js.Object) {
 	currentInput.setMouseCursor(int(float64(x)/scale), int(float64(y)/scale))
 }
 
+func setPenFromEvent(e *js.Object) {
+	scale := currentUI.getScale()
+	rect := canvas.Call("getBoundingClientRect")
+	x, y := e.Get("clientX").Float(), e.Get("clientY").Float()
+	x -= rect.Get("left").Float()
+	y -= rect.Get("top").Float()
+	currentInput.pen = pen{
+		inRange:  true,
+		x:        int(x / scale),
+		y:        int(y / scale),
+		pressure: e.Get("pressure").Float(),
+		tiltX:    e.Get("tiltX").Float(),
+		tiltY:    e.Get("tiltY").Float(),
+		// The eraser button is 32 in the buttons of the pointer events.
+		eraser: e.Get("buttons").Int()&32 != 0,
+	}
+}
+
 func devicePixelRatio() float64 {
 	ratio := js.Global.Get("window").Get("devicePixelRatio").Float()
 	if ratio == 0 {
@@ -33,10 +33,6 @@ func deviceScale() float64 {
 	return float64(C.scale())
 }
 
-func glfwScale() float64 {
-	return 1
-}
-
 func adjustWindowPosition(x, y int) (int, int) {
 	return x, y
 }
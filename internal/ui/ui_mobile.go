@@ -103,10 +103,20 @@ func SetScreenScale(scale float64) bool {
 	return false
 }
 
+func SetScreenSizeAndScale(width, height int, scale float64) bool {
+	// TODO: Implement
+	return false
+}
+
 func ScreenScale() float64 {
 	return currentUI.scale
 }
 
+// OutsideSize returns (0, 0) since the outside size is not available on mobiles so far.
+func OutsideSize() (int, int) {
+	return 0, 0
+}
+
 func ScreenOffset() (float64, float64) {
 	return 0, 0
 }
@@ -123,6 +133,14 @@ func SetCursorVisibility(visibility bool) {
 	// Do nothing
 }
 
+func GetCursorMode() CursorMode {
+	return CursorModeHidden
+}
+
+func SetCursorMode(mode CursorMode) {
+	// Do nothing
+}
+
 func SetFullscreen(fullscreen bool) {
 	// Do nothing
 }
@@ -132,6 +150,198 @@ func IsFullscreen() bool {
 	return false
 }
 
+func MonitorIDs() []int {
+	return []int{}
+}
+
+func Monitors() []Monitor {
+	return []Monitor{}
+}
+
+func SetMonitorsChangedCallback(f func()) {
+	// Do nothing
+}
+
+func KeyName(key Key) string {
+	return ""
+}
+
+func MonitorName(id int) string {
+	return ""
+}
+
+func CurrentMonitor() int {
+	return 0
+}
+
+func MonitorRefreshRate() int {
+	return 0
+}
+
+func MonitorVideoMode(id int) VideoMode {
+	return VideoMode{}
+}
+
+func MonitorVideoModes(id int) []VideoMode {
+	return []VideoMode{}
+}
+
+func FullscreenMonitor() int {
+	return 0
+}
+
+func SetFullscreenMonitor(id int) {
+	// Do nothing
+}
+
+func IsFullscreenBorderless() bool {
+	return false
+}
+
+func SetFullscreenBorderless(borderless bool) {
+	// Do nothing
+}
+
+func IsIntegerScalingEnabled() bool {
+	return false
+}
+
+func SetIntegerScalingEnabled(enabled bool) {
+	// Do nothing
+}
+
+func IsVsyncEnabled() bool {
+	return true
+}
+
+func SetVsyncEnabled(enabled bool) {
+	// Do nothing
+}
+
+func GetFPSMode() FPSMode {
+	return FPSModeVsyncOn
+}
+
+func SetFPSMode(mode FPSMode) {
+	// Do nothing
+}
+
+func ScheduleFrame() {
+	// Do nothing
+}
+
+func IsWindowDecorated() bool {
+	return false
+}
+
+func SetWindowDecorated(decorated bool) {
+	// Do nothing
+}
+
+func IsWindowFloating() bool {
+	return false
+}
+
+func SetWindowFloating(floating bool) {
+	// Do nothing
+}
+
+func IsWindowMinimized() bool {
+	return false
+}
+
+func IsWindowMaximized() bool {
+	return false
+}
+
+func MinimizeWindow() {
+	// Do nothing
+}
+
+func MaximizeWindow() {
+	// Do nothing
+}
+
+func SetWindowMaximized(maximized bool) {
+	// Do nothing
+}
+
+func RestoreWindow() {
+	// Do nothing
+}
+
+func WindowSizeLimits() (minw, minh, maxw, maxh int) {
+	return -1, -1, -1, -1
+}
+
+func SetWindowSizeLimits(minw, minh, maxw, maxh int) {
+	// Do nothing
+}
+
+func WindowAspectRatio() (numer, denom int) {
+	return -1, -1
+}
+
+func SetWindowAspectRatio(numer, denom int) {
+	// Do nothing
+}
+
+func IsScreenTransparent() bool {
+	return false
+}
+
+func SetScreenTransparent(transparent bool) {
+	// Do nothing
+}
+
+func WindowOpacity() float64 {
+	return 1
+}
+
+func SetWindowOpacity(opacity float64) {
+	// Do nothing
+}
+
+func SetIMEPosition(x, y int) {
+	// Do nothing
+}
+
+func SetCursorImage(img image.Image, hotX, hotY int) {
+	// Do nothing
+}
+
+func SetCursorShape(shape CursorShape) {
+	// Do nothing
+}
+
+func IsFocused() bool {
+	return true
+}
+
+func IsWindowClosingHandled() bool {
+	return false
+}
+
+func SetWindowClosingHandled(handled bool) {
+	// Do nothing
+}
+
+func IsWindowBeingClosed() bool {
+	return false
+}
+
+func SetFocusChangedCallback(f func(focused bool)) {
+	// Do nothing
+}
+
+func DeviceScaleFactor() float64 {
+	return deviceScale()
+}
+
+func SetDeviceScaleChangedCallback(f func(scale float64)) {
+	// Do nothing
+}
+
 func SetRunnableInBackground(runnableInBackground bool) {
 	// Do nothing
 }
@@ -141,10 +351,30 @@ func IsRunnableInBackground() bool {
 	return false
 }
 
+func NativeWindowHandle() uintptr {
+	return 0
+}
+
+func SetWindowTitle(title string) {
+	// Do nothing
+}
+
 func SetWindowIcon(iconImages []image.Image) {
 	// Do nothing
 }
 
+func WindowPosition() (int, int) {
+	return 0, 0
+}
+
+func SetWindowPosition(x, y int) {
+	// Do nothing
+}
+
+func SetInitialWindowPosition(x, y int) {
+	// Do nothing
+}
+
 func (u *userInterface) actualScreenScale() float64 {
 	return u.scale * deviceScale()
 }
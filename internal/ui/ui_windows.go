@@ -44,10 +44,6 @@ func deviceScale() float64 {
 	return float64(dpi) / 96
 }
 
-func glfwScale() float64 {
-	return deviceScale()
-}
-
 func adjustWindowPosition(x, y int) (int, int) {
 	// As the video width/height might be wrong,
 	// adjust x/y at least to enable to handle the window (#328)
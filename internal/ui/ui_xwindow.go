@@ -23,10 +23,6 @@ func deviceScale() float64 {
 	return 1
 }
 
-func glfwScale() float64 {
-	return deviceScale()
-}
-
 func adjustWindowPosition(x, y int) (int, int) {
 	return x, y
 }
@@ -0,0 +1,171 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !android
+// +build !js
+
+package ui
+
+import (
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	evFF     = 0x15
+	ffRumble = 0x50
+)
+
+// ffEffect is struct ff_effect in linux/input.h for FF_RUMBLE.
+type ffEffect struct {
+	typ             uint16
+	id              int16
+	direction       uint16
+	triggerButton   uint16
+	triggerInterval uint16
+	replayLength    uint16
+	replayDelay     uint16
+	_               uint16
+
+	// The union of the effects. The layout must be the same as struct ff_periodic_effect,
+	// the largest member, while the first two fields are the ones of struct ff_rumble_effect.
+	strongMagnitude uint16
+	weakMagnitude   uint16
+	_               [8]uint16
+	_               uint32
+	_               uintptr
+}
+
+// inputEvent is struct input_event in linux/input.h.
+type inputEvent struct {
+	sec   int
+	usec  int
+	typ   uint16
+	code  uint16
+	value int32
+}
+
+// evIOCSFF is EVIOCSFF, _IOW('E', 0x80, struct ff_effect).
+var evIOCSFF = 1<<30 | unsafe.Sizeof(ffEffect{})<<16 | 'E'<<8 | 0x80
+
+type ffDevice struct {
+	fd       int
+	effectID int16
+}
+
+var (
+	// ffDevices is the opened event devices. The keys are the paths.
+	ffDevices = map[string]*ffDevice{}
+	ffM       sync.Mutex
+)
+
+// eventDevicePath returns the path of the event device for the gamepad (id).
+//
+// GLFW 3.2 uses the joystick API (/dev/input/js*), which doesn't support force feedback.
+func eventDevicePath(names []string, id int) string {
+	j := joystickSysfsPath(names, id)
+	if j == "" {
+		return ""
+	}
+	events, _ := filepath.Glob(filepath.Join(j, "device", "event*"))
+	if len(events) == 0 {
+		return ""
+	}
+	return filepath.Join("/dev/input", filepath.Base(events[0]))
+}
+
+func openFFDevice(path string) (*ffDevice, error) {
+	if d, ok := ffDevices[path]; ok {
+		return d, nil
+	}
+	fd, err := syscall.Open(path, syscall.O_RDWR|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	d := &ffDevice{
+		fd:       fd,
+		effectID: -1,
+	}
+	ffDevices[path] = d
+	return d, nil
+}
+
+func closeFFDevice(path string) {
+	d, ok := ffDevices[path]
+	if !ok {
+		return
+	}
+	syscall.Close(d.fd)
+	delete(ffDevices, path)
+}
+
+func (d *ffDevice) play(value int32) error {
+	ev := inputEvent{
+		typ:   evFF,
+		code:  uint16(d.effectID),
+		value: value,
+	}
+	_, err := syscall.Write(d.fd, (*[unsafe.Sizeof(inputEvent{})]byte)(unsafe.Pointer(&ev))[:])
+	return err
+}
+
+// vibrateGamepad vibrates the gamepad (id) by the force feedback of the event device.
+//
+// names is the names of the gamepads for each ID. The name is empty for a disconnected ID.
+//
+// This requires the write permission to the event device.
+func vibrateGamepad(names []string, id int, duration time.Duration, strong, weak float64) {
+	path := eventDevicePath(names, id)
+	if path == "" {
+		return
+	}
+
+	ffM.Lock()
+	defer ffM.Unlock()
+	d, err := openFFDevice(path)
+	if err != nil {
+		return
+	}
+	if duration <= 0 {
+		if d.effectID >= 0 {
+			_ = d.play(0)
+		}
+		return
+	}
+
+	ms := duration / time.Millisecond
+	if ms > 0xffff {
+		ms = 0xffff
+	}
+	e := ffEffect{
+		typ:             ffRumble,
+		id:              d.effectID,
+		replayLength:    uint16(ms),
+		strongMagnitude: uint16(strong * 0xffff),
+		weakMagnitude:   uint16(weak * 0xffff),
+	}
+	// The effect is uploaded for the first time when the ID is -1, or updated otherwise.
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(d.fd), evIOCSFF, uintptr(unsafe.Pointer(&e))); errno != 0 {
+		// The device might be disconnected.
+		closeFFDevice(path)
+		return
+	}
+	d.effectID = e.id
+	if err := d.play(1); err != nil {
+		closeFFDevice(path)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !js
+
+package ui
+
+import (
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+type xinputVibration struct {
+	wLeftMotorSpeed  uint16
+	wRightMotorSpeed uint16
+}
+
+var (
+	xinputSetStateProc *windows.LazyProc
+	xinputOnce         sync.Once
+
+	vibrationTimers = map[int]*time.Timer{}
+	vibrationM      sync.Mutex
+)
+
+func loadXInput() {
+	// Try the DLLs in the same order as GLFW does.
+	for _, name := range []string{"xinput1_4.dll", "xinput1_3.dll", "xinput9_1_0.dll", "xinput1_2.dll", "xinput1_1.dll"} {
+		dll := windows.NewLazySystemDLL(name)
+		if dll.Load() != nil {
+			continue
+		}
+		xinputSetStateProc = dll.NewProc("XInputSetState")
+		return
+	}
+}
+
+func isXInputGamepadName(name string) bool {
+	// These are the names GLFW 3.2 gives to XInput devices.
+	return strings.HasPrefix(name, "XInput ") ||
+		name == "Xbox 360 Controller" ||
+		name == "Wireless Xbox 360 Controller" ||
+		name == "Unknown XInput Device"
+}
+
+func setXInputState(index int, strong, weak uint16) {
+	v := xinputVibration{
+		wLeftMotorSpeed:  strong,
+		wRightMotorSpeed: weak,
+	}
+	syscall.Syscall(xinputSetStateProc.Addr(), 2, uintptr(index), uintptr(unsafe.Pointer(&v)), 0)
+}
+
+// vibrateGamepad vibrates the gamepad (id) by XInput.
+//
+// names is the names of the gamepads for each ID. The name is empty for a disconnected ID.
+//
+// GLFW 3.2 doesn't expose XInput user indices. As GLFW assigns IDs to XInput devices in the order
+// of the user indices, the index is assumed to be the number of XInput gamepads whose IDs are less than id.
+// DirectInput gamepads are not supported.
+func vibrateGamepad(names []string, id int, duration time.Duration, strong, weak float64) {
+	if !isXInputGamepadName(names[id]) {
+		return
+	}
+	index := 0
+	for i := 0; i < id; i++ {
+		if isXInputGamepadName(names[i]) {
+			index++
+		}
+	}
+
+	xinputOnce.Do(loadXInput)
+	if xinputSetStateProc == nil {
+		return
+	}
+
+	vibrationM.Lock()
+	defer vibrationM.Unlock()
+	if t, ok := vibrationTimers[index]; ok {
+		t.Stop()
+		delete(vibrationTimers, index)
+	}
+	if duration <= 0 {
+		setXInputState(index, 0, 0)
+		return
+	}
+	setXInputState(index, uint16(strong*0xffff), uint16(weak*0xffff))
+	var t *time.Timer
+	t = time.AfterFunc(duration, func() {
+		vibrationM.Lock()
+		defer vibrationM.Unlock()
+		if vibrationTimers[index] != t {
+			// The vibration was already replaced with a new one.
+			return
+		}
+		setXInputState(index, 0, 0)
+		delete(vibrationTimers, index)
+	})
+	vibrationTimers[index] = t
+}
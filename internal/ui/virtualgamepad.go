@@ -0,0 +1,100 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"sort"
+	"sync"
+)
+
+// virtualGamepadIDBase is the minimum ID of virtual gamepads.
+// The IDs less than this are used for the actual gamepads.
+const virtualGamepadIDBase = 16
+
+var (
+	virtualGamepads  = map[int]*gamePad{}
+	virtualGamepadsM sync.RWMutex
+)
+
+// AddVirtualGamepad adds a virtual gamepad in the standard layout, and returns its ID.
+func AddVirtualGamepad(name string) int {
+	virtualGamepadsM.Lock()
+	defer virtualGamepadsM.Unlock()
+	id := virtualGamepadIDBase
+	for {
+		if _, ok := virtualGamepads[id]; !ok {
+			break
+		}
+		id++
+	}
+	virtualGamepads[id] = &gamePad{
+		valid:     true,
+		name:      name,
+		axisNum:   4,
+		buttonNum: 17,
+		standard:  true,
+	}
+	return id
+}
+
+// RemoveVirtualGamepad removes the virtual gamepad of the given ID.
+func RemoveVirtualGamepad(id int) {
+	virtualGamepadsM.Lock()
+	delete(virtualGamepads, id)
+	virtualGamepadsM.Unlock()
+}
+
+// SetVirtualGamepadState sets the state of the virtual gamepad of the given ID.
+// The axes and the buttons are in the standard layout.
+func SetVirtualGamepadState(id int, axes []float64, buttons []bool) {
+	virtualGamepadsM.Lock()
+	defer virtualGamepadsM.Unlock()
+	g, ok := virtualGamepads[id]
+	if !ok {
+		return
+	}
+	for i := 0; i < g.axisNum; i++ {
+		g.axes[i] = 0
+		if i < len(axes) {
+			g.axes[i] = axes[i]
+		}
+	}
+	for i := 0; i < g.buttonNum; i++ {
+		g.buttonPressed[i] = i < len(buttons) && buttons[i]
+	}
+}
+
+func virtualGamepadIDs() []int {
+	virtualGamepadsM.RLock()
+	defer virtualGamepadsM.RUnlock()
+	ids := make([]int, 0, len(virtualGamepads))
+	for id := range virtualGamepads {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// virtualGamepad returns a copy of the virtual gamepad of the given ID, or nil if it doesn't exist.
+func virtualGamepad(id int) *gamePad {
+	virtualGamepadsM.RLock()
+	defer virtualGamepadsM.RUnlock()
+	g, ok := virtualGamepads[id]
+	if !ok {
+		return nil
+	}
+	c := *g
+	return &c
+}
@@ -0,0 +1,195 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build darwin
+// +build !js
+// +build !ios
+
+package ui
+
+// #cgo CFLAGS: -x objective-c
+// #cgo LDFLAGS: -framework AppKit
+//
+// #import <AppKit/AppKit.h>
+//
+// static void setDecorated(uintptr_t windowPtr, int decorated) {
+//   NSWindow* window = (NSWindow*)windowPtr;
+//   NSUInteger styleMask = NSBorderlessWindowMask;
+//   if (decorated) {
+//     // This is the same style mask that GLFW uses for non-resizable decorated windows.
+//     styleMask = NSTitledWindowMask | NSClosableWindowMask | NSMiniaturizableWindowMask;
+//   }
+//   // Keep the content area as it is.
+//   NSRect content = [window contentRectForFrameRect:[window frame]];
+//   [window setStyleMask:styleMask];
+//   [window setFrame:[window frameRectForContentRect:content] display:YES];
+//   // Changing the style mask might reset the first responder.
+//   [window makeFirstResponder:[window contentView]];
+// }
+//
+// static void setFloating(uintptr_t windowPtr, int floating) {
+//   NSWindow* window = (NSWindow*)windowPtr;
+//   if (floating) {
+//     [window setLevel:NSFloatingWindowLevel];
+//   } else {
+//     [window setLevel:NSNormalWindowLevel];
+//   }
+// }
+//
+// static void setOpacity(uintptr_t windowPtr, double opacity) {
+//   NSWindow* window = (NSWindow*)windowPtr;
+//   [window setAlphaValue:opacity];
+// }
+//
+// static void setTransparent(uintptr_t windowPtr) {
+//   NSWindow* window = (NSWindow*)windowPtr;
+//   [window setOpaque:NO];
+//   [window setBackgroundColor:[NSColor clearColor]];
+//   GLint opaque = 0;
+//   [[NSOpenGLContext currentContext] setValues:&opaque forParameter:NSOpenGLCPSurfaceOpacity];
+// }
+//
+// static int isCapsLockOn() {
+//   return ([NSEvent modifierFlags] & NSAlphaShiftKeyMask) != 0;
+// }
+//
+// static float windowScale(uintptr_t windowPtr) {
+//   NSWindow* window = (NSWindow*)windowPtr;
+//   return [window backingScaleFactor];
+// }
+//
+// // screenInfo returns the scale and the visible frame of the screen for the display.
+// // The frame is in the coordinates whose origin is the upper-left corner of the primary screen.
+// // This returns 0 when the screen is not found.
+// static int screenInfo(uint32_t displayID, double* scale, int* x, int* y, int* width, int* height) {
+//   NSArray* screens = [NSScreen screens];
+//   for (NSScreen* screen in screens) {
+//     NSNumber* number = [[screen deviceDescription] objectForKey:@"NSScreenNumber"];
+//     if ([number unsignedIntValue] != displayID) {
+//       continue;
+//     }
+//     *scale = [screen backingScaleFactor];
+//     NSRect frame = [screen visibleFrame];
+//     CGFloat primaryHeight = [(NSScreen*)[screens objectAtIndex:0] frame].size.height;
+//     *x = frame.origin.x;
+//     *y = primaryHeight - frame.origin.y - frame.size.height;
+//     *width = frame.size.width;
+//     *height = frame.size.height;
+//     return 1;
+//   }
+//   return 0;
+// }
+import "C"
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// nativeWindowHandle returns the NSWindow* of the window.
+func nativeWindowHandle(window *glfw.Window) uintptr {
+	return window.GetCocoaWindow()
+}
+
+// setWindowDecorated changes the window's style mask directly since GLFW 3.2 can't change decoration
+// after the window is created.
+func setWindowDecorated(window *glfw.Window, decorated bool) {
+	d := 0
+	if decorated {
+		d = 1
+	}
+	C.setDecorated(C.uintptr_t(window.GetCocoaWindow()), C.int(d))
+}
+
+// setWindowBounds sets the position and the size of the window.
+func setWindowBounds(window *glfw.Window, x, y, width, height int) {
+	window.SetPos(x, y)
+	window.SetSize(width, height)
+}
+
+// setWindowFloating changes whether the window is always on top of other windows.
+func setWindowFloating(window *glfw.Window, floating bool) {
+	f := 0
+	if floating {
+		f = 1
+	}
+	C.setFloating(C.uintptr_t(window.GetCocoaWindow()), C.int(f))
+}
+
+// setWindowOpacity sets the opacity of the whole window.
+func setWindowOpacity(window *glfw.Window, opacity float64) {
+	C.setOpacity(C.uintptr_t(window.GetCocoaWindow()), C.double(opacity))
+}
+
+// setWindowTransparent makes the framebuffer's alpha channel affect the window.
+//
+// This must be called when the window's OpenGL context is current.
+func setWindowTransparent(window *glfw.Window) {
+	C.setTransparent(C.uintptr_t(window.GetCocoaWindow()))
+}
+
+// setIMEPosition moves the IME's candidate window.
+func setIMEPosition(window *glfw.Window, x, y int) {
+	// GLFW 3.2's content view decides the position of the candidate window by itself.
+	// Do nothing so far.
+}
+
+// windowDeviceScale returns the device scale of the screen where the window is.
+func windowDeviceScale(window *glfw.Window) float64 {
+	return float64(C.windowScale(C.uintptr_t(window.GetCocoaWindow())))
+}
+
+// windowGLFWScale returns the scale of GLFW's coordinates for the window.
+func windowGLFWScale(window *glfw.Window) float64 {
+	return 1
+}
+
+func screenInfo(monitor *glfw.Monitor) (scale float64, x, y, width, height int, ok bool) {
+	var cs C.double
+	var cx, cy, cw, ch C.int
+	if C.screenInfo(C.uint32_t(monitor.GetCocoaMonitor()), &cs, &cx, &cy, &cw, &ch) == 0 {
+		return 0, 0, 0, 0, 0, false
+	}
+	return float64(cs), int(cx), int(cy), int(cw), int(ch), true
+}
+
+// monitorDeviceScale returns the device scale of the monitor.
+func monitorDeviceScale(monitor *glfw.Monitor) float64 {
+	s, _, _, _, _, ok := screenInfo(monitor)
+	if !ok {
+		return deviceScale()
+	}
+	return s
+}
+
+// monitorGLFWScale returns the scale of GLFW's coordinates for the monitor.
+func monitorGLFWScale(monitor *glfw.Monitor) float64 {
+	return 1
+}
+
+// monitorWorkArea returns the area of the monitor excluding the menu bar and the Dock in GLFW's coordinates.
+func monitorWorkArea(monitor *glfw.Monitor) (x, y, width, height int) {
+	_, x, y, width, height, ok := screenInfo(monitor)
+	if !ok {
+		x, y = monitor.GetPos()
+		v := monitor.GetVideoMode()
+		return x, y, v.Width, v.Height
+	}
+	return x, y, width, height
+}
+
+// lockKeyStates returns whether Caps Lock and Num Lock are on.
+// Num Lock is always off since Mac keyboards don't have it.
+func lockKeyStates() (capsLock, numLock bool) {
+	return C.isCapsLockOn() != 0, false
+}
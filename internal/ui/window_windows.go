@@ -0,0 +1,326 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !js
+
+package ui
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	gwlStyle   = ^uintptr(15) // GWL_STYLE (-16)
+	gwlExStyle = ^uintptr(19) // GWL_EXSTYLE (-20)
+
+	wsPopup       = 0x80000000
+	wsCaption     = 0x00c00000
+	wsSysMenu     = 0x00080000
+	wsThickFrame  = 0x00040000
+	wsMinimizeBox = 0x00020000
+	wsMaximizeBox = 0x00010000
+
+	wsExLayered = 0x00080000
+
+	lwaAlpha = 0x00000002
+
+	monitorDefaultToNearest = 0x00000002
+	mdtEffectiveDPI         = 0
+
+	cfsPoint        = 0x00000002
+	cfsCandidatePos = 0x00000040
+
+	dwmBBEnable     = 0x00000001
+	dwmBBBlurRegion = 0x00000002
+
+	swpNoSize       = 0x0001
+	swpNoMove       = 0x0002
+	swpNoZOrder     = 0x0004
+	swpNoActivate   = 0x0010
+	swpFrameChanged = 0x0020
+
+	hwndTopmost   = ^uintptr(0) // HWND_TOPMOST (-1)
+	hwndNoTopmost = ^uintptr(1) // HWND_NOTOPMOST (-2)
+)
+
+type point struct {
+	x int32
+	y int32
+}
+
+type rect struct {
+	left   int32
+	top    int32
+	right  int32
+	bottom int32
+}
+
+type monitorInfo struct {
+	cbSize    uint32
+	rcMonitor rect
+	rcWork    rect
+	dwFlags   uint32
+}
+
+type compositionForm struct {
+	dwStyle      uint32
+	ptCurrentPos point
+	rcArea       rect
+}
+
+type candidateForm struct {
+	dwIndex      uint32
+	dwStyle      uint32
+	ptCurrentPos point
+	rcArea       rect
+}
+
+type dwmBlurBehind struct {
+	dwFlags                uint32
+	fEnable                int32
+	hRgnBlur               uintptr
+	fTransitionOnMaximized int32
+}
+
+var (
+	gdi32  = windows.NewLazySystemDLL("gdi32.dll")
+	dwmapi = windows.NewLazySystemDLL("dwmapi.dll")
+	imm32  = windows.NewLazySystemDLL("imm32.dll")
+	shcore = windows.NewLazySystemDLL("shcore.dll")
+
+	getWindowLongProc      = user32.NewProc("GetWindowLongW")
+	setWindowLongProc      = user32.NewProc("SetWindowLongW")
+	setWindowPosProc       = user32.NewProc("SetWindowPos")
+	getClientRectProc      = user32.NewProc("GetClientRect")
+	clientToScreenProc     = user32.NewProc("ClientToScreen")
+	adjustWindowRectExProc = user32.NewProc("AdjustWindowRectEx")
+
+	setLayeredWindowAttributesProc = user32.NewProc("SetLayeredWindowAttributes")
+	createRectRgnProc              = gdi32.NewProc("CreateRectRgn")
+	deleteObjectProc               = gdi32.NewProc("DeleteObject")
+	dwmIsCompositionEnabledProc    = dwmapi.NewProc("DwmIsCompositionEnabled")
+	dwmEnableBlurBehindWindowProc  = dwmapi.NewProc("DwmEnableBlurBehindWindow")
+	immGetContextProc              = imm32.NewProc("ImmGetContext")
+	immReleaseContextProc          = imm32.NewProc("ImmReleaseContext")
+	immSetCompositionWindowProc    = imm32.NewProc("ImmSetCompositionWindow")
+	immSetCandidateWindowProc      = imm32.NewProc("ImmSetCandidateWindow")
+	monitorFromWindowProc          = user32.NewProc("MonitorFromWindow")
+	monitorFromRectProc            = user32.NewProc("MonitorFromRect")
+	getMonitorInfoProc             = user32.NewProc("GetMonitorInfoW")
+	getDpiForMonitorProc           = shcore.NewProc("GetDpiForMonitor")
+	getKeyStateProc                = user32.NewProc("GetKeyState")
+)
+
+func windowHandle(window *glfw.Window) uintptr {
+	return uintptr(unsafe.Pointer(window.GetWin32Window()))
+}
+
+// nativeWindowHandle returns the HWND of the window.
+func nativeWindowHandle(window *glfw.Window) uintptr {
+	return windowHandle(window)
+}
+
+func getWindowLong(hwnd uintptr, index uintptr) uintptr {
+	r, _, _ := syscall.Syscall(getWindowLongProc.Addr(), 2, hwnd, index, 0)
+	// The returned value is a 32bit LONG.
+	return uintptr(uint32(r))
+}
+
+// setWindowDecorated changes the window style directly since GLFW 3.2 can't change decoration
+// after the window is created.
+//
+// The client area is kept as it is.
+func setWindowDecorated(window *glfw.Window, decorated bool) {
+	h := windowHandle(window)
+	style := getWindowLong(h, gwlStyle)
+	if decorated {
+		style &^= wsPopup
+		style |= wsCaption | wsSysMenu | wsMinimizeBox
+	} else {
+		style &^= wsCaption | wsSysMenu | wsThickFrame | wsMinimizeBox | wsMaximizeBox
+		style |= wsPopup
+	}
+
+	r := rect{}
+	p := point{}
+	syscall.Syscall(getClientRectProc.Addr(), 2, h, uintptr(unsafe.Pointer(&r)), 0)
+	syscall.Syscall(clientToScreenProc.Addr(), 2, h, uintptr(unsafe.Pointer(&p)), 0)
+	syscall.Syscall(setWindowLongProc.Addr(), 3, h, gwlStyle, style)
+
+	// Calculate the new window bounds from the client area.
+	r.left += p.x
+	r.right += p.x
+	r.top += p.y
+	r.bottom += p.y
+	syscall.Syscall6(adjustWindowRectExProc.Addr(), 4, uintptr(unsafe.Pointer(&r)), style, 0, getWindowLong(h, gwlExStyle), 0, 0)
+
+	// SetWindowPos with SWP_FRAMECHANGED is required to apply the new style.
+	syscall.Syscall9(setWindowPosProc.Addr(), 7, h, 0, uintptr(r.left), uintptr(r.top), uintptr(r.right-r.left), uintptr(r.bottom-r.top), swpNoZOrder|swpNoActivate|swpFrameChanged, 0, 0)
+}
+
+// setWindowBounds sets the position and the size of the window including its frame.
+func setWindowBounds(window *glfw.Window, x, y, width, height int) {
+	// GLFW adjusts the bounds with the window frame based on the decoration at the creation.
+	// Use SetWindowPos directly so that the bounds are applied as they are.
+	syscall.Syscall9(setWindowPosProc.Addr(), 7, windowHandle(window), 0, uintptr(x), uintptr(y), uintptr(width), uintptr(height), swpNoZOrder|swpNoActivate, 0, 0)
+}
+
+// setWindowFloating changes whether the window is always on top of other windows.
+func setWindowFloating(window *glfw.Window, floating bool) {
+	after := hwndNoTopmost
+	if floating {
+		after = hwndTopmost
+	}
+	syscall.Syscall9(setWindowPosProc.Addr(), 7, windowHandle(window), after, 0, 0, 0, 0, swpNoMove|swpNoSize|swpNoActivate, 0, 0)
+}
+
+// setWindowOpacity sets the opacity of the whole window by making the window layered.
+func setWindowOpacity(window *glfw.Window, opacity float64) {
+	h := windowHandle(window)
+	exStyle := getWindowLong(h, gwlExStyle)
+	if opacity >= 1 {
+		syscall.Syscall(setWindowLongProc.Addr(), 3, h, gwlExStyle, exStyle&^wsExLayered)
+		return
+	}
+	if opacity < 0 {
+		opacity = 0
+	}
+	syscall.Syscall(setWindowLongProc.Addr(), 3, h, gwlExStyle, exStyle|wsExLayered)
+	syscall.Syscall6(setLayeredWindowAttributesProc.Addr(), 4, h, 0, uintptr(uint8(opacity*0xff)), lwaAlpha, 0, 0)
+}
+
+// setWindowTransparent makes the framebuffer's alpha channel affect the window.
+//
+// This uses the blur-behind feature of DWM in the same way as later GLFW versions do.
+// This does nothing when DWM composition is not available.
+func setWindowTransparent(window *glfw.Window) {
+	if dwmEnableBlurBehindWindowProc.Find() != nil {
+		// DWM is not available before Windows Vista.
+		return
+	}
+	enabled := int32(0)
+	syscall.Syscall(dwmIsCompositionEnabledProc.Addr(), 1, uintptr(unsafe.Pointer(&enabled)), 0, 0)
+	if enabled == 0 {
+		return
+	}
+	// An empty region makes the whole window transparent without blur.
+	region, _, _ := syscall.Syscall6(createRectRgnProc.Addr(), 4, 0, 0, ^uintptr(0), ^uintptr(0), 0, 0)
+	bb := dwmBlurBehind{
+		dwFlags:  dwmBBEnable | dwmBBBlurRegion,
+		fEnable:  1,
+		hRgnBlur: region,
+	}
+	syscall.Syscall(dwmEnableBlurBehindWindowProc.Addr(), 2, windowHandle(window), uintptr(unsafe.Pointer(&bb)), 0)
+	syscall.Syscall(deleteObjectProc.Addr(), 1, region, 0, 0)
+}
+
+// setIMEPosition moves the IME's composition and candidate windows to (x, y) in the client area.
+func setIMEPosition(window *glfw.Window, x, y int) {
+	h := windowHandle(window)
+	himc, _, _ := syscall.Syscall(immGetContextProc.Addr(), 1, h, 0, 0)
+	if himc == 0 {
+		// IME is not enabled for the window.
+		return
+	}
+	p := point{x: int32(x), y: int32(y)}
+	comp := compositionForm{
+		dwStyle:      cfsPoint,
+		ptCurrentPos: p,
+	}
+	syscall.Syscall(immSetCompositionWindowProc.Addr(), 2, himc, uintptr(unsafe.Pointer(&comp)), 0)
+	cand := candidateForm{
+		dwStyle:      cfsCandidatePos,
+		ptCurrentPos: p,
+	}
+	syscall.Syscall(immSetCandidateWindowProc.Addr(), 2, himc, uintptr(unsafe.Pointer(&cand)), 0)
+	syscall.Syscall(immReleaseContextProc.Addr(), 2, h, himc, 0)
+}
+
+// monitorHandle returns the HMONITOR of the GLFW monitor.
+func monitorHandle(monitor *glfw.Monitor) uintptr {
+	x, y := monitor.GetPos()
+	r := rect{
+		left:   int32(x),
+		top:    int32(y),
+		right:  int32(x) + 1,
+		bottom: int32(y) + 1,
+	}
+	m, _, _ := syscall.Syscall(monitorFromRectProc.Addr(), 2, uintptr(unsafe.Pointer(&r)), monitorDefaultToNearest, 0)
+	return m
+}
+
+// handleDeviceScale returns the device scale of the HMONITOR.
+func handleDeviceScale(m uintptr) float64 {
+	if getDpiForMonitorProc.Find() != nil {
+		// GetDpiForMonitor is not available before Windows 8.1. Use the system DPI instead.
+		return deviceScale()
+	}
+	dpiX, dpiY := uint32(0), uint32(0)
+	r, _, _ := syscall.Syscall6(getDpiForMonitorProc.Addr(), 4, m, mdtEffectiveDPI, uintptr(unsafe.Pointer(&dpiX)), uintptr(unsafe.Pointer(&dpiY)), 0, 0)
+	if r != 0 {
+		return deviceScale()
+	}
+	return float64(dpiX) / 96
+}
+
+// windowDeviceScale returns the device scale of the monitor where the window is.
+func windowDeviceScale(window *glfw.Window) float64 {
+	m, _, _ := syscall.Syscall(monitorFromWindowProc.Addr(), 2, windowHandle(window), monitorDefaultToNearest, 0)
+	return handleDeviceScale(m)
+}
+
+// windowGLFWScale returns the scale of GLFW's coordinates for the window.
+func windowGLFWScale(window *glfw.Window) float64 {
+	return windowDeviceScale(window)
+}
+
+// monitorDeviceScale returns the device scale of the monitor.
+func monitorDeviceScale(monitor *glfw.Monitor) float64 {
+	return handleDeviceScale(monitorHandle(monitor))
+}
+
+// monitorGLFWScale returns the scale of GLFW's coordinates for the monitor.
+func monitorGLFWScale(monitor *glfw.Monitor) float64 {
+	return monitorDeviceScale(monitor)
+}
+
+// monitorWorkArea returns the area of the monitor excluding the taskbar in GLFW's coordinates.
+func monitorWorkArea(monitor *glfw.Monitor) (x, y, width, height int) {
+	mi := monitorInfo{}
+	mi.cbSize = uint32(unsafe.Sizeof(mi))
+	r, _, _ := syscall.Syscall(getMonitorInfoProc.Addr(), 2, monitorHandle(monitor), uintptr(unsafe.Pointer(&mi)), 0)
+	if r == 0 {
+		x, y = monitor.GetPos()
+		v := monitor.GetVideoMode()
+		return x, y, v.Width, v.Height
+	}
+	w := mi.rcWork
+	return int(w.left), int(w.top), int(w.right - w.left), int(w.bottom - w.top)
+}
+
+// lockKeyStates returns whether Caps Lock and Num Lock are on.
+func lockKeyStates() (capsLock, numLock bool) {
+	const (
+		vkCapital = 0x14
+		vkNumlock = 0x90
+	)
+	// The low-order bit of GetKeyState's result is the toggle state.
+	c, _, _ := syscall.Syscall(getKeyStateProc.Addr(), 1, vkCapital, 0, 0)
+	n, _, _ := syscall.Syscall(getKeyStateProc.Addr(), 1, vkNumlock, 0, 0)
+	return c&1 != 0, n&1 != 0
+}
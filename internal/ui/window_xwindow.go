@@ -0,0 +1,231 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build freebsd linux
+// +build !js
+// +build !android
+
+package ui
+
+// #cgo LDFLAGS: -lX11
+//
+// #include <X11/Xlib.h>
+// #include <X11/Xatom.h>
+// #include <X11/XKBlib.h>
+//
+// // setDecorated sets the Motif WM hints in the same way as GLFW does for undecorated windows.
+// static void setDecorated(Display* display, Window window, int decorated) {
+//   struct {
+//     unsigned long flags;
+//     unsigned long functions;
+//     unsigned long decorations;
+//     long input_mode;
+//     unsigned long status;
+//   } hints = {0};
+//   hints.flags = 2;                        // MWM_HINTS_DECORATIONS
+//   hints.decorations = decorated ? 1 : 0; // MWM_DECOR_ALL
+//   Atom atom = XInternAtom(display, "_MOTIF_WM_HINTS", False);
+//   XChangeProperty(display, window, atom, atom, 32, PropModeReplace,
+//                   (unsigned char*)&hints, sizeof(hints) / sizeof(long));
+//   XFlush(display);
+// }
+//
+// // setFloating requests the window manager to keep the window above others by _NET_WM_STATE_ABOVE.
+// static void setFloating(Display* display, Window window, int floating) {
+//   XEvent event = {0};
+//   event.type = ClientMessage;
+//   event.xclient.window = window;
+//   event.xclient.format = 32;
+//   event.xclient.message_type = XInternAtom(display, "_NET_WM_STATE", False);
+//   event.xclient.data.l[0] = floating ? 1 : 0; // _NET_WM_STATE_ADD or _NET_WM_STATE_REMOVE
+//   event.xclient.data.l[1] = XInternAtom(display, "_NET_WM_STATE_ABOVE", False);
+//   event.xclient.data.l[3] = 1; // The source indication: a normal application
+//   XSendEvent(display, DefaultRootWindow(display), False,
+//              SubstructureNotifyMask | SubstructureRedirectMask, &event);
+//   XFlush(display);
+// }
+//
+// // setOpacity sets _NET_WM_WINDOW_OPACITY, which is respected by compositing window managers.
+// static void setOpacity(Display* display, Window window, double opacity) {
+//   Atom atom = XInternAtom(display, "_NET_WM_WINDOW_OPACITY", False);
+//   if (opacity >= 1) {
+//     XDeleteProperty(display, window, atom);
+//   } else {
+//     unsigned long value = (unsigned long)(0xffffffffu * opacity);
+//     XChangeProperty(display, window, atom, XA_CARDINAL, 32, PropModeReplace,
+//                     (unsigned char*)&value, 1);
+//   }
+//   XFlush(display);
+// }
+//
+// // getWorkArea gets the work area of the current desktop by _NET_WORKAREA.
+// // This returns 0 when the window manager doesn't support _NET_WORKAREA.
+// static int getWorkArea(Display* display, long* x, long* y, long* width, long* height) {
+//   Window root = DefaultRootWindow(display);
+//   Atom type;
+//   int format;
+//   unsigned long count, remaining;
+//   unsigned char* data = NULL;
+//
+//   long desktop = 0;
+//   Atom current = XInternAtom(display, "_NET_CURRENT_DESKTOP", True);
+//   if (current != None &&
+//       XGetWindowProperty(display, root, current, 0, 1, False, XA_CARDINAL,
+//                          &type, &format, &count, &remaining, &data) == Success && data) {
+//     if (count == 1) {
+//       desktop = ((long*)data)[0];
+//     }
+//     XFree(data);
+//     data = NULL;
+//   }
+//
+//   Atom workArea = XInternAtom(display, "_NET_WORKAREA", True);
+//   if (workArea == None) {
+//     return 0;
+//   }
+//   if (XGetWindowProperty(display, root, workArea, 0, 1024, False, XA_CARDINAL,
+//                          &type, &format, &count, &remaining, &data) != Success || !data) {
+//     return 0;
+//   }
+//   int ok = 0;
+//   if (count >= 4 * (desktop + 1)) {
+//     long* values = (long*)data + 4 * desktop;
+//     *x = values[0];
+//     *y = values[1];
+//     *width = values[2];
+//     *height = values[3];
+//     ok = 1;
+//   }
+//   XFree(data);
+//   return ok;
+// }
+//
+// // lockedModifiers returns the locked modifiers of the core keyboard.
+// static unsigned int lockedModifiers(Display* display) {
+//   XkbStateRec state;
+//   if (XkbGetState(display, XkbUseCoreKbd, &state) != Success) {
+//     return 0;
+//   }
+//   return state.locked_mods;
+// }
+import "C"
+
+import (
+	"image"
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// nativeWindowHandle returns the X11 Window of the window.
+func nativeWindowHandle(window *glfw.Window) uintptr {
+	return uintptr(window.GetX11Window())
+}
+
+// setWindowDecorated changes the window's Motif hints directly since GLFW 3.2 can't change decoration
+// after the window is created.
+func setWindowDecorated(window *glfw.Window, decorated bool) {
+	d := 0
+	if decorated {
+		d = 1
+	}
+	display := (*C.Display)(unsafe.Pointer(glfw.GetX11Display()))
+	C.setDecorated(display, C.Window(window.GetX11Window()), C.int(d))
+}
+
+// setWindowBounds sets the position and the size of the window.
+func setWindowBounds(window *glfw.Window, x, y, width, height int) {
+	window.SetPos(x, y)
+	window.SetSize(width, height)
+}
+
+// setWindowFloating changes whether the window is always on top of other windows.
+func setWindowFloating(window *glfw.Window, floating bool) {
+	f := 0
+	if floating {
+		f = 1
+	}
+	display := (*C.Display)(unsafe.Pointer(glfw.GetX11Display()))
+	C.setFloating(display, C.Window(window.GetX11Window()), C.int(f))
+}
+
+// setWindowOpacity sets the opacity of the whole window.
+func setWindowOpacity(window *glfw.Window, opacity float64) {
+	if opacity < 0 {
+		opacity = 0
+	}
+	display := (*C.Display)(unsafe.Pointer(glfw.GetX11Display()))
+	C.setOpacity(display, C.Window(window.GetX11Window()), C.double(opacity))
+}
+
+// setWindowTransparent makes the framebuffer's alpha channel affect the window.
+func setWindowTransparent(window *glfw.Window) {
+	// A transparent framebuffer requires a visual with an alpha channel, which must be chosen
+	// when the window is created. GLFW 3.2 doesn't have a way to do this. Do nothing so far.
+}
+
+// setIMEPosition moves the IME's candidate window.
+func setIMEPosition(window *glfw.Window, x, y int) {
+	// The input context of GLFW 3.2 doesn't use the over-the-spot style, and
+	// the position can't be specified. Do nothing so far.
+}
+
+// windowDeviceScale returns the device scale of the monitor where the window is.
+func windowDeviceScale(window *glfw.Window) float64 {
+	// TODO: Implement this per monitor.
+	return deviceScale()
+}
+
+// windowGLFWScale returns the scale of GLFW's coordinates for the window.
+func windowGLFWScale(window *glfw.Window) float64 {
+	return windowDeviceScale(window)
+}
+
+// monitorDeviceScale returns the device scale of the monitor.
+func monitorDeviceScale(monitor *glfw.Monitor) float64 {
+	// TODO: Implement this per monitor.
+	return deviceScale()
+}
+
+// monitorGLFWScale returns the scale of GLFW's coordinates for the monitor.
+func monitorGLFWScale(monitor *glfw.Monitor) float64 {
+	return monitorDeviceScale(monitor)
+}
+
+// monitorWorkArea returns the area of the monitor excluding panels in GLFW's coordinates.
+func monitorWorkArea(monitor *glfw.Monitor) (x, y, width, height int) {
+	mx, my := monitor.GetPos()
+	v := monitor.GetVideoMode()
+	bounds := image.Rect(mx, my, mx+v.Width, my+v.Height)
+
+	var cx, cy, cw, ch C.long
+	display := (*C.Display)(unsafe.Pointer(glfw.GetX11Display()))
+	if C.getWorkArea(display, &cx, &cy, &cw, &ch) == 0 {
+		return mx, my, v.Width, v.Height
+	}
+	// _NET_WORKAREA is one area for the whole screen spanning all the monitors.
+	r := image.Rect(int(cx), int(cy), int(cx+cw), int(cy+ch)).Intersect(bounds)
+	if r.Empty() {
+		return mx, my, v.Width, v.Height
+	}
+	return r.Min.X, r.Min.Y, r.Dx(), r.Dy()
+}
+
+// lockKeyStates returns whether Caps Lock and Num Lock are on.
+func lockKeyStates() (capsLock, numLock bool) {
+	display := (*C.Display)(unsafe.Pointer(glfw.GetX11Display()))
+	m := C.lockedModifiers(display)
+	// Num Lock is bound to Mod2 on almost all the keymaps.
+	return m&C.LockMask != 0, m&C.Mod2Mask != 0
+}
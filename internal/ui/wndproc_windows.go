@@ -0,0 +1,70 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !js
+
+package ui
+
+import (
+	"syscall"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+const (
+	gwlpWndProc = ^uintptr(3) // GWLP_WNDPROC (-4)
+)
+
+var (
+	callWindowProcProc   = user32.NewProc("CallWindowProcW")
+	setWindowLongPtrProc = user32.NewProc("SetWindowLongPtrW")
+	wndProcCallback      = syscall.NewCallback(wndProc)
+	origWndProc          uintptr
+)
+
+// subclassWindow replaces the window procedure to handle the messages GLFW 3.2 doesn't handle.
+// The other messages are passed to GLFW's window procedure.
+//
+// subclassWindow does nothing when the window is already subclassed.
+func subclassWindow(window *glfw.Window) {
+	if origWndProc != 0 {
+		return
+	}
+	p := setWindowLongProc
+	if setWindowLongPtrProc.Find() == nil {
+		// SetWindowLongPtrW is not exported on 32bit Windows, where SetWindowLongW is used instead.
+		p = setWindowLongPtrProc
+	}
+	origWndProc, _, _ = syscall.Syscall(p.Addr(), 3, windowHandle(window), gwlpWndProc, wndProcCallback)
+}
+
+func wndProc(hwnd, msg, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case wmTouch:
+		if handleTouch(hwnd, wParam, lParam) {
+			return 0
+		}
+	case wmInput:
+		// The message must be passed to DefWindowProc after being handled.
+		handleRawInput(lParam)
+	case wmPointerUpdate, wmPointerDown, wmPointerUp, wmPointerEnter, wmPointerLeave:
+		handlePointer(hwnd, msg, wParam, lParam)
+	case wmIMEComposition:
+		handleIMEComposition(hwnd, lParam)
+	case wmIMEEndComposition:
+		setIMEComposition("", 0)
+	}
+	r, _, _ := syscall.Syscall6(callWindowProcProc.Addr(), 5, origWndProc, hwnd, msg, wParam, lParam, 0)
+	return r
+}
@@ -0,0 +1,156 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// keyNamesUS is the names of the keys on US keyboards.
+var keyNamesUS = map[Key]string{
+	Key0:            "0",
+	Key1:            "1",
+	Key2:            "2",
+	Key3:            "3",
+	Key4:            "4",
+	Key5:            "5",
+	Key6:            "6",
+	Key7:            "7",
+	Key8:            "8",
+	Key9:            "9",
+	KeyA:            "A",
+	KeyB:            "B",
+	KeyC:            "C",
+	KeyD:            "D",
+	KeyE:            "E",
+	KeyF:            "F",
+	KeyG:            "G",
+	KeyH:            "H",
+	KeyI:            "I",
+	KeyJ:            "J",
+	KeyK:            "K",
+	KeyL:            "L",
+	KeyM:            "M",
+	KeyN:            "N",
+	KeyO:            "O",
+	KeyP:            "P",
+	KeyQ:            "Q",
+	KeyR:            "R",
+	KeyS:            "S",
+	KeyT:            "T",
+	KeyU:            "U",
+	KeyV:            "V",
+	KeyW:            "W",
+	KeyX:            "X",
+	KeyY:            "Y",
+	KeyZ:            "Z",
+	KeyAlt:          "Alt",
+	KeyApostrophe:   "'",
+	KeyBackslash:    "\\",
+	KeyBackspace:    "Backspace",
+	KeyCapsLock:     "Caps Lock",
+	KeyComma:        ",",
+	KeyControl:      "Ctrl",
+	KeyDelete:       "Delete",
+	KeyDown:         "Down",
+	KeyEnd:          "End",
+	KeyEnter:        "Enter",
+	KeyEqual:        "=",
+	KeyEscape:       "Esc",
+	KeyF1:           "F1",
+	KeyF2:           "F2",
+	KeyF3:           "F3",
+	KeyF4:           "F4",
+	KeyF5:           "F5",
+	KeyF6:           "F6",
+	KeyF7:           "F7",
+	KeyF8:           "F8",
+	KeyF9:           "F9",
+	KeyF10:          "F10",
+	KeyF11:          "F11",
+	KeyF12:          "F12",
+	KeyGraveAccent:  "`",
+	KeyHome:         "Home",
+	KeyInsert:       "Insert",
+	KeyLeft:         "Left",
+	KeyLeftBracket:  "[",
+	KeyMinus:        "-",
+	KeyPageDown:     "Page Down",
+	KeyPageUp:       "Page Up",
+	KeyPeriod:       ".",
+	KeyRight:        "Right",
+	KeyRightBracket: "]",
+	KeySemicolon:    ";",
+	KeyShift:        "Shift",
+	KeySlash:        "/",
+	KeySpace:        "Space",
+	KeyTab:          "Tab",
+	KeyUp:           "Up",
+}
+
+// KeyName returns a human-readable name of the physical key, e.g. for a key binding menu.
+//
+// For the printable keys, the name is the character the key produces on the current keyboard layout,
+// in upper case. For example, KeyName(KeyQ) returns "Q" on US keyboards and "A" on AZERTY keyboards,
+// and KeyName(Key2) returns "É" on AZERTY keyboards.
+// For the other keys, the name is an English name like "Ctrl" or "Enter".
+// On macOS, KeyAlt and KeyControl are "Option" and "Control".
+//
+// When the character on the current layout is unknown, the name on US keyboards is returned.
+// On browsers, the characters are available only when the browser supports navigator.keyboard,
+// or after the keys are pressed.
+// The characters are always unknown before Run is called and on mobiles.
+//
+// KeyName returns an empty string for an invalid key.
+//
+// This function is concurrent-safe.
+func KeyName(key Key) string {
+	if n := ui.KeyName(ui.Key(key)); n != "" {
+		return strings.ToUpper(n)
+	}
+	if runtime.GOOS == "darwin" {
+		switch key {
+		case KeyAlt:
+			return "Option"
+		case KeyControl:
+			return "Control"
+		}
+	}
+	return keyNamesUS[key]
+}
+
+// KeyForName returns the physical key that produces the character name on the current keyboard layout.
+//
+// KeyForName is useful for shortcuts based on the printed characters, e.g.:
+//
+//     if k, ok := ebiten.KeyForName("z"); ok && ebiten.IsKeyPressed(k) {
+//         // Undo
+//     }
+//
+// name is case-insensitive. The second returned value is false when no key produces name,
+// or the characters on the current layout are unknown (see KeyName).
+//
+// This function is concurrent-safe.
+func KeyForName(name string) (Key, bool) {
+	for k := Key(0); k <= KeyMax; k++ {
+		if n := ui.KeyName(ui.Key(k)); n != "" && strings.EqualFold(n, name) {
+			return k, true
+		}
+	}
+	return 0, false
+}
@@ -107,3 +107,168 @@ const (
 	KeyUp           Key = Key(ui.KeyUp)
 	KeyMax          Key = KeyUp
 )
+
+// String returns the name of the key without the prefix 'Key', e.g. "A" for KeyA or "Space" for KeySpace.
+//
+// The name doesn't depend on the keyboard layout. Use KeyName for a human-readable name.
+func (k Key) String() string {
+	switch k {
+	case Key0:
+		return "0"
+	case Key1:
+		return "1"
+	case Key2:
+		return "2"
+	case Key3:
+		return "3"
+	case Key4:
+		return "4"
+	case Key5:
+		return "5"
+	case Key6:
+		return "6"
+	case Key7:
+		return "7"
+	case Key8:
+		return "8"
+	case Key9:
+		return "9"
+	case KeyA:
+		return "A"
+	case KeyB:
+		return "B"
+	case KeyC:
+		return "C"
+	case KeyD:
+		return "D"
+	case KeyE:
+		return "E"
+	case KeyF:
+		return "F"
+	case KeyG:
+		return "G"
+	case KeyH:
+		return "H"
+	case KeyI:
+		return "I"
+	case KeyJ:
+		return "J"
+	case KeyK:
+		return "K"
+	case KeyL:
+		return "L"
+	case KeyM:
+		return "M"
+	case KeyN:
+		return "N"
+	case KeyO:
+		return "O"
+	case KeyP:
+		return "P"
+	case KeyQ:
+		return "Q"
+	case KeyR:
+		return "R"
+	case KeyS:
+		return "S"
+	case KeyT:
+		return "T"
+	case KeyU:
+		return "U"
+	case KeyV:
+		return "V"
+	case KeyW:
+		return "W"
+	case KeyX:
+		return "X"
+	case KeyY:
+		return "Y"
+	case KeyZ:
+		return "Z"
+	case KeyAlt:
+		return "Alt"
+	case KeyApostrophe:
+		return "Apostrophe"
+	case KeyBackslash:
+		return "Backslash"
+	case KeyBackspace:
+		return "Backspace"
+	case KeyCapsLock:
+		return "CapsLock"
+	case KeyComma:
+		return "Comma"
+	case KeyControl:
+		return "Control"
+	case KeyDelete:
+		return "Delete"
+	case KeyDown:
+		return "Down"
+	case KeyEnd:
+		return "End"
+	case KeyEnter:
+		return "Enter"
+	case KeyEqual:
+		return "Equal"
+	case KeyEscape:
+		return "Escape"
+	case KeyF1:
+		return "F1"
+	case KeyF2:
+		return "F2"
+	case KeyF3:
+		return "F3"
+	case KeyF4:
+		return "F4"
+	case KeyF5:
+		return "F5"
+	case KeyF6:
+		return "F6"
+	case KeyF7:
+		return "F7"
+	case KeyF8:
+		return "F8"
+	case KeyF9:
+		return "F9"
+	case KeyF10:
+		return "F10"
+	case KeyF11:
+		return "F11"
+	case KeyF12:
+		return "F12"
+	case KeyGraveAccent:
+		return "GraveAccent"
+	case KeyHome:
+		return "Home"
+	case KeyInsert:
+		return "Insert"
+	case KeyLeft:
+		return "Left"
+	case KeyLeftBracket:
+		return "LeftBracket"
+	case KeyMinus:
+		return "Minus"
+	case KeyPageDown:
+		return "PageDown"
+	case KeyPageUp:
+		return "PageUp"
+	case KeyPeriod:
+		return "Period"
+	case KeyRight:
+		return "Right"
+	case KeyRightBracket:
+		return "RightBracket"
+	case KeySemicolon:
+		return "Semicolon"
+	case KeyShift:
+		return "Shift"
+	case KeySlash:
+		return "Slash"
+	case KeySpace:
+		return "Space"
+	case KeyTab:
+		return "Tab"
+	case KeyUp:
+		return "Up"
+	}
+	return ""
+}
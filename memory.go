@@ -0,0 +1,126 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/internal/restorable"
+)
+
+// MemoryStats represents the statistics of the video memory used by images.
+type MemoryStats struct {
+	// ImageCount is the number of the live images.
+	// This includes the internal images like the offscreen and the atlases that small images share.
+	// The screen is not counted.
+	ImageCount int
+
+	// TotalBytes is the estimated total size in bytes of the video memory used by the images.
+	//
+	// The size of an image is estimated from its texture size, which is the power of 2 of the image size.
+	// Mipmaps are not counted.
+	TotalBytes int
+}
+
+// ReadMemoryStats populates stats with the statistics of the video memory used by images.
+//
+// The statistics are estimated from the images Ebiten creates, and the actual usage
+// in the driver might be different.
+//
+// This function is concurrent-safe.
+func ReadMemoryStats(stats *MemoryStats) {
+	stats.ImageCount, stats.TotalBytes = restorable.ReadMemoryStats()
+}
+
+// SizeInBytes returns the estimated size in bytes of the video memory the image uses.
+//
+// For an image in an atlas, SizeInBytes returns the size of the region in the atlas.
+//
+// When the image is a sub-image or is disposed, SizeInBytes returns 0.
+func (i *Image) SizeInBytes() int {
+	if i.isSubImage() {
+		return 0
+	}
+	if i.shared != nil {
+		w, h := i.Size()
+		return 4 * w * h
+	}
+	if i.restorable == nil {
+		return 0
+	}
+	return i.restorable.SizeInBytes()
+}
+
+// MemoryBudgetPolicy represents what happens when the video memory used by images exceeds the budget.
+type MemoryBudgetPolicy int
+
+const (
+	// MemoryBudgetLog logs a message once each time the usage exceeds the budget.
+	MemoryBudgetLog MemoryBudgetPolicy = iota
+
+	// MemoryBudgetError makes Run return an error when the usage exceeds the budget.
+	MemoryBudgetError
+)
+
+var theMemoryBudget = &memoryBudget{}
+
+type memoryBudget struct {
+	bytes    int
+	policy   MemoryBudgetPolicy
+	exceeded bool
+	m        sync.Mutex
+}
+
+// SetMemoryBudget sets the soft budget in bytes of the video memory used by images.
+//
+// The usage is checked against the budget at the end of each frame. See ReadMemoryStats for the usage.
+// The policy decides what happens when the usage exceeds the budget.
+// If bytes is 0 or less, the budget is disabled. The budget is disabled by default.
+//
+// The budget doesn't prevent creating images; use this to find out that the game uses too much memory
+// before it runs out of memory e.g. on mobiles.
+//
+// This function is concurrent-safe.
+func SetMemoryBudget(bytes int, policy MemoryBudgetPolicy) {
+	theMemoryBudget.m.Lock()
+	theMemoryBudget.bytes = bytes
+	theMemoryBudget.policy = policy
+	theMemoryBudget.exceeded = false
+	theMemoryBudget.m.Unlock()
+}
+
+// check checks the current usage against the budget.
+func (m *memoryBudget) check() error {
+	m.m.Lock()
+	defer m.m.Unlock()
+	if m.bytes <= 0 {
+		return nil
+	}
+	_, used := restorable.ReadMemoryStats()
+	if used <= m.bytes {
+		m.exceeded = false
+		return nil
+	}
+	if m.policy == MemoryBudgetError {
+		return fmt.Errorf("ebiten: the video memory usage (%d bytes) exceeds the budget (%d bytes)", used, m.bytes)
+	}
+	if !m.exceeded {
+		log.Printf("ebiten: the video memory usage (%d bytes) exceeds the budget (%d bytes)", used, m.bytes)
+	}
+	m.exceeded = true
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+)
+
+func TestReadMemoryStats(t *testing.T) {
+	var before MemoryStats
+	ReadMemoryStats(&before)
+
+	img, _ := NewImage(300, 100, FilterNearest)
+	defer img.Dispose()
+
+	// The texture size is the power of 2 of the image size.
+	if got, want := img.SizeInBytes(), 4*512*128; got != want {
+		t.Errorf("img.SizeInBytes(): got: %d, want: %d", got, want)
+	}
+
+	var after MemoryStats
+	ReadMemoryStats(&after)
+	if got, want := after.ImageCount, before.ImageCount+1; got != want {
+		t.Errorf("ImageCount: got: %d, want: %d", got, want)
+	}
+	if got, want := after.TotalBytes, before.TotalBytes+img.SizeInBytes(); got != want {
+		t.Errorf("TotalBytes: got: %d, want: %d", got, want)
+	}
+
+	sub := img.SubImage(img.Bounds()).(*Image)
+	if got := sub.SizeInBytes(); got != 0 {
+		t.Errorf("sub.SizeInBytes(): got: %d, want: 0", got)
+	}
+}
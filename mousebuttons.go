@@ -19,6 +19,10 @@ import (
 )
 
 // A MouseButton represents a mouse button.
+//
+// MouseButton4 and MouseButton5 are usually the back and the forward buttons.
+// The assignments of the other extended buttons depend on the mouse.
+// On browsers, only the buttons up to MouseButton5 are available.
 type MouseButton int
 
 // MouseButtons
@@ -26,4 +30,10 @@ const (
 	MouseButtonLeft   MouseButton = MouseButton(ui.MouseButtonLeft)
 	MouseButtonRight  MouseButton = MouseButton(ui.MouseButtonRight)
 	MouseButtonMiddle MouseButton = MouseButton(ui.MouseButtonMiddle)
+	MouseButton4      MouseButton = MouseButton(ui.MouseButton4)
+	MouseButton5      MouseButton = MouseButton(ui.MouseButton5)
+	MouseButton6      MouseButton = MouseButton(ui.MouseButton6)
+	MouseButton7      MouseButton = MouseButton(ui.MouseButton7)
+	MouseButton8      MouseButton = MouseButton(ui.MouseButton8)
+	MouseButtonMax    MouseButton = MouseButton8
 )
@@ -0,0 +1,151 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"runtime"
+
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/restorable"
+)
+
+// PaletteSize is the number of the colors in a palette.
+const PaletteSize = restorable.PaletteSize
+
+// Palette represents a palette to draw indexed images with.
+//
+// The colors of a palette can be changed at any time, e.g. every frame for palette cycling.
+// The change affects only the drawings after the change.
+type Palette struct {
+	restorable *restorable.Image
+
+	// colors is the colors of the palette with premultiplied alpha.
+	colors [PaletteSize]color.RGBA
+
+	// dirty indicates whether colors have to be sent to the palette texture.
+	dirty bool
+}
+
+// NewPalette returns a new palette with the given colors.
+//
+// The colors after len(colors) are transparent.
+//
+// If len(colors) is more than PaletteSize, NewPalette panics.
+func NewPalette(colors color.Palette) *Palette {
+	p := &Palette{
+		restorable: restorable.NewImage(PaletteSize, 1, opengl.Nearest, false),
+	}
+	p.SetColors(colors)
+	runtime.SetFinalizer(p, (*Palette).Dispose)
+	return p
+}
+
+// SetColor sets the color at index.
+//
+// If index is out of the range [0, PaletteSize), SetColor panics.
+func (p *Palette) SetColor(index int, clr color.Color) {
+	if index < 0 || PaletteSize <= index {
+		panic(fmt.Sprintf("ebiten: index must be in [0, %d) but %d", PaletteSize, index))
+	}
+	p.colors[index] = color.RGBAModel.Convert(clr).(color.RGBA)
+	p.dirty = true
+}
+
+// SetColors sets the colors from index 0. The colors after len(colors) are not changed.
+//
+// If len(colors) is more than PaletteSize, SetColors panics.
+func (p *Palette) SetColors(colors color.Palette) {
+	if len(colors) > PaletteSize {
+		panic(fmt.Sprintf("ebiten: len(colors) must be <= %d but %d", PaletteSize, len(colors)))
+	}
+	for i, c := range colors {
+		p.colors[i] = color.RGBAModel.Convert(c).(color.RGBA)
+	}
+	p.dirty = true
+}
+
+// Color returns the color at index.
+//
+// If index is out of the range [0, PaletteSize), Color panics.
+func (p *Palette) Color(index int) color.Color {
+	if index < 0 || PaletteSize <= index {
+		panic(fmt.Sprintf("ebiten: index must be in [0, %d) but %d", PaletteSize, index))
+	}
+	return p.colors[index]
+}
+
+// Dispose disposes the palette.
+//
+// After disposing, drawing with the palette draws nothing.
+func (p *Palette) Dispose() {
+	if p.restorable == nil {
+		return
+	}
+	p.restorable.Dispose()
+	p.restorable = nil
+	runtime.SetFinalizer(p, nil)
+}
+
+// restorableImage returns the palette texture, sending the colors if needed.
+// restorableImage returns nil when the palette is disposed.
+func (p *Palette) restorableImage() *restorable.Image {
+	if p.restorable == nil {
+		return nil
+	}
+	if p.dirty {
+		pix := make([]uint8, 4*PaletteSize)
+		for i, c := range p.colors {
+			pix[4*i] = c.R
+			pix[4*i+1] = c.G
+			pix[4*i+2] = c.B
+			pix[4*i+3] = c.A
+		}
+		p.restorable.ReplacePixels(pix)
+		p.dirty = false
+	}
+	return p.restorable
+}
+
+// NewIndexedImage returns an indexed image of the given paletted image.
+//
+// The red, green and blue channels of each pixel of an indexed image are the palette index of the pixel,
+// and the alpha channel is always opaque.
+// To draw an indexed image with its colors, specify a palette in DrawImageOptions or DrawTrianglesOptions.
+// The colors of source.Palette are not used. Use NewPalette(source.Palette) to draw the image as it is.
+//
+// Drawing an indexed image without palettes shows the indices in gray scale.
+// Drawing onto an indexed image breaks the indices.
+//
+// If source's size is less than 1 or more than MaxImageSize, NewIndexedImage panics.
+//
+// Error returned by NewIndexedImage is always nil.
+func NewIndexedImage(source *image.Paletted) (*Image, error) {
+	b := source.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for j := b.Min.Y; j < b.Max.Y; j++ {
+		for i := b.Min.X; i < b.Max.X; i++ {
+			idx := source.ColorIndexAt(i, j)
+			k := img.PixOffset(i-b.Min.X, j-b.Min.Y)
+			img.Pix[k] = idx
+			img.Pix[k+1] = idx
+			img.Pix[k+2] = idx
+			img.Pix[k+3] = 0xff
+		}
+	}
+	return NewImageFromImage(img, FilterNearest)
+}
@@ -0,0 +1,308 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package particles offers particle emitters for effects like smoke, sparks and rain.
+//
+// An Emitter spawns particles, updates them every tick and draws all of them with one call of DrawTriangles
+// for every 16384 particles.
+//
+// Note: This package is experimental and API might be changed.
+package particles
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// maxQuadsNum is the maximum number of particles in one call of DrawTriangles.
+// The number of vertices must be within the range of uint16 indices.
+const maxQuadsNum = (1 << 16) / 4
+
+// Range represents a range of values. A value is chosen uniformly at random from [Min, Max].
+type Range struct {
+	Min float64
+	Max float64
+}
+
+func (r Range) random(rnd *rand.Rand) float64 {
+	if r.Min == r.Max {
+		return r.Min
+	}
+	return r.Min + rnd.Float64()*(r.Max-r.Min)
+}
+
+// Config represents the behavior of the particles of an Emitter.
+//
+// Times are in ticks, and velocities are in pixels per tick.
+type Config struct {
+	// Image is the image of a particle.
+	Image *ebiten.Image
+
+	// SourceRect is the region of Image to draw.
+	// The default (zero) value means the whole image.
+	SourceRect image.Rectangle
+
+	// SpawnRate is the number of the particles spawned per tick.
+	// A fractional value is accumulated over ticks.
+	SpawnRate float64
+
+	// MaxParticles is the maximum number of the living particles.
+	// The default (zero) value means no limit.
+	MaxParticles int
+
+	// Lifetime is the lifetime of a particle in ticks.
+	Lifetime Range
+
+	// Speed and Angle are the initial speed and the direction in radians of a particle.
+	// An angle of 0 points to the right, and π/2 points downward.
+	Speed Range
+	Angle Range
+
+	// SpawnWidth and SpawnHeight are the size of the rectangle around the emitter's position
+	// where particles are spawned.
+	SpawnWidth  float64
+	SpawnHeight float64
+
+	// GravityX and GravityY are the acceleration applied to the particles every tick.
+	GravityX float64
+	GravityY float64
+
+	// Damping is the ratio of the velocity lost every tick, between 0 and 1.
+	Damping float64
+
+	// StartColor and EndColor are the colors at the birth and the death of a particle.
+	// The color is interpolated linearly over the lifetime.
+	// The default (nil) values are white.
+	StartColor color.Color
+	EndColor   color.Color
+
+	// StartScale and EndScale are the scales at the birth and the death of a particle.
+	// The scale is interpolated linearly over the lifetime.
+	// The default (zero) values mean 1.
+	StartScale float64
+	EndScale   float64
+
+	// AngularVelocity is the rotation of a particle in radians per tick.
+	AngularVelocity Range
+}
+
+type particle struct {
+	x        float64
+	y        float64
+	vx       float64
+	vy       float64
+	rotation float64
+	spin     float64
+	age      float64
+	lifetime float64
+}
+
+// Emitter emits particles.
+type Emitter struct {
+	config    Config
+	particles []particle
+	pending   float64
+	rand      *rand.Rand
+
+	// X and Y are the position of the emitter.
+	X float64
+	Y float64
+
+	// Paused reports whether the emitter stops spawning new particles.
+	// The living particles are still updated.
+	Paused bool
+
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+// NewEmitter returns a new Emitter with the given config.
+func NewEmitter(config *Config) *Emitter {
+	if config.Image == nil {
+		panic("particles: Config.Image must not be nil")
+	}
+	return &Emitter{
+		config: *config,
+		rand:   rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Len returns the number of the living particles.
+func (e *Emitter) Len() int {
+	return len(e.particles)
+}
+
+// Burst spawns n particles immediately regardless of SpawnRate and Paused.
+func (e *Emitter) Burst(n int) {
+	for i := 0; i < n; i++ {
+		e.spawn()
+	}
+}
+
+func (e *Emitter) spawn() {
+	c := &e.config
+	if c.MaxParticles > 0 && len(e.particles) >= c.MaxParticles {
+		return
+	}
+	lifetime := c.Lifetime.random(e.rand)
+	if lifetime <= 0 {
+		return
+	}
+	speed := c.Speed.random(e.rand)
+	angle := c.Angle.random(e.rand)
+	e.particles = append(e.particles, particle{
+		x:        e.X + (e.rand.Float64()-0.5)*c.SpawnWidth,
+		y:        e.Y + (e.rand.Float64()-0.5)*c.SpawnHeight,
+		vx:       speed * math.Cos(angle),
+		vy:       speed * math.Sin(angle),
+		spin:     c.AngularVelocity.random(e.rand),
+		lifetime: lifetime,
+	})
+}
+
+// Update advances the particles by one tick and spawns new particles.
+//
+// Update is expected to be called every tick, i.e., in the game's update function.
+func (e *Emitter) Update() {
+	c := &e.config
+
+	// Remove the dead particles keeping the order.
+	ps := e.particles[:0]
+	for _, p := range e.particles {
+		p.age++
+		if p.age >= p.lifetime {
+			continue
+		}
+		p.vx += c.GravityX
+		p.vy += c.GravityY
+		p.vx *= 1 - c.Damping
+		p.vy *= 1 - c.Damping
+		p.x += p.vx
+		p.y += p.vy
+		p.rotation += p.spin
+		ps = append(ps, p)
+	}
+	e.particles = ps
+
+	if e.Paused {
+		return
+	}
+	e.pending += c.SpawnRate
+	for ; e.pending >= 1; e.pending-- {
+		e.spawn()
+	}
+}
+
+func colorToFloats(clr color.Color) [4]float64 {
+	if clr == nil {
+		return [4]float64{1, 1, 1, 1}
+	}
+	r, g, b, a := clr.RGBA()
+	if a == 0 {
+		return [4]float64{}
+	}
+	// Vertex colors scale the non-premultiplied colors of the source.
+	return [4]float64{float64(r) / float64(a), float64(g) / float64(a), float64(b) / float64(a), float64(a) / 0xffff}
+}
+
+// DrawOptions represents options to draw particles.
+type DrawOptions struct {
+	// GeoM is a geometry matrix applied to the particles' positions, e.g., for a camera.
+	GeoM ebiten.GeoM
+
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	// CompositeModeLighter is suitable for glowing effects like sparks.
+	CompositeMode ebiten.CompositeMode
+}
+
+// Draw draws the living particles on dst.
+// The particles are drawn from the oldest to the newest.
+//
+// op can be nil, which means the default options.
+func (e *Emitter) Draw(dst *ebiten.Image, op *DrawOptions) {
+	if op == nil {
+		op = &DrawOptions{}
+	}
+	c := &e.config
+	r := c.SourceRect
+	if r.Empty() {
+		r = c.Image.Bounds()
+	}
+	hw, hh := float64(r.Dx())/2, float64(r.Dy())/2
+	startScale, endScale := c.StartScale, c.EndScale
+	if startScale == 0 {
+		startScale = 1
+	}
+	if endScale == 0 {
+		endScale = 1
+	}
+	c0, c1 := colorToFloats(c.StartColor), colorToFloats(c.EndColor)
+
+	top := &ebiten.DrawTrianglesOptions{
+		CompositeMode: op.CompositeMode,
+	}
+	flush := func() {
+		if len(e.vertices) == 0 {
+			return
+		}
+		dst.DrawTriangles(e.vertices, e.indices, c.Image, top)
+		e.vertices = e.vertices[:0]
+		e.indices = e.indices[:0]
+	}
+
+	for _, p := range e.particles {
+		if len(e.vertices) == 4*maxQuadsNum {
+			flush()
+		}
+		t := p.age / p.lifetime
+		s := startScale + (endScale-startScale)*t
+		var clr [4]float32
+		for i := range clr {
+			clr[i] = float32(c0[i] + (c1[i]-c0[i])*t)
+		}
+		sin, cos := math.Sincos(p.rotation)
+		n := uint16(len(e.vertices))
+		for _, v := range []struct {
+			x  float64
+			y  float64
+			sx int
+			sy int
+		}{
+			{-hw, -hh, r.Min.X, r.Min.Y},
+			{hw, -hh, r.Max.X, r.Min.Y},
+			{-hw, hh, r.Min.X, r.Max.Y},
+			{hw, hh, r.Max.X, r.Max.Y},
+		} {
+			x, y := v.x*s, v.y*s
+			dx, dy := op.GeoM.Apply(x*cos-y*sin+p.x, x*sin+y*cos+p.y)
+			e.vertices = append(e.vertices, ebiten.Vertex{
+				DstX:   float32(dx),
+				DstY:   float32(dy),
+				SrcX:   float32(v.sx),
+				SrcY:   float32(v.sy),
+				ColorR: clr[0],
+				ColorG: clr[1],
+				ColorB: clr[2],
+				ColorA: clr[3],
+			})
+		}
+		e.indices = append(e.indices, n, n+1, n+2, n+1, n+2, n+3)
+	}
+	flush()
+}
@@ -0,0 +1,98 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package particles_test
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/particles"
+)
+
+func TestMain(m *testing.M) {
+	code := 0
+	// Run an Ebiten process so that (*Image).At is available.
+	regularTermination := errors.New("regular termination")
+	f := func(screen *ebiten.Image) error {
+		code = m.Run()
+		return regularTermination
+	}
+	if err := ebiten.Run(f, 320, 240, 1, "Test"); err != nil && err != regularTermination {
+		panic(err)
+	}
+	os.Exit(code)
+}
+
+func TestEmitterLifetime(t *testing.T) {
+	src, _ := ebiten.NewImage(1, 1, ebiten.FilterNearest)
+	src.Fill(color.White)
+
+	e := NewEmitter(&Config{
+		Image:     src,
+		SpawnRate: 0.5,
+		Lifetime:  Range{Min: 4, Max: 4},
+	})
+	for i := 0; i < 4; i++ {
+		e.Update()
+	}
+	// Particles are spawned at the 2nd and the 4th ticks.
+	if got, want := e.Len(), 2; got != want {
+		t.Errorf("Len(): got: %d, want: %d", got, want)
+	}
+	e.Paused = true
+	for i := 0; i < 4; i++ {
+		e.Update()
+	}
+	if got, want := e.Len(), 0; got != want {
+		t.Errorf("Len(): got: %d, want: %d", got, want)
+	}
+}
+
+func TestEmitterDraw(t *testing.T) {
+	src, _ := ebiten.NewImage(2, 2, ebiten.FilterNearest)
+	src.Fill(color.White)
+
+	e := NewEmitter(&Config{
+		Image:      src,
+		Lifetime:   Range{Min: 2, Max: 2},
+		Speed:      Range{Min: 1, Max: 1},
+		GravityY:   1,
+		StartColor: color.RGBA{0xff, 0, 0, 0xff},
+		EndColor:   color.RGBA{0xff, 0, 0, 0xff},
+	})
+	e.X = 4
+	e.Y = 4
+	e.Burst(1)
+	// The velocity is (1, 1) after gravity is applied.
+	e.Update()
+
+	dst, _ := ebiten.NewImage(8, 8, ebiten.FilterNearest)
+	e.Draw(dst, nil)
+	for j := 0; j < 8; j++ {
+		for i := 0; i < 8; i++ {
+			got := dst.At(i, j)
+			want := color.RGBA{}
+			if 4 <= i && i < 6 && 4 <= j && j < 6 {
+				want = color.RGBA{0xff, 0, 0, 0xff}
+			}
+			if got != want {
+				t.Errorf("dst.At(%d, %d): got: %v, want: %v", i, j, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ReadPixels reads the pixels of the image into dst.
+//
+// The pixels are written to dst as RGBA pre-multiplied alpha values in the same layout as ReplacePixels.
+// len(dst) must equal to 4 * (image width) * (image height).
+//
+// ReadPixels reads all the pixels at once, which is much faster than calling At for each pixel.
+// Nevertheless, ReadPixels still has to wait for GPU if the image is modified after the last reading.
+// To avoid the stall in the middle of a frame, use ReadPixelsAsync.
+//
+// When the image is a sub-image, the pixels in the sub-image's bounds are read.
+//
+// When len(dst) is not appropriate, ReadPixels panics.
+//
+// When the image is disposed, ReadPixels does nothing.
+//
+// ReadPixels can't be called before the main loop (ebiten.Run) starts.
+func (i *Image) ReadPixels(dst []byte) error {
+	i.checkReadPixelsLen(dst)
+	return i.readPixels(dst)
+}
+
+// ReadPixelsAsync requests to read the pixels of the image into dst, and returns a channel
+// that receives the result of the reading.
+//
+// The reading is done at the end of the current frame, where all the drawing commands are
+// sent to GPU anyway, so that ReadPixelsAsync doesn't stall the frame.
+// All the requests in a frame are processed together.
+// dst must not be used until the channel receives a value.
+//
+// The format of dst and the treatment of sub-images are the same as ReadPixels.
+// dst has the pixels at the time when the request is processed,
+// so the drawing to the image after calling ReadPixelsAsync in the same frame is reflected.
+//
+// When len(dst) is not appropriate, ReadPixelsAsync panics.
+//
+// When the image is disposed at the time of the reading, dst is not modified and the channel receives nil.
+func (i *Image) ReadPixelsAsync(dst []byte) <-chan error {
+	i.checkReadPixelsLen(dst)
+	ch := make(chan error, 1)
+	theReadPixelsQueue.enqueue(&readPixelsRequest{
+		image: i,
+		dst:   dst,
+		ch:    ch,
+	})
+	return ch
+}
+
+func (i *Image) checkReadPixelsLen(dst []byte) {
+	w, h := i.Size()
+	if l := 4 * w * h; len(dst) != l {
+		panic(fmt.Sprintf("ebiten: len(dst) was %d but must be %d", len(dst), l))
+	}
+}
+
+func (i *Image) readPixels(dst []byte) error {
+	r := i.restorableImage()
+	if r == nil {
+		return nil
+	}
+	b := i.Bounds()
+	if b.Empty() {
+		return nil
+	}
+	b = b.Add(i.offset())
+	return r.ReadPixels(dst, b.Min.X, b.Min.Y, b.Dx(), b.Dy())
+}
+
+type readPixelsRequest struct {
+	image *Image
+	dst   []byte
+	ch    chan<- error
+}
+
+type readPixelsQueue struct {
+	requests []*readPixelsRequest
+	m        sync.Mutex
+}
+
+var theReadPixelsQueue = &readPixelsQueue{}
+
+func (q *readPixelsQueue) enqueue(r *readPixelsRequest) {
+	q.m.Lock()
+	q.requests = append(q.requests, r)
+	q.m.Unlock()
+}
+
+// flush processes all the requests.
+//
+// flush must be called after all the drawing commands in the frame are issued.
+func (q *readPixelsQueue) flush() {
+	q.m.Lock()
+	rs := q.requests
+	q.requests = nil
+	q.m.Unlock()
+
+	for _, r := range rs {
+		r.ch <- r.image.readPixels(r.dst)
+	}
+}
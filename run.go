@@ -19,23 +19,89 @@ import (
 	"sync/atomic"
 
 	"github.com/hajimehoshi/ebiten/internal/clock"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
 	"github.com/hajimehoshi/ebiten/internal/ui"
 )
 
-// FPS represents how many times game updating happens in a second (60).
+// FPS represents the default number of how many times game updating happens in a second (60).
+//
+// The number of game updating in a second is configurable with SetMaxTPS.
 const FPS = clock.FPS
 
+// UncappedTPS is a special TPS value that means the game doesn't have limitation on TPS.
+// With UncappedTPS, game updating happens once per rendering frame.
+const UncappedTPS = clock.UncappedTPS
+
 // CurrentFPS returns the current number of frames per second of rendering.
 //
 // The returned value represents how many times rendering happens in a second and
 // NOT how many times logical game updating (a passed function to Run) happens.
-// Note that logical game updating is assured to happen 60 times in a second.
+// Use CurrentTPS to get the number of logical game updating in a second.
 //
 // This function is concurrent-safe.
 func CurrentFPS() float64 {
 	return clock.CurrentFPS()
 }
 
+// CurrentTPS returns the current number of ticks per second,
+// that represents how many times logical game updating (a passed function to Run or Game's Update) happens in a second.
+//
+// This function is concurrent-safe.
+func CurrentTPS() float64 {
+	return clock.CurrentTPS()
+}
+
+// TickProgress returns the progress of time since the latest game updating (tick) in the current rendering frame,
+// in the unit of a tick. The returned value is in [0, 1].
+//
+// TickProgress is useful to interpolate positions at drawing on a display with a higher refresh rate
+// than TPS, while keeping the game logic deterministic:
+//
+//     // In Draw
+//     x := prevX + (currX-prevX)*ebiten.TickProgress()
+//
+// TickProgress returns 0 when the game clock is just synchronized, e.g. with UncappedTPS,
+// FPSModeVsyncOffMinimum, or the audio clock.
+//
+// This function is concurrent-safe.
+func TickProgress() float64 {
+	return clock.TickProgress()
+}
+
+var (
+	theMaxTPS          = int32(FPS)
+	theFrameSkipPolicy = int32(FrameSkipPolicySkipDraw)
+	updateCountInFrame = int32(0)
+)
+
+// MaxTPS returns the current maximum TPS.
+//
+// This function is concurrent-safe.
+func MaxTPS() int {
+	return int(atomic.LoadInt32(&theMaxTPS))
+}
+
+// SetMaxTPS sets the maximum TPS (ticks per second),
+// that represents how many times logical game updating happens in a second.
+// The initial value is 60 (FPS).
+//
+// Game updating is decoupled from rendering: rendering still follows the display,
+// and logical game updating happens zero or more times before each rendering frame to keep the given TPS.
+//
+// If tps is UncappedTPS, game updating happens once per rendering frame.
+// If tps is 0, game updating never happens.
+// SetMaxTPS panics if tps is negative and is not UncappedTPS.
+//
+// SetMaxTPS can be called before Run and at any time while the game is running.
+//
+// This function is concurrent-safe.
+func SetMaxTPS(tps int) {
+	if tps < 0 && tps != UncappedTPS {
+		panic("ebiten: tps must be >= 0 or UncappedTPS")
+	}
+	atomic.StoreInt32(&theMaxTPS, int32(tps))
+}
+
 var (
 	isRunningSlowly = int32(0)
 )
@@ -48,7 +114,42 @@ func setRunningSlowly(slow bool) {
 	atomic.StoreInt32(&isRunningSlowly, v)
 }
 
-// IsRunningSlowly returns true if the game is running too slowly to keep 60 FPS of rendering.
+// FrameSkipPolicy returns the current frame skip policy.
+//
+// This function is concurrent-safe.
+func FrameSkipPolicy() FrameSkipPolicyType {
+	return FrameSkipPolicyType(atomic.LoadInt32(&theFrameSkipPolicy))
+}
+
+// SetFrameSkipPolicy sets the frame skip policy,
+// that represents how game updating and rendering behave when rendering falls behind.
+//
+// The initial value is FrameSkipPolicySkipDraw.
+//
+// SetFrameSkipPolicy can be called before Run and at any time while the game is running.
+//
+// This function is concurrent-safe.
+func SetFrameSkipPolicy(policy FrameSkipPolicyType) {
+	atomic.StoreInt32(&theFrameSkipPolicy, int32(policy))
+}
+
+// UpdateCountInFrame returns the number of game updating in the current rendering frame.
+//
+// In the game updating, UpdateCountInFrame returns the number of game updating planned for the current frame.
+// In Game's Draw, UpdateCountInFrame returns how many times Update was called before Draw in the current frame.
+// The returned value can be 0 e.g. when the display's refresh rate is higher than TPS,
+// and more than 1 when rendering falls behind (see SetFrameSkipPolicy).
+//
+// This function is concurrent-safe.
+func UpdateCountInFrame() int {
+	return int(atomic.LoadInt32(&updateCountInFrame))
+}
+
+func setUpdateCountInFrame(count int) {
+	atomic.StoreInt32(&updateCountInFrame, int32(count))
+}
+
+// IsRunningSlowly returns true if the game is running too slowly to keep the maximum TPS.
 // The game screen is not updated when IsRunningSlowly is true.
 // It is recommended to skip heavy processing, especially drawing screen,
 // when IsRunningSlowly is true.
@@ -79,7 +180,12 @@ func (u *updater) SetSize(width, height int, scale float64) {
 }
 
 func (u *updater) Update(afterFrameUpdate func()) error {
-	n := clock.Update()
+	tps := MaxTPS()
+	if tps != 0 && FPSMode() == FPSModeVsyncOffMinimum {
+		// The time between frames is arbitrary, so update the game exactly once per frame.
+		tps = UncappedTPS
+	}
+	n := clock.Update(tps, clock.FrameSkipPolicy(FrameSkipPolicy()))
 	if err := u.g.Update(n, afterFrameUpdate); err != nil {
 		return err
 	}
@@ -101,10 +207,12 @@ func (u *updater) Invalidate() {
 // Run must be called from the OS main thread.
 // Note that Ebiten bounds the main goroutine to the main OS thread by runtime.LockOSThread.
 //
-// The given function f is guaranteed to be called 60 times a second
+// The given function f is guaranteed to be called 60 times a second by default
 // even if a rendering frame is skipped.
+// The number of calls in a second is configurable with SetMaxTPS.
+// How to behave when rendering falls behind is configurable with SetFrameSkipPolicy.
 // f is not called when the window is in background by default.
-// This setting is configurable with SetRunnableInBackground.
+// This setting is configurable with SetRunnableOnUnfocused.
 //
 // The given scale is ignored on fullscreen mode.
 //
@@ -176,6 +284,39 @@ func SetScreenScale(scale float64) {
 	ui.SetScreenScale(scale)
 }
 
+// finalScreenDrawer is a wrapper to store a function in atomic.Value, which can't store nil.
+type finalScreenDrawer struct {
+	f func(screen *Image, offscreen *Image)
+}
+
+var theFinalScreenDrawer atomic.Value
+
+// SetFinalScreenDrawer sets the function to draw the game screen onto the window at the end of each frame.
+//
+// screen is the image that represents the window's framebuffer, and its size is the screen size multiplied by the scale.
+// screen is already cleared when f is called.
+// offscreen is the image that was passed to the update function, and its size is the screen size.
+// f is responsible for drawing offscreen onto screen, typically with scaling to fit screen.
+// f can use DrawTrianglesShader for global effects like CRT, scanlines or anti-aliasing
+// without an extra offscreen pass.
+//
+// If f is nil, the default drawer, which just scales offscreen to fit screen, is used.
+//
+// f must not draw onto offscreen or use screen after f returns.
+//
+// This function is concurrent-safe.
+func SetFinalScreenDrawer(f func(screen *Image, offscreen *Image)) {
+	theFinalScreenDrawer.Store(finalScreenDrawer{f})
+}
+
+func currentFinalScreenDrawer() func(screen *Image, offscreen *Image) {
+	d, ok := theFinalScreenDrawer.Load().(finalScreenDrawer)
+	if !ok {
+		return nil
+	}
+	return d.f
+}
+
 // ScreenScale returns the current screen scale.
 //
 // If Run is not called, this returns 0.
@@ -204,6 +345,69 @@ func SetCursorVisibility(visible bool) {
 	ui.SetCursorVisibility(visible)
 }
 
+// CursorMode returns the current cursor mode.
+//
+// CursorMode always returns CursorModeHidden on mobiles.
+//
+// This function is concurrent-safe.
+func CursorMode() CursorModeType {
+	return CursorModeType(ui.GetCursorMode())
+}
+
+// SetCursorMode sets the cursor mode.
+//
+// The initial value is CursorModeVisible.
+// SetCursorVisibility(true) and SetCursorVisibility(false) are equivalent to
+// SetCursorMode(CursorModeVisible) and SetCursorMode(CursorModeHidden).
+//
+// In CursorModeCaptured, the cursor doesn't leave the game window, and
+// the movement can be read by CursorDelta.
+// This is useful e.g. for controlling a camera in first-person games.
+//
+// On browsers, the cursor is captured by the Pointer Lock API, which requires a user action.
+// If the cursor can't be captured immediately, it is captured when the user clicks the game screen.
+//
+// SetCursorMode can be called before Run.
+//
+// SetCursorMode does nothing on mobiles.
+//
+// This function is concurrent-safe.
+func SetCursorMode(mode CursorModeType) {
+	ui.SetCursorMode(ui.CursorMode(mode))
+}
+
+// SetCursorImage sets the image of the mouse cursor.
+//
+// (hotX, hotY) is the position of the cursor's hot spot, relative to the upper-left corner of img.
+// If img is nil, the cursor reverts to the default one.
+//
+// Unlike drawing a sprite at CursorPosition, the cursor set by SetCursorImage is rendered by the OS
+// and doesn't lag behind the pointer.
+// The image is not scaled by the screen scale.
+//
+// SetCursorImage can be called before Run.
+//
+// SetCursorImage does nothing on mobiles.
+//
+// This function is concurrent-safe.
+func SetCursorImage(img image.Image, hotX, hotY int) {
+	ui.SetCursorImage(img, hotX, hotY)
+}
+
+// SetCursorShape sets the shape of the mouse cursor to one of the standard shapes provided by the system.
+//
+// SetCursorShape replaces the cursor image set by SetCursorImage.
+// CursorShapeDefault reverts the cursor to the default one.
+//
+// SetCursorShape can be called before Run.
+//
+// SetCursorShape does nothing on mobiles.
+//
+// This function is concurrent-safe.
+func SetCursorShape(shape CursorShape) {
+	ui.SetCursorShape(ui.CursorShape(shape))
+}
+
 // IsFullscreen returns a boolean value indicating whether
 // the current mode is fullscreen or not.
 //
@@ -222,6 +426,9 @@ func IsFullscreen() bool {
 // On desktops, Ebiten uses 'windowed' fullscreen mode, which doesn't change
 // your monitor's resolution.
 //
+// On desktops, the monitor to go fullscreen on can be specified by SetFullscreenMonitor.
+// Borderless fullscreen mode can be chosen by SetFullscreenBorderless.
+//
 // On browsers, the game screen is resized to fit with the body element (client) size.
 // Additionally, the game screen is automatically resized when the body element is resized.
 //
@@ -232,26 +439,480 @@ func SetFullscreen(fullscreen bool) {
 	ui.SetFullscreen(fullscreen)
 }
 
-// IsRunnableInBackground returns a boolean value indicating whether the game runs even in background.
+// MonitorIDs returns a slice indicating IDs of the connected monitors.
+//
+// The ID 0 represents the primary monitor.
+// IDs are indices of the monitor list the OS reports, and might change when a monitor is connected or disconnected.
+//
+// MonitorIDs returns an empty slice when Run is not called yet.
+//
+// MonitorIDs always returns an empty slice on browsers and mobiles.
 //
 // This function is concurrent-safe.
-func IsRunnableInBackground() bool {
+func MonitorIDs() []int {
+	return ui.MonitorIDs()
+}
+
+// A Monitor represents a connected monitor.
+type Monitor struct {
+	// ID is the monitor ID used by e.g. MonitorName and SetFullscreenMonitor.
+	ID int
+
+	// Name is the human-readable name of the monitor.
+	Name string
+
+	// Bounds is the area of the monitor in the virtual desktop.
+	// The unit is device-independent pixel.
+	Bounds image.Rectangle
+
+	// WorkArea is the area of the monitor where windows can be placed,
+	// excluding e.g. the taskbar, the menu bar or the Dock.
+	// The unit is device-independent pixel.
+	//
+	// When the work area is unknown, WorkArea is the same as Bounds.
+	WorkArea image.Rectangle
+
+	// DeviceScale is the device scale factor of the monitor.
+	DeviceScale float64
+}
+
+// Monitors returns the connected monitors.
+//
+// The order is the same as MonitorIDs: Monitors()[0] represents the primary monitor.
+//
+// Monitors returns an empty slice when Run is not called yet.
+//
+// Monitors always returns an empty slice on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func Monitors() []Monitor {
+	ms := ui.Monitors()
+	r := make([]Monitor, len(ms))
+	for i, m := range ms {
+		r[i] = Monitor(m)
+	}
+	return r
+}
+
+// SetMonitorsChangedCallback sets the function called when a monitor is connected or disconnected.
+//
+// As monitor IDs might change, use Monitors in f to get the new list of the monitors.
+// The monitor ID given to SetFullscreenMonitor might also represent another monitor after the change.
+// When the ID no longer represents a connected monitor, the primary monitor is used for fullscreen mode.
+//
+// f is called on the same goroutine as the game's update function, before the update function is called.
+//
+// If f is nil, no function is called.
+//
+// SetMonitorsChangedCallback does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetMonitorsChangedCallback(f func()) {
+	ui.SetMonitorsChangedCallback(f)
+}
+
+// MonitorName returns the human-readable name of the monitor (id).
+//
+// MonitorName returns an empty string when the monitor is not connected or Run is not called yet.
+//
+// MonitorName always returns an empty string on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func MonitorName(id int) string {
+	return ui.MonitorName(id)
+}
+
+// A VideoMode represents a video mode of a monitor.
+type VideoMode struct {
+	// Width and Height are the resolution in pixels.
+	Width  int
+	Height int
+
+	// RefreshRate is the refresh rate in Hz.
+	RefreshRate int
+}
+
+// CurrentMonitor returns the ID of the monitor where the game window is.
+//
+// On fullscreen mode, CurrentMonitor returns the monitor used for fullscreen mode.
+// Otherwise, CurrentMonitor returns the monitor containing the center of the window.
+//
+// CurrentMonitor returns 0 when Run is not called yet.
+//
+// CurrentMonitor always returns 0 on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func CurrentMonitor() int {
+	return ui.CurrentMonitor()
+}
+
+// MonitorRefreshRate returns the refresh rate in Hz of the monitor where the game window is.
+//
+// Note that the game logic is updated MaxTPS times a second regardless of the refresh rate.
+//
+// MonitorRefreshRate returns 0 when the refresh rate is unknown or Run is not called yet.
+//
+// MonitorRefreshRate always returns 0 on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func MonitorRefreshRate() int {
+	return ui.MonitorRefreshRate()
+}
+
+// MonitorVideoMode returns the current video mode of the monitor (id).
+//
+// MonitorVideoMode returns a zero VideoMode when the monitor is not connected or Run is not called yet.
+//
+// MonitorVideoMode always returns a zero VideoMode on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func MonitorVideoMode(id int) VideoMode {
+	return VideoMode(ui.MonitorVideoMode(id))
+}
+
+// MonitorVideoModes returns all the video modes the monitor (id) supports.
+//
+// The video modes are sorted in ascending order, first by the color depth, then by the resolution area
+// and then by the refresh rate.
+//
+// MonitorVideoModes returns an empty slice when the monitor is not connected or Run is not called yet.
+//
+// MonitorVideoModes always returns an empty slice on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func MonitorVideoModes(id int) []VideoMode {
+	ms := ui.MonitorVideoModes(id)
+	r := make([]VideoMode, len(ms))
+	for i, m := range ms {
+		r[i] = VideoMode(m)
+	}
+	return r
+}
+
+// FullscreenMonitor returns the ID of the monitor used for fullscreen mode.
+//
+// The initial value is 0, which represents the primary monitor.
+//
+// This function is concurrent-safe.
+func FullscreenMonitor() int {
+	return ui.FullscreenMonitor()
+}
+
+// SetFullscreenMonitor sets the monitor (id) used for fullscreen mode.
+//
+// If the game is already on fullscreen mode, the window moves to the given monitor immediately.
+// If the monitor is not connected, the primary monitor is used instead.
+//
+// SetFullscreenMonitor can be called before Run.
+//
+// SetFullscreenMonitor does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetFullscreenMonitor(id int) {
+	ui.SetFullscreenMonitor(id)
+}
+
+// IsFullscreenBorderless returns a boolean value indicating whether fullscreen mode is borderless.
+//
+// This function is concurrent-safe.
+func IsFullscreenBorderless() bool {
+	return ui.IsFullscreenBorderless()
+}
+
+// SetFullscreenBorderless sets whether fullscreen mode is borderless (so-called 'borderless windowed' mode).
+//
+// In borderless fullscreen mode, the window is not attached to the monitor exclusively.
+// Instead, the decoration is removed and the window covers the whole monitor.
+// This makes switching to other applications smoother.
+//
+// If the game is already on fullscreen mode, the new mode is applied immediately.
+//
+// SetFullscreenBorderless can be called before Run.
+//
+// SetFullscreenBorderless does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetFullscreenBorderless(borderless bool) {
+	ui.SetFullscreenBorderless(borderless)
+}
+
+// IsIntegerScalingEnabled returns a boolean value indicating whether the screen is scaled by integers in fullscreen mode.
+//
+// This function is concurrent-safe.
+func IsIntegerScalingEnabled() bool {
+	return ui.IsIntegerScalingEnabled()
+}
+
+// SetIntegerScalingEnabled sets whether the screen is scaled by integers in fullscreen mode.
+//
+// The initial value is false, and the screen is scaled to fit the monitor as much as possible.
+// With integer scaling, the scale is the largest integer in device pixels that fits the monitor,
+// and the rest of the monitor is filled with black (letterboxing).
+// All the pixels of the game screen then have the same size, which is suitable for pixel art.
+// If the monitor is smaller than the game screen, the screen is shrunk as it is.
+//
+// The scale given to Run or SetScreenScale in windowed mode is not affected.
+//
+// SetIntegerScalingEnabled can be called before Run and at any time while the game is running.
+//
+// SetIntegerScalingEnabled does nothing on mobiles.
+//
+// This function is concurrent-safe.
+func SetIntegerScalingEnabled(enabled bool) {
+	ui.SetIntegerScalingEnabled(enabled)
+}
+
+// IsVsyncEnabled returns a boolean value indicating whether the game uses vertical sync.
+//
+// IsVsyncEnabled always returns true on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func IsVsyncEnabled() bool {
+	return ui.IsVsyncEnabled()
+}
+
+// SetVsyncEnabled sets whether the game uses vertical sync.
+//
+// The initial value is true.
+// Without vertical sync, the screen is rendered as fast as possible,
+// while the game logic is still updated MaxTPS times per second.
+// This is useful e.g. for benchmarking or for reducing input latency.
+//
+// SetVsyncEnabled(true) is equivalent to SetFPSMode(FPSModeVsyncOn),
+// and SetVsyncEnabled(false) is equivalent to SetFPSMode(FPSModeVsyncOffMaximum).
+//
+// SetVsyncEnabled can be called before Run and at any time while the game is running.
+//
+// SetVsyncEnabled does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetVsyncEnabled(enabled bool) {
+	ui.SetVsyncEnabled(enabled)
+}
+
+// FPSMode returns the current FPS mode.
+//
+// FPSMode always returns FPSModeVsyncOn on mobiles.
+//
+// This function is concurrent-safe.
+func FPSMode() FPSModeType {
+	return FPSModeType(ui.GetFPSMode())
+}
+
+// SetFPSMode sets the FPS mode.
+//
+// The initial value is FPSModeVsyncOn.
+//
+// SetFPSMode can be called before Run and at any time while the game is running.
+//
+// On browsers, FPSModeVsyncOffMaximum works as FPSModeVsyncOn since rendering always follows the display.
+// SetFPSMode does nothing on mobiles.
+//
+// This function is concurrent-safe.
+func SetFPSMode(mode FPSModeType) {
+	ui.SetFPSMode(ui.FPSMode(mode))
+}
+
+// ScheduleFrame schedules a next frame when the current FPS mode is FPSModeVsyncOffMinimum.
+//
+// ScheduleFrame is useful when the game needs to be redrawn without any input,
+// e.g. when a background task finishes.
+// ScheduleFrame does nothing with the other FPS modes.
+//
+// ScheduleFrame does nothing on mobiles.
+//
+// This function is concurrent-safe.
+func ScheduleFrame() {
+	ui.ScheduleFrame()
+}
+
+// SetGammaCorrectionEnabled sets whether rendering is gamma-correct.
+//
+// The initial value is false.
+// With gamma correction, the pixels of images and the screen are regarded as colors in the sRGB color space:
+// they are decoded into the linear color space when read, blended and filtered in the linear color space,
+// and encoded into the sRGB color space when written.
+// This makes alpha blending, linear filtering and additive lights look physically correct.
+// The results of ColorM, vertex colors and shaders are regarded as linear colors.
+// Pixels given to ReplacePixels and read by At are in the sRGB color space as they are.
+// HDR images (see NewImageOptions.HDR) are always in the linear color space.
+//
+// As translucent pixels are stored with premultiplied alpha, their colors are approximations.
+//
+// SetGammaCorrectionEnabled must be called before Run. Calling it while the game is running has no effect.
+//
+// SetGammaCorrectionEnabled does nothing on browsers and mobiles.
+func SetGammaCorrectionEnabled(enabled bool) {
+	opengl.RequestSRGB(enabled)
+}
+
+// IsGammaCorrectionEnabled reports whether rendering is gamma-correct.
+// This can be false even after SetGammaCorrectionEnabled(true) when the environment doesn't support sRGB framebuffers.
+//
+// IsGammaCorrectionEnabled can't be called before the main loop (ebiten.Run) starts.
+func IsGammaCorrectionEnabled() bool {
+	return opengl.GetContext().IsSRGBEnabled()
+}
+
+// DeviceScaleFactor returns the device scale factor of the monitor where the game window is.
+//
+// The device scale factor is the ratio of physical pixels to device-independent pixels,
+// e.g. 2 on Retina displays.
+// Ebiten scales the screen by this factor automatically.
+// The screen scale given to Run and SetScreenScale is in device-independent pixels.
+//
+// This function is concurrent-safe.
+func DeviceScaleFactor() float64 {
+	return ui.DeviceScaleFactor()
+}
+
+// SetDeviceScaleChangedCallback sets the function called when the device scale factor is changed
+// e.g. when the game window moves to another monitor with a different DPI.
+//
+// Before f is called, the game window is resized so that the window keeps the same size
+// in device-independent pixels.
+//
+// f is called on the same goroutine as the game's update function, before the update function is called.
+//
+// If f is nil, no function is called.
+//
+// SetDeviceScaleChangedCallback does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetDeviceScaleChangedCallback(f func(scale float64)) {
+	ui.SetDeviceScaleChangedCallback(f)
+}
+
+// IsFocused returns a boolean value indicating whether the game window has the input focus.
+//
+// IsFocused returns false when Run is not called yet.
+//
+// IsFocused always returns true on mobiles.
+//
+// This function is concurrent-safe.
+func IsFocused() bool {
+	return ui.IsFocused()
+}
+
+// SetFocusChangedCallback sets the function called when the game window gains or loses the input focus.
+//
+// f is called on the same goroutine as the game's update function, before the update function is called.
+// When the game is not runnable in background, f(false) is called before the game stops.
+// This is useful e.g. to pause music or show a pause menu.
+//
+// If f is nil, no function is called.
+//
+// SetFocusChangedCallback does nothing on mobiles.
+//
+// This function is concurrent-safe.
+func SetFocusChangedCallback(f func(focused bool)) {
+	ui.SetFocusChangedCallback(f)
+}
+
+// IsWindowClosingHandled returns a boolean value indicating whether the game handles closing the window by itself.
+//
+// This function is concurrent-safe.
+func IsWindowClosingHandled() bool {
+	return ui.IsWindowClosingHandled()
+}
+
+// SetWindowClosingHandled sets whether the game handles closing the window by itself.
+//
+// The initial value is false, and Run returns without an error when the user tries to close the window
+// e.g. by clicking the close button.
+//
+// If handled is true, Run doesn't return even when the user tries to close the window.
+// Instead, IsWindowBeingClosed returns true at the next frame,
+// and the game can e.g. show a dialog like "save before quitting?".
+// To quit the game, return an error from the game's update function.
+//
+// SetWindowClosingHandled does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowClosingHandled(handled bool) {
+	ui.SetWindowClosingHandled(handled)
+}
+
+// IsWindowBeingClosed returns a boolean value indicating whether the user tried to close the window
+// at the current frame.
+//
+// IsWindowBeingClosed can return true only when SetWindowClosingHandled(true) is called.
+//
+// IsWindowBeingClosed always returns false on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func IsWindowBeingClosed() bool {
+	return ui.IsWindowBeingClosed()
+}
+
+// IsRunnableOnUnfocused returns a boolean value indicating whether the game runs even when the window is unfocused.
+//
+// This function is concurrent-safe.
+func IsRunnableOnUnfocused() bool {
 	return ui.IsRunnableInBackground()
 }
 
-// SetRunnableInBackground sets the state if the game runs even in background.
+// SetRunnableOnUnfocused sets the state if the game runs even when the window is unfocused.
 //
-// If the given value is true, the game runs in background e.g. when losing focus.
-// The initial state is false.
+// If the given value is true, the game keeps updating and rendering e.g. when losing focus.
+// The initial state is false, and the game stops until the window gets the focus again.
+// The state is useful e.g. for music players, streaming setups or servers with a viewport.
 //
 // Known issue: On browsers, even if the state is on, the game doesn't run in background tabs.
 // This is because browsers throttles background tabs not to often update.
 //
-// SetRunnableInBackground does nothing on mobiles so far.
+// SetRunnableOnUnfocused does nothing on mobiles so far.
 //
 // This function is concurrent-safe.
+func SetRunnableOnUnfocused(runnableOnUnfocused bool) {
+	ui.SetRunnableInBackground(runnableOnUnfocused)
+}
+
+// IsRunnableInBackground returns a boolean value indicating whether the game runs even in background.
+//
+// Deprecated (as of 1.6.0-alpha): Use IsRunnableOnUnfocused instead.
+func IsRunnableInBackground() bool {
+	return IsRunnableOnUnfocused()
+}
+
+// SetRunnableInBackground sets the state if the game runs even in background.
+//
+// Deprecated (as of 1.6.0-alpha): Use SetRunnableOnUnfocused instead.
 func SetRunnableInBackground(runnableInBackground bool) {
-	ui.SetRunnableInBackground(runnableInBackground)
+	SetRunnableOnUnfocused(runnableInBackground)
+}
+
+// NativeWindowHandle returns the platform-specific handle of the game window.
+//
+// The returned value is HWND on Windows, NSWindow* on macOS and X11's Window on Linux and FreeBSD.
+// This is useful to integrate with native libraries e.g. to show native dialogs with the game window as their parent.
+//
+// Don't change the window's state via the handle in a way that conflicts with Ebiten's functions,
+// or the behavior is undefined.
+//
+// NativeWindowHandle returns 0 when Run is not called yet.
+//
+// NativeWindowHandle always returns 0 on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func NativeWindowHandle() uintptr {
+	return ui.NativeWindowHandle()
+}
+
+// SetWindowTitle sets the title of the game window.
+//
+// The initial title is the one given to Run.
+// With RunGame, the title set before RunGame is used as the initial title, and the default title is "Ebiten".
+// Otherwise, SetWindowTitle does nothing when Run is not called yet.
+//
+// On browsers, SetWindowTitle sets the document's title.
+//
+// SetWindowTitle does nothing on mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowTitle(title string) {
+	theWindow.setTitle(title)
+	ui.SetWindowTitle(title)
 }
 
 // SetWindowIcon sets the icon of the game window.
@@ -277,3 +938,289 @@ func SetRunnableInBackground(runnableInBackground bool) {
 func SetWindowIcon(iconImages []image.Image) {
 	ui.SetWindowIcon(iconImages)
 }
+
+// IsWindowDecorated returns a boolean value indicating whether the game window has decoration
+// like a title bar and borders.
+//
+// IsWindowDecorated always returns false on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func IsWindowDecorated() bool {
+	return ui.IsWindowDecorated()
+}
+
+// SetWindowDecorated sets whether the game window has decoration like a title bar and borders.
+//
+// The initial value is true.
+// An undecorated (frameless) window is useful e.g. for splash screens or kiosk applications.
+//
+// SetWindowDecorated can be called before Run and at any time while the game is running.
+// When calling SetWindowDecorated before Run, the window is created without decoration.
+// When calling SetWindowDecorated on fullscreen mode, the change is applied after fullscreen mode is turned off.
+//
+// SetWindowDecorated does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowDecorated(decorated bool) {
+	ui.SetWindowDecorated(decorated)
+}
+
+// IsWindowFloating returns a boolean value indicating whether the game window is always on top of other windows.
+//
+// IsWindowFloating always returns false on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func IsWindowFloating() bool {
+	return ui.IsWindowFloating()
+}
+
+// SetWindowFloating sets whether the game window is always on top of other windows.
+//
+// The initial value is false.
+//
+// SetWindowFloating can be called before Run and at any time while the game is running.
+// When calling SetWindowFloating on fullscreen mode, the change is applied after fullscreen mode is turned off.
+//
+// On Linux, this depends on the window manager supporting _NET_WM_STATE_ABOVE.
+//
+// SetWindowFloating does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowFloating(floating bool) {
+	ui.SetWindowFloating(floating)
+}
+
+// IsWindowMinimized returns a boolean value indicating whether the game window is minimized (iconified).
+//
+// IsWindowMinimized returns false when Run is not called yet.
+//
+// IsWindowMinimized always returns false on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func IsWindowMinimized() bool {
+	return ui.IsWindowMinimized()
+}
+
+// IsWindowMaximized returns a boolean value indicating whether the game window is maximized.
+//
+// When Run is not called yet, IsWindowMaximized returns the value set by SetWindowMaximized.
+//
+// IsWindowMaximized always returns false on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func IsWindowMaximized() bool {
+	return ui.IsWindowMaximized()
+}
+
+// MinimizeWindow minimizes (iconifies) the game window.
+//
+// While the window is minimized, Ebiten skips presenting the screen.
+// The game function is still called only when SetRunnableOnUnfocused is set to true.
+//
+// MinimizeWindow does nothing when Run is not called yet.
+//
+// MinimizeWindow does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func MinimizeWindow() {
+	ui.MinimizeWindow()
+}
+
+// MaximizeWindow maximizes the game window.
+//
+// MaximizeWindow does nothing on fullscreen mode or when Run is not called yet.
+// Depending on the platform, MaximizeWindow might not work since the game window is not resizable.
+//
+// MaximizeWindow does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func MaximizeWindow() {
+	ui.MaximizeWindow()
+}
+
+// SetWindowMaximized sets whether the game window is maximized.
+//
+// When SetWindowMaximized(true) is called before Run, the game window opens maximized
+// instead of the size of the screen size multiplied by the scale.
+// This is ignored when SetFullscreen(true) is also called before Run.
+// When Run is already called, SetWindowMaximized works as MaximizeWindow or RestoreWindow.
+//
+// Depending on the platform, SetWindowMaximized might not work since the game window is not resizable.
+//
+// SetWindowMaximized does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowMaximized(maximized bool) {
+	ui.SetWindowMaximized(maximized)
+}
+
+// RestoreWindow restores the game window from the minimized or maximized state.
+//
+// RestoreWindow does nothing when Run is not called yet.
+//
+// RestoreWindow does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func RestoreWindow() {
+	ui.RestoreWindow()
+}
+
+// WindowSizeLimits returns the limits of the game window size.
+//
+// The unit is device-independent pixel.
+// A negative value means the limit is not set.
+//
+// WindowSizeLimits always returns (-1, -1, -1, -1) on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func WindowSizeLimits() (minw, minh, maxw, maxh int) {
+	return ui.WindowSizeLimits()
+}
+
+// SetWindowSizeLimits sets the limits of the game window size.
+//
+// The unit is device-independent pixel.
+// A negative value means the limit is not set.
+// The initial values are all -1.
+//
+// The limits are for the window size changed by the user or the OS e.g. by maximizing.
+// Use the limits consistent with the size given to SetScreenSize and SetScreenScale,
+// or the window size might be limited in a platform-specific way.
+//
+// SetWindowSizeLimits can be called before Run.
+//
+// SetWindowSizeLimits does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowSizeLimits(minw, minh, maxw, maxh int) {
+	ui.SetWindowSizeLimits(minw, minh, maxw, maxh)
+}
+
+// WindowAspectRatio returns the aspect ratio the game window keeps.
+//
+// A non-positive value means the aspect ratio is not set.
+//
+// WindowAspectRatio always returns (-1, -1) on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func WindowAspectRatio() (numer, denom int) {
+	return ui.WindowAspectRatio()
+}
+
+// SetWindowAspectRatio sets the aspect ratio the game window keeps, e.g. (16, 9).
+//
+// A non-positive value removes the constraint.
+// The initial values are both -1.
+//
+// As well as SetWindowSizeLimits, the aspect ratio is for the window size changed by the user or the OS,
+// so that the game screen keeps its proportions without letterboxing.
+// The window size given by SetScreenSize and SetScreenScale is not affected.
+//
+// SetWindowAspectRatio can be called before Run.
+//
+// SetWindowAspectRatio does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowAspectRatio(numer, denom int) {
+	ui.SetWindowAspectRatio(numer, denom)
+}
+
+// IsScreenTransparent returns a boolean value indicating whether the game screen is transparent.
+//
+// This function is concurrent-safe.
+func IsScreenTransparent() bool {
+	return ui.IsScreenTransparent()
+}
+
+// SetScreenTransparent sets whether the game screen is transparent.
+//
+// When the screen is transparent, the alpha values rendered on the screen image are
+// reflected to the game window, and the desktop behind the window is visible
+// where the screen is not filled with opaque colors.
+// This is useful e.g. for overlay-style applications with SetWindowDecorated(false).
+//
+// The initial value is false.
+//
+// SetScreenTransparent works only before Run. After Run is called, SetScreenTransparent does nothing.
+//
+// SetScreenTransparent works on Windows (with desktop composition enabled) and macOS.
+// SetScreenTransparent does nothing on Linux, browsers and mobiles so far.
+//
+// This function is concurrent-safe.
+func SetScreenTransparent(transparent bool) {
+	ui.SetScreenTransparent(transparent)
+}
+
+// WindowOpacity returns the opacity of the whole game window.
+//
+// WindowOpacity always returns 1 on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func WindowOpacity() float64 {
+	return ui.WindowOpacity()
+}
+
+// SetWindowOpacity sets the opacity of the whole game window.
+//
+// The given value is clamped to the range [0, 1].
+// The initial value is 1.
+//
+// SetWindowOpacity can be called before Run and at any time while the game is running.
+//
+// On Linux, this depends on the window manager supporting _NET_WM_WINDOW_OPACITY.
+//
+// SetWindowOpacity does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowOpacity(opacity float64) {
+	ui.SetWindowOpacity(opacity)
+}
+
+// WindowPosition returns the position of the game window's upper-left corner.
+//
+// The unit is device-independent pixel.
+//
+// On fullscreen mode, WindowPosition returns the position where the window will be placed
+// when fullscreen mode is turned off.
+//
+// WindowPosition returns (0, 0) when Run is not called yet.
+//
+// WindowPosition always returns (0, 0) on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func WindowPosition() (x, y int) {
+	return ui.WindowPosition()
+}
+
+// SetWindowPosition moves the game window so that its upper-left corner is at (x, y).
+//
+// The unit is device-independent pixel.
+//
+// On fullscreen mode, the given position is used when fullscreen mode is turned off.
+//
+// SetWindowPosition does nothing when Run is not called yet.
+//
+// SetWindowPosition does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetWindowPosition(x, y int) {
+	ui.SetWindowPosition(x, y)
+}
+
+// SetInitialWindowPosition sets the position of the game window's upper-left corner
+// where the window is placed when Run is called.
+//
+// The unit is device-independent pixel.
+//
+// Without SetInitialWindowPosition, the game window is placed around the center of the primary monitor.
+// This is useful e.g. to restore the window position saved by WindowPosition at the previous launch.
+// The position is not adjusted even when it is out of the visible area of monitors.
+//
+// SetInitialWindowPosition does nothing when Run is already called. Use SetWindowPosition instead.
+//
+// SetInitialWindowPosition does nothing on browsers and mobiles.
+//
+// This function is concurrent-safe.
+func SetInitialWindowPosition(x, y int) {
+	ui.SetInitialWindowPosition(x, y)
+}
@@ -0,0 +1,38 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"errors"
+	"image"
+)
+
+// Screenshot returns a copy of the current content of the screen.
+//
+// The returned image has the same size as the screen image passed to the game's update function,
+// and doesn't include the screen scale nor the device scale.
+//
+// As the screen is cleared at the beginning of every frame, call Screenshot
+// at the end of the update function after rendering the screen.
+// When IsRunningSlowly returns true and the screen is not rendered, the returned image might be empty.
+//
+// Screenshot must be called from the game's update function.
+func Screenshot() (image.Image, error) {
+	g, ok := theGraphicsContext.Load().(*graphicsContext)
+	if !ok {
+		return nil, errors.New("ebiten: Run is not called yet")
+	}
+	return g.screenshot()
+}
@@ -0,0 +1,347 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"fmt"
+	"image"
+	"runtime"
+
+	"github.com/hajimehoshi/ebiten/internal/math"
+	"github.com/hajimehoshi/ebiten/internal/opengl"
+	"github.com/hajimehoshi/ebiten/internal/restorable"
+	"github.com/hajimehoshi/ebiten/internal/shader"
+)
+
+// Shader represents a custom fragment shader.
+//
+// Note that this API is experimental.
+type Shader struct {
+	shader *restorable.Shader
+}
+
+// NewShader compiles a shader program and returns a new shader.
+//
+// The shader program is written in the Ebiten shading language, a subset of Go.
+// A program must have a function Fragment, which returns the color of the fragment as alpha-premultiplied RGBA:
+//
+//     package main
+//
+//     // Uniform variables are declared as package-level variables with exported names.
+//     var Time float
+//
+//     func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+//         clr := imageSrc0At(texCoord)
+//         clr.rgb *= (sin(Time) + 1) / 2
+//         return clr * color
+//     }
+//
+// position is the position of the fragment on the destination image in pixels.
+// texCoord is the texture coordinate of the source image.
+// color is the color scaling values of the vertices.
+//
+// The available types are bool, int, float, vec2, vec3, vec4, mat2, mat3 and mat4.
+// The built-in functions are the same as GLSL's, plus imageSrc0At(texCoord vec2) vec4 to get the source image color
+// (imageSrc1At, imageSrc2At and imageSrc3At for the other source images),
+// imageSrcTextureSize() vec2 to get the texture size of the source image and discard() to discard the fragment.
+//
+// If the program is invalid, NewShader returns an error.
+//
+// Note that this API is experimental.
+func NewShader(src []byte) (*Shader, error) {
+	p, err := shader.Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	s := &Shader{
+		shader: restorable.NewShader(p),
+	}
+	runtime.SetFinalizer(s, (*Shader).Dispose)
+	return s, nil
+}
+
+// Dispose disposes the shader.
+//
+// When the shader is disposed, Dispose does nothing.
+//
+// Dispose always returns nil.
+func (s *Shader) Dispose() error {
+	if s.shader == nil {
+		return nil
+	}
+	s.shader.Dispose()
+	s.shader = nil
+	runtime.SetFinalizer(s, nil)
+	return nil
+}
+
+// uniforms converts the given uniform values into the values in the declared order.
+func (s *Shader) uniforms(values map[string]interface{}) [][]float32 {
+	p := s.shader.Program()
+	names := map[string]struct{}{}
+	r := make([][]float32, len(p.Uniforms))
+	for i, u := range p.Uniforms {
+		names[u.Name] = struct{}{}
+		n := u.Type.FloatsNum()
+		r[i] = make([]float32, n)
+		v, ok := values[u.Name]
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case float32:
+			if n == 1 {
+				r[i][0] = v
+				continue
+			}
+		case float64:
+			if n == 1 {
+				r[i][0] = float32(v)
+				continue
+			}
+		case int:
+			if n == 1 {
+				r[i][0] = float32(v)
+				continue
+			}
+		case []float32:
+			if len(v) == n {
+				copy(r[i], v)
+				continue
+			}
+		case []float64:
+			if len(v) == n {
+				for j := range v {
+					r[i][j] = float32(v[j])
+				}
+				continue
+			}
+		}
+		panic(fmt.Sprintf("ebiten: the uniform variable %s (%s) can't be set with %T (%v)", u.Name, u.Type, v, v))
+	}
+	for name := range values {
+		if _, ok := names[name]; !ok {
+			panic(fmt.Sprintf("ebiten: the uniform variable %s is not declared in the shader", name))
+		}
+	}
+	return r
+}
+
+// DrawTrianglesShaderOptions represents options to render triangles with a custom shader.
+//
+// Note that this API is experimental.
+type DrawTrianglesShaderOptions struct {
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	CompositeMode CompositeMode
+
+	// Blend is a custom blending function to draw.
+	// If Blend is not nil, CompositeMode is ignored.
+	Blend *Blend
+
+	// Filter is a filter to sample the images.
+	// The default (zero) value is FilterDefault, which uses the filter specified when Images[0] is created.
+	Filter Filter
+
+	// Uniforms is a set of uniform variables for the shader.
+	// The keys are the names of the uniform variables.
+	//
+	// A float variable can be set with a float32, float64 or int value.
+	// A vector or matrix variable can be set with a []float32 or []float64 value of the same number of elements.
+	// Matrices are in column-major order.
+	// Variables that are not specified are zero.
+	Uniforms map[string]interface{}
+
+	// Images is a set of the source images.
+	// Images[0] can be read with imageSrc0At in the shader, Images[1] with imageSrc1At, and so on.
+	// Each image can be nil.
+	//
+	// imageSrc1At and later take the same texel position as imageSrc0At's, i.e., texCoord reads
+	// the same pixel position in all the images.
+	// All the images must have the same size, and Images[0] must not be nil when the other images are used.
+	Images [4]*Image
+}
+
+// DrawTrianglesShader draws triangles with the specified vertices and their indices with the shader.
+//
+// SrcX and SrcY of the vertices are in pixels of Images[0].
+// If Images[0] is nil, SrcX and SrcY are passed to the shader as they are.
+// ColorR/ColorG/ColorB/ColorA are passed to the shader as color without any processing.
+// Note that the images might be in an atlas, and the texels outside of the images can belong to other images.
+//
+// The rules of the vertices and the indices are the same as DrawTriangles'.
+//
+// If a uniform variable can't be set with the specified value, or the name is not declared in the shader,
+// DrawTrianglesShader panics.
+//
+// When the image i is disposed, DrawTrianglesShader does nothing.
+//
+// When any of the source images is as same as i or a sub-image of i, DrawTrianglesShader panics.
+// When the source images have different sizes, DrawTrianglesShader panics.
+//
+// When the image i is a sub-image, the triangles are clipped to the bounds of i.
+// When the image i is a viewport, the destination positions are transformed by the view (see Viewport).
+//
+// Note that this API is experimental.
+//
+// DrawTrianglesShader always returns nil.
+func (i *Image) DrawTrianglesShader(vertices []Vertex, indices []uint16, shader *Shader, options *DrawTrianglesShaderOptions) error {
+	dst, region := i.renderTarget()
+	if dst == nil {
+		return nil
+	}
+	if shader.shader == nil {
+		panic("ebiten: the shader is already disposed")
+	}
+	checkTriangles(vertices, indices)
+	if options == nil {
+		options = &DrawTrianglesShaderOptions{}
+	}
+	var srcs [restorable.ShaderImageNum]*restorable.Image
+	var offsets [restorable.ShaderImageNum]image.Point
+	for idx, img := range options.Images {
+		if img == nil {
+			continue
+		}
+		if i.sameTexture(img) {
+			panic("ebiten: Image.DrawTrianglesShader: source images must be different from the receiver")
+		}
+		if idx > 0 {
+			if options.Images[0] == nil {
+				panic("ebiten: Image.DrawTrianglesShader: Images[0] must not be nil when the other images are used")
+			}
+			if img.Bounds().Size() != options.Images[0].Bounds().Size() {
+				panic("ebiten: Image.DrawTrianglesShader: all the source images must have the same size")
+			}
+		}
+		srcs[idx] = img.restorableImage()
+		if srcs[idx] == nil {
+			return nil
+		}
+		// The shader converts a texel position of Images[0] into the other images' with the positions
+		// of the images' upper-left corners in their textures.
+		offsets[idx] = img.offset().Add(img.Bounds().Min)
+	}
+
+	wf, hf := float32(1), float32(1)
+	filter := glFilter(options.Filter)
+	var ox, oy float32
+	if img := options.Images[0]; img != nil {
+		filter = drawFilter(img, options.Filter)
+		w, h := srcs[0].Size()
+		wf = float32(math.NextPowerOf2Int(w))
+		hf = float32(math.NextPowerOf2Int(h))
+		o := img.offset()
+		ox, oy = float32(o.X), float32(o.Y)
+	}
+	for _, img := range options.Images[1:] {
+		// Fall back to linear filtering when any of the images can't have mipmaps.
+		if img != nil && filter == opengl.Anisotropic && drawFilter(img, FilterAnisotropic) != opengl.Anisotropic {
+			filter = opengl.Linear
+		}
+	}
+	vs := make([]float32, 0, len(vertices)*restorable.VertexSizeInBytes()/4)
+	for _, v := range vertices {
+		dx, dy := i.viewPosition(v.DstX, v.DstY)
+		vs = append(vs, dx, dy, (v.SrcX+ox)/wf, (v.SrcY+oy)/hf, v.ColorR, v.ColorG, v.ColorB, v.ColorA)
+	}
+	dst.DrawShader(srcs, offsets, vs, indices, shader.shader, shader.uniforms(options.Uniforms), glBlend(options.CompositeMode, options.Blend), filter, region)
+	return nil
+}
+
+// DrawRectShaderOptions represents options to render a rectangle with a custom shader.
+//
+// Note that this API is experimental.
+type DrawRectShaderOptions struct {
+	// GeoM is a geometry matrix to draw.
+	// The default (zero) value is identity, which draws the rectangle at (0, 0).
+	GeoM GeoM
+
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	CompositeMode CompositeMode
+
+	// Blend is a custom blending function to draw.
+	// If Blend is not nil, CompositeMode is ignored.
+	Blend *Blend
+
+	// Filter is a filter to sample the images.
+	// The default (zero) value is FilterDefault, which uses the filter specified when Images[0] is created.
+	Filter Filter
+
+	// Uniforms is a set of uniform variables for the shader.
+	// See the document of DrawTrianglesShaderOptions.Uniforms.
+	Uniforms map[string]interface{}
+
+	// Images is a set of the source images.
+	// All the images must have the same size as the rectangle.
+	// See the document of DrawTrianglesShaderOptions.Images.
+	Images [4]*Image
+}
+
+// DrawRectShader draws a rectangle with the specified width and height with the shader.
+// This is useful for effects on the whole image, like post effects on the screen.
+//
+// texCoord in the shader is the position in pixels of Images[0] converted to the texture coordinate.
+// If Images[0] is nil, texCoord is the position in pixels in the rectangle.
+// color in the shader is always (1, 1, 1, 1).
+//
+// When any of the source images doesn't have the same size as the rectangle, DrawRectShader panics.
+// The other rules are the same as DrawTrianglesShader's.
+//
+// Note that this API is experimental.
+//
+// DrawRectShader always returns nil.
+func (i *Image) DrawRectShader(width, height int, shader *Shader, options *DrawRectShaderOptions) error {
+	if options == nil {
+		options = &DrawRectShaderOptions{}
+	}
+	var sx, sy float32
+	for _, img := range options.Images {
+		if img == nil {
+			continue
+		}
+		if w, h := img.Size(); w != width || h != height {
+			panic("ebiten: Image.DrawRectShader: all the source images must have the same size as the rectangle")
+		}
+	}
+	if img := options.Images[0]; img != nil {
+		b := img.Bounds()
+		sx, sy = float32(b.Min.X), float32(b.Min.Y)
+	}
+
+	vs := make([]Vertex, 4)
+	for idx := range vs {
+		x, y := float64((idx%2)*width), float64((idx/2)*height)
+		dx, dy := options.GeoM.Apply(x, y)
+		vs[idx] = Vertex{
+			DstX:   float32(dx),
+			DstY:   float32(dy),
+			SrcX:   sx + float32(x),
+			SrcY:   sy + float32(y),
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		}
+	}
+	return i.DrawTrianglesShader(vs, quadIndices, shader, &DrawTrianglesShaderOptions{
+		CompositeMode: options.CompositeMode,
+		Blend:         options.Blend,
+		Filter:        options.Filter,
+		Uniforms:      options.Uniforms,
+		Images:        options.Images,
+	})
+}
@@ -0,0 +1,189 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spritebatch offers a builder to draw many sprites of one image with few draw calls.
+//
+// DrawImage already batches successive calls with the same conditions, but each call has its own
+// overhead like option handling and matrix calculation.
+// A Batch accumulates the vertices of the sprites and draws them with DrawTriangles at once,
+// which is suitable for thousands of sprites like bullets and particles.
+//
+// Note: This package is experimental and API might be changed.
+package spritebatch
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// maxQuadsNum is the maximum number of sprites in one call of DrawTriangles.
+// The number of vertices must be within the range of uint16 indices.
+const maxQuadsNum = (1 << 16) / 4
+
+// Sprite represents a sprite to be added to a Batch.
+type Sprite struct {
+	// SourceRect is the region of the source image to draw.
+	// The default (zero) value means the whole source image.
+	SourceRect image.Rectangle
+
+	// X and Y are the position of the origin on the destination image.
+	X float64
+	Y float64
+
+	// OriginX and OriginY are the origin of the scaling and the rotation
+	// in pixels relative to the left-upper corner of SourceRect.
+	OriginX float64
+	OriginY float64
+
+	// ScaleX and ScaleY are the scaling factors.
+	// The default (zero) values mean 1.
+	ScaleX float64
+	ScaleY float64
+
+	// Rotation is the rotation angle in radians, which is clockwise on the screen.
+	Rotation float64
+
+	// Color is the color to multiply the source image's colors by.
+	// The default (nil) value is white, which doesn't change the colors.
+	Color color.Color
+}
+
+// Batch accumulates sprites of one source image.
+//
+// A Batch can be reused with Reset to avoid allocations every frame.
+type Batch struct {
+	src      *ebiten.Image
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+// New returns a new empty Batch for the source image src.
+func New(src *ebiten.Image) *Batch {
+	return &Batch{
+		src: src,
+	}
+}
+
+// Len returns the number of the sprites in the batch.
+func (b *Batch) Len() int {
+	return len(b.vertices) / 4
+}
+
+// Reset removes all the sprites from the batch.
+// The allocated memory is kept for reuse.
+func (b *Batch) Reset() {
+	b.vertices = b.vertices[:0]
+	b.indices = b.indices[:0]
+}
+
+// Add adds a sprite to the batch.
+func (b *Batch) Add(sprite *Sprite) {
+	r := sprite.SourceRect
+	if r.Empty() {
+		r = b.src.Bounds()
+	}
+
+	sx, sy := sprite.ScaleX, sprite.ScaleY
+	if sx == 0 {
+		sx = 1
+	}
+	if sy == 0 {
+		sy = 1
+	}
+	sin, cos := math.Sincos(sprite.Rotation)
+
+	cr, cg, cb, ca := float32(1), float32(1), float32(1), float32(1)
+	if sprite.Color != nil {
+		red, green, blue, alpha := sprite.Color.RGBA()
+		if alpha == 0 {
+			return
+		}
+		// Vertex colors scale the non-premultiplied colors of the source.
+		cr = float32(red) / float32(alpha)
+		cg = float32(green) / float32(alpha)
+		cb = float32(blue) / float32(alpha)
+		ca = float32(alpha) / 0xffff
+	}
+
+	w, h := float64(r.Dx()), float64(r.Dy())
+	n := uint16(len(b.vertices) % (4 * maxQuadsNum))
+	for _, p := range []struct {
+		x  float64
+		y  float64
+		sx int
+		sy int
+	}{
+		{0, 0, r.Min.X, r.Min.Y},
+		{w, 0, r.Max.X, r.Min.Y},
+		{0, h, r.Min.X, r.Max.Y},
+		{w, h, r.Max.X, r.Max.Y},
+	} {
+		x := (p.x - sprite.OriginX) * sx
+		y := (p.y - sprite.OriginY) * sy
+		b.vertices = append(b.vertices, ebiten.Vertex{
+			DstX:   float32(x*cos - y*sin + sprite.X),
+			DstY:   float32(x*sin + y*cos + sprite.Y),
+			SrcX:   float32(p.sx),
+			SrcY:   float32(p.sy),
+			ColorR: cr,
+			ColorG: cg,
+			ColorB: cb,
+			ColorA: ca,
+		})
+	}
+	b.indices = append(b.indices, n, n+1, n+2, n+1, n+2, n+3)
+}
+
+// DrawOptions represents options to draw a batch.
+type DrawOptions struct {
+	// ColorM is a color matrix to draw.
+	// The default (zero) value is identity, which doesn't change any color.
+	// ColorM is applied before the sprites' colors are applied.
+	ColorM ebiten.ColorM
+
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	CompositeMode ebiten.CompositeMode
+
+	// Filter is a filter to sample the source image.
+	// The default (zero) value is FilterDefault, which uses the filter specified when the source image is created.
+	Filter ebiten.Filter
+}
+
+// Draw draws all the sprites in the batch on dst in the order they were added.
+//
+// The sprites are drawn with one call of DrawTriangles for every 16384 sprites.
+// Draw doesn't remove the sprites from the batch: call Reset to start the next batch.
+//
+// op can be nil, which means the default options.
+func (b *Batch) Draw(dst *ebiten.Image, op *DrawOptions) {
+	if op == nil {
+		op = &DrawOptions{}
+	}
+	top := &ebiten.DrawTrianglesOptions{
+		ColorM:        op.ColorM,
+		CompositeMode: op.CompositeMode,
+		Filter:        op.Filter,
+	}
+	for i := 0; i < b.Len(); i += maxQuadsNum {
+		n := b.Len() - i
+		if n > maxQuadsNum {
+			n = maxQuadsNum
+		}
+		dst.DrawTriangles(b.vertices[4*i:4*(i+n)], b.indices[6*i:6*(i+n)], b.src, top)
+	}
+}
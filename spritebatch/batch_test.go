@@ -0,0 +1,141 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spritebatch_test
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/spritebatch"
+)
+
+func TestMain(m *testing.M) {
+	code := 0
+	// Run an Ebiten process so that (*Image).At is available.
+	regularTermination := errors.New("regular termination")
+	f := func(screen *ebiten.Image) error {
+		code = m.Run()
+		return regularTermination
+	}
+	if err := ebiten.Run(f, 320, 240, 1, "Test"); err != nil && err != regularTermination {
+		panic(err)
+	}
+	os.Exit(code)
+}
+
+func TestBatch(t *testing.T) {
+	src, _ := ebiten.NewImage(4, 2, ebiten.FilterNearest)
+	pix := make([]uint8, 4*4*2)
+	for i := 0; i < 4*2; i++ {
+		if i%4 < 2 {
+			// The left half is red.
+			pix[4*i] = 0xff
+		} else {
+			// The right half is green.
+			pix[4*i+1] = 0xff
+		}
+		pix[4*i+3] = 0xff
+	}
+	src.ReplacePixels(pix)
+
+	b := New(src)
+	b.Add(&Sprite{
+		SourceRect: image.Rect(0, 0, 2, 2),
+		X:          1,
+		Y:          1,
+	})
+	// The green part rotated by 90 degrees around its center and scaled by 2.
+	b.Add(&Sprite{
+		SourceRect: image.Rect(2, 0, 4, 2),
+		X:          8,
+		Y:          8,
+		OriginX:    1,
+		OriginY:    1,
+		ScaleX:     2,
+		ScaleY:     2,
+		Rotation:   math.Pi / 2,
+		Color:      color.RGBA{0, 0x80, 0, 0x80},
+	})
+	if got, want := b.Len(), 2; got != want {
+		t.Errorf("Len(): got: %d, want: %d", got, want)
+	}
+
+	dst, _ := ebiten.NewImage(16, 16, ebiten.FilterNearest)
+	b.Draw(dst, nil)
+	cases := []struct {
+		X     int
+		Y     int
+		Color color.RGBA
+	}{
+		{0, 0, color.RGBA{}},
+		{1, 1, color.RGBA{0xff, 0, 0, 0xff}},
+		{2, 2, color.RGBA{0xff, 0, 0, 0xff}},
+		{3, 3, color.RGBA{}},
+		{6, 6, color.RGBA{0, 0x80, 0, 0x80}},
+		{9, 9, color.RGBA{0, 0x80, 0, 0x80}},
+		{10, 10, color.RGBA{}},
+	}
+	for _, c := range cases {
+		if got := dst.At(c.X, c.Y); got != c.Color {
+			t.Errorf("dst.At(%d, %d): got: %v, want: %v", c.X, c.Y, got, c.Color)
+		}
+	}
+
+	b.Reset()
+	if got, want := b.Len(), 0; got != want {
+		t.Errorf("Len() after Reset: got: %d, want: %d", got, want)
+	}
+}
+
+const benchmarkSpritesNum = 10000
+
+func BenchmarkBatch(b *testing.B) {
+	src, _ := ebiten.NewImage(16, 16, ebiten.FilterNearest)
+	dst, _ := ebiten.NewImage(256, 256, ebiten.FilterNearest)
+	batch := New(src)
+	for i := 0; i < b.N; i++ {
+		batch.Reset()
+		for j := 0; j < benchmarkSpritesNum; j++ {
+			batch.Add(&Sprite{
+				X:        float64(j % 256),
+				Y:        float64(j / 256),
+				Rotation: float64(j),
+			})
+		}
+		batch.Draw(dst, nil)
+		// Read a pixel to wait for the GPU.
+		dst.At(0, 0)
+	}
+}
+
+func BenchmarkDrawImage(b *testing.B) {
+	src, _ := ebiten.NewImage(16, 16, ebiten.FilterNearest)
+	dst, _ := ebiten.NewImage(256, 256, ebiten.FilterNearest)
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < benchmarkSpritesNum; j++ {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Rotate(float64(j))
+			op.GeoM.Translate(float64(j%256), float64(j/256))
+			dst.DrawImage(src, op)
+		}
+		// Read a pixel to wait for the GPU.
+		dst.At(0, 0)
+	}
+}
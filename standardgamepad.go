@@ -0,0 +1,215 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten
+
+import (
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/internal/gamepaddb"
+)
+
+// A StandardGamepadButton represents a gamepad button in the standard layout.
+//
+// The layout is the same as W3C's standard gamepad (https://www.w3.org/TR/gamepad/#remapping).
+// For example, StandardGamepadButtonRightBottom is A on Xbox controllers and Cross on PlayStation controllers.
+type StandardGamepadButton int
+
+// StandardGamepadButtons
+const (
+	StandardGamepadButtonRightBottom      StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonRightBottom)
+	StandardGamepadButtonRightRight       StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonRightRight)
+	StandardGamepadButtonRightLeft        StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonRightLeft)
+	StandardGamepadButtonRightTop         StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonRightTop)
+	StandardGamepadButtonFrontTopLeft     StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonFrontTopLeft)
+	StandardGamepadButtonFrontTopRight    StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonFrontTopRight)
+	StandardGamepadButtonFrontBottomLeft  StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonFrontBottomLeft)
+	StandardGamepadButtonFrontBottomRight StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonFrontBottomRight)
+	StandardGamepadButtonCenterLeft       StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonCenterLeft)
+	StandardGamepadButtonCenterRight      StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonCenterRight)
+	StandardGamepadButtonLeftStick        StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonLeftStick)
+	StandardGamepadButtonRightStick       StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonRightStick)
+	StandardGamepadButtonLeftTop          StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonLeftTop)
+	StandardGamepadButtonLeftBottom       StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonLeftBottom)
+	StandardGamepadButtonLeftLeft         StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonLeftLeft)
+	StandardGamepadButtonLeftRight        StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonLeftRight)
+	StandardGamepadButtonCenterCenter     StandardGamepadButton = StandardGamepadButton(gamepaddb.StandardButtonCenterCenter)
+	StandardGamepadButtonMax              StandardGamepadButton = StandardGamepadButtonCenterCenter
+)
+
+// A StandardGamepadAxis represents a gamepad axis in the standard layout.
+//
+// The horizontal axes are positive to the right, and the vertical axes are positive downward.
+type StandardGamepadAxis int
+
+// StandardGamepadAxes
+const (
+	StandardGamepadAxisLeftStickHorizontal  StandardGamepadAxis = StandardGamepadAxis(gamepaddb.StandardAxisLeftStickHorizontal)
+	StandardGamepadAxisLeftStickVertical    StandardGamepadAxis = StandardGamepadAxis(gamepaddb.StandardAxisLeftStickVertical)
+	StandardGamepadAxisRightStickHorizontal StandardGamepadAxis = StandardGamepadAxis(gamepaddb.StandardAxisRightStickHorizontal)
+	StandardGamepadAxisRightStickVertical   StandardGamepadAxis = StandardGamepadAxis(gamepaddb.StandardAxisRightStickVertical)
+	StandardGamepadAxisMax                  StandardGamepadAxis = StandardGamepadAxisRightStickVertical
+)
+
+// IsStandardGamepadLayoutAvailable returns a boolean value indicating whether
+// the gamepad (id) can be used with the standard layout.
+//
+// On desktops, the standard layout is available when there is a mapping for the gamepad.
+// See UpdateStandardGamepadLayoutMappings.
+// On browsers, the standard layout is available when the browser reports the standard mapping.
+//
+// This function is concurrent-safe.
+//
+// This function always returns false on mobiles.
+func IsStandardGamepadLayoutAvailable(id int) bool {
+	return currentInput().IsStandardGamepadLayoutAvailable(id)
+}
+
+// IsStandardGamepadButtonPressed returns a boolean value indicating whether
+// the given button of the gamepad (id) in the standard layout is pressed.
+//
+// IsStandardGamepadButtonPressed returns false when the standard layout is not available for the gamepad.
+//
+// This function is concurrent-safe.
+//
+// This function always returns false on mobiles.
+func IsStandardGamepadButtonPressed(id int, button StandardGamepadButton) bool {
+	return currentInput().IsStandardGamepadButtonPressed(id, gamepaddb.StandardButton(button))
+}
+
+// StandardGamepadButtonValue returns the float value [0.0 - 1.0] of the given button of the gamepad (id)
+// in the standard layout.
+//
+// This is useful for analog buttons like triggers.
+// For digital buttons, StandardGamepadButtonValue returns 0 or 1.
+//
+// StandardGamepadButtonValue returns 0 when the standard layout is not available for the gamepad.
+//
+// This function is concurrent-safe.
+//
+// This function always returns 0 on mobiles.
+func StandardGamepadButtonValue(id int, button StandardGamepadButton) float64 {
+	return currentInput().StandardGamepadButtonValue(id, gamepaddb.StandardButton(button))
+}
+
+// StandardGamepadAxisValue returns the float value [-1.0 - 1.0] of the given axis of the gamepad (id)
+// in the standard layout.
+//
+// StandardGamepadAxisValue returns 0 when the standard layout is not available for the gamepad.
+//
+// This function is concurrent-safe.
+//
+// This function always returns 0 on mobiles.
+func StandardGamepadAxisValue(id int, axis StandardGamepadAxis) float64 {
+	return currentInput().StandardGamepadAxisValue(id, gamepaddb.StandardAxis(axis))
+}
+
+// UpdateStandardGamepadLayoutMappings adds the gamepad mappings to the standard layout.
+//
+// mappings is in the format of SDL_GameControllerDB (https://github.com/gabomdq/SDL_GameControllerDB).
+// Each line is 'GUID,name,mapping...,platform:Platform,'.
+// Lines for other platforms are ignored, and a later line overrides an earlier one for the same gamepad.
+// Ebiten has built-in mappings for well-known gamepads like Xbox and PlayStation controllers,
+// but not the whole SDL_GameControllerDB.
+//
+// A mapping is used for the gamepad whose GUID (GamepadGUID) is the same as the GUID in the line.
+// As in SDL, the version part of the GUID is ignored when no line has the exact GUID.
+// When no GUID matches, a mapping is used for the gamepad whose name reported by the OS is the same as the name in the line.
+// GUIDs of devices are available only on Linux, so on the other platforms, only the names are used.
+// As the names in SDL_GameControllerDB are rarely the same as the names the OS reports,
+// the lines of SDL_GameControllerDB for Windows and macOS don't work without replacing the names.
+//
+// The indices of buttons and axes must be the ones GamepadAxis and IsGamepadButtonPressed use.
+// They are the same as SDL's on Linux and for DirectInput gamepads on Windows, but not for XInput gamepads
+// on Windows or for gamepads on macOS.
+// Hats (e.g. 'dpup:h0.1') are treated as the last axes on Linux and the last buttons on the other platforms.
+//
+// UpdateStandardGamepadLayoutMappings returns true if at least one mapping is added.
+// UpdateStandardGamepadLayoutMappings returns an error when mappings has an invalid line,
+// and no mapping is added in this case.
+//
+// This function is concurrent-safe.
+func UpdateStandardGamepadLayoutMappings(mappings string) (bool, error) {
+	return gamepaddb.Update([]byte(mappings))
+}
+
+type gamepadBrand int
+
+const (
+	gamepadBrandXbox gamepadBrand = iota
+	gamepadBrandPlayStation
+	gamepadBrandNintendo
+)
+
+// standardGamepadButtonNames is the names of the buttons in the standard layout for each brand.
+var standardGamepadButtonNames = map[gamepadBrand][]string{
+	gamepadBrandXbox: {
+		"A", "B", "X", "Y", "LB", "RB", "LT", "RT", "View", "Menu", "LS", "RS", "Up", "Down", "Left", "Right", "Xbox",
+	},
+	gamepadBrandPlayStation: {
+		"Cross", "Circle", "Square", "Triangle", "L1", "R1", "L2", "R2", "Share", "Options", "L3", "R3", "Up", "Down", "Left", "Right", "PS",
+	},
+	gamepadBrandNintendo: {
+		"B", "A", "Y", "X", "L", "R", "ZL", "ZR", "-", "+", "LS", "RS", "Up", "Down", "Left", "Right", "Home",
+	},
+}
+
+func currentGamepadBrand(id int) gamepadBrand {
+	switch v, _ := GamepadVendorProductID(id); v {
+	case 0x045e:
+		return gamepadBrandXbox
+	case 0x054c:
+		return gamepadBrandPlayStation
+	case 0x057e:
+		return gamepadBrandNintendo
+	}
+	n := strings.ToLower(GamepadName(id))
+	// Xbox Wireless Controller must not be taken for DualShock 4, whose name is "Wireless Controller" on Windows.
+	if strings.Contains(n, "xbox") {
+		return gamepadBrandXbox
+	}
+	if n == "wireless controller" {
+		return gamepadBrandPlayStation
+	}
+	for _, s := range []string{"playstation", "ps3", "ps4", "dualshock", "dualsense", "sony"} {
+		if strings.Contains(n, s) {
+			return gamepadBrandPlayStation
+		}
+	}
+	for _, s := range []string{"nintendo", "pro controller", "joy-con"} {
+		if strings.Contains(n, s) {
+			return gamepadBrandNintendo
+		}
+	}
+	return gamepadBrandXbox
+}
+
+// StandardGamepadButtonName returns a human-readable name of the button in the standard layout
+// for the gamepad (id), e.g. for a key binding menu.
+//
+// The names depend on the brand of the gamepad.
+// For example, StandardGamepadButtonRightBottom is "A" on Xbox controllers, "Cross" on PlayStation controllers and
+// "B" on Nintendo controllers.
+// The brand is guessed from the vendor ID or the name of the gamepad, and the names of Xbox controllers are used
+// when the brand is unknown.
+//
+// StandardGamepadButtonName returns an empty string for an invalid button.
+//
+// This function is concurrent-safe.
+func StandardGamepadButtonName(id int, button StandardGamepadButton) string {
+	if button < 0 || StandardGamepadButtonMax < button {
+		return ""
+	}
+	return standardGamepadButtonNames[currentGamepadBrand(id)][button]
+}
@@ -0,0 +1,46 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebiten_test
+
+import (
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+func TestStandardGamepadButtonName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"Xbox Wireless Controller", "A"},
+		{"Xbox 360 Controller", "A"},
+		{"Wireless Controller", "Cross"},
+		{"Sony Interactive Entertainment Wireless Controller", "Cross"},
+		{"DualSense Wireless Controller", "Cross"},
+		{"Nintendo Switch Pro Controller", "B"},
+		{"Unknown Gamepad", "A"},
+	}
+	defer ui.SetReplayedInputState(nil)
+	for _, c := range testCases {
+		ui.SetReplayedInputState(&ui.InputState{
+			Gamepads: []ui.GamepadState{{ID: 0, Name: c.name}},
+		})
+		if got := StandardGamepadButtonName(0, StandardGamepadButtonRightBottom); got != c.expected {
+			t.Errorf("StandardGamepadButtonName(%q, StandardGamepadButtonRightBottom) = %q, wanted %q", c.name, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// texcompress compresses an image file (PNG, JPEG or GIF) into the raw blocks for ebiten.NewCompressedImage.
+//
+// Usage:
+//
+//     texcompress [-format dxt1|dxt5] input.png output.dxt
+//
+// The output file has no header. Pass the size of the input image to ebiten.NewCompressedImage:
+//
+//     data, _ := ioutil.ReadFile("output.dxt")
+//     img, err := ebiten.NewCompressedImage(data, width, height, ebiten.CompressedFormatDXT5, ebiten.FilterLinear)
+//
+// This is useful with go:generate to compress images at build time.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/ioutil"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/texcompress"
+)
+
+var flagFormat = flag.String("format", "dxt5", "compressed format: dxt1 or dxt5")
+
+func run() error {
+	flag.Parse()
+	if flag.NArg() != 2 {
+		return fmt.Errorf("usage: texcompress [-format dxt1|dxt5] input output")
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch *flagFormat {
+	case "dxt1":
+		data, err = texcompress.EncodeDXT1(img)
+	case "dxt5":
+		data, err = texcompress.EncodeDXT5(img)
+	default:
+		return fmt.Errorf("texcompress: invalid format: %s", *flagFormat)
+	}
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(flag.Arg(1), data, 0644); err != nil {
+		return err
+	}
+	s := img.Bounds().Size()
+	fmt.Printf("%s: %dx%d, %d bytes\n", flag.Arg(1), s.X, s.Y, len(data))
+	return nil
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package texcompress offers encoders of GPU-compressed pixels for ebiten.NewCompressedImage.
+//
+// The encoders are simple and fast enough to compress images at build time or at loading time.
+// For better quality or ETC2, use external tools that output raw blocks.
+//
+// Note: This package is experimental and API might be changed.
+package texcompress
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// EncodeDXT1 returns the DXT1 blocks of the image for ebiten.CompressedFormatDXT1.
+//
+// A pixel whose alpha is less than half is encoded as a transparent pixel, and the other pixels are opaque.
+//
+// The width and height of img must be multiples of 4. Otherwise, EncodeDXT1 returns an error.
+func EncodeDXT1(img image.Image) ([]byte, error) {
+	return encode(img, 8, func(dst []byte, block *[16]color.RGBA) {
+		encodeColorBlock(dst, block, true)
+	})
+}
+
+// EncodeDXT5 returns the DXT5 blocks of the image for ebiten.CompressedFormatDXT5.
+//
+// The width and height of img must be multiples of 4. Otherwise, EncodeDXT5 returns an error.
+func EncodeDXT5(img image.Image) ([]byte, error) {
+	return encode(img, 16, func(dst []byte, block *[16]color.RGBA) {
+		encodeAlphaBlock(dst[:8], block)
+		encodeColorBlock(dst[8:], block, false)
+	})
+}
+
+func encode(img image.Image, blockSize int, encodeBlock func(dst []byte, block *[16]color.RGBA)) ([]byte, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil, errors.New("texcompress: the image must not be empty")
+	}
+	if w%4 != 0 || h%4 != 0 {
+		return nil, fmt.Errorf("texcompress: width (%d) and height (%d) must be multiples of 4", w, h)
+	}
+	data := make([]byte, (w/4)*(h/4)*blockSize)
+	var block [16]color.RGBA
+	idx := 0
+	for y := b.Min.Y; y < b.Max.Y; y += 4 {
+		for x := b.Min.X; x < b.Max.X; x += 4 {
+			for j := 0; j < 4; j++ {
+				for i := 0; i < 4; i++ {
+					// Ebiten's textures have premultiplied alpha.
+					block[4*j+i] = color.RGBAModel.Convert(img.At(x+i, y+j)).(color.RGBA)
+				}
+			}
+			encodeBlock(data[idx:idx+blockSize], &block)
+			idx += blockSize
+		}
+	}
+	return data, nil
+}
+
+// encodeColorBlock encodes the colors of the block into 8 bytes.
+//
+// If punchThrough is true, the block can have transparent pixels in the 3-color mode.
+func encodeColorBlock(dst []byte, block *[16]color.RGBA, punchThrough bool) {
+	transparent := func(c color.RGBA) bool {
+		return punchThrough && c.A < 0x80
+	}
+
+	// Use the corners of the bounding box of the colors as the end points.
+	min := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	max := color.RGBA{}
+	hasTransparent := false
+	hasOpaque := false
+	for _, c := range block {
+		if transparent(c) {
+			hasTransparent = true
+			continue
+		}
+		hasOpaque = true
+		if c.R < min.R {
+			min.R = c.R
+		}
+		if c.G < min.G {
+			min.G = c.G
+		}
+		if c.B < min.B {
+			min.B = c.B
+		}
+		if max.R < c.R {
+			max.R = c.R
+		}
+		if max.G < c.G {
+			max.G = c.G
+		}
+		if max.B < c.B {
+			max.B = c.B
+		}
+	}
+	if !hasOpaque {
+		// All the pixels use the index 3, which is transparent in the 3-color mode.
+		for i := range dst {
+			dst[i] = 0
+		}
+		dst[4], dst[5], dst[6], dst[7] = 0xff, 0xff, 0xff, 0xff
+		return
+	}
+
+	c0, c1 := to565(max), to565(min)
+	// The order of the end points decides the mode: c0 > c1 is the 4-color mode and
+	// c0 <= c1 is the 3-color mode with transparency.
+	if hasTransparent {
+		if c0 > c1 {
+			c0, c1 = c1, c0
+		}
+	} else if c0 < c1 {
+		c0, c1 = c1, c0
+	}
+
+	p0, p1 := from565(c0), from565(c1)
+	var palette [4]color.RGBA
+	palette[0], palette[1] = p0, p1
+	n := 4
+	if c0 > c1 {
+		palette[2] = mix(p0, p1, 2, 1, 3)
+		palette[3] = mix(p0, p1, 1, 2, 3)
+	} else {
+		palette[2] = mix(p0, p1, 1, 1, 2)
+		n = 3
+	}
+
+	var indices uint32
+	for i, c := range block {
+		var idx uint32
+		if transparent(c) {
+			idx = 3
+		} else {
+			idx = uint32(nearest(c, palette[:n]))
+		}
+		indices |= idx << (2 * uint(i))
+	}
+
+	dst[0], dst[1] = uint8(c0), uint8(c0>>8)
+	dst[2], dst[3] = uint8(c1), uint8(c1>>8)
+	dst[4], dst[5], dst[6], dst[7] = uint8(indices), uint8(indices>>8), uint8(indices>>16), uint8(indices>>24)
+}
+
+// encodeAlphaBlock encodes the alpha values of the block into 8 bytes.
+func encodeAlphaBlock(dst []byte, block *[16]color.RGBA) {
+	a0, a1 := uint8(0), uint8(0xff)
+	for _, c := range block {
+		if a0 < c.A {
+			a0 = c.A
+		}
+		if c.A < a1 {
+			a1 = c.A
+		}
+	}
+
+	// a0 > a1 is the mode with 6 interpolated values.
+	var palette [8]int
+	palette[0], palette[1] = int(a0), int(a1)
+	for i := 1; i <= 6; i++ {
+		palette[i+1] = ((7-i)*int(a0) + i*int(a1) + 3) / 7
+	}
+
+	var indices uint64
+	if a0 != a1 {
+		for i, c := range block {
+			idx, d := 0, 0x100
+			for j, a := range palette {
+				if dd := abs(int(c.A) - a); dd < d {
+					idx, d = j, dd
+				}
+			}
+			indices |= uint64(idx) << (3 * uint(i))
+		}
+	}
+
+	dst[0], dst[1] = a0, a1
+	for i := 0; i < 6; i++ {
+		dst[2+i] = uint8(indices >> (8 * uint(i)))
+	}
+}
+
+func to565(c color.RGBA) uint16 {
+	return uint16(c.R>>3)<<11 | uint16(c.G>>2)<<5 | uint16(c.B>>3)
+}
+
+func from565(c uint16) color.RGBA {
+	r := uint8(c >> 11 & 0x1f)
+	g := uint8(c >> 5 & 0x3f)
+	b := uint8(c & 0x1f)
+	return color.RGBA{r<<3 | r>>2, g<<2 | g>>4, b<<3 | b>>2, 0xff}
+}
+
+func mix(c0, c1 color.RGBA, w0, w1, d int) color.RGBA {
+	return color.RGBA{
+		uint8((w0*int(c0.R) + w1*int(c1.R)) / d),
+		uint8((w0*int(c0.G) + w1*int(c1.G)) / d),
+		uint8((w0*int(c0.B) + w1*int(c1.B)) / d),
+		0xff,
+	}
+}
+
+func nearest(c color.RGBA, palette []color.RGBA) int {
+	idx, d := 0, -1
+	for i, p := range palette {
+		dr := int(c.R) - int(p.R)
+		dg := int(c.G) - int(p.G)
+		db := int(c.B) - int(p.B)
+		if dd := dr*dr + dg*dg + db*db; d < 0 || dd < d {
+			idx, d = i, dd
+		}
+	}
+	return idx
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
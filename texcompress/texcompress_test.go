@@ -0,0 +1,103 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package texcompress_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/texcompress"
+)
+
+func filledImage(w, h int, clr color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			img.Set(i, j, clr)
+		}
+	}
+	return img
+}
+
+func TestEncodeDXT1(t *testing.T) {
+	cases := []struct {
+		Color color.Color
+		Want  []byte
+	}{
+		{
+			// 0xf800 is red in RGB565.
+			Color: color.RGBA{0xff, 0, 0, 0xff},
+			Want:  []byte{0x00, 0xf8, 0x00, 0xf8, 0, 0, 0, 0},
+		},
+		{
+			Color: color.Transparent,
+			Want:  []byte{0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff},
+		},
+	}
+	for _, c := range cases {
+		got, err := EncodeDXT1(filledImage(4, 4, c.Color))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, c.Want) {
+			t.Errorf("EncodeDXT1(%v): got: %v, want: %v", c.Color, got, c.Want)
+		}
+	}
+}
+
+func TestEncodeDXT1TwoColors(t *testing.T) {
+	img := filledImage(4, 4, color.White)
+	for i := 0; i < 4; i++ {
+		img.Set(i, 0, color.Black)
+	}
+	got, err := EncodeDXT1(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first end point is white and the second is black in the 4-color mode.
+	want := []byte{0xff, 0xff, 0x00, 0x00, 0x55, 0, 0, 0}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestEncodeDXT5(t *testing.T) {
+	img := filledImage(8, 4, color.RGBA{0, 0, 0x80, 0x80})
+	got, err := EncodeDXT5(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 32 {
+		t.Fatalf("len(got): %d, want: 32", len(got))
+	}
+	// 0x0010 is the blue 0x80 in RGB565.
+	want := []byte{0x80, 0x80, 0, 0, 0, 0, 0, 0, 0x10, 0x00, 0x10, 0x00, 0, 0, 0, 0}
+	for i := 0; i < 2; i++ {
+		if block := got[16*i : 16*(i+1)]; !bytes.Equal(block, want) {
+			t.Errorf("block %d: got: %v, want: %v", i, block, want)
+		}
+	}
+}
+
+func TestEncodeInvalidSize(t *testing.T) {
+	if _, err := EncodeDXT1(filledImage(6, 4, color.White)); err == nil {
+		t.Errorf("EncodeDXT1 must return an error for 6x4")
+	}
+	if _, err := EncodeDXT5(filledImage(4, 2, color.White)); err == nil {
+		t.Errorf("EncodeDXT5 must return an error for 4x2")
+	}
+}
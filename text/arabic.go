@@ -0,0 +1,183 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"unicode"
+)
+
+type arabicForm int
+
+const (
+	arabicIsolated arabicForm = iota
+	arabicFinal
+	arabicInitial
+	arabicMedial
+)
+
+// An arabicLetter represents the presentation forms of an Arabic letter.
+type arabicLetter struct {
+	// isolated is the isolated form in Arabic Presentation Forms-B.
+	// The final, initial and medial forms follow the isolated form in this order.
+	isolated rune
+
+	// dual reports whether the letter joins both sides.
+	// If dual is false, the letter joins only the previous (right) letter.
+	dual bool
+}
+
+var arabicLetters = map[rune]arabicLetter{
+	0x0622: {0xfe81, false},
+	0x0623: {0xfe83, false},
+	0x0624: {0xfe85, false},
+	0x0625: {0xfe87, false},
+	0x0626: {0xfe89, true},
+	0x0627: {0xfe8d, false},
+	0x0628: {0xfe8f, true},
+	0x0629: {0xfe93, false},
+	0x062a: {0xfe95, true},
+	0x062b: {0xfe99, true},
+	0x062c: {0xfe9d, true},
+	0x062d: {0xfea1, true},
+	0x062e: {0xfea5, true},
+	0x062f: {0xfea9, false},
+	0x0630: {0xfeab, false},
+	0x0631: {0xfead, false},
+	0x0632: {0xfeaf, false},
+	0x0633: {0xfeb1, true},
+	0x0634: {0xfeb5, true},
+	0x0635: {0xfeb9, true},
+	0x0636: {0xfebd, true},
+	0x0637: {0xfec1, true},
+	0x0638: {0xfec5, true},
+	0x0639: {0xfec9, true},
+	0x063a: {0xfecd, true},
+	0x0641: {0xfed1, true},
+	0x0642: {0xfed5, true},
+	0x0643: {0xfed9, true},
+	0x0644: {0xfedd, true},
+	0x0645: {0xfee1, true},
+	0x0646: {0xfee5, true},
+	0x0647: {0xfee9, true},
+	0x0648: {0xfeed, false},
+	0x0649: {0xfeef, false},
+	0x064a: {0xfef1, true},
+}
+
+const (
+	arabicLam     = 0x0644
+	arabicTatweel = 0x0640
+)
+
+// lamAlefLigatures maps an alef to the isolated form of the ligature of lam and the alef.
+// The final form follows the isolated form.
+var lamAlefLigatures = map[rune]rune{
+	0x0622: 0xfef5,
+	0x0623: 0xfef7,
+	0x0625: 0xfef9,
+	0x0627: 0xfefb,
+}
+
+func joinsPrev(r rune) bool {
+	if r == arabicTatweel {
+		return true
+	}
+	_, ok := arabicLetters[r]
+	return ok
+}
+
+func joinsNext(r rune) bool {
+	if r == arabicTatweel {
+		return true
+	}
+	l, ok := arabicLetters[r]
+	return ok && l.dual
+}
+
+// isTransparent reports whether r is skipped when joining is determined.
+func isTransparent(r rune) bool {
+	return unicode.Is(unicode.Mn, r)
+}
+
+// shapeArabic replaces Arabic letters with their contextual presentation forms.
+//
+// Fonts are expected to have glyphs for Arabic Presentation Forms-B,
+// since font.Face doesn't offer glyph substitution tables.
+func shapeArabic(runes []rune) []rune {
+	prevOf := func(i int) rune {
+		for i--; i >= 0; i-- {
+			if !isTransparent(runes[i]) {
+				return runes[i]
+			}
+		}
+		return 0
+	}
+	nextOf := func(i int) (rune, int) {
+		for i++; i < len(runes); i++ {
+			if !isTransparent(runes[i]) {
+				return runes[i], i
+			}
+		}
+		return 0, -1
+	}
+
+	result := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		l, ok := arabicLetters[r]
+		if !ok {
+			result = append(result, r)
+			continue
+		}
+		prev := joinsNext(prevOf(i)) && joinsPrev(r)
+
+		if r == arabicLam {
+			if n, j := nextOf(i); j == i+1 {
+				if lig, ok := lamAlefLigatures[n]; ok {
+					if prev {
+						lig++
+					}
+					result = append(result, lig)
+					i++
+					continue
+				}
+			}
+		}
+
+		n, _ := nextOf(i)
+		next := l.dual && joinsPrev(n)
+		form := arabicIsolated
+		switch {
+		case prev && next:
+			form = arabicMedial
+		case prev:
+			form = arabicFinal
+		case next:
+			form = arabicInitial
+		}
+		result = append(result, l.isolated+rune(form))
+	}
+	return result
+}
+
+// hasArabic reports whether runes include Arabic letters to be shaped.
+func hasArabic(runes []rune) bool {
+	for _, r := range runes {
+		if _, ok := arabicLetters[r]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,328 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"unicode"
+)
+
+// bidiClass is a simplified bidirectional character type.
+// See Unicode Standard Annex #9 for the full types.
+type bidiClass int
+
+const (
+	bidiNeutral bidiClass = iota
+	bidiL
+	bidiR
+	bidiNumber
+)
+
+func isRTLRune(r rune) bool {
+	return unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko)
+}
+
+func classOf(r rune) bidiClass {
+	switch {
+	case unicode.IsDigit(r):
+		return bidiNumber
+	case isRTLRune(r) && (unicode.IsLetter(r) || unicode.Is(unicode.Mn, r)):
+		return bidiR
+	case unicode.IsLetter(r), unicode.Is(unicode.Mn, r):
+		return bidiL
+	}
+	return bidiNeutral
+}
+
+func hasRTL(runes []rune) bool {
+	for _, r := range runes {
+		if r >= 0x0590 && isRTLRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+var mirroredRunes = map[rune]rune{
+	'(': ')',
+	')': '(',
+	'<': '>',
+	'>': '<',
+	'[': ']',
+	']': '[',
+	'{': '}',
+	'}': '{',
+	'«': '»',
+	'»': '«',
+}
+
+// bracketPairs maps an opening paired bracket to its closing bracket.
+var bracketPairs = map[rune]rune{
+	'(': ')',
+	'[': ']',
+	'{': '}',
+}
+
+// maxBracketDepth is the maximum depth of the nested brackets (BD16).
+const maxBracketDepth = 63
+
+// findBracketPairs returns the positions of the paired brackets sorted by the opening brackets (BD16).
+func findBracketPairs(runes []rune) [][2]int {
+	type opening struct {
+		closing rune
+		pos     int
+	}
+	var stack []opening
+	var pairs [][2]int
+	for i, r := range runes {
+		if c, ok := bracketPairs[r]; ok {
+			if len(stack) == maxBracketDepth {
+				break
+			}
+			stack = append(stack, opening{c, i})
+			continue
+		}
+		for j := len(stack) - 1; j >= 0; j-- {
+			if stack[j].closing != r {
+				continue
+			}
+			pairs = append(pairs, [2]int{stack[j].pos, i})
+			stack = stack[:j]
+			break
+		}
+	}
+	// Sort the pairs by the opening brackets.
+	for i := 1; i < len(pairs); i++ {
+		for j := i; j > 0 && pairs[j][0] < pairs[j-1][0]; j-- {
+			pairs[j], pairs[j-1] = pairs[j-1], pairs[j]
+		}
+	}
+	return pairs
+}
+
+// bidiLevels returns the embedding levels of the given runes.
+//
+// This is a simplified version of the Unicode Bidirectional Algorithm:
+// explicit embeddings and overrides are not supported.
+func bidiLevels(runes []rune) []int {
+	classes := make([]bidiClass, len(runes))
+	for i, r := range runes {
+		classes[i] = classOf(r)
+	}
+
+	// P2, P3: The paragraph level is determined by the first strong character.
+	base := 0
+	for _, c := range classes {
+		if c == bidiL {
+			break
+		}
+		if c == bidiR {
+			base = 1
+			break
+		}
+	}
+	sos := bidiL
+	if base == 1 {
+		sos = bidiR
+	}
+
+	// W7: Numbers after L are treated as L.
+	last := sos
+	for i, c := range classes {
+		switch c {
+		case bidiL, bidiR:
+			last = c
+		case bidiNumber:
+			if last == bidiL {
+				classes[i] = bidiL
+			}
+		}
+	}
+
+	// Numbers act as R in resolving neutrals.
+	strong := func(c bidiClass) bidiClass {
+		if c == bidiNumber {
+			return bidiR
+		}
+		return c
+	}
+
+	// N0: Paired brackets take the direction of their content,
+	// or the direction of the preceding context if the content is in the opposite direction.
+	for _, p := range findBracketPairs(runes) {
+		var found bidiClass
+		for _, c := range classes[p[0]+1 : p[1]] {
+			c = strong(c)
+			if c == sos {
+				found = c
+				break
+			}
+			if c != bidiNeutral {
+				found = c
+			}
+		}
+		if found == bidiNeutral {
+			continue
+		}
+		if found != sos {
+			ctx := sos
+			for i := p[0] - 1; i >= 0; i-- {
+				if c := strong(classes[i]); c != bidiNeutral {
+					ctx = c
+					break
+				}
+			}
+			if ctx != found {
+				found = sos
+			}
+		}
+		classes[p[0]] = found
+		classes[p[1]] = found
+	}
+
+	// N1, N2: Neutrals between the same directions take the direction.
+	for i := 0; i < len(classes); {
+		if classes[i] != bidiNeutral {
+			i++
+			continue
+		}
+		j := i
+		for j < len(classes) && classes[j] == bidiNeutral {
+			j++
+		}
+		prev := sos
+		if i > 0 {
+			prev = strong(classes[i-1])
+		}
+		next := sos
+		if j < len(classes) {
+			next = strong(classes[j])
+		}
+		c := sos
+		if prev == next {
+			c = prev
+		}
+		for k := i; k < j; k++ {
+			classes[k] = c
+		}
+		i = j
+	}
+
+	// I1, I2: Resolve the implicit levels.
+	levels := make([]int, len(runes))
+	for i, c := range classes {
+		switch {
+		case base == 0 && c == bidiR:
+			levels[i] = 1
+		case base == 0 && c == bidiNumber:
+			levels[i] = 2
+		case base == 1 && c != bidiR:
+			levels[i] = 2
+		default:
+			levels[i] = base
+		}
+	}
+
+	// L1: Trailing whitespaces are reset to the paragraph level.
+	for i := len(runes) - 1; i >= 0 && unicode.IsSpace(runes[i]); i-- {
+		levels[i] = base
+	}
+	return levels
+}
+
+// reverseRunes reverses runes keeping non-spacing marks after their base characters.
+func reverseRunes(runes []rune) {
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	for i := 0; i < len(runes); i++ {
+		if !unicode.Is(unicode.Mn, runes[i]) {
+			continue
+		}
+		j := i
+		for j < len(runes) && unicode.Is(unicode.Mn, runes[j]) {
+			j++
+		}
+		// runes[i:j] are marks and runes[j] is their base.
+		if j == len(runes) {
+			break
+		}
+		b := runes[j]
+		copy(runes[i+1:j+1], runes[i:j])
+		runes[i] = b
+		for k, l := i+1, j; k < l; k, l = k+1, l-1 {
+			runes[k], runes[l] = runes[l], runes[k]
+		}
+		i = j
+	}
+}
+
+// visualOrder converts a line in logical order to visual order.
+func visualOrder(runes []rune) []rune {
+	levels := bidiLevels(runes)
+	result := make([]rune, len(runes))
+	copy(result, runes)
+	for i, l := range levels {
+		if l%2 == 0 {
+			continue
+		}
+		if m, ok := mirroredRunes[result[i]]; ok {
+			result[i] = m
+		}
+	}
+
+	// L2: Reverse any contiguous sequence at the level and higher,
+	// from the highest level to the lowest odd level.
+	max := 0
+	for _, l := range levels {
+		if max < l {
+			max = l
+		}
+	}
+	for level := max; level >= 1; level-- {
+		for i := 0; i < len(levels); {
+			if levels[i] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(levels) && levels[j] >= level {
+				j++
+			}
+			reverseRunes(result[i:j])
+			reverseInts(levels[i:j])
+			i = j
+		}
+	}
+	return result
+}
+
+func reverseInts(xs []int) {
+	for i, j := 0, len(xs)-1; i < j; i, j = i+1, j-1 {
+		xs[i], xs[j] = xs[j], xs[i]
+	}
+}
+
+// shapeLine converts a line in logical order to runes to be drawn from left to right.
+// Arabic letters are shaped and right-to-left runs are reordered.
+func shapeLine(text string) []rune {
+	runes := []rune(text)
+	if !hasRTL(runes) {
+		return runes
+	}
+	if hasArabic(runes) {
+		runes = shapeArabic(runes)
+	}
+	return visualOrder(runes)
+}
@@ -0,0 +1,114 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/text"
+)
+
+func TestBidiLevels(t *testing.T) {
+	cases := []struct {
+		Text   string
+		Levels []int
+	}{
+		{"abc", []int{0, 0, 0}},
+		{"ab שלום", []int{0, 0, 0, 1, 1, 1, 1}},
+		// Numbers in an RTL paragraph are at the level 2, and the trailing space is at the paragraph level.
+		{"שלום 12 ", []int{1, 1, 1, 1, 1, 2, 2, 1}},
+	}
+	for _, c := range cases {
+		if got := BidiLevels([]rune(c.Text)); !reflect.DeepEqual(got, c.Levels) {
+			t.Errorf("BidiLevels(%q): got: %v, want: %v", c.Text, got, c.Levels)
+		}
+	}
+}
+
+func TestReverseRunes(t *testing.T) {
+	// The non-spacing mark (patah) stays after its base character.
+	runes := []rune{'א', 0x05b7, 'ב'}
+	ReverseRunes(runes)
+	if got, want := runes, []rune{'ב', 'א', 0x05b7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %U, want: %U", got, want)
+	}
+}
+
+func TestVisualOrder(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Text   string
+		Visual string
+	}{
+		{"LTR", "abc def", "abc def"},
+		{"Hebrew run", "abc שלום def", "abc םולש def"},
+		{"RTL paragraph", "שלום עולם", "םלוע םולש"},
+		{"digits in RTL", "שלום 123 עולם", "םלוע 123 םולש"},
+		{"mirrored brackets", "(שלום)", "(םולש)"},
+		{"brackets at LTR level", "a (שלום) b", "a (םולש) b"},
+		// Paired brackets take the direction of their content or the preceding context.
+		{"bracket pairs in LTR paragraph", "a [שלום (עולם)] b", "a [(םלוע) םולש] b"},
+		{"bracket pairs in RTL paragraph", "שלום (abc) עולם", "םלוע (abc) םולש"},
+		{"unpaired bracket", "שלום (abc", "abc) םולש"},
+	}
+	for _, c := range cases {
+		if got := string(VisualOrder([]rune(c.Text))); got != c.Visual {
+			t.Errorf("%s: VisualOrder(%q): got: %q, want: %q", c.Name, c.Text, got, c.Visual)
+		}
+	}
+}
+
+func TestShapeArabic(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Text   string
+		Shaped []rune
+	}{
+		{"isolated", "ب", []rune{0xfe8f}},
+		{"initial and final", "بب", []rune{0xfe91, 0xfe90}},
+		{"medial", "ببب", []rune{0xfe91, 0xfe92, 0xfe90}},
+		// Alef doesn't join the next letter.
+		{"right-joining", "ابا", []rune{0xfe8d, 0xfe91, 0xfe8e}},
+		// A non-spacing mark (fatha) is skipped in joining.
+		{"transparent", "بَب", []rune{0xfe91, 0x064e, 0xfe90}},
+		{"lam-alef isolated", "لا", []rune{0xfefb}},
+		{"lam-alef final", "بلا", []rune{0xfe91, 0xfefc}},
+		{"non-Arabic", "a", []rune{'a'}},
+	}
+	for _, c := range cases {
+		if got := ShapeArabic([]rune(c.Text)); !reflect.DeepEqual(got, c.Shaped) {
+			t.Errorf("%s: ShapeArabic(%q): got: %U, want: %U", c.Name, c.Text, got, c.Shaped)
+		}
+	}
+}
+
+func TestShapeLine(t *testing.T) {
+	cases := []struct {
+		Text  string
+		Runes []rune
+	}{
+		{"abc", []rune("abc")},
+		{"שלום", []rune("םולש")},
+		// Shaped Arabic letters are reordered from left to right.
+		{"بلا", []rune{0xfefc, 0xfe91}},
+		{"ab بب", []rune{'a', 'b', ' ', 0xfe90, 0xfe91}},
+	}
+	for _, c := range cases {
+		if got := ShapeLine(c.Text); !reflect.DeepEqual(got, c.Runes) {
+			t.Errorf("ShapeLine(%q): got: %U, want: %U", c.Text, got, c.Runes)
+		}
+	}
+}
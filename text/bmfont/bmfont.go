@@ -0,0 +1,284 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bmfont offers font faces for pre-rendered bitmap fonts in the AngelCode BMFont format.
+//
+// The faces can be used with the text package.
+//
+// Note: This package is experimental and API might be changed.
+package bmfont
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+type char struct {
+	x        int
+	y        int
+	width    int
+	height   int
+	xoffset  int
+	yoffset  int
+	xadvance int
+	page     int
+}
+
+type kerningPair struct {
+	first  rune
+	second rune
+}
+
+type face struct {
+	lineHeight int
+	base       int
+	pages      []*image.Alpha
+	chars      map[rune]char
+	kernings   map[kerningPair]int
+}
+
+// NewFace parses a BMFont descriptor (.fnt) in the text format and returns a font face.
+//
+// loadPage is called with each page's file name written in the descriptor and must return the page image.
+// Glyphs are used as masks: the alpha channel is used when the page image has it,
+// and the luminance is used otherwise (e.g. for grayscale images).
+// Colors of the text are specified at drawing as well as fonts for the text package.
+//
+// The XML and binary formats are not supported.
+func NewFace(fnt io.Reader, loadPage func(name string) (image.Image, error)) (font.Face, error) {
+	f := &face{
+		chars:    map[rune]char{},
+		kernings: map[kerningPair]int{},
+	}
+
+	s := bufio.NewScanner(fnt)
+	for lineno := 1; s.Scan(); lineno++ {
+		tag, attrs, err := parseLine(s.Text())
+		if err != nil {
+			return nil, fmt.Errorf("bmfont: line %d: %v", lineno, err)
+		}
+		switch tag {
+		case "common":
+			if f.lineHeight, err = attrs.int("lineHeight"); err != nil {
+				return nil, fmt.Errorf("bmfont: line %d: %v", lineno, err)
+			}
+			if f.base, err = attrs.int("base"); err != nil {
+				return nil, fmt.Errorf("bmfont: line %d: %v", lineno, err)
+			}
+		case "page":
+			id, err := attrs.int("id")
+			if err != nil {
+				return nil, fmt.Errorf("bmfont: line %d: %v", lineno, err)
+			}
+			name, ok := attrs["file"]
+			if !ok {
+				return nil, fmt.Errorf("bmfont: line %d: file is missing", lineno)
+			}
+			img, err := loadPage(name)
+			if err != nil {
+				return nil, err
+			}
+			for len(f.pages) <= id {
+				f.pages = append(f.pages, nil)
+			}
+			f.pages[id] = toAlpha(img)
+		case "char":
+			var c char
+			id := 0
+			for _, a := range []struct {
+				key string
+				v   *int
+			}{
+				{"id", &id},
+				{"x", &c.x},
+				{"y", &c.y},
+				{"width", &c.width},
+				{"height", &c.height},
+				{"xoffset", &c.xoffset},
+				{"yoffset", &c.yoffset},
+				{"xadvance", &c.xadvance},
+				{"page", &c.page},
+			} {
+				if *a.v, err = attrs.int(a.key); err != nil {
+					return nil, fmt.Errorf("bmfont: line %d: %v", lineno, err)
+				}
+			}
+			f.chars[rune(id)] = c
+		case "kerning":
+			first, err := attrs.int("first")
+			if err != nil {
+				return nil, fmt.Errorf("bmfont: line %d: %v", lineno, err)
+			}
+			second, err := attrs.int("second")
+			if err != nil {
+				return nil, fmt.Errorf("bmfont: line %d: %v", lineno, err)
+			}
+			amount, err := attrs.int("amount")
+			if err != nil {
+				return nil, fmt.Errorf("bmfont: line %d: %v", lineno, err)
+			}
+			f.kernings[kerningPair{rune(first), rune(second)}] = amount
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	for r, c := range f.chars {
+		if c.page < 0 || len(f.pages) <= c.page || f.pages[c.page] == nil {
+			return nil, fmt.Errorf("bmfont: page %d for char %d is missing", c.page, r)
+		}
+	}
+	return f, nil
+}
+
+type attributes map[string]string
+
+func (a attributes) int(key string) (int, error) {
+	v, ok := a[key]
+	if !ok {
+		return 0, fmt.Errorf("%s is missing", key)
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", key, err)
+	}
+	return i, nil
+}
+
+// parseLine parses a line like `tag key0=value0 key1="value 1"`.
+func parseLine(line string) (string, attributes, error) {
+	line = strings.TrimSpace(line)
+	idx := strings.IndexAny(line, " \t")
+	if idx < 0 {
+		return line, attributes{}, nil
+	}
+	tag := line[:idx]
+	rest := line[idx:]
+
+	attrs := attributes{}
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			break
+		}
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return "", nil, fmt.Errorf("invalid attribute: %q", rest)
+		}
+		key := rest[:eq]
+		rest = rest[eq+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return "", nil, fmt.Errorf("unterminated string: %q", rest)
+			}
+			value = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		attrs[key] = value
+	}
+	return tag, attrs, nil
+}
+
+func toAlpha(img image.Image) *image.Alpha {
+	b := img.Bounds()
+	a := image.NewAlpha(image.Rect(0, 0, b.Dx(), b.Dy()))
+	useLuminance := false
+	switch img.ColorModel() {
+	case color.GrayModel, color.Gray16Model:
+		useLuminance = true
+	}
+	for j := 0; j < b.Dy(); j++ {
+		for i := 0; i < b.Dx(); i++ {
+			c := img.At(b.Min.X+i, b.Min.Y+j)
+			if useLuminance {
+				a.Pix[j*a.Stride+i] = color.GrayModel.Convert(c).(color.Gray).Y
+				continue
+			}
+			_, _, _, ca := c.RGBA()
+			a.Pix[j*a.Stride+i] = uint8(ca >> 8)
+		}
+	}
+	return a
+}
+
+// Close implements font.Face.
+func (f *face) Close() error {
+	return nil
+}
+
+// Glyph implements font.Face.
+func (f *face) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	c, ok := f.chars[r]
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	x := dot.X.Floor() + c.xoffset
+	y := dot.Y.Floor() - f.base + c.yoffset
+	dr := image.Rect(x, y, x+c.width, y+c.height)
+	return dr, f.pages[c.page], image.Pt(c.x, c.y), fixed.I(c.xadvance), true
+}
+
+// GlyphBounds implements font.Face.
+func (f *face) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	c, ok := f.chars[r]
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	b := fixed.Rectangle26_6{
+		Min: fixed.P(c.xoffset, c.yoffset-f.base),
+		Max: fixed.P(c.xoffset+c.width, c.yoffset-f.base+c.height),
+	}
+	return b, fixed.I(c.xadvance), true
+}
+
+// GlyphAdvance implements font.Face.
+func (f *face) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	c, ok := f.chars[r]
+	if !ok {
+		return 0, false
+	}
+	return fixed.I(c.xadvance), true
+}
+
+// Kern implements font.Face.
+func (f *face) Kern(r0, r1 rune) fixed.Int26_6 {
+	return fixed.I(f.kernings[kerningPair{r0, r1}])
+}
+
+// Metrics implements font.Face.
+func (f *face) Metrics() font.Metrics {
+	return font.Metrics{
+		Height:  fixed.I(f.lineHeight),
+		Ascent:  fixed.I(f.base),
+		Descent: fixed.I(f.lineHeight - f.base),
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bmfont_test
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+
+	. "github.com/hajimehoshi/ebiten/text/bmfont"
+)
+
+const testFnt = `info face="Test Font" size=8 bold=0 italic=0
+common lineHeight=10 base=8 scaleW=4 scaleH=2 pages=1 packed=0
+page id=0 file="test_0.png"
+chars count=2
+char id=65 x=0 y=0 width=2 height=2 xoffset=1 yoffset=6 xadvance=4 page=0 chnl=15
+char id=66 x=2 y=0 width=2 height=2 xoffset=0 yoffset=3 xadvance=3 page=0 chnl=15
+kernings count=1
+kerning first=65 second=66 amount=-1
+`
+
+func TestNewFace(t *testing.T) {
+	page := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	page.Set(0, 0, color.NRGBA{0xff, 0xff, 0xff, 0xff})
+	page.Set(3, 1, color.NRGBA{0xff, 0xff, 0xff, 0x80})
+
+	var name string
+	f, err := NewFace(strings.NewReader(testFnt), func(n string) (image.Image, error) {
+		name = n
+		return page, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "test_0.png" {
+		t.Errorf("page name: got: %q, want: %q", name, "test_0.png")
+	}
+
+	m := f.Metrics()
+	if m.Height != fixed.I(10) || m.Ascent != fixed.I(8) || m.Descent != fixed.I(2) {
+		t.Errorf("Metrics: got: %v", m)
+	}
+	if got, want := f.Kern('A', 'B'), fixed.I(-1); got != want {
+		t.Errorf("Kern('A', 'B'): got: %v, want: %v", got, want)
+	}
+	if got, want := f.Kern('B', 'A'), fixed.I(0); got != want {
+		t.Errorf("Kern('B', 'A'): got: %v, want: %v", got, want)
+	}
+	if got, ok := f.GlyphAdvance('B'); got != fixed.I(3) || !ok {
+		t.Errorf("GlyphAdvance('B'): got: %v, %t, want: %v, true", got, ok, fixed.I(3))
+	}
+	if _, ok := f.GlyphAdvance('C'); ok {
+		t.Errorf("GlyphAdvance('C'): got: true, want: false")
+	}
+
+	b, _, _ := f.GlyphBounds('A')
+	if want := (fixed.Rectangle26_6{Min: fixed.P(1, -2), Max: fixed.P(3, 0)}); b != want {
+		t.Errorf("GlyphBounds('A'): got: %v, want: %v", b, want)
+	}
+
+	dr, mask, maskp, _, ok := f.Glyph(fixed.P(10, 20), 'B')
+	if !ok {
+		t.Fatal("Glyph('B'): got: false, want: true")
+	}
+	if want := image.Rect(10, 15, 12, 17); dr != want {
+		t.Errorf("Glyph('B') rectangle: got: %v, want: %v", dr, want)
+	}
+	if want := image.Pt(2, 0); maskp != want {
+		t.Errorf("Glyph('B') mask point: got: %v, want: %v", maskp, want)
+	}
+	for _, c := range []struct {
+		X     int
+		Y     int
+		Alpha uint32
+	}{
+		{0, 0, 0xffff},
+		{1, 0, 0},
+		{3, 1, 0x8080},
+	} {
+		_, _, _, a := mask.At(c.X, c.Y).RGBA()
+		if a != c.Alpha {
+			t.Errorf("mask.At(%d, %d): got: %#x, want: %#x", c.X, c.Y, a, c.Alpha)
+		}
+	}
+}
+
+func TestNewFaceMissingPage(t *testing.T) {
+	const fnt = `common lineHeight=10 base=8
+char id=65 x=0 y=0 width=2 height=2 xoffset=1 yoffset=6 xadvance=4 page=0
+`
+	if _, err := NewFace(strings.NewReader(fnt), nil); err == nil {
+		t.Errorf("NewFace must return an error when a page is missing")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"image"
+	"math"
+)
+
+// sdfPoint is an offset to the nearest pixel found so far in the distance transform.
+type sdfPoint struct {
+	dx int
+	dy int
+}
+
+func (p sdfPoint) distSq() int {
+	return p.dx*p.dx + p.dy*p.dy
+}
+
+// distanceGrid returns the offsets from each pixel to the nearest pixel where target returns true.
+//
+// distanceGrid uses the 8-points signed sequential Euclidean distance transform (8SSEDT).
+func distanceGrid(w, h int, target func(i, j int) bool) []sdfPoint {
+	const far = 1 << 14
+
+	g := make([]sdfPoint, w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			if !target(i, j) {
+				g[i+j*w] = sdfPoint{far, far}
+			}
+		}
+	}
+
+	compare := func(i, j, ox, oy int) {
+		x, y := i+ox, j+oy
+		if x < 0 || y < 0 || x >= w || y >= h {
+			return
+		}
+		o := g[x+y*w]
+		o.dx += ox
+		o.dy += oy
+		if p := &g[i+j*w]; o.distSq() < p.distSq() {
+			*p = o
+		}
+	}
+
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			compare(i, j, -1, 0)
+			compare(i, j, 0, -1)
+			compare(i, j, -1, -1)
+			compare(i, j, 1, -1)
+		}
+		for i := w - 1; i >= 0; i-- {
+			compare(i, j, 1, 0)
+		}
+	}
+	for j := h - 1; j >= 0; j-- {
+		for i := w - 1; i >= 0; i-- {
+			compare(i, j, 1, 0)
+			compare(i, j, 0, 1)
+			compare(i, j, -1, 1)
+			compare(i, j, 1, 1)
+		}
+		for i := 0; i < w; i++ {
+			compare(i, j, -1, 0)
+		}
+	}
+	return g
+}
+
+// signedDistanceField converts the coverage of a glyph into a signed distance field.
+//
+// The result is a premultiplied RGBA pixels whose all the channels hold the distance:
+// 0.5 (0x80) is on the edge, a bigger value is inside and a smaller value is outside.
+// The distance of spread pixels maps to 0.5.
+func signedDistanceField(coverage *image.Alpha, spread int) []uint8 {
+	b := coverage.Bounds()
+	w, h := b.Dx(), b.Dy()
+	at := func(i, j int) uint8 {
+		return coverage.Pix[j*coverage.Stride+i]
+	}
+	inside := distanceGrid(w, h, func(i, j int) bool { return at(i, j) >= 0x80 })
+	outside := distanceGrid(w, h, func(i, j int) bool { return at(i, j) < 0x80 })
+
+	pix := make([]uint8, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			var d float64
+			switch a := at(i, j); {
+			case a != 0 && a != 0xff:
+				// Anti-aliased pixels are on the edge: approximate the distance with the coverage.
+				d = float64(a)/0xff - 0.5
+			case a >= 0x80:
+				d = math.Sqrt(float64(outside[i+j*w].distSq())) - 0.5
+			default:
+				d = -(math.Sqrt(float64(inside[i+j*w].distSq())) - 0.5)
+			}
+			v := 0.5 + d/float64(2*spread)
+			if v < 0 {
+				v = 0
+			}
+			if v > 1 {
+				v = 1
+			}
+			c := uint8(v*0xff + 0.5)
+			idx := 4 * (i + j*w)
+			pix[idx] = c
+			pix[idx+1] = c
+			pix[idx+2] = c
+			pix[idx+3] = c
+		}
+	}
+	return pix
+}
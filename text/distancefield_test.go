@@ -0,0 +1,67 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_test
+
+import (
+	"image"
+	"testing"
+
+	. "github.com/hajimehoshi/ebiten/text"
+)
+
+func TestSignedDistanceField(t *testing.T) {
+	// The glyph is a 5x5 square at (2, 2) with an anti-aliased pixel below it.
+	coverage := image.NewAlpha(image.Rect(0, 0, 9, 9))
+	for j := 2; j < 7; j++ {
+		for i := 2; i < 7; i++ {
+			coverage.Pix[j*coverage.Stride+i] = 0xff
+		}
+	}
+	coverage.Pix[7*coverage.Stride+4] = 0x80
+
+	cases := []struct {
+		Name   string
+		X      int
+		Y      int
+		Spread int
+		Value  uint8
+	}{
+		// The center is 2.5 pixels far from the edge, which is clamped with the spread 2.
+		{"center", 4, 4, 2, 0xff},
+		{"center", 4, 4, 8, 167},
+		{"inside the edge", 2, 4, 2, 159},
+		{"inside the edge", 2, 4, 8, 135},
+		{"outside the edge", 1, 4, 2, 96},
+		{"outside the edge", 1, 4, 8, 120},
+		// The corner is sqrt(8) pixels far from the glyph, which is clamped with the spread 2.
+		{"corner", 0, 0, 2, 0},
+		{"corner", 0, 0, 8, 90},
+		// The anti-aliased pixel is on the edge.
+		{"anti-aliased", 4, 7, 2, 0x80},
+		{"anti-aliased", 4, 7, 8, 0x80},
+	}
+	for _, c := range cases {
+		pix := SignedDistanceField(coverage, c.Spread)
+		if got, want := len(pix), 4*9*9; got != want {
+			t.Fatalf("len(pix): got: %d, want: %d", got, want)
+		}
+		idx := 4 * (c.X + c.Y*9)
+		for k := 0; k < 4; k++ {
+			if got := pix[idx+k]; got != c.Value {
+				t.Errorf("%s (%d, %d) with spread %d: channel %d: got: %d, want: %d", c.Name, c.X, c.Y, c.Spread, k, got, c.Value)
+			}
+		}
+	}
+}
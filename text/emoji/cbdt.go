@@ -0,0 +1,347 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package emoji
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var be = binary.BigEndian
+
+// sfntTables returns the tables of an OpenType font by their tags.
+func sfntTables(src []byte) (map[string][]byte, error) {
+	if len(src) < 12 {
+		return nil, errors.New("emoji: the font is too short")
+	}
+	n := int(be.Uint16(src[4:]))
+	if len(src) < 12+16*n {
+		return nil, errors.New("emoji: the table directory is broken")
+	}
+	tables := map[string][]byte{}
+	for i := 0; i < n; i++ {
+		r := src[12+16*i:]
+		tag := string(r[:4])
+		offset := int(be.Uint32(r[8:]))
+		length := int(be.Uint32(r[12:]))
+		if offset < 0 || length < 0 || offset+length > len(src) {
+			return nil, fmt.Errorf("emoji: the table %q is out of range", tag)
+		}
+		tables[tag] = src[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// bytesReader reads big-endian values from a table.
+// Reading out of range results in zero values and sets the error.
+type bytesReader struct {
+	b   []byte
+	err error
+}
+
+func (r *bytesReader) check(offset, size int) bool {
+	if r.err != nil {
+		return false
+	}
+	if offset < 0 || offset+size > len(r.b) {
+		r.err = errors.New("emoji: the table is broken")
+		return false
+	}
+	return true
+}
+
+func (r *bytesReader) u8(offset int) int {
+	if !r.check(offset, 1) {
+		return 0
+	}
+	return int(r.b[offset])
+}
+
+func (r *bytesReader) i8(offset int) int {
+	if !r.check(offset, 1) {
+		return 0
+	}
+	return int(int8(r.b[offset]))
+}
+
+func (r *bytesReader) u16(offset int) int {
+	if !r.check(offset, 2) {
+		return 0
+	}
+	return int(be.Uint16(r.b[offset:]))
+}
+
+func (r *bytesReader) i16(offset int) int {
+	if !r.check(offset, 2) {
+		return 0
+	}
+	return int(int16(be.Uint16(r.b[offset:])))
+}
+
+func (r *bytesReader) u32(offset int) int {
+	if !r.check(offset, 4) {
+		return 0
+	}
+	return int(be.Uint32(r.b[offset:]))
+}
+
+func (r *bytesReader) bytes(offset, size int) []byte {
+	if !r.check(offset, size) {
+		return nil
+	}
+	return r.b[offset : offset+size]
+}
+
+// cmap maps runes to glyph indices.
+type cmap struct {
+	r      *bytesReader
+	offset int
+	format int
+}
+
+func parseCmap(table []byte) (*cmap, error) {
+	r := &bytesReader{b: table}
+	n := r.u16(2)
+	var best *cmap
+	for i := 0; i < n; i++ {
+		platform := r.u16(4 + 8*i)
+		encoding := r.u16(4 + 8*i + 2)
+		offset := r.u32(4 + 8*i + 4)
+		format := r.u16(offset)
+		if r.err != nil {
+			return nil, r.err
+		}
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		// Prefer format 12, which covers the supplementary planes where most emoji are.
+		switch format {
+		case 12:
+			return &cmap{r: r, offset: offset, format: format}, nil
+		case 4:
+			if best == nil {
+				best = &cmap{r: r, offset: offset, format: format}
+			}
+		}
+	}
+	if best == nil {
+		return nil, errors.New("emoji: no supported cmap subtable")
+	}
+	return best, nil
+}
+
+func (c *cmap) glyphIndex(x rune) int {
+	r := c.r
+	o := c.offset
+	switch c.format {
+	case 4:
+		if x > 0xffff {
+			return 0
+		}
+		segs := r.u16(o+6) / 2
+		ends := o + 14
+		starts := ends + 2*segs + 2
+		deltas := starts + 2*segs
+		rangeOffsets := deltas + 2*segs
+		for i := 0; i < segs; i++ {
+			if int(x) > r.u16(ends+2*i) {
+				continue
+			}
+			start := r.u16(starts + 2*i)
+			if int(x) < start {
+				return 0
+			}
+			delta := r.i16(deltas + 2*i)
+			ro := r.u16(rangeOffsets + 2*i)
+			if ro == 0 {
+				return (int(x) + delta) & 0xffff
+			}
+			g := r.u16(rangeOffsets + 2*i + ro + 2*(int(x)-start))
+			if g == 0 {
+				return 0
+			}
+			return (g + delta) & 0xffff
+		}
+	case 12:
+		n := r.u32(o + 12)
+		for i := 0; i < n; i++ {
+			g := o + 16 + 12*i
+			start, end := r.u32(g), r.u32(g+4)
+			if int(x) < start || end < int(x) {
+				continue
+			}
+			return r.u32(g+8) + int(x) - start
+		}
+	}
+	return 0
+}
+
+// glyphMetrics represents the metrics of a bitmap glyph in pixels of the strike.
+type glyphMetrics struct {
+	width    int
+	height   int
+	bearingX int
+	bearingY int
+	advance  int
+}
+
+func readBigGlyphMetrics(r *bytesReader, offset int) glyphMetrics {
+	return glyphMetrics{
+		height:   r.u8(offset),
+		width:    r.u8(offset + 1),
+		bearingX: r.i8(offset + 2),
+		bearingY: r.i8(offset + 3),
+		advance:  r.u8(offset + 4),
+	}
+}
+
+// strike represents a set of bitmap glyphs of a size in the CBLC table.
+type strike struct {
+	ppem int
+
+	cblc                *bytesReader
+	cbdt                *bytesReader
+	subTableArrayOffset int
+	subTablesNum        int
+}
+
+func parseStrikes(cblc, cbdt []byte) ([]*strike, error) {
+	r := &bytesReader{b: cblc}
+	d := &bytesReader{b: cbdt}
+	n := r.u32(4)
+	var strikes []*strike
+	for i := 0; i < n; i++ {
+		o := 8 + 48*i
+		s := &strike{
+			ppem:                r.u8(o + 45),
+			cblc:                r,
+			cbdt:                d,
+			subTableArrayOffset: r.u32(o),
+			subTablesNum:        r.u32(o + 8),
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		if s.ppem == 0 {
+			continue
+		}
+		strikes = append(strikes, s)
+	}
+	if len(strikes) == 0 {
+		return nil, errors.New("emoji: no bitmap strikes")
+	}
+	return strikes, nil
+}
+
+// glyph returns the metrics and the PNG data of the glyph.
+// glyph returns nil data when the glyph is not found in the strike.
+func (s *strike) glyph(index int) (glyphMetrics, []byte, error) {
+	r := s.cblc
+	for i := 0; i < s.subTablesNum; i++ {
+		a := s.subTableArrayOffset + 8*i
+		first, last := r.u16(a), r.u16(a+2)
+		if r.err != nil {
+			return glyphMetrics{}, nil, r.err
+		}
+		if index < first || last < index {
+			continue
+		}
+		h := s.subTableArrayOffset + r.u32(a+4)
+		indexFormat := r.u16(h)
+		imageFormat := r.u16(h + 2)
+		dataOffset := r.u32(h + 4)
+		k := index - first
+
+		var offset int
+		var metrics *glyphMetrics
+		switch indexFormat {
+		case 1:
+			offset = r.u32(h + 8 + 4*k)
+		case 2:
+			offset = r.u32(h+8) * k
+			m := readBigGlyphMetrics(r, h+12)
+			metrics = &m
+		case 3:
+			offset = r.u16(h + 8 + 2*k)
+		case 4:
+			n := r.u32(h + 8)
+			found := false
+			for j := 0; j < n; j++ {
+				if r.u16(h+12+4*j) == index {
+					offset = r.u16(h + 12 + 4*j + 2)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return glyphMetrics{}, nil, r.err
+			}
+		case 5:
+			m := readBigGlyphMetrics(r, h+12)
+			metrics = &m
+			n := r.u32(h + 20)
+			found := false
+			for j := 0; j < n; j++ {
+				if r.u16(h+24+2*j) == index {
+					offset = r.u32(h+8) * j
+					found = true
+					break
+				}
+			}
+			if !found {
+				return glyphMetrics{}, nil, r.err
+			}
+		default:
+			return glyphMetrics{}, nil, fmt.Errorf("emoji: index subtable format %d is not supported", indexFormat)
+		}
+		if r.err != nil {
+			return glyphMetrics{}, nil, r.err
+		}
+
+		d := s.cbdt
+		o := dataOffset + offset
+		var m glyphMetrics
+		var data []byte
+		switch imageFormat {
+		case 17:
+			m = glyphMetrics{
+				height:   d.u8(o),
+				width:    d.u8(o + 1),
+				bearingX: d.i8(o + 2),
+				bearingY: d.i8(o + 3),
+				advance:  d.u8(o + 4),
+			}
+			data = d.bytes(o+9, d.u32(o+5))
+		case 18:
+			m = readBigGlyphMetrics(d, o)
+			data = d.bytes(o+12, d.u32(o+8))
+		case 19:
+			if metrics == nil {
+				return glyphMetrics{}, nil, errors.New("emoji: image format 19 requires metrics in the index subtable")
+			}
+			m = *metrics
+			data = d.bytes(o+4, d.u32(o))
+		default:
+			return glyphMetrics{}, nil, fmt.Errorf("emoji: image format %d is not supported", imageFormat)
+		}
+		if d.err != nil {
+			return glyphMetrics{}, nil, d.err
+		}
+		return m, data, nil
+	}
+	return glyphMetrics{}, nil, nil
+}
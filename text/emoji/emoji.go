@@ -0,0 +1,224 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package emoji offers font faces for color emoji fonts with embedded bitmaps.
+//
+// A face of this package draws emoji with their colors by the text package,
+// and delegates the other characters to the base face.
+//
+// Note: This package is experimental and API might be changed.
+package emoji
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"math"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Face is a font face for a color emoji font.
+//
+// Face implements text.ColoredGlyphFace.
+type Face struct {
+	base   font.Face
+	cmap   *cmap
+	strike *strike
+	scale  float64
+
+	glyphs map[rune]*glyph
+}
+
+type glyph struct {
+	image   *image.RGBA
+	advance fixed.Int26_6
+}
+
+// NewFace returns a new font face for the given emoji font.
+//
+// src is the font file including CBDT and CBLC tables for color bitmap glyphs, like Noto Color Emoji.
+// size is the font size in pixels. The bitmaps of the nearest size in the font are scaled to size.
+// base is the font face for characters that the emoji font doesn't have. base must not be nil.
+//
+// Sequences composed by ligatures, such as ones joined with ZERO WIDTH JOINER (U+200D), are not supported.
+// Variation selectors are ignored.
+func NewFace(src []byte, size float64, base font.Face) (*Face, error) {
+	if base == nil {
+		return nil, errors.New("emoji: base must not be nil")
+	}
+	tables, err := sfntTables(src)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"cmap", "CBLC", "CBDT"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, errors.New("emoji: " + tag + " table is missing")
+		}
+	}
+	c, err := parseCmap(tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	strikes, err := parseStrikes(tables["CBLC"], tables["CBDT"])
+	if err != nil {
+		return nil, err
+	}
+
+	// Choose the smallest strike not smaller than size, or the biggest one.
+	var s *strike
+	for _, st := range strikes {
+		if s == nil {
+			s = st
+			continue
+		}
+		big := float64(s.ppem) >= size
+		if float64(st.ppem) >= size {
+			if !big || st.ppem < s.ppem {
+				s = st
+			}
+			continue
+		}
+		if !big && st.ppem > s.ppem {
+			s = st
+		}
+	}
+	return &Face{
+		base:   base,
+		cmap:   c,
+		strike: s,
+		scale:  size / float64(s.ppem),
+		glyphs: map[rune]*glyph{},
+	}, nil
+}
+
+func isIgnorable(r rune) bool {
+	return (0xfe00 <= r && r <= 0xfe0f) || (0xe0100 <= r && r <= 0xe01ef) || r == 0x200d
+}
+
+// glyph returns the colored glyph for r, or nil if the emoji font doesn't have r.
+func (f *Face) glyph(r rune) *glyph {
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+	f.glyphs[r] = nil
+
+	idx := f.cmap.glyphIndex(r)
+	if idx == 0 {
+		return nil
+	}
+	m, data, err := f.strike.glyph(idx)
+	if err != nil || data == nil {
+		return nil
+	}
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	x0 := int(math.Floor(float64(m.bearingX) * f.scale))
+	y0 := int(math.Floor(float64(-m.bearingY) * f.scale))
+	x1 := int(math.Ceil(float64(m.bearingX+m.width) * f.scale))
+	y1 := int(math.Ceil(float64(-m.bearingY+m.height) * f.scale))
+	dst := image.NewRGBA(image.Rect(x0, y0, x1, y1))
+	draw.BiLinear.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	g := &glyph{
+		image:   dst,
+		advance: fixed.Int26_6(float64(m.advance) * f.scale * (1 << 6)),
+	}
+	f.glyphs[r] = g
+	return g
+}
+
+// ColoredGlyph returns the colored image of the glyph for r.
+// The image's bounds are relative to the dot.
+//
+// ColoredGlyph returns false if the emoji font doesn't have r.
+func (f *Face) ColoredGlyph(r rune) (image.Image, bool) {
+	g := f.glyph(r)
+	if g == nil {
+		return nil, false
+	}
+	return g.image, true
+}
+
+// Close implements font.Face.
+//
+// Close doesn't close the base face.
+func (f *Face) Close() error {
+	return nil
+}
+
+// Glyph implements font.Face.
+//
+// For an emoji, the mask is the alpha channel of the colored glyph.
+func (f *Face) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	if isIgnorable(r) {
+		return image.Rectangle{}, image.Transparent, image.Point{}, 0, true
+	}
+	g := f.glyph(r)
+	if g == nil {
+		return f.base.Glyph(dot, r)
+	}
+	b := g.image.Bounds()
+	dr := b.Add(image.Pt(dot.X.Round(), dot.Y.Round()))
+	return dr, g.image, b.Min, g.advance, true
+}
+
+// GlyphBounds implements font.Face.
+func (f *Face) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	if isIgnorable(r) {
+		return fixed.Rectangle26_6{}, 0, true
+	}
+	g := f.glyph(r)
+	if g == nil {
+		return f.base.GlyphBounds(r)
+	}
+	b := g.image.Bounds()
+	return fixed.Rectangle26_6{
+		Min: fixed.P(b.Min.X, b.Min.Y),
+		Max: fixed.P(b.Max.X, b.Max.Y),
+	}, g.advance, true
+}
+
+// GlyphAdvance implements font.Face.
+func (f *Face) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	if isIgnorable(r) {
+		return 0, true
+	}
+	g := f.glyph(r)
+	if g == nil {
+		return f.base.GlyphAdvance(r)
+	}
+	return g.advance, true
+}
+
+// Kern implements font.Face.
+func (f *Face) Kern(r0, r1 rune) fixed.Int26_6 {
+	if f.glyph(r0) != nil || f.glyph(r1) != nil {
+		return 0
+	}
+	return f.base.Kern(r0, r1)
+}
+
+// Metrics implements font.Face.
+//
+// Metrics returns the base face's metrics.
+func (f *Face) Metrics() font.Metrics {
+	return f.base.Metrics()
+}
@@ -0,0 +1,138 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package emoji_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	. "github.com/hajimehoshi/ebiten/text/emoji"
+)
+
+func u16(v int) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, uint16(v))
+	return b
+}
+
+func u32(v int) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+func join(bs ...[]byte) []byte {
+	return bytes.Join(bs, nil)
+}
+
+const testEmoji = 0x1f600
+
+// testFont returns a font that has a 2x2 red glyph for testEmoji at 2 ppem.
+func testFont(t *testing.T) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	for j := 0; j < 2; j++ {
+		for i := 0; i < 2; i++ {
+			img.Set(i, j, color.NRGBA{0xff, 0, 0, 0xff})
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	cmap := join(
+		u16(0), u16(1),
+		u16(3), u16(10), u32(12),
+		u16(12), u16(0), u32(28), u32(0), u32(1),
+		u32(testEmoji), u32(testEmoji), u32(1))
+
+	// The small glyph metrics: height, width, bearingX, bearingY and advance.
+	glyphData := join([]byte{2, 2, 0, 2, 3}, u32(buf.Len()), buf.Bytes())
+	cbdt := join(u16(3), u16(0), glyphData)
+
+	bitmapSize := join(
+		u32(56), u32(16), u32(1), u32(0),
+		make([]byte, 24),
+		u16(1), u16(1), []byte{2, 2, 32, 1})
+	cblc := join(
+		u16(3), u16(0), u32(1),
+		bitmapSize,
+		u16(1), u16(1), u32(8),
+		u16(1), u16(17), u32(4), u32(0), u32(len(glyphData)))
+
+	tables := []struct {
+		tag  string
+		data []byte
+	}{
+		{"CBDT", cbdt},
+		{"CBLC", cblc},
+		{"cmap", cmap},
+	}
+	header := join(u32(0x00010000), u16(len(tables)), u16(0), u16(0), u16(0))
+	offset := len(header) + 16*len(tables)
+	var records, data []byte
+	for _, t := range tables {
+		records = join(records, []byte(t.tag), u32(0), u32(offset+len(data)), u32(len(t.data)))
+		data = join(data, t.data)
+	}
+	return join(header, records, data)
+}
+
+func TestFace(t *testing.T) {
+	// The bitmap at 2 ppem is scaled by 2.
+	f, err := NewFace(testFont(t), 4, basicfont.Face7x13)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, ok := f.ColoredGlyph(testEmoji)
+	if !ok {
+		t.Fatal("ColoredGlyph: got: false, want: true")
+	}
+	if got, want := img.Bounds(), image.Rect(0, -4, 4, 0); got != want {
+		t.Errorf("ColoredGlyph bounds: got: %v, want: %v", got, want)
+	}
+	if got, want := color.RGBAModel.Convert(img.At(1, -1)), (color.RGBA{0xff, 0, 0, 0xff}); got != want {
+		t.Errorf("ColoredGlyph At(1, -1): got: %v, want: %v", got, want)
+	}
+	if got, _ := f.GlyphAdvance(testEmoji); got != fixed.I(6) {
+		t.Errorf("GlyphAdvance(emoji): got: %v, want: %v", got, fixed.I(6))
+	}
+
+	// Runes that the emoji font doesn't have are delegated to the base face.
+	if _, ok := f.ColoredGlyph('a'); ok {
+		t.Errorf("ColoredGlyph('a'): got: true, want: false")
+	}
+	if got, _ := f.GlyphAdvance('a'); got != fixed.I(7) {
+		t.Errorf("GlyphAdvance('a'): got: %v, want: %v", got, fixed.I(7))
+	}
+	if got, _ := f.GlyphAdvance(0xfe0f); got != 0 {
+		t.Errorf("GlyphAdvance(U+FE0F): got: %v, want: 0", got)
+	}
+}
+
+func TestNewFaceWithoutColorTables(t *testing.T) {
+	src := join(u32(0x00010000), u16(0), u16(0), u16(0), u16(0))
+	if _, err := NewFace(src, 16, basicfont.Face7x13); err == nil {
+		t.Errorf("NewFace must return an error for a font without color tables")
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"image/color"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// Align represents horizontal alignment of lines.
+type Align int
+
+const (
+	// AlignLeft aligns lines to the left edge.
+	AlignLeft Align = iota
+
+	// AlignCenter aligns lines to the center.
+	AlignCenter
+
+	// AlignRight aligns lines to the right edge.
+	AlignRight
+)
+
+// A LayoutOptions represents options to lay out a text.
+//
+// The zero value lays out the text with no wrapping, the face's default line height and left alignment.
+type LayoutOptions struct {
+	// MaxWidth is the maximum width of a line in pixels.
+	// A line wider than MaxWidth is wrapped at a space, or at a character if a word doesn't fit the width.
+	// The default (zero) value means no wrapping.
+	MaxWidth int
+
+	// LineSpacing is the distance between baselines of adjacent lines in pixels.
+	// The default (zero) value means the face's recommended line height.
+	LineSpacing int
+
+	// Align is the horizontal alignment of lines.
+	// If MaxWidth is positive, lines are aligned within MaxWidth.
+	// Otherwise, lines are aligned within the width of the widest line.
+	// The default (zero) value is AlignLeft.
+	Align Align
+}
+
+func lineSpacing(face font.Face, op *LayoutOptions) fixed.Int26_6 {
+	if op != nil && op.LineSpacing > 0 {
+		return fixed.I(op.LineSpacing)
+	}
+	return face.Metrics().Height
+}
+
+func lineAdvance(face font.Face, text string) fixed.Int26_6 {
+	x := fixed.Int26_6(0)
+	prevC := rune(-1)
+	for _, c := range shapeLine(text) {
+		if prevC >= 0 {
+			x += face.Kern(prevC, c)
+		}
+		a, _ := face.GlyphAdvance(c)
+		x += a
+		prevC = c
+	}
+	return x
+}
+
+// splitLongWord splits word into pieces each of which fits maxWidth.
+// Each piece has at least one character.
+func splitLongWord(face font.Face, word string, maxWidth fixed.Int26_6) []string {
+	var pieces []string
+	runes := []rune(word)
+	for len(runes) > 0 {
+		n := 1
+		for n < len(runes) && lineAdvance(face, string(runes[:n+1])) <= maxWidth {
+			n++
+		}
+		pieces = append(pieces, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return pieces
+}
+
+// layoutLines splits text into lines by line breaks and wrapping.
+func layoutLines(face font.Face, text string, op *LayoutOptions) []string {
+	paragraphs := strings.Split(text, "\n")
+	if op == nil || op.MaxWidth <= 0 {
+		return paragraphs
+	}
+	maxWidth := fixed.I(op.MaxWidth)
+
+	lines := []string{}
+	for _, p := range paragraphs {
+		line := ""
+		for i, word := range strings.Split(p, " ") {
+			if i > 0 {
+				if l := line + " " + word; lineAdvance(face, l) <= maxWidth {
+					line = l
+					continue
+				}
+				lines = append(lines, line)
+				line = ""
+			}
+			if lineAdvance(face, word) <= maxWidth {
+				line = word
+				continue
+			}
+			pieces := splitLongWord(face, word, maxWidth)
+			lines = append(lines, pieces[:len(pieces)-1]...)
+			line = pieces[len(pieces)-1]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Measure returns the size in pixels of the text laid out with the given options.
+//
+// The width is the advance of the widest line.
+// The height is the distance from the ascent of the first line to the descent of the last line.
+// op can be nil, which means the default options.
+//
+// This function is concurrent-safe.
+func Measure(text string, face font.Face, op *LayoutOptions) (width, height int) {
+	textM.Lock()
+	defer textM.Unlock()
+
+	lines := layoutLines(face, text, op)
+	w := fixed.Int26_6(0)
+	for _, l := range lines {
+		if a := lineAdvance(face, l); w < a {
+			w = a
+		}
+	}
+	m := face.Metrics()
+	h := m.Ascent + m.Descent + lineSpacing(face, op)*fixed.Int26_6(len(lines)-1)
+	return w.Ceil(), h.Ceil()
+}
+
+// DrawWithOptions draws a given text on a given destination image dst with the given layout options.
+//
+// (x, y) represents the 'dot' (period) position of the first line, as Draw does.
+// Line breaks ('\n') in the text start new lines.
+// op can be nil, which means the default options.
+//
+// This function is concurrent-safe.
+func DrawWithOptions(dst *ebiten.Image, text string, face font.Face, x, y int, clr color.Color, op *LayoutOptions) {
+	textM.Lock()
+	defer textM.Unlock()
+
+	n := now()
+	lines := layoutLines(face, text, op)
+	advances := make([]fixed.Int26_6, len(lines))
+	width := fixed.Int26_6(0)
+	for i, l := range lines {
+		advances[i] = lineAdvance(face, l)
+		if width < advances[i] {
+			width = advances[i]
+		}
+	}
+	align := AlignLeft
+	if op != nil {
+		align = op.Align
+		if op.MaxWidth > 0 {
+			width = fixed.I(op.MaxWidth)
+		}
+	}
+
+	spacing := lineSpacing(face, op)
+	fy := fixed.I(y)
+	for i, l := range lines {
+		fx := fixed.I(x)
+		switch align {
+		case AlignCenter:
+			fx += (width - advances[i]) / 2
+		case AlignRight:
+			fx += width - advances[i]
+		}
+		drawLine(dst, l, face, fx, fy, clr, n)
+		fy += spacing
+	}
+}
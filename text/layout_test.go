@@ -0,0 +1,51 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_test
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+
+	. "github.com/hajimehoshi/ebiten/text"
+)
+
+func TestMeasure(t *testing.T) {
+	// Face7x13 has 7 pixels advance, 13 pixels height, 11 pixels ascent and 2 pixels descent.
+	face := basicfont.Face7x13
+	cases := []struct {
+		Text   string
+		Option *LayoutOptions
+		Width  int
+		Height int
+	}{
+		{"", nil, 0, 13},
+		{"abc", nil, 21, 13},
+		{"abc\nde", nil, 21, 26},
+		{"abc\nde", &LayoutOptions{LineSpacing: 20}, 21, 33},
+		{"abc de fgh", &LayoutOptions{MaxWidth: 42}, 42, 26},
+		{"abc de fgh", &LayoutOptions{MaxWidth: 48}, 42, 26},
+		{"abc de fgh", &LayoutOptions{MaxWidth: 70}, 70, 13},
+		{"abcdefgh", &LayoutOptions{MaxWidth: 21}, 21, 39},
+		{"abcdefgh", &LayoutOptions{MaxWidth: 1}, 7, 104},
+		{"ab cd\n\nef", &LayoutOptions{MaxWidth: 14}, 14, 52},
+	}
+	for _, c := range cases {
+		w, h := Measure(c.Text, face, c.Option)
+		if w != c.Width || h != c.Height {
+			t.Errorf("Measure(%q, %v): got: (%d, %d), want: (%d, %d)", c.Text, c.Option, w, h, c.Width, c.Height)
+		}
+	}
+}
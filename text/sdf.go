@@ -0,0 +1,315 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const sdfShaderSrc = `package main
+
+var Smoothing float
+var Color vec4
+var OutlineColor vec4
+var OutlineWidth float
+var GlowColor vec4
+var GlowWidth float
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	d := imageSrc0At(texCoord).a
+	fill := smoothstep(0.5-Smoothing, 0.5+Smoothing, d)
+	edge := 0.5 - OutlineWidth
+	outline := smoothstep(edge-Smoothing, edge+Smoothing, d)
+	clr := Color*fill + OutlineColor*outline*(1-fill)
+	if GlowWidth > 0 {
+		glow := smoothstep(edge-GlowWidth, edge, d)
+		clr += GlowColor * glow * (1 - clr.a)
+	}
+	return clr
+}
+`
+
+var sdfShader *ebiten.Shader
+
+// sdfAtlasSize is the size of an atlas of an SDFFace.
+// A distance field keeps its quality at any scale, then a small atlas is enough.
+const sdfAtlasSize = 512
+
+type sdfGlyph struct {
+	// x, y, w and h represent the region of the glyph in the atlas.
+	x int
+	y int
+	w int
+	h int
+
+	// offsetX and offsetY represent the left-upper position of the region relative to the dot.
+	offsetX int
+	offsetY int
+}
+
+// An SDFFace is a font face rendered with signed distance fields.
+//
+// Glyphs are rasterized once at the size of the original face and converted into distance fields.
+// The distance fields can be drawn at any scale with smooth edges, outlines and glows by DrawSDF.
+type SDFFace struct {
+	face   font.Face
+	spread int
+
+	atlas  *ebiten.Image
+	glyphs map[rune]*sdfGlyph
+
+	// shelfX, shelfY and shelfH represent the current shelf to put glyphs in the atlas.
+	shelfX int
+	shelfY int
+	shelfH int
+}
+
+// NewSDFFace returns a new SDFFace for the given font face.
+//
+// The glyphs are rasterized with face, so face's size should be big enough for the expected scales, e.g. 32 or 48.
+// spread is the maximum distance in face's pixels held in the distance fields.
+// Outlines and glows wider than spread are clipped.
+func NewSDFFace(face font.Face, spread int) *SDFFace {
+	if spread <= 0 {
+		panic("text: spread must be positive")
+	}
+	return &SDFFace{
+		face:   face,
+		spread: spread,
+		glyphs: map[rune]*sdfGlyph{},
+	}
+}
+
+// glyph returns the cached glyph for r, rasterizing it if needed.
+// glyph returns nil when r doesn't have its image.
+// flush is called before the atlas is reset when the atlas is full.
+func (f *SDFFace) glyph(r rune, flush func()) *sdfGlyph {
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+
+	b, _, ok := f.face.GlyphBounds(r)
+	if !ok {
+		f.glyphs[r] = nil
+		return nil
+	}
+	x0, y0 := b.Min.X.Floor()-f.spread, b.Min.Y.Floor()-f.spread
+	x1, y1 := b.Max.X.Ceil()+f.spread, b.Max.Y.Ceil()+f.spread
+	w, h := x1-x0, y1-y0
+	if b.Min.X == b.Max.X || b.Min.Y == b.Max.Y || w > sdfAtlasSize || h > sdfAtlasSize {
+		f.glyphs[r] = nil
+		return nil
+	}
+
+	if f.atlas == nil {
+		f.atlas, _ = ebiten.NewImage(sdfAtlasSize, sdfAtlasSize, ebiten.FilterLinear)
+	}
+	// Put a gap of 1 pixel between glyphs so that linear filtering doesn't mix them.
+	if f.shelfX+w > sdfAtlasSize {
+		f.shelfX = 0
+		f.shelfY += f.shelfH + 1
+		f.shelfH = 0
+	}
+	if f.shelfY+h > sdfAtlasSize {
+		// The atlas is full. Reset the atlas and the cache.
+		flush()
+		f.glyphs = map[rune]*sdfGlyph{}
+		f.shelfX, f.shelfY, f.shelfH = 0, 0, 0
+	}
+
+	coverage := image.NewAlpha(image.Rect(0, 0, w, h))
+	d := font.Drawer{
+		Dst:  coverage,
+		Src:  image.Opaque,
+		Face: f.face,
+		Dot:  fixed.P(-x0, -y0),
+	}
+	d.DrawString(string(r))
+
+	g := &sdfGlyph{
+		x:       f.shelfX,
+		y:       f.shelfY,
+		w:       w,
+		h:       h,
+		offsetX: x0,
+		offsetY: y0,
+	}
+	f.atlas.SubImage(image.Rect(g.x, g.y, g.x+w, g.y+h)).(*ebiten.Image).ReplacePixels(signedDistanceField(coverage, f.spread))
+
+	f.shelfX += w + 1
+	if f.shelfH < h {
+		f.shelfH = h
+	}
+	f.glyphs[r] = g
+	return g
+}
+
+// SDFDrawOptions represents options to render a text with an SDFFace.
+type SDFDrawOptions struct {
+	// GeoM is a geometry matrix to draw.
+	// The 'dot' (period) position of the first line is at the origin before GeoM is applied.
+	// Scaling GeoM changes the text size while the edges are kept smooth.
+	GeoM ebiten.GeoM
+
+	// Color is the color of the text.
+	// The default (nil) value is white.
+	Color color.Color
+
+	// OutlineColor is the color of the outline.
+	// The default (nil) value is transparent.
+	OutlineColor color.Color
+
+	// OutlineWidth is the width of the outline in the original face's pixels.
+	OutlineWidth float64
+
+	// GlowColor is the color of the glow outside the outline.
+	// The glow fades out toward its outer side.
+	// The default (nil) value is transparent.
+	GlowColor color.Color
+
+	// GlowWidth is the width of the glow in the original face's pixels.
+	GlowWidth float64
+
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	CompositeMode ebiten.CompositeMode
+}
+
+func premultipliedColor(clr color.Color) []float32 {
+	if clr == nil {
+		return []float32{0, 0, 0, 0}
+	}
+	r, g, b, a := clr.RGBA()
+	return []float32{float32(r) / 0xffff, float32(g) / 0xffff, float32(b) / 0xffff, float32(a) / 0xffff}
+}
+
+// DrawSDF draws a given text on a given destination image dst with an SDFFace.
+//
+// Line breaks ('\n') in the text start new lines.
+// op can be nil, which means the default options.
+//
+// This function is concurrent-safe.
+func DrawSDF(dst *ebiten.Image, text string, face *SDFFace, op *SDFDrawOptions) {
+	textM.Lock()
+	defer textM.Unlock()
+
+	if op == nil {
+		op = &SDFDrawOptions{}
+	}
+	if sdfShader == nil {
+		s, err := ebiten.NewShader([]byte(sdfShaderSrc))
+		if err != nil {
+			panic(err)
+		}
+		sdfShader = s
+	}
+
+	a, b, c, d := op.GeoM.Element(0, 0), op.GeoM.Element(0, 1), op.GeoM.Element(1, 0), op.GeoM.Element(1, 1)
+	scale := math.Sqrt(math.Abs(a*d - b*c))
+	if scale == 0 {
+		return
+	}
+	// The distance of 1 pixel in the face is 1/(2*spread) in the field.
+	unit := 1 / float64(2*face.spread)
+
+	clr := []float32{1, 1, 1, 1}
+	if op.Color != nil {
+		clr = premultipliedColor(op.Color)
+	}
+	sop := &ebiten.DrawTrianglesShaderOptions{
+		CompositeMode: op.CompositeMode,
+		Uniforms: map[string]interface{}{
+			// Smooth the edges by a half pixel on the destination.
+			"Smoothing":    0.5 * unit / scale,
+			"Color":        clr,
+			"OutlineColor": premultipliedColor(op.OutlineColor),
+			"OutlineWidth": math.Min(op.OutlineWidth*unit, 0.5),
+			"GlowColor":    premultipliedColor(op.GlowColor),
+			"GlowWidth":    math.Min(op.GlowWidth*unit, 0.5),
+		},
+	}
+
+	var vs []ebiten.Vertex
+	var is []uint16
+	flush := func() {
+		if len(vs) == 0 {
+			return
+		}
+		sop.Images[0] = face.atlas
+		dst.DrawTrianglesShader(vs, is, sdfShader, sop)
+		vs = vs[:0]
+		is = is[:0]
+	}
+
+	// The number of vertices must be within the range of uint16.
+	const maxGlyphsNum = 1 << 14
+
+	spacing := lineSpacing(face.face, nil)
+	fy := fixed.Int26_6(0)
+	for _, l := range layoutLines(face.face, text, nil) {
+		fx := fixed.Int26_6(0)
+		prevC := rune(-1)
+		for _, r := range shapeLine(l) {
+			if prevC >= 0 {
+				fx += face.face.Kern(prevC, r)
+			}
+			prevC = r
+			if g := face.glyph(r, flush); g != nil {
+				x0 := fixed26_6ToFloat64(fx) + float64(g.offsetX)
+				y0 := fixed26_6ToFloat64(fy) + float64(g.offsetY)
+				x1, y1 := x0+float64(g.w), y0+float64(g.h)
+				n := uint16(len(vs))
+				for _, p := range []struct {
+					x  float64
+					y  float64
+					sx int
+					sy int
+				}{
+					{x0, y0, g.x, g.y},
+					{x1, y0, g.x + g.w, g.y},
+					{x0, y1, g.x, g.y + g.h},
+					{x1, y1, g.x + g.w, g.y + g.h},
+				} {
+					dx, dy := op.GeoM.Apply(p.x, p.y)
+					vs = append(vs, ebiten.Vertex{
+						DstX:   float32(dx),
+						DstY:   float32(dy),
+						SrcX:   float32(p.sx),
+						SrcY:   float32(p.sy),
+						ColorR: 1,
+						ColorG: 1,
+						ColorB: 1,
+						ColorA: 1,
+					})
+				}
+				is = append(is, n, n+1, n+2, n+1, n+2, n+3)
+				if len(vs)/4 == maxGlyphsNum {
+					flush()
+				}
+			}
+			adv, _ := face.face.GlyphAdvance(r)
+			fx += adv
+		}
+		fy += spacing
+	}
+	flush()
+}
@@ -22,6 +22,7 @@ package text
 import (
 	"image"
 	"image/color"
+	"image/draw"
 	"math"
 
 	"golang.org/x/image/font"
@@ -89,6 +90,9 @@ type glyph struct {
 	char  char
 	index int
 	atime int64
+
+	// colored reports whether the glyph is drawn with its own colors.
+	colored bool
 }
 
 func fixed26_6ToFloat64(x fixed.Int26_6) float64 {
@@ -110,6 +114,10 @@ func (g *glyph) draw(dst *ebiten.Image, x, y fixed.Int26_6, clr color.Color) {
 	gf := float64(cg) / float64(ca)
 	bf := float64(cb) / float64(ca)
 	af := float64(ca) / 0xffff
+	if g.colored {
+		// Only the alpha is applied to colored glyphs.
+		rf, gf, bf = 1, 1, 1
+	}
 	op.ColorM.Scale(rf, gf, bf, af)
 
 	a := atlases[g.char.atlasGroup()]
@@ -128,9 +136,6 @@ type atlas struct {
 	// image is the back-end image to hold glyph cache.
 	image *ebiten.Image
 
-	// tmpImage is the temporary image as a renderer source for glyph.
-	tmpImage *ebiten.Image
-
 	// glyphSize is the size of one glyph in the cache.
 	// This value is always power of 2.
 	glyphSize int
@@ -185,28 +190,48 @@ func (a *atlas) appendGlyph(face font.Face, rune rune, now int64) *glyph {
 }
 
 func (a *atlas) draw(glyph *glyph) {
-	if a.tmpImage == nil {
-		a.tmpImage, _ = ebiten.NewImage(a.glyphSize, a.glyphSize, ebiten.FilterNearest)
-	}
-
 	dst := image.NewRGBA(image.Rect(0, 0, a.glyphSize, a.glyphSize))
-	d := font.Drawer{
-		Dst:  dst,
-		Src:  image.White,
-		Face: glyph.char.face,
-	}
 	b := glyph.char.bounds()
-	d.Dot = fixed.Point26_6{-b.Min.X, -b.Min.Y}
-	d.DrawString(string(glyph.char.rune))
-	a.tmpImage.ReplacePixels(dst.Pix)
+	if img, ok := coloredGlyph(glyph.char.face, glyph.char.rune); ok {
+		p := img.Bounds().Min.Sub(image.Pt(b.Min.X.Floor(), b.Min.Y.Floor()))
+		draw.Draw(dst, img.Bounds().Sub(img.Bounds().Min).Add(p), img, img.Bounds().Min, draw.Src)
+		glyph.colored = true
+	} else {
+		d := font.Drawer{
+			Dst:  dst,
+			Src:  image.White,
+			Face: glyph.char.face,
+		}
+		d.Dot = fixed.Point26_6{-b.Min.X, -b.Min.Y}
+		d.DrawString(string(glyph.char.rune))
+	}
 
-	op := &ebiten.DrawImageOptions{}
+	// Replace only the glyph's cell. The atlas keeps its pixels on the CPU side
+	// so that the atlas can be restored without reading pixels from GPU.
 	x, y := a.at(glyph)
-	op.GeoM.Translate(float64(x), float64(y))
-	op.CompositeMode = ebiten.CompositeModeCopy
-	a.image.DrawImage(a.tmpImage, op)
+	r := image.Rect(x, y, x+a.glyphSize, y+a.glyphSize)
+	a.image.SubImage(r).(*ebiten.Image).ReplacePixels(dst.Pix)
+}
+
+// ColoredGlyphFace is a font face that has colored glyphs, e.g., color emoji.
+//
+// Colored glyphs are drawn with their own colors, and only the alpha of the text color is applied.
+type ColoredGlyphFace interface {
+	font.Face
+
+	// ColoredGlyph returns the colored image of the glyph for r.
+	// The image's bounds are relative to the dot, and must be included in the bounds by GlyphBounds.
+	//
+	// ColoredGlyph returns false if the glyph for r is not colored.
+	ColoredGlyph(r rune) (image.Image, bool)
+}
 
-	a.tmpImage.Clear()
+func coloredGlyph(face font.Face, r rune) (image.Image, bool) {
+	f, ok := face.(ColoredGlyphFace)
+	if !ok {
+		return nil, false
+	}
+	return f.ColoredGlyph(r)
 }
 
 func getGlyphFromCache(face font.Face, r rune, now int64) *glyph {
@@ -231,15 +256,10 @@ func getGlyphFromCache(face font.Face, r rune, now int64) *glyph {
 
 	if !ok {
 		// Don't use ebiten.MaxImageSize here.
-		// It's because the back-end image pixels will be restored from GPU
-		// whenever a new glyph is rendered on the image, and restoring cost is
-		// expensive if the image is big.
-		// The back-end image is updated a temporary image, and the temporary image is
-		// always cleared after used. This means that there is no clue to restore
-		// the back-end image without reading from GPU
-		// (see the package 'restorable' implementation).
-		//
-		// TODO: How about making a new function for 'flagile' image?
+		// The atlas holds its pixels on CPU side as well as GPU side, and a big
+		// atlas would waste memory for typical texts.
+		// All the atlases are shared among faces: glyphs whose sizes are in the same
+		// group share one atlas.
 		const size = 1024
 		i, _ := ebiten.NewImage(size, size, ebiten.FilterNearest)
 		a = &atlas{
@@ -255,6 +275,22 @@ func getGlyphFromCache(face font.Face, r rune, now int64) *glyph {
 
 var textM sync.Mutex
 
+// CacheGlyphs precaches the glyphs for the given text and the given font face into the glyph atlas.
+//
+// Draw caches the glyphs automatically, but rasterizing glyphs might take time.
+// CacheGlyphs is useful to avoid the delay at the first call of Draw, e.g., while the game is loading.
+//
+// This function is concurrent-safe.
+func CacheGlyphs(face font.Face, text string) {
+	textM.Lock()
+	defer textM.Unlock()
+
+	n := now()
+	for _, c := range text {
+		getGlyphFromCache(face, c, n)
+	}
+}
+
 // Draw draws a given text on a given destination image dst.
 //
 // face is the font for text rendering.
@@ -262,31 +298,35 @@ var textM sync.Mutex
 // Be careful that this doesn't represent left-upper corner position.
 // clr is the color for text rendering.
 //
+// Right-to-left scripts such as Hebrew and Arabic are reordered by a simplified bidirectional algorithm,
+// and Arabic letters are shaped into their contextual forms in Arabic Presentation Forms-B.
+// Scripts that require glyph substitution tables (e.g. Devanagari) and vertical layout are not supported.
+//
 // Glyphs used for rendering are cached in least-recently-used way.
 // It is OK to call this function with a same text and a same face at every frame in terms of performance.
 //
 // This function is concurrent-safe.
 func Draw(dst *ebiten.Image, text string, face font.Face, x, y int, clr color.Color) {
 	textM.Lock()
+	drawLine(dst, text, face, fixed.I(x), fixed.I(y), clr, now())
+	textM.Unlock()
+}
 
-	n := now()
-	fx := fixed.I(x)
+func drawLine(dst *ebiten.Image, text string, face font.Face, x, y fixed.Int26_6, clr color.Color, now int64) {
+	fx := x
 	prevC := rune(-1)
 
-	runes := []rune(text)
-	for _, c := range runes {
+	for _, c := range shapeLine(text) {
 		if prevC >= 0 {
 			fx += face.Kern(prevC, c)
 		}
-		if g := getGlyphFromCache(face, c, n); g != nil {
+		if g := getGlyphFromCache(face, c, now); g != nil {
 			if !g.char.empty() {
-				g.draw(dst, fx, fixed.I(y), clr)
+				g.draw(dst, fx, y, clr)
 			}
 			a, _ := face.GlyphAdvance(c)
 			fx += a
 		}
 		prevC = c
 	}
-
-	textM.Unlock()
 }
@@ -0,0 +1,278 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tilemap offers rendering of grid maps made from a tileset image.
+//
+// A Map consists of layers of tile indices. Each layer's geometry is built per chunk of tiles and is cached
+// until the chunk's tiles are changed, and only the chunks in the visible region are drawn.
+// This is much faster than calling DrawImage for every tile.
+//
+// Note: This package is experimental and API might be changed.
+package tilemap
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// chunkSize is the number of tiles in a row or a column of a chunk.
+const chunkSize = 32
+
+// maxQuadsNum is the maximum number of tiles in one call of DrawTriangles.
+// The number of vertices must be within the range of uint16 indices.
+const maxQuadsNum = (1 << 16) / 4
+
+// Tileset represents a tileset image where tiles of the same size are arranged in a grid.
+//
+// The tiles are indexed from the left-upper corner in row-major order.
+type Tileset struct {
+	// Image is the tileset image.
+	Image *ebiten.Image
+
+	// TileWidth and TileHeight are the size of a tile in pixels.
+	TileWidth  int
+	TileHeight int
+}
+
+func (t *Tileset) sourceRect(index int) image.Rectangle {
+	w, _ := t.Image.Size()
+	n := w / t.TileWidth
+	x := (index % n) * t.TileWidth
+	y := (index / n) * t.TileHeight
+	return image.Rect(x, y, x+t.TileWidth, y+t.TileHeight)
+}
+
+// Map represents a tilemap.
+type Map struct {
+	tileset *Tileset
+	width   int
+	height  int
+	layers  []*Layer
+
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+// NewMap returns a new Map of width x height tiles without layers.
+func NewMap(tileset *Tileset, width, height int) *Map {
+	if tileset.TileWidth <= 0 || tileset.TileHeight <= 0 {
+		panic("tilemap: the tile size must be positive")
+	}
+	if width <= 0 || height <= 0 {
+		panic("tilemap: width and height must be positive")
+	}
+	return &Map{
+		tileset: tileset,
+		width:   width,
+		height:  height,
+	}
+}
+
+// Size returns the size of the map in tiles.
+func (m *Map) Size() (width, height int) {
+	return m.width, m.height
+}
+
+// AddLayer adds a new empty layer on top of the existing layers and returns it.
+func (m *Map) AddLayer() *Layer {
+	tiles := make([]int, m.width*m.height)
+	for i := range tiles {
+		tiles[i] = -1
+	}
+	cw := (m.width + chunkSize - 1) / chunkSize
+	ch := (m.height + chunkSize - 1) / chunkSize
+	l := &Layer{
+		m:         m,
+		tiles:     tiles,
+		chunks:    make([]chunk, cw*ch),
+		ParallaxX: 1,
+		ParallaxY: 1,
+	}
+	for i := range l.chunks {
+		l.chunks[i].dirty = true
+	}
+	m.layers = append(m.layers, l)
+	return l
+}
+
+// Layer represents a layer of a Map.
+type Layer struct {
+	m      *Map
+	tiles  []int
+	chunks []chunk
+
+	// ParallaxX and ParallaxY are the factors of the layer's scrolling against the camera.
+	// 1 means that the layer scrolls with the camera. A smaller value makes the layer look farther.
+	// The default values are 1.
+	ParallaxX float64
+	ParallaxY float64
+
+	// Hidden reports whether the layer is hidden.
+	// The default value is false, which means the layer is drawn.
+	Hidden bool
+}
+
+// chunk is the cached geometry of a chunk of tiles in the map's coordinates.
+type chunk struct {
+	vertices []ebiten.Vertex
+	dirty    bool
+}
+
+// Tile returns the tile index at (x, y). A negative value means no tile.
+//
+// Tile panics if (x, y) is out of the map.
+func (l *Layer) Tile(x, y int) int {
+	if x < 0 || y < 0 || x >= l.m.width || y >= l.m.height {
+		panic("tilemap: the position is out of the map")
+	}
+	return l.tiles[x+y*l.m.width]
+}
+
+// SetTile sets the tile index at (x, y). A negative index means no tile.
+func (l *Layer) SetTile(x, y int, index int) {
+	if x < 0 || y < 0 || x >= l.m.width || y >= l.m.height {
+		panic("tilemap: the position is out of the map")
+	}
+	idx := x + y*l.m.width
+	if l.tiles[idx] == index {
+		return
+	}
+	l.tiles[idx] = index
+	cw := (l.m.width + chunkSize - 1) / chunkSize
+	l.chunks[x/chunkSize+(y/chunkSize)*cw].dirty = true
+}
+
+func (l *Layer) chunk(cx, cy int) *chunk {
+	cw := (l.m.width + chunkSize - 1) / chunkSize
+	c := &l.chunks[cx+cy*cw]
+	if !c.dirty {
+		return c
+	}
+
+	t := l.m.tileset
+	tw, th := float32(t.TileWidth), float32(t.TileHeight)
+	c.vertices = c.vertices[:0]
+	for j := cy * chunkSize; j < (cy+1)*chunkSize && j < l.m.height; j++ {
+		for i := cx * chunkSize; i < (cx+1)*chunkSize && i < l.m.width; i++ {
+			index := l.tiles[i+j*l.m.width]
+			if index < 0 {
+				continue
+			}
+			r := t.sourceRect(index)
+			x, y := float32(i)*tw, float32(j)*th
+			sx0, sy0 := float32(r.Min.X), float32(r.Min.Y)
+			sx1, sy1 := float32(r.Max.X), float32(r.Max.Y)
+			c.vertices = append(c.vertices,
+				ebiten.Vertex{DstX: x, DstY: y, SrcX: sx0, SrcY: sy0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+				ebiten.Vertex{DstX: x + tw, DstY: y, SrcX: sx1, SrcY: sy0, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+				ebiten.Vertex{DstX: x, DstY: y + th, SrcX: sx0, SrcY: sy1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+				ebiten.Vertex{DstX: x + tw, DstY: y + th, SrcX: sx1, SrcY: sy1, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+			)
+		}
+	}
+	c.dirty = false
+	return c
+}
+
+// DrawOptions represents options to draw a Map.
+type DrawOptions struct {
+	// CameraX and CameraY are the position in the map's pixels shown at the left-upper corner of
+	// the destination image.
+	CameraX float64
+	CameraY float64
+
+	// Scale is the scale of the map.
+	// The default (zero) value means 1.
+	Scale float64
+
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	CompositeMode ebiten.CompositeMode
+}
+
+// Draw draws the visible layers of the map on dst from the bottom layer.
+//
+// op can be nil, which means the default options.
+func (m *Map) Draw(dst *ebiten.Image, op *DrawOptions) {
+	if op == nil {
+		op = &DrawOptions{}
+	}
+	scale := op.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	dw, dh := dst.Size()
+	tw := float64(m.tileset.TileWidth) * scale
+	th := float64(m.tileset.TileHeight) * scale
+	cw := (m.width + chunkSize - 1) / chunkSize
+	ch := (m.height + chunkSize - 1) / chunkSize
+
+	top := &ebiten.DrawTrianglesOptions{
+		CompositeMode: op.CompositeMode,
+	}
+	flush := func() {
+		if len(m.vertices) == 0 {
+			return
+		}
+		dst.DrawTriangles(m.vertices, m.indices, m.tileset.Image, top)
+		m.vertices = m.vertices[:0]
+		m.indices = m.indices[:0]
+	}
+
+	for _, l := range m.layers {
+		if l.Hidden {
+			continue
+		}
+		// The camera position in the destination's pixels for this layer.
+		camX := op.CameraX * l.ParallaxX * scale
+		camY := op.CameraY * l.ParallaxY * scale
+
+		// Cull the chunks out of the destination.
+		cx0 := clamp(int(math.Floor(camX/(tw*chunkSize))), 0, cw)
+		cy0 := clamp(int(math.Floor(camY/(th*chunkSize))), 0, ch)
+		cx1 := clamp(int(math.Ceil((camX+float64(dw))/(tw*chunkSize))), 0, cw)
+		cy1 := clamp(int(math.Ceil((camY+float64(dh))/(th*chunkSize))), 0, ch)
+
+		for cy := cy0; cy < cy1; cy++ {
+			for cx := cx0; cx < cx1; cx++ {
+				c := l.chunk(cx, cy)
+				for i := 0; i < len(c.vertices); i += 4 {
+					if len(m.vertices) == 4*maxQuadsNum {
+						flush()
+					}
+					n := uint16(len(m.vertices))
+					for _, v := range c.vertices[i : i+4] {
+						v.DstX = float32(float64(v.DstX)*scale - camX)
+						v.DstY = float32(float64(v.DstY)*scale - camY)
+						m.vertices = append(m.vertices, v)
+					}
+					m.indices = append(m.indices, n, n+1, n+2, n+1, n+2, n+3)
+				}
+			}
+		}
+	}
+	flush()
+}
+
+func clamp(x, min, max int) int {
+	if x < min {
+		return min
+	}
+	if x > max {
+		return max
+	}
+	return x
+}
@@ -0,0 +1,150 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tilemap_test
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/tilemap"
+)
+
+func TestMain(m *testing.M) {
+	code := 0
+	// Run an Ebiten process so that (*Image).At is available.
+	regularTermination := errors.New("regular termination")
+	f := func(screen *ebiten.Image) error {
+		code = m.Run()
+		return regularTermination
+	}
+	if err := ebiten.Run(f, 320, 240, 1, "Test"); err != nil && err != regularTermination {
+		panic(err)
+	}
+	os.Exit(code)
+}
+
+var (
+	red   = color.RGBA{0xff, 0, 0, 0xff}
+	green = color.RGBA{0, 0xff, 0, 0xff}
+	blue  = color.RGBA{0, 0, 0xff, 0xff}
+	white = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+// newTileset returns a tileset of four 2x2 tiles in two rows: red (0), green (1), blue (2) and white (3).
+func newTileset() *Tileset {
+	colors := []color.RGBA{red, green, blue, white}
+	src := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			src.Set(i, j, colors[(j/2)*2+i/2])
+		}
+	}
+	img, _ := ebiten.NewImageFromImage(src, ebiten.FilterNearest)
+	return &Tileset{Image: img, TileWidth: 2, TileHeight: 2}
+}
+
+func TestMapDraw(t *testing.T) {
+	m := NewMap(newTileset(), 100, 100)
+	ground := m.AddLayer()
+	for j := 0; j < 100; j++ {
+		for i := 0; i < 100; i++ {
+			ground.SetTile(i, j, 2)
+		}
+	}
+	top := m.AddLayer()
+	top.SetTile(50, 50, 3)
+	top.SetTile(51, 50, 1)
+	top.SetTile(50, 51, 3)
+	// An empty tile shows the lower layer.
+	top.SetTile(50, 51, -1)
+
+	dst, _ := ebiten.NewImage(16, 16, ebiten.FilterNearest)
+	m.Draw(dst, &DrawOptions{
+		CameraX: 96,
+		CameraY: 96,
+	})
+	cases := []struct {
+		X     int
+		Y     int
+		Color color.RGBA
+	}{
+		{0, 0, blue},
+		{3, 3, blue},
+		{4, 4, white},
+		{5, 5, white},
+		{6, 4, green},
+		{7, 5, green},
+		{4, 6, blue},
+		{15, 15, blue},
+	}
+	for _, c := range cases {
+		if got := dst.At(c.X, c.Y); got != c.Color {
+			t.Errorf("dst.At(%d, %d): got: %v, want: %v", c.X, c.Y, got, c.Color)
+		}
+	}
+
+	// The top layer doesn't scroll with ParallaxX and ParallaxY of 0.
+	dst.Clear()
+	top.ParallaxX = 0
+	top.ParallaxY = 0
+	top.SetTile(1, 1, 0)
+	m.Draw(dst, &DrawOptions{
+		CameraX: 96,
+		CameraY: 96,
+	})
+	if got := dst.At(2, 2); got != red {
+		t.Errorf("dst.At(2, 2): got: %v, want: %v", got, red)
+	}
+	if got := dst.At(4, 4); got != blue {
+		t.Errorf("dst.At(4, 4): got: %v, want: %v", got, blue)
+	}
+}
+
+func TestLayerTile(t *testing.T) {
+	m := NewMap(newTileset(), 3, 2)
+	l := m.AddLayer()
+	l.SetTile(2, 0, 1)
+	l.SetTile(0, 1, 0)
+	if got, want := l.Tile(2, 0), 1; got != want {
+		t.Errorf("l.Tile(2, 0): got: %d, want: %d", got, want)
+	}
+	if got, want := l.Tile(0, 1), 0; got != want {
+		t.Errorf("l.Tile(0, 1): got: %d, want: %d", got, want)
+	}
+
+	// Out-of-range positions must not read a tile in another row.
+	for _, p := range []struct {
+		X int
+		Y int
+	}{
+		{3, 0},
+		{-1, 1},
+		{0, 2},
+		{0, -1},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("l.Tile(%d, %d) must panic but not", p.X, p.Y)
+				}
+			}()
+			l.Tile(p.X, p.Y)
+		}()
+	}
+}
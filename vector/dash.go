@@ -0,0 +1,142 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"math"
+)
+
+// dasher splits subpaths into dashes.
+type dasher struct {
+	pattern []float64
+	offset  float64
+}
+
+// newDasher returns a dasher for the dash array and the offset.
+// If the dash array represents a solid line, newDasher returns nil.
+func newDasher(array []float32, offset float32) *dasher {
+	if len(array) == 0 {
+		return nil
+	}
+	pattern := make([]float64, 0, 2*len(array))
+	total := 0.0
+	for _, v := range array {
+		if v < 0 {
+			return nil
+		}
+		pattern = append(pattern, float64(v))
+		total += float64(v)
+	}
+	if total == 0 {
+		return nil
+	}
+	if len(pattern)%2 != 0 {
+		pattern = append(pattern, pattern...)
+		total *= 2
+	}
+	o := math.Mod(float64(offset), total)
+	if o < 0 {
+		o += total
+	}
+	return &dasher{
+		pattern: pattern,
+		offset:  o,
+	}
+}
+
+// split returns the dashes of the subpath as open subpaths.
+func (d *dasher) split(sp subpath) []subpath {
+	if len(sp.points) < 2 {
+		return nil
+	}
+	points := sp.points
+	if sp.closed {
+		points = append(append([]point{}, points...), points[0])
+	}
+
+	// Find the position in the pattern at the offset.
+	idx := 0
+	remaining := d.pattern[0]
+	for o := d.offset; o > 0; {
+		if o < remaining {
+			remaining -= o
+			break
+		}
+		o -= remaining
+		idx = (idx + 1) % len(d.pattern)
+		remaining = d.pattern[idx]
+	}
+
+	var dashes []subpath
+	var current []point
+	startsWithDash := idx%2 == 0
+	if startsWithDash {
+		current = []point{points[0]}
+	}
+	for i := 0; i < len(points)-1; i++ {
+		a, b := toVec(points[i]), toVec(points[i+1])
+		l := b.sub(a).length()
+		pos := 0.0
+		for l-pos > remaining {
+			pos += remaining
+			pt := a.add(b.sub(a).scale(pos / l)).point()
+			if idx%2 == 0 {
+				// The end of a dash.
+				current = append(current, pt)
+				dashes = append(dashes, subpath{points: current})
+				current = nil
+			} else {
+				// The start of a dash.
+				current = []point{pt}
+			}
+			idx = (idx + 1) % len(d.pattern)
+			remaining = d.pattern[idx]
+		}
+		remaining -= l - pos
+		if idx%2 == 0 {
+			current = append(current, points[i+1])
+		}
+	}
+	if len(current) > 1 {
+		dashes = append(dashes, subpath{points: current})
+	}
+
+	// In a closed subpath, the last dash and the first dash are connected at the start point.
+	if sp.closed && startsWithDash && len(current) > 1 && len(dashes) > 1 {
+		last := dashes[len(dashes)-1]
+		first := dashes[0]
+		merged := append(append([]point{}, last.points...), first.points[1:]...)
+		dashes = append([]subpath{{points: merged}}, dashes[1:len(dashes)-1]...)
+	}
+	return removeDegenerateDashes(dashes)
+}
+
+// removeDegenerateDashes removes zero-length dashes and duplicated points in dashes.
+func removeDegenerateDashes(dashes []subpath) []subpath {
+	result := dashes[:0]
+	for _, d := range dashes {
+		ps := d.points[:1]
+		for _, p := range d.points[1:] {
+			if p != ps[len(ps)-1] {
+				ps = append(ps, p)
+			}
+		}
+		if len(ps) < 2 {
+			continue
+		}
+		result = append(result, subpath{points: ps})
+	}
+	return result
+}
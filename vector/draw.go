@@ -0,0 +1,200 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"image/color"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// maxQuadsNum is the maximum number of trapezoids in one call of DrawTriangles.
+// The number of vertices must be within the range of uint16 indices.
+const maxQuadsNum = (1 << 16) / 4
+
+// FillOptions represents options to fill a path.
+type FillOptions struct {
+	// Color is the color to fill the path.
+	// The default (nil) value is white.
+	Color color.Color
+
+	// FillRule is the rule to decide the inside of the path.
+	// The default (zero) value is FillRuleNonZero.
+	FillRule FillRule
+
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	CompositeMode ebiten.CompositeMode
+}
+
+// StrokeOptions represents options to stroke a path.
+type StrokeOptions struct {
+	// Color is the color to stroke the path.
+	// The default (nil) value is white.
+	Color color.Color
+
+	// Width is the width of the stroke.
+	// If Width is 0 or less, nothing is drawn.
+	Width float32
+
+	// LineJoin is the shape of the joints.
+	// The default (zero) value is LineJoinMiter.
+	LineJoin LineJoin
+
+	// LineCap is the shape of the ends of open subpaths.
+	// The default (zero) value is LineCapButt.
+	LineCap LineCap
+
+	// MiterLimit is the limit of the ratio of the miter length to the half of the width for LineJoinMiter.
+	// The default (zero) value is 10.
+	MiterLimit float32
+
+	// DashArray is the lengths of the dashes and the gaps alternately.
+	// If the number of the elements is odd, the elements are repeated to make it even.
+	// Each dash is capped with LineCap.
+	// The default (nil) value draws a solid line.
+	// If any element is negative or all the elements are 0, a solid line is drawn as well.
+	DashArray []float32
+
+	// DashOffset is the distance into the dash pattern at which the stroke starts.
+	DashOffset float32
+
+	// CompositeMode is a composite mode to draw.
+	// The default (zero) value is regular alpha blending.
+	CompositeMode ebiten.CompositeMode
+}
+
+// Fill fills the area enclosed by the path on dst.
+// All the subpaths are closed implicitly.
+//
+// Fill works for any paths including self-intersecting ones, and each pixel is painted at most once.
+func (p *Path) Fill(dst *ebiten.Image, op *FillOptions) {
+	if op == nil {
+		op = &FillOptions{}
+	}
+	qs := fillQuads(p.edges(), op.FillRule)
+	drawQuads(dst, qs, op.Color, op.CompositeMode)
+}
+
+// Stroke draws the outline of the path on dst.
+//
+// Overlapping parts of the stroke are painted only once, so a translucent stroke looks uniform.
+func (p *Path) Stroke(dst *ebiten.Image, op *StrokeOptions) {
+	if op == nil {
+		op = &StrokeOptions{}
+	}
+	qs := p.strokeQuads(op)
+	drawQuads(dst, qs, op.Color, op.CompositeMode)
+}
+
+func (p *Path) strokeQuads(op *StrokeOptions) []quad {
+	var edges []edge
+	for _, ps := range p.strokePolygons(op) {
+		edges = appendPolygonEdges(edges, ps)
+	}
+	return fillQuads(edges, FillRuleNonZero)
+}
+
+// AppendVerticesAndIndicesForFilling appends the vertices and the indices of the triangles to fill the path,
+// and returns the extended slices.
+//
+// The indices are relative to the first vertex of vertices as DrawTriangles requires.
+// SrcX and SrcY of the vertices are 0, and ColorR, ColorG, ColorB and ColorA are 1.
+//
+// This is useful to fill a path with a custom source image or a shader.
+// Note that the number of the vertices might exceed the limit of DrawTriangles for a complex path.
+func (p *Path) AppendVerticesAndIndicesForFilling(vertices []ebiten.Vertex, indices []uint16, rule FillRule) ([]ebiten.Vertex, []uint16) {
+	return appendQuads(vertices, indices, fillQuads(p.edges(), rule))
+}
+
+// AppendVerticesAndIndicesForStroke appends the vertices and the indices of the triangles to stroke the path,
+// and returns the extended slices.
+//
+// The rules of the vertices and the indices are the same as AppendVerticesAndIndicesForFilling's.
+// op's Color and CompositeMode are ignored.
+func (p *Path) AppendVerticesAndIndicesForStroke(vertices []ebiten.Vertex, indices []uint16, op *StrokeOptions) ([]ebiten.Vertex, []uint16) {
+	if op == nil {
+		op = &StrokeOptions{}
+	}
+	return appendQuads(vertices, indices, p.strokeQuads(op))
+}
+
+func appendQuads(vertices []ebiten.Vertex, indices []uint16, qs []quad) ([]ebiten.Vertex, []uint16) {
+	for _, q := range qs {
+		base := uint16(len(vertices))
+		for _, pt := range q {
+			vertices = append(vertices, ebiten.Vertex{
+				DstX:   pt.x,
+				DstY:   pt.y,
+				ColorR: 1,
+				ColorG: 1,
+				ColorB: 1,
+				ColorA: 1,
+			})
+		}
+		indices = append(indices, base, base+1, base+2, base+1, base+2, base+3)
+	}
+	return vertices, indices
+}
+
+var (
+	whiteImage     *ebiten.Image
+	whiteImageOnce sync.Once
+)
+
+func drawQuads(dst *ebiten.Image, qs []quad, clr color.Color, mode ebiten.CompositeMode) {
+	if len(qs) == 0 {
+		return
+	}
+	whiteImageOnce.Do(func() {
+		whiteImage, _ = ebiten.NewImage(1, 1, ebiten.FilterNearest)
+		_ = whiteImage.Fill(color.White)
+	})
+
+	if clr == nil {
+		clr = color.White
+	}
+	// Vertex colors scale the non-premultiplied colors of the source.
+	r, g, b, a := clr.RGBA()
+	var cr, cg, cb float32
+	if a > 0 {
+		cr = float32(r) / float32(a)
+		cg = float32(g) / float32(a)
+		cb = float32(b) / float32(a)
+	}
+	ca := float32(a) / 0xffff
+
+	op := &ebiten.DrawTrianglesOptions{
+		CompositeMode: mode,
+	}
+	var vs []ebiten.Vertex
+	var is []uint16
+	for len(qs) > 0 {
+		n := len(qs)
+		if n > maxQuadsNum {
+			n = maxQuadsNum
+		}
+		vs, is = appendQuads(vs[:0], is[:0], qs[:n])
+		for i := range vs {
+			vs[i].ColorR = cr
+			vs[i].ColorG = cg
+			vs[i].ColorB = cb
+			vs[i].ColorA = ca
+		}
+		_ = dst.DrawTriangles(vs, is, whiteImage, op)
+		qs = qs[n:]
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"math"
+	"sort"
+)
+
+// FillRule represents the rule to decide whether a point is inside a path.
+type FillRule int
+
+const (
+	// FillRuleNonZero fills a point if the winding number of the path around the point is not zero.
+	FillRuleNonZero FillRule = iota
+
+	// FillRuleEvenOdd fills a point if the winding number of the path around the point is odd.
+	FillRuleEvenOdd
+)
+
+// edge is a line segment of a polygon, whose y0 is less than y1.
+type edge struct {
+	x0 float32
+	y0 float32
+	x1 float32
+	y1 float32
+
+	// winding is 1 if the original segment goes down, and -1 otherwise.
+	winding int
+}
+
+// xAt returns the x coordinate of the edge at y.
+func (e *edge) xAt(y float32) float32 {
+	if y <= e.y0 {
+		return e.x0
+	}
+	if y >= e.y1 {
+		return e.x1
+	}
+	return e.x0 + (e.x1-e.x0)*(y-e.y0)/(e.y1-e.y0)
+}
+
+// quad is a trapezoid with horizontal top and bottom sides.
+// The points are the top-left, top-right, bottom-left and bottom-right in this order.
+type quad [4]point
+
+// appendPolygonEdges appends the edges of the polygon that the points form.
+// The polygon is closed implicitly.
+func appendPolygonEdges(edges []edge, points []point) []edge {
+	for i := range points {
+		a := points[i]
+		b := points[(i+1)%len(points)]
+		switch {
+		case a.y < b.y:
+			edges = append(edges, edge{x0: a.x, y0: a.y, x1: b.x, y1: b.y, winding: 1})
+		case a.y > b.y:
+			edges = append(edges, edge{x0: b.x, y0: b.y, x1: a.x, y1: a.y, winding: -1})
+		}
+		// Horizontal edges don't affect the filling.
+	}
+	return edges
+}
+
+// edges returns the edges of the path for filling. All the subpaths are closed implicitly.
+func (p *Path) edges() []edge {
+	var edges []edge
+	for _, s := range p.subpaths {
+		if len(s.points) < 3 {
+			continue
+		}
+		edges = appendPolygonEdges(edges, s.points)
+	}
+	return edges
+}
+
+// fillQuads decomposes the area enclosed by the edges into trapezoids.
+//
+// The area is split into horizontal bands at every end point and every intersection of the edges.
+// In each band, no edges cross, so the filled spans between the edges are trapezoids.
+func fillQuads(edges []edge, rule FillRule) []quad {
+	if len(edges) == 0 {
+		return nil
+	}
+	ys := make([]float32, 0, 2*len(edges))
+	for _, e := range edges {
+		ys = append(ys, e.y0, e.y1)
+	}
+	sort.Sort(float32s(ys))
+	sort.Sort(edgesByY0(edges))
+
+	var quads []quad
+	var active []*edge
+	next := 0
+	for k := 0; k < len(ys)-1; k++ {
+		top, bottom := ys[k], ys[k+1]
+		if top == bottom {
+			continue
+		}
+		for next < len(edges) && edges[next].y0 <= top {
+			active = append(active, &edges[next])
+			next++
+		}
+		n := 0
+		for _, e := range active {
+			if e.y1 > top {
+				active[n] = e
+				n++
+			}
+		}
+		active = active[:n]
+		quads = appendBandQuads(quads, active, top, bottom, rule)
+	}
+	return quads
+}
+
+type bandEdge struct {
+	edge   *edge
+	top    float32
+	bottom float32
+}
+
+type float32s []float32
+
+func (f float32s) Len() int           { return len(f) }
+func (f float32s) Less(i, j int) bool { return f[i] < f[j] }
+func (f float32s) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+
+type edgesByY0 []edge
+
+func (e edgesByY0) Len() int           { return len(e) }
+func (e edgesByY0) Less(i, j int) bool { return e[i].y0 < e[j].y0 }
+func (e edgesByY0) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
+// bandEdges is sorted by the x coordinates at the top, and then at the bottom.
+type bandEdges []bandEdge
+
+func (b bandEdges) Len() int { return len(b) }
+
+func (b bandEdges) Less(i, j int) bool {
+	if b[i].top != b[j].top {
+		return b[i].top < b[j].top
+	}
+	return b[i].bottom < b[j].bottom
+}
+
+func (b bandEdges) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+
+// minBandHeight is the minimum height of a band split at an intersection.
+const minBandHeight = 1.0 / 256
+
+// appendBandQuads appends the trapezoids in the band between top and bottom.
+// All the edges must span the band.
+func appendBandQuads(quads []quad, edges []*edge, top, bottom float32, rule FillRule) []quad {
+	es := make([]bandEdge, len(edges))
+	for top < bottom {
+		for i, e := range edges {
+			es[i] = bandEdge{edge: e, top: e.xAt(top), bottom: e.xAt(bottom)}
+		}
+		sort.Sort(bandEdges(es))
+
+		// The first intersection in the band is between edges adjacent at the top.
+		// Split the band there.
+		b := bottom
+		for i := 0; i < len(es)-1; i++ {
+			e0, e1 := es[i], es[i+1]
+			if e0.bottom <= e1.bottom {
+				continue
+			}
+			// Solve e0.top + (e0.bottom-e0.top)*t = e1.top + (e1.bottom-e1.top)*t.
+			t := (e1.top - e0.top) / ((e0.bottom - e0.top) - (e1.bottom - e1.top))
+			y := top + (bottom-top)*t
+			if y-top < minBandHeight {
+				y = top + minBandHeight
+			}
+			if y <= top {
+				// minBandHeight can be lost in the float32 precision with large coordinates.
+				y = math.Nextafter32(top, bottom)
+			}
+			if y < b {
+				b = y
+			}
+		}
+		if b < bottom {
+			for i := range es {
+				es[i].bottom = es[i].edge.xAt(b)
+			}
+		}
+
+		winding := 0
+		for i := 0; i < len(es)-1; i++ {
+			winding += es[i].edge.winding
+			if !isInside(winding, rule) {
+				continue
+			}
+			l, r := es[i], es[i+1]
+			quads = append(quads, quad{
+				{l.top, top},
+				{r.top, top},
+				{l.bottom, b},
+				{r.bottom, b},
+			})
+		}
+		top = b
+	}
+	return quads
+}
+
+func isInside(winding int, rule FillRule) bool {
+	if rule == FillRuleEvenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
@@ -0,0 +1,239 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vector provides functions to build paths of lines, curves and arcs,
+// and to fill or stroke them onto images.
+//
+// Paths are converted into triangles and drawn with (*ebiten.Image).DrawTriangles.
+// The edges are not anti-aliased unless the destination image is created with NewImageOptions.Antialias.
+package vector
+
+import (
+	"math"
+)
+
+// flatness is the maximum distance in pixels between a curve and the line segments approximating it.
+const flatness = 0.25
+
+// maxSubdivisionLevel is the maximum level to subdivide a curve.
+const maxSubdivisionLevel = 16
+
+// maxArcSegmentsNum is the maximum number of line segments to approximate an arc.
+// Without this limit, an arc with a huge radius would require a huge number of vertices.
+const maxArcSegmentsNum = 1024
+
+type point struct {
+	x float32
+	y float32
+}
+
+type subpath struct {
+	points []point
+	closed bool
+}
+
+// Path represents a collection of subpaths, each of which is a sequence of connected line segments.
+//
+// The zero value is an empty path.
+type Path struct {
+	subpaths []subpath
+}
+
+// MoveTo starts a new subpath at (x, y).
+func (p *Path) MoveTo(x, y float32) {
+	p.subpaths = append(p.subpaths, subpath{
+		points: []point{{x, y}},
+	})
+}
+
+// LineTo adds a line segment from the current point to (x, y).
+//
+// If there is no current point, LineTo works in the same way as MoveTo.
+func (p *Path) LineTo(x, y float32) {
+	p.lineTo(point{x, y})
+}
+
+func (p *Path) lineTo(pt point) {
+	if len(p.subpaths) == 0 {
+		p.MoveTo(pt.x, pt.y)
+		return
+	}
+	s := &p.subpaths[len(p.subpaths)-1]
+	if s.closed {
+		// A new subpath starts at the start point of the closed subpath.
+		p.subpaths = append(p.subpaths, subpath{
+			points: []point{s.points[0], pt},
+		})
+		return
+	}
+	if s.points[len(s.points)-1] == pt {
+		return
+	}
+	s.points = append(s.points, pt)
+}
+
+// currentPoint returns the current point.
+// If there is no current point, currentPoint returns false.
+func (p *Path) currentPoint() (point, bool) {
+	if len(p.subpaths) == 0 {
+		return point{}, false
+	}
+	s := p.subpaths[len(p.subpaths)-1]
+	if s.closed {
+		return s.points[0], true
+	}
+	return s.points[len(s.points)-1], true
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to (x, y) with the control point (cpx, cpy).
+//
+// If there is no current point, the curve starts at the control point.
+func (p *Path) QuadTo(cpx, cpy, x, y float32) {
+	p0, ok := p.currentPoint()
+	if !ok {
+		p0 = point{cpx, cpy}
+		p.lineTo(p0)
+	}
+	p.quadTo(p0, point{cpx, cpy}, point{x, y}, 0)
+}
+
+func (p *Path) quadTo(p0, p1, p2 point, level int) {
+	if level >= maxSubdivisionLevel || distanceFromLine(p1, p0, p2) <= flatness {
+		p.lineTo(p2)
+		return
+	}
+	p01 := midpoint(p0, p1)
+	p12 := midpoint(p1, p2)
+	m := midpoint(p01, p12)
+	p.quadTo(p0, p01, m, level+1)
+	p.quadTo(m, p12, p2, level+1)
+}
+
+// CubicTo adds a cubic Bézier curve from the current point to (x, y) with the control points (cp0x, cp0y) and (cp1x, cp1y).
+//
+// If there is no current point, the curve starts at the first control point.
+func (p *Path) CubicTo(cp0x, cp0y, cp1x, cp1y, x, y float32) {
+	p0, ok := p.currentPoint()
+	if !ok {
+		p0 = point{cp0x, cp0y}
+		p.lineTo(p0)
+	}
+	p.cubicTo(p0, point{cp0x, cp0y}, point{cp1x, cp1y}, point{x, y}, 0)
+}
+
+func (p *Path) cubicTo(p0, p1, p2, p3 point, level int) {
+	if level >= maxSubdivisionLevel || (distanceFromLine(p1, p0, p3) <= flatness && distanceFromLine(p2, p0, p3) <= flatness) {
+		p.lineTo(p3)
+		return
+	}
+	p01 := midpoint(p0, p1)
+	p12 := midpoint(p1, p2)
+	p23 := midpoint(p2, p3)
+	p012 := midpoint(p01, p12)
+	p123 := midpoint(p12, p23)
+	m := midpoint(p012, p123)
+	p.cubicTo(p0, p01, p012, m, level+1)
+	p.cubicTo(m, p123, p23, p3, level+1)
+}
+
+// Direction represents the direction to draw an arc.
+type Direction int
+
+const (
+	// Clockwise represents the direction in which the angle increases.
+	// Since the Y axis points down, the arc goes clockwise on the screen.
+	Clockwise Direction = iota
+
+	// CounterClockwise represents the direction in which the angle decreases.
+	CounterClockwise
+)
+
+// Arc adds a circular arc centered at (x, y) with the radius from startAngle to endAngle in the direction dir.
+// The angles are in radians.
+//
+// If there is a current point, a line segment from the current point to the start point of the arc is also added.
+func (p *Path) Arc(x, y, radius, startAngle, endAngle float32, dir Direction) {
+	start := float64(startAngle)
+	sweep := float64(endAngle - startAngle)
+	if math.IsNaN(sweep) || math.IsInf(sweep, 0) {
+		return
+	}
+	if dir == Clockwise {
+		for sweep < 0 {
+			sweep += 2 * math.Pi
+		}
+		if sweep > 2*math.Pi {
+			sweep = 2 * math.Pi
+		}
+	} else {
+		for sweep > 0 {
+			sweep -= 2 * math.Pi
+		}
+		if sweep < -2*math.Pi {
+			sweep = -2 * math.Pi
+		}
+	}
+
+	r := float64(radius)
+	n := arcSegmentsNum(r, math.Abs(sweep))
+	for i := 0; i <= n; i++ {
+		theta := start + sweep*float64(i)/float64(n)
+		p.lineTo(point{
+			x: x + float32(r*math.Cos(theta)),
+			y: y + float32(r*math.Sin(theta)),
+		})
+	}
+}
+
+// arcSegmentsNum returns the number of line segments to approximate an arc with the radius r and the angle sweep.
+func arcSegmentsNum(r, sweep float64) int {
+	if r <= flatness || math.IsNaN(r) || math.IsNaN(sweep) {
+		return 1
+	}
+	step := 2 * math.Acos(1-flatness/r)
+	n := math.Ceil(sweep/step) + 1
+	if !(n <= maxArcSegmentsNum) {
+		return maxArcSegmentsNum
+	}
+	return int(n)
+}
+
+// Close closes the current subpath by adding a line segment from the current point to the start point of the subpath.
+//
+// After Close, the current point is the start point of the closed subpath.
+func (p *Path) Close() {
+	if len(p.subpaths) == 0 {
+		return
+	}
+	s := &p.subpaths[len(p.subpaths)-1]
+	if len(s.points) > 1 && s.points[0] == s.points[len(s.points)-1] {
+		s.points = s.points[:len(s.points)-1]
+	}
+	s.closed = true
+}
+
+func midpoint(a, b point) point {
+	return point{(a.x + b.x) / 2, (a.y + b.y) / 2}
+}
+
+// distanceFromLine returns the distance between p and the line through a and b.
+func distanceFromLine(p, a, b point) float32 {
+	dx := float64(b.x - a.x)
+	dy := float64(b.y - a.y)
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return float32(math.Hypot(float64(p.x-a.x), float64(p.y-a.y)))
+	}
+	return float32(math.Abs(dx*float64(a.y-p.y)-dy*float64(a.x-p.x)) / l)
+}
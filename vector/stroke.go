@@ -0,0 +1,229 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"math"
+)
+
+// LineJoin represents the shape of the joint of two line segments in a stroke.
+type LineJoin int
+
+const (
+	// LineJoinMiter extends the outer edges of the segments until they meet.
+	// If the miter is longer than the miter limit, LineJoinBevel is used instead.
+	LineJoinMiter LineJoin = iota
+
+	// LineJoinBevel connects the outer corners of the segments with a straight line.
+	LineJoinBevel
+
+	// LineJoinRound rounds the joint.
+	LineJoinRound
+)
+
+// LineCap represents the shape of the ends of an open subpath in a stroke.
+type LineCap int
+
+const (
+	// LineCapButt ends the stroke exactly at the end points.
+	LineCapButt LineCap = iota
+
+	// LineCapSquare extends the stroke by the half of the width beyond the end points.
+	LineCapSquare
+
+	// LineCapRound rounds the ends.
+	LineCapRound
+)
+
+// defaultMiterLimit is the miter limit used when StrokeOptions.MiterLimit is 0.
+const defaultMiterLimit = 10
+
+// strokePolygons returns the polygons that the stroke of the path consists of.
+// Each polygon is oriented in the same direction so that the union of them is filled with the non-zero rule.
+func (p *Path) strokePolygons(op *StrokeOptions) [][]point {
+	if op.Width <= 0 {
+		return nil
+	}
+	miterLimit := op.MiterLimit
+	if miterLimit == 0 {
+		miterLimit = defaultMiterLimit
+	}
+	s := &stroker{
+		halfWidth:  float64(op.Width) / 2,
+		lineJoin:   op.LineJoin,
+		lineCap:    op.LineCap,
+		miterLimit: float64(miterLimit),
+	}
+	d := newDasher(op.DashArray, op.DashOffset)
+	for _, sp := range p.subpaths {
+		if d == nil {
+			s.strokeSubpath(sp)
+			continue
+		}
+		for _, dash := range d.split(sp) {
+			s.strokeSubpath(dash)
+		}
+	}
+	return s.polygons
+}
+
+type vec struct {
+	x float64
+	y float64
+}
+
+func (v vec) add(o vec) vec       { return vec{v.x + o.x, v.y + o.y} }
+func (v vec) sub(o vec) vec       { return vec{v.x - o.x, v.y - o.y} }
+func (v vec) scale(s float64) vec { return vec{v.x * s, v.y * s} }
+func (v vec) dot(o vec) float64   { return v.x*o.x + v.y*o.y }
+func (v vec) cross(o vec) float64 { return v.x*o.y - v.y*o.x }
+func (v vec) length() float64     { return math.Hypot(v.x, v.y) }
+func (v vec) normal() vec         { return vec{-v.y, v.x} }
+func (v vec) point() point        { return point{float32(v.x), float32(v.y)} }
+func toVec(p point) vec           { return vec{float64(p.x), float64(p.y)} }
+func (v vec) normalize() vec      { return v.scale(1 / v.length()) }
+func (v vec) rotate(theta float64) vec {
+	s, c := math.Sincos(theta)
+	return vec{v.x*c - v.y*s, v.x*s + v.y*c}
+}
+
+func isFinite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
+
+type stroker struct {
+	halfWidth  float64
+	lineJoin   LineJoin
+	lineCap    LineCap
+	miterLimit float64
+	polygons   [][]point
+}
+
+func (s *stroker) addPolygon(vs ...vec) {
+	area := 0.0
+	for i := range vs {
+		area += vs[i].cross(vs[(i+1)%len(vs)])
+	}
+	if area == 0 {
+		return
+	}
+	ps := make([]point, len(vs))
+	for i, v := range vs {
+		ps[i] = v.point()
+	}
+	if area < 0 {
+		for i, j := 0, len(ps)-1; i < j; i, j = i+1, j-1 {
+			ps[i], ps[j] = ps[j], ps[i]
+		}
+	}
+	s.polygons = append(s.polygons, ps)
+}
+
+func (s *stroker) strokeSubpath(sp subpath) {
+	ps := make([]vec, len(sp.points))
+	for i, p := range sp.points {
+		ps[i] = toVec(p)
+	}
+	if len(ps) < 2 {
+		return
+	}
+	n := len(ps) - 1
+	if sp.closed {
+		n = len(ps)
+	}
+	for i := 0; i < n; i++ {
+		a, b := ps[i], ps[(i+1)%len(ps)]
+		nv := b.sub(a).normalize().normal().scale(s.halfWidth)
+		s.addPolygon(a.add(nv), b.add(nv), b.sub(nv), a.sub(nv))
+	}
+
+	// Joins
+	for i := 0; i < len(ps); i++ {
+		if !sp.closed && (i == 0 || i == len(ps)-1) {
+			continue
+		}
+		prev := ps[(i+len(ps)-1)%len(ps)]
+		next := ps[(i+1)%len(ps)]
+		s.join(ps[i], ps[i].sub(prev).normalize(), next.sub(ps[i]).normalize())
+	}
+
+	// Caps
+	if !sp.closed {
+		s.cap(ps[0], ps[0].sub(ps[1]).normalize())
+		s.cap(ps[len(ps)-1], ps[len(ps)-1].sub(ps[len(ps)-2]).normalize())
+	}
+}
+
+// join adds the joint at p between the segments in the directions d0 and d1.
+func (s *stroker) join(p, d0, d1 vec) {
+	cross := d0.cross(d1)
+	if math.Abs(cross) < 1e-9 && d0.dot(d1) > 0 {
+		// The segments are straight.
+		return
+	}
+	// The joint is on the outer side of the turn.
+	n0 := d0.normal().scale(s.halfWidth)
+	n1 := d1.normal().scale(s.halfWidth)
+	if cross > 0 {
+		n0 = n0.scale(-1)
+		n1 = n1.scale(-1)
+	}
+
+	switch s.lineJoin {
+	case LineJoinMiter:
+		m := n0.add(n1)
+		if l := m.length(); l > 0 {
+			// The ratio of the miter length to the half width is 1 / cos(phi / 2), where phi is the angle between the normals.
+			if ratio := 2 * s.halfWidth / l; ratio <= s.miterLimit {
+				miter := m.scale(2 * s.halfWidth * s.halfWidth / (l * l))
+				s.addPolygon(p, p.add(n0), p.add(miter), p.add(n1))
+				return
+			}
+		}
+		s.addPolygon(p, p.add(n0), p.add(n1))
+	case LineJoinBevel:
+		s.addPolygon(p, p.add(n0), p.add(n1))
+	case LineJoinRound:
+		theta := math.Atan2(n0.cross(n1), n0.dot(n1))
+		s.addArc(p, n0, theta)
+	}
+}
+
+// cap adds the cap at the end point p where the stroke goes in the direction d.
+func (s *stroker) cap(p, d vec) {
+	n := d.normal().scale(s.halfWidth)
+	switch s.lineCap {
+	case LineCapButt:
+	case LineCapSquare:
+		e := d.scale(s.halfWidth)
+		s.addPolygon(p.add(n), p.add(n).add(e), p.sub(n).add(e), p.sub(n))
+	case LineCapRound:
+		s.addArc(p, n, -math.Pi)
+	}
+}
+
+// addArc adds a fan centered at p from the vector v rotating by theta.
+func (s *stroker) addArc(p, v vec, theta float64) {
+	if !isFinite(theta) || !isFinite(p.x) || !isFinite(p.y) || !isFinite(v.x) || !isFinite(v.y) {
+		return
+	}
+	num := arcSegmentsNum(s.halfWidth, math.Abs(theta))
+	vs := make([]vec, 0, num+2)
+	vs = append(vs, p)
+	for i := 0; i <= num; i++ {
+		vs = append(vs, p.add(v.rotate(theta*float64(i)/float64(num))))
+	}
+	s.addPolygon(vs...)
+}
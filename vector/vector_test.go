@@ -0,0 +1,265 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	. "github.com/hajimehoshi/ebiten/vector"
+)
+
+// area returns the total area of the triangles.
+func area(vertices []ebiten.Vertex, indices []uint16) float64 {
+	a := 0.0
+	for i := 0; i < len(indices); i += 3 {
+		v0, v1, v2 := vertices[indices[i]], vertices[indices[i+1]], vertices[indices[i+2]]
+		x1, y1 := float64(v1.DstX-v0.DstX), float64(v1.DstY-v0.DstY)
+		x2, y2 := float64(v2.DstX-v0.DstX), float64(v2.DstY-v0.DstY)
+		a += math.Abs(x1*y2-x2*y1) / 2
+	}
+	return a
+}
+
+func rect(p *Path, x, y, w, h float32) {
+	p.MoveTo(x, y)
+	p.LineTo(x+w, y)
+	p.LineTo(x+w, y+h)
+	p.LineTo(x, y+h)
+	p.Close()
+}
+
+func fillArea(p *Path, rule FillRule) float64 {
+	return area(p.AppendVerticesAndIndicesForFilling(nil, nil, rule))
+}
+
+func strokeArea(p *Path, op *StrokeOptions) float64 {
+	return area(p.AppendVerticesAndIndicesForStroke(nil, nil, op))
+}
+
+func near(a, b, delta float64) bool {
+	return math.Abs(a-b) <= delta
+}
+
+func TestFillRect(t *testing.T) {
+	var p Path
+	rect(&p, 10, 20, 30, 40)
+	if got, want := fillArea(&p, FillRuleNonZero), 30.0*40; !near(got, want, 1e-3) {
+		t.Errorf("got: %f, want: %f", got, want)
+	}
+}
+
+func TestFillRules(t *testing.T) {
+	// Two overlapping squares in the same direction.
+	var p Path
+	rect(&p, 0, 0, 20, 20)
+	rect(&p, 10, 10, 20, 20)
+	if got, want := fillArea(&p, FillRuleNonZero), 700.0; !near(got, want, 1e-3) {
+		t.Errorf("non-zero: got: %f, want: %f", got, want)
+	}
+	if got, want := fillArea(&p, FillRuleEvenOdd), 600.0; !near(got, want, 1e-3) {
+		t.Errorf("even-odd: got: %f, want: %f", got, want)
+	}
+}
+
+func TestFillSelfIntersecting(t *testing.T) {
+	// A bow tie: two triangles meeting at (10, 10).
+	var p Path
+	p.MoveTo(0, 0)
+	p.LineTo(20, 20)
+	p.LineTo(20, 0)
+	p.LineTo(0, 20)
+	p.Close()
+	if got, want := fillArea(&p, FillRuleNonZero), 200.0; !near(got, want, 1e-2) {
+		t.Errorf("got: %f, want: %f", got, want)
+	}
+}
+
+func TestFillCircle(t *testing.T) {
+	var p Path
+	p.Arc(50, 50, 40, 0, 2*math.Pi, Clockwise)
+	want := math.Pi * 40 * 40
+	if got := fillArea(&p, FillRuleNonZero); !near(got, want, want*0.01) {
+		t.Errorf("got: %f, want: %f", got, want)
+	}
+}
+
+func TestFillCurves(t *testing.T) {
+	// The curve is a parabola whose vertex is (0, 0).
+	// The area between a parabola and its chord is 2/3 of the enclosing rectangle.
+	var p Path
+	p.MoveTo(-10, 10)
+	p.QuadTo(0, -10, 10, 10)
+	p.Close()
+	want := 2.0 / 3 * 20 * 10
+	if got := fillArea(&p, FillRuleNonZero); !near(got, want, want*0.02) {
+		t.Errorf("quadratic: got: %f, want: %f", got, want)
+	}
+
+	// The curve is (100(3t^2 - 2t^3), 300t(1-t)), and the area between the curve and the chord is
+	// the integral of 300t(1-t) * 100(6t - 6t^2) for 0 <= t <= 1.
+	var p2 Path
+	p2.MoveTo(0, 0)
+	p2.CubicTo(0, 100, 100, 100, 100, 0)
+	p2.Close()
+	want = 6000
+	if got := fillArea(&p2, FillRuleNonZero); !near(got, want, want*0.01) {
+		t.Errorf("cubic: got: %f, want: %f", got, want)
+	}
+}
+
+func TestStrokeLine(t *testing.T) {
+	var p Path
+	p.MoveTo(10, 10)
+	p.LineTo(40, 50)
+
+	cases := []struct {
+		cap  LineCap
+		want float64
+	}{
+		{LineCapButt, 50 * 4},
+		{LineCapSquare, 54 * 4},
+		{LineCapRound, 50*4 + math.Pi*2*2},
+	}
+	for _, c := range cases {
+		got := strokeArea(&p, &StrokeOptions{Width: 4, LineCap: c.cap})
+		if !near(got, c.want, c.want*0.01) {
+			t.Errorf("cap %d: got: %f, want: %f", c.cap, got, c.want)
+		}
+	}
+}
+
+func TestStrokeRect(t *testing.T) {
+	var p Path
+	rect(&p, 10, 10, 20, 20)
+
+	cases := []struct {
+		join LineJoin
+		want float64
+	}{
+		// The outer square minus the inner square.
+		{LineJoinMiter, 24*24 - 16*16},
+		// Each corner of the outer square lacks a right triangle.
+		{LineJoinBevel, 24*24 - 16*16 - 4*2},
+		// Each corner of the outer square lacks a square minus a quarter circle.
+		{LineJoinRound, 24*24 - 16*16 - (16 - 4*math.Pi)},
+	}
+	for _, c := range cases {
+		got := strokeArea(&p, &StrokeOptions{Width: 4, LineJoin: c.join})
+		if !near(got, c.want, c.want*0.01) {
+			t.Errorf("join %d: got: %f, want: %f", c.join, got, c.want)
+		}
+	}
+}
+
+func TestStrokeMiterLimit(t *testing.T) {
+	// A sharp turn whose miter is longer than the limit is beveled.
+	var p Path
+	p.MoveTo(0, 0)
+	p.LineTo(100, 0)
+	p.LineTo(0, 10)
+	miter := strokeArea(&p, &StrokeOptions{Width: 2, LineJoin: LineJoinMiter, MiterLimit: 100})
+	limited := strokeArea(&p, &StrokeOptions{Width: 2, LineJoin: LineJoinMiter})
+	bevel := strokeArea(&p, &StrokeOptions{Width: 2, LineJoin: LineJoinBevel})
+	if !(miter > limited) {
+		t.Errorf("miter: %f must be greater than limited: %f", miter, limited)
+	}
+	if !near(limited, bevel, 1e-3) {
+		t.Errorf("limited: got: %f, want: %f", limited, bevel)
+	}
+}
+
+func TestStrokeZeroWidth(t *testing.T) {
+	var p Path
+	p.MoveTo(0, 0)
+	p.LineTo(10, 0)
+	vs, is := p.AppendVerticesAndIndicesForStroke(nil, nil, &StrokeOptions{})
+	if len(vs) != 0 || len(is) != 0 {
+		t.Errorf("got: %d vertices and %d indices, want: none", len(vs), len(is))
+	}
+}
+
+func TestStrokeDash(t *testing.T) {
+	var p Path
+	p.MoveTo(0, 0)
+	p.LineTo(100, 0)
+
+	cases := []struct {
+		array  []float32
+		offset float32
+		cap    LineCap
+		want   float64
+	}{
+		{nil, 0, LineCapButt, 100 * 2},
+		{[]float32{10, 10}, 0, LineCapButt, 50 * 2},
+		{[]float32{10}, 0, LineCapButt, 50 * 2},
+		{[]float32{10, 10}, 15, LineCapButt, 50 * 2},
+		{[]float32{10, 30}, 0, LineCapButt, 30 * 2},
+		{[]float32{10, 30}, 0, LineCapSquare, 30*2 + 3*2*2},
+		{[]float32{0, 0}, 0, LineCapButt, 100 * 2},
+		{[]float32{10, -10}, 0, LineCapButt, 100 * 2},
+	}
+	for _, c := range cases {
+		got := strokeArea(&p, &StrokeOptions{Width: 2, LineCap: c.cap, DashArray: c.array, DashOffset: c.offset})
+		if !near(got, c.want, 1e-3) {
+			t.Errorf("dash %v, offset %f, cap %d: got: %f, want: %f", c.array, c.offset, c.cap, got, c.want)
+		}
+	}
+}
+
+func TestStrokeDashClosed(t *testing.T) {
+	// Each dash crosses a corner of the square, including the start point.
+	// The dash crossing the start point must be joined with the miter.
+	var p Path
+	rect(&p, 0, 0, 20, 20)
+	got := strokeArea(&p, &StrokeOptions{Width: 2, DashArray: []float32{10, 10}, DashOffset: 5})
+	if want := 4 * 20.0; !near(got, want, 1e-3) {
+		t.Errorf("got: %f, want: %f", got, want)
+	}
+}
+
+func TestStrokeRoundNonFinite(t *testing.T) {
+	// Non-finite coordinates or a huge width must not panic nor allocate without bound.
+	cases := []struct {
+		x     float32
+		width float32
+	}{
+		{float32(math.NaN()), 4},
+		{float32(math.Inf(1)), 4},
+		{10, float32(math.NaN())},
+		{10, 1e9},
+	}
+	for _, c := range cases {
+		var p Path
+		p.MoveTo(0, 0)
+		p.LineTo(c.x, 0)
+		p.LineTo(c.x, 10)
+		vs, _ := p.AppendVerticesAndIndicesForStroke(nil, nil, &StrokeOptions{
+			Width:    c.width,
+			LineJoin: LineJoinRound,
+			LineCap:  LineCapRound,
+		})
+		// The vertices must be indexable with uint16.
+		if len(vs) > 1<<16 {
+			t.Errorf("x: %f, width: %f: too many vertices: %d", c.x, c.width, len(vs))
+		}
+	}
+
+	var p Path
+	p.Arc(0, 0, 1e9, 0, 2*math.Pi, Clockwise)
+	p.Arc(0, 0, 10, 0, float32(math.Inf(1)), Clockwise)
+	p.Arc(0, 0, 10, float32(math.NaN()), 0, CounterClockwise)
+}
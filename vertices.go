@@ -30,6 +30,9 @@ var (
 	theVerticesBackend = &verticesBackend{}
 )
 
+// quadIndices are the indices to draw a quadrangle returned by vertices.
+var quadIndices = []uint16{0, 1, 2, 1, 2, 3}
+
 type verticesBackend struct {
 	backend []float32
 	head    int
@@ -40,7 +43,8 @@ func (v *verticesBackend) get() []float32 {
 	if v.backend == nil {
 		v.backend = make([]float32, quadFloat32Num*num)
 	}
-	s := v.backend[v.head : v.head+quadFloat32Num]
+	// Limit the capacity so that appending to the returned slice doesn't overwrite the next vertices.
+	s := v.backend[v.head : v.head+quadFloat32Num : v.head+quadFloat32Num]
 	v.head += quadFloat32Num
 	if v.head+quadFloat32Num > len(v.backend) {
 		v.backend = nil
@@ -56,12 +60,6 @@ func vertices(sx0, sy0, sx1, sy1 int, width, height int, geo *affine.GeoM) []flo
 	// TODO: This function should be in graphics package?
 	vs := theVerticesBackend.get()
 	a, b, c, d, tx, ty := geo.Elements()
-	g0 := float32(a)
-	g1 := float32(b)
-	g2 := float32(c)
-	g3 := float32(d)
-	g4 := float32(tx)
-	g5 := float32(ty)
 	w := 1
 	h := 1
 	for w < width {
@@ -72,56 +70,30 @@ func vertices(sx0, sy0, sx1, sy1 int, width, height int, geo *affine.GeoM) []flo
 	}
 	wf := float32(w)
 	hf := float32(h)
-	x0, y0, x1, y1 := float32(0), float32(0), float32(sx1-sx0), float32(sy1-sy0)
+	x0, y0, x1, y1 := float64(0), float64(0), float64(sx1-sx0), float64(sy1-sy0)
 	u0, v0, u1, v1 := float32(sx0)/wf, float32(sy0)/hf, float32(sx1)/wf, float32(sy1)/hf
 	// Adjust texels to fix a problem that outside texels are used (#317).
 	if texelAdjustment > 0 {
 		u1 -= 1.0 / wf / texelAdjustment
 		v1 -= 1.0 / hf / texelAdjustment
 	}
-	vs[0] = x0
-	vs[1] = y0
-	vs[2] = u0
-	vs[3] = v0
-	vs[4] = g0
-	vs[5] = g1
-	vs[6] = g2
-	vs[7] = g3
-	vs[8] = g4
-	vs[9] = g5
-
-	vs[10] = x1
-	vs[11] = y0
-	vs[12] = u1
-	vs[13] = v0
-	vs[14] = g0
-	vs[15] = g1
-	vs[16] = g2
-	vs[17] = g3
-	vs[18] = g4
-	vs[19] = g5
-
-	vs[20] = x0
-	vs[21] = y1
-	vs[22] = u0
-	vs[23] = v1
-	vs[24] = g0
-	vs[25] = g1
-	vs[26] = g2
-	vs[27] = g3
-	vs[28] = g4
-	vs[29] = g5
-
-	vs[30] = x1
-	vs[31] = y1
-	vs[32] = u1
-	vs[33] = v1
-	vs[34] = g0
-	vs[35] = g1
-	vs[36] = g2
-	vs[37] = g3
-	vs[38] = g4
-	vs[39] = g5
-
+	// The geometry matrix is applied here instead of in the vertex shader
+	// so that the vertices have the same format as DrawTriangles's.
+	putVertex(vs[0:], float32(a*x0+b*y0+tx), float32(c*x0+d*y0+ty), u0, v0)
+	putVertex(vs[8:], float32(a*x1+b*y0+tx), float32(c*x1+d*y0+ty), u1, v0)
+	putVertex(vs[16:], float32(a*x0+b*y1+tx), float32(c*x0+d*y1+ty), u0, v1)
+	putVertex(vs[24:], float32(a*x1+b*y1+tx), float32(c*x1+d*y1+ty), u1, v1)
 	return vs
 }
+
+// putVertex puts a vertex with the position (x, y), the texel (u, v) and no color scaling to vs.
+func putVertex(vs []float32, x, y, u, v float32) {
+	vs[0] = x
+	vs[1] = y
+	vs[2] = u
+	vs[3] = v
+	vs[4] = 1
+	vs[5] = 1
+	vs[6] = 1
+	vs[7] = 1
+}
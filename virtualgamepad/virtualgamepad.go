@@ -0,0 +1,407 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package virtualgamepad provides virtual gamepads on the screen operated by touches.
+//
+// A virtual gamepad works as a gamepad in the standard layout.
+// The game can handle it in the same way as the actual gamepads with
+// ebiten.IsStandardGamepadButtonPressed or ebiten.StandardGamepadAxisValue,
+// e.g. for touch platforms or as an accessibility option.
+//
+// The gamepad is also operated by the left mouse button, which is useful for debugging on desktops.
+package virtualgamepad
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/hooks"
+	"github.com/hajimehoshi/ebiten/internal/ui"
+)
+
+// A Button is a virtual button.
+type Button struct {
+	// Button is the button in the standard layout this reports.
+	Button ebiten.StandardGamepadButton
+
+	// X and Y are the center of the button on the screen.
+	X float64
+	Y float64
+
+	// Radius is the radius of the touchable area.
+	Radius float64
+
+	// Image and PressedImage are the images drawn at the center of the button.
+	// When Image is nil, a translucent circle is drawn.
+	// When PressedImage is nil, Image is drawn brighter while the button is pressed.
+	Image        *ebiten.Image
+	PressedImage *ebiten.Image
+
+	pressed bool
+}
+
+// A DPad is a virtual directional pad that reports the buttons StandardGamepadButtonLeft*.
+type DPad struct {
+	// X and Y are the center of the pad on the screen.
+	X float64
+	Y float64
+
+	// Radius is the radius of the touchable area.
+	Radius float64
+
+	// Image is the image drawn at the center of the pad.
+	// When Image is nil, translucent circles are drawn.
+	Image *ebiten.Image
+
+	touchID touchID
+	up      bool
+	down    bool
+	left    bool
+	right   bool
+}
+
+// A Stick is a virtual analog stick.
+type Stick struct {
+	// Horizontal and Vertical are the axes in the standard layout this reports.
+	Horizontal ebiten.StandardGamepadAxis
+	Vertical   ebiten.StandardGamepadAxis
+
+	// X and Y are the center of the stick on the screen.
+	X float64
+	Y float64
+
+	// Radius is the radius of the touchable area.
+	// The axis values are 1 or -1 when the knob is moved by Radius.
+	Radius float64
+
+	// Image and KnobImage are the images drawn at the center of the stick and at the knob.
+	// When they are nil, translucent circles are drawn.
+	Image     *ebiten.Image
+	KnobImage *ebiten.Image
+
+	touchID touchID
+	ax      float64
+	ay      float64
+}
+
+// touchID is a touch ID or mouseTouchID. valid is false when no touch is tracked.
+type touchID struct {
+	id    int
+	valid bool
+}
+
+// mouseTouchID is the ID to treat the left mouse button as a touch.
+const mouseTouchID = -1
+
+// A Gamepad is a virtual gamepad.
+//
+// The state is updated automatically before the game's update function is called at every tick.
+// The fields can be modified at any time in the game's update function.
+type Gamepad struct {
+	DPad    *DPad
+	Sticks  []*Stick
+	Buttons []*Button
+
+	id int
+}
+
+var (
+	gamepads  = map[*Gamepad]struct{}{}
+	gamepadsM sync.Mutex
+)
+
+func init() {
+	hooks.AppendHookOnInputUpdate(func() error {
+		ts := currentTouches()
+		gamepadsM.Lock()
+		defer gamepadsM.Unlock()
+		for g := range gamepads {
+			g.update(ts)
+		}
+		return nil
+	})
+}
+
+// New creates a virtual gamepad without any controls, and connects it.
+//
+// name is the name reported as the gamepad's name.
+func New(name string) *Gamepad {
+	g := &Gamepad{
+		id: ui.AddVirtualGamepad(name),
+	}
+	gamepadsM.Lock()
+	gamepads[g] = struct{}{}
+	gamepadsM.Unlock()
+	return g
+}
+
+// NewWithDefaultLayout creates a virtual gamepad with a d-pad at the bottom-left and
+// four buttons at the bottom-right of the screen, and connects it.
+func NewWithDefaultLayout(name string, screenWidth, screenHeight int) *Gamepad {
+	g := New(name)
+	w, h := float64(screenWidth), float64(screenHeight)
+	s := math.Min(w, h) / 8
+	g.DPad = &DPad{
+		X:      s * 1.5,
+		Y:      h - s*1.5,
+		Radius: s,
+	}
+	bx, by := w-s*1.5, h-s*1.5
+	r := s * 0.4
+	g.Buttons = []*Button{
+		{Button: ebiten.StandardGamepadButtonRightBottom, X: bx, Y: by + s*0.6, Radius: r},
+		{Button: ebiten.StandardGamepadButtonRightRight, X: bx + s*0.6, Y: by, Radius: r},
+		{Button: ebiten.StandardGamepadButtonRightLeft, X: bx - s*0.6, Y: by, Radius: r},
+		{Button: ebiten.StandardGamepadButtonRightTop, X: bx, Y: by - s*0.6, Radius: r},
+	}
+	return g
+}
+
+// ID returns the gamepad ID.
+//
+// The ID can be used with the gamepad functions of the ebiten package, e.g. ebiten.IsStandardGamepadButtonPressed.
+func (g *Gamepad) ID() int {
+	return g.id
+}
+
+// Close disconnects the virtual gamepad.
+func (g *Gamepad) Close() {
+	gamepadsM.Lock()
+	delete(gamepads, g)
+	gamepadsM.Unlock()
+	ui.RemoveVirtualGamepad(g.id)
+}
+
+type touchPosition struct {
+	id   int
+	x, y float64
+}
+
+func currentTouches() []touchPosition {
+	var ts []touchPosition
+	for _, id := range ebiten.TouchIDs() {
+		x, y := ebiten.TouchPosition(id)
+		ts = append(ts, touchPosition{id, float64(x), float64(y)})
+	}
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		ts = append(ts, touchPosition{mouseTouchID, float64(x), float64(y)})
+	}
+	return ts
+}
+
+func inCircle(t touchPosition, x, y, r float64) bool {
+	return math.Hypot(t.x-x, t.y-y) <= r
+}
+
+// track returns the touch tracked by id. If id doesn't track any touches,
+// track starts tracking a touch in the circle.
+func track(id *touchID, ts []touchPosition, x, y, r float64) (touchPosition, bool) {
+	if id.valid {
+		for _, t := range ts {
+			if t.id == id.id {
+				return t, true
+			}
+		}
+		id.valid = false
+	}
+	for _, t := range ts {
+		if inCircle(t, x, y, r) {
+			*id = touchID{t.id, true}
+			return t, true
+		}
+	}
+	return touchPosition{}, false
+}
+
+func (g *Gamepad) update(ts []touchPosition) {
+	axes := make([]float64, ebiten.StandardGamepadAxisMax+1)
+	buttons := make([]bool, ebiten.StandardGamepadButtonMax+1)
+
+	if d := g.DPad; d != nil {
+		d.up, d.down, d.left, d.right = false, false, false, false
+		if t, ok := track(&d.touchID, ts, d.X, d.Y, d.Radius); ok && d.Radius > 0 {
+			dx, dy := (t.x-d.X)/d.Radius, (t.y-d.Y)/d.Radius
+			// The center is a dead zone. The diagonal directions press two buttons.
+			const deadZone = 0.25
+			if math.Hypot(dx, dy) > deadZone {
+				l := math.Hypot(dx, dy)
+				nx, ny := dx/l, dy/l
+				s := math.Sin(math.Pi / 8)
+				d.up, d.down, d.left, d.right = ny < -s, ny > s, nx < -s, nx > s
+			}
+		}
+		buttons[ebiten.StandardGamepadButtonLeftTop] = d.up
+		buttons[ebiten.StandardGamepadButtonLeftBottom] = d.down
+		buttons[ebiten.StandardGamepadButtonLeftLeft] = d.left
+		buttons[ebiten.StandardGamepadButtonLeftRight] = d.right
+	}
+
+	for _, s := range g.Sticks {
+		s.ax, s.ay = 0, 0
+		if t, ok := track(&s.touchID, ts, s.X, s.Y, s.Radius); ok && s.Radius > 0 {
+			s.ax, s.ay = (t.x-s.X)/s.Radius, (t.y-s.Y)/s.Radius
+			if l := math.Hypot(s.ax, s.ay); l > 1 {
+				s.ax /= l
+				s.ay /= l
+			}
+		}
+		if 0 <= s.Horizontal && s.Horizontal <= ebiten.StandardGamepadAxisMax {
+			axes[s.Horizontal] = s.ax
+		}
+		if 0 <= s.Vertical && s.Vertical <= ebiten.StandardGamepadAxisMax {
+			axes[s.Vertical] = s.ay
+		}
+	}
+
+	for _, b := range g.Buttons {
+		// A button is pressed while any touch is on it, so that a finger can slide between buttons.
+		b.pressed = false
+		for _, t := range ts {
+			if inCircle(t, b.X, b.Y, b.Radius) {
+				b.pressed = true
+				break
+			}
+		}
+		if b.pressed && 0 <= b.Button && b.Button <= ebiten.StandardGamepadButtonMax {
+			buttons[b.Button] = true
+		}
+	}
+
+	ui.SetVirtualGamepadState(g.id, axes, buttons)
+}
+
+// Draw draws the virtual gamepad on the screen.
+func (g *Gamepad) Draw(screen *ebiten.Image) error {
+	if d := g.DPad; d != nil {
+		if d.Image != nil {
+			if err := drawCentered(screen, d.Image, d.X, d.Y, 1); err != nil {
+				return err
+			}
+		} else {
+			if err := drawCircle(screen, d.X, d.Y, d.Radius, 0.25); err != nil {
+				return err
+			}
+		}
+		r := d.Radius * 0.3
+		for _, dir := range []struct {
+			x, y    float64
+			pressed bool
+		}{
+			{0, -1, d.up},
+			{0, 1, d.down},
+			{-1, 0, d.left},
+			{1, 0, d.right},
+		} {
+			if err := drawCircle(screen, d.X+dir.x*d.Radius*0.6, d.Y+dir.y*d.Radius*0.6, r, alpha(dir.pressed)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, s := range g.Sticks {
+		kx, ky := s.X+s.ax*s.Radius, s.Y+s.ay*s.Radius
+		if s.Image != nil {
+			if err := drawCentered(screen, s.Image, s.X, s.Y, 1); err != nil {
+				return err
+			}
+		} else {
+			if err := drawCircle(screen, s.X, s.Y, s.Radius, 0.25); err != nil {
+				return err
+			}
+		}
+		if s.KnobImage != nil {
+			if err := drawCentered(screen, s.KnobImage, kx, ky, 1); err != nil {
+				return err
+			}
+		} else {
+			if err := drawCircle(screen, kx, ky, s.Radius*0.4, alpha(s.touchID.valid)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, b := range g.Buttons {
+		switch {
+		case b.pressed && b.PressedImage != nil:
+			if err := drawCentered(screen, b.PressedImage, b.X, b.Y, 1); err != nil {
+				return err
+			}
+		case b.Image != nil:
+			s := 1.0
+			if b.pressed {
+				s = 1.5
+			}
+			if err := drawCentered(screen, b.Image, b.X, b.Y, s); err != nil {
+				return err
+			}
+		default:
+			if err := drawCircle(screen, b.X, b.Y, b.Radius, alpha(b.pressed)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func alpha(pressed bool) float64 {
+	if pressed {
+		return 0.75
+	}
+	return 0.4
+}
+
+func drawCentered(screen, img *ebiten.Image, x, y float64, brightness float64) error {
+	w, h := img.Size()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x-float64(w)/2, y-float64(h)/2)
+	op.ColorM.Scale(brightness, brightness, brightness, 1)
+	return screen.DrawImage(img, op)
+}
+
+const circleImageSize = 64
+
+var (
+	circleImage  *ebiten.Image
+	circleImageM sync.Mutex
+)
+
+func drawCircle(screen *ebiten.Image, x, y, r float64, a float64) error {
+	circleImageM.Lock()
+	defer circleImageM.Unlock()
+	if circleImage == nil {
+		const s = circleImageSize
+		img := image.NewAlpha(image.Rect(0, 0, s, s))
+		for j := 0; j < s; j++ {
+			for i := 0; i < s; i++ {
+				d := math.Hypot(float64(i)+0.5-s/2, float64(j)+0.5-s/2)
+				// Antialias the edge by one pixel.
+				v := math.Max(0, math.Min(1, s/2-d))
+				img.SetAlpha(i, j, color.Alpha{uint8(v * 0xff)})
+			}
+		}
+		ci, err := ebiten.NewImageFromImage(img, ebiten.FilterLinear)
+		if err != nil {
+			return err
+		}
+		circleImage = ci
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-circleImageSize/2, -circleImageSize/2)
+	op.GeoM.Scale(2*r/circleImageSize, 2*r/circleImageSize)
+	op.GeoM.Translate(x, y)
+	op.ColorM.Scale(1, 1, 1, a)
+	return screen.DrawImage(circleImage, op)
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 The Ebiten Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualgamepad_test
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/internal/gamepaddb"
+	"github.com/hajimehoshi/ebiten/internal/hooks"
+	"github.com/hajimehoshi/ebiten/internal/ui"
+	. "github.com/hajimehoshi/ebiten/virtualgamepad"
+)
+
+// update runs the hooks with the given touches. The touches are given via the replayed input state.
+func update(t *testing.T, touches ...ui.TouchState) {
+	ui.SetReplayedInputState(&ui.InputState{TouchStates: touches})
+	defer ui.SetReplayedInputState(nil)
+	if err := hooks.RunBeforeUpdateHooks(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func pressed(id int, b ebiten.StandardGamepadButton) bool {
+	return ui.CurrentInput().IsStandardGamepadButtonPressed(id, gamepaddb.StandardButton(b))
+}
+
+func TestGamepad(t *testing.T) {
+	g := New("Virtual Gamepad")
+	defer g.Close()
+	g.DPad = &DPad{X: 50, Y: 50, Radius: 20}
+	g.Sticks = []*Stick{
+		{
+			Horizontal: ebiten.StandardGamepadAxisRightStickHorizontal,
+			Vertical:   ebiten.StandardGamepadAxisRightStickVertical,
+			X:          150,
+			Y:          50,
+			Radius:     20,
+		},
+	}
+	g.Buttons = []*Button{
+		{Button: ebiten.StandardGamepadButtonRightBottom, X: 100, Y: 100, Radius: 10},
+	}
+
+	if !ui.CurrentInput().IsStandardGamepadLayoutAvailable(g.ID()) {
+		t.Errorf("IsStandardGamepadLayoutAvailable(%d): got: false, want: true", g.ID())
+	}
+
+	// Touch the upper-right of the d-pad, the button and the stick.
+	update(t, ui.TouchState{ID: 1, X: 62, Y: 38}, ui.TouchState{ID: 2, X: 105, Y: 100}, ui.TouchState{ID: 3, X: 160, Y: 50})
+	for b, want := range map[ebiten.StandardGamepadButton]bool{
+		ebiten.StandardGamepadButtonLeftTop:     true,
+		ebiten.StandardGamepadButtonLeftRight:   true,
+		ebiten.StandardGamepadButtonLeftBottom:  false,
+		ebiten.StandardGamepadButtonLeftLeft:    false,
+		ebiten.StandardGamepadButtonRightBottom: true,
+	} {
+		if got := pressed(g.ID(), b); got != want {
+			t.Errorf("button %d: got: %t, want: %t", b, got, want)
+		}
+	}
+	if got := ui.CurrentInput().StandardGamepadAxisValue(g.ID(), gamepaddb.StandardAxisRightStickHorizontal); got != 0.5 {
+		t.Errorf("axis: got: %f, want: 0.5", got)
+	}
+
+	// The stick keeps tracking the touch outside of it.
+	update(t, ui.TouchState{ID: 3, X: 250, Y: 50})
+	if got := ui.CurrentInput().StandardGamepadAxisValue(g.ID(), gamepaddb.StandardAxisRightStickHorizontal); got != 1 {
+		t.Errorf("axis: got: %f, want: 1", got)
+	}
+	if pressed(g.ID(), ebiten.StandardGamepadButtonRightBottom) {
+		t.Errorf("the button must be released")
+	}
+
+	g.Close()
+	for _, id := range ui.CurrentInput().GamepadIDs() {
+		if id == g.ID() {
+			t.Errorf("the closed gamepad %d must not be connected", id)
+		}
+	}
+}